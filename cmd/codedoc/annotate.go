@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/codepigeon/codedoc/internal/annotate"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+	"github.com/codepigeon/codedoc/internal/util"
+	"github.com/codepigeon/codedoc/pkg/codedoc"
+)
+
+func runAnnotate(ctx context.Context, args []string) error {
+	annotateCmd := flag.NewFlagSet("annotate", flag.ExitOnError)
+	repoPath := annotateCmd.String("path", ".", "Repository to annotate")
+	analysis := annotateCmd.String("analysis", "", "JSON artifact or index whose file summaries to reuse; other files are summarized now")
+	write := annotateCmd.Bool("write", false, "Write the doc comments to the files instead of printing a diff")
+	maxFiles := annotateCmd.Int("max-files", 5000, "Maximum number of files to scan")
+	cacheDir := annotateCmd.String("cache-dir", util.DefaultCacheDir(), "Directory for cached LLM responses")
+	promptsDir := annotateCmd.String("prompts-dir", "", "Directory of prompt templates; doc_comment.tmpl overrides the doc comment prompt")
+	dryRun := annotateCmd.Bool("dry-run", false, "List the undocumented packages with placeholder comments, without calling the LLM")
+
+	if err := annotateCmd.Parse(args); err != nil {
+		return err
+	}
+	if annotateCmd.NArg() != 0 {
+		return fmt.Errorf("usage: codedoc annotate [--path repo] [--analysis report.json|index.codedoc] [--write]")
+	}
+
+	result := &summarize.Result{FileSummaries: map[string]summarize.FileSummary{}}
+	if *analysis != "" {
+		artifact, _, err := loadArtifact(*analysis)
+		if err != nil {
+			return err
+		}
+		if artifact.Summaries == nil {
+			return fmt.Errorf("%s has no summaries", *analysis)
+		}
+		result = artifact.Summaries
+		if result.FileSummaries == nil {
+			result.FileSummaries = map[string]summarize.FileSummary{}
+		}
+	}
+
+	scanResult, err := scanner.Scan(ctx, scanner.Options{Path: *repoPath, MaxFiles: *maxFiles})
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	targets, err := annotate.Find(*repoPath, scanResult.Files)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "Every Go package and Python module is documented")
+		return nil
+	}
+
+	var provider llm.Provider = llm.NewNoOpProvider()
+	if !*dryRun {
+		var prompts llm.Prompts
+		if *promptsDir != "" {
+			if prompts, err = llm.LoadPrompts(*promptsDir); err != nil {
+				return err
+			}
+		}
+		provider, err = llm.NewAnthropicProvider(llm.AnthropicConfig{CacheDir: *cacheDir, Prompts: prompts})
+		if err != nil {
+			return fmt.Errorf("failed to create LLM provider: %w", err)
+		}
+	}
+
+	opts := summarize.Options{
+		ScanResult:      scanResult,
+		LLMProvider:     provider,
+		MaxLinesPerFile: codedoc.DefaultConfig("").MaxLinesPerFile,
+		RedactSecrets:   true,
+	}
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		comment, err := summarize.DocComment(ctx, opts, result, target)
+		if err != nil {
+			slog.Warn("Doc comment skipped", "file", target.File, "err", err)
+			continue
+		}
+		patch, err := annotate.NewPatch(*repoPath, target, comment)
+		if err != nil {
+			slog.Warn("Doc comment skipped", "file", target.File, "err", err)
+			continue
+		}
+		if !*write {
+			fmt.Print(patch.Diff())
+			continue
+		}
+		if err := patch.Write(*repoPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Annotated %s\n", patch.Path)
+	}
+	return nil
+}
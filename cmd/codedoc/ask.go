@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/index"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/summarize"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+func runAsk(ctx context.Context, args []string) error {
+	askCmd := flag.NewFlagSet("ask", flag.ExitOnError)
+	analysis := askCmd.String("analysis", "", "JSON artifact written by generate --json-out, or index written by --save-index")
+	repoPath := askCmd.String("path", ".", "Repository the analysis was made of, for file excerpts (default: the indexed path)")
+	embeddings := askCmd.String("embeddings", "local", "Provider ranking files by relevance: "+strings.Join(embed.Providers, ", "))
+	sources := askCmd.Int("sources", summarize.DefaultSources, "Number of most relevant files to show the model")
+	cacheDir := askCmd.String("cache-dir", util.DefaultCacheDir(), "Directory for cached LLM responses")
+	promptsDir := askCmd.String("prompts-dir", "", "Directory of prompt templates; answer.tmpl overrides the answer prompt")
+	dryRun := askCmd.Bool("dry-run", false, "Show the relevant files without calling the LLM")
+	asJSON := askCmd.Bool("json", false, "Print the answer and its sources as JSON")
+
+	if err := askCmd.Parse(args); err != nil {
+		return err
+	}
+	question := strings.TrimSpace(strings.Join(askCmd.Args(), " "))
+	if *analysis == "" || question == "" {
+		return fmt.Errorf("usage: codedoc ask --analysis report.json|index.codedoc [--path repo] \"question\"")
+	}
+	if *sources <= 0 {
+		return fmt.Errorf("--sources must be positive")
+	}
+
+	artifact, idx, err := loadArtifact(*analysis)
+	if err != nil {
+		return err
+	}
+	var vectors map[string][]float64
+	if idx != nil {
+		vectors = idx.Vectors(*embeddings)
+		if !flagSet(askCmd, "path") {
+			*repoPath = idx.RepoPath
+		}
+	}
+	if artifact.Scan == nil || artifact.Summaries == nil {
+		return fmt.Errorf("%s has no scan results or summaries", *analysis)
+	}
+	// Read the files from --path, wherever the analysis ran.
+	for i := range artifact.Scan.Files {
+		file := &artifact.Scan.Files[i]
+		file.Path = filepath.Join(*repoPath, file.RelativePath)
+	}
+
+	embedder, err := embed.New(*embeddings)
+	if err != nil {
+		return fmt.Errorf("--embeddings: %w", err)
+	}
+
+	var provider llm.Provider = llm.NewNoOpProvider()
+	if !*dryRun {
+		var prompts llm.Prompts
+		if *promptsDir != "" {
+			if prompts, err = llm.LoadPrompts(*promptsDir); err != nil {
+				return err
+			}
+		}
+		provider, err = llm.NewAnthropicProvider(llm.AnthropicConfig{CacheDir: *cacheDir, Prompts: prompts})
+		if err != nil {
+			return fmt.Errorf("failed to create LLM provider: %w", err)
+		}
+	}
+
+	opts := summarize.Options{
+		ScanResult:    artifact.Scan,
+		LLMProvider:   provider,
+		RedactSecrets: true,
+	}
+	answer, err := summarize.Ask(ctx, opts, artifact.Summaries, embedder, vectors, question, *sources)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(answer)
+	}
+	fmt.Println(answer.Text)
+	printList("Sources", sourcePaths(answer.Sources))
+	return nil
+}
+
+// loadArtifact reads a JSON artifact, or an index and its artifact.
+func loadArtifact(path string) (report.Artifact, *index.Index, error) {
+	var artifact report.Artifact
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return artifact, nil, err
+	}
+	if index.Is(data) {
+		idx, err := index.Decode(data)
+		if err != nil {
+			return artifact, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return idx.Artifact, idx, nil
+	}
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return artifact, nil, fmt.Errorf("%s is not a codedoc JSON artifact: %w", path, err)
+	}
+	return artifact, nil, nil
+}
+
+func sourcePaths(matches []embed.Match) []string {
+	paths := []string{}
+	for _, match := range matches {
+		paths = append(paths, match.Path)
+	}
+	return paths
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/progress"
+	"github.com/codepigeon/codedoc/internal/summarize"
+	"github.com/codepigeon/codedoc/internal/util"
+	"github.com/codepigeon/codedoc/pkg/codedoc"
+)
+
+func runChanges(ctx context.Context, args []string) error {
+	changesCmd := flag.NewFlagSet("changes", flag.ExitOnError)
+	from := changesCmd.String("from", "", "Git ref the changes start from, such as the last release tag")
+	to := changesCmd.String("to", "HEAD", "Git ref the changes end at")
+	repoPath := changesCmd.String("path", ".", "Repository to compare the refs in")
+	outputFile := changesCmd.String("out", "", "Write the summary to this file instead of stdout")
+	asJSON := changesCmd.Bool("json", false, "Write the summary as JSON")
+	maxFiles := changesCmd.Int("max-files", summarize.DefaultChangedFiles, "Summarize this many of the most changed files; the rest are only listed")
+	cacheDir := changesCmd.String("cache-dir", util.DefaultCacheDir(), "Directory for cached LLM responses")
+	promptsDir := changesCmd.String("prompts-dir", "", "Directory of prompt templates; change.tmpl and changelog.tmpl override the change prompts")
+	dryRun := changesCmd.Bool("dry-run", false, "List the changed files with placeholder summaries, without calling the LLM")
+	quiet := changesCmd.Bool("quiet", false, "Print nothing but the summary and errors")
+
+	if err := changesCmd.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || changesCmd.NArg() > 0 {
+		return fmt.Errorf("usage: codedoc changes --from <ref> [--to <ref>] [--path repo]")
+	}
+	if *maxFiles < 0 {
+		return fmt.Errorf("--max-files must not be negative")
+	}
+
+	changes, err := util.GitChanges(ctx, *repoPath, *from, *to)
+	if err != nil {
+		return err
+	}
+
+	var provider llm.Provider = llm.NewNoOpProvider()
+	if !*dryRun {
+		var prompts llm.Prompts
+		if *promptsDir != "" {
+			if prompts, err = llm.LoadPrompts(*promptsDir); err != nil {
+				return err
+			}
+		}
+		provider, err = llm.NewAnthropicProvider(llm.AnthropicConfig{CacheDir: *cacheDir, Prompts: prompts})
+		if err != nil {
+			return fmt.Errorf("failed to create LLM provider: %w", err)
+		}
+	}
+
+	level := progress.Normal
+	if *quiet {
+		level = progress.Quiet
+	}
+	reporter := progress.New(os.Stderr, level)
+	opts := summarize.Options{
+		LLMProvider:     provider,
+		MaxLinesPerFile: codedoc.DefaultConfig("").MaxLinesPerFile,
+		RedactSecrets:   true,
+		Progress:        reporter,
+	}
+	reporter.Stage("summarize", min(len(changes), *maxFiles))
+	result, err := summarize.SummarizeChanges(ctx, opts, *from, *to, changes, *maxFiles)
+	if err != nil {
+		return err
+	}
+	reporter.Done(fmt.Sprintf("%d files changed", len(changes)))
+
+	output := []byte(result.Markdown())
+	if *asJSON {
+		if output, err = json.MarshalIndent(result, "", "  "); err != nil {
+			return err
+		}
+		output = append(output, '\n')
+	}
+	if *outputFile == "" {
+		_, err = os.Stdout.Write(output)
+		return err
+	}
+	return os.WriteFile(*outputFile, output, 0o644)
+}
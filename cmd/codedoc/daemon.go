@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/codepigeon/codedoc/internal/drift"
+	"github.com/codepigeon/codedoc/internal/logging"
+	"github.com/codepigeon/codedoc/internal/notify"
+	"github.com/codepigeon/codedoc/internal/progress"
+	"github.com/codepigeon/codedoc/internal/schedule"
+	"github.com/codepigeon/codedoc/pkg/codedoc"
+)
+
+// daemonFile is the --jobs file: the repositories to keep documented, each
+// with the generate flags that analyze and publish it.
+type daemonFile struct {
+	Schedule string        `yaml:"schedule"`
+	Alerts   notify.Config `yaml:"alerts"`
+	Jobs     []daemonJob   `yaml:"jobs"`
+}
+
+type daemonJob struct {
+	Name string `yaml:"name"`
+	// Schedule and Alerts override the file's and the flags for this job.
+	Schedule string        `yaml:"schedule"`
+	Alerts   notify.Config `yaml:"alerts"`
+	Args     []string      `yaml:"args"`
+
+	config   *Config
+	schedule *schedule.Schedule
+	next     time.Time
+	sinks    []notify.Sink
+	// previous is the last analysis, which the next run is compared with.
+	previous *drift.Snapshot
+}
+
+func runDaemon(ctx context.Context, args []string) error {
+	daemonCmd := flag.NewFlagSet("daemon", flag.ExitOnError)
+	spec := daemonCmd.String("schedule", "", `Cron expression for re-analysis, such as "0 6 * * 1" (Mondays at 06:00) or @daily`)
+	jobsFile := daemonCmd.String("jobs", "", "YAML file listing the repositories to document and their generate flags")
+	runNow := daemonCmd.Bool("run-now", false, "Also run every job once at startup")
+	var alerts notify.Config
+	daemonCmd.BoolVar(&alerts.Stdout, "alert-stdout", false, "Print an alert when a run finds new endpoints, models or frameworks")
+	daemonCmd.StringVar(&alerts.Webhook, "alert-webhook", "", "POST those alerts as JSON to this URL")
+	daemonCmd.StringVar(&alerts.Slack, "alert-slack", "", "Send those alerts to this Slack incoming webhook URL")
+	logLevel := daemonCmd.String("log-level", "info", "Diagnostic log level: "+strings.Join(logging.Levels, ", "))
+	logJSON := daemonCmd.Bool("log-json", false, "Write diagnostic logs as JSON lines")
+
+	if err := daemonCmd.Parse(args); err != nil {
+		return err
+	}
+
+	logger, err := logging.New(progress.New(os.Stderr, progress.Normal), *logLevel, *logJSON)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	jobs, err := loadDaemonJobs(*spec, *jobsFile, alerts, daemonCmd.Args())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, job := range jobs {
+		job.next = job.schedule.Next(now)
+		if job.next.IsZero() {
+			return fmt.Errorf("job %s: schedule %q never fires", job.Name, job.Schedule)
+		}
+		if *runNow {
+			job.next = now
+		}
+	}
+
+	for {
+		due := jobs[0]
+		for _, job := range jobs[1:] {
+			if job.next.Before(due.next) {
+				due = job
+			}
+		}
+		slog.Info("Waiting for next run", "job", due.Name, "at", due.next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(due.next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			slog.Info("Daemon stopped")
+			return nil
+		case <-timer.C:
+		}
+
+		for _, job := range jobs {
+			if job.next.After(time.Now()) {
+				continue
+			}
+			runDaemonJob(ctx, job)
+			if ctx.Err() != nil {
+				slog.Info("Daemon stopped")
+				return nil
+			}
+			// A run that overlaps the next firing skips it rather than
+			// starting again at once.
+			job.next = job.schedule.Next(time.Now())
+		}
+	}
+}
+
+// loadDaemonJobs builds the jobs from the --jobs file, or a single job from
+// generate flags given after the daemon flags.
+func loadDaemonJobs(spec, jobsFile string, alerts notify.Config, args []string) ([]*daemonJob, error) {
+	file := &daemonFile{Schedule: spec, Alerts: alerts}
+	switch {
+	case jobsFile != "" && len(args) > 0:
+		return nil, fmt.Errorf("give either --jobs or generate flags, not both")
+	case jobsFile != "":
+		data, err := os.ReadFile(jobsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jobs: %w", err)
+		}
+		if err := yaml.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", jobsFile, err)
+		}
+		if spec != "" {
+			file.Schedule = spec
+		}
+		if !alerts.Empty() {
+			file.Alerts = alerts
+		}
+	default:
+		file.Jobs = []daemonJob{{Name: "generate", Args: args}}
+	}
+	if len(file.Jobs) == 0 {
+		return nil, fmt.Errorf("usage: codedoc daemon --schedule <cron> (--jobs jobs.yaml | -- [generate flags])")
+	}
+
+	jobs := make([]*daemonJob, len(file.Jobs))
+	for i := range file.Jobs {
+		job := &file.Jobs[i]
+		if job.Name == "" {
+			job.Name = fmt.Sprintf("job %d", i+1)
+		}
+		if job.Schedule == "" {
+			job.Schedule = file.Schedule
+		}
+		if job.Schedule == "" {
+			return nil, fmt.Errorf("job %s: no schedule; pass --schedule or set one in the jobs file", job.Name)
+		}
+
+		var err error
+		if job.schedule, err = schedule.Parse(job.Schedule); err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		if job.config, err = parseGenerateArgs(job.Args); err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.Name, err)
+		}
+
+		if job.Alerts.Empty() {
+			job.Alerts = file.Alerts
+		}
+		job.sinks = job.Alerts.Sinks(os.Stdout)
+		// The job's last JSON artifact, if any, stands in for the previous
+		// run so alerts survive restarts.
+		if output := job.config.JSONOutputFile; len(job.sinks) > 0 && output != "" {
+			if snapshot, err := loadSnapshot(output); err == nil {
+				job.previous = &snapshot
+			}
+		}
+		jobs[i] = job
+	}
+	return jobs, nil
+}
+
+// runDaemonJob regenerates one job's documentation. Failures are logged and
+// the job is retried at its next scheduled time.
+func runDaemonJob(ctx context.Context, job *daemonJob) {
+	started := time.Now()
+	slog.Info("Starting scheduled run", "job", job.Name)
+
+	// Each run starts from the parsed flags, so nothing set during one run
+	// carries over to the next.
+	config := *job.config
+	config.Progress = progress.New(os.Stderr, config.progressLevel())
+	result, err := codedoc.Run(ctx, config.Config)
+	if err != nil {
+		if ctx.Err() != nil {
+			slog.Warn("Scheduled run interrupted", "job", job.Name, "err", err)
+			return
+		}
+		slog.Error("Scheduled run failed", "job", job.Name, "err", err)
+		return
+	}
+	slog.Info("Finished scheduled run", "job", job.Name, "report", config.OutputFile, "duration", time.Since(started).Round(time.Second))
+
+	// Per-project runs keep their analyses in the sub-project reports and
+	// are not compared.
+	if len(job.sinks) == 0 || result.Scan == nil {
+		return
+	}
+	commit := result.Provenance.CommitSHA
+	if commit == "" {
+		commit = result.Scan.RepoMetadata.LastCommit.Hash
+	}
+	current := drift.Snapshot{Label: job.Name, Commit: commit, Scan: result.Scan, Detection: result.Detection}
+	if job.previous != nil {
+		if alert, ok := notify.FromDrift(job.Name, drift.Compare(*job.previous, current)); ok {
+			for _, sink := range job.sinks {
+				if err := sink.Send(ctx, alert); err != nil {
+					slog.Error("Alert failed", "job", job.Name, "err", err)
+				}
+			}
+		}
+	}
+	job.previous = &current
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/drift"
+	"github.com/codepigeon/codedoc/internal/index"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+func runDiff(ctx context.Context, args []string) error {
+	diffCmd := flag.NewFlagSet("diff", flag.ExitOnError)
+	since := diffCmd.String("since", "", "Compare the working tree at --path with this git ref instead of two JSON artifacts")
+	repoPath := diffCmd.String("path", ".", "Repository to analyze with --since")
+	outputFile := diffCmd.String("out", "", "Write the changelog to this file instead of stdout")
+	maxFiles := diffCmd.Int("max-files", 5000, "Maximum number of files to scan with --since")
+
+	if err := diffCmd.Parse(args); err != nil {
+		return err
+	}
+
+	var before, after drift.Snapshot
+	var err error
+	switch {
+	case *since != "" && diffCmd.NArg() == 0:
+		before, after, err = snapshotsSince(ctx, *repoPath, *since, *maxFiles)
+	case *since == "" && diffCmd.NArg() == 2:
+		before, err = loadSnapshot(diffCmd.Arg(0))
+		if err == nil {
+			after, err = loadSnapshot(diffCmd.Arg(1))
+		}
+	default:
+		return fmt.Errorf("usage: codedoc diff old-report.json new-report.json (or index files), or codedoc diff --since <ref> [--path repo]")
+	}
+	if err != nil {
+		return err
+	}
+
+	changelog := drift.Compare(before, after).Markdown()
+	if *outputFile == "" {
+		fmt.Print(changelog)
+		return nil
+	}
+	return os.WriteFile(*outputFile, []byte(changelog), 0o644)
+}
+
+// loadSnapshot reads a JSON artifact written with --json-out or an index
+// written with --save-index.
+func loadSnapshot(path string) (drift.Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return drift.Snapshot{}, err
+	}
+	var artifact report.Artifact
+	if index.Is(data) {
+		idx, err := index.Decode(data)
+		if err != nil {
+			return drift.Snapshot{}, fmt.Errorf("%s: %w", path, err)
+		}
+		artifact = idx.Artifact
+	} else if err := json.Unmarshal(data, &artifact); err != nil {
+		return drift.Snapshot{}, fmt.Errorf("%s is not a codedoc JSON artifact: %w", path, err)
+	}
+	if artifact.Scan == nil {
+		return drift.Snapshot{}, fmt.Errorf("%s has no scan results", path)
+	}
+
+	commit := artifact.Provenance.CommitSHA
+	if commit == "" {
+		commit = artifact.Scan.RepoMetadata.LastCommit.Hash
+	}
+	return drift.Snapshot{Label: path, Commit: commit, Scan: artifact.Scan, Detection: artifact.Detection}, nil
+}
+
+// snapshotsSince analyzes ref, exported to a temporary directory, and the
+// working tree at repoPath.
+func snapshotsSince(ctx context.Context, repoPath, ref string, maxFiles int) (drift.Snapshot, drift.Snapshot, error) {
+	tempDir, err := os.MkdirTemp("", "codedoc-diff-*")
+	if err != nil {
+		return drift.Snapshot{}, drift.Snapshot{}, err
+	}
+	defer util.RemoveDir(tempDir)
+
+	if err := util.GitExport(ctx, repoPath, ref, tempDir); err != nil {
+		return drift.Snapshot{}, drift.Snapshot{}, err
+	}
+
+	before, err := analyzeSnapshot(ctx, tempDir, maxFiles)
+	if err != nil {
+		return drift.Snapshot{}, drift.Snapshot{}, fmt.Errorf("%s: %w", ref, err)
+	}
+	before.Label = ref
+
+	after, err := analyzeSnapshot(ctx, repoPath, maxFiles)
+	if err != nil {
+		return drift.Snapshot{}, drift.Snapshot{}, err
+	}
+	after.Label = "working tree"
+	return before, after, nil
+}
+
+func analyzeSnapshot(ctx context.Context, path string, maxFiles int) (drift.Snapshot, error) {
+	scanResult, err := scanner.Scan(ctx, scanner.Options{Path: path, MaxFiles: maxFiles})
+	if err != nil {
+		return drift.Snapshot{}, fmt.Errorf("scan failed: %w", err)
+	}
+	detectionResult, err := detect.Detect(ctx, detect.Options{Files: scanResult.Files})
+	if err != nil {
+		return drift.Snapshot{}, fmt.Errorf("detection failed: %w", err)
+	}
+	return drift.Snapshot{Scan: scanResult, Detection: detectionResult}, nil
+}
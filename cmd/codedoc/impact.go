@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codepigeon/codedoc/internal/depmap"
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/impact"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func runImpact(ctx context.Context, args []string) error {
+	impactCmd := flag.NewFlagSet("impact", flag.ExitOnError)
+	repoPath := impactCmd.String("path", ".", "Path to repository containing the file")
+	asJSON := impactCmd.Bool("json", false, "Print the result as JSON")
+	maxFiles := impactCmd.Int("max-files", 5000, "Maximum number of files to scan")
+
+	if err := impactCmd.Parse(args); err != nil {
+		return err
+	}
+	if impactCmd.NArg() != 1 {
+		return fmt.Errorf("usage: codedoc impact [--path repo] [--json] <file>")
+	}
+
+	target, err := relativeTarget(*repoPath, impactCmd.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	scanResult, err := scanner.Scan(ctx, scanner.Options{
+		Path:         *repoPath,
+		MaxFiles:     *maxFiles,
+		IncludeTests: true,
+	})
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+
+	found := false
+	for _, file := range scanResult.Files {
+		if filepath.ToSlash(file.RelativePath) == target {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s was not found in the scanned files", target)
+	}
+
+	detectionResult, err := detect.Detect(ctx, detect.Options{Files: scanResult.Files})
+	if err != nil {
+		return fmt.Errorf("detection failed: %w", err)
+	}
+
+	result := impact.Analyze(impact.Options{
+		Target:    target,
+		Files:     scanResult.Files,
+		Graph:     graph.Build(scanResult.Files, depmap.ModuleName(*repoPath)),
+		Detection: detectionResult,
+	})
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	fmt.Printf("Impact of changes to %s\n", result.Target)
+	printList("Downstream files", result.Dependents)
+
+	fmt.Printf("\nEndpoints (%d)\n", len(result.Endpoints))
+	for _, endpoint := range result.Endpoints {
+		fmt.Printf("  %s %s (%s)\n", endpoint.Method, endpoint.Path, endpoint.File)
+	}
+
+	printList("Tests", result.Tests)
+	return nil
+}
+
+func relativeTarget(repoPath, target string) (string, error) {
+	if filepath.IsAbs(target) {
+		absRepo, err := filepath.Abs(repoPath)
+		if err != nil {
+			return "", err
+		}
+		rel, err := filepath.Rel(absRepo, target)
+		if err != nil {
+			return "", err
+		}
+		target = rel
+	}
+	return filepath.ToSlash(filepath.Clean(target)), nil
+}
+
+func printList(title string, items []string) {
+	fmt.Printf("\n%s (%d)\n", title, len(items))
+	for _, item := range items {
+		fmt.Printf("  %s\n", item)
+	}
+}
@@ -2,19 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"time"
+	"strings"
+	"syscall"
 
-	"github.com/codepigeon/codedoc/internal/detect"
-	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/baseline"
+	"github.com/codepigeon/codedoc/internal/logging"
+	"github.com/codepigeon/codedoc/internal/progress"
+	"github.com/codepigeon/codedoc/internal/readme"
 	"github.com/codepigeon/codedoc/internal/report"
-	"github.com/codepigeon/codedoc/internal/scanner"
-	"github.com/codepigeon/codedoc/internal/summarize"
-	"github.com/codepigeon/codedoc/internal/util"
+	"github.com/codepigeon/codedoc/pkg/codedoc"
 )
 
 // Version information set by GoReleaser
@@ -25,50 +29,114 @@ var (
 	builtBy = "unknown"
 )
 
+// Config holds the generate flags: the library configuration plus the
+// options that only concern the CLI.
 type Config struct {
-	Path            string
-	RepoURL         string
-	OutputFile      string
-	MaxFiles        int
-	MaxLinesPerFile int
-	IncludeTests    bool
-	DryRun          bool
-	Languages       []string
-	RedactSecrets   bool
-	Force           bool
+	codedoc.Config
+	Interactive bool // set by `codedoc review`
+	CheckUpdate bool
+	Quiet       bool
+	Verbose     bool
+	LogLevel    string
+	LogJSON     bool
 }
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "self-update":
+			if err := runSelfUpdate(ctx, os.Args[2:]); err != nil {
+				fatal("Self-update failed", err)
+			}
+			return
+		case "impact":
+			if err := runImpact(ctx, os.Args[2:]); err != nil {
+				fatal("Impact analysis failed", err)
+			}
+			return
+		case "diff":
+			if err := runDiff(ctx, os.Args[2:]); err != nil {
+				fatal("Diff failed", err)
+			}
+			return
+		case "daemon":
+			if err := runDaemon(ctx, os.Args[2:]); err != nil {
+				fatal("Daemon failed", err)
+			}
+			return
+		case "serve":
+			if err := runServe(ctx, os.Args[2:]); err != nil {
+				fatal("Serve failed", err)
+			}
+			return
+		case "plan":
+			if err := runPlan(ctx, os.Args[2:]); err != nil {
+				fatal("Plan failed", err)
+			}
+			return
+		case "ask":
+			if err := runAsk(ctx, os.Args[2:]); err != nil {
+				fatal("Ask failed", err)
+			}
+			return
+		case "render":
+			if err := runRender(ctx, os.Args[2:]); err != nil {
+				fatal("Render failed", err)
+			}
+			return
+		case "changes":
+			if err := runChanges(ctx, os.Args[2:]); err != nil {
+				fatal("Changes failed", err)
+			}
+			return
+		case "annotate":
+			if err := runAnnotate(ctx, os.Args[2:]); err != nil {
+				fatal("Annotate failed", err)
+			}
+			return
+		}
+	}
+
 	config := parseFlags()
 
 	if err := validateConfig(config); err != nil {
-		log.Fatalf("Configuration error: %v", err)
+		fatal("Configuration error", err)
 	}
 
-	ctx := context.Background()
-	if err := runGenerate(ctx, config); err != nil {
-		log.Fatalf("Generation failed: %v", err)
+	reporter := progress.New(os.Stderr, config.progressLevel())
+	logger, err := logging.New(reporter, config.LogLevel, config.LogJSON)
+	if err != nil {
+		fatal("Configuration error", err)
+	}
+	slog.SetDefault(logger)
+
+	go func() {
+		<-ctx.Done()
+		// Restore the default handling so a second Ctrl-C aborts at once.
+		stop()
+		slog.Warn("Interrupted, writing a partial report; press Ctrl-C again to abort")
+	}()
+
+	if err := runGenerate(ctx, config, reporter); err != nil {
+		if errors.Is(err, codedoc.ErrInterrupted) {
+			slog.Error("Generation interrupted; rerun with --resume to continue", "report", config.OutputFile)
+			os.Exit(130)
+		}
+		fatal("Generation failed", err)
 	}
 }
 
-func parseFlags() *Config {
-	config := &Config{}
-
-	generateCmd := flag.NewFlagSet("generate", flag.ExitOnError)
-	generateCmd.StringVar(&config.Path, "path", "", "Path to repository to analyze")
-	generateCmd.StringVar(&config.RepoURL, "repo-url", "", "Git repository URL to clone and analyze")
-	generateCmd.StringVar(&config.OutputFile, "out", "CODEBASE_REPORT.md", "Output file name")
-	generateCmd.IntVar(&config.MaxFiles, "max-files", 200, "Maximum number of files to process")
-	generateCmd.IntVar(&config.MaxLinesPerFile, "max-lines-per-file", 1000, "Maximum lines per file to process")
-	generateCmd.BoolVar(&config.IncludeTests, "include-tests", false, "Include test files in analysis")
-	generateCmd.BoolVar(&config.DryRun, "dry-run", false, "Generate report without LLM calls")
-	generateCmd.BoolVar(&config.RedactSecrets, "redact-secrets", true, "Redact potential secrets from output")
-	generateCmd.BoolVar(&config.Force, "force", false, "Force re-analysis of cached files")
+// fatal logs err through the default logger, so --log-json runs fail with a
+// JSON record, and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
 
-	langDefault := "go,py,ts,js,md,yaml,dockerfile"
-	langUsage := "Comma-separated list of languages to analyze"
-	var langString string
-	generateCmd.StringVar(&langString, "lang", langDefault, langUsage)
+func parseFlags() *Config {
+	generateCmd, parsed := newGenerateFlags(flag.ExitOnError)
 
 	// Check for version flag first
 	if len(os.Args) > 1 && (os.Args[1] == "-v" || os.Args[1] == "--version" || os.Args[1] == "version") {
@@ -82,9 +150,33 @@ func parseFlags() *Config {
 	// Check for help flag
 	if len(os.Args) > 1 && (os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help") {
 		fmt.Println("Usage: codedoc generate [flags]")
+		fmt.Println("       codedoc impact [--path repo] [--json] <file>")
+		fmt.Println("       codedoc review [flags]")
+		fmt.Println("       codedoc readme [flags]")
+		fmt.Println("       codedoc plan [--json] [generate flags]")
+		fmt.Println("       codedoc ask --analysis report.json|index.codedoc [--path repo] \"question\"")
+		fmt.Println("       codedoc render --index index.codedoc [generate output flags]")
+		fmt.Println("       codedoc annotate [--path repo] [--analysis report.json|index.codedoc] [--write]")
+		fmt.Println("       codedoc diff old-report.json new-report.json | --since <ref>")
+		fmt.Println("       codedoc changes --from <ref> [--to <ref>] [--path repo] [--json]")
+		fmt.Println("       codedoc daemon --schedule <cron> (--jobs jobs.yaml | -- [generate flags])")
+		fmt.Println("       codedoc serve [--cache-dir dir] [--dry-run] [--index index.codedoc]")
+		fmt.Println("       codedoc self-update [--force]")
 		fmt.Println("       codedoc version")
 		fmt.Println("\nCommands:")
 		fmt.Println("  generate    Generate codebase documentation")
+		fmt.Println("  review      Generate, but accept, edit or regenerate each summary before writing")
+		fmt.Println("  readme      Generate, and create or refresh the repository's README.md from the analysis")
+		fmt.Println("  plan        Estimate repository size, LLM cost and time, and recommend flags, without generating")
+		fmt.Println("  ask         Answer a question about the repository from an earlier analysis")
+		fmt.Println("  render      Write the report of a saved index again, in any format or language")
+		fmt.Println("  annotate    Propose doc comments for undocumented Go packages and Python modules as a diff")
+		fmt.Println("  impact      List files, endpoints and tests affected by changing a file")
+		fmt.Println("  diff        Describe architecture changes between two runs as Markdown")
+		fmt.Println("  changes     Summarize the changes between two git refs by module, for a changelog")
+		fmt.Println("  daemon      Keep documentation fresh by regenerating it on a cron schedule")
+		fmt.Println("  serve       Answer scan, detect, summarizeFile and report requests as JSON-RPC over stdio")
+		fmt.Println("  self-update Download and install the latest release")
 		fmt.Println("  version     Show version information")
 		fmt.Println("\nFlags for 'generate' command:")
 		generateCmd.PrintDefaults()
@@ -98,7 +190,7 @@ func parseFlags() *Config {
 		os.Exit(1)
 	}
 
-	if os.Args[1] != "generate" {
+	if os.Args[1] != "generate" && os.Args[1] != "review" && os.Args[1] != "readme" {
 		fmt.Printf("Unknown command: %s\n", os.Args[1])
 		fmt.Println("Usage: codedoc generate [flags]")
 		fmt.Println("       codedoc version")
@@ -107,17 +199,210 @@ func parseFlags() *Config {
 	}
 
 	if err := generateCmd.Parse(os.Args[2:]); err != nil {
-		log.Fatalf("Failed to parse flags: %v", err)
+		fatal("Failed to parse flags", err)
+	}
+	config := parsed()
+	config.Interactive = os.Args[1] == "review"
+	if os.Args[1] == "readme" && config.Readme == "" {
+		if config.Path == "" {
+			fatal("Configuration error", errors.New("codedoc readme needs --path, or --readme with --repo-url"))
+		}
+		config.Readme = filepath.Join(config.Path, readme.DefaultFileName)
 	}
+	return config
+}
 
-	config.Languages = parseLanguages(langString)
+// parseGenerateArgs parses generate flags given other than on the command
+// line, such as a daemon job's, returning errors instead of exiting.
+func parseGenerateArgs(args []string) (*Config, error) {
+	generateCmd, parsed := newGenerateFlags(flag.ContinueOnError)
+	generateCmd.SetOutput(io.Discard)
+	if err := generateCmd.Parse(args); err != nil {
+		return nil, err
+	}
+	if generateCmd.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", generateCmd.Arg(0))
+	}
+	config := parsed()
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
 
-	return config
+// newGenerateFlags defines the generate flags. Once the returned flag set is
+// parsed, the returned function builds the Config from them.
+func newGenerateFlags(errorHandling flag.ErrorHandling) (*flag.FlagSet, func() *Config) {
+	config := &Config{Config: codedoc.DefaultConfig("")}
+	defaults := config.Config
+
+	generateCmd := flag.NewFlagSet("generate", errorHandling)
+	var paths pathList
+	generateCmd.Var(&paths, "path", "Path to repository to analyze (repeat to combine several repositories in one report)")
+	generateCmd.StringVar(&config.RepoURL, "repo-url", "", "Git repository URL to clone and analyze")
+	generateCmd.StringVar(&config.ConfigFile, "config", "", "Path to codedoc.yaml (default: codedoc.yaml in the analyzed repository)")
+	generateCmd.StringVar(&config.OutputFile, "out", defaults.OutputFile, "Output file name")
+	generateCmd.StringVar(&config.OutputDir, "output-dir", "", "Directory for the report and every other artifact; relative --out and --json-out are placed inside it")
+	generateCmd.StringVar(&config.OutDir, "out-dir", "", "Write the report as an index plus one page per module and top file in this directory")
+	generateCmd.StringVar(&config.TemplateDir, "template-dir", "", "Directory of Go text/templates (*.tmpl) overriding the report layout or individual sections")
+	generateCmd.StringVar(&config.PromptsDir, "prompts-dir", "", "Directory of Go text/templates (<type>.tmpl) overriding the built-in LLM prompts")
+	generateCmd.StringVar(&config.OutputLang, "output-lang", "", "Language of summaries and report headings, as a tag such as ja, de or pt-BR (default: English)")
+	generateCmd.StringVar(&config.Format, "format", defaults.Format, "Report format: markdown, html, pdf or text")
+	generateCmd.StringVar(&config.Theme, "theme", defaults.Theme, "HTML and PDF color theme: light, dark or auto (follows the reader's system setting)")
+	generateCmd.StringVar(&config.CSSFile, "css", "", "Style sheet added to HTML and PDF output after the built-in one")
+	generateCmd.StringVar(&config.Logo, "logo", "", "Image file or URL shown in a header above HTML and PDF reports")
+	generateCmd.StringVar(&config.Header, "header", "", "Text shown in the HTML and PDF header, such as the organization name")
+	generateCmd.IntVar(&config.MaxFiles, "max-files", defaults.MaxFiles, "Maximum number of files to process")
+	generateCmd.IntVar(&config.MaxLinesPerFile, "max-lines-per-file", defaults.MaxLinesPerFile, "Maximum lines per file to process")
+	generateCmd.IntVar(&config.TopFiles, "top-files", defaults.TopFiles, "Number of most important files to summarize individually")
+	generateCmd.IntVar(&config.FilesPerModule, "files-per-module", 0, "Summarize this many files in every module instead of --top-files overall")
+	generateCmd.IntVar(&config.FileTokenBudget, "file-token-budget", 0, "Stop file summaries after spending this many LLM tokens (0 for no limit)")
+	generateCmd.BoolVar(&config.MapReduce, "map-reduce", false, "Summarize modules from their code first, then build the architecture summary from those")
+	generateCmd.IntVar(&config.MaxEndpoints, "max-endpoints", defaults.MaxEndpoints, "Maximum rows in the endpoints table (0 for no limit)")
+	generateCmd.BoolVar(&config.IncludeTests, "include-tests", false, "Include test files in analysis")
+	generateCmd.BoolVar(&config.DryRun, "dry-run", false, "Generate report without LLM calls")
+	generateCmd.BoolVar(&config.RedactSecrets, "redact-secrets", defaults.RedactSecrets, "Redact potential secrets from output")
+	generateCmd.BoolVar(&config.Force, "force", false, "Force re-analysis of cached files")
+	generateCmd.BoolVar(&config.Resume, "resume", false, "Resume an interrupted run from its checkpoint instead of starting over")
+	generateCmd.StringVar(&config.CacheDir, "cache-dir", defaults.CacheDir, "Directory for cached LLM summaries")
+	generateCmd.StringVar(&config.CacheURL, "cache-url", "", "Shared cache backend (s3://bucket/prefix, redis://host:port/db); overrides --cache-dir")
+	generateCmd.StringVar(&config.JSONOutputFile, "json-out", "", "Also write the analysis as a JSON artifact to this file")
+	generateCmd.StringVar(&config.SignKey, "sign-key", "", "Ed25519 PEM private key used to sign the JSON artifact")
+	generateCmd.BoolVar(&config.PerProject, "per-project", false, "In monorepos, write one report per sub-project plus an index")
+	generateCmd.BoolVar(&config.SplitByOwner, "split-by-owner", false, "Also write one report per CODEOWNERS owner covering only their files")
+	generateCmd.StringVar(&config.Model, "model", defaults.Model, "Anthropic model that writes the summaries")
+	generateCmd.IntVar(&config.MaxOutputTokens, "max-output-tokens", defaults.MaxOutputTokens, "Maximum length of each LLM response, in tokens")
+	generateCmd.Float64Var(&config.Temperature, "temperature", defaults.Temperature, "Sampling temperature of the LLM, from 0 to 1")
+	generateCmd.BoolVar(&config.Reproducible, "reproducible", false, "Pin the model, use temperature 0 and write an input manifest for audit diffing")
+	generateCmd.BoolVar(&config.CheckUpdate, "check-update", false, "Print a notice when a newer codedoc release is available")
+	generateCmd.StringVar(&config.BaselineFile, "baseline", "", "Baseline of acknowledged risks (default: "+baseline.DefaultFileName+" in the analyzed repository)")
+	generateCmd.BoolVar(&config.WriteBaseline, "write-baseline", false, "Acknowledge every current risk, finding and secret in the baseline file")
+	generateCmd.StringVar(&config.CatalogInfo, "catalog-info", "", "Create or update this Backstage catalog-info.yaml from the analysis")
+	generateCmd.StringVar(&config.Readme, "readme", "", "Create this README, or refresh the sections codedoc manages in it, from the analysis")
+	generateCmd.StringVar(&config.CycloneDX, "cyclonedx", "", "Export detected endpoints and pinned dependencies as a CycloneDX JSON BOM to this file")
+	generateCmd.StringVar(&config.EmitTables, "emit-tables", "", "Write endpoints, models, modules, dependencies and risks as CSV files to this directory")
+	generateCmd.StringVar(&config.TablesFormat, "tables-format", defaults.TablesFormat, "Format of --emit-tables files: csv or tsv")
+	generateCmd.StringVar(&config.EmitSQLite, "emit-sqlite", "", "Write files, symbols, endpoints, models, dependencies and summaries to this SQLite database")
+	generateCmd.StringVar(&config.EmitEditor, "emit-editor", "", "Write one-line file summaries and module descriptions as compact JSON for editor extensions to this file")
+	generateCmd.StringVar(&config.SaveIndex, "save-index", "", "Save the scan, detections, summaries and embeddings to this file for ask, diff, serve and render")
+	generateCmd.BoolVar(&config.Audit, "audit", false, "Check pinned dependencies for known vulnerabilities via OSV.dev")
+	generateCmd.BoolVar(&config.Glossary, "glossary", false, "Append a glossary of abbreviations and domain terms mined from identifiers")
+	generateCmd.BoolVar(&config.Decompose, "decompose", false, "Suggest module boundaries from clusters in the import graph")
+	generateCmd.StringVar(&config.Embeddings, "embeddings", "", "Cluster file summaries into functional areas with embeddings from anthropic (Voyage AI), openai or local")
+	generateCmd.BoolVar(&config.GitHistory, "git-history", false, "Analyze the last year of git history for hotspots and ownership")
+	generateCmd.BoolVar(&config.Quiet, "quiet", false, "Print nothing but errors")
+	generateCmd.BoolVar(&config.Verbose, "verbose", false, "Print every file as it is scanned, analyzed and summarized")
+	generateCmd.StringVar(&config.LogLevel, "log-level", "warn", "Diagnostic log level: "+strings.Join(logging.Levels, ", "))
+	generateCmd.BoolVar(&config.LogJSON, "log-json", false, "Write diagnostic logs as JSON lines")
+	generateCmd.BoolVar(&config.ReadOnlySource, "read-only-source", false, "Fail instead of writing any file under the analyzed paths")
+
+	generateCmd.BoolVar(&config.SaveProfile, "save-profile", false, "Save the modules, priority files, suppressed risks and guidance in effect to .codedoc/profile.yaml for later runs")
+	var modules, priorityFiles, suppressRisks string
+	var guidance pathList
+	generateCmd.StringVar(&modules, "modules", "", "Comma-separated directories to summarize as key modules instead of the ones picked automatically")
+	generateCmd.StringVar(&priorityFiles, "priority-files", "", "Comma-separated files to summarize before any other top file")
+	generateCmd.StringVar(&suppressRisks, "suppress-risks", "", "Comma-separated risk rule IDs to leave out of the report")
+	generateCmd.Var(&guidance, "guidance", "Extra prompt guidance for one summary type, as type=text (repeatable), e.g. architecture=\"Focus on the event pipeline\"")
+
+	var internalPrefixes, orgPaths string
+	generateCmd.StringVar(&internalPrefixes, "internal-prefix", "", "Comma-separated internal module prefixes (e.g. github.com/acme/*)")
+	generateCmd.StringVar(&orgPaths, "org-paths", "", "Comma-separated sibling repositories to search for consumers of this repo")
+
+	langDefault := strings.Join(defaults.Languages, ",")
+	langUsage := "Comma-separated list of languages to analyze"
+	var langString string
+	generateCmd.StringVar(&langString, "lang", langDefault, langUsage)
+
+	return generateCmd, func() *Config {
+		config.Paths = paths
+		if len(paths) > 0 {
+			config.Path = paths[0]
+		}
+		config.ToolVersion = version
+		config.Languages = parseLanguages(langString)
+		config.InternalPrefix = splitAndTrim(internalPrefixes, ",")
+		config.OrgPaths = splitAndTrim(orgPaths, ",")
+		config.Profile.Modules = splitAndTrim(modules, ",")
+		config.Profile.PriorityFiles = splitAndTrim(priorityFiles, ",")
+		config.Profile.SuppressedRisks = splitAndTrim(suppressRisks, ",")
+		for _, value := range guidance {
+			if config.Profile.Prompts == nil {
+				config.Profile.Prompts = map[string]string{}
+			}
+			summaryType, text, _ := strings.Cut(value, "=")
+			config.Profile.Prompts[strings.TrimSpace(summaryType)] = strings.TrimSpace(text)
+		}
+
+		config.Flags = make(map[string]string)
+		generateCmd.Visit(func(f *flag.Flag) {
+			config.Flags[f.Name] = f.Value.String()
+		})
+
+		if _, explicit := config.Flags["out"]; !explicit && config.Format != report.FormatMarkdown {
+			config.OutputFile = strings.TrimSuffix(config.OutputFile, filepath.Ext(config.OutputFile)) + report.FormatExtension(config.Format)
+		}
+
+		if config.OutDir != "" {
+			config.OutputFile = filepath.Join(config.OutDir, "index"+report.FormatExtension(config.Format))
+		}
+
+		if config.OutputDir != "" {
+			config.OutputFile = inOutputDir(config.OutputDir, config.OutputFile)
+			if config.JSONOutputFile != "" {
+				config.JSONOutputFile = inOutputDir(config.OutputDir, config.JSONOutputFile)
+			}
+			if config.CatalogInfo != "" {
+				config.CatalogInfo = inOutputDir(config.OutputDir, config.CatalogInfo)
+			}
+			if config.Readme != "" {
+				config.Readme = inOutputDir(config.OutputDir, config.Readme)
+			}
+			if config.CycloneDX != "" {
+				config.CycloneDX = inOutputDir(config.OutputDir, config.CycloneDX)
+			}
+			if config.EmitTables != "" {
+				config.EmitTables = inOutputDir(config.OutputDir, config.EmitTables)
+			}
+			if config.EmitSQLite != "" {
+				config.EmitSQLite = inOutputDir(config.OutputDir, config.EmitSQLite)
+			}
+			if config.EmitEditor != "" {
+				config.EmitEditor = inOutputDir(config.OutputDir, config.EmitEditor)
+			}
+			if config.SaveIndex != "" {
+				config.SaveIndex = inOutputDir(config.OutputDir, config.SaveIndex)
+			}
+		}
+
+		return config
+	}
+}
+
+// inOutputDir places a relative artifact path inside dir. Manifests,
+// signatures and per-project or per-owner reports are derived from --out and
+// --json-out, so they follow.
+func inOutputDir(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// pathList collects a repeatable string flag.
+type pathList []string
+
+func (p *pathList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *pathList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
 }
 
 func parseLanguages(langString string) []string {
 	if langString == "" {
-		return []string{"go", "py", "ts", "js", "md", "yaml", "dockerfile"}
+		return codedoc.DefaultLanguages()
 	}
 
 	languages := []string{}
@@ -173,122 +458,43 @@ func stringTrim(s string) string {
 }
 
 func validateConfig(config *Config) error {
-	if config.Path == "" && config.RepoURL == "" {
-		return fmt.Errorf("either --path or --repo-url must be specified")
-	}
-
-	if config.Path != "" && config.RepoURL != "" {
-		return fmt.Errorf("cannot specify both --path and --repo-url")
+	if err := config.Validate(); err != nil {
+		return err
 	}
 
-	if config.MaxFiles <= 0 {
-		return fmt.Errorf("--max-files must be positive")
+	if config.Quiet && config.Verbose {
+		return fmt.Errorf("cannot specify both --quiet and --verbose")
 	}
 
-	if config.MaxLinesPerFile <= 0 {
-		return fmt.Errorf("--max-lines-per-file must be positive")
+	if _, err := logging.ParseLevel(config.LogLevel); err != nil {
+		return fmt.Errorf("--log-level: %w", err)
 	}
 
 	return nil
 }
 
-func runGenerate(ctx context.Context, config *Config) error {
-	startTime := time.Now()
-
-	repoPath := config.Path
-
-	if config.RepoURL != "" {
-		clonedPath, cleanupFunc, err := cloneRepository(config.RepoURL)
-		if err != nil {
-			return fmt.Errorf("failed to clone repository: %w", err)
-		}
-		defer cleanupFunc()
-		repoPath = clonedPath
-	}
-
-	fmt.Printf("Analyzing repository: %s\n", repoPath)
-
-	scanOpts := scanner.Options{
-		Path:         repoPath,
-		MaxFiles:     config.MaxFiles,
-		IncludeTests: config.IncludeTests,
-		Languages:    config.Languages,
-	}
-
-	scanResult, err := scanner.Scan(ctx, scanOpts)
-	if err != nil {
-		return fmt.Errorf("scan failed: %w", err)
-	}
-
-	fmt.Printf("Scanned %d files (%d lines)\n", len(scanResult.Files), scanResult.TotalLines)
-
-	detectOpts := detect.Options{
-		Files: scanResult.Files,
-	}
-
-	detectionResult, err := detect.Detect(ctx, detectOpts)
-	if err != nil {
-		return fmt.Errorf("detection failed: %w", err)
-	}
-
-	var llmProvider llm.Provider
-	if !config.DryRun {
-		llmProvider, err = llm.NewAnthropicProvider(llm.AnthropicConfig{
-			CacheDir: filepath.Join(repoPath, ".codedoc-cache"),
-			Force:    config.Force,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create LLM provider: %w", err)
-		}
-	}
-
-	summarizeOpts := summarize.Options{
-		ScanResult:      scanResult,
-		DetectionResult: detectionResult,
-		MaxLinesPerFile: config.MaxLinesPerFile,
-		LLMProvider:     llmProvider,
-		RedactSecrets:   config.RedactSecrets,
+func (c *Config) progressLevel() progress.Level {
+	switch {
+	case c.Quiet:
+		return progress.Quiet
+	case c.Verbose:
+		return progress.Verbose
 	}
-
-	summaries, err := summarize.Summarize(ctx, summarizeOpts)
-	if err != nil {
-		return fmt.Errorf("summarization failed: %w", err)
-	}
-
-	reportOpts := report.Options{
-		RepoPath:        repoPath,
-		RepoURL:         config.RepoURL,
-		ScanResult:      scanResult,
-		DetectionResult: detectionResult,
-		Summaries:       summaries,
-		OutputFile:      config.OutputFile,
-	}
-
-	if err := report.Generate(ctx, reportOpts); err != nil {
-		return fmt.Errorf("report generation failed: %w", err)
-	}
-
-	elapsed := time.Since(startTime)
-	fmt.Printf("\nReport generated: %s\n", config.OutputFile)
-	fmt.Printf("Time elapsed: %s\n", elapsed.Round(time.Second))
-
-	return nil
+	return progress.Normal
 }
 
-func cloneRepository(repoURL string) (string, func(), error) {
-	tempDir, err := os.MkdirTemp("", "codedoc-*")
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to create temp dir: %w", err)
+func runGenerate(ctx context.Context, config *Config, reporter *progress.Reporter) error {
+	config.Progress = reporter
+	if config.Interactive {
+		config.Config.Review = newReviewer().review
 	}
 
-	cleanupFunc := func() {
-		os.RemoveAll(tempDir)
+	if _, err := codedoc.Run(ctx, config.Config); err != nil {
+		return err
 	}
 
-	if err := util.GitCloneShallow(repoURL, tempDir); err != nil {
-		cleanupFunc()
-		return "", nil, err
+	if config.CheckUpdate {
+		printUpdateNotice(ctx)
 	}
-
-	return tempDir, cleanupFunc, nil
+	return nil
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codepigeon/codedoc/pkg/codedoc"
+)
+
+// runPlan prints the pre-flight of a generate run with the same flags,
+// without running it.
+func runPlan(ctx context.Context, args []string) error {
+	planCmd, parsed := newGenerateFlags(flag.ExitOnError)
+	asJSON := planCmd.Bool("json", false, "Print the plan as JSON")
+
+	if err := planCmd.Parse(args); err != nil {
+		return err
+	}
+	if planCmd.NArg() > 0 {
+		return fmt.Errorf("usage: codedoc plan [--json] [generate flags]")
+	}
+	config := parsed()
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	plan, err := codedoc.Preflight(ctx, config.Config)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	}
+
+	fmt.Printf("Files: %d (%d lines, %.1f MB), %d tests\n", plan.Files, plan.Lines, float64(plan.Bytes)/(1<<20), plan.Tests)
+	fmt.Println("\nLanguages")
+	for _, language := range plan.Languages {
+		share := ""
+		if language.Code {
+			share = fmt.Sprintf("%5.1f%%", language.Percentage)
+		}
+		analyzed := ""
+		if language.Analyzed {
+			analyzed = "analyzed"
+		}
+		fmt.Printf("  %-12s %6d files %8d lines %6s  %s\n", language.Name, language.Files, language.Lines, share, analyzed)
+	}
+
+	fmt.Printf("\nScanned: %d of %d matching files (--max-files %d)\n", plan.Scanned, plan.Selected, config.MaxFiles)
+	fmt.Printf("Estimate: %s (%s, %d input and %d output tokens, empty cache)\n",
+		plan.Estimate, plan.Estimate.Model, plan.Estimate.InputTokens, plan.Estimate.OutputTokens)
+	if len(plan.Recommendations) == 0 {
+		fmt.Println("\nNo flag changes recommended.")
+		return nil
+	}
+
+	fmt.Println("\nRecommended flags")
+	args = []string{}
+	for _, recommendation := range plan.Recommendations {
+		fmt.Printf("  %s\n      %s\n", recommendation.Arg(), recommendation.Reason)
+		args = append(args, recommendation.Arg())
+	}
+	fmt.Printf("\nWith them: %s\n", plan.Recommended)
+	fmt.Printf("  codedoc generate [flags] %s\n", strings.Join(args, " "))
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/codepigeon/codedoc/internal/progress"
+	"github.com/codepigeon/codedoc/pkg/codedoc"
+)
+
+// runRender writes the report of a --save-index file again, taking the
+// output flags of generate.
+func runRender(ctx context.Context, args []string) error {
+	renderCmd, parsed := newGenerateFlags(flag.ExitOnError)
+	indexFile := renderCmd.String("index", "", "Index written by generate --save-index")
+
+	if err := renderCmd.Parse(args); err != nil {
+		return err
+	}
+	if *indexFile == "" || renderCmd.NArg() > 0 {
+		return fmt.Errorf("usage: codedoc render --index index.codedoc [--out file] [--format format] [--output-lang lang]")
+	}
+	// Render validates the rest once it knows the indexed path.
+	config := parsed()
+	if config.Quiet && config.Verbose {
+		return fmt.Errorf("cannot specify both --quiet and --verbose")
+	}
+	config.Progress = progress.New(os.Stderr, config.progressLevel())
+
+	_, err := codedoc.Render(ctx, *indexFile, config.Config)
+	return err
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/feedback"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+var errReviewAborted = errors.New("review aborted; no report was written")
+
+// reviewer walks through generated summaries in the terminal, letting the
+// user accept, rate, edit or regenerate each one before the report is written.
+type reviewer struct {
+	in     *bufio.Reader
+	out    io.Writer
+	editor string
+}
+
+func newReviewer() *reviewer {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	return &reviewer{in: bufio.NewReader(os.Stdin), out: os.Stdout, editor: editor}
+}
+
+func (r *reviewer) review(ctx context.Context, opts summarize.Options, result *summarize.Result) error {
+	items := summarize.ReviewItems(result)
+	fmt.Fprintf(r.out, "\nReviewing %d summaries.\n", len(items))
+
+	for i, item := range items {
+		text := item.Text
+		for {
+			fmt.Fprintf(r.out, "\n[%d/%d] %s\n%s\n%s\n", i+1, len(items), item.Title(), strings.Repeat("─", 60), text)
+			answer, err := r.prompt("\n[a]ccept  mark [g]ood  mark [b]ad  [e]dit  [r]egenerate  accept [A]ll  [q]uit: ")
+			if err != nil {
+				return err
+			}
+
+			switch answer {
+			case "a", "":
+			case "g":
+				opts.Feedback.Record(item.Feedback(opts, feedback.Good, text, "", ""))
+			case "b":
+				note, err := r.prompt("What is wrong with it? (optional): ")
+				if err != nil {
+					return err
+				}
+				edited, err := r.edit(text)
+				if err != nil {
+					fmt.Fprintf(r.out, "Edit failed: %v\n", err)
+					edited = text
+				}
+				correction := ""
+				if edited = strings.TrimSpace(edited); edited != "" && edited != strings.TrimSpace(item.Text) {
+					correction = edited
+					text = edited
+				}
+				opts.Feedback.Record(item.Feedback(opts, feedback.Bad, item.Text, correction, note))
+				fmt.Fprintln(r.out, "Feedback recorded; later runs will learn from it.")
+				continue
+			case "A":
+				result.Apply(item, text)
+				fmt.Fprintf(r.out, "Accepted the remaining %d summaries.\n", len(items)-i)
+				return nil
+			case "e":
+				edited, err := r.edit(text)
+				if err != nil {
+					fmt.Fprintf(r.out, "Edit failed: %v\n", err)
+				} else {
+					text = edited
+				}
+				continue
+			case "r":
+				guidance, err := r.prompt("Guidance for the model (optional): ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(r.out, "Regenerating...")
+				regenerated, err := summarize.Regenerate(ctx, opts, result, item, guidance)
+				if err != nil {
+					fmt.Fprintf(r.out, "Regeneration failed: %v\n", err)
+				} else {
+					text = regenerated
+				}
+				continue
+			case "q":
+				return errReviewAborted
+			default:
+				fmt.Fprintf(r.out, "Unknown choice %q.\n", answer)
+				continue
+			}
+			break
+		}
+		result.Apply(item, text)
+	}
+	return nil
+}
+
+func (r *reviewer) prompt(question string) (string, error) {
+	fmt.Fprint(r.out, question)
+	line, err := r.in.ReadString('\n')
+	switch {
+	case err == io.EOF && line == "":
+		return "", errReviewAborted
+	case err != nil && err != io.EOF:
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// edit opens text in $VISUAL or $EDITOR, or reads replacement lines from the
+// terminal up to a lone "." when neither is set.
+func (r *reviewer) edit(text string) (string, error) {
+	if r.editor == "" {
+		fmt.Fprintln(r.out, "Enter the new text; finish with a line containing only \".\":")
+		var lines []string
+		for {
+			line, err := r.in.ReadString('\n')
+			trimmed := strings.TrimRight(line, "\r\n")
+			if trimmed == "." {
+				break
+			}
+			lines = append(lines, trimmed)
+			if err != nil {
+				break
+			}
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+
+	file, err := os.CreateTemp("", "codedoc-review-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(text + "\n"); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(r.editor)
+	cmd := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w", r.editor, err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(edited)), nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/codepigeon/codedoc/internal/update"
+)
+
+func runSelfUpdate(ctx context.Context, args []string) error {
+	selfUpdateCmd := flag.NewFlagSet("self-update", flag.ExitOnError)
+	force := selfUpdateCmd.Bool("force", false, "Reinstall even if already on the latest version")
+	if err := selfUpdateCmd.Parse(args); err != nil {
+		return err
+	}
+
+	fmt.Printf("Current version: %s\n", version)
+
+	result, err := update.SelfUpdate(ctx, update.Options{
+		CurrentVersion: version,
+		Force:          *force,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !result.Updated {
+		fmt.Printf("Already up to date (latest release: %s)\n", result.NewVersion)
+		return nil
+	}
+
+	fmt.Printf("Updated %s to %s (checksum verified)\n", result.BinaryPath, result.NewVersion)
+	return nil
+}
+
+func printUpdateNotice(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	release, newer, err := update.CheckForUpdate(ctx, version)
+	if err != nil || !newer {
+		return
+	}
+
+	fmt.Printf("\nA new version of codedoc is available: %s (current %s)\n", release.Version, version)
+	fmt.Println("Run 'codedoc self-update' to install it.")
+}
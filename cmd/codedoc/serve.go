@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/index"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/logging"
+	"github.com/codepigeon/codedoc/internal/profile"
+	"github.com/codepigeon/codedoc/internal/progress"
+	"github.com/codepigeon/codedoc/internal/rpc"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+	"github.com/codepigeon/codedoc/internal/util"
+	"github.com/codepigeon/codedoc/pkg/codedoc"
+)
+
+// scanParams select the files of the scan and detect methods.
+type scanParams struct {
+	Path         string   `json:"path"`
+	MaxFiles     int      `json:"maxFiles"`
+	IncludeTests bool     `json:"includeTests"`
+	Languages    []string `json:"languages"`
+}
+
+type summarizeFileParams struct {
+	Path string `json:"path"`
+	File string `json:"file"`
+	// PartialResultToken, when set, streams the summary as it is written in
+	// $/progress notifications carrying the token, the way the Language
+	// Server Protocol streams partial results.
+	PartialResultToken json.RawMessage `json:"partialResultToken,omitempty"`
+}
+
+// progressParams are the params of a $/progress notification.
+type progressParams struct {
+	Token json.RawMessage `json:"token"`
+	Value partialSummary  `json:"value"`
+}
+
+// partialSummary is the next piece of a summary being written; type is
+// file for the summary and function for the key functions.
+type partialSummary struct {
+	Type llm.SummaryType `json:"type"`
+	Text string          `json:"text"`
+}
+
+type reportParams struct {
+	// Args are generate flags, such as ["--path", "repo", "--out", "DOCS.md"].
+	Args []string `json:"args"`
+}
+
+type reportResult struct {
+	OutputFile     string   `json:"outputFile"`
+	JSONOutputFile string   `json:"jsonOutputFile,omitempty"`
+	Projects       []string `json:"projects,omitempty"`
+}
+
+// server keeps one LLM provider for the life of the process, so summaries
+// share its cache and rate limit across requests.
+type server struct {
+	cacheDir string
+	dryRun   bool
+	// conn sends notifications to the client.
+	conn *rpc.Server
+
+	// index, from --index, answers requests about its repository, at the
+	// absolute path indexRoot, without scanning or calling the LLM.
+	index     *index.Index
+	indexRoot string
+	// indexed are the index's files by slash path.
+	indexed map[string]scanner.FileInfo
+
+	providerOnce sync.Once
+	provider     llm.Provider
+	providerErr  error
+}
+
+func runServe(ctx context.Context, args []string) error {
+	serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
+	cacheDir := serveCmd.String("cache-dir", util.DefaultCacheDir(), "Cache directory for LLM responses")
+	dryRun := serveCmd.Bool("dry-run", false, "Answer summarizeFile without calling the LLM")
+	logLevel := serveCmd.String("log-level", "info", "Diagnostic log level: "+strings.Join(logging.Levels, ", "))
+	logJSON := serveCmd.Bool("log-json", false, "Write diagnostic logs as JSON lines")
+	indexFile := serveCmd.String("index", "", "Answer scan, detect and summarizeFile for the indexed repository from this --save-index file")
+
+	if err := serveCmd.Parse(args); err != nil {
+		return err
+	}
+
+	// Stdout carries the protocol; everything else goes to stderr.
+	logger, err := logging.New(progress.New(os.Stderr, progress.Normal), *logLevel, *logJSON)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	s := &server{cacheDir: *cacheDir, dryRun: *dryRun}
+	if *indexFile != "" {
+		if err := s.loadIndex(*indexFile); err != nil {
+			return err
+		}
+	}
+	rpcServer := rpc.NewServer()
+	s.conn = rpcServer
+	rpcServer.Handle("scan", s.scan)
+	rpcServer.Handle("detect", s.detect)
+	rpcServer.Handle("summarizeFile", s.summarizeFile)
+	rpcServer.Handle("report", s.report)
+
+	slog.Info("Serving JSON-RPC on stdin and stdout")
+	return rpcServer.Serve(ctx, os.Stdin, os.Stdout)
+}
+
+func (s *server) loadIndex(path string) error {
+	idx, err := index.Load(path)
+	if err != nil {
+		return err
+	}
+	root, err := filepath.Abs(idx.RepoPath)
+	if err != nil {
+		return err
+	}
+	s.index, s.indexRoot = idx, root
+	s.indexed = map[string]scanner.FileInfo{}
+	for _, file := range idx.Scan.Files {
+		s.indexed[filepath.ToSlash(file.RelativePath)] = file
+	}
+	slog.Info("Loaded index", "path", path, "repository", root, "files", len(idx.Scan.Files))
+	return nil
+}
+
+// fromIndex reports whether requests about repoPath are answered from the
+// index.
+func (s *server) fromIndex(repoPath string) bool {
+	if s.index == nil {
+		return false
+	}
+	abs, err := filepath.Abs(repoPath)
+	return err == nil && abs == s.indexRoot
+}
+
+func (s *server) scanRepo(ctx context.Context, raw json.RawMessage) (*scanner.Result, error) {
+	params := scanParams{Path: ".", MaxFiles: 5000}
+	if err := rpc.Decode(raw, &params); err != nil {
+		return nil, err
+	}
+	if s.fromIndex(params.Path) {
+		return s.index.Scan, nil
+	}
+	result, err := scanner.Scan(ctx, scanner.Options{
+		Path:         params.Path,
+		MaxFiles:     params.MaxFiles,
+		IncludeTests: params.IncludeTests,
+		Languages:    params.Languages,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan failed: %w", err)
+	}
+	return result, nil
+}
+
+func (s *server) scan(ctx context.Context, raw json.RawMessage) (any, error) {
+	return s.scanRepo(ctx, raw)
+}
+
+func (s *server) detect(ctx context.Context, raw json.RawMessage) (any, error) {
+	scanResult, err := s.scanRepo(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	if s.index != nil && scanResult == s.index.Scan && s.index.Detection != nil {
+		return s.index.Detection, nil
+	}
+	result, err := detect.Detect(ctx, detect.Options{Files: scanResult.Files})
+	if err != nil {
+		return nil, fmt.Errorf("detection failed: %w", err)
+	}
+	return result, nil
+}
+
+func (s *server) summarizeFile(ctx context.Context, raw json.RawMessage) (any, error) {
+	params := summarizeFileParams{Path: "."}
+	if err := rpc.Decode(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.File == "" {
+		return nil, rpc.Errorf(rpc.CodeInvalidParams, "file is required")
+	}
+	target, err := relativeTarget(params.Path, params.File)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(target, "../") || target == ".." {
+		return nil, rpc.Errorf(rpc.CodeInvalidParams, "%s is outside %s", params.File, params.Path)
+	}
+
+	file, err := scanner.ScanFile(scanner.Options{Path: params.Path}, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", target, err)
+	}
+	// Files unchanged since the index keep its summary.
+	if s.fromIndex(params.Path) && s.index.Summaries != nil {
+		if indexed, ok := s.indexed[target]; ok && indexed.Hash == file.Hash {
+			if summary, ok := s.index.Summaries.FileSummaries[indexed.RelativePath]; ok {
+				return summary, nil
+			}
+		}
+	}
+	provider, err := s.llmProvider()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := profile.Load(profile.Path(params.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	opts := summarize.Options{
+		MaxLinesPerFile: codedoc.DefaultConfig("").MaxLinesPerFile,
+		LLMProvider:     provider,
+		RedactSecrets:   true,
+	}
+	if len(saved.Prompts) > 0 {
+		opts.Middleware = append(opts.Middleware, summarize.TransformRequests(saved.Guide))
+	}
+	if len(params.PartialResultToken) > 0 {
+		ctx = llm.WithStream(ctx, func(summaryType llm.SummaryType, text string) {
+			progress := progressParams{Token: params.PartialResultToken, Value: partialSummary{Type: summaryType, Text: text}}
+			if err := s.conn.Notify("$/progress", progress); err != nil {
+				slog.WarnContext(ctx, "failed to send partial summary", "err", err)
+			}
+		})
+	}
+	return summarize.SummarizeFile(ctx, opts, *file)
+}
+
+func (s *server) report(ctx context.Context, raw json.RawMessage) (any, error) {
+	var params reportParams
+	if err := rpc.Decode(raw, &params); err != nil {
+		return nil, err
+	}
+	config, err := parseGenerateArgs(params.Args)
+	if err != nil {
+		return nil, rpc.Errorf(rpc.CodeInvalidParams, "invalid generate flags: %v", err)
+	}
+	config.Progress = progress.New(os.Stderr, progress.Quiet)
+
+	generated, err := codedoc.Run(ctx, config.Config)
+	if err != nil {
+		return nil, err
+	}
+	result := reportResult{OutputFile: generated.OutputFile, JSONOutputFile: generated.JSONOutputFile}
+	for _, project := range generated.Projects {
+		result.Projects = append(result.Projects, project.OutputFile)
+	}
+	return result, nil
+}
+
+// llmProvider creates the provider on first use, so scan and detect work
+// without an API key.
+func (s *server) llmProvider() (llm.Provider, error) {
+	s.providerOnce.Do(func() {
+		if s.dryRun {
+			s.provider = llm.NewNoOpProvider()
+			return
+		}
+		s.provider, s.providerErr = llm.NewAnthropicProvider(llm.AnthropicConfig{CacheDir: s.cacheDir})
+		if s.providerErr != nil {
+			s.providerErr = fmt.Errorf("failed to create LLM provider: %w", s.providerErr)
+		}
+	})
+	return s.provider, s.providerErr
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+// fakeAPI answers every Messages API request with the same summary.
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (f *fakeAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.requests++
+	f.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"content":[{"text":"Handles orders."}]}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestSummarizeFileConcurrently(t *testing.T) {
+	repo := t.TempDir()
+	const files = 8
+	for i := range files {
+		source := fmt.Sprintf("package shop\n\nfunc Order%d() {}\n", i)
+		if err := os.WriteFile(filepath.Join(repo, fmt.Sprintf("order%d.go", i)), []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api := &fakeAPI{}
+	provider, err := llm.NewAnthropicProvider(llm.AnthropicConfig{
+		APIKey:     "test",
+		CacheDir:   t.TempDir(),
+		MaxQPS:     1000,
+		HTTPClient: &http.Client{Transport: api},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{}
+	s.providerOnce.Do(func() { s.provider = provider })
+
+	// serve answers each request in its own goroutine, sharing the provider.
+	var wg sync.WaitGroup
+	for i := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			params, _ := json.Marshal(summarizeFileParams{Path: repo, File: fmt.Sprintf("order%d.go", i)})
+			result, err := s.summarizeFile(context.Background(), params)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if summary := result.(summarize.FileSummary); summary.Summary != "Handles orders." {
+				t.Errorf("summarizeFile(order%d.go) = %q", i, summary.Summary)
+			}
+		}()
+	}
+	wg.Wait()
+	if api.requests < files {
+		t.Errorf("Expected at least %d API requests, got %d", files, api.requests)
+	}
+}
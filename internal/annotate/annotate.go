@@ -0,0 +1,123 @@
+package annotate
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// DocFile is the file a Go package comment is written to.
+const DocFile = "doc.go"
+
+// Target is a Go package without a package comment, or a Python module
+// without a docstring.
+type Target struct {
+	Language string `json:"language"`
+	// Name is the Go package name or the dotted Python module name.
+	Name string `json:"name"`
+	// File is the slash path, relative to the repository, the comment goes
+	// in: the package's doc.go, or the module itself.
+	File string `json:"file"`
+	// Files are the relative paths of the files documented.
+	Files []string `json:"files"`
+}
+
+// Command reports whether the target is a Go main package, whose comment
+// describes the command rather than the package.
+func (t Target) Command() bool {
+	return t.Language == "go" && t.Name == "main"
+}
+
+// Find lists the undocumented Go packages and Python modules among files,
+// tests excluded, read from root.
+func Find(root string, files []scanner.FileInfo) ([]Target, error) {
+	packages := map[string][]scanner.FileInfo{}
+	targets := []Target{}
+	for _, file := range files {
+		if file.IsTest {
+			continue
+		}
+		// go.mod and the like share their language with the code.
+		switch filepath.Ext(file.RelativePath) {
+		case ".go":
+			dir := path.Dir(filepath.ToSlash(file.RelativePath))
+			packages[dir] = append(packages[dir], file)
+		case ".py":
+			content, err := os.ReadFile(filepath.Join(root, file.RelativePath))
+			if err != nil {
+				return nil, err
+			}
+			if _, found := pythonDocstring(strings.Split(string(content), "\n")); !found {
+				continue
+			}
+			targets = append(targets, Target{
+				Language: "python",
+				Name:     pythonModule(filepath.ToSlash(file.RelativePath)),
+				File:     filepath.ToSlash(file.RelativePath),
+				Files:    []string{file.RelativePath},
+			})
+		}
+	}
+
+	for dir, files := range packages {
+		target, err := goPackage(root, dir, files)
+		if err != nil {
+			return nil, err
+		}
+		if target != nil {
+			targets = append(targets, *target)
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].File < targets[j].File })
+	return targets, nil
+}
+
+// goPackage returns the target of the package in dir, or nil when one of
+// its files has a package comment.
+func goPackage(root, dir string, files []scanner.FileInfo) (*Target, error) {
+	target := &Target{Language: "go", File: path.Join(dir, DocFile)}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, filepath.Join(root, file.RelativePath), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.RelativePath, err)
+		}
+		if f.Doc != nil {
+			return nil, nil
+		}
+		if target.Name == "" {
+			target.Name = f.Name.Name
+		}
+		target.Files = append(target.Files, file.RelativePath)
+	}
+	return target, nil
+}
+
+// pythonDocstring returns the index of the first statement of a Python
+// module, and whether the module needs a docstring there: it has code and
+// that code does not start with a string literal.
+func pythonDocstring(lines []string) (int, bool) {
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimLeft(line, "rRuUbB")
+		return i, !strings.HasPrefix(line, `"`) && !strings.HasPrefix(line, "'")
+	}
+	return 0, false
+}
+
+func pythonModule(file string) string {
+	module := strings.TrimSuffix(file, ".py")
+	module = strings.TrimSuffix(module, "/__init__")
+	return strings.ReplaceAll(module, "/", ".")
+}
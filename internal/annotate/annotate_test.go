@@ -0,0 +1,142 @@
+package annotate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func writeFiles(t *testing.T, files map[string]string) (string, []scanner.FileInfo) {
+	t.Helper()
+	root := t.TempDir()
+	infos := []scanner.FileInfo{}
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		language := map[string]string{".go": "go", ".mod": "go", ".py": "python"}[filepath.Ext(rel)]
+		infos = append(infos, scanner.FileInfo{
+			Path:         path,
+			RelativePath: filepath.FromSlash(rel),
+			Language:     language,
+			IsTest:       strings.HasSuffix(rel, "_test.go"),
+		})
+	}
+	return root, infos
+}
+
+func TestFind(t *testing.T) {
+	root, files := writeFiles(t, map[string]string{
+		"go.mod":                "module shop\n",
+		"cart/cart.go":          "package cart\n\nfunc Total() int { return 0 }\n",
+		"cart/cart_test.go":     "// Package cart tests.\npackage cart\n",
+		"pay/pay.go":            "// Package pay charges cards.\npackage pay\n",
+		"pay/refund.go":         "package pay\n",
+		"cmd/shop/main.go":      "// Copyright 2026 Shop.\n\npackage main\n",
+		"shop/__init__.py":      "",
+		"shop/orders.py":        "#!/usr/bin/env python\n# Orders.\n\nimport os\n",
+		"shop/documented.py":    "r'''Documented.'''\nimport os\n",
+		"shop/comment_only.py":  "# nothing here\n",
+		"shop/double_quoted.py": "\"\"\"Documented.\"\"\"\n",
+	})
+
+	targets, err := Find(root, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Target{
+		{Language: "go", Name: "cart", File: "cart/doc.go", Files: []string{filepath.FromSlash("cart/cart.go")}},
+		{Language: "go", Name: "main", File: "cmd/shop/doc.go", Files: []string{filepath.FromSlash("cmd/shop/main.go")}},
+		{Language: "python", Name: "shop.orders", File: "shop/orders.py", Files: []string{filepath.FromSlash("shop/orders.py")}},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets = %+v, want %+v", targets, want)
+	}
+	if !targets[1].Command() || targets[0].Command() {
+		t.Error("only package main is a command")
+	}
+}
+
+func TestPatch(t *testing.T) {
+	root, _ := writeFiles(t, map[string]string{
+		"cart/cart.go":   "package cart\n",
+		"pay/doc.go":     "//go:build !tiny\n\npackage pay",
+		"shop/orders.py": "#!/usr/bin/env python\n# Orders.\n\nimport os\nimport sys\n",
+	})
+	long := "Package cart keeps the items a shopper has chosen, with their quantities and prices, until checkout."
+
+	tests := []struct {
+		name    string
+		target  Target
+		comment string
+		content string
+		diff    string
+	}{
+		{
+			name:    "new doc.go",
+			target:  Target{Language: "go", Name: "cart", File: "cart/doc.go"},
+			comment: long + "\n\nIt is safe\nfor concurrent use.",
+			content: "// Package cart keeps the items a shopper has chosen, with their quantities and\n" +
+				"// prices, until checkout.\n//\n// It is safe for concurrent use.\npackage cart\n",
+			diff: "--- /dev/null\n+++ b/cart/doc.go\n@@ -0,0 +1,5 @@\n" +
+				"+// Package cart keeps the items a shopper has chosen, with their quantities and\n" +
+				"+// prices, until checkout.\n+//\n+// It is safe for concurrent use.\n+package cart\n",
+		},
+		{
+			name:    "existing doc.go",
+			target:  Target{Language: "go", Name: "pay", File: "pay/doc.go"},
+			comment: "Package pay charges cards.",
+			content: "//go:build !tiny\n\n// Package pay charges cards.\npackage pay",
+			diff: "--- a/pay/doc.go\n+++ b/pay/doc.go\n@@ -1,3 +1,4 @@\n" +
+				" //go:build !tiny\n \n+// Package pay charges cards.\n package pay\n\\ No newline at end of file\n",
+		},
+		{
+			name:    "python module",
+			target:  Target{Language: "python", Name: "shop.orders", File: "shop/orders.py"},
+			comment: "Order placement and \"\"\"tracking\"\"\".",
+			content: "#!/usr/bin/env python\n# Orders.\n\n\"\"\"Order placement and \\\"\\\"\\\"tracking\\\"\\\"\\\".\"\"\"\n\nimport os\nimport sys\n",
+			diff: "--- a/shop/orders.py\n+++ b/shop/orders.py\n@@ -1,5 +1,7 @@\n" +
+				" #!/usr/bin/env python\n # Orders.\n \n+\"\"\"Order placement and \\\"\\\"\\\"tracking\\\"\\\"\\\".\"\"\"\n+\n import os\n import sys\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := NewPatch(root, tt.target, tt.comment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := patch.Content(); got != tt.content {
+				t.Errorf("content =\n%s\nwant\n%s", got, tt.content)
+			}
+			if got := patch.Diff(); got != tt.diff {
+				t.Errorf("diff =\n%s\nwant\n%s", got, tt.diff)
+			}
+		})
+	}
+
+	if _, err := NewPatch(root, Target{Language: "go", Name: "cart", File: "cart/doc.go"}, " \n"); err == nil {
+		t.Error("empty comment accepted")
+	}
+}
+
+func TestPythonComment(t *testing.T) {
+	comment := pythonComment("Orders.\n\nPlaces orders, tracks their shipment and refunds them when the customer returns the goods.")
+	want := []string{
+		`"""Orders.`,
+		"",
+		"Places orders, tracks their shipment and refunds them when the customer returns",
+		"the goods.",
+		`"""`,
+	}
+	if !reflect.DeepEqual(comment, want) {
+		t.Errorf("pythonComment = %q, want %q", comment, want)
+	}
+}
@@ -0,0 +1,190 @@
+package annotate
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// commentWidth is where comment text wraps, markers included.
+	commentWidth = 80
+	// contextLines is how many unchanged lines surround a diff hunk.
+	contextLines = 3
+)
+
+// Patch inserts a doc comment into one file, creating it if needed.
+type Patch struct {
+	// Path is the slash path of the file, relative to the repository.
+	Path   string
+	Create bool
+	lines  []string
+	at     int
+	insert []string
+	// noEOL is set when the file does not end with a newline.
+	noEOL bool
+}
+
+// NewPatch returns the patch adding comment, plain text with paragraphs
+// separated by blank lines, to target's file under root.
+func NewPatch(root string, target Target, comment string) (*Patch, error) {
+	if len(paragraphs(comment)) == 0 {
+		return nil, fmt.Errorf("%s: empty comment", target.File)
+	}
+	patch := &Patch{Path: target.File}
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(target.File)))
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && target.Language == "go":
+		patch.Create = true
+		patch.insert = append(goComment(comment), "package "+target.Name)
+		return patch, nil
+	case err != nil:
+		return nil, err
+	}
+
+	text := string(content)
+	patch.noEOL = text != "" && !strings.HasSuffix(text, "\n")
+	patch.lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	switch target.Language {
+	case "go":
+		// doc.go exists without a package comment: add one above its
+		// package clause.
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, target.File, content, parser.PackageClauseOnly)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target.File, err)
+		}
+		patch.at = fset.Position(f.Package).Line - 1
+		patch.insert = goComment(comment)
+	case "python":
+		at, ok := pythonDocstring(patch.lines)
+		if !ok {
+			return nil, fmt.Errorf("%s already has a docstring", target.File)
+		}
+		patch.at = at
+		patch.insert = append(pythonComment(comment), "")
+	default:
+		return nil, fmt.Errorf("%s: cannot annotate %s files", target.File, target.Language)
+	}
+	return patch, nil
+}
+
+// Content returns the file as patched.
+func (p *Patch) Content() string {
+	lines := append(append(append([]string{}, p.lines[:p.at]...), p.insert...), p.lines[p.at:]...)
+	content := strings.Join(lines, "\n")
+	if !p.noEOL {
+		content += "\n"
+	}
+	return content
+}
+
+// Write applies the patch to the file under root.
+func (p *Patch) Write(root string) error {
+	return os.WriteFile(filepath.Join(root, filepath.FromSlash(p.Path)), []byte(p.Content()), 0644)
+}
+
+// Diff returns the patch as a unified diff, for git apply or patch -p1.
+func (p *Patch) Diff() string {
+	var b strings.Builder
+	if p.Create {
+		fmt.Fprintf(&b, "--- /dev/null\n+++ b/%s\n", p.Path)
+	} else {
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", p.Path, p.Path)
+	}
+
+	start := max(p.at-contextLines, 0)
+	end := min(p.at+contextLines, len(p.lines))
+	before, after := p.lines[start:p.at], p.lines[p.at:end]
+	oldCount := len(before) + len(after)
+	newCount := oldCount + len(p.insert)
+	fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(start, oldCount), hunkRange(start, newCount))
+	for _, line := range before {
+		fmt.Fprintf(&b, " %s\n", line)
+	}
+	for _, line := range p.insert {
+		fmt.Fprintf(&b, "+%s\n", line)
+	}
+	for _, line := range after {
+		fmt.Fprintf(&b, " %s\n", line)
+	}
+	if p.noEOL && end == len(p.lines) {
+		b.WriteString("\\ No newline at end of file\n")
+	}
+	return b.String()
+}
+
+// hunkRange formats the line range of a hunk side starting after start
+// lines; an empty side names the line before it.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func goComment(comment string) []string {
+	lines := []string{}
+	for i, paragraph := range paragraphs(comment) {
+		if i > 0 {
+			lines = append(lines, "//")
+		}
+		for _, line := range wrap(paragraph, commentWidth-len("// ")) {
+			lines = append(lines, "// "+line)
+		}
+	}
+	return lines
+}
+
+func pythonComment(comment string) []string {
+	comment = strings.ReplaceAll(comment, `"""`, `\"\"\"`)
+	parts := paragraphs(comment)
+	if len(parts) == 1 && len(parts[0])+len(`""""""`) <= commentWidth {
+		return []string{`"""` + parts[0] + `"""`}
+	}
+	lines := []string{}
+	for i, paragraph := range parts {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, wrap(paragraph, commentWidth)...)
+	}
+	lines[0] = `"""` + lines[0]
+	return append(lines, `"""`)
+}
+
+// paragraphs splits text at blank lines, joining the lines of each
+// paragraph with spaces.
+func paragraphs(text string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func wrap(text string, width int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,114 @@
+// Package baseline reads the list of risks a team has reviewed and accepted,
+// so reports only flag what is new.
+package baseline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const DefaultFileName = ".codedoc-baseline.json"
+
+// Entry acknowledges a finding or secret by rule, optionally limited to one
+// file, or a heuristic risk by the start of its text.
+type Entry struct {
+	Rule   string `json:"rule,omitempty"`
+	File   string `json:"file,omitempty"`
+	Risk   string `json:"risk,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type Baseline struct {
+	Acknowledged []Entry `json:"acknowledged"`
+}
+
+// Load reads the baseline at path. A missing file yields an empty baseline.
+func Load(path string) (*Baseline, error) {
+	b := &Baseline{Acknowledged: []Entry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for i, entry := range b.Acknowledged {
+		if entry.Rule == "" && entry.Risk == "" {
+			return nil, fmt.Errorf("invalid baseline %s: entry %d needs a rule or a risk", path, i+1)
+		}
+	}
+	return b, nil
+}
+
+// Path returns the baseline for repoPath: explicit when set, otherwise
+// DefaultFileName at the repository root.
+func Path(explicit, repoPath string) string {
+	if explicit != "" {
+		return explicit
+	}
+	return filepath.Join(repoPath, DefaultFileName)
+}
+
+// Match returns the entry acknowledging a finding of rule in file.
+func (b *Baseline) Match(rule, file string) (Entry, bool) {
+	if b == nil {
+		return Entry{}, false
+	}
+	for _, entry := range b.Acknowledged {
+		if entry.Rule == rule && (entry.File == "" || entry.File == file) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// MatchRisk returns the entry acknowledging a heuristic risk. Risks embed
+// counts and paths, so entries match on a prefix of the text.
+func (b *Baseline) MatchRisk(risk string) (Entry, bool) {
+	if b == nil {
+		return Entry{}, false
+	}
+	for _, entry := range b.Acknowledged {
+		if entry.Risk != "" && strings.HasPrefix(risk, entry.Risk) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Write saves entries to path, keeping the reasons already recorded for
+// entries that are still present.
+func Write(path string, entries []Entry) error {
+	previous, err := Load(path)
+	if err != nil {
+		return err
+	}
+	reasons := make(map[Entry]string)
+	for _, entry := range previous.Acknowledged {
+		reason := entry.Reason
+		entry.Reason = ""
+		reasons[entry] = reason
+	}
+
+	b := Baseline{Acknowledged: []Entry{}}
+	for _, entry := range entries {
+		entry.Reason = reasons[entry]
+		b.Acknowledged = append(b.Acknowledged, entry)
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	b := &Baseline{Acknowledged: []Entry{
+		{Rule: "root-user", File: "Dockerfile", Reason: "dev image"},
+		{Rule: "latest-tag"},
+		{Risk: "Low test coverage"},
+	}}
+
+	tests := []struct {
+		rule, file string
+		want       bool
+	}{
+		{"root-user", "Dockerfile", true},
+		{"root-user", "deploy/Dockerfile", false},
+		{"latest-tag", "any/Dockerfile", true},
+		{"apt-no-clean", "Dockerfile", false},
+	}
+	for _, tt := range tests {
+		if _, got := b.Match(tt.rule, tt.file); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.rule, tt.file, got, tt.want)
+		}
+	}
+
+	if _, ok := b.MatchRisk("Low test coverage (less than 10% test files)"); !ok {
+		t.Error("Expected the risk to match by prefix")
+	}
+	if _, ok := b.MatchRisk("No CI/CD configuration detected"); ok {
+		t.Error("Expected an unlisted risk not to match")
+	}
+
+	var missing *Baseline
+	if _, ok := missing.Match("root-user", "Dockerfile"); ok {
+		t.Error("Expected a nil baseline to match nothing")
+	}
+}
+
+func TestWriteKeepsReasons(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFileName)
+	if err := os.WriteFile(path, []byte(`{"acknowledged": [{"rule": "root-user", "file": "Dockerfile", "reason": "dev image"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Write(path, []Entry{{Rule: "root-user", File: "Dockerfile"}, {Risk: "Missing dependency lock file"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Acknowledged) != 2 || b.Acknowledged[0].Reason != "dev image" || b.Acknowledged[1].Reason != "" {
+		t.Errorf("Unexpected baseline after write: %+v", b.Acknowledged)
+	}
+}
+
+func TestLoadRejectsEmptyEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFileName)
+	if err := os.WriteFile(path, []byte(`{"acknowledged": [{"reason": "?"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an entry without rule or risk to be rejected")
+	}
+}
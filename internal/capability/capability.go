@@ -0,0 +1,217 @@
+// Package capability detects the optional tools, credentials and network
+// access a run relies on, so that without them codedoc leaves out what
+// depends on them, and says so, instead of failing.
+package capability
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/osv"
+	"github.com/codepigeon/codedoc/internal/render"
+)
+
+// Names of the capabilities, in the order Detect lists them.
+const (
+	Git           = "git"
+	Network       = "network"
+	AnthropicKey  = "anthropic-api-key"
+	EmbeddingsKey = "embeddings-api-key"
+	Mermaid       = "mermaid"
+)
+
+// dialTimeout bounds each reachability check.
+const dialTimeout = 3 * time.Second
+
+// Seams for tests.
+var (
+	lookPath = exec.LookPath
+	getenv   = os.Getenv
+	dial     = func(ctx context.Context, address string) error {
+		conn, err := (&net.Dialer{Timeout: dialTimeout}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+)
+
+// Needs describes what a run uses.
+type Needs struct {
+	// LLM is set unless the run is a dry run.
+	LLM   bool
+	Audit bool
+	// GitHistory is --git-history.
+	GitHistory bool
+	// Embeddings is the --embeddings provider, if any.
+	Embeddings string
+	// Diagrams is set when the report has diagrams and is rendered to HTML
+	// or PDF.
+	Diagrams bool
+}
+
+// Capability is one optional dependency and whether it is present.
+type Capability struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	// Needed reports whether the run uses it.
+	Needed bool `json:"needed"`
+	// Detail says where it was found or why it is missing.
+	Detail string `json:"detail"`
+	// Without describes what codedoc does when it is missing.
+	Without string `json:"without"`
+}
+
+// Missing reports whether the run needs c but it is unavailable.
+func (c Capability) Missing() bool {
+	return c.Needed && !c.Available
+}
+
+// Matrix lists every capability, needed or not.
+type Matrix []Capability
+
+// Missing reports whether the named capability is needed but unavailable.
+func (m Matrix) Missing(name string) bool {
+	for _, c := range m {
+		if c.Name == name {
+			return c.Missing()
+		}
+	}
+	return false
+}
+
+// Degraded reports whether any needed capability is unavailable.
+func (m Matrix) Degraded() bool {
+	for _, c := range m {
+		if c.Missing() {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect checks each capability. The network is only probed, against the
+// hosts the run would contact, when something needs it.
+func Detect(ctx context.Context, needs Needs) Matrix {
+	git := Capability{
+		Name:    Git,
+		Needed:  needs.GitHistory,
+		Without: "--git-history and the hotspot sections are left out; cloning and commit metadata use the built-in implementation",
+	}
+	git.Available, git.Detail = lookup("git")
+
+	remoteEmbeddings := embed.KeyEnv(needs.Embeddings) != ""
+	hosts := []string{}
+	if needs.LLM {
+		hosts = append(hosts, llm.APIHost)
+	}
+	if needs.Audit {
+		hosts = append(hosts, hostOf(osv.DefaultBaseURL))
+	}
+	if remoteEmbeddings {
+		hosts = append(hosts, embed.Host(needs.Embeddings))
+	}
+	network := Capability{
+		Name:    Network,
+		Needed:  len(hosts) > 0,
+		Without: "summaries, --audit and remote --embeddings are left out, as in a dry run",
+	}
+	network.Available, network.Detail = reach(ctx, hosts)
+
+	anthropic := Capability{
+		Name:    AnthropicKey,
+		Needed:  needs.LLM,
+		Without: "summaries are left out, as in a dry run",
+	}
+	anthropic.Available, anthropic.Detail = env("ANTHROPIC_API_KEY")
+
+	embeddings := Capability{
+		Name:    EmbeddingsKey,
+		Needed:  remoteEmbeddings,
+		Without: "functional areas are clustered with --embeddings local",
+	}
+	if keyEnv := embed.KeyEnv(needs.Embeddings); keyEnv != "" {
+		embeddings.Available, embeddings.Detail = env(keyEnv)
+	} else {
+		embeddings.Available, embeddings.Detail = true, "not required by --embeddings "+orNone(needs.Embeddings)
+	}
+
+	mermaid := Capability{
+		Name:    Mermaid,
+		Needed:  needs.Diagrams,
+		Without: "diagrams in HTML and PDF reports are shown as Mermaid source",
+	}
+	mermaid.Available, mermaid.Detail = lookup(render.MermaidCLI)
+
+	return Matrix{git, network, anthropic, embeddings, mermaid}
+}
+
+func lookup(binary string) (bool, string) {
+	path, err := lookPath(binary)
+	if err != nil {
+		return false, binary + " not found in PATH"
+	}
+	return true, path
+}
+
+func env(name string) (bool, string) {
+	if getenv(name) == "" {
+		return false, name + " not set"
+	}
+	return true, name + " set"
+}
+
+func reach(ctx context.Context, hosts []string) (bool, string) {
+	if len(hosts) == 0 {
+		return true, "not checked; nothing in this run needs it"
+	}
+	unreachable := []string{}
+	for _, host := range hosts {
+		if err := dial(ctx, net.JoinHostPort(host, "443")); err != nil {
+			unreachable = append(unreachable, host)
+		}
+	}
+	if len(unreachable) > 0 {
+		return false, "cannot reach " + strings.Join(unreachable, ", ")
+	}
+	return true, "reached " + strings.Join(hosts, ", ")
+}
+
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Hostname()
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// String renders the matrix as an aligned table, one capability per line.
+func (m Matrix) String() string {
+	var b strings.Builder
+	for _, c := range m {
+		status := "yes"
+		switch {
+		case c.Missing():
+			status = "MISSING"
+		case !c.Available:
+			status = "no"
+		}
+		fmt.Fprintf(&b, "  %-18s %-7s %s\n", c.Name, status, c.Detail)
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package capability
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		needs   Needs
+		binary  map[string]bool
+		env     map[string]string
+		online  bool
+		missing []string
+		dialed  []string
+	}{
+		{
+			name:   "dry run needs nothing",
+			needs:  Needs{},
+			online: false,
+		},
+		{
+			name:    "llm without key",
+			needs:   Needs{LLM: true},
+			online:  true,
+			missing: []string{AnthropicKey},
+			dialed:  []string{"api.anthropic.com:443"},
+		},
+		{
+			name:    "offline audit and remote embeddings",
+			needs:   Needs{Audit: true, Embeddings: "openai"},
+			env:     map[string]string{"OPENAI_API_KEY": "k"},
+			missing: []string{Network},
+			dialed:  []string{"api.osv.dev:443", "api.openai.com:443"},
+		},
+		{
+			name:   "local embeddings stay offline",
+			needs:  Needs{Embeddings: "local"},
+			online: false,
+		},
+		{
+			name:    "history and diagrams without tools",
+			needs:   Needs{GitHistory: true, Diagrams: true},
+			missing: []string{Git, Mermaid},
+		},
+		{
+			name:   "everything present",
+			needs:  Needs{LLM: true, GitHistory: true, Diagrams: true, Embeddings: "anthropic"},
+			binary: map[string]bool{"git": true, "mmdc": true},
+			env:    map[string]string{"ANTHROPIC_API_KEY": "k", "VOYAGE_API_KEY": "k"},
+			online: true,
+			dialed: []string{"api.anthropic.com:443", "api.voyageai.com:443"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialed := []string{}
+			lookPath = func(name string) (string, error) {
+				if tt.binary[name] {
+					return "/usr/bin/" + name, nil
+				}
+				return "", errors.New("not found")
+			}
+			getenv = func(name string) string { return tt.env[name] }
+			dial = func(_ context.Context, address string) error {
+				dialed = append(dialed, address)
+				if !tt.online {
+					return errors.New("unreachable")
+				}
+				return nil
+			}
+
+			matrix := Detect(context.Background(), tt.needs)
+			missing := []string{}
+			for _, c := range matrix {
+				if c.Missing() {
+					missing = append(missing, c.Name)
+				}
+			}
+			if strings.Join(missing, ",") != strings.Join(tt.missing, ",") {
+				t.Errorf("Missing = %v, want %v\n%s", missing, tt.missing, matrix)
+			}
+			if matrix.Degraded() != (len(tt.missing) > 0) {
+				t.Errorf("Degraded = %v with %v missing", matrix.Degraded(), tt.missing)
+			}
+			if strings.Join(dialed, ",") != strings.Join(tt.dialed, ",") {
+				t.Errorf("Dialed %v, want %v", dialed, tt.dialed)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+// Package catalog writes Backstage catalog-info.yaml descriptors from the
+// analysis, so a repository can be registered in a software catalog.
+package catalog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+const (
+	DefaultFileName = "catalog-info.yaml"
+	apiVersion      = "backstage.io/v1alpha1"
+	// defaultLifecycle is used until someone sets the real one, which later
+	// updates keep.
+	defaultLifecycle = "experimental"
+)
+
+// Input is what the descriptor is built from.
+type Input struct {
+	Name        string
+	Description string
+	// RepoURL is the repository's remote, used for links and annotations.
+	RepoURL string
+	// Owner is a CODEOWNERS owner of the repository root.
+	Owner     string
+	Tags      []string
+	Detection *detect.Result
+}
+
+type Entity struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       Spec     `yaml:"spec"`
+}
+
+type Metadata struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Tags        []string          `yaml:"tags,omitempty"`
+	Links       []Link            `yaml:"links,omitempty"`
+}
+
+type Link struct {
+	URL   string `yaml:"url"`
+	Title string `yaml:"title,omitempty"`
+}
+
+type Spec struct {
+	Type         string      `yaml:"type"`
+	Lifecycle    string      `yaml:"lifecycle"`
+	Owner        string      `yaml:"owner"`
+	ProvidesAPIs []string    `yaml:"providesApis,omitempty"`
+	Definition   *Definition `yaml:"definition,omitempty"`
+}
+
+// Definition points an API entity at its spec, relative to the descriptor.
+type Definition struct {
+	Text string `yaml:"$text"`
+}
+
+var (
+	invalidName = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+	invalidTag  = regexp.MustCompile(`[^a-z0-9:+#]+`)
+)
+
+// Entities returns the Component describing the repository, followed by one
+// API entity per OpenAPI spec it serves.
+func Entities(in Input) []Entity {
+	name := entityName(in.Name)
+	owner := ownerRef(in.Owner)
+
+	component := Entity{
+		APIVersion: apiVersion,
+		Kind:       "Component",
+		Metadata: Metadata{
+			Name:        name,
+			Description: in.Description,
+			Tags:        tags(in.Tags),
+		},
+		Spec: Spec{
+			Type:      componentType(in),
+			Lifecycle: defaultLifecycle,
+			Owner:     owner,
+		},
+	}
+
+	if in.RepoURL != "" {
+		url := util.NormalizeRepoURL(in.RepoURL)
+		component.Metadata.Links = []Link{{URL: url, Title: "Repository"}}
+		component.Metadata.Annotations = map[string]string{"backstage.io/source-location": "url:" + url + "/"}
+		if slug, ok := strings.CutPrefix(url, "https://github.com/"); ok {
+			component.Metadata.Annotations["github.com/project-slug"] = slug
+		}
+	}
+
+	entities := []Entity{component}
+	for i, spec := range apiSpecs(in.Detection) {
+		apiName := name + "-api"
+		if i > 0 {
+			apiName = fmt.Sprintf("%s-api-%d", name, i+1)
+		}
+		entities[0].Spec.ProvidesAPIs = append(entities[0].Spec.ProvidesAPIs, apiName)
+		entities = append(entities, Entity{
+			APIVersion: apiVersion,
+			Kind:       "API",
+			Metadata:   Metadata{Name: apiName, Description: "HTTP API described by " + spec},
+			Spec: Spec{
+				Type:       "openapi",
+				Lifecycle:  defaultLifecycle,
+				Owner:      owner,
+				Definition: &Definition{Text: "./" + spec},
+			},
+		})
+	}
+	return entities
+}
+
+// componentType picks a well-known Backstage type from what was detected.
+func componentType(in Input) string {
+	for _, tag := range in.Tags {
+		if tag == "react-spa" {
+			return "website"
+		}
+	}
+	if d := in.Detection; d != nil {
+		switch {
+		case len(d.Endpoints) > 0 || len(d.K8s) > 0 || len(d.HelmCharts) > 0:
+			return "service"
+		case len(d.CLICommands) > 0:
+			return "tool"
+		}
+	}
+	return "library"
+}
+
+func apiSpecs(detection *detect.Result) []string {
+	if detection == nil {
+		return nil
+	}
+	specs := []string{}
+	seen := make(map[string]bool)
+	for _, endpoint := range detection.Endpoints {
+		if endpoint.Source == "openapi" && !seen[endpoint.File] {
+			seen[endpoint.File] = true
+			specs = append(specs, endpoint.File)
+		}
+	}
+	return specs
+}
+
+// entityName makes name a valid Backstage entity name.
+func entityName(name string) string {
+	name = strings.Trim(invalidName.ReplaceAllString(name, "-"), "-_.")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-_.")
+	}
+	if name == "" {
+		return "unnamed"
+	}
+	return name
+}
+
+// ownerRef turns a CODEOWNERS owner into an entity reference: @org/team is a
+// group, @user and email addresses are users.
+func ownerRef(owner string) string {
+	switch {
+	case owner == "":
+		return "unknown"
+	case strings.HasPrefix(owner, "@") && strings.Contains(owner, "/"):
+		return "group:" + entityName(owner[strings.LastIndex(owner, "/")+1:])
+	case strings.HasPrefix(owner, "@"):
+		return "user:" + entityName(owner[1:])
+	default:
+		user, _, _ := strings.Cut(owner, "@")
+		return "user:" + entityName(user)
+	}
+}
+
+// tags keeps the tags Backstage accepts, rewriting separators it does not.
+func tags(in []string) []string {
+	out := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range in {
+		tag = strings.Trim(invalidTag.ReplaceAllString(strings.ToLower(tag), "-"), "-")
+		if tag == "" || len(tag) > 63 || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+// Write writes entities to path. When path already exists its documents are
+// updated instead: values set there win, codedoc only fills in what is
+// missing and adds new list entries, and unrelated documents are kept.
+// Comments are not preserved.
+func Write(path string, entities []Entity) error {
+	existing, err := readDocuments(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entity := range entities {
+		generated, err := toMap(entity)
+		if err != nil {
+			return err
+		}
+		if i := findDocument(existing, entity); i >= 0 {
+			existing[i] = merge(existing[i], generated).(map[string]any)
+		} else {
+			existing = append(existing, generated)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, document := range existing {
+		if err := encoder.Encode(document); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", path, err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write catalog descriptor: %w", err)
+	}
+	return nil
+}
+
+func readDocuments(path string) ([]map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog descriptor: %w", err)
+	}
+
+	documents := []map[string]any{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document map[string]any
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if document != nil {
+			documents = append(documents, document)
+		}
+	}
+}
+
+func toMap(entity Entity) (map[string]any, error) {
+	data, err := yaml.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var document map[string]any
+	return document, yaml.Unmarshal(data, &document)
+}
+
+// findDocument returns the existing document entity updates: the first
+// Component, or the API of the same name.
+func findDocument(documents []map[string]any, entity Entity) int {
+	for i, document := range documents {
+		if document["kind"] != entity.Kind {
+			continue
+		}
+		if entity.Kind == "Component" {
+			return i
+		}
+		if metadata, ok := document["metadata"].(map[string]any); ok && metadata["name"] == entity.Metadata.Name {
+			return i
+		}
+	}
+	return -1
+}
+
+// merge combines an existing value with a generated one. Existing scalars
+// win, maps merge key by key and lists gain the generated entries they lack.
+func merge(existing, generated any) any {
+	switch e := existing.(type) {
+	case map[string]any:
+		g, ok := generated.(map[string]any)
+		if !ok {
+			return existing
+		}
+		for key, value := range g {
+			if current, ok := e[key]; ok && current != nil {
+				e[key] = merge(current, value)
+			} else {
+				e[key] = value
+			}
+		}
+		return e
+	case []any:
+		g, ok := generated.([]any)
+		if !ok {
+			return existing
+		}
+		for _, value := range g {
+			if !containsValue(e, value) {
+				e = append(e, value)
+			}
+		}
+		return e
+	case nil:
+		return generated
+	}
+	return existing
+}
+
+// containsValue compares list entries by value, and links by URL.
+func containsValue(list []any, value any) bool {
+	for _, item := range list {
+		if fmt.Sprint(item) == fmt.Sprint(value) {
+			return true
+		}
+		a, aok := item.(map[string]any)
+		b, bok := value.(map[string]any)
+		if aok && bok && a["url"] != nil && a["url"] == b["url"] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+)
+
+func TestEntities(t *testing.T) {
+	entities := Entities(Input{
+		Name:    "Orders Service",
+		RepoURL: "git@github.com:acme/orders.git",
+		Owner:   "@acme/payments-team",
+		Tags:    []string{"payments", "node.js", "http-api"},
+		Detection: &detect.Result{Endpoints: []detect.Endpoint{
+			{Method: "GET", Path: "/orders", File: "api/openapi.yaml", Source: "openapi"},
+			{Method: "POST", Path: "/orders", File: "api/openapi.yaml", Source: "openapi"},
+		}},
+	})
+
+	if len(entities) != 2 {
+		t.Fatalf("Expected a component and an API, got %d entities", len(entities))
+	}
+	component, api := entities[0], entities[1]
+	if component.Metadata.Name != "Orders-Service" || component.Spec.Type != "service" || component.Spec.Owner != "group:payments-team" {
+		t.Errorf("Unexpected component %+v", component)
+	}
+	if got := strings.Join(component.Metadata.Tags, ","); got != "payments,node-js,http-api" {
+		t.Errorf("tags = %s", got)
+	}
+	if component.Metadata.Annotations["github.com/project-slug"] != "acme/orders" {
+		t.Errorf("annotations = %v", component.Metadata.Annotations)
+	}
+	if len(component.Spec.ProvidesAPIs) != 1 || component.Spec.ProvidesAPIs[0] != api.Metadata.Name {
+		t.Errorf("Expected the component to provide %s, got %v", api.Metadata.Name, component.Spec.ProvidesAPIs)
+	}
+	if api.Spec.Type != "openapi" || api.Spec.Definition.Text != "./api/openapi.yaml" {
+		t.Errorf("Unexpected API %+v", api.Spec)
+	}
+}
+
+func TestOwnerRef(t *testing.T) {
+	tests := map[string]string{
+		"":                  "unknown",
+		"@acme/platform":    "group:platform",
+		"@octocat":          "user:octocat",
+		"jane.doe@acme.com": "user:jane.doe",
+	}
+	for owner, want := range tests {
+		if got := ownerRef(owner); got != want {
+			t.Errorf("ownerRef(%q) = %q, want %q", owner, got, want)
+		}
+	}
+}
+
+func TestWriteUpdatesExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFileName)
+	existing := `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: orders
+  tags: [legacy]
+spec:
+  type: service
+  lifecycle: production
+  owner: group:checkout
+---
+apiVersion: backstage.io/v1alpha1
+kind: System
+metadata:
+  name: commerce
+spec:
+  owner: group:checkout
+`
+	if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entities := Entities(Input{Name: "orders", Owner: "@acme/other", Tags: []string{"payments", "legacy"}, RepoURL: "https://github.com/acme/orders"})
+	if err := Write(path, entities); err != nil {
+		t.Fatal(err)
+	}
+
+	documents, err := readDocuments(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(documents) != 2 || documents[1]["kind"] != "System" {
+		t.Fatalf("Expected the System document to be kept, got %v", documents)
+	}
+	metadata := documents[0]["metadata"].(map[string]any)
+	spec := documents[0]["spec"].(map[string]any)
+	if spec["lifecycle"] != "production" || spec["owner"] != "group:checkout" {
+		t.Errorf("Expected hand-set values to win, got %v", spec)
+	}
+	if tags := metadata["tags"].([]any); len(tags) != 2 || tags[0] != "legacy" || tags[1] != "payments" {
+		t.Errorf("Expected new tags appended once, got %v", tags)
+	}
+	if links := metadata["links"].([]any); len(links) != 1 {
+		t.Errorf("Expected the repository link to be added, got %v", links)
+	}
+
+	if err := Write(path, entities); err != nil {
+		t.Fatal(err)
+	}
+	again, err := readDocuments(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if links := again[0]["metadata"].(map[string]any)["links"].([]any); len(links) != 1 {
+		t.Errorf("Expected a second update to be a no-op, got links %v", links)
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/risk"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+const DefaultFileName = "codedoc.yaml"
+
+type File struct {
+	Detect           DetectConfig `yaml:"detect"`
+	InternalPrefixes []string     `yaml:"internal_prefixes"`
+	Report           ReportConfig `yaml:"report"`
+	// Tests overrides which files count as tests.
+	Tests scanner.TestRules `yaml:"tests"`
+	// Risks disables, re-ranks and adds risk rules.
+	Risks risk.Config `yaml:"risks"`
+	// Models picks the model of each listed summary type, overriding
+	// --model for it.
+	Models map[llm.SummaryType]string `yaml:"models"`
+}
+
+// ReportConfig holds report layout settings. Pointer fields distinguish
+// "unset" from zero so command-line defaults can apply.
+type ReportConfig struct {
+	// MaxEndpoints caps the endpoints table; 0 lists every endpoint.
+	MaxEndpoints *int `yaml:"max_endpoints"`
+}
+
+type DetectConfig struct {
+	Frameworks []detect.Rule `yaml:"frameworks"`
+	Endpoints  []detect.Rule `yaml:"endpoints"`
+	Models     []detect.Rule `yaml:"models"`
+}
+
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	file := &File{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	slog.Debug("loaded config", "path", path, "framework_rules", len(file.Detect.Frameworks),
+		"endpoint_rules", len(file.Detect.Endpoints), "model_rules", len(file.Detect.Models))
+	return file, nil
+}
+
+// Resolve loads the explicitly requested config file, or codedoc.yaml from
+// the analyzed repository when present. A missing default file is not an
+// error and yields an empty config.
+func Resolve(explicitPath, repoPath string) (*File, error) {
+	if explicitPath != "" {
+		return Load(explicitPath)
+	}
+
+	candidate := filepath.Join(repoPath, DefaultFileName)
+	if _, err := os.Stat(candidate); err != nil {
+		return &File{}, nil
+	}
+
+	return Load(candidate)
+}
+
+func (f *File) DetectRules() detect.Rules {
+	return detect.Rules{
+		Frameworks: f.Detect.Frameworks,
+		Endpoints:  f.Detect.Endpoints,
+		Models:     f.Detect.Models,
+	}
+}
+
+func (f *File) validate() error {
+	if f.Report.MaxEndpoints != nil && *f.Report.MaxEndpoints < 0 {
+		return fmt.Errorf("report.max_endpoints must not be negative")
+	}
+
+	sections := map[string][]detect.Rule{
+		"detect.frameworks": f.Detect.Frameworks,
+		"detect.endpoints":  f.Detect.Endpoints,
+		"detect.models":     f.Detect.Models,
+	}
+
+	for section, rules := range sections {
+		for i, rule := range rules {
+			if err := rule.Validate(); err != nil {
+				return fmt.Errorf("%s[%d]: %w", section, i, err)
+			}
+		}
+	}
+
+	if err := f.Risks.Validate(); err != nil {
+		return err
+	}
+
+	for summaryType, model := range f.Models {
+		if !slices.Contains(llm.SummaryTypes, summaryType) {
+			return fmt.Errorf("models: unknown summary type %q", summaryType)
+		}
+		if model == "" {
+			return fmt.Errorf("models.%s: model must not be empty", summaryType)
+		}
+	}
+
+	return nil
+}
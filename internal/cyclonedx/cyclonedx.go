@@ -0,0 +1,235 @@
+// Package cyclonedx exports the analysis as a CycloneDX BOM: the HTTP API the
+// repository serves becomes a service and its pinned dependencies become
+// components, which API catalogs and gateways can ingest.
+package cyclonedx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/deps"
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+const specVersion = "1.5"
+
+// Input is what the BOM is built from.
+type Input struct {
+	Name        string
+	Description string
+	// Version identifies the analyzed revision, usually the commit SHA.
+	Version      string
+	RepoURL      string
+	Tags         []string
+	ToolVersion  string
+	Timestamp    string
+	Detection    *detect.Result
+	Dependencies []deps.Dependency
+}
+
+type BOM struct {
+	BOMFormat    string       `json:"bomFormat"`
+	SpecVersion  string       `json:"specVersion"`
+	Version      int          `json:"version"`
+	Metadata     Metadata     `json:"metadata"`
+	Components   []Component  `json:"components"`
+	Services     []Service    `json:"services,omitempty"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+type Metadata struct {
+	Timestamp string    `json:"timestamp,omitempty"`
+	Tools     Tools     `json:"tools"`
+	Component Component `json:"component"`
+}
+
+type Tools struct {
+	Components []Component `json:"components"`
+}
+
+type Component struct {
+	Type               string              `json:"type"`
+	BOMRef             string              `json:"bom-ref,omitempty"`
+	Name               string              `json:"name"`
+	Version            string              `json:"version,omitempty"`
+	Description        string              `json:"description,omitempty"`
+	PURL               string              `json:"purl,omitempty"`
+	ExternalReferences []ExternalReference `json:"externalReferences,omitempty"`
+	Properties         []Property          `json:"properties,omitempty"`
+}
+
+type Service struct {
+	BOMRef             string              `json:"bom-ref"`
+	Name               string              `json:"name"`
+	Version            string              `json:"version,omitempty"`
+	Description        string              `json:"description,omitempty"`
+	Endpoints          []string            `json:"endpoints,omitempty"`
+	ExternalReferences []ExternalReference `json:"externalReferences,omitempty"`
+	Properties         []Property          `json:"properties,omitempty"`
+}
+
+type ExternalReference struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// Build returns the BOM for in. The repository is the metadata component;
+// it provides the service and depends directly on the non-indirect
+// dependencies.
+func Build(in Input) BOM {
+	toolVersion := in.ToolVersion
+	if toolVersion == "" {
+		toolVersion = "dev"
+	}
+
+	root := Component{
+		Type:        "application",
+		BOMRef:      in.Name,
+		Name:        in.Name,
+		Version:     in.Version,
+		Description: in.Description,
+	}
+	if in.RepoURL != "" {
+		root.ExternalReferences = []ExternalReference{{Type: "vcs", URL: util.NormalizeRepoURL(in.RepoURL)}}
+	}
+	for _, tag := range in.Tags {
+		root.Properties = append(root.Properties, Property{Name: "codedoc:tag", Value: tag})
+	}
+
+	bom := BOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: specVersion,
+		Version:     1,
+		Metadata: Metadata{
+			Timestamp: in.Timestamp,
+			Tools:     Tools{Components: []Component{{Type: "application", Name: "codedoc", Version: toolVersion}}},
+			Component: root,
+		},
+		Components:   []Component{},
+		Dependencies: []Dependency{},
+	}
+
+	rootDependency := Dependency{Ref: root.BOMRef}
+	seen := make(map[string]bool)
+	for _, dep := range in.Dependencies {
+		purl := PackageURL(dep)
+		if purl == "" || seen[purl] {
+			continue
+		}
+		seen[purl] = true
+
+		component := Component{
+			Type:       "library",
+			BOMRef:     purl,
+			Name:       dep.Name,
+			Version:    dep.Version,
+			PURL:       purl,
+			Properties: []Property{{Name: "codedoc:file", Value: dep.File}},
+		}
+		if dep.Indirect {
+			component.Properties = append(component.Properties, Property{Name: "codedoc:indirect", Value: "true"})
+		} else {
+			rootDependency.DependsOn = append(rootDependency.DependsOn, purl)
+		}
+		if dep.Replaces != "" {
+			component.Properties = append(component.Properties, Property{Name: "codedoc:replaces", Value: dep.Replaces})
+		}
+		bom.Components = append(bom.Components, component)
+		bom.Dependencies = append(bom.Dependencies, Dependency{Ref: purl})
+	}
+	bom.Dependencies = append([]Dependency{rootDependency}, bom.Dependencies...)
+
+	if service, ok := apiService(in); ok {
+		bom.Services = []Service{service}
+	}
+	return bom
+}
+
+// apiService describes the HTTP API found by detection. Endpoints are the
+// distinct route paths; the method and handler of each route are kept as
+// properties since CycloneDX endpoints are plain URIs.
+func apiService(in Input) (Service, bool) {
+	if in.Detection == nil || len(in.Detection.Endpoints) == 0 {
+		return Service{}, false
+	}
+
+	service := Service{
+		BOMRef:      in.Name + "-api",
+		Name:        in.Name + "-api",
+		Version:     in.Version,
+		Description: "HTTP API served by " + in.Name,
+	}
+	seenPaths := make(map[string]bool)
+	seenSpecs := make(map[string]bool)
+	for _, endpoint := range in.Detection.Endpoints {
+		if !seenPaths[endpoint.Path] {
+			seenPaths[endpoint.Path] = true
+			service.Endpoints = append(service.Endpoints, endpoint.Path)
+		}
+
+		route := strings.TrimSpace(endpoint.Method + " " + endpoint.Path)
+		if endpoint.Handler != "" {
+			route += " " + endpoint.Handler
+		}
+		service.Properties = append(service.Properties, Property{Name: "codedoc:route", Value: route})
+
+		if endpoint.Source == "openapi" && !seenSpecs[endpoint.File] {
+			seenSpecs[endpoint.File] = true
+			service.ExternalReferences = append(service.ExternalReferences, ExternalReference{
+				Type:    "documentation",
+				URL:     endpoint.File,
+				Comment: "OpenAPI specification",
+			})
+		}
+	}
+	return service, true
+}
+
+// PackageURL returns the purl of dep, or "" for an ecosystem without one.
+func PackageURL(dep deps.Dependency) string {
+	var typ, name string
+	switch dep.Ecosystem {
+	case deps.EcosystemGo:
+		typ, name = "golang", dep.Name
+	case deps.EcosystemNPM:
+		typ, name = "npm", strings.Replace(dep.Name, "@", "%40", 1)
+	case deps.EcosystemPyPI:
+		typ, name = "pypi", strings.ReplaceAll(strings.ToLower(dep.Name), "_", "-")
+	case deps.EcosystemCargo:
+		typ, name = "cargo", dep.Name
+	default:
+		return ""
+	}
+
+	purl := "pkg:" + typ + "/" + name
+	if dep.Version != "" {
+		purl += "@" + dep.Version
+	}
+	return purl
+}
+
+// Write saves bom as indented JSON.
+func Write(path string, bom BOM) error {
+	data, err := json.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode CycloneDX BOM: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write CycloneDX BOM: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package cyclonedx
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/deps"
+	"github.com/codepigeon/codedoc/internal/detect"
+)
+
+func TestPackageURL(t *testing.T) {
+	tests := []struct {
+		dep  deps.Dependency
+		want string
+	}{
+		{deps.Dependency{Name: "github.com/a/b", Version: "v1.2.3", Ecosystem: deps.EcosystemGo}, "pkg:golang/github.com/a/b@v1.2.3"},
+		{deps.Dependency{Name: "@types/node", Version: "20.1.0", Ecosystem: deps.EcosystemNPM}, "pkg:npm/%40types/node@20.1.0"},
+		{deps.Dependency{Name: "Typing_Extensions", Version: "4.8.0", Ecosystem: deps.EcosystemPyPI}, "pkg:pypi/typing-extensions@4.8.0"},
+		{deps.Dependency{Name: "serde", Version: "1.0.100", Ecosystem: deps.EcosystemCargo}, "pkg:cargo/serde@1.0.100"},
+		{deps.Dependency{Name: "x", Version: "1", Ecosystem: "Maven"}, ""},
+	}
+	for _, tt := range tests {
+		if got := PackageURL(tt.dep); got != tt.want {
+			t.Errorf("PackageURL(%s) = %q, want %q", tt.dep.Name, got, tt.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	bom := Build(Input{
+		Name:    "orders",
+		Version: "abc123",
+		RepoURL: "git@github.com:acme/orders.git",
+		Detection: &detect.Result{Endpoints: []detect.Endpoint{
+			{Method: "GET", Path: "/orders", Handler: "listOrders", File: "api.go"},
+			{Method: "POST", Path: "/orders", Handler: "createOrder", File: "api.go"},
+			{Method: "GET", Path: "/orders/{id}", File: "openapi.yaml", Source: "openapi"},
+		}},
+		Dependencies: []deps.Dependency{
+			{Name: "github.com/a/b", Version: "v1.2.3", Ecosystem: deps.EcosystemGo, File: "go.mod"},
+			{Name: "golang.org/x/net", Version: "v0.1.0", Ecosystem: deps.EcosystemGo, File: "go.mod", Indirect: true},
+			{Name: "github.com/a/b", Version: "v1.2.3", Ecosystem: deps.EcosystemGo, File: "tools/go.mod"},
+		},
+	})
+
+	if bom.Metadata.Tools.Components[0].Version != "dev" {
+		t.Errorf("tool version = %q, want dev", bom.Metadata.Tools.Components[0].Version)
+	}
+	if refs := bom.Metadata.Component.ExternalReferences; len(refs) != 1 || refs[0].URL != "https://github.com/acme/orders" {
+		t.Errorf("external references = %+v", refs)
+	}
+
+	if len(bom.Components) != 2 {
+		t.Fatalf("components = %+v, want 2", bom.Components)
+	}
+	wantDependencies := []Dependency{
+		{Ref: "orders", DependsOn: []string{"pkg:golang/github.com/a/b@v1.2.3"}},
+		{Ref: "pkg:golang/github.com/a/b@v1.2.3"},
+		{Ref: "pkg:golang/golang.org/x/net@v0.1.0"},
+	}
+	if !reflect.DeepEqual(bom.Dependencies, wantDependencies) {
+		t.Errorf("dependencies = %+v, want %+v", bom.Dependencies, wantDependencies)
+	}
+
+	if len(bom.Services) != 1 {
+		t.Fatalf("services = %+v, want 1", bom.Services)
+	}
+	service := bom.Services[0]
+	if !reflect.DeepEqual(service.Endpoints, []string{"/orders", "/orders/{id}"}) {
+		t.Errorf("endpoints = %v", service.Endpoints)
+	}
+	if len(service.Properties) != 3 || service.Properties[1].Value != "POST /orders createOrder" {
+		t.Errorf("properties = %+v", service.Properties)
+	}
+	if len(service.ExternalReferences) != 1 || service.ExternalReferences[0].URL != "openapi.yaml" {
+		t.Errorf("service references = %+v", service.ExternalReferences)
+	}
+
+	if bom := Build(Input{Name: "lib"}); bom.Services != nil {
+		t.Errorf("services without endpoints = %+v, want none", bom.Services)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bom.json")
+	if err := Write(path, Build(Input{Name: "orders"})); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["bomFormat"] != "CycloneDX" || decoded["specVersion"] != specVersion {
+		t.Errorf("header = %v %v", decoded["bomFormat"], decoded["specVersion"])
+	}
+	if _, ok := decoded["services"]; ok {
+		t.Error("empty services should be omitted")
+	}
+}
@@ -0,0 +1,165 @@
+package depmap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+type Options struct {
+	Module   string
+	Files    []scanner.FileInfo
+	Prefixes []string
+	// OrgPaths are sibling repositories scanned to find consumers of this
+	// repository's packages.
+	OrgPaths []string
+}
+
+type Result struct {
+	Module    string
+	DependsOn []Library
+	Consumers []Consumer
+}
+
+type Library struct {
+	Name     string
+	Packages []string
+	Files    []string
+}
+
+type Consumer struct {
+	Package string
+	Repos   []string
+}
+
+func Map(ctx context.Context, opts Options) (*Result, error) {
+	result := &Result{
+		Module:    opts.Module,
+		DependsOn: []Library{},
+		Consumers: []Consumer{},
+	}
+
+	if len(opts.Prefixes) == 0 {
+		return result, nil
+	}
+
+	libraries := make(map[string]*Library)
+	for _, file := range opts.Files {
+		for _, imp := range file.Imports {
+			if opts.Module != "" && isWithin(imp, opts.Module) {
+				continue
+			}
+			name, ok := matchLibrary(imp, opts.Prefixes)
+			if !ok {
+				continue
+			}
+			lib, exists := libraries[name]
+			if !exists {
+				lib = &Library{Name: name}
+				libraries[name] = lib
+			}
+			lib.Packages = append(lib.Packages, imp)
+			lib.Files = append(lib.Files, file.RelativePath)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(libraries)) {
+		lib := libraries[name]
+		slices.Sort(lib.Packages)
+		lib.Packages = slices.Compact(lib.Packages)
+		slices.Sort(lib.Files)
+		lib.Files = slices.Compact(lib.Files)
+		result.DependsOn = append(result.DependsOn, *lib)
+	}
+
+	if opts.Module == "" || len(opts.OrgPaths) == 0 {
+		return result, nil
+	}
+
+	consumers := make(map[string][]string)
+	for _, orgPath := range opts.OrgPaths {
+		scanResult, err := scanner.Scan(ctx, scanner.Options{
+			Path:         orgPath,
+			MaxFiles:     10000,
+			IncludeTests: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", orgPath, err)
+		}
+
+		repoName := filepath.Base(filepath.Clean(orgPath))
+		for _, file := range scanResult.Files {
+			for _, imp := range file.Imports {
+				if isWithin(imp, opts.Module) {
+					consumers[imp] = append(consumers[imp], repoName)
+				}
+			}
+		}
+	}
+
+	for _, pkg := range slices.Sorted(maps.Keys(consumers)) {
+		repos := consumers[pkg]
+		slices.Sort(repos)
+		repos = slices.Compact(repos)
+		result.Consumers = append(result.Consumers, Consumer{Package: pkg, Repos: repos})
+	}
+
+	return result, nil
+}
+
+// ModuleName returns the import path other repositories use for this one,
+// taken from go.mod or package.json. It returns "" when neither is present.
+func ModuleName(repoPath string) string {
+	if data, err := os.ReadFile(filepath.Join(repoPath, "go.mod")); err == nil {
+		if name := modfile.ModulePath(data); name != "" {
+			return name
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, "package.json")); err == nil {
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &pkg) == nil {
+			return pkg.Name
+		}
+	}
+
+	return ""
+}
+
+// matchLibrary maps an import to the internal library it belongs to. A prefix
+// ending in "*" (github.com/acme/*, acme.*) groups imports by the next path
+// segment; any other prefix names a single library.
+func matchLibrary(imp string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if base, ok := strings.CutSuffix(prefix, "*"); ok {
+			if !strings.HasPrefix(imp, base) || len(imp) == len(base) {
+				continue
+			}
+			rest := imp[len(base):]
+			if idx := strings.IndexAny(rest, "/."); idx >= 0 {
+				rest = rest[:idx]
+			}
+			return base + rest, true
+		}
+
+		if isWithin(imp, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+func isWithin(imp, module string) bool {
+	return imp == module || strings.HasPrefix(imp, module+"/") || strings.HasPrefix(imp, module+".")
+}
@@ -0,0 +1,58 @@
+package depmap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestMatchLibrary(t *testing.T) {
+	prefixes := []string{"github.com/acme/*", "acme.*", "github.com/partner/sdk"}
+
+	tests := []struct {
+		imp      string
+		expected string
+		ok       bool
+	}{
+		{"github.com/acme/shared/log", "github.com/acme/shared", true},
+		{"github.com/acme/auth", "github.com/acme/auth", true},
+		{"acme.billing.client", "acme.billing", true},
+		{"github.com/partner/sdk/v2", "github.com/partner/sdk", true},
+		{"github.com/partner/sdkx", "", false},
+		{"fmt", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imp, func(t *testing.T) {
+			name, ok := matchLibrary(tt.imp, prefixes)
+			if ok != tt.ok || name != tt.expected {
+				t.Errorf("matchLibrary(%s) = %q, %v, want %q, %v", tt.imp, name, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMapSkipsOwnModule(t *testing.T) {
+	files := []scanner.FileInfo{
+		{RelativePath: "main.go", Imports: []string{"github.com/acme/a/internal/x", "github.com/acme/shared/log"}},
+		{RelativePath: "db.go", Imports: []string{"github.com/acme/shared/db"}},
+	}
+
+	result, err := Map(context.Background(), Options{
+		Module:   "github.com/acme/a",
+		Files:    files,
+		Prefixes: []string{"github.com/acme/*"},
+	})
+	if err != nil {
+		t.Fatalf("Map failed: %v", err)
+	}
+
+	if len(result.DependsOn) != 1 {
+		t.Fatalf("Expected 1 library, got %+v", result.DependsOn)
+	}
+	lib := result.DependsOn[0]
+	if lib.Name != "github.com/acme/shared" || len(lib.Packages) != 2 || len(lib.Files) != 2 {
+		t.Errorf("Unexpected library %+v", lib)
+	}
+}
@@ -0,0 +1,508 @@
+// Package deps extracts the third-party dependencies a repository pins, with
+// exact versions where a lock file provides them.
+package deps
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// Ecosystem names match the OSV schema.
+const (
+	EcosystemGo    = "Go"
+	EcosystemNPM   = "npm"
+	EcosystemPyPI  = "PyPI"
+	EcosystemCargo = "crates.io"
+)
+
+type Dependency struct {
+	Name      string
+	Version   string
+	Ecosystem string
+	File      string
+	Indirect  bool
+	// Replaces is the module a go.mod replace directive substitutes with
+	// this one.
+	Replaces string
+}
+
+var (
+	requirementPin = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._\-\[\],]*)\s*==\s*([^\s;#]+)`)
+	tomlName       = regexp.MustCompile(`^name\s*=\s*"([^"]+)"`)
+	tomlVersion    = regexp.MustCompile(`^version\s*=\s*"([^"]+)"`)
+	yarnVersion    = regexp.MustCompile(`^\s+version:?\s+"?([^"\s]+)"?`)
+	pnpmV5Key      = regexp.MustCompile(`^(.+)/(\d[^/_]*)(?:_.*)?$`)
+	// yarnNonRegistry matches workspace, patched, linked and local packages.
+	yarnNonRegistry = regexp.MustCompile(`@(workspace|patch|link|portal|file):|^__metadata$`)
+)
+
+// Parse walks repoPath and returns every pinned dependency, sorted by
+// ecosystem, name and version.
+func Parse(repoPath string) ([]Dependency, error) {
+	dependencies := []Dependency{}
+
+	err := filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != repoPath && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		var parsed []Dependency
+		switch name := d.Name(); {
+		case name == "go.mod":
+			parsed = parseGoMod(path)
+		case name == "package-lock.json":
+			parsed = parsePackageLock(path)
+		case name == "yarn.lock":
+			parsed = parseYarnLock(path)
+		case name == "pnpm-lock.yaml":
+			parsed = parsePnpmLock(path)
+		case strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt"):
+			parsed = parseRequirements(path)
+		case name == "poetry.lock":
+			parsed = parseTOMLPackages(path, EcosystemPyPI)
+		case name == "Cargo.lock":
+			parsed = parseTOMLPackages(path, EcosystemCargo)
+		}
+
+		for i := range parsed {
+			parsed[i].File = rel
+		}
+		dependencies = append(dependencies, parsed...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(dependencies, func(i, j int) bool {
+		a, b := dependencies[i], dependencies[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+
+	return dedupe(dependencies), nil
+}
+
+// parseGoMod returns the modules a go.mod requires as the build resolves
+// them: a replace directive substitutes its module and version, and a module
+// replaced by a local directory keeps its path but has no version. Before Go
+// 1.17, go.mod lists only part of the module graph, so the other modules
+// recorded in go.sum are added as indirect.
+func parseGoMod(path string) []Dependency {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	// A go.mod with directives newer than this parser still yields its
+	// requirements, without replacements.
+	file, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		if file, err = modfile.ParseLax(path, data, nil); err != nil {
+			return nil
+		}
+	}
+
+	dependencies := []Dependency{}
+	required := map[string]bool{}
+	for _, req := range file.Require {
+		dep := Dependency{
+			Name:      req.Mod.Path,
+			Version:   req.Mod.Version,
+			Ecosystem: EcosystemGo,
+			Indirect:  req.Indirect,
+		}
+		if replacement, ok := goReplacement(file.Replace, req.Mod); ok {
+			if modfile.IsDirectoryPath(replacement.Path) {
+				dep.Version = ""
+			} else {
+				dep.Name, dep.Version = replacement.Path, replacement.Version
+			}
+			dep.Replaces = req.Mod.Path
+		}
+		required[dep.Name] = true
+		dependencies = append(dependencies, dep)
+	}
+
+	if file.Go != nil && semver.Compare("v"+file.Go.Version, "v1.17") >= 0 {
+		return dependencies
+	}
+	for name, version := range goSumModules(filepath.Join(filepath.Dir(path), "go.sum")) {
+		if !required[name] {
+			dependencies = append(dependencies, Dependency{
+				Name:      name,
+				Version:   version,
+				Ecosystem: EcosystemGo,
+				Indirect:  true,
+			})
+		}
+	}
+	return dependencies
+}
+
+// goReplacement returns what replaces mod: a directive for its exact
+// version wins over one for every version.
+func goReplacement(replaces []*modfile.Replace, mod module.Version) (module.Version, bool) {
+	var found *modfile.Replace
+	for _, replace := range replaces {
+		if replace.Old.Path != mod.Path {
+			continue
+		}
+		if replace.Old.Version == mod.Version {
+			return replace.New, true
+		}
+		if replace.Old.Version == "" {
+			found = replace
+		}
+	}
+	if found == nil {
+		return module.Version{}, false
+	}
+	return found.New, true
+}
+
+// goSumModules returns the highest version of each module whose source
+// go.sum records. Entries for a go.mod file alone are modules the build
+// only consulted for their requirements.
+func goSumModules(path string) map[string]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	modules := map[string]string{}
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		fields := strings.Fields(lines.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		if current, ok := modules[fields[0]]; !ok || semver.Compare(fields[1], current) > 0 {
+			modules[fields[0]] = fields[1]
+		}
+	}
+	return modules
+}
+
+// npmDeclared is the dependency sections of package.json, which the
+// package-lock.json root and workspace entries repeat.
+type npmDeclared struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+func (d npmDeclared) addTo(direct map[string]bool) {
+	for _, section := range []map[string]string{d.Dependencies, d.DevDependencies, d.OptionalDependencies, d.PeerDependencies} {
+		for name := range section {
+			direct[name] = true
+		}
+	}
+}
+
+// packageJSONDeclared returns the packages the package.json next to a lock
+// file depends on directly.
+func packageJSONDeclared(lockPath string) map[string]bool {
+	direct := map[string]bool{}
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(lockPath), "package.json"))
+	if err != nil {
+		return direct
+	}
+	var manifest npmDeclared
+	if json.Unmarshal(content, &manifest) == nil {
+		manifest.addTo(direct)
+	}
+	return direct
+}
+
+// parsePackageLock marks the packages the root and workspace manifests do
+// not declare as indirect. Without any declarations, only nested copies
+// count as indirect.
+func parsePackageLock(path string) []Dependency {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var lock struct {
+		Packages map[string]struct {
+			npmDeclared
+			Version string `json:"version"`
+			Dev     bool   `json:"dev"`
+			Link    bool   `json:"link"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil
+	}
+
+	dependencies := []Dependency{}
+
+	// lockfileVersion 2 and 3 key packages by their node_modules path.
+	if len(lock.Packages) > 0 {
+		direct := map[string]bool{}
+		for key, pkg := range lock.Packages {
+			if !strings.Contains(key, "node_modules/") {
+				pkg.addTo(direct)
+			}
+		}
+		for key, pkg := range lock.Packages {
+			idx := strings.LastIndex(key, "node_modules/")
+			if idx < 0 || pkg.Link || pkg.Version == "" {
+				continue
+			}
+			name := key[idx+len("node_modules/"):]
+			dependencies = append(dependencies, Dependency{
+				Name:      name,
+				Version:   pkg.Version,
+				Ecosystem: EcosystemNPM,
+				Indirect:  strings.Count(key, "node_modules/") > 1 || (len(direct) > 0 && !direct[name]),
+			})
+		}
+		return dependencies
+	}
+
+	direct := packageJSONDeclared(path)
+	for name, pkg := range lock.Dependencies {
+		dependencies = append(dependencies, Dependency{
+			Name:      name,
+			Version:   pkg.Version,
+			Ecosystem: EcosystemNPM,
+			Indirect:  len(direct) > 0 && !direct[name],
+		})
+	}
+	return dependencies
+}
+
+// parseYarnLock reads both the classic yarn.lock format and the YAML of
+// Yarn 2 and later. Entries list their descriptors, such as
+// "lodash@^4.17.0, lodash@^4.17.21:", followed by the resolved version.
+// Workspaces and patched packages are not registry packages and are skipped.
+func parseYarnLock(path string) []Dependency {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	direct := packageJSONDeclared(path)
+	dependencies := []Dependency{}
+	name := ""
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := lines.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case !strings.HasPrefix(line, " "):
+			name = ""
+			descriptor, _, _ := strings.Cut(strings.TrimSuffix(line, ":"), ",")
+			descriptor = strings.Trim(strings.TrimSpace(descriptor), `"`)
+			if at := strings.LastIndex(descriptor, "@"); at > 0 && !yarnNonRegistry.MatchString(descriptor) {
+				name = descriptor[:at]
+				// Yarn 2 descriptors name the protocol, lodash@npm:^4.17.0,
+				// and aliases keep their installed name: a@npm:b@^1.0.0.
+				if at := strings.Index(name[1:], "@"); at >= 0 {
+					name = name[:at+1]
+				}
+			}
+		case name != "":
+			match := yarnVersion.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			dependencies = append(dependencies, Dependency{
+				Name:      name,
+				Version:   match[1],
+				Ecosystem: EcosystemNPM,
+				Indirect:  len(direct) > 0 && !direct[name],
+			})
+			name = ""
+		}
+	}
+	return dependencies
+}
+
+// parsePnpmLock reads pnpm-lock.yaml from lockfile version 5 to 9. Its
+// importers, the root project and each workspace, declare the direct
+// dependencies.
+func parsePnpmLock(path string) []Dependency {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	type importer struct {
+		Dependencies         map[string]any `yaml:"dependencies"`
+		DevDependencies      map[string]any `yaml:"devDependencies"`
+		OptionalDependencies map[string]any `yaml:"optionalDependencies"`
+	}
+	var lock struct {
+		importer  `yaml:",inline"`
+		Importers map[string]importer `yaml:"importers"`
+		Packages  map[string]any      `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil
+	}
+
+	direct := map[string]bool{}
+	projects := []importer{lock.importer}
+	for _, project := range lock.Importers {
+		projects = append(projects, project)
+	}
+	for _, project := range projects {
+		for _, section := range []map[string]any{project.Dependencies, project.DevDependencies, project.OptionalDependencies} {
+			for name := range section {
+				direct[name] = true
+			}
+		}
+	}
+
+	dependencies := []Dependency{}
+	for key := range lock.Packages {
+		name, version, ok := pnpmPackage(key)
+		if !ok {
+			continue
+		}
+		dependencies = append(dependencies, Dependency{
+			Name:      name,
+			Version:   version,
+			Ecosystem: EcosystemNPM,
+			Indirect:  !direct[name],
+		})
+	}
+	return dependencies
+}
+
+// pnpmPackage splits a packages key: /lodash/4.17.21 in version 5,
+// /lodash@4.17.21 in version 6 and lodash@4.17.21 from version 9, each
+// possibly followed by peer dependency suffixes.
+func pnpmPackage(key string) (name, version string, ok bool) {
+	key = strings.TrimPrefix(key, "/")
+	if idx := strings.Index(key, "("); idx >= 0 {
+		key = key[:idx]
+	}
+	if match := pnpmV5Key.FindStringSubmatch(key); match != nil {
+		name, version = match[1], match[2]
+	} else if at := strings.LastIndex(key, "@"); at > 0 {
+		name, version = key[:at], key[at+1:]
+	}
+	if name == "" || version == "" || strings.Contains(version, ":") {
+		return "", "", false
+	}
+	return name, version, true
+}
+
+func parseRequirements(path string) []Dependency {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	dependencies := []Dependency{}
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		match := requirementPin.FindStringSubmatch(strings.TrimSpace(lines.Text()))
+		if match == nil {
+			continue
+		}
+		name := match[1]
+		if idx := strings.Index(name, "["); idx >= 0 {
+			name = name[:idx]
+		}
+		dependencies = append(dependencies, Dependency{
+			Name:      name,
+			Version:   match[2],
+			Ecosystem: EcosystemPyPI,
+		})
+	}
+	return dependencies
+}
+
+// parseTOMLPackages reads the [[package]] tables shared by poetry.lock and
+// Cargo.lock.
+func parseTOMLPackages(path, ecosystem string) []Dependency {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	dependencies := []Dependency{}
+	var current *Dependency
+	flush := func() {
+		if current != nil && current.Name != "" && current.Version != "" {
+			dependencies = append(dependencies, *current)
+		}
+		current = nil
+	}
+
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		switch {
+		case line == "[[package]]":
+			flush()
+			current = &Dependency{Ecosystem: ecosystem}
+		case strings.HasPrefix(line, "["):
+			flush()
+		case current != nil:
+			if match := tomlName.FindStringSubmatch(line); match != nil {
+				current.Name = match[1]
+			} else if match := tomlVersion.FindStringSubmatch(line); match != nil {
+				current.Version = match[1]
+			}
+		}
+	}
+	flush()
+
+	return dependencies
+}
+
+func dedupe(dependencies []Dependency) []Dependency {
+	result := []Dependency{}
+	for i, dep := range dependencies {
+		if i > 0 {
+			prev := dependencies[i-1]
+			if prev.Ecosystem == dep.Ecosystem && prev.Name == dep.Name && prev.Version == dep.Version {
+				continue
+			}
+		}
+		result = append(result, dep)
+	}
+	return result
+}
@@ -0,0 +1,243 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":                "module x\n\ngo 1.22\n\ntoolchain go1.22.4\n\nrequire github.com/a/b v1.2.3\n\nrequire (\n\tgolang.org/x/net v0.1.0 // indirect\n)\n",
+		"web/package-lock.json": `{"lockfileVersion": 3, "packages": {"": {"name": "web"}, "node_modules/lodash": {"version": "4.17.20"}, "node_modules/a/node_modules/ms": {"version": "2.0.0"}}}`,
+		"requirements.txt":      "# pinned\nDjango==3.2.1\nrequests>=2.0\nuvicorn[standard]==0.20.0 ; python_version > '3.8'\n",
+		"Cargo.lock":            "version = 3\n\n[[package]]\nname = \"serde\"\nversion = \"1.0.100\"\n\n[metadata]\nname = \"ignored\"\n",
+		"node_modules/x/go.mod": "module y\n\nrequire github.com/ignored/dep v1.0.0\n",
+		"tools/go.mod": `module tools
+
+go 1.16
+
+require (
+	github.com/c/d v1.0.0
+	github.com/e/f v0.3.0 // indirect
+	example.com/local v0.0.0
+)
+
+replace github.com/c/d v1.0.0 => github.com/fork/d v1.0.1
+
+replace example.com/local => ../local
+`,
+		"tools/go.sum": `github.com/c/d v1.0.0/go.mod h1:a=
+github.com/fork/d v1.0.1 h1:b=
+github.com/g/h v1.1.0 h1:c=
+github.com/g/h v1.10.0 h1:d=
+github.com/g/h v1.10.0/go.mod h1:e=
+github.com/only/gomod v2.0.0/go.mod h1:f=
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dependencies, err := Parse(tempDir)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []Dependency{
+		{Name: "github.com/a/b", Version: "v1.2.3", Ecosystem: EcosystemGo, File: "go.mod"},
+		{Name: "golang.org/x/net", Version: "v0.1.0", Ecosystem: EcosystemGo, File: "go.mod", Indirect: true},
+		{Name: "github.com/fork/d", Version: "v1.0.1", Ecosystem: EcosystemGo, File: "tools/go.mod", Replaces: "github.com/c/d"},
+		{Name: "github.com/e/f", Version: "v0.3.0", Ecosystem: EcosystemGo, File: "tools/go.mod", Indirect: true},
+		{Name: "example.com/local", Ecosystem: EcosystemGo, File: "tools/go.mod", Replaces: "example.com/local"},
+		{Name: "github.com/g/h", Version: "v1.10.0", Ecosystem: EcosystemGo, File: "tools/go.mod", Indirect: true},
+		{Name: "Django", Version: "3.2.1", Ecosystem: EcosystemPyPI, File: "requirements.txt"},
+		{Name: "uvicorn", Version: "0.20.0", Ecosystem: EcosystemPyPI, File: "requirements.txt"},
+		{Name: "serde", Version: "1.0.100", Ecosystem: EcosystemCargo, File: "Cargo.lock"},
+		{Name: "lodash", Version: "4.17.20", Ecosystem: EcosystemNPM, File: "web/package-lock.json"},
+		{Name: "ms", Version: "2.0.0", Ecosystem: EcosystemNPM, File: "web/package-lock.json", Indirect: true},
+	}
+
+	if len(dependencies) != len(expected) {
+		t.Fatalf("Parse() = %+v, want %d dependencies", dependencies, len(expected))
+	}
+	found := map[Dependency]bool{}
+	for _, dep := range dependencies {
+		found[dep] = true
+	}
+	for _, want := range expected {
+		if !found[want] {
+			t.Errorf("Missing dependency %+v", want)
+		}
+	}
+}
+
+func TestParseNodeLockfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		lockfile string
+		content  string
+		manifest string
+		want     []string
+	}{
+		{
+			name:     "package-lock declared by root and workspace",
+			lockfile: "package-lock.json",
+			content: `{"lockfileVersion": 3, "packages": {
+  "": {"name": "app", "dependencies": {"express": "^4.18.0"}, "workspaces": ["ui"]},
+  "ui": {"devDependencies": {"react": "^18.0.0"}},
+  "node_modules/express": {"version": "4.18.2"},
+  "node_modules/react": {"version": "18.2.0", "dev": true},
+  "node_modules/ui": {"link": true, "resolved": "ui"},
+  "node_modules/ms": {"version": "2.1.3"},
+  "node_modules/send/node_modules/ms": {"version": "2.0.0"}
+}}`,
+			want: []string{"express@4.18.2", "ms@2.0.0 indirect", "ms@2.1.3 indirect", "react@18.2.0"},
+		},
+		{
+			name:     "yarn classic",
+			lockfile: "yarn.lock",
+			manifest: `{"dependencies": {"@babel/core": "^7.0.0"}, "devDependencies": {"lodash": "^4.17.0"}}`,
+			content: `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/core@^7.0.0", "@babel/core@^7.12.3":
+  version "7.23.2"
+  resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.23.2.tgz"
+  dependencies:
+    "@babel/code-frame" "^7.22.13"
+
+"@babel/code-frame@^7.22.13":
+  version "7.22.13"
+
+lodash@^4.17.0:
+  version "4.17.21"
+`,
+			want: []string{"@babel/code-frame@7.22.13 indirect", "@babel/core@7.23.2", "lodash@4.17.21"},
+		},
+		{
+			name:     "yarn berry",
+			lockfile: "yarn.lock",
+			manifest: `{"dependencies": {"string-width-cjs": "npm:string-width@^4.2.0"}}`,
+			content: `__metadata:
+  version: 8
+  cacheKey: 10
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "app@workspace:."
+
+"string-width-cjs@npm:string-width@^4.2.0":
+  version: 4.2.3
+  resolution: "string-width@npm:4.2.3"
+
+"resolve@patch:resolve@npm%3A^1.22.0#optional!builtin<compat/resolve>":
+  version: 1.22.8
+
+"@types/node@npm:*, @types/node@npm:^20.0.0":
+  version: 20.8.9
+`,
+			want: []string{"@types/node@20.8.9 indirect", "string-width-cjs@4.2.3"},
+		},
+		{
+			name:     "pnpm 6",
+			lockfile: "pnpm-lock.yaml",
+			content: `lockfileVersion: '6.0'
+dependencies:
+  react-dom:
+    specifier: ^18.2.0
+    version: 18.2.0(react@18.2.0)
+packages:
+  /react-dom@18.2.0(react@18.2.0):
+    resolution: {integrity: sha512-x}
+  /@types/react@18.2.31:
+    resolution: {integrity: sha512-y}
+`,
+			want: []string{"@types/react@18.2.31 indirect", "react-dom@18.2.0"},
+		},
+		{
+			name:     "pnpm 9 workspace",
+			lockfile: "pnpm-lock.yaml",
+			content: `lockfileVersion: '9.0'
+importers:
+  .:
+    devDependencies:
+      typescript:
+        specifier: ^5.2.0
+        version: 5.2.2
+  packages/api:
+    dependencies:
+      fastify:
+        specifier: ^4.0.0
+        version: 4.24.3
+      shared:
+        specifier: workspace:*
+        version: link:../shared
+packages:
+  typescript@5.2.2:
+    resolution: {integrity: sha512-a}
+  fastify@4.24.3:
+    resolution: {integrity: sha512-b}
+  avvio@8.2.1:
+    resolution: {integrity: sha512-c}
+`,
+			want: []string{"avvio@8.2.1 indirect", "fastify@4.24.3", "typescript@5.2.2"},
+		},
+		{
+			name:     "pnpm 5",
+			lockfile: "pnpm-lock.yaml",
+			content: `lockfileVersion: 5.4
+specifiers:
+  debug: ^4.3.0
+dependencies:
+  debug: 4.3.4
+packages:
+  /debug/4.3.4:
+    resolution: {integrity: sha512-d}
+  /@scope/pkg/1.0.0_debug@4.3.4:
+    resolution: {integrity: sha512-e}
+`,
+			want: []string{"@scope/pkg@1.0.0 indirect", "debug@4.3.4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			if tt.manifest != "" {
+				if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(tt.manifest), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.WriteFile(filepath.Join(tempDir, tt.lockfile), []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			dependencies, err := Parse(tempDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, dep := range dependencies {
+				entry := dep.Name + "@" + dep.Version
+				if dep.Indirect {
+					entry += " indirect"
+				}
+				got = append(got, entry)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Decision is an Architecture Decision Record.
+type Decision struct {
+	Number int
+	Title  string
+	Status string
+	Date   string
+	File   string
+	// Summary is the opening paragraph of the decision itself.
+	Summary string
+}
+
+// maxDecisionSummary caps Decision.Summary, in characters.
+const maxDecisionSummary = 300
+
+var (
+	adrDirs = map[string]bool{
+		"adr": true, "adrs": true, "decisions": true, "architecture-decisions": true, "decision-records": true,
+	}
+	adrFileName  = regexp.MustCompile(`(?i)^(adr[-_ ]?)?(\d{1,4})[-_ ].*\.md$`)
+	adrTitle     = regexp.MustCompile(`(?i)^(?:adr[-_ ]?)?\d{1,4}\s*[.:-]\s*`)
+	adrField     = regexp.MustCompile(`(?i)^(?:[-*]\s*)?(?:\*\*)?(status|date)(?:\*\*)?\s*:\s*(?:\*\*)?\s*(.+)$`)
+	adrDate      = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`)
+	markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+)
+
+// detectDecisions reads ADRs: numbered Markdown files such as
+// 0001-use-postgres.md in an ADR directory (docs/adr, doc/decisions, ...),
+// or named ADR-0001-*.md anywhere. Nygard-style "## Status" sections, MADR
+// front matter and "Status:" lines are understood.
+func detectDecisions(ctx context.Context, repoPath string) []Decision {
+	decisions := []Decision{}
+	if repoPath == "" {
+		return decisions
+	}
+
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		base := path.Base(rel)
+		match := adrFileName.FindStringSubmatch(base)
+		if match == nil || (match[1] == "" && !adrDirs[strings.ToLower(path.Base(path.Dir(rel)))]) {
+			return
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return
+		}
+		decision := parseDecision(string(content))
+		if decision.Title == "" || strings.Contains(strings.ToLower(base), "template") {
+			return
+		}
+		decision.Number, _ = strconv.Atoi(match[2])
+		decision.File = rel
+		decisions = append(decisions, decision)
+	})
+
+	sort.SliceStable(decisions, func(i, j int) bool {
+		if decisions[i].Number != decisions[j].Number {
+			return decisions[i].Number < decisions[j].Number
+		}
+		return decisions[i].File < decisions[j].File
+	})
+	return decisions
+}
+
+func parseDecision(content string) Decision {
+	decision := Decision{}
+	section := ""
+	frontMatter := false
+	summary := []string{}
+	summaryDone := false
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if i == 0 && trimmed == "---" {
+			frontMatter = true
+			continue
+		}
+		if frontMatter {
+			if trimmed == "---" {
+				frontMatter = false
+			} else if key, value, ok := strings.Cut(trimmed, ":"); ok {
+				setDecisionField(&decision, key, value)
+			}
+			continue
+		}
+
+		if heading, ok := strings.CutPrefix(trimmed, "#"); ok {
+			level := strings.TrimLeft(heading, "#")
+			title := strings.TrimSpace(level)
+			if len(heading) == len(level) && decision.Title == "" {
+				decision.Title = adrTitle.ReplaceAllString(title, "")
+				continue
+			}
+			section = strings.ToLower(title)
+			continue
+		}
+
+		if match := adrField.FindStringSubmatch(trimmed); match != nil {
+			setDecisionField(&decision, match[1], match[2])
+			continue
+		}
+		switch {
+		case section == "status" && trimmed != "" && decision.Status == "":
+			decision.Status = trimmed
+		case (section == "decision" || strings.HasPrefix(section, "decision outcome")) && !summaryDone:
+			if trimmed == "" {
+				summaryDone = len(summary) > 0
+				continue
+			}
+			summary = append(summary, trimmed)
+		}
+		if decision.Date == "" && i < 15 {
+			decision.Date = adrDate.FindString(trimmed)
+		}
+	}
+
+	decision.Status = cleanMarkdown(decision.Status)
+	decision.Summary = cleanMarkdown(strings.Join(summary, " "))
+	if runes := []rune(decision.Summary); len(runes) > maxDecisionSummary {
+		decision.Summary = strings.TrimSpace(string(runes[:maxDecisionSummary])) + "…"
+	}
+	return decision
+}
+
+func setDecisionField(decision *Decision, key, value string) {
+	value = strings.Trim(strings.TrimSpace(value), `"'*`)
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "status":
+		if decision.Status == "" {
+			decision.Status = value
+		}
+	case "date":
+		if decision.Date == "" {
+			decision.Date = value
+		}
+	case "title":
+		if decision.Title == "" {
+			decision.Title = value
+		}
+	}
+}
+
+// cleanMarkdown keeps the text of links and drops emphasis markers.
+func cleanMarkdown(text string) string {
+	text = markdownLink.ReplaceAllString(text, "$1")
+	return strings.TrimSpace(strings.NewReplacer("**", "", "__", "", "`", "").Replace(text))
+}
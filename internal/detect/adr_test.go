@@ -0,0 +1,71 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectDecisions(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"docs/adr/0001-record-architecture-decisions.md": `# 1. Record architecture decisions
+
+Date: 2021-03-04
+
+## Status
+
+Accepted
+
+## Context
+
+We need to record the architectural decisions made on this project.
+
+## Decision
+
+We will use Architecture Decision Records, as
+[described by Michael Nygard](http://thinkrelevance.com/blog/2011/11/15/documenting-architecture-decisions).
+
+## Consequences
+
+See Michael Nygard's article.
+`,
+		"docs/adr/0002-use-postgres.md": `---
+status: superseded by ADR-0003
+date: 2022-01-10
+---
+# Use PostgreSQL for orders
+
+## Decision Outcome
+
+Chosen option: **PostgreSQL**, because the team knows it.
+`,
+		"services/billing/ADR-003-event-sourcing.md": "# ADR-003: Event sourcing for billing\n\n* Status: proposed\n* Date: 2023-06-01\n",
+		"docs/adr/template.md":                       "# Title\n\n## Status\n\nProposed\n",
+		"docs/adr/0000-template.md":                  "# [short title]\n\n## Status\n\n[proposed]\n",
+		"notes/0001-meeting.md":                      "# Standup\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []Decision{
+		{Number: 1, Title: "Record architecture decisions", Status: "Accepted", Date: "2021-03-04", File: "docs/adr/0001-record-architecture-decisions.md",
+			Summary: "We will use Architecture Decision Records, as described by Michael Nygard."},
+		{Number: 2, Title: "Use PostgreSQL for orders", Status: "superseded by ADR-0003", Date: "2022-01-10", File: "docs/adr/0002-use-postgres.md",
+			Summary: "Chosen option: PostgreSQL, because the team knows it."},
+		{Number: 3, Title: "Event sourcing for billing", Status: "proposed", Date: "2023-06-01", File: "services/billing/ADR-003-event-sourcing.md"},
+	}
+	if got := detectDecisions(context.Background(), tempDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("detectDecisions(context.Background(), ) =\n%+v\nwant\n%+v", got, want)
+	}
+}
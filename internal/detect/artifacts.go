@@ -0,0 +1,309 @@
+package detect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Artifact is something the repository builds and ships: a binary, a
+// container image or a language package.
+type Artifact struct {
+	Kind        string
+	Name        string
+	Targets     []string
+	Destination string
+	Source      string
+}
+
+var (
+	goreleaserDefaultOS   = []string{"linux", "darwin", "windows"}
+	goreleaserDefaultArch = []string{"amd64", "arm64", "386"}
+
+	dockerImageLabel = regexp.MustCompile(`(?im)^\s*LABEL\s+.*org\.opencontainers\.image\.source="?([^"\s]+)`)
+	pyprojectName    = regexp.MustCompile(`(?m)^\s*name\s*=\s*["']([^"']+)["']`)
+	setupPyName      = regexp.MustCompile(`name\s*=\s*["']([^"']+)["']`)
+	setupCfgName     = regexp.MustCompile(`(?m)^\s*name\s*=\s*(\S+)`)
+)
+
+type goreleaserConfig struct {
+	ProjectName string `yaml:"project_name"`
+	Builds      []struct {
+		ID     string   `yaml:"id"`
+		Binary string   `yaml:"binary"`
+		Goos   []string `yaml:"goos"`
+		Goarch []string `yaml:"goarch"`
+		Ignore []struct {
+			Goos   string `yaml:"goos"`
+			Goarch string `yaml:"goarch"`
+		} `yaml:"ignore"`
+	} `yaml:"builds"`
+	Dockers []struct {
+		ImageTemplates []string `yaml:"image_templates"`
+	} `yaml:"dockers"`
+	Brews []struct {
+		Name       string `yaml:"name"`
+		Repository struct {
+			Owner string `yaml:"owner"`
+			Name  string `yaml:"name"`
+		} `yaml:"repository"`
+		Tap struct {
+			Owner string `yaml:"owner"`
+			Name  string `yaml:"name"`
+		} `yaml:"tap"`
+	} `yaml:"brews"`
+	Release struct {
+		GitHub struct {
+			Owner string `yaml:"owner"`
+			Name  string `yaml:"name"`
+		} `yaml:"github"`
+	} `yaml:"release"`
+}
+
+func detectArtifacts(ctx context.Context, repoPath string) []Artifact {
+	if repoPath == "" {
+		return []Artifact{}
+	}
+
+	artifacts := []Artifact{}
+	walkRepo(ctx, repoPath, func(path, rel string) {
+		base := filepath.Base(rel)
+		lower := strings.ToLower(base)
+
+		switch {
+		case lower == ".goreleaser.yml" || lower == ".goreleaser.yaml" || lower == "goreleaser.yml" || lower == "goreleaser.yaml":
+			artifacts = append(artifacts, goreleaserArtifacts(path, rel)...)
+		case IsDockerfile(base):
+			artifacts = append(artifacts, dockerfileArtifact(path, rel))
+		case base == "package.json":
+			if artifact, ok := npmArtifact(path, rel); ok {
+				artifacts = append(artifacts, artifact)
+			}
+		case base == "pyproject.toml" || base == "setup.py" || base == "setup.cfg":
+			if artifact, ok := pypiArtifact(path, rel); ok {
+				artifacts = append(artifacts, artifact)
+			}
+		}
+	})
+
+	return deduplicateArtifacts(artifacts)
+}
+
+func goreleaserArtifacts(path, rel string) []Artifact {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var config goreleaserConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil
+	}
+
+	destination := "GitHub Releases"
+	if config.Release.GitHub.Owner != "" && config.Release.GitHub.Name != "" {
+		destination = fmt.Sprintf("GitHub Releases (%s/%s)", config.Release.GitHub.Owner, config.Release.GitHub.Name)
+	}
+
+	artifacts := []Artifact{}
+	for _, build := range config.Builds {
+		name := build.Binary
+		if name == "" {
+			name = build.ID
+		}
+		if name == "" {
+			name = config.ProjectName
+		}
+
+		goos := build.Goos
+		if len(goos) == 0 {
+			goos = goreleaserDefaultOS
+		}
+		goarch := build.Goarch
+		if len(goarch) == 0 {
+			goarch = goreleaserDefaultArch
+		}
+
+		ignored := map[string]bool{}
+		for _, ignore := range build.Ignore {
+			ignored[ignore.Goos+"/"+ignore.Goarch] = true
+		}
+
+		targets := []string{}
+		for _, system := range goos {
+			for _, arch := range goarch {
+				target := system + "/" + arch
+				if !ignored[target] {
+					targets = append(targets, target)
+				}
+			}
+		}
+
+		artifacts = append(artifacts, Artifact{
+			Kind:        "binary",
+			Name:        name,
+			Targets:     targets,
+			Destination: destination,
+			Source:      rel,
+		})
+	}
+
+	for _, docker := range config.Dockers {
+		for _, image := range docker.ImageTemplates {
+			artifacts = append(artifacts, Artifact{
+				Kind:        "image",
+				Name:        image,
+				Destination: imageRegistry(image),
+				Source:      rel,
+			})
+		}
+	}
+
+	for _, brew := range config.Brews {
+		tap := brew.Repository
+		if tap.Owner == "" {
+			tap = brew.Tap
+		}
+		name := brew.Name
+		if name == "" {
+			name = config.ProjectName
+		}
+		destination := "Homebrew tap"
+		if tap.Owner != "" {
+			destination = fmt.Sprintf("Homebrew tap (%s/%s)", tap.Owner, tap.Name)
+		}
+		artifacts = append(artifacts, Artifact{
+			Kind:        "homebrew",
+			Name:        name,
+			Destination: destination,
+			Source:      rel,
+		})
+	}
+
+	return artifacts
+}
+
+func dockerfileArtifact(path, rel string) Artifact {
+	artifact := Artifact{
+		Kind:        "image",
+		Name:        filepath.Dir(rel),
+		Destination: "unspecified registry",
+		Source:      rel,
+	}
+	if artifact.Name == "." {
+		artifact.Name = "(repository root)"
+	}
+
+	content, err := os.ReadFile(path)
+	if err == nil {
+		if match := dockerImageLabel.FindStringSubmatch(string(content)); match != nil {
+			artifact.Destination = match[1]
+		}
+	}
+
+	return artifact
+}
+
+func npmArtifact(path, rel string) (Artifact, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Artifact{}, false
+	}
+
+	var pkg struct {
+		Name          string `json:"name"`
+		Private       bool   `json:"private"`
+		PublishConfig struct {
+			Registry string `json:"registry"`
+			Access   string `json:"access"`
+		} `json:"publishConfig"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil || pkg.Name == "" || pkg.Private {
+		return Artifact{}, false
+	}
+
+	destination := "registry.npmjs.org"
+	if pkg.PublishConfig.Registry != "" {
+		destination = strings.TrimPrefix(strings.TrimPrefix(pkg.PublishConfig.Registry, "https://"), "http://")
+		destination = strings.TrimSuffix(destination, "/")
+	}
+
+	return Artifact{
+		Kind:        "npm",
+		Name:        pkg.Name,
+		Destination: destination,
+		Source:      rel,
+	}, true
+}
+
+func pypiArtifact(path, rel string) (Artifact, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Artifact{}, false
+	}
+	text := string(content)
+
+	var match []string
+	switch filepath.Base(rel) {
+	case "pyproject.toml":
+		if !strings.Contains(text, "[project]") && !strings.Contains(text, "[tool.poetry]") {
+			return Artifact{}, false
+		}
+		match = pyprojectName.FindStringSubmatch(text)
+	case "setup.cfg":
+		if !strings.Contains(text, "[metadata]") {
+			return Artifact{}, false
+		}
+		match = setupCfgName.FindStringSubmatch(text)
+	default:
+		match = setupPyName.FindStringSubmatch(text)
+	}
+	if match == nil {
+		return Artifact{}, false
+	}
+
+	return Artifact{
+		Kind:        "pypi",
+		Name:        match[1],
+		Destination: "pypi.org",
+		Source:      rel,
+	}, true
+}
+
+func imageRegistry(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first
+	}
+	return "docker.io"
+}
+
+// deduplicateArtifacts collapses a package declared in several metadata
+// files (setup.py and pyproject.toml, say) into a single entry.
+func deduplicateArtifacts(artifacts []Artifact) []Artifact {
+	seen := make(map[string]bool)
+	result := []Artifact{}
+	for _, artifact := range artifacts {
+		key := artifact.Kind + "|" + artifact.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, artifact)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,51 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectArtifacts(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		".goreleaser.yaml":  "project_name: tool\nbuilds:\n  - binary: tool\n    goos: [linux, darwin]\n    goarch: [amd64, arm64]\n    ignore:\n      - goos: darwin\n        goarch: amd64\ndockers:\n  - image_templates: [\"ghcr.io/acme/tool:latest\"]\n",
+		"Dockerfile":        "FROM alpine\nLABEL org.opencontainers.image.source=\"https://github.com/acme/tool\"\n",
+		"web/package.json":  "{\"name\": \"@acme/web\", \"publishConfig\": {\"registry\": \"https://npm.pkg.github.com/\"}}",
+		"app/package.json":  "{\"name\": \"app\", \"private\": true}",
+		"py/pyproject.toml": "[project]\nname = \"acme-tool\"\n",
+		"py/setup.py":       "setup(name='acme-tool')\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifacts := detectArtifacts(context.Background(), tempDir)
+
+	byKind := map[string][]Artifact{}
+	for _, artifact := range artifacts {
+		byKind[artifact.Kind] = append(byKind[artifact.Kind], artifact)
+	}
+
+	binaries := byKind["binary"]
+	if len(binaries) != 1 || len(binaries[0].Targets) != 3 {
+		t.Errorf("Expected one binary with 3 targets, got %+v", binaries)
+	}
+	if len(byKind["image"]) != 2 {
+		t.Errorf("Expected goreleaser and Dockerfile images, got %+v", byKind["image"])
+	}
+	if npm := byKind["npm"]; len(npm) != 1 || npm[0].Destination != "npm.pkg.github.com" {
+		t.Errorf("Expected one public npm package, got %+v", npm)
+	}
+	if len(byKind["pypi"]) != 1 {
+		t.Errorf("Expected PyPI package to be deduplicated, got %+v", byKind["pypi"])
+	}
+}
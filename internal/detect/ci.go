@@ -0,0 +1,484 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pipeline is a CI workflow: a GitHub Actions workflow, a GitLab CI config,
+// a Jenkinsfile or a CircleCI workflow.
+type Pipeline struct {
+	// System is github-actions, gitlab-ci, jenkins or circleci.
+	System   string
+	Name     string
+	File     string
+	Triggers []string
+	Jobs     []PipelineJob
+}
+
+// PipelineJob is a job, or a Jenkins stage, with the shell commands it runs.
+type PipelineJob struct {
+	Name        string
+	Stage       string
+	Needs       []string
+	Environment string
+	// Conditions are the branch filters and expressions gating the job.
+	Conditions []string
+	Commands   []string
+	// Uses lists the GitHub actions, reusable workflows and CircleCI orb
+	// commands the job calls.
+	Uses []string
+	// Deploy is set when the job deploys, releases or publishes.
+	Deploy bool
+}
+
+var (
+	deployCommand = regexp.MustCompile(`(?i)\b(deploy|release|publish)|kubectl (apply|rollout|set image)|helm (upgrade|install)|terraform apply|docker (push|buildx build .*--push)|goreleaser|serverless deploy|cdk deploy`)
+
+	jenkinsStage    = regexp.MustCompile(`\bstage\s*\(\s*['"]([^'"]+)['"]`)
+	jenkinsCommand  = regexp.MustCompile(`\b(?:sh|bat|pwsh|powershell)\s*\(?\s*(?:script:\s*)?('''|"""|'|")(.*)`)
+	jenkinsBranch   = regexp.MustCompile(`\bbranch\s*\(?\s*['"]([^'"]+)['"]`)
+	jenkinsCron     = regexp.MustCompile(`\b(cron|pollSCM)\s*\(\s*['"]([^'"]+)['"]`)
+	jenkinsUpstream = regexp.MustCompile(`\bupstream\s*\(`)
+
+	gitlabKeywords = map[string]bool{
+		"stages": true, "variables": true, "image": true, "include": true, "default": true,
+		"workflow": true, "services": true, "before_script": true, "after_script": true,
+		"cache": true,
+	}
+	circleBuiltinSteps = map[string]bool{
+		"checkout": true, "save_cache": true, "restore_cache": true, "persist_to_workspace": true,
+		"attach_workspace": true, "store_artifacts": true, "store_test_results": true,
+		"setup_remote_docker": true, "add_ssh_keys": true, "when": true, "unless": true,
+	}
+)
+
+// detectPipelines reads the CI configurations at their conventional paths;
+// walkRepo would skip .github and .circleci.
+func detectPipelines(repoPath string) []Pipeline {
+	pipelines := []Pipeline{}
+	if repoPath == "" {
+		return pipelines
+	}
+
+	workflows, _ := filepath.Glob(filepath.Join(repoPath, ".github", "workflows", "*.y*ml"))
+	for _, p := range workflows {
+		if pipeline, ok := parseGitHubWorkflow(p, ".github/workflows/"+filepath.Base(p)); ok {
+			pipelines = append(pipelines, pipeline)
+		}
+	}
+	if pipeline, ok := parseGitLabCI(filepath.Join(repoPath, ".gitlab-ci.yml"), ".gitlab-ci.yml"); ok {
+		pipelines = append(pipelines, pipeline)
+	}
+	if pipeline, ok := parseJenkinsfile(filepath.Join(repoPath, "Jenkinsfile"), "Jenkinsfile"); ok {
+		pipelines = append(pipelines, pipeline)
+	}
+	pipelines = append(pipelines, parseCircleCI(filepath.Join(repoPath, ".circleci", "config.yml"), ".circleci/config.yml")...)
+	return pipelines
+}
+
+func parseGitHubWorkflow(p, rel string) (Pipeline, bool) {
+	var workflow struct {
+		Name string    `yaml:"name"`
+		On   yaml.Node `yaml:"on"`
+		Jobs yaml.Node `yaml:"jobs"`
+	}
+	if !readYAML(p, &workflow) || workflow.Jobs.Kind != yaml.MappingNode {
+		return Pipeline{}, false
+	}
+
+	pipeline := Pipeline{
+		System:   "github-actions",
+		Name:     firstNonEmpty(workflow.Name, strings.TrimSuffix(strings.TrimSuffix(filepath.Base(p), ".yml"), ".yaml")),
+		File:     rel,
+		Triggers: githubTriggers(&workflow.On),
+	}
+	for i := 0; i+1 < len(workflow.Jobs.Content); i += 2 {
+		var job struct {
+			Name        string    `yaml:"name"`
+			Needs       yaml.Node `yaml:"needs"`
+			If          string    `yaml:"if"`
+			Uses        string    `yaml:"uses"`
+			Environment yaml.Node `yaml:"environment"`
+			Steps       []struct {
+				Uses string `yaml:"uses"`
+				Run  string `yaml:"run"`
+			} `yaml:"steps"`
+		}
+		if err := workflow.Jobs.Content[i+1].Decode(&job); err != nil {
+			continue
+		}
+
+		environment := job.Environment.Value
+		if job.Environment.Kind == yaml.MappingNode {
+			environment = mappingValue(&job.Environment, "name")
+		}
+		pipelineJob := PipelineJob{
+			Name:        firstNonEmpty(job.Name, workflow.Jobs.Content[i].Value),
+			Needs:       nodeStrings(&job.Needs),
+			Environment: environment,
+		}
+		if job.If != "" {
+			pipelineJob.Conditions = []string{job.If}
+		}
+		if job.Uses != "" {
+			pipelineJob.Uses = append(pipelineJob.Uses, job.Uses)
+		}
+		for _, step := range job.Steps {
+			if step.Uses != "" {
+				pipelineJob.Uses = append(pipelineJob.Uses, step.Uses)
+			}
+			pipelineJob.Commands = append(pipelineJob.Commands, shellLines(step.Run)...)
+		}
+		pipeline.Jobs = append(pipeline.Jobs, withDeploy(pipelineJob))
+	}
+	return pipeline, true
+}
+
+// githubTriggers lists the events of an `on:` key, with their branch, tag
+// and schedule filters.
+func githubTriggers(on *yaml.Node) []string {
+	if on.Kind != yaml.MappingNode {
+		return nodeStrings(on)
+	}
+	triggers := []string{}
+	for i := 0; i+1 < len(on.Content); i += 2 {
+		event, value := on.Content[i].Value, on.Content[i+1]
+		filters := []string{}
+		switch {
+		case event == "schedule":
+			for _, entry := range value.Content {
+				if cron := mappingValue(entry, "cron"); cron != "" {
+					filters = append(filters, cron)
+				}
+			}
+		case value.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				key := value.Content[j].Value
+				switch key {
+				case "branches":
+					filters = append(filters, nodeStrings(value.Content[j+1])...)
+				case "tags":
+					for _, tag := range nodeStrings(value.Content[j+1]) {
+						filters = append(filters, "tag "+tag)
+					}
+				}
+			}
+		}
+		if len(filters) > 0 {
+			event += " (" + strings.Join(filters, ", ") + ")"
+		}
+		triggers = append(triggers, event)
+	}
+	return triggers
+}
+
+func parseGitLabCI(p, rel string) (Pipeline, bool) {
+	var root yaml.Node
+	if !readYAML(p, &root) || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return Pipeline{}, false
+	}
+	config := root.Content[0]
+
+	pipeline := Pipeline{System: "gitlab-ci", Name: "GitLab CI", File: rel}
+	for i := 0; i+1 < len(config.Content); i += 2 {
+		name, value := config.Content[i].Value, config.Content[i+1]
+		if name == "workflow" {
+			var workflow struct {
+				Rules []struct {
+					If string `yaml:"if"`
+				} `yaml:"rules"`
+			}
+			if value.Decode(&workflow) == nil {
+				for _, rule := range workflow.Rules {
+					if rule.If != "" {
+						pipeline.Triggers = append(pipeline.Triggers, rule.If)
+					}
+				}
+			}
+			continue
+		}
+		// Hidden keys are templates for extends.
+		if gitlabKeywords[name] || strings.HasPrefix(name, ".") || value.Kind != yaml.MappingNode {
+			continue
+		}
+
+		var job struct {
+			Stage       string    `yaml:"stage"`
+			Script      yaml.Node `yaml:"script"`
+			Trigger     yaml.Node `yaml:"trigger"`
+			Needs       yaml.Node `yaml:"needs"`
+			Only        yaml.Node `yaml:"only"`
+			Environment yaml.Node `yaml:"environment"`
+			Rules       []struct {
+				If string `yaml:"if"`
+			} `yaml:"rules"`
+		}
+		if err := value.Decode(&job); err != nil || (job.Script.Kind == 0 && job.Trigger.Kind == 0) {
+			continue
+		}
+
+		environment := job.Environment.Value
+		if job.Environment.Kind == yaml.MappingNode {
+			environment = mappingValue(&job.Environment, "name")
+		}
+		pipelineJob := PipelineJob{Name: name, Stage: job.Stage, Environment: environment}
+		for _, need := range job.Needs.Content {
+			pipelineJob.Needs = append(pipelineJob.Needs, firstNonEmpty(need.Value, mappingValue(need, "job")))
+		}
+		if job.Only.Kind == yaml.MappingNode {
+			pipelineJob.Conditions = nodeStrings(mappingNode(&job.Only, "refs"))
+		} else {
+			pipelineJob.Conditions = nodeStrings(&job.Only)
+		}
+		for _, rule := range job.Rules {
+			if rule.If != "" {
+				pipelineJob.Conditions = append(pipelineJob.Conditions, rule.If)
+			}
+		}
+		for _, line := range nodeStrings(&job.Script) {
+			pipelineJob.Commands = append(pipelineJob.Commands, shellLines(line)...)
+		}
+		if project := firstNonEmpty(job.Trigger.Value, mappingValue(&job.Trigger, "project"), mappingValue(&job.Trigger, "include")); project != "" {
+			pipelineJob.Uses = append(pipelineJob.Uses, project)
+		}
+		pipeline.Jobs = append(pipeline.Jobs, withDeploy(pipelineJob))
+	}
+	return pipeline, len(pipeline.Jobs) > 0
+}
+
+// parseJenkinsfile reads the stages of a declarative or scripted pipeline
+// line by line. Commands before the first stage belong to a "pipeline" job.
+func parseJenkinsfile(p, rel string) (Pipeline, bool) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return Pipeline{}, false
+	}
+
+	pipeline := Pipeline{System: "jenkins", Name: "Jenkins", File: rel}
+	current := func() *PipelineJob {
+		if len(pipeline.Jobs) == 0 {
+			pipeline.Jobs = append(pipeline.Jobs, PipelineJob{Name: "pipeline"})
+		}
+		return &pipeline.Jobs[len(pipeline.Jobs)-1]
+	}
+
+	multiline := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if multiline != "" {
+			if end := strings.Index(trimmed, multiline); end >= 0 {
+				trimmed, multiline = trimmed[:end], ""
+			}
+			current().Commands = append(current().Commands, shellLines(trimmed)...)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		if match := jenkinsStage.FindStringSubmatch(trimmed); match != nil {
+			pipeline.Jobs = append(pipeline.Jobs, PipelineJob{Name: match[1]})
+		}
+		if match := jenkinsBranch.FindStringSubmatch(trimmed); match != nil {
+			current().Conditions = append(current().Conditions, "branch "+match[1])
+		}
+		for _, match := range jenkinsCron.FindAllStringSubmatch(trimmed, -1) {
+			pipeline.Triggers = append(pipeline.Triggers, match[1]+" ("+match[2]+")")
+		}
+		if jenkinsUpstream.MatchString(trimmed) {
+			pipeline.Triggers = append(pipeline.Triggers, "upstream")
+		}
+
+		match := jenkinsCommand.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+		quote, rest := match[1], match[2]
+		if end := strings.Index(rest, quote); end >= 0 {
+			current().Commands = append(current().Commands, shellLines(rest[:end])...)
+		} else if len(quote) == 3 {
+			multiline = quote
+			current().Commands = append(current().Commands, shellLines(rest)...)
+		}
+	}
+
+	for i := range pipeline.Jobs {
+		pipeline.Jobs[i] = withDeploy(pipeline.Jobs[i])
+	}
+	return pipeline, len(pipeline.Jobs) > 0
+}
+
+// parseCircleCI returns a pipeline per workflow, with the jobs it runs in
+// workflow order.
+func parseCircleCI(p, rel string) []Pipeline {
+	var config struct {
+		Jobs      yaml.Node `yaml:"jobs"`
+		Workflows yaml.Node `yaml:"workflows"`
+	}
+	if !readYAML(p, &config) {
+		return nil
+	}
+
+	jobs := map[string]PipelineJob{}
+	order := []string{}
+	for i := 0; i+1 < len(config.Jobs.Content); i += 2 {
+		name := config.Jobs.Content[i].Value
+		var job struct {
+			Steps []yaml.Node `yaml:"steps"`
+		}
+		if config.Jobs.Content[i+1].Decode(&job) != nil {
+			continue
+		}
+		pipelineJob := PipelineJob{Name: name}
+		for _, step := range job.Steps {
+			switch step.Kind {
+			case yaml.ScalarNode:
+				if !circleBuiltinSteps[step.Value] {
+					pipelineJob.Uses = append(pipelineJob.Uses, step.Value)
+				}
+			case yaml.MappingNode:
+				if len(step.Content) < 2 {
+					continue
+				}
+				key, value := step.Content[0].Value, step.Content[1]
+				switch {
+				case key == "run":
+					pipelineJob.Commands = append(pipelineJob.Commands, shellLines(firstNonEmpty(value.Value, mappingValue(value, "command")))...)
+				case !circleBuiltinSteps[key]:
+					pipelineJob.Uses = append(pipelineJob.Uses, key)
+				}
+			}
+		}
+		jobs[name] = pipelineJob
+		order = append(order, name)
+	}
+
+	pipelines := []Pipeline{}
+	for i := 0; i+1 < len(config.Workflows.Content); i += 2 {
+		name, value := config.Workflows.Content[i].Value, config.Workflows.Content[i+1]
+		if value.Kind != yaml.MappingNode {
+			continue
+		}
+		pipeline := Pipeline{System: "circleci", Name: name, File: rel}
+		for _, trigger := range mappingNode(value, "triggers").Content {
+			if cron := mappingValue(mappingNode(trigger, "schedule"), "cron"); cron != "" {
+				pipeline.Triggers = append(pipeline.Triggers, "schedule ("+cron+")")
+			}
+		}
+		if len(pipeline.Triggers) == 0 {
+			pipeline.Triggers = []string{"push"}
+		}
+		for _, ref := range mappingNode(value, "jobs").Content {
+			jobName, settings := ref.Value, (*yaml.Node)(nil)
+			if ref.Kind == yaml.MappingNode && len(ref.Content) == 2 {
+				jobName, settings = ref.Content[0].Value, ref.Content[1]
+			}
+			job, ok := jobs[jobName]
+			if !ok {
+				// An orb job such as aws-ecr/build-and-push-image.
+				job = PipelineJob{Name: jobName, Uses: []string{jobName}}
+			}
+			job.Name = firstNonEmpty(mappingValue(settings, "name"), jobName)
+			job.Needs = nodeStrings(mappingNode(settings, "requires"))
+			job.Environment = mappingValue(settings, "context")
+			filters := mappingNode(settings, "filters")
+			job.Conditions = nodeStrings(mappingNode(mappingNode(filters, "branches"), "only"))
+			for _, tag := range nodeStrings(mappingNode(mappingNode(filters, "tags"), "only")) {
+				job.Conditions = append(job.Conditions, "tag "+tag)
+			}
+			pipeline.Jobs = append(pipeline.Jobs, withDeploy(job))
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+
+	if len(pipelines) == 0 && len(order) > 0 {
+		pipeline := Pipeline{System: "circleci", Name: "CircleCI", File: rel}
+		for _, name := range order {
+			pipeline.Jobs = append(pipeline.Jobs, withDeploy(jobs[name]))
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+	return pipelines
+}
+
+func withDeploy(job PipelineJob) PipelineJob {
+	job.Deploy = deployCommand.MatchString(job.Name) || deployCommand.MatchString(job.Environment)
+	for _, command := range append(append([]string{}, job.Commands...), job.Uses...) {
+		if deployCommand.MatchString(command) {
+			job.Deploy = true
+		}
+	}
+	return job
+}
+
+// shellLines splits a script into its commands, joining continuation lines
+// and dropping comments.
+func shellLines(script string) []string {
+	lines := []string{}
+	pending := ""
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSpace(strings.TrimSuffix(line, "\\")) + " "
+			continue
+		}
+		lines = append(lines, pending+line)
+		pending = ""
+	}
+	if pending != "" {
+		lines = append(lines, strings.TrimSpace(pending))
+	}
+	return lines
+}
+
+func readYAML(p string, out any) bool {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return false
+	}
+	return yaml.Unmarshal(content, out) == nil
+}
+
+// nodeStrings returns a scalar's value or a sequence's scalar values.
+func nodeStrings(node *yaml.Node) []string {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Value != "" {
+			return []string{node.Value}
+		}
+	case yaml.SequenceNode:
+		values := []string{}
+		for _, item := range node.Content {
+			values = append(values, nodeStrings(item)...)
+		}
+		return values
+	}
+	return nil
+}
+
+func mappingNode(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return &yaml.Node{}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return &yaml.Node{}
+}
+
+func mappingValue(node *yaml.Node, key string) string {
+	return mappingNode(node, key).Value
+}
@@ -0,0 +1,130 @@
+package detect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectPipelines(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		".github/workflows/deploy.yml": `name: Deploy
+on:
+  push:
+    branches: [main]
+    tags: ["v*"]
+  schedule:
+    - cron: "0 3 * * 1"
+  workflow_dispatch:
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: |
+          # unit tests
+          go test ./...
+          go vet \
+            ./...
+  ship:
+    needs: test
+    if: github.ref == 'refs/heads/main'
+    environment:
+      name: production
+    steps:
+      - run: helm upgrade --install api ./chart
+`,
+		".gitlab-ci.yml": `stages: [build, release]
+.defaults:
+  script: ["echo template"]
+build:
+  stage: build
+  script:
+    - make build
+release:
+  stage: release
+  needs: [build]
+  environment: production
+  only: [main]
+  script: ./scripts/release.sh
+`,
+		"Jenkinsfile": `pipeline {
+  triggers { cron('H 4 * * *') }
+  stages {
+    stage('Build') {
+      steps {
+        sh 'npm ci'
+        sh """
+          npm run build
+        """
+      }
+    }
+    stage('Publish') {
+      when { branch 'main' }
+      steps { sh "npm publish" }
+    }
+  }
+}
+`,
+		".circleci/config.yml": `version: 2.1
+jobs:
+  test:
+    steps:
+      - checkout
+      - node/install-packages
+      - run: npm test
+      - run:
+          name: Lint
+          command: npm run lint
+workflows:
+  main:
+    jobs:
+      - test
+      - aws-ecr/build-and-push-image:
+          requires: [test]
+          filters:
+            branches:
+              only: main
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := []string{}
+	for _, pipeline := range detectPipelines(tempDir) {
+		got = append(got, fmt.Sprintf("%s %s [%s]", pipeline.System, pipeline.Name, strings.Join(pipeline.Triggers, "; ")))
+		for _, job := range pipeline.Jobs {
+			got = append(got, fmt.Sprintf("  %s stage=%s needs=%v env=%s when=%v uses=%v deploy=%v: %s",
+				job.Name, job.Stage, job.Needs, job.Environment, job.Conditions, job.Uses, job.Deploy, strings.Join(job.Commands, "; ")))
+		}
+	}
+	want := []string{
+		"github-actions Deploy [push (main, tag v*); schedule (0 3 * * 1); workflow_dispatch]",
+		"  test stage= needs=[] env= when=[] uses=[actions/checkout@v4] deploy=false: go test ./...; go vet ./...",
+		"  ship stage= needs=[test] env=production when=[github.ref == 'refs/heads/main'] uses=[] deploy=true: helm upgrade --install api ./chart",
+		"gitlab-ci GitLab CI []",
+		"  build stage=build needs=[] env= when=[] uses=[] deploy=false: make build",
+		"  release stage=release needs=[build] env=production when=[main] uses=[] deploy=true: ./scripts/release.sh",
+		"jenkins Jenkins [cron (H 4 * * *)]",
+		"  Build stage= needs=[] env= when=[] uses=[] deploy=false: npm ci; npm run build",
+		"  Publish stage= needs=[] env= when=[branch main] uses=[] deploy=true: npm publish",
+		"circleci main [push]",
+		"  test stage= needs=[] env= when=[] uses=[node/install-packages] deploy=false: npm test; npm run lint",
+		"  aws-ecr/build-and-push-image stage= needs=[test] env= when=[main] uses=[aws-ecr/build-and-push-image] deploy=false: ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("detectPipelines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,431 @@
+package detect
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// CLICommand is one command of a command-line tool. Name is the full
+// invocation path, e.g. "tool db migrate".
+type CLICommand struct {
+	Name        string
+	Description string
+	Flags       []string
+	Framework   string
+	File        string
+}
+
+var (
+	cobraCommand   = regexp.MustCompile(`(?:(\w+)\s*:?=\s*|return\s+)&cobra\.Command\{`)
+	cobraFunc      = regexp.MustCompile(`func\s+(\w+)\([^)]*\)\s*\*cobra\.Command\s*\{`)
+	cobraUse       = regexp.MustCompile(`\bUse:\s*"([^"]*)"`)
+	cobraShort     = regexp.MustCompile(`\bShort:\s*"([^"]*)"`)
+	cobraAdd       = regexp.MustCompile(`(\w+)\.AddCommand\(([^;]*?)\)\s*\n`)
+	cobraFlag      = regexp.MustCompile(`(\w+)\.(?:Persistent)?Flags\(\)\.\w+\(([^\n]*)`)
+	goStringLit    = regexp.MustCompile(`"([^"]*)"`)
+	clickDecorator = regexp.MustCompile(`^@(\w+)\.(command|group)\((.*)\)\s*$`)
+	clickOption    = regexp.MustCompile(`^@(?:click\.)?(option|argument)\(\s*['"]([^'"]+)['"]`)
+	pyDef          = regexp.MustCompile(`^(?:async\s+)?def\s+(\w+)\s*\(`)
+	pyStringArg    = regexp.MustCompile(`^\s*['"]([^'"]+)['"]`)
+	pyKeywordArg   = regexp.MustCompile(`\b(help|description|prog|name)\s*=\s*['"]([^'"]*)['"]`)
+	argparseRoot   = regexp.MustCompile(`(\w+)\s*=\s*(?:argparse\.)?ArgumentParser\((.*)`)
+	argparseSubs   = regexp.MustCompile(`(\w+)\s*=\s*(\w+)\.add_subparsers\(`)
+	argparseParser = regexp.MustCompile(`(\w+)\s*=\s*(\w+)\.add_parser\((.*)`)
+	argparseArg    = regexp.MustCompile(`(\w+)\.add_argument\(\s*['"]([^'"]+)['"]`)
+	jsCommand      = regexp.MustCompile(`(?:(\w+)\s*=\s*)?(\w+)?\s*\.command\(\s*['"\x60]([^'"\x60]+)['"\x60]`)
+	jsName         = regexp.MustCompile(`\.name\(\s*['"\x60]([^'"\x60]+)['"\x60]`)
+	jsDescription  = regexp.MustCompile(`\.description\(\s*['"\x60]([^'"\x60]*)['"\x60]`)
+	jsOption       = regexp.MustCompile(`\.(?:requiredOption|option)\(\s*['"\x60]([^'"\x60]+)['"\x60]`)
+)
+
+// detectCLICommands builds command trees for cobra, click, argparse and
+// commander programs in the scanned files. Cobra trees are resolved across
+// files because subcommands usually live next to their own flags.
+func detectCLICommands(files []scanner.FileInfo) []CLICommand {
+	cobra := newCobraTree()
+	commands := []CLICommand{}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			continue
+		}
+		text := string(content)
+
+		switch file.Language {
+		case "go":
+			if strings.Contains(text, "github.com/spf13/cobra") {
+				cobra.add(text, file.RelativePath)
+			}
+		case "python":
+			if strings.Contains(text, "import click") || strings.Contains(text, "from click") {
+				commands = append(commands, extractClickCommands(text, file.RelativePath)...)
+			}
+			if strings.Contains(text, "argparse") {
+				commands = append(commands, extractArgparseCommands(text, file.RelativePath)...)
+			}
+		case "javascript", "typescript":
+			if strings.Contains(text, "commander") {
+				commands = append(commands, extractCommanderCommands(text, file.RelativePath)...)
+			}
+		}
+	}
+
+	commands = append(commands, cobra.commands()...)
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+type cobraNode struct {
+	command  CLICommand
+	use      string
+	children []*cobraNode
+	isChild  bool
+}
+
+type cobraEdge struct {
+	parent    *cobraNode
+	parentKey string
+	child     string
+}
+
+type cobraTree struct {
+	all   []*cobraNode
+	nodes map[string]*cobraNode
+	edges []cobraEdge
+}
+
+func newCobraTree() *cobraTree {
+	return &cobraTree{nodes: make(map[string]*cobraNode)}
+}
+
+// add records the cobra.Command literals of one file. Commands are keyed by
+// the variable they are assigned to and, for constructor functions, by the
+// function name so AddCommand(newServeCmd()) resolves too.
+func (t *cobraTree) add(content, file string) {
+	funcs := cobraFunc.FindAllStringSubmatchIndex(content, -1)
+	local := make(map[string]*cobraNode)
+
+	for _, match := range cobraCommand.FindAllStringSubmatchIndex(content, -1) {
+		body := braceBlock(content[match[1]-1:])
+		node := &cobraNode{command: CLICommand{Framework: "cobra", File: file}}
+		if use := cobraUse.FindStringSubmatch(body); use != nil {
+			node.use = firstField(use[1])
+		}
+		if short := cobraShort.FindStringSubmatch(body); short != nil {
+			node.command.Description = short[1]
+		}
+		if node.use == "" {
+			continue
+		}
+		t.all = append(t.all, node)
+
+		if match[2] >= 0 {
+			local[content[match[2]:match[3]]] = node
+			t.nodes[content[match[2]:match[3]]] = node
+		}
+		for i := len(funcs) - 1; i >= 0; i-- {
+			if funcs[i][0] < match[0] {
+				t.nodes[content[funcs[i][2]:funcs[i][3]]] = node
+				break
+			}
+		}
+	}
+
+	for _, match := range cobraFlag.FindAllStringSubmatch(content, -1) {
+		node, ok := local[match[1]]
+		if !ok {
+			continue
+		}
+		if name := goStringLit.FindStringSubmatch(match[2]); name != nil && name[1] != "" {
+			node.command.Flags = appendUnique(node.command.Flags, "--"+name[1])
+		}
+	}
+
+	for _, match := range cobraAdd.FindAllStringSubmatch(content, -1) {
+		for _, child := range strings.Split(match[2], ",") {
+			child, _, _ = strings.Cut(strings.TrimSpace(child), "(")
+			if child != "" {
+				t.edges = append(t.edges, cobraEdge{parent: local[match[1]], parentKey: match[1], child: child})
+			}
+		}
+	}
+}
+
+func (t *cobraTree) commands() []CLICommand {
+	for _, edge := range t.edges {
+		parent := edge.parent
+		if parent == nil {
+			parent = t.nodes[edge.parentKey]
+		}
+		child := t.nodes[edge.child]
+		if parent == nil || child == nil || parent == child || child.isChild {
+			continue
+		}
+		parent.children = append(parent.children, child)
+		child.isChild = true
+	}
+
+	commands := []CLICommand{}
+	seen := make(map[*cobraNode]bool)
+	var visit func(node *cobraNode, prefix string)
+	visit = func(node *cobraNode, prefix string) {
+		if seen[node] {
+			return
+		}
+		seen[node] = true
+
+		command := node.command
+		command.Name = strings.TrimSpace(prefix + " " + node.use)
+		commands = append(commands, command)
+		for _, child := range node.children {
+			visit(child, command.Name)
+		}
+	}
+
+	for _, node := range t.all {
+		if !node.isChild {
+			visit(node, "")
+		}
+	}
+	return commands
+}
+
+// braceBlock returns s up to the brace that closes the one s starts with.
+func braceBlock(s string) string {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[:i+1]
+			}
+		}
+	}
+	return s
+}
+
+func firstField(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func extractClickCommands(content, file string) []CLICommand {
+	lines := strings.Split(content, "\n")
+	groups := make(map[string]string)
+	commands := []CLICommand{}
+
+	var pending *CLICommand
+	var parent, explicitName string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if match := clickDecorator.FindStringSubmatch(trimmed); match != nil {
+			pending = &CLICommand{Framework: "click", File: file}
+			parent = ""
+			if match[1] != "click" {
+				parent = match[1]
+			}
+			explicitName = ""
+			if name := pyStringArg.FindStringSubmatch(match[3]); name != nil {
+				explicitName = name[1]
+			}
+			continue
+		}
+		if pending == nil {
+			continue
+		}
+
+		if match := clickOption.FindStringSubmatch(trimmed); match != nil {
+			flag := match[2]
+			if match[1] == "argument" {
+				flag = "<" + flag + ">"
+			}
+			pending.Flags = append(pending.Flags, flag)
+			continue
+		}
+
+		if match := pyDef.FindStringSubmatch(trimmed); match != nil {
+			name := firstNonEmpty(explicitName, strings.ReplaceAll(match[1], "_", "-"))
+			if prefix, ok := groups[parent]; ok {
+				name = prefix + " " + name
+			}
+			groups[match[1]] = name
+
+			pending.Name = name
+			pending.Description = pythonDocstring(lines[i+1:])
+			commands = append(commands, *pending)
+			pending = nil
+		}
+	}
+
+	return commands
+}
+
+// pythonDocstring returns the first line of a docstring at the start of lines.
+func pythonDocstring(lines []string) string {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		for _, quote := range []string{`"""`, `'''`} {
+			if strings.HasPrefix(trimmed, quote) {
+				doc := strings.TrimPrefix(trimmed, quote)
+				doc, _, _ = strings.Cut(doc, quote)
+				return strings.TrimSpace(doc)
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+func extractArgparseCommands(content, file string) []CLICommand {
+	parsers := make(map[string]int)
+	subparsers := make(map[string]string)
+	commands := []CLICommand{}
+
+	for _, line := range strings.Split(content, "\n") {
+		if match := argparseRoot.FindStringSubmatch(line); match != nil {
+			command := CLICommand{Framework: "argparse", File: file}
+			command.Name = strings.TrimSuffix(path.Base(file), ".py")
+			for _, kw := range pyKeywordArg.FindAllStringSubmatch(match[2], -1) {
+				switch kw[1] {
+				case "prog":
+					command.Name = kw[2]
+				case "description":
+					command.Description = kw[2]
+				}
+			}
+			parsers[match[1]] = len(commands)
+			commands = append(commands, command)
+			continue
+		}
+
+		if match := argparseSubs.FindStringSubmatch(line); match != nil {
+			subparsers[match[1]] = match[2]
+			continue
+		}
+
+		if match := argparseParser.FindStringSubmatch(line); match != nil {
+			parentIdx, ok := parsers[subparsers[match[2]]]
+			name := pyStringArg.FindStringSubmatch(match[3])
+			if !ok || name == nil {
+				continue
+			}
+			command := CLICommand{
+				Name:      commands[parentIdx].Name + " " + name[1],
+				Framework: "argparse",
+				File:      file,
+			}
+			for _, kw := range pyKeywordArg.FindAllStringSubmatch(match[3], -1) {
+				if kw[1] == "help" || kw[1] == "description" {
+					command.Description = firstNonEmpty(command.Description, kw[2])
+				}
+			}
+			parsers[match[1]] = len(commands)
+			commands = append(commands, command)
+			continue
+		}
+
+		if match := argparseArg.FindStringSubmatch(line); match != nil {
+			idx, ok := parsers[match[1]]
+			if !ok {
+				continue
+			}
+			flag := match[2]
+			if !strings.HasPrefix(flag, "-") {
+				flag = "<" + flag + ">"
+			}
+			commands[idx].Flags = appendUnique(commands[idx].Flags, flag)
+		}
+	}
+
+	return commands
+}
+
+// extractCommanderCommands follows commander's fluent API: each .command()
+// call starts a command whose chained .description() and .option() calls
+// run until the next .command() or the end of the statement.
+func extractCommanderCommands(content, file string) []CLICommand {
+	program := "program"
+	if match := jsName.FindStringSubmatch(content); match != nil {
+		program = match[1]
+	}
+
+	root := CLICommand{Name: program, Framework: "commander", File: file}
+	names := make(map[string]string)
+	commands := []CLICommand{}
+
+	matches := jsCommand.FindAllStringSubmatchIndex(content, -1)
+	rootEnd := len(content)
+	if len(matches) > 0 {
+		rootEnd = matches[0][0]
+	}
+	for _, option := range jsOption.FindAllStringSubmatch(content[:rootEnd], -1) {
+		root.Flags = append(root.Flags, commanderFlag(option[1]))
+	}
+
+	for i, match := range matches {
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		chain := content[match[1]:end]
+		if idx := strings.Index(chain, ";"); idx >= 0 {
+			chain = chain[:idx]
+		}
+
+		name := firstField(content[match[6]:match[7]])
+		if match[4] >= 0 {
+			if prefix, ok := names[content[match[4]:match[5]]]; ok {
+				name = prefix + " " + name
+			} else {
+				name = program + " " + name
+			}
+		} else {
+			name = program + " " + name
+		}
+		if match[2] >= 0 {
+			names[content[match[2]:match[3]]] = name
+		}
+
+		command := CLICommand{Name: name, Framework: "commander", File: file}
+		if description := jsDescription.FindStringSubmatch(chain); description != nil {
+			command.Description = description[1]
+		}
+		for _, option := range jsOption.FindAllStringSubmatch(chain, -1) {
+			command.Flags = append(command.Flags, commanderFlag(option[1]))
+		}
+		commands = append(commands, command)
+	}
+
+	if len(commands) == 0 && len(root.Flags) == 0 {
+		return commands
+	}
+	return append([]CLICommand{root}, commands...)
+}
+
+// commanderFlag reduces "-p, --port <number>" to its long form.
+func commanderFlag(spec string) string {
+	for _, field := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if strings.HasPrefix(field, "--") {
+			return field
+		}
+	}
+	return firstField(strings.Trim(spec, ","))
+}
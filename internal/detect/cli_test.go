@@ -0,0 +1,141 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestDetectCLICommands(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sources := map[string]struct {
+		language string
+		content  string
+	}{
+		"cmd/root.go": {"go", `package cmd
+
+import "github.com/spf13/cobra"
+
+var rootCmd = &cobra.Command{
+	Use:   "tool",
+	Short: "Manage widgets",
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&cfg, "config", "", "config file")
+	rootCmd.AddCommand(serveCmd, newDBCmd())
+}
+`},
+		"cmd/serve.go": {"go", `package cmd
+
+import "github.com/spf13/cobra"
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [flags]",
+	Short: "Start the server",
+	RunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+func init() {
+	serveCmd.Flags().IntVarP(&port, "port", "p", 8080, "listen port")
+}
+`},
+		"cmd/db.go": {"go", `package cmd
+
+import "github.com/spf13/cobra"
+
+func newDBCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "db", Short: "Database tasks"}
+	return cmd
+}
+`},
+		"cli.py": {"python", `import click
+
+@click.group()
+def cli():
+    """Widget tool."""
+
+@cli.command("sync")
+@click.option("--dry-run", is_flag=True)
+@click.argument("target")
+def sync_widgets(dry_run, target):
+    """Sync widgets to the server."""
+`},
+		"manage.py": {"python", `import argparse
+
+parser = argparse.ArgumentParser(prog="manage", description="Admin tasks")
+parser.add_argument("--verbose", action="store_true")
+sub = parser.add_subparsers(dest="command")
+export = sub.add_parser("export", help="Export data")
+export.add_argument("--format")
+`},
+		"bin/cli.js": {"javascript", `const { program } = require('commander');
+
+program.name('widgets').option('-d, --debug', 'debug output');
+
+program
+  .command('build <dir>')
+  .description('Build the site')
+  .option('-o, --out <path>', 'output directory')
+  .action(build);
+`},
+	}
+
+	files := []scanner.FileInfo{}
+	for name, source := range sources {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, scanner.FileInfo{Path: path, RelativePath: name, Language: source.language})
+	}
+
+	commands := detectCLICommands(files)
+
+	byName := map[string]CLICommand{}
+	for _, command := range commands {
+		byName[command.Name] = command
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		flags       []string
+	}{
+		{"tool", "Manage widgets", []string{"--config"}},
+		{"tool serve", "Start the server", []string{"--port"}},
+		{"tool db", "Database tasks", nil},
+		{"cli", "Widget tool.", nil},
+		{"cli sync", "Sync widgets to the server.", []string{"--dry-run", "<target>"}},
+		{"manage", "Admin tasks", []string{"--verbose"}},
+		{"manage export", "Export data", []string{"--format"}},
+		{"widgets", "", []string{"--debug"}},
+		{"widgets build", "Build the site", []string{"--out"}},
+	}
+	for _, tt := range tests {
+		command, ok := byName[tt.name]
+		if !ok {
+			t.Errorf("Missing command %q in %+v", tt.name, commands)
+			continue
+		}
+		if command.Description != tt.description {
+			t.Errorf("%s: description = %q, want %q", tt.name, command.Description, tt.description)
+		}
+		if len(command.Flags) != len(tt.flags) {
+			t.Errorf("%s: flags = %v, want %v", tt.name, command.Flags, tt.flags)
+			continue
+		}
+		for i, flag := range tt.flags {
+			if command.Flags[i] != flag {
+				t.Errorf("%s: flags = %v, want %v", tt.name, command.Flags, tt.flags)
+				break
+			}
+		}
+	}
+}
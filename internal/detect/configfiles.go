@@ -0,0 +1,199 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	maxConfigFiles     = 15
+	maxConfigFileSize  = 256 * 1024
+	maxConfigKeyDepth  = 3
+	maxConfigNestedKey = 12
+)
+
+// ConfigFile is an application configuration file and its key hierarchy.
+type ConfigFile struct {
+	Path     string
+	Format   string
+	Sections []ConfigSection
+}
+
+// ConfigSection is a top-level key. Keys holds dotted paths of the keys nested
+// beneath it; list elements are written as "key[]".
+type ConfigSection struct {
+	Key  string
+	Type string
+	Keys []string
+}
+
+var (
+	configDirs = map[string]bool{"config": true, "configs": true, "conf": true, "settings": true}
+
+	// toolingConfigs are well-known files that configure tools rather than
+	// the application itself.
+	toolingConfigs = map[string]bool{
+		"package.json": true, "package-lock.json": true, "tsconfig.json": true, "jsconfig.json": true,
+		"composer.json": true, "composer.lock": true, "renovate.json": true, "lerna.json": true,
+		"chart.yaml": true, "docker-compose.yml": true, "docker-compose.yaml": true,
+		"pnpm-lock.yaml": true, "pnpm-workspace.yaml": true, "codedoc.yaml": true,
+		"mkdocs.yml": true, "codecov.yml": true, "openapi.yaml": true, "openapi.json": true,
+		"swagger.yaml": true, "swagger.json": true, ".goreleaser.yml": true, ".goreleaser.yaml": true,
+	}
+	toolingPrefixes = []string{"tsconfig.", "eslint", ".eslintrc", "prettier", ".prettierrc", "babel", "jest.config", "vite.config", "webpack"}
+)
+
+func detectConfigFiles(ctx context.Context, repoPath string) []ConfigFile {
+	if repoPath == "" {
+		return []ConfigFile{}
+	}
+
+	candidates := []string{}
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		if isSignificantConfig(rel) {
+			candidates = append(candidates, rel)
+		}
+	})
+	sort.Strings(candidates)
+
+	configs := []ConfigFile{}
+	for _, rel := range candidates {
+		if len(configs) >= maxConfigFiles {
+			break
+		}
+
+		full := filepath.Join(repoPath, filepath.FromSlash(rel))
+		info, err := os.Stat(full)
+		if err != nil || info.Size() > maxConfigFileSize {
+			continue
+		}
+		content, err := os.ReadFile(full)
+		if err != nil {
+			continue
+		}
+
+		var root map[string]any
+		if err := yaml.Unmarshal(content, &root); err != nil || len(root) == 0 {
+			continue
+		}
+		// Kubernetes manifests are covered by the runtime topology.
+		if _, ok := root["apiVersion"]; ok {
+			if _, ok := root["kind"]; ok {
+				continue
+			}
+		}
+
+		format := "yaml"
+		if strings.HasSuffix(rel, ".json") {
+			format = "json"
+		}
+
+		configs = append(configs, ConfigFile{
+			Path:     rel,
+			Format:   format,
+			Sections: configSections(root),
+		})
+	}
+
+	return configs
+}
+
+func isSignificantConfig(rel string) bool {
+	base := strings.ToLower(path.Base(rel))
+	ext := path.Ext(base)
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return false
+	}
+	if toolingConfigs[base] || strings.HasSuffix(base, ".lock.json") {
+		return false
+	}
+	for _, prefix := range toolingPrefixes {
+		if strings.HasPrefix(base, prefix) {
+			return false
+		}
+	}
+	// Helm templates are not valid YAML on their own.
+	if strings.Contains(rel, "/templates/") || strings.HasPrefix(rel, "templates/") {
+		return false
+	}
+
+	name := strings.TrimSuffix(base, ext)
+	if strings.HasPrefix(name, "values") || strings.HasPrefix(name, "config") || strings.HasPrefix(name, "settings") ||
+		strings.HasPrefix(name, "application") || strings.HasPrefix(name, "appsettings") {
+		return true
+	}
+
+	for _, dir := range strings.Split(path.Dir(rel), "/") {
+		if configDirs[strings.ToLower(dir)] {
+			return true
+		}
+	}
+	return false
+}
+
+func configSections(root map[string]any) []ConfigSection {
+	sections := []ConfigSection{}
+	for _, key := range sortedMapKeys(root) {
+		section := ConfigSection{Key: key, Type: configValueType(root[key])}
+		section.Keys = nestedConfigKeys(root[key], "", 1)
+		if len(section.Keys) > maxConfigNestedKey {
+			section.Keys = section.Keys[:maxConfigNestedKey]
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+func nestedConfigKeys(value any, prefix string, depth int) []string {
+	if depth > maxConfigKeyDepth {
+		return nil
+	}
+
+	keys := []string{}
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range sortedMapKeys(v) {
+			full := key
+			if prefix != "" {
+				full = prefix + "." + key
+			}
+			keys = append(keys, full)
+			keys = append(keys, nestedConfigKeys(v[key], full, depth+1)...)
+		}
+	case []any:
+		if len(v) > 0 {
+			if element, ok := v[0].(map[string]any); ok {
+				if prefix != "" {
+					prefix += "[]"
+				} else {
+					prefix = "[]"
+				}
+				keys = append(keys, nestedConfigKeys(element, prefix, depth)...)
+			}
+		}
+	}
+	return keys
+}
+
+func configValueType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "map"
+	case []any:
+		return "list"
+	case bool:
+		return "bool"
+	case int, int64, uint64, float64:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,71 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSignificantConfig(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"charts/web/values.yaml", true},
+		{"config/production.yml", true},
+		{"appsettings.Development.json", true},
+		{"src/settings.json", true},
+		{"package.json", false},
+		{"tsconfig.base.json", false},
+		{"charts/web/templates/config.yaml", false},
+		{"data/fixtures.json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if result := isSignificantConfig(tt.path); result != tt.expected {
+				t.Errorf("isSignificantConfig(%s) = %v, want %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectConfigFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"config/app.yaml":  "server:\n  port: 8080\n  tls:\n    enabled: true\ndatabases:\n  - name: main\n    url: postgres://\ndebug: false\n",
+		"config/pod.yaml":  "apiVersion: v1\nkind: Pod\nmetadata:\n  name: x\n",
+		"config/list.json": "[1, 2, 3]",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configs := detectConfigFiles(context.Background(), tempDir)
+	if len(configs) != 1 || configs[0].Path != "config/app.yaml" {
+		t.Fatalf("Expected only config/app.yaml, got %+v", configs)
+	}
+
+	sections := map[string]ConfigSection{}
+	for _, section := range configs[0].Sections {
+		sections[section.Key] = section
+	}
+
+	if server := sections["server"]; server.Type != "map" || len(server.Keys) != 3 || server.Keys[2] != "tls.enabled" {
+		t.Errorf("Unexpected server section: %+v", server)
+	}
+	if dbs := sections["databases"]; dbs.Type != "list" || len(dbs.Keys) != 2 || dbs.Keys[0] != "[].name" {
+		t.Errorf("Unexpected databases section: %+v", dbs)
+	}
+	if debug := sections["debug"]; debug.Type != "bool" {
+		t.Errorf("Unexpected debug section: %+v", debug)
+	}
+}
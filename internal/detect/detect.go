@@ -3,15 +3,27 @@ package detect
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/codepigeon/codedoc/internal/progress"
 	"github.com/codepigeon/codedoc/internal/scanner"
 )
 
 type Options struct {
 	Files []scanner.FileInfo
+	Rules Rules
+	// RepoPath enables detections that read files outside the scanned set,
+	// such as SQL migrations and Prisma schemas.
+	RepoPath string
+	// Tests overrides which files count as tests in the test inventory.
+	Tests scanner.TestRules
+	// Progress, when set, is advanced once per analyzed file.
+	Progress *progress.Reporter
 }
 
 type Result struct {
@@ -20,19 +32,54 @@ type Result struct {
 	Endpoints   []Endpoint
 	Models      []Model
 	BuildTools  []BuildTool
+	Tables      []Table
+	Artifacts   []Artifact
+	Findings    []Finding
+	HelmCharts  []HelmChart
+	K8s         []K8sResource
+	// Infrastructure is read from Terraform files anywhere in the repository.
+	Infrastructure Infrastructure
+	Pipelines      []Pipeline
+	Decisions      []Decision
+	ConfigFiles    []ConfigFile
+	Testing        TestInventory
+	CLICommands    []CLICommand
+	Secrets        []Secret
+	Tags           []Tag
+	Performance    []PerformanceHint
+	// Todos are ranked FIXME, HACK, XXX, then TODO.
+	Todos []Todo
+	// Docs are the README and contributing guide; DocGaps compares them with
+	// the rest of the result.
+	Docs    []Doc
+	DocGaps []DocGap
 }
 
+// Confidence is how strongly the evidence supports a detection, from 0 to 1.
+type Confidence float64
+
+const (
+	// ConfidenceHigh: declared in a manifest, spec or unambiguous code.
+	ConfidenceHigh Confidence = 0.9
+	// ConfidenceMedium: an import, a naming convention or a custom rule.
+	ConfidenceMedium Confidence = 0.6
+	// ConfidenceLow: a string mention without supporting evidence.
+	ConfidenceLow Confidence = 0.3
+)
+
 type Entrypoint struct {
 	Type        string
 	Path        string
 	Command     string
 	Description string
+	Confidence  Confidence
 }
 
 type Framework struct {
-	Name     string
-	Language string
-	Files    []string
+	Name       string
+	Language   string
+	Files      []string
+	Confidence Confidence
 }
 
 type Endpoint struct {
@@ -40,20 +87,35 @@ type Endpoint struct {
 	Path    string
 	Handler string
 	File    string
+	// Summary, Request and Response come from an OpenAPI/Swagger spec when
+	// one describes the endpoint.
+	Summary  string
+	Request  string
+	Response string
+	// Source is "openapi" for endpoints described by a spec and empty for
+	// routes found only in code.
+	Source     string
+	Confidence Confidence
 }
 
 type Model struct {
-	Name   string
-	Fields []string
-	File   string
+	Name       string
+	Fields     []string
+	File       string
+	Confidence Confidence
 }
 
 type BuildTool struct {
 	Type    string
 	File    string
 	Scripts []string
+	// Modules lists the sub-projects of a multi-module Maven or Gradle build.
+	Modules []string
 }
 
+// Detect analyzes opts.Files and the repository at opts.RepoPath. When ctx is
+// cancelled it stops at the next file or detector and returns what it found
+// so far with ctx.Err().
 func Detect(ctx context.Context, opts Options) (*Result, error) {
 	result := &Result{
 		Entrypoints: []Entrypoint{},
@@ -61,19 +123,101 @@ func Detect(ctx context.Context, opts Options) (*Result, error) {
 		Endpoints:   []Endpoint{},
 		Models:      []Model{},
 		BuildTools:  []BuildTool{},
+		Tables:      []Table{},
+		Artifacts:   []Artifact{},
+		Findings:    []Finding{},
+		HelmCharts:  []HelmChart{},
+		K8s:         []K8sResource{},
+		Pipelines:   []Pipeline{},
+		Decisions:   []Decision{},
+		Docs:        []Doc{},
+		DocGaps:     []DocGap{},
+		ConfigFiles: []ConfigFile{},
+		CLICommands: []CLICommand{},
+		Secrets:     []Secret{},
+		Tags:        []Tag{},
+		Performance: []PerformanceHint{},
+		Todos:       []Todo{},
+	}
+
+	rules, err := compileRules(opts.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid detection rule: %w", err)
 	}
 
+	// An interrupted run keeps what was detected, deduplicated like a full
+	// one.
+	interrupted := func() (*Result, error) {
+		deduplicateResults(result)
+		sortTodos(result.Todos)
+		return result, ctx.Err()
+	}
+
+	manifests := detectManifestFrameworks(ctx, opts.RepoPath)
+	result.Frameworks = append(result.Frameworks, manifests.frameworks...)
+
 	for _, file := range opts.Files {
+		if ctx.Err() != nil {
+			return interrupted()
+		}
 		detectEntrypoints(file, result)
-		detectFrameworks(file, result)
+		detectFrameworks(file, manifests, result)
 		detectBuildTools(file, result)
 		detectEndpoints(file, result)
 		detectModels(file, result)
+		detectCustom(file, rules, result)
+		detectPerformance(file, result)
+		detectTodos(file, result)
+		opts.Progress.Advance(file.RelativePath)
+	}
+
+	if ctx.Err() != nil {
+		return interrupted()
+	}
+	result.BuildTools = append(result.BuildTools, detectJVMBuildTools(ctx, opts.RepoPath)...)
+	result.BuildTools = append(result.BuildTools, detectRubyBuildTools(ctx, opts.RepoPath)...)
+	result.BuildTools = append(result.BuildTools, detectCargoBuildTools(ctx, opts.RepoPath)...)
+	result.BuildTools = append(result.BuildTools, detectComposerBuildTools(ctx, opts.RepoPath)...)
+	dotnetTools, dotnetEntrypoints := detectDotnetProjects(ctx, opts.RepoPath)
+	result.BuildTools = append(result.BuildTools, dotnetTools...)
+	result.Entrypoints = append(result.Entrypoints, dotnetEntrypoints...)
+	result.Models = append(result.Models, detectEntityFrameworkModels(opts.Files)...)
+	result.Tables = detectSchema(ctx, opts.RepoPath, opts.Files)
+	result.Artifacts = detectArtifacts(ctx, opts.RepoPath)
+	result.HelmCharts, result.K8s = detectKubernetes(ctx, opts.RepoPath)
+	result.Infrastructure = detectTerraform(ctx, opts.RepoPath)
+	result.BuildTools = append(result.BuildTools, terraformBuildTools(result.Infrastructure)...)
+	result.Findings = append(detectDockerfileFindings(ctx, opts.RepoPath), checkK8sResources(result.K8s)...)
+	result.Findings = append(result.Findings, detectEOLFindings(ctx, opts.RepoPath, time.Now())...)
+	SortFindings(result.Findings)
+	result.Pipelines = detectPipelines(opts.RepoPath)
+	result.Decisions = detectDecisions(ctx, opts.RepoPath)
+	result.ConfigFiles = detectConfigFiles(ctx, opts.RepoPath)
+	result.Testing = detectTesting(ctx, opts.RepoPath, scanner.NewTestMatcher(opts.RepoPath, opts.Tests))
+	result.CLICommands = detectCLICommands(opts.Files)
+	// The secret scan and blame read the most, so they start only if the run
+	// is still going.
+	if ctx.Err() != nil {
+		return interrupted()
+	}
+	result.Secrets = detectSecrets(ctx, opts.RepoPath)
+	sortTodos(result.Todos)
+	attributeTodos(ctx, opts.RepoPath, result.Todos)
+
+	specEndpoints, specModels := detectOpenAPI(ctx, opts.RepoPath)
+	result.Models = append(result.Models, specModels...)
+	if ctx.Err() != nil {
+		return interrupted()
 	}
 
 	deduplicateResults(result)
+	linkModelColumns(opts.RepoPath, result)
 
-	return result, nil
+	result.Endpoints = mergeSpecEndpoints(specEndpoints, result.Endpoints)
+	result.Tags = inferTags(ctx, opts.RepoPath, result)
+	result.Docs, result.DocGaps = detectDocs(opts.RepoPath, result)
+
+	return result, ctx.Err()
 }
 
 func detectEntrypoints(file scanner.FileInfo, result *Result) {
@@ -90,17 +234,23 @@ func detectEntrypoints(file scanner.FileInfo, result *Result) {
 					Path:        file.RelativePath,
 					Command:     fmt.Sprintf("go run %s", file.RelativePath),
 					Description: "Go main package",
+					Confidence:  ConfidenceHigh,
 				})
 			}
 		}
 
 	case "python":
 		if base == "__main__.py" || base == "main.py" || base == "app.py" {
+			confidence := ConfidenceMedium
+			if content, err := os.ReadFile(file.Path); err == nil && (base == "__main__.py" || strings.Contains(string(content), "__name__ == ")) {
+				confidence = ConfidenceHigh
+			}
 			result.Entrypoints = append(result.Entrypoints, Entrypoint{
 				Type:        "python-script",
 				Path:        file.RelativePath,
 				Command:     fmt.Sprintf("python %s", file.RelativePath),
 				Description: "Python entrypoint",
+				Confidence:  confidence,
 			})
 		}
 
@@ -111,6 +261,7 @@ func detectEntrypoints(file scanner.FileInfo, result *Result) {
 				Path:        file.RelativePath,
 				Command:     fmt.Sprintf("node %s", file.RelativePath),
 				Description: "Node.js entrypoint",
+				Confidence:  ConfidenceMedium,
 			})
 		}
 
@@ -120,11 +271,22 @@ func detectEntrypoints(file scanner.FileInfo, result *Result) {
 			Path:        file.RelativePath,
 			Command:     "docker build .",
 			Description: "Docker container",
+			Confidence:  ConfidenceHigh,
 		})
+
+	case "rust":
+		if entrypoint, ok := rustEntrypoint(file.Path, file.RelativePath); ok {
+			result.Entrypoints = append(result.Entrypoints, entrypoint)
+		}
 	}
 }
 
-func detectFrameworks(file scanner.FileInfo, result *Result) {
+func detectFrameworks(file scanner.FileInfo, manifests manifestDetection, result *Result) {
+	patterns, ok := frameworkPatterns[file.Language]
+	if !ok {
+		return
+	}
+
 	content, err := os.ReadFile(file.Path)
 	if err != nil {
 		return
@@ -132,51 +294,42 @@ func detectFrameworks(file scanner.FileInfo, result *Result) {
 
 	contentStr := string(content)
 
-	frameworkPatterns := map[string]map[string][]string{
-		"go": {
-			"gin":         {"github.com/gin-gonic/gin", "gin.New()", "gin.Default()"},
-			"echo":        {"github.com/labstack/echo", "echo.New()"},
-			"fiber":       {"github.com/gofiber/fiber", "fiber.New()"},
-			"chi":         {"github.com/go-chi/chi", "chi.NewRouter()"},
-			"gorilla/mux": {"github.com/gorilla/mux", "mux.NewRouter()"},
-			"beego":       {"github.com/astaxie/beego", "beego.Run()"},
-		},
-		"python": {
-			"flask":   {"from flask import", "Flask(__name__)"},
-			"django":  {"from django", "django.contrib"},
-			"fastapi": {"from fastapi import", "FastAPI()"},
-			"tornado": {"import tornado", "tornado.web"},
-			"pyramid": {"from pyramid", "pyramid.config"},
-		},
-		"javascript": {
-			"express": {"require('express')", "require(\"express\")", "from 'express'"},
-			"koa":     {"require('koa')", "from 'koa'"},
-			"hapi":    {"require('@hapi/hapi')", "from '@hapi/hapi'"},
-			"fastify": {"require('fastify')", "from 'fastify'"},
-		},
-		"typescript": {
-			"express": {"from 'express'", "import express"},
-			"nest":    {"@nestjs/", "from '@nestjs"},
-			"next":    {"from 'next'", "import next"},
-		},
-	}
-
-	if patterns, ok := frameworkPatterns[file.Language]; ok {
-		for framework, indicators := range patterns {
-			for _, indicator := range indicators {
-				if strings.Contains(contentStr, indicator) {
-					result.Frameworks = append(result.Frameworks, Framework{
-						Name:     framework,
-						Language: file.Language,
-						Files:    []string{file.RelativePath},
-					})
-					break
-				}
+	for framework, indicators := range patterns {
+		if !manifests.allows(file.Language, framework) {
+			continue
+		}
+
+		var confidence Confidence
+		for _, indicator := range indicators {
+			if strings.Contains(contentStr, indicator) {
+				confidence = max(confidence, indicatorConfidence(indicator))
 			}
 		}
+		if confidence == 0 {
+			continue
+		}
+		if manifests.declared[file.Language][framework] {
+			confidence = ConfidenceHigh
+		}
+
+		result.Frameworks = append(result.Frameworks, Framework{
+			Name:       framework,
+			Language:   file.Language,
+			Files:      []string{file.RelativePath},
+			Confidence: confidence,
+		})
 	}
 }
 
+// indicatorConfidence rates an import path or import statement above a bare
+// mention such as "gin.New()".
+func indicatorConfidence(indicator string) Confidence {
+	if strings.Contains(indicator, "import") || strings.Contains(indicator, "require(") || strings.HasPrefix(indicator, "require ") || strings.HasPrefix(indicator, "use ") || strings.HasPrefix(indicator, "using ") || strings.Contains(indicator, "/") {
+		return ConfidenceMedium
+	}
+	return ConfidenceLow
+}
+
 func detectBuildTools(file scanner.FileInfo, result *Result) {
 	base := filepath.Base(file.Path)
 
@@ -206,13 +359,6 @@ func detectBuildTools(file scanner.FileInfo, result *Result) {
 			Scripts: []string{"go build", "go test", "go run"},
 		})
 
-	case "cargo.toml":
-		result.BuildTools = append(result.BuildTools, BuildTool{
-			Type:    "cargo",
-			File:    file.RelativePath,
-			Scripts: []string{"cargo build", "cargo test", "cargo run"},
-		})
-
 	case "requirements.txt", "setup.py", "pipfile":
 		result.BuildTools = append(result.BuildTools, BuildTool{
 			Type:    "pip",
@@ -245,6 +391,16 @@ func detectEndpoints(file scanner.FileInfo, result *Result) {
 		endpoints = extractPythonEndpoints(contentStr, file.RelativePath)
 	case "javascript", "typescript":
 		endpoints = extractJSEndpoints(contentStr, file.RelativePath)
+	case "java", "kotlin":
+		endpoints = extractJVMEndpoints(contentStr, file.RelativePath)
+	case "ruby":
+		endpoints = extractRubyEndpoints(contentStr, file.RelativePath)
+	case "rust":
+		endpoints = extractRustEndpoints(contentStr, file.RelativePath)
+	case "csharp":
+		endpoints = extractAspNetEndpoints(contentStr, file.RelativePath)
+	case "php":
+		endpoints = extractPHPEndpoints(contentStr, file.RelativePath)
 	}
 
 	result.Endpoints = append(result.Endpoints, endpoints...)
@@ -266,11 +422,62 @@ func detectModels(file scanner.FileInfo, result *Result) {
 		models = extractPythonModels(contentStr, file.RelativePath)
 	case "javascript", "typescript":
 		models = extractJSModels(contentStr, file.RelativePath)
+	case "java", "kotlin":
+		models = extractJPAModels(contentStr, file.RelativePath)
+	case "ruby":
+		models = extractActiveRecordModels(contentStr, file.RelativePath)
+	case "rust":
+		models = extractSerdeModels(contentStr, file.RelativePath)
+	case "php":
+		models = extractEloquentModels(contentStr, file.RelativePath)
 	}
 
 	result.Models = append(result.Models, models...)
 }
 
+func detectCustom(file scanner.FileInfo, rules compiledRules, result *Result) {
+	if len(rules.frameworks) == 0 && len(rules.endpoints) == 0 && len(rules.models) == 0 {
+		return
+	}
+
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return
+	}
+
+	applyCustomRules(file, string(content), rules, result)
+}
+
+// walkRepo calls fn for every regular file under repoPath, skipping hidden
+// directories and what the scanner ignores, such as vendor and node_modules.
+// rel is slash-separated. It stops with ctx.Err() when ctx is cancelled.
+func walkRepo(ctx context.Context, repoPath string, fn func(path, rel string)) error {
+	return filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil || path == repoPath {
+			return nil
+		}
+		rel, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") || scanner.Ignored(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || scanner.Ignored(rel) {
+			return nil
+		}
+		fn(path, rel)
+		return nil
+	})
+}
+
 func extractMakefileTargets(content string) []string {
 	targets := []string{}
 	lines := strings.Split(content, "\n")
@@ -398,12 +605,16 @@ func extractJSModels(content, file string) []Model {
 	return models
 }
 
+// deduplicateResults merges entries detected more than once and sorts every
+// list so reports are stable across runs. When duplicates disagree, the entry
+// from the lexicographically first file wins and list fields are unioned.
 func deduplicateResults(result *Result) {
 	frameworkMap := make(map[string]Framework)
 	for _, fw := range result.Frameworks {
 		key := fmt.Sprintf("%s-%s", fw.Language, fw.Name)
 		if existing, ok := frameworkMap[key]; ok {
 			existing.Files = append(existing.Files, fw.Files...)
+			existing.Confidence = max(existing.Confidence, fw.Confidence)
 			frameworkMap[key] = existing
 		} else {
 			frameworkMap[key] = fw
@@ -411,7 +622,123 @@ func deduplicateResults(result *Result) {
 	}
 
 	result.Frameworks = []Framework{}
-	for _, fw := range frameworkMap {
+	for _, key := range sortedMapKeys(frameworkMap) {
+		fw := frameworkMap[key]
+		sort.Strings(fw.Files)
+		fw.Files = dedupeSorted(fw.Files)
 		result.Frameworks = append(result.Frameworks, fw)
 	}
+
+	endpointMap := make(map[string]Endpoint)
+	for _, ep := range result.Endpoints {
+		ep.Method = strings.ToUpper(ep.Method)
+		key := ep.Method + " " + ep.Path
+		existing, ok := endpointMap[key]
+		if !ok {
+			endpointMap[key] = ep
+			continue
+		}
+		if ep.File < existing.File {
+			ep.Handler = firstNonEmpty(ep.Handler, existing.Handler)
+			ep.Confidence = max(ep.Confidence, existing.Confidence)
+			endpointMap[key] = ep
+		} else {
+			existing.Handler = firstNonEmpty(existing.Handler, ep.Handler)
+			existing.Confidence = max(existing.Confidence, ep.Confidence)
+			endpointMap[key] = existing
+		}
+	}
+
+	result.Endpoints = []Endpoint{}
+	for _, ep := range endpointMap {
+		result.Endpoints = append(result.Endpoints, ep)
+	}
+	sort.Slice(result.Endpoints, func(i, j int) bool {
+		a, b := result.Endpoints[i], result.Endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+
+	modelMap := make(map[string]Model)
+	for _, model := range result.Models {
+		existing, ok := modelMap[model.Name]
+		if !ok {
+			modelMap[model.Name] = model
+			continue
+		}
+		first, second := existing, model
+		if model.File < existing.File {
+			first, second = model, existing
+		}
+		first.Fields = unionPreservingOrder(first.Fields, second.Fields)
+		first.Confidence = max(first.Confidence, second.Confidence)
+		modelMap[model.Name] = first
+	}
+
+	result.Models = []Model{}
+	for _, key := range sortedMapKeys(modelMap) {
+		result.Models = append(result.Models, modelMap[key])
+	}
+
+	entrypointMap := make(map[string]Entrypoint)
+	for _, ep := range result.Entrypoints {
+		key := ep.Type + "|" + ep.Path
+		if existing, ok := entrypointMap[key]; !ok || ep.Confidence > existing.Confidence {
+			entrypointMap[key] = ep
+		}
+	}
+
+	result.Entrypoints = []Entrypoint{}
+	for _, key := range sortedMapKeys(entrypointMap) {
+		result.Entrypoints = append(result.Entrypoints, entrypointMap[key])
+	}
+	sort.SliceStable(result.Entrypoints, func(i, j int) bool {
+		return result.Entrypoints[i].Path < result.Entrypoints[j].Path
+	})
+
+	toolMap := make(map[string]BuildTool)
+	for _, tool := range result.BuildTools {
+		key := tool.File + "|" + tool.Type
+		if existing, ok := toolMap[key]; ok {
+			existing.Scripts = unionPreservingOrder(existing.Scripts, tool.Scripts)
+			toolMap[key] = existing
+		} else {
+			toolMap[key] = tool
+		}
+	}
+
+	result.BuildTools = []BuildTool{}
+	for _, key := range sortedMapKeys(toolMap) {
+		result.BuildTools = append(result.BuildTools, toolMap[key])
+	}
+}
+
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func unionPreservingOrder(a, b []string) []string {
+	result := append([]string{}, a...)
+	for _, item := range b {
+		if !containsString(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
 }
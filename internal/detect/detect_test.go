@@ -0,0 +1,122 @@
+package detect
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestDeduplicateResults(t *testing.T) {
+	result := &Result{
+		Frameworks: []Framework{
+			{Name: "gin", Language: "go", Files: []string{"b.go"}},
+			{Name: "gin", Language: "go", Files: []string{"a.go"}},
+			{Name: "gin", Language: "go", Files: []string{"b.go"}},
+		},
+		Endpoints: []Endpoint{
+			{Method: "get", Path: "/users", File: "z.go", Handler: "listUsers"},
+			{Method: "GET", Path: "/users", File: "a.go"},
+			{Method: "POST", Path: "/users", File: "a.go"},
+			{Method: "GET", Path: "/health", File: "a.go"},
+		},
+		Models: []Model{
+			{Name: "User", Fields: []string{"id", "email"}, File: "b.go"},
+			{Name: "User", Fields: []string{"id", "name"}, File: "a.go"},
+		},
+		Entrypoints: []Entrypoint{
+			{Type: "go-binary", Path: "cmd/b/main.go"},
+			{Type: "go-binary", Path: "cmd/a/main.go"},
+			{Type: "go-binary", Path: "cmd/b/main.go"},
+		},
+		BuildTools: []BuildTool{
+			{Type: "make", File: "Makefile", Scripts: []string{"build"}},
+			{Type: "make", File: "Makefile", Scripts: []string{"build", "test"}},
+			{Type: "go", File: "go.mod"},
+		},
+	}
+
+	deduplicateResults(result)
+
+	if len(result.Frameworks) != 1 || !reflect.DeepEqual(result.Frameworks[0].Files, []string{"a.go", "b.go"}) {
+		t.Errorf("Unexpected frameworks: %+v", result.Frameworks)
+	}
+
+	expectedEndpoints := []Endpoint{
+		{Method: "GET", Path: "/health", File: "a.go"},
+		{Method: "GET", Path: "/users", File: "a.go", Handler: "listUsers"},
+		{Method: "POST", Path: "/users", File: "a.go"},
+	}
+	if !reflect.DeepEqual(result.Endpoints, expectedEndpoints) {
+		t.Errorf("Endpoints = %+v, want %+v", result.Endpoints, expectedEndpoints)
+	}
+
+	expectedModel := Model{Name: "User", Fields: []string{"id", "name", "email"}, File: "a.go"}
+	if len(result.Models) != 1 || !reflect.DeepEqual(result.Models[0], expectedModel) {
+		t.Errorf("Models = %+v, want %+v", result.Models, expectedModel)
+	}
+
+	if len(result.Entrypoints) != 2 || result.Entrypoints[0].Path != "cmd/a/main.go" {
+		t.Errorf("Unexpected entrypoints: %+v", result.Entrypoints)
+	}
+
+	if len(result.BuildTools) != 2 || !reflect.DeepEqual(result.BuildTools[0].Scripts, []string{"build", "test"}) {
+		t.Errorf("Unexpected build tools: %+v", result.BuildTools)
+	}
+}
+
+func TestDetectCancelled(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := Detect(ctx, Options{
+		Files:    []scanner.FileInfo{{Path: path, RelativePath: "main.go", Language: "go"}},
+		RepoPath: dir,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Detect() error = %v, want context.Canceled", err)
+	}
+	if result == nil || len(result.Entrypoints) != 0 {
+		t.Errorf("Detect() = %+v, want an empty partial result", result)
+	}
+}
+
+func TestWalkRepo(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{
+		"main.go", "web/app.js", "web/app.min.js", ".github/workflows/ci.yml",
+		"vendor/github.com/lib/lib.go", "node_modules/left-pad/index.js", "api/node_modules/x/index.js", "dist/bundle.js",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	walked := []string{}
+	if err := walkRepo(context.Background(), dir, func(_, rel string) { walked = append(walked, rel) }); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"main.go", "web/app.js"}; !reflect.DeepEqual(walked, want) {
+		t.Errorf("walkRepo() visited %v, want %v", walked, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	walked = nil
+	if err := walkRepo(ctx, dir, func(_, rel string) { walked = append(walked, rel) }); !errors.Is(err, context.Canceled) || len(walked) != 0 {
+		t.Errorf("walkRepo() = %v after visiting %v, want context.Canceled and no files", err, walked)
+	}
+}
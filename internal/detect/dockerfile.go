@@ -0,0 +1,281 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// Finding is a best-practice violation found in a configuration file.
+type Finding struct {
+	Severity string
+	Rule     string
+	File     string
+	Line     int
+	Message  string
+}
+
+var (
+	secretVarName = regexp.MustCompile(`(?i)(secret|password|passwd|token|api_?key|private_?key|access_?key|credentials)`)
+
+	// largeBaseImages are full distribution images that usually have a slim
+	// or alpine variant.
+	largeBaseImages = map[string]bool{
+		"ubuntu": true, "debian": true, "centos": true, "fedora": true,
+		"node": true, "python": true, "golang": true, "openjdk": true,
+		"ruby": true, "php": true,
+	}
+)
+
+type dockerInstruction struct {
+	line    int
+	command string
+	args    string
+}
+
+func detectDockerfileFindings(ctx context.Context, repoPath string) []Finding {
+	if repoPath == "" {
+		return []Finding{}
+	}
+
+	findings := []Finding{}
+	walkRepo(ctx, repoPath, func(path, rel string) {
+		if !IsDockerfile(rel) {
+			return
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		findings = append(findings, checkDockerfile(rel, string(content))...)
+	})
+
+	SortFindings(findings)
+	return findings
+}
+
+// IsDockerfile reports whether the file at path is a Dockerfile: named
+// Dockerfile, Dockerfile.<variant> or <name>.dockerfile, in any case.
+func IsDockerfile(path string) bool {
+	lower := strings.ToLower(filepath.Base(path))
+	return lower == "dockerfile" || strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile")
+}
+
+func checkDockerfile(rel, content string) []Finding {
+	findings := []Finding{}
+	instructions := parseDockerfile(content)
+
+	stages := map[string]bool{}
+	var finalFrom dockerInstruction
+	finalUser := ""
+	hasHealthcheck := false
+
+	for _, inst := range instructions {
+		switch inst.command {
+		case "FROM":
+			fields := strings.Fields(inst.args)
+			image := ""
+			for _, field := range fields {
+				if !strings.HasPrefix(field, "--") {
+					image = field
+					break
+				}
+			}
+			if len(fields) >= 3 && strings.EqualFold(fields[len(fields)-2], "AS") {
+				stages[strings.ToLower(fields[len(fields)-1])] = true
+			}
+
+			finalFrom = inst
+			finalUser = ""
+
+			if image == "" || image == "scratch" || stages[strings.ToLower(image)] || strings.Contains(image, "$") {
+				continue
+			}
+			if tag := imageTag(image); tag == "" || tag == "latest" {
+				findings = append(findings, Finding{
+					Severity: SeverityMedium,
+					Rule:     "unpinned-base-image",
+					File:     rel,
+					Line:     inst.line,
+					Message:  "base image " + image + " is not pinned to a version tag or digest",
+				})
+			}
+
+		case "USER":
+			finalUser = strings.TrimSpace(inst.args)
+
+		case "HEALTHCHECK":
+			hasHealthcheck = !strings.EqualFold(strings.TrimSpace(inst.args), "NONE")
+
+		case "ARG", "ENV":
+			for _, name := range dockerVarNames(inst.command, inst.args) {
+				if secretVarName.MatchString(name) {
+					findings = append(findings, Finding{
+						Severity: SeverityHigh,
+						Rule:     "secret-in-build",
+						File:     rel,
+						Line:     inst.line,
+						Message:  inst.command + " " + name + " looks like a secret and will be stored in the image history",
+					})
+				}
+			}
+		}
+	}
+
+	if finalFrom.command == "" {
+		return findings
+	}
+
+	if user, _, _ := strings.Cut(finalUser, ":"); user == "" || user == "root" || user == "0" {
+		findings = append(findings, Finding{
+			Severity: SeverityHigh,
+			Rule:     "root-user",
+			File:     rel,
+			Line:     finalFrom.line,
+			Message:  "final stage runs as root; add a non-root USER",
+		})
+	}
+
+	if !hasHealthcheck {
+		findings = append(findings, Finding{
+			Severity: SeverityLow,
+			Rule:     "missing-healthcheck",
+			File:     rel,
+			Line:     finalFrom.line,
+			Message:  "no HEALTHCHECK instruction",
+		})
+	}
+
+	if image := finalImage(finalFrom.args); image != "" && isLargeBaseImage(image) {
+		findings = append(findings, Finding{
+			Severity: SeverityLow,
+			Rule:     "large-base-image",
+			File:     rel,
+			Line:     finalFrom.line,
+			Message:  "final stage uses full " + image + " image; consider a slim, alpine or distroless variant",
+		})
+	}
+
+	return findings
+}
+
+// parseDockerfile joins continuation lines and splits the file into
+// instructions, keeping the line number each one starts on.
+func parseDockerfile(content string) []dockerInstruction {
+	instructions := []dockerInstruction{}
+
+	var current strings.Builder
+	start := 0
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		if current.Len() == 0 && (line == "" || strings.HasPrefix(line, "#")) {
+			continue
+		}
+		if current.Len() == 0 {
+			start = i + 1
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\"))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(line)
+
+		command, args, _ := strings.Cut(current.String(), " ")
+		instructions = append(instructions, dockerInstruction{
+			line:    start,
+			command: strings.ToUpper(command),
+			args:    strings.TrimSpace(args),
+		})
+		current.Reset()
+	}
+
+	return instructions
+}
+
+func dockerVarNames(command, args string) []string {
+	names := []string{}
+	fields := strings.Fields(args)
+
+	// Legacy form: ENV KEY value with spaces.
+	if command == "ENV" && len(fields) > 0 && !strings.Contains(fields[0], "=") {
+		return []string{fields[0]}
+	}
+
+	for _, field := range fields {
+		name, _, _ := strings.Cut(field, "=")
+		if name != "" && !strings.HasPrefix(name, "\"") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return "digest"
+	}
+	slash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > slash {
+		return image[colon+1:]
+	}
+	return ""
+}
+
+func finalImage(args string) string {
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "--") {
+			return field
+		}
+	}
+	return ""
+}
+
+func isLargeBaseImage(image string) bool {
+	name := image
+	if at := strings.Index(name, "@"); at >= 0 {
+		name = name[:at]
+	}
+	tag := imageTag(name)
+	if tag != "" {
+		name = strings.TrimSuffix(name, ":"+tag)
+	}
+	name = strings.TrimPrefix(name, "docker.io/")
+	name = strings.TrimPrefix(name, "library/")
+
+	if !largeBaseImages[name] {
+		return false
+	}
+	for _, variant := range []string{"slim", "alpine", "distroless", "minimal"} {
+		if strings.Contains(tag, variant) {
+			return false
+		}
+	}
+	return true
+}
+
+var severityOrder = map[string]int{SeverityHigh: 0, SeverityMedium: 1, SeverityLow: 2}
+
+// SortFindings orders findings by severity, then file and line.
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if severityOrder[a.Severity] != severityOrder[b.Severity] {
+			return severityOrder[a.Severity] < severityOrder[b.Severity]
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+}
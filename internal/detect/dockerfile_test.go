@@ -0,0 +1,57 @@
+package detect
+
+import "testing"
+
+func TestCheckDockerfile(t *testing.T) {
+	content := "FROM golang:1.22 AS build\n" +
+		"ARG GITHUB_TOKEN\n" +
+		"RUN go build \\\n  -o /app .\n" +
+		"FROM ubuntu\n" +
+		"ENV API_KEY=abc \\\n    PORT=8080\n" +
+		"COPY --from=build /app /app\n"
+
+	findings := checkDockerfile("Dockerfile", content)
+
+	rules := map[string]Finding{}
+	for _, finding := range findings {
+		rules[finding.Rule+"@"+finding.Severity] = finding
+	}
+
+	expected := []string{
+		"unpinned-base-image@medium",
+		"secret-in-build@high",
+		"root-user@high",
+		"missing-healthcheck@low",
+		"large-base-image@low",
+	}
+	for _, key := range expected {
+		if _, ok := rules[key]; !ok {
+			t.Errorf("Expected finding %s, got %+v", key, findings)
+		}
+	}
+
+	if finding := rules["unpinned-base-image@medium"]; finding.Line != 5 {
+		t.Errorf("Expected unpinned image on line 5, got %d", finding.Line)
+	}
+
+	clean := "FROM node:20-slim AS deps\nFROM deps\nUSER node\nHEALTHCHECK CMD [\"node\", \"health.js\"]\n"
+	if findings := checkDockerfile("Dockerfile", clean); len(findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", findings)
+	}
+}
+
+func TestIsDockerfile(t *testing.T) {
+	for file, want := range map[string]bool{
+		"Dockerfile":            true,
+		"build/Dockerfile.prod": true,
+		"api.dockerfile":        true,
+		"docker/DOCKERFILE":     true,
+		"docker-compose.yml":    false,
+		"Dockerfiles/README.md": false,
+		"chart/values.yaml":     false,
+	} {
+		if got := IsDockerfile(file); got != want {
+			t.Errorf("IsDockerfile(%q) = %v, want %v", file, got, want)
+		}
+	}
+}
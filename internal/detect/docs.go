@@ -0,0 +1,427 @@
+package detect
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Doc is a README or contributing guide, split into the sections codedoc
+// passes to the model as context.
+type Doc struct {
+	File     string
+	Sections []DocSection
+}
+
+// DocSection is a section of a Doc classified by its heading; the text
+// before the first subheading of a README is its overview.
+type DocSection struct {
+	Heading string
+	Kind    string
+	Text    string
+}
+
+const (
+	DocOverview     = "overview"
+	DocInstallation = "installation"
+	DocUsage        = "usage"
+	DocDevelopment  = "development"
+)
+
+// DocGap is a disagreement between the docs and the repository.
+type DocGap struct {
+	// Kind is DocGapStale for a claim the repository contradicts and
+	// DocGapUndocumented for something detected that the docs never mention.
+	Kind    string
+	File    string
+	Line    int
+	Message string
+}
+
+const (
+	DocGapStale        = "stale"
+	DocGapUndocumented = "undocumented"
+)
+
+// maxDocSection caps DocSection.Text, in characters.
+const maxDocSection = 1500
+
+var (
+	readmeFiles       = []string{"README.md", "README.markdown", "readme.md", "README"}
+	contributingFiles = []string{"CONTRIBUTING.md", ".github/CONTRIBUTING.md", "docs/CONTRIBUTING.md"}
+
+	docSectionKinds = []struct {
+		kind    string
+		pattern *regexp.Regexp
+	}{
+		{DocInstallation, regexp.MustCompile(`install|setup|set up|getting started|quick ?start|prerequisites|requirements|building`)},
+		{DocDevelopment, regexp.MustCompile(`develop|contribut|testing|running tests|local`)},
+		{DocUsage, regexp.MustCompile(`usage|running|how to use|examples?\b|commands|\bcli\b`)},
+		{DocOverview, regexp.MustCompile(`overview|about|architecture|design|how it works|introduction|features`)},
+	}
+
+	docHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	inlineCode  = regexp.MustCompile("`([^`]+)`")
+	docLink     = regexp.MustCompile(`\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	docPath     = regexp.MustCompile(`^(?:\./)?[\w.-]+(?:/[\w.-]+)+/?$`)
+	makeTarget  = regexp.MustCompile(`(?m)^([^\s:#=.][^:=]*?)\s*::?(?:[^=]|$)`)
+	shellFences = map[string]bool{"": true, "sh": true, "bash": true, "shell": true, "console": true, "zsh": true, "text": true}
+	yarnBuiltin = map[string]bool{
+		"install": true, "add": true, "remove": true, "upgrade": true, "up": true, "global": true, "init": true,
+		"create": true, "dlx": true, "why": true, "info": true, "workspace": true, "workspaces": true,
+		"config": true, "cache": true, "link": true, "pack": true, "publish": true, "login": true, "set": true,
+	}
+)
+
+// docLine is a command or path mentioned on a line of a doc.
+type docLine struct {
+	line int
+	text string
+	// link is set for the target of a Markdown link, which is checked even
+	// when its directory does not exist.
+	link bool
+}
+
+// detectDocs reads the README and contributing guide at the root of
+// repoPath, and checks the commands and paths they mention against the
+// repository and result: make targets and npm scripts that do not exist,
+// links to missing files, and detected binaries, commands, endpoints and
+// Dockerfiles the docs never mention.
+func detectDocs(repoPath string, result *Result) ([]Doc, []DocGap) {
+	docs := []Doc{}
+	gaps := []DocGap{}
+	if repoPath == "" {
+		return docs, gaps
+	}
+
+	var text strings.Builder
+	installs := false
+	for _, candidates := range [][]string{readmeFiles, contributingFiles} {
+		for _, name := range candidates {
+			content, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(name)))
+			if err != nil {
+				continue
+			}
+			doc, commands, paths := parseDoc(name, string(content))
+			docs = append(docs, doc)
+			text.WriteString(string(content))
+			text.WriteString("\n")
+			for _, section := range doc.Sections {
+				installs = installs || section.Kind == DocInstallation
+			}
+			for _, command := range commands {
+				if message := checkDocCommand(repoPath, command.text); message != "" {
+					gaps = append(gaps, DocGap{Kind: DocGapStale, File: name, Line: command.line, Message: message})
+				}
+			}
+			for _, mention := range paths {
+				if message := checkDocPath(repoPath, name, mention); message != "" {
+					gaps = append(gaps, DocGap{Kind: DocGapStale, File: name, Line: mention.line, Message: message})
+				}
+			}
+			break
+		}
+	}
+	if len(docs) == 0 {
+		return docs, gaps
+	}
+
+	mentioned := func(term string) bool {
+		return regexp.MustCompile(`(?i)(^|[^\w-])` + regexp.QuoteMeta(term) + `($|[^\w-])`).MatchString(text.String())
+	}
+	undocumented := func(format string, args ...any) {
+		gaps = append(gaps, DocGap{Kind: DocGapUndocumented, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !installs && len(result.BuildTools) > 0 {
+		undocumented("No installation or getting-started section, although %s builds were detected", buildToolNames(result.BuildTools))
+	}
+	seen := map[string]bool{}
+	for _, entrypoint := range result.Entrypoints {
+		dir := path.Dir(entrypoint.Path)
+		if entrypoint.Type != "go-binary" || path.Base(path.Dir(dir)) != "cmd" {
+			continue
+		}
+		if name := path.Base(dir); !seen[name] && !mentioned(name) {
+			seen[name] = true
+			undocumented("Binary `%s` (%s) is not mentioned", name, dir)
+		}
+	}
+	for _, command := range result.CLICommands {
+		fields := strings.Fields(command.Name)
+		if len(fields) == 0 {
+			continue
+		}
+		if name := fields[0]; !seen[name] && !mentioned(name) {
+			seen[name] = true
+			undocumented("CLI command `%s` (%s) is not mentioned", name, command.File)
+		}
+	}
+	if len(result.Endpoints) > 0 && !mentioned("api") && !mentioned("endpoint") && !mentioned("endpoints") {
+		undocumented("No API documentation, although endpoints such as `%s %s` were detected", result.Endpoints[0].Method, result.Endpoints[0].Path)
+	}
+	for _, entrypoint := range result.Entrypoints {
+		if entrypoint.Type == "docker" && !mentioned("docker") {
+			undocumented("%s exists but Docker is never mentioned", entrypoint.Path)
+			break
+		}
+	}
+
+	return docs, gaps
+}
+
+// parseDoc splits a Markdown doc into classified sections and collects the
+// commands of its shell code blocks and inline code, and the repository
+// paths it mentions in inline code and links.
+func parseDoc(file, content string) (Doc, []docLine, []docLine) {
+	doc := Doc{File: file, Sections: []DocSection{}}
+	commands := []docLine{}
+	paths := []docLine{}
+
+	var current *DocSection
+	currentLevel := 0
+	fence := ""
+	shell := false
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" && (fence == "" || strings.HasPrefix(trimmed, fence)) {
+			if fence == "" {
+				fence = marker
+				shell = shellFences[strings.ToLower(strings.TrimSpace(strings.TrimLeft(trimmed, "`~")))]
+			} else {
+				fence = ""
+			}
+		} else if fence != "" {
+			if command := strings.TrimPrefix(trimmed, "$ "); shell && command != "" && !strings.HasPrefix(command, "#") {
+				commands = append(commands, docLine{line: i + 1, text: command})
+			}
+		} else if match := docHeading.FindStringSubmatch(trimmed); match != nil {
+			level := len(match[1])
+			title := cleanMarkdown(match[2])
+			if level == 1 && len(doc.Sections) == 0 && current == nil {
+				// The intro under the title ends at the first subheading.
+				current = &DocSection{Heading: title, Kind: DocOverview}
+				currentLevel = len("######")
+				continue
+			}
+			kind := classifyDocSection(title)
+			if kind == "" && current != nil && level > currentLevel {
+				current.Text += "\n" + trimmed
+				continue
+			}
+			appendDocSection(&doc, current)
+			current, currentLevel = nil, level
+			if kind != "" {
+				current = &DocSection{Heading: title, Kind: kind}
+			}
+			continue
+		} else {
+			for _, code := range inlineCode.FindAllStringSubmatch(line, -1) {
+				switch {
+				case docPath.MatchString(code[1]):
+					paths = append(paths, docLine{line: i + 1, text: code[1]})
+				case isDocCommand(code[1]):
+					commands = append(commands, docLine{line: i + 1, text: code[1]})
+				}
+			}
+			for _, link := range docLink.FindAllStringSubmatch(line, -1) {
+				paths = append(paths, docLine{line: i + 1, text: link[1], link: true})
+			}
+		}
+		if current != nil {
+			current.Text += "\n" + line
+		}
+	}
+	appendDocSection(&doc, current)
+	return doc, commands, paths
+}
+
+func fenceMarker(line string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+func classifyDocSection(title string) string {
+	title = strings.ToLower(title)
+	for _, kind := range docSectionKinds {
+		if kind.pattern.MatchString(title) {
+			return kind.kind
+		}
+	}
+	return ""
+}
+
+func appendDocSection(doc *Doc, section *DocSection) {
+	if section == nil {
+		return
+	}
+	section.Text = strings.TrimSpace(section.Text)
+	if runes := []rune(section.Text); len(runes) > maxDocSection {
+		section.Text = strings.TrimSpace(string(runes[:maxDocSection])) + "…"
+	}
+	if section.Text != "" {
+		doc.Sections = append(doc.Sections, *section)
+	}
+}
+
+// isDocCommand reports whether inline code is a make or npm-family command.
+func isDocCommand(code string) bool {
+	fields := strings.Fields(code)
+	if len(fields) < 2 {
+		return false
+	}
+	switch fields[0] {
+	case "make", "npm", "yarn", "pnpm":
+		return true
+	}
+	return false
+}
+
+// checkDocCommand returns why command does not work in the repository, or
+// "" when it does or cannot be checked. Only make targets, package.json
+// scripts and ./ scripts at the repository root are checked; a command
+// after a cd is skipped.
+func checkDocCommand(repoPath, command string) string {
+	for _, part := range regexp.MustCompile(`&&|;|\|\|`).Split(command, -1) {
+		fields := strings.Fields(part)
+		for len(fields) > 0 && (fields[0] == "sudo" || strings.Contains(fields[0], "=")) {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "cd" || fields[0] == "pushd" {
+			return ""
+		}
+		if message := checkDocTool(repoPath, part, fields); message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+func checkDocTool(repoPath, command string, fields []string) string {
+	command = strings.TrimSpace(command)
+	args := []string{}
+	for _, field := range fields[1:] {
+		if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+			args = append(args, field)
+		}
+	}
+
+	switch tool := fields[0]; {
+	case tool == "make":
+		content, ok := readFirst(repoPath, "Makefile", "makefile", "GNUmakefile")
+		if !ok {
+			return fmt.Sprintf("`%s` is documented but there is no Makefile", command)
+		}
+		targets := map[string]bool{}
+		for _, match := range makeTarget.FindAllStringSubmatch(content, -1) {
+			for _, target := range strings.Fields(match[1]) {
+				targets[target] = true
+			}
+		}
+		for _, target := range args {
+			if !targets[target] && !strings.Contains(target, "$") {
+				return fmt.Sprintf("`%s` is documented but the Makefile has no `%s` target", command, target)
+			}
+		}
+
+	case tool == "npm" || tool == "yarn" || tool == "pnpm":
+		if len(args) == 0 {
+			return ""
+		}
+		script := ""
+		switch {
+		case args[0] == "run" || args[0] == "run-script":
+			if len(args) > 1 {
+				script = args[1]
+			}
+		case args[0] == "test" || args[0] == "start":
+			script = args[0]
+		case tool == "yarn" && !yarnBuiltin[args[0]]:
+			script = args[0]
+		}
+		if script == "" {
+			return ""
+		}
+		content, ok := readFirst(repoPath, "package.json")
+		if !ok {
+			return fmt.Sprintf("`%s` is documented but there is no package.json", command)
+		}
+		if !containsString(extractPackageJsonScripts(content), script) {
+			return fmt.Sprintf("`%s` is documented but package.json has no `%s` script", command, script)
+		}
+
+	case strings.HasPrefix(tool, "./"):
+		if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(tool))); err != nil {
+			return fmt.Sprintf("`%s` is documented but %s does not exist", command, tool)
+		}
+	}
+	return ""
+}
+
+// checkDocPath returns why a path mentioned in file does not exist, or ""
+// when it does or is not a repository path. Link targets are resolved from
+// the doc's directory; paths in inline code from the repository root, and
+// only when their first directory exists, which tells them apart from
+// import paths and URLs.
+func checkDocPath(repoPath, file string, mention docLine) string {
+	target := mention.text
+	if mention.link {
+		if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") {
+			return ""
+		}
+		target, _, _ = strings.Cut(target, "#")
+		target, _, _ = strings.Cut(target, "?")
+		if target == "" {
+			return ""
+		}
+		target = path.Join(path.Dir(file), target)
+	} else {
+		target = path.Clean(target)
+		first, _, _ := strings.Cut(target, "/")
+		if strings.Contains(first, ".") && !strings.HasPrefix(first, ".") {
+			return ""
+		}
+		if info, err := os.Stat(filepath.Join(repoPath, first)); err != nil || !info.IsDir() {
+			return ""
+		}
+	}
+	if strings.HasPrefix(target, "../") {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(target))); err != nil {
+		return fmt.Sprintf("`%s` is referenced but does not exist", mention.text)
+	}
+	return ""
+}
+
+func readFirst(repoPath string, names ...string) (string, bool) {
+	for _, name := range names {
+		if content, err := os.ReadFile(filepath.Join(repoPath, name)); err == nil {
+			return string(content), true
+		}
+	}
+	return "", false
+}
+
+func buildToolNames(tools []BuildTool) string {
+	names := []string{}
+	for _, tool := range tools {
+		if !containsString(names, tool.Type) {
+			names = append(names, tool.Type)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,77 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectDocs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"README.md": "# Orders\n\nOrders tracks customer orders.\n\n" +
+			"## Installation\n\n```bash\n$ make build\nmake lint\nnpm run dev\n./scripts/bootstrap.sh\n```\n\n" +
+			"### Notes\n\nSee [the guide](docs/guide.md) and [design](docs/design.md#intro).\n\n" +
+			"## License\n\nMIT, see `internal/legacy/` and `github.com/acme/orders`.\n",
+		"CONTRIBUTING.md":        "# Contributing\n\n## Running tests\n\nRun `yarn test` or `npm run typecheck`, then `cd web && make e2e`.\n",
+		"Makefile":               "build: deps\n\tgo build ./...\n\ndeps:\n\tgo mod download\n",
+		"package.json":           "{\n  \"scripts\": {\n    \"dev\": \"vite\",\n    \"test\": \"vitest\"\n  }\n}\n",
+		"docs/guide.md":          "# Guide\n",
+		"internal/api/server.go": "package api\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := &Result{
+		BuildTools: []BuildTool{{Type: "make", File: "Makefile"}},
+		Entrypoints: []Entrypoint{
+			{Type: "go-binary", Path: "cmd/orders/main.go"},
+			{Type: "go-binary", Path: "cmd/migrate/main.go"},
+			{Type: "docker", Path: "Dockerfile"},
+		},
+		Endpoints: []Endpoint{{Method: "GET", Path: "/orders"}},
+	}
+	docs, gaps := detectDocs(tempDir, result)
+
+	wantSections := [][2]string{
+		{"README.md", "Orders/" + DocOverview},
+		{"README.md", "Installation/" + DocInstallation},
+		{"CONTRIBUTING.md", "Running tests/" + DocDevelopment},
+	}
+	gotSections := [][2]string{}
+	for _, doc := range docs {
+		for _, section := range doc.Sections {
+			gotSections = append(gotSections, [2]string{doc.File, section.Heading + "/" + section.Kind})
+		}
+	}
+	if !reflect.DeepEqual(gotSections, wantSections) {
+		t.Errorf("Sections = %v, want %v", gotSections, wantSections)
+	}
+	if text := docs[0].Sections[1].Text; !strings.Contains(text, "make build") || !strings.Contains(text, "docs/guide.md") {
+		t.Errorf("Expected the installation section to keep its code block and subsection, got %q", text)
+	}
+
+	want := []DocGap{
+		{Kind: DocGapStale, File: "README.md", Line: 9, Message: "`make lint` is documented but the Makefile has no `lint` target"},
+		{Kind: DocGapStale, File: "README.md", Line: 11, Message: "`./scripts/bootstrap.sh` is documented but ./scripts/bootstrap.sh does not exist"},
+		{Kind: DocGapStale, File: "README.md", Line: 16, Message: "`docs/design.md#intro` is referenced but does not exist"},
+		{Kind: DocGapStale, File: "README.md", Line: 20, Message: "`internal/legacy/` is referenced but does not exist"},
+		{Kind: DocGapStale, File: "CONTRIBUTING.md", Line: 5, Message: "`npm run typecheck` is documented but package.json has no `typecheck` script"},
+		{Kind: DocGapUndocumented, Message: "Binary `migrate` (cmd/migrate) is not mentioned"},
+		{Kind: DocGapUndocumented, Message: "No API documentation, although endpoints such as `GET /orders` were detected"},
+		{Kind: DocGapUndocumented, Message: "Dockerfile exists but Docker is never mentioned"},
+	}
+	if !reflect.DeepEqual(gaps, want) {
+		t.Errorf("DocGaps =\n%+v\nwant\n%+v", gaps, want)
+	}
+}
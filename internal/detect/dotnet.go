@@ -0,0 +1,322 @@
+package detect
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+var (
+	slnProject  = regexp.MustCompile(`(?m)^Project\("[^"]*"\)\s*=\s*"[^"]*"\s*,\s*"([^"]+\.(?:cs|fs|vb)proj)"`)
+	slnxProject = regexp.MustCompile(`<Project\s+Path="([^"]+\.(?:cs|fs|vb)proj)"`)
+
+	csAttribute = regexp.MustCompile(`^\[(\w+)(?:\((.*)\))?\]`)
+	csClass     = regexp.MustCompile(`\bclass\s+(\w+)`)
+	csMethod    = regexp.MustCompile(`^(?:(?:public|internal|protected|private|static|virtual|override|async|sealed|new)\s+)+[\w<>\[\]?,. ]+\s+(\w+)\s*\(`)
+	csMapRoute  = regexp.MustCompile(`\b(\w+)\.Map(Get|Post|Put|Delete|Patch)\(\s*"([^"]*)"\s*(?:,\s*([\w.]+)\s*\))?`)
+	csMapGroup  = regexp.MustCompile(`\b(?:var\s+)?(\w+)\s*=\s*(\w+)\.MapGroup\(\s*"([^"]*)"`)
+	csDbSet     = regexp.MustCompile(`\bDbSet<(\w+)>`)
+	csDbContext = regexp.MustCompile(`\bclass\s+\w+\s*(?:\([^)]*\))?\s*:\s*(?:\w+\.)*(?:Identity)?DbContext\b`)
+	csProperty  = regexp.MustCompile(`^(?:(?:public|internal|protected|required|virtual)\s+)+[\w<>\[\]?,. ]+\s+(\w+)\s*\{\s*get;`)
+)
+
+// dotnetVerbs maps ASP.NET Core action attributes to HTTP methods.
+var dotnetVerbs = map[string]string{
+	"HttpGet": "GET", "HttpPost": "POST", "HttpPut": "PUT", "HttpDelete": "DELETE", "HttpPatch": "PATCH",
+}
+
+// csproj is the part of an SDK-style project file the detectors use.
+type csproj struct {
+	SDK        string `xml:"Sdk,attr"`
+	Properties []struct {
+		OutputType    string `xml:"OutputType"`
+		IsTestProject string `xml:"IsTestProject"`
+	} `xml:"PropertyGroup"`
+	Packages []struct {
+		Include string `xml:"Include,attr"`
+	} `xml:"ItemGroup>PackageReference"`
+}
+
+func isProjectFile(base string) bool {
+	ext := path.Ext(base)
+	return ext == ".csproj" || ext == ".fsproj" || ext == ".vbproj"
+}
+
+func parseCsproj(p string) (csproj, bool) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return csproj{}, false
+	}
+	var project csproj
+	if xml.Unmarshal(content, &project) != nil {
+		return csproj{}, false
+	}
+	return project, true
+}
+
+// nugetDependencies returns the SDK and package IDs a project file
+// references, each with its parent namespaces, so
+// Microsoft.EntityFrameworkCore.SqlServer also declares
+// Microsoft.EntityFrameworkCore.
+func nugetDependencies(p string) []string {
+	project, ok := parseCsproj(p)
+	if !ok {
+		return nil
+	}
+	ids := []string{project.SDK}
+	for _, pkg := range project.Packages {
+		ids = append(ids, pkg.Include)
+	}
+
+	names := []string{}
+	for _, id := range ids {
+		for parts := strings.Split(id, "."); len(parts) >= 2; parts = parts[:len(parts)-1] {
+			names = append(names, strings.Join(parts, "."))
+		}
+	}
+	return names
+}
+
+func (p csproj) runnable() bool {
+	if p.SDK == "Microsoft.NET.Sdk.Web" {
+		return true
+	}
+	for _, group := range p.Properties {
+		if group.OutputType == "Exe" || group.OutputType == "WinExe" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p csproj) test() bool {
+	for _, group := range p.Properties {
+		if strings.EqualFold(group.IsTestProject, "true") {
+			return true
+		}
+	}
+	for _, pkg := range p.Packages {
+		if pkg.Include == "Microsoft.NET.Test.Sdk" {
+			return true
+		}
+	}
+	return false
+}
+
+// detectDotnetProjects reports one build per solution, with its projects
+// as modules, and one per project no solution includes. Web and console
+// projects are entrypoints run with dotnet run.
+func detectDotnetProjects(ctx context.Context, repoPath string) ([]BuildTool, []Entrypoint) {
+	if repoPath == "" {
+		return nil, nil
+	}
+
+	solutions, projects := []string{}, []string{}
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		switch base := path.Base(rel); {
+		case strings.HasSuffix(base, ".sln") || strings.HasSuffix(base, ".slnx"):
+			solutions = append(solutions, rel)
+		case isProjectFile(base):
+			projects = append(projects, rel)
+		}
+	})
+	if len(projects) == 0 {
+		return nil, nil
+	}
+
+	parsed := map[string]csproj{}
+	entrypoints := []Entrypoint{}
+	for _, rel := range projects {
+		project, ok := parseCsproj(filepath.Join(repoPath, filepath.FromSlash(rel)))
+		if !ok {
+			continue
+		}
+		parsed[rel] = project
+		if project.runnable() && !project.test() {
+			description := ".NET app"
+			if project.SDK == "Microsoft.NET.Sdk.Web" {
+				description = "ASP.NET Core app"
+			}
+			entrypoints = append(entrypoints, Entrypoint{
+				Type:        "dotnet-app",
+				Path:        rel,
+				Command:     "dotnet run --project " + path.Dir(rel),
+				Description: description,
+				Confidence:  ConfidenceHigh,
+			})
+		}
+	}
+
+	build := func(file string, members []string) BuildTool {
+		tool := BuildTool{Type: "dotnet", File: file, Scripts: []string{"dotnet restore " + file, "dotnet build " + file}}
+		for _, member := range members {
+			if parsed[member].test() {
+				tool.Scripts = append(tool.Scripts, "dotnet test "+file)
+				break
+			}
+		}
+		return tool
+	}
+
+	tools := []BuildTool{}
+	covered := map[string]bool{}
+	for _, rel := range solutions {
+		content, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+		members := []string{}
+		modules := []string{}
+		for _, match := range append(slnProject.FindAllStringSubmatch(string(content), -1), slnxProject.FindAllStringSubmatch(string(content), -1)...) {
+			member := path.Join(path.Dir(rel), strings.ReplaceAll(match[1], `\`, "/"))
+			covered[member] = true
+			members = append(members, member)
+			modules = append(modules, path.Dir(member))
+		}
+		tool := build(rel, members)
+		tool.Modules = modules
+		tools = append(tools, tool)
+	}
+	for _, rel := range projects {
+		if !covered[rel] {
+			tools = append(tools, build(rel, []string{rel}))
+		}
+	}
+	return tools, entrypoints
+}
+
+// extractAspNetEndpoints finds attribute-routed controller actions and
+// minimal API routes (app.MapGet, including MapGroup prefixes).
+func extractAspNetEndpoints(content, file string) []Endpoint {
+	endpoints := []Endpoint{}
+	add := func(method, route, handler string) {
+		endpoints = append(endpoints, Endpoint{
+			Method:     method,
+			Path:       route,
+			Handler:    handler,
+			File:       file,
+			Confidence: ConfidenceHigh,
+		})
+	}
+
+	groups := map[string]string{}
+	for _, match := range csMapGroup.FindAllStringSubmatch(content, -1) {
+		groups[match[1]] = joinRoute(groups[match[2]], match[3])
+	}
+	for _, match := range csMapRoute.FindAllStringSubmatch(content, -1) {
+		add(strings.ToUpper(match[2]), joinRoute(groups[match[1]], match[3]), match[4])
+	}
+
+	// Attributes collect until the declaration they decorate: the class
+	// [Route] becomes the prefix, verb and [Route] attributes on an action
+	// give its method and path.
+	class, prefix := "", ""
+	method, route := "", ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if attr := csAttribute.FindStringSubmatch(line); attr != nil {
+			argument := ""
+			if match := quotedString.FindStringSubmatch(attr[2]); match != nil {
+				argument = match[1]
+			}
+			if verb, ok := dotnetVerbs[attr[1]]; ok {
+				method = verb
+			}
+			if _, ok := dotnetVerbs[attr[1]]; ok || attr[1] == "Route" {
+				route = firstNonEmpty(argument, route)
+			}
+			continue
+		}
+
+		if match := csClass.FindStringSubmatch(line); match != nil {
+			class = match[1]
+			prefix = strings.ReplaceAll(route, "[controller]", strings.TrimSuffix(class, "Controller"))
+		} else if match := csMethod.FindStringSubmatch(line); match != nil && method != "" {
+			action := strings.ReplaceAll(route, "[action]", match[1])
+			if strings.HasPrefix(action, "/") || strings.HasPrefix(action, "~/") {
+				action = "/" + strings.TrimLeft(action, "~/")
+			} else {
+				action = joinRoute(prefix, action)
+			}
+			add(method, action, class+"."+match[1])
+		}
+		method, route = "", ""
+	}
+	return endpoints
+}
+
+// detectEntityFrameworkModels returns the entity types exposed as DbSet
+// properties of a DbContext, with their mapped properties. Entities and
+// contexts usually live in different files, so all C# files are read
+// together.
+func detectEntityFrameworkModels(files []scanner.FileInfo) []Model {
+	entities := map[string]bool{}
+	classes := map[string]Model{}
+	for _, file := range files {
+		if file.Language != "csharp" {
+			continue
+		}
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			continue
+		}
+		text := string(content)
+		if csDbContext.MatchString(text) {
+			for _, match := range csDbSet.FindAllStringSubmatch(text, -1) {
+				entities[match[1]] = true
+			}
+		}
+		for name, model := range csharpClasses(text, file.RelativePath) {
+			classes[name] = model
+		}
+	}
+
+	models := []Model{}
+	for _, name := range sortedMapKeys(entities) {
+		if model, ok := classes[name]; ok {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// csharpClasses returns the auto-properties of each class in content,
+// leaving out [NotMapped] ones.
+func csharpClasses(content, file string) map[string]Model {
+	classes := map[string]Model{}
+	current := ""
+	notMapped := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if match := csClass.FindStringSubmatch(trimmed); match != nil && !strings.HasPrefix(trimmed, "//") {
+			current = match[1]
+			classes[current] = Model{Name: current, Fields: []string{}, File: file, Confidence: ConfidenceHigh}
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[NotMapped") {
+			notMapped = true
+			continue
+		}
+		if match := csProperty.FindStringSubmatch(trimmed); match != nil {
+			if !notMapped {
+				model := classes[current]
+				model.Fields = append(model.Fields, match[1])
+				classes[current] = model
+			}
+			notMapped = false
+		}
+	}
+	return classes
+}
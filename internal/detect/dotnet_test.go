@@ -0,0 +1,195 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestExtractAspNetEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "controller",
+			content: `[ApiController]
+[Route("api/[controller]")]
+public class OrdersController : ControllerBase
+{
+    [HttpGet]
+    public IEnumerable<Order> List() => _orders;
+
+    [HttpGet("{id:int}")]
+    [ProducesResponseType(404)]
+    public async Task<ActionResult<Order>> Get(int id) { }
+
+    // [HttpDelete("{id}")]
+    [HttpPost("[action]")]
+    public IActionResult Import() { }
+
+    [Route("/health")]
+    [HttpGet]
+    public IActionResult Health() { }
+
+    public void Helper() { }
+}`,
+			want: []string{
+				"GET /api/Orders OrdersController.List",
+				"GET /api/Orders/{id:int} OrdersController.Get",
+				"POST /api/Orders/Import OrdersController.Import",
+				"GET /health OrdersController.Health",
+			},
+		},
+		{
+			name: "minimal api",
+			content: `var app = builder.Build();
+app.MapGet("/", () => "ok");
+var api = app.MapGroup("/api");
+var users = api.MapGroup("users");
+users.MapGet("/{id}", Users.Get);
+users.MapPost("", (User user) => Results.Created());`,
+			want: []string{
+				"GET / ",
+				"GET /api/users/{id} Users.Get",
+				"POST /api/users ",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, endpoint := range extractAspNetEndpoints(tt.content, "Program.cs") {
+				got = append(got, endpoint.Method+" "+endpoint.Path+" "+endpoint.Handler)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractAspNetEndpoints() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectDotnet(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"Shop.sln": `Microsoft Visual Studio Solution File, Format Version 12.00
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Shop.Api", "src\Shop.Api\Shop.Api.csproj", "{11111111-1111-1111-1111-111111111111}"
+EndProject
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Shop.Tests", "tests\Shop.Tests\Shop.Tests.csproj", "{22222222-2222-2222-2222-222222222222}"
+EndProject
+`,
+		"src/Shop.Api/Shop.Api.csproj": `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Microsoft.EntityFrameworkCore.SqlServer" Version="8.0.0" />
+    <PackageReference Include="Stripe.net" Version="43.0.0" />
+  </ItemGroup>
+</Project>
+`,
+		"src/Shop.Api/Data/ShopContext.cs": `using Microsoft.EntityFrameworkCore;
+
+public class ShopContext(DbContextOptions<ShopContext> options) : DbContext(options)
+{
+    public DbSet<Order> Orders => Set<Order>();
+}
+`,
+		"src/Shop.Api/Models/Order.cs": `public class Order
+{
+    public int Id { get; set; }
+    public required string Customer { get; init; }
+    [NotMapped]
+    public decimal Display { get; set; }
+    public List<Line> Lines { get; } = new();
+}
+
+public class OrderDto
+{
+    public int Id { get; set; }
+}
+`,
+		"tests/Shop.Tests/Shop.Tests.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Microsoft.NET.Test.Sdk" Version="17.8.0" />
+  </ItemGroup>
+</Project>
+`,
+		"tools/Seed/Seed.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+  </PropertyGroup>
+</Project>
+`,
+	}
+	scanned := []scanner.FileInfo{}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(name, ".cs") {
+			scanned = append(scanned, scanner.FileInfo{Path: path, RelativePath: name, Language: "csharp"})
+		}
+	}
+	sort.Slice(scanned, func(i, j int) bool { return scanned[i].RelativePath < scanned[j].RelativePath })
+
+	result, err := Detect(context.Background(), Options{Files: scanned, RepoPath: tempDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tools := []string{}
+	for _, tool := range result.BuildTools {
+		tools = append(tools, tool.File+"["+strings.Join(tool.Modules, ",")+"]"+strings.Join(tool.Scripts, ";"))
+	}
+	want := []string{
+		"Shop.sln[src/Shop.Api,tests/Shop.Tests]dotnet restore Shop.sln;dotnet build Shop.sln;dotnet test Shop.sln",
+		"tools/Seed/Seed.csproj[]dotnet restore tools/Seed/Seed.csproj;dotnet build tools/Seed/Seed.csproj",
+	}
+	if !reflect.DeepEqual(tools, want) {
+		t.Errorf("BuildTools = %q, want %q", tools, want)
+	}
+
+	entrypoints := []string{}
+	for _, entrypoint := range result.Entrypoints {
+		entrypoints = append(entrypoints, entrypoint.Description+": "+entrypoint.Command)
+	}
+	wantEntrypoints := []string{"ASP.NET Core app: dotnet run --project src/Shop.Api", ".NET app: dotnet run --project tools/Seed"}
+	if !reflect.DeepEqual(entrypoints, wantEntrypoints) {
+		t.Errorf("Entrypoints = %q, want %q", entrypoints, wantEntrypoints)
+	}
+
+	frameworks := []string{}
+	for _, framework := range result.Frameworks {
+		frameworks = append(frameworks, framework.Name)
+	}
+	sort.Strings(frameworks)
+	if !reflect.DeepEqual(frameworks, []string{"aspnetcore", "entity-framework"}) {
+		t.Errorf("Frameworks = %v, want aspnetcore and entity-framework", frameworks)
+	}
+
+	if len(result.Models) != 1 || result.Models[0].Name != "Order" || !reflect.DeepEqual(result.Models[0].Fields, []string{"Id", "Customer", "Lines"}) {
+		t.Errorf("Models = %+v, want Order(Id,Customer,Lines)", result.Models)
+	}
+
+	tags := []string{}
+	for _, tag := range result.Tags {
+		tags = append(tags, tag.Name)
+	}
+	if !containsString(tags, "payments") {
+		t.Errorf("Tags = %v, want payments from Stripe.net", tags)
+	}
+}
@@ -0,0 +1,241 @@
+package detect
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// eolWarningWindow is how far ahead of an end-of-life date a runtime is
+// reported as approaching EOL.
+const eolWarningWindow = 180 * 24 * time.Hour
+
+//go:embed eol.json
+var eolData []byte
+
+type eolCycle struct {
+	Cycle   string   `json:"cycle"`
+	EOL     string   `json:"eol"`
+	Aliases []string `json:"aliases"`
+}
+
+var (
+	eolTable map[string][]eolCycle
+
+	goDirective       = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+	pythonRequires    = regexp.MustCompile(`(?m)(?:python_requires|requires-python)\s*=\s*["']?([^"'\n,]+)`)
+	versionNumber     = regexp.MustCompile(`(\d+(?:\.\d+)*)`)
+	runtimeTxtVersion = regexp.MustCompile(`^python-(\d+\.\d+)`)
+
+	// eolImages maps Docker base images to the runtime they ship.
+	eolImages = map[string]string{
+		"golang": "go", "node": "node", "python": "python",
+		"ubuntu": "ubuntu", "debian": "debian", "alpine": "alpine",
+	}
+)
+
+func init() {
+	if err := json.Unmarshal(eolData, &eolTable); err != nil {
+		panic(fmt.Sprintf("invalid embedded eol.json: %v", err))
+	}
+}
+
+type runtimeVersion struct {
+	runtime string
+	version string
+	file    string
+	line    int
+	// ranged marks a minimum version constraint rather than a pinned one.
+	ranged bool
+}
+
+func detectEOLFindings(ctx context.Context, repoPath string, now time.Time) []Finding {
+	if repoPath == "" {
+		return []Finding{}
+	}
+
+	versions := []runtimeVersion{}
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		base := path.Base(rel)
+		switch {
+		case base == "go.mod", base == ".nvmrc", base == ".node-version", base == ".python-version",
+			base == "runtime.txt", base == "package.json", base == "setup.py", base == "setup.cfg",
+			base == "pyproject.toml", IsDockerfile(base):
+		default:
+			return
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return
+		}
+		versions = append(versions, runtimeVersions(rel, string(content))...)
+	})
+
+	findings := []Finding{}
+	for _, v := range versions {
+		if finding, ok := checkEOL(v, now); ok {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func runtimeVersions(rel, content string) []runtimeVersion {
+	base := path.Base(rel)
+
+	switch base {
+	case "go.mod":
+		if match := goDirective.FindStringSubmatchIndex(content); match != nil {
+			return []runtimeVersion{{
+				runtime: "go",
+				version: content[match[2]:match[3]],
+				file:    rel,
+				line:    strings.Count(content[:match[0]], "\n") + 1,
+			}}
+		}
+
+	case ".nvmrc", ".node-version":
+		if match := versionNumber.FindString(strings.TrimSpace(content)); match != "" {
+			return []runtimeVersion{{runtime: "node", version: match, file: rel, line: 1}}
+		}
+
+	case ".python-version":
+		if match := versionNumber.FindString(strings.TrimSpace(content)); match != "" {
+			return []runtimeVersion{{runtime: "python", version: match, file: rel, line: 1}}
+		}
+
+	case "runtime.txt":
+		if match := runtimeTxtVersion.FindStringSubmatch(strings.TrimSpace(content)); match != nil {
+			return []runtimeVersion{{runtime: "python", version: match[1], file: rel, line: 1}}
+		}
+
+	case "package.json":
+		var pkg struct {
+			Engines map[string]string `json:"engines"`
+		}
+		if json.Unmarshal([]byte(content), &pkg) == nil {
+			if match := versionNumber.FindString(pkg.Engines["node"]); match != "" {
+				return []runtimeVersion{{runtime: "node", version: match, file: rel, ranged: true}}
+			}
+		}
+
+	case "setup.py", "setup.cfg", "pyproject.toml":
+		if match := pythonRequires.FindStringSubmatchIndex(content); match != nil {
+			constraint := content[match[2]:match[3]]
+			if version := versionNumber.FindString(constraint); version != "" {
+				return []runtimeVersion{{
+					runtime: "python",
+					version: version,
+					file:    rel,
+					line:    strings.Count(content[:match[0]], "\n") + 1,
+					ranged:  !strings.HasPrefix(strings.TrimSpace(constraint), "=="),
+				}}
+			}
+		}
+
+	default:
+		versions := []runtimeVersion{}
+		for _, inst := range parseDockerfile(content) {
+			if inst.command != "FROM" {
+				continue
+			}
+			image := finalImage(inst.args)
+			name, tag, ok := strings.Cut(image, ":")
+			if !ok {
+				continue
+			}
+			name = strings.TrimPrefix(strings.TrimPrefix(name, "docker.io/"), "library/")
+			runtime, known := eolImages[name]
+			if !known {
+				continue
+			}
+			tag, _, _ = strings.Cut(tag, "@")
+			versions = append(versions, runtimeVersion{runtime: runtime, version: tag, file: rel, line: inst.line})
+		}
+		return versions
+	}
+
+	return nil
+}
+
+func checkEOL(v runtimeVersion, now time.Time) (Finding, bool) {
+	cycle, ok := lookupEOLCycle(v.runtime, v.version)
+	if !ok {
+		return Finding{}, false
+	}
+
+	eol, err := time.Parse("2006-01-02", cycle.EOL)
+	if err != nil {
+		return Finding{}, false
+	}
+
+	name := runtimeDisplayName(v.runtime)
+	finding := Finding{Rule: "eol-runtime", File: v.file, Line: v.line}
+
+	switch {
+	case now.After(eol):
+		finding.Severity = SeverityHigh
+		finding.Message = fmt.Sprintf("%s %s reached end of life on %s", name, cycle.Cycle, cycle.EOL)
+		if v.ranged {
+			finding.Severity = SeverityMedium
+			finding.Message = fmt.Sprintf("version constraint still allows %s %s, which reached end of life on %s",
+				name, cycle.Cycle, cycle.EOL)
+		}
+	case !v.ranged && eol.Sub(now) < eolWarningWindow:
+		finding.Severity = SeverityLow
+		finding.Message = fmt.Sprintf("%s %s reaches end of life on %s", name, cycle.Cycle, cycle.EOL)
+	default:
+		return Finding{}, false
+	}
+
+	return finding, true
+}
+
+// lookupEOLCycle finds the release cycle a version string belongs to,
+// trying the longest matching prefix so "3.10.4" maps to 3.10, not 3.1.
+func lookupEOLCycle(runtime, version string) (eolCycle, bool) {
+	version = strings.TrimPrefix(strings.ToLower(version), "v")
+
+	var best eolCycle
+	found := false
+	for _, cycle := range eolTable[runtime] {
+		for _, alias := range cycle.Aliases {
+			if version == alias || strings.HasPrefix(version, alias+"-") {
+				return cycle, true
+			}
+		}
+
+		if version == cycle.Cycle || strings.HasPrefix(version, cycle.Cycle+".") || strings.HasPrefix(version, cycle.Cycle+"-") {
+			if !found || len(cycle.Cycle) > len(best.Cycle) {
+				best = cycle
+				found = true
+			}
+		}
+	}
+	return best, found
+}
+
+func runtimeDisplayName(runtime string) string {
+	switch runtime {
+	case "go":
+		return "Go"
+	case "node":
+		return "Node.js"
+	case "python":
+		return "Python"
+	case "ubuntu":
+		return "Ubuntu"
+	case "debian":
+		return "Debian"
+	case "alpine":
+		return "Alpine"
+	}
+	return runtime
+}
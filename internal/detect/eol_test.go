@@ -0,0 +1,63 @@
+package detect
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLookupEOLCycle(t *testing.T) {
+	tests := []struct {
+		runtime  string
+		version  string
+		expected string
+	}{
+		{"go", "1.21", "1.21"},
+		{"python", "3.10.4", "3.10"},
+		{"python", "3.1", ""},
+		{"node", "v18.17.0", "18"},
+		{"node", "16-alpine", "16"},
+		{"debian", "bullseye-slim", "11"},
+		{"alpine", "3.16.2", "3.16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.runtime+"-"+tt.version, func(t *testing.T) {
+			cycle, _ := lookupEOLCycle(tt.runtime, tt.version)
+			if cycle.Cycle != tt.expected {
+				t.Errorf("lookupEOLCycle(%s, %s) = %q, want %q", tt.runtime, tt.version, cycle.Cycle, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRuntimeVersionsEOL(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		file     string
+		content  string
+		severity string
+	}{
+		{"go.mod", "module x\n\ngo 1.21.3\n", SeverityHigh},
+		{"go.mod", "module x\n\ngo 1.23\n", SeverityLow},
+		{"go.mod", "module x\n\ngo 1.27\n", ""},
+		{"package.json", `{"engines": {"node": ">=16"}}`, SeverityMedium},
+		{"setup.py", "setup(python_requires='>=3.11')\n", ""},
+		{".nvmrc", "lts/*\n", ""},
+		{"Dockerfile", "FROM python:3.7-slim AS build\nFROM gcr.io/distroless/base\n", SeverityHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+"-"+tt.content, func(t *testing.T) {
+			severity := ""
+			for _, v := range runtimeVersions(tt.file, tt.content) {
+				if finding, ok := checkEOL(v, now); ok {
+					severity = finding.Severity
+				}
+			}
+			if severity != tt.severity {
+				t.Errorf("Expected severity %q, got %q", tt.severity, severity)
+			}
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package detect
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	gradleDependency = regexp.MustCompile(`["']([\w.-]+):([\w.-]+)(?::[^"']*)?["']`)
+	gradlePlugin     = regexp.MustCompile(`\bid\s*\(?\s*["']([\w.-]+)["']`)
+	gradleInclude    = regexp.MustCompile(`(?m)^\s*include\s*\(?([^)\n]*)\)?`)
+	quotedString     = regexp.MustCompile(`["']([^"']+)["']`)
+
+	jvmAnnotation  = regexp.MustCompile(`^@([\w.]+)(\([^)]*\))?\s*`)
+	jvmClass       = regexp.MustCompile(`\b(?:class|interface|object)\s+(\w+)`)
+	jvmFunction    = regexp.MustCompile(`\bfun\s+(?:<[^>]*>\s*)?(\w+)\s*\(`)
+	jvmMethod      = regexp.MustCompile(`^(?:(?:public|protected|private|static|final|synchronized|abstract|default)\s+)*[\w<>\[\]?,. ]+\s+(\w+)\s*\(`)
+	jvmRequestVerb = regexp.MustCompile(`RequestMethod\.(\w+)`)
+	javaField      = regexp.MustCompile(`^(?:(?:private|protected|public|final|transient)\s+)+[\w<>\[\]?,. ]+\s+(\w+)\s*(?:=[^;]*)?;`)
+	kotlinProperty = regexp.MustCompile(`\b(?:val|var)\s+(\w+)\s*:`)
+)
+
+// jvmVerbs maps method-level mapping annotations to HTTP methods: Spring's
+// @GetMapping, Micronaut's @Get and JAX-RS's @GET (used by Quarkus).
+var jvmVerbs = map[string]string{
+	"GetMapping": "GET", "PostMapping": "POST", "PutMapping": "PUT", "DeleteMapping": "DELETE", "PatchMapping": "PATCH",
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Delete": "DELETE", "Patch": "PATCH",
+	"GET": "GET", "POST": "POST", "PUT": "PUT", "DELETE": "DELETE", "PATCH": "PATCH",
+}
+
+// jvmDependencies returns the group IDs, each with its parent groups, and
+// plugin IDs a Maven or Gradle build file declares, so io.micronaut.data
+// also declares io.micronaut.
+func jvmDependencies(p string) []string {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return nil
+	}
+
+	groups := []string{}
+	if strings.HasSuffix(p, ".xml") {
+		var pom struct {
+			Parent struct {
+				GroupID string `xml:"groupId"`
+			} `xml:"parent"`
+			Dependencies []struct {
+				GroupID string `xml:"groupId"`
+			} `xml:"dependencies>dependency"`
+			Plugins []struct {
+				GroupID string `xml:"groupId"`
+			} `xml:"build>plugins>plugin"`
+		}
+		if xml.Unmarshal(content, &pom) != nil {
+			return nil
+		}
+		groups = append(groups, pom.Parent.GroupID)
+		for _, dependency := range pom.Dependencies {
+			groups = append(groups, dependency.GroupID)
+		}
+		for _, plugin := range pom.Plugins {
+			groups = append(groups, plugin.GroupID)
+		}
+	} else {
+		for _, match := range gradleDependency.FindAllStringSubmatch(string(content), -1) {
+			groups = append(groups, match[1])
+		}
+		for _, match := range gradlePlugin.FindAllStringSubmatch(string(content), -1) {
+			groups = append(groups, match[1])
+		}
+	}
+
+	names := []string{}
+	for _, group := range groups {
+		for parts := strings.Split(group, "."); len(parts) >= 2; parts = parts[:len(parts)-1] {
+			names = append(names, strings.Join(parts, "."))
+		}
+	}
+	return names
+}
+
+func isJVMBuildFile(base string) bool {
+	switch base {
+	case "pom.xml", "build.gradle", "build.gradle.kts":
+		return true
+	}
+	return false
+}
+
+// detectJVMBuildTools reports Maven and Gradle builds with their modules.
+// Build files nested inside another build of the same tool are its modules,
+// not separate builds.
+func detectJVMBuildTools(ctx context.Context, repoPath string) []BuildTool {
+	if repoPath == "" {
+		return nil
+	}
+
+	builds := []string{}
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		if isJVMBuildFile(path.Base(rel)) {
+			builds = append(builds, rel)
+		}
+	})
+	sort.Slice(builds, func(i, j int) bool {
+		if di, dj := strings.Count(builds[i], "/"), strings.Count(builds[j], "/"); di != dj {
+			return di < dj
+		}
+		return builds[i] < builds[j]
+	})
+
+	tools := []BuildTool{}
+	roots := map[string][]string{}
+	for _, rel := range builds {
+		tool := "gradle"
+		if path.Base(rel) == "pom.xml" {
+			tool = "maven"
+		}
+		dir := path.Dir(rel)
+		nested := false
+		for _, root := range roots[tool] {
+			if root == "." || strings.HasPrefix(dir+"/", root+"/") {
+				nested = true
+				break
+			}
+		}
+		if nested {
+			continue
+		}
+		roots[tool] = append(roots[tool], dir)
+
+		abs := filepath.Join(repoPath, filepath.FromSlash(dir))
+		build := BuildTool{Type: tool, File: rel}
+		if tool == "maven" {
+			command := "mvn"
+			if _, err := os.Stat(filepath.Join(abs, "mvnw")); err == nil {
+				command = "./mvnw"
+			}
+			build.Scripts = []string{command + " package", command + " test"}
+			build.Modules = mavenModules(filepath.Join(abs, "pom.xml"))
+		} else {
+			command := "gradle"
+			if _, err := os.Stat(filepath.Join(abs, "gradlew")); err == nil {
+				command = "./gradlew"
+			}
+			build.Scripts = []string{command + " build", command + " test"}
+			build.Modules = gradleModules(abs)
+		}
+		tools = append(tools, build)
+	}
+	return tools
+}
+
+func mavenModules(p string) []string {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return nil
+	}
+	var pom struct {
+		Modules []string `xml:"modules>module"`
+	}
+	if xml.Unmarshal(content, &pom) != nil {
+		return nil
+	}
+	return pom.Modules
+}
+
+// gradleModules reads the include(...) lines of settings.gradle(.kts),
+// turning ":services:api" into "services/api".
+func gradleModules(dir string) []string {
+	modules := []string{}
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		for _, include := range gradleInclude.FindAllStringSubmatch(string(content), -1) {
+			for _, match := range quotedString.FindAllStringSubmatch(include[1], -1) {
+				modules = append(modules, strings.ReplaceAll(strings.TrimPrefix(match[1], ":"), ":", "/"))
+			}
+		}
+	}
+	return modules
+}
+
+// jvmDeclaration is a class or method seen by the JVM source walker with
+// the annotations placed before it.
+type jvmDeclaration struct {
+	annotations map[string]string
+	class       string
+	method      string
+}
+
+// walkJVMDeclarations calls fn for every class declaration in Java or Kotlin
+// source and for every method that carries annotations. Annotation arguments must
+// fit on one line, as they almost always do.
+func walkJVMDeclarations(content string, fn func(jvmDeclaration)) {
+	pending := map[string]string{}
+	inComment := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if inComment {
+			if strings.Contains(line, "*/") {
+				inComment = false
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "/*") {
+			inComment = !strings.Contains(line, "*/")
+			continue
+		}
+		if strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		for {
+			match := jvmAnnotation.FindStringSubmatch(line)
+			if match == nil {
+				break
+			}
+			name := match[1][strings.LastIndex(match[1], ".")+1:]
+			pending[name] = strings.TrimSuffix(strings.TrimPrefix(match[2], "("), ")")
+			line = line[len(match[0]):]
+		}
+		if line == "" {
+			continue
+		}
+
+		// Every class is reported so methods are attributed to the right
+		// one; methods only when annotated. Annotations on anything else,
+		// such as a field, do not carry over.
+		if match := jvmClass.FindStringSubmatch(line); match != nil {
+			fn(jvmDeclaration{annotations: pending, class: match[1]})
+		} else if len(pending) > 0 {
+			if match := jvmFunction.FindStringSubmatch(line); match != nil {
+				fn(jvmDeclaration{annotations: pending, method: match[1]})
+			} else if match := jvmMethod.FindStringSubmatch(line); match != nil {
+				fn(jvmDeclaration{annotations: pending, method: match[1]})
+			}
+		}
+		pending = map[string]string{}
+	}
+}
+
+// annotationPath returns the path an annotation argument names:
+// ("/orders"), (value = "/orders") or (path = ["/orders"]).
+func annotationPath(args string) string {
+	for _, match := range quotedString.FindAllStringSubmatch(args, -1) {
+		if strings.HasPrefix(match[1], "/") || !strings.Contains(match[1], " ") {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+func joinRoute(prefix, route string) string {
+	return "/" + strings.Trim(strings.Trim(prefix, "/")+"/"+strings.Trim(route, "/"), "/")
+}
+
+// extractJVMEndpoints finds Spring, Micronaut and JAX-RS (Quarkus) routes,
+// joining class-level prefixes with method-level mappings.
+func extractJVMEndpoints(content, file string) []Endpoint {
+	endpoints := []Endpoint{}
+	class, prefix, client := "", "", false
+	walkJVMDeclarations(content, func(decl jvmDeclaration) {
+		if decl.class != "" {
+			class, prefix = decl.class, ""
+			// Feign clients declare the routes they call, not serve.
+			_, client = decl.annotations["FeignClient"]
+			for _, name := range []string{"RequestMapping", "Controller", "Path"} {
+				if route := annotationPath(decl.annotations[name]); route != "" {
+					prefix = route
+				}
+			}
+			return
+		}
+		if client {
+			return
+		}
+
+		method, route := "", ""
+		for name, args := range decl.annotations {
+			if verb, ok := jvmVerbs[name]; ok {
+				method = verb
+				if route == "" {
+					route = annotationPath(args)
+				}
+			}
+			if name == "RequestMapping" {
+				method = "ANY"
+				if verb := jvmRequestVerb.FindStringSubmatch(args); verb != nil {
+					method = strings.ToUpper(verb[1])
+				}
+				route = annotationPath(args)
+			}
+			if name == "Path" {
+				route = annotationPath(args)
+			}
+		}
+		if method == "" {
+			return
+		}
+
+		handler := decl.method
+		if class != "" {
+			handler = class + "." + decl.method
+		}
+		endpoints = append(endpoints, Endpoint{
+			Method:     method,
+			Path:       joinRoute(prefix, route),
+			Handler:    handler,
+			File:       file,
+			Confidence: ConfidenceHigh,
+		})
+	})
+	return endpoints
+}
+
+// extractJPAModels returns @Entity classes with their persistent fields.
+func extractJPAModels(content, file string) []Model {
+	models := []Model{}
+	lines := strings.Split(content, "\n")
+	walkJVMDeclarations(content, func(decl jvmDeclaration) {
+		if _, ok := decl.annotations["Entity"]; !ok || decl.class == "" {
+			return
+		}
+		models = append(models, Model{
+			Name:       decl.class,
+			Fields:     entityFields(lines, decl.class),
+			File:       file,
+			Confidence: ConfidenceHigh,
+		})
+	})
+	return models
+}
+
+// entityFields lists the non-static fields of class: Java fields in its body,
+// Kotlin properties in its constructor or body. @Transient fields are left
+// out.
+func entityFields(lines []string, class string) []string {
+	fields := []string{}
+	start := -1
+	for i, line := range lines {
+		if match := jvmClass.FindStringSubmatch(line); match != nil && match[1] == class {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return fields
+	}
+
+	depth, opened, transient := 0, false, false
+	for _, line := range lines[start:] {
+		trimmed := strings.TrimSpace(line)
+		for match := jvmAnnotation.FindStringSubmatch(trimmed); match != nil; match = jvmAnnotation.FindStringSubmatch(trimmed) {
+			if match[1] == "Transient" {
+				transient = true
+			}
+			trimmed = trimmed[len(match[0]):]
+		}
+
+		if depth <= 1 && trimmed != "" && !strings.Contains(" "+trimmed, " static ") {
+			names := []string{}
+			if match := javaField.FindStringSubmatch(trimmed); match != nil {
+				names = append(names, match[1])
+			}
+			for _, match := range kotlinProperty.FindAllStringSubmatch(trimmed, -1) {
+				names = append(names, match[1])
+			}
+			if len(names) > 0 {
+				if !transient {
+					fields = append(fields, names...)
+				}
+				transient = false
+			}
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		opened = opened || strings.Contains(line, "{")
+		if opened && depth <= 0 {
+			break
+		}
+	}
+	return fields
+}
@@ -0,0 +1,156 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestDetectJVM(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"pom.xml": `<project>
+  <parent><groupId>org.springframework.boot</groupId></parent>
+  <modules><module>orders</module><module>billing</module></modules>
+</project>`,
+		"orders/pom.xml": "<project><dependencies><dependency><groupId>org.springframework.boot</groupId></dependency></dependencies></project>",
+		"mvnw":           "#!/bin/sh\n",
+		"orders/src/main/java/shop/OrderController.java": `package shop;
+
+import org.springframework.boot.autoconfigure.SpringBootApplication;
+import org.springframework.web.bind.annotation.*;
+
+@RestController
+@RequestMapping("/api/orders")
+public class OrderController {
+    @Autowired
+    private OrderService service;
+
+    @GetMapping
+    public List<Order> list() { return service.all(); }
+
+    @GetMapping("/{id}")
+    public ResponseEntity<Order> get(@PathVariable Long id) { return null; }
+
+    @RequestMapping(value = "/{id}/cancel", method = RequestMethod.POST)
+    public void cancel(@PathVariable Long id) {}
+}
+`,
+		"orders/src/main/java/shop/Order.java": `package shop;
+
+@Entity
+@Table(name = "orders")
+public class Order {
+    private static final long serialVersionUID = 1L;
+
+    @Id
+    @GeneratedValue
+    private Long id;
+
+    @Column(name = "total")
+    private BigDecimal total;
+
+    @Transient
+    private String display;
+
+    public Long getId() { return id; }
+}
+`,
+		"orders/src/main/java/shop/PaymentsClient.java": `package shop;
+
+@FeignClient(name = "payments")
+public interface PaymentsClient {
+    @PostMapping("/charges")
+    Charge charge(Charge charge);
+}
+`,
+		"billing/build.gradle.kts":    "plugins {\n    id(\"io.micronaut.application\") version \"4.0.0\"\n}\n",
+		"billing/settings.gradle.kts": "include(\"invoices\", \":invoices:pdf\")\n",
+		"billing/src/main/kotlin/InvoiceController.kt": `package billing
+
+import io.micronaut.http.annotation.*
+
+@Controller("/invoices")
+class InvoiceController(private val repo: InvoiceRepository) {
+    @Get("/{id}")
+    fun show(id: Long): Invoice = repo.find(id)
+
+    @Post fun create(@Body invoice: Invoice) = repo.save(invoice)
+}
+
+@Entity
+data class Invoice(
+    @Id val id: Long,
+    val amount: BigDecimal,
+) {
+    var paid: Boolean = false
+}
+`,
+	}
+	scanned := []scanner.FileInfo{}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		language := map[string]string{".java": "java", ".kt": "kotlin"}[filepath.Ext(name)]
+		if language != "" {
+			scanned = append(scanned, scanner.FileInfo{Path: path, RelativePath: name, Language: language})
+		}
+	}
+
+	sort.Slice(scanned, func(i, j int) bool { return scanned[i].RelativePath < scanned[j].RelativePath })
+
+	result, err := Detect(context.Background(), Options{Files: scanned, RepoPath: tempDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frameworks := []string{}
+	for _, fw := range result.Frameworks {
+		frameworks = append(frameworks, fw.Language+"/"+fw.Name)
+	}
+	if got := strings.Join(frameworks, ", "); got != "java/micronaut, java/spring-boot, kotlin/micronaut" {
+		t.Errorf("Frameworks = %s", got)
+	}
+
+	endpoints := []string{}
+	for _, endpoint := range result.Endpoints {
+		endpoints = append(endpoints, endpoint.Method+" "+endpoint.Path+" "+endpoint.Handler)
+	}
+	want := []string{
+		"GET /api/orders OrderController.list",
+		"GET /api/orders/{id} OrderController.get",
+		"POST /api/orders/{id}/cancel OrderController.cancel",
+		"POST /invoices InvoiceController.create",
+		"GET /invoices/{id} InvoiceController.show",
+	}
+	if got := strings.Join(endpoints, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("Endpoints =\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+
+	models := []string{}
+	for _, model := range result.Models {
+		models = append(models, model.Name+"("+strings.Join(model.Fields, ",")+")")
+	}
+	if got := strings.Join(models, " "); got != "Invoice(id,amount,paid) Order(id,total)" {
+		t.Errorf("Models = %s", got)
+	}
+
+	tools := []string{}
+	for _, tool := range result.BuildTools {
+		tools = append(tools, tool.Type+":"+tool.File+"["+strings.Join(tool.Modules, ",")+"]"+tool.Scripts[0])
+	}
+	if got := strings.Join(tools, " "); got != "gradle:billing/build.gradle.kts[invoices,invoices/pdf]gradle build maven:pom.xml[orders,billing]./mvnw package" {
+		t.Errorf("BuildTools = %s", got)
+	}
+}
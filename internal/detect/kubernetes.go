@@ -0,0 +1,531 @@
+package detect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HelmChart describes a chart found via its Chart.yaml.
+type HelmChart struct {
+	Name         string
+	Version      string
+	AppVersion   string
+	Path         string
+	Dependencies []string
+}
+
+// K8sResource is a summarized Kubernetes object. Fields that do not apply to
+// the object's kind are left empty.
+type K8sResource struct {
+	Kind        string
+	Name        string
+	Namespace   string
+	Source      string
+	Chart       string
+	Replicas    string
+	Images      []string
+	Ports       []string
+	EnvSources  []string
+	ServiceType string
+	Hosts       []string
+	Backends    []string
+	DataKeys    []string
+
+	podSpec map[string]any
+}
+
+var (
+	workloadKinds = map[string]bool{
+		"Deployment": true, "StatefulSet": true, "DaemonSet": true,
+		"Job": true, "CronJob": true, "ReplicaSet": true, "Pod": true,
+	}
+	topologyKinds = map[string]bool{
+		"Service": true, "Ingress": true, "ConfigMap": true,
+	}
+
+	helmAction      = regexp.MustCompile(`{{-?\s*(.*?)\s*-?}}`)
+	helmValuesRef   = regexp.MustCompile(`^\.Values\.([\w.]+)`)
+	helmIndent      = regexp.MustCompile(`\bn?indent\s+(\d+)`)
+	manifestDocSep  = regexp.MustCompile(`(?m)^---.*$`)
+	helmBlockStart  = regexp.MustCompile(`^(if|range|with|define|block)\b`)
+	helmBlockElse   = regexp.MustCompile(`^else\b`)
+	helmBlockFinish = regexp.MustCompile(`^end\b`)
+)
+
+func detectKubernetes(ctx context.Context, repoPath string) ([]HelmChart, []K8sResource) {
+	if repoPath == "" {
+		return []HelmChart{}, []K8sResource{}
+	}
+
+	charts := []HelmChart{}
+	chartValues := map[string]map[string]any{}
+	manifests := []string{}
+
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		base := path.Base(rel)
+		switch {
+		case base == "Chart.yaml":
+			if chart, ok := parseHelmChart(p, rel); ok {
+				charts = append(charts, chart)
+				chartValues[chart.Path] = loadHelmValues(filepath.Join(filepath.Dir(p), "values.yaml"))
+			}
+		case strings.HasSuffix(base, ".yaml") || strings.HasSuffix(base, ".yml"):
+			manifests = append(manifests, rel)
+		}
+	})
+
+	resources := []K8sResource{}
+	for _, rel := range manifests {
+		content, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+
+		text := string(content)
+		chart := chartForTemplate(rel, charts)
+		if chart == nil {
+			if !strings.Contains(text, "apiVersion:") || !strings.Contains(text, "kind:") {
+				continue
+			}
+		} else {
+			text = renderHelmTemplate(text, chart.Name, chartValues[chart.Path])
+		}
+
+		for _, doc := range manifestDocSep.Split(text, -1) {
+			var object map[string]any
+			if err := yaml.Unmarshal([]byte(doc), &object); err != nil || object == nil {
+				continue
+			}
+			resource, ok := summarizeK8sObject(object)
+			if !ok {
+				continue
+			}
+			resource.Source = rel
+			if chart != nil {
+				resource.Chart = chart.Name
+			}
+			resources = append(resources, resource)
+		}
+	}
+
+	sort.Slice(charts, func(i, j int) bool { return charts[i].Path < charts[j].Path })
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Kind != resources[j].Kind {
+			return resources[i].Kind < resources[j].Kind
+		}
+		return resources[i].Name < resources[j].Name
+	})
+
+	return charts, resources
+}
+
+func parseHelmChart(p, rel string) (HelmChart, bool) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return HelmChart{}, false
+	}
+
+	var meta struct {
+		Name         string `yaml:"name"`
+		Version      string `yaml:"version"`
+		AppVersion   string `yaml:"appVersion"`
+		Dependencies []struct {
+			Name string `yaml:"name"`
+		} `yaml:"dependencies"`
+	}
+	if err := yaml.Unmarshal(content, &meta); err != nil || meta.Name == "" {
+		return HelmChart{}, false
+	}
+
+	chart := HelmChart{
+		Name:       meta.Name,
+		Version:    meta.Version,
+		AppVersion: meta.AppVersion,
+		Path:       path.Dir(rel),
+	}
+	for _, dep := range meta.Dependencies {
+		chart.Dependencies = append(chart.Dependencies, dep.Name)
+	}
+	return chart, true
+}
+
+func loadHelmValues(p string) map[string]any {
+	values := map[string]any{}
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return values
+	}
+	yaml.Unmarshal(content, &values)
+	return values
+}
+
+func chartForTemplate(rel string, charts []HelmChart) *HelmChart {
+	for i := range charts {
+		prefix := charts[i].Path + "/templates/"
+		if charts[i].Path == "." {
+			prefix = "templates/"
+		}
+		if strings.HasPrefix(rel, prefix) {
+			return &charts[i]
+		}
+	}
+	return nil
+}
+
+// renderHelmTemplate turns a chart template into parseable YAML without a Helm
+// binary. Control lines are dropped (keeping only the first branch of an
+// if/else), simple .Values references are resolved from values.yaml, and
+// whole-line includes become placeholder keys so the surrounding block is
+// still seen as non-empty.
+func renderHelmTemplate(text, chartName string, values map[string]any) string {
+	var out strings.Builder
+	depth := 0
+	skipDepth := -1
+	placeholder := 0
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if match := helmAction.FindStringSubmatch(trimmed); match != nil && match[0] == trimmed {
+			action := match[1]
+			switch {
+			case strings.HasPrefix(action, "/*"):
+				continue
+			case helmBlockStart.MatchString(action):
+				depth++
+				continue
+			case helmBlockElse.MatchString(action):
+				if skipDepth < 0 {
+					skipDepth = depth
+				}
+				continue
+			case helmBlockFinish.MatchString(action):
+				if skipDepth == depth {
+					skipDepth = -1
+				}
+				depth--
+				continue
+			}
+
+			if skipDepth >= 0 {
+				continue
+			}
+			if indent := helmIndent.FindStringSubmatch(action); indent != nil {
+				var n int
+				fmt.Sscanf(indent[1], "%d", &n)
+				placeholder++
+				fmt.Fprintf(&out, "%s__templated_%d__: %q\n", strings.Repeat(" ", n), placeholder, action)
+			}
+			continue
+		}
+
+		if skipDepth >= 0 {
+			continue
+		}
+
+		line = helmAction.ReplaceAllStringFunc(line, func(action string) string {
+			expr := helmAction.FindStringSubmatch(action)[1]
+			return resolveHelmExpr(expr, chartName, values)
+		})
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+func resolveHelmExpr(expr, chartName string, values map[string]any) string {
+	first, _, _ := strings.Cut(expr, "|")
+	first = strings.TrimSpace(first)
+
+	if match := helmValuesRef.FindStringSubmatch(first); match != nil {
+		if value, ok := lookupPath(values, strings.Split(match[1], ".")); ok {
+			switch value.(type) {
+			case map[string]any, []any:
+			default:
+				return fmt.Sprint(value)
+			}
+		}
+		return "templated"
+	}
+
+	if strings.HasPrefix(first, ".Chart.Name") || strings.HasPrefix(first, ".Release.Name") ||
+		(strings.HasPrefix(first, "include") && strings.Contains(first, "name")) {
+		return chartName
+	}
+
+	return "templated"
+}
+
+func lookupPath(values map[string]any, keys []string) (any, bool) {
+	var current any = values
+	for _, key := range keys {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func summarizeK8sObject(object map[string]any) (K8sResource, bool) {
+	kind := yamlString(object["kind"])
+	if !workloadKinds[kind] && !topologyKinds[kind] {
+		return K8sResource{}, false
+	}
+
+	metadata := yamlMap(object["metadata"])
+	resource := K8sResource{
+		Kind:      kind,
+		Name:      yamlString(metadata["name"]),
+		Namespace: yamlString(metadata["namespace"]),
+	}
+	spec := yamlMap(object["spec"])
+
+	switch kind {
+	case "Service":
+		resource.ServiceType = yamlString(spec["type"])
+		if resource.ServiceType == "" {
+			resource.ServiceType = "ClusterIP"
+		}
+		for _, item := range yamlList(spec["ports"]) {
+			port := yamlMap(item)
+			entry := yamlString(port["port"])
+			if target := yamlString(port["targetPort"]); target != "" && target != entry {
+				entry += "->" + target
+			}
+			if protocol := yamlString(port["protocol"]); protocol != "" && protocol != "TCP" {
+				entry += "/" + protocol
+			}
+			resource.Ports = append(resource.Ports, entry)
+		}
+
+	case "Ingress":
+		for _, item := range yamlList(spec["rules"]) {
+			rule := yamlMap(item)
+			if host := yamlString(rule["host"]); host != "" {
+				resource.Hosts = appendUnique(resource.Hosts, host)
+			}
+			for _, p := range yamlList(yamlMap(rule["http"])["paths"]) {
+				resource.Backends = appendUnique(resource.Backends, ingressBackend(yamlMap(p)))
+			}
+		}
+		if backend := yamlMap(spec["defaultBackend"]); len(backend) > 0 {
+			resource.Backends = appendUnique(resource.Backends, ingressBackend(map[string]any{"backend": backend}))
+		}
+
+	case "ConfigMap":
+		for key := range yamlMap(object["data"]) {
+			resource.DataKeys = append(resource.DataKeys, key)
+		}
+		sort.Strings(resource.DataKeys)
+
+	default:
+		resource.Replicas = yamlString(spec["replicas"])
+		if resource.Replicas == "" && kind != "DaemonSet" && kind != "Job" && kind != "CronJob" && kind != "Pod" {
+			resource.Replicas = "1"
+		}
+
+		podSpec := spec
+		if kind == "CronJob" {
+			podSpec = yamlMap(yamlMap(yamlMap(spec["jobTemplate"])["spec"])["template"])
+			podSpec = yamlMap(podSpec["spec"])
+		} else if kind != "Pod" {
+			podSpec = yamlMap(yamlMap(spec["template"])["spec"])
+		}
+		resource.podSpec = podSpec
+
+		for _, item := range yamlList(podSpec["containers"]) {
+			container := yamlMap(item)
+			if image := yamlString(container["image"]); image != "" {
+				resource.Images = appendUnique(resource.Images, image)
+			}
+			for _, p := range yamlList(container["ports"]) {
+				port := yamlMap(p)
+				if number := yamlString(port["containerPort"]); number != "" {
+					resource.Ports = appendUnique(resource.Ports, number)
+				}
+			}
+			resource.EnvSources = append(resource.EnvSources, containerEnvSources(container)...)
+		}
+		sort.Strings(resource.EnvSources)
+		resource.EnvSources = dedupeSorted(resource.EnvSources)
+	}
+
+	return resource, resource.Name != ""
+}
+
+func ingressBackend(p map[string]any) string {
+	backend := yamlMap(p["backend"])
+	pathValue := yamlString(p["path"])
+
+	service := yamlMap(backend["service"])
+	name := yamlString(service["name"])
+	port := yamlString(yamlMap(service["port"])["number"])
+	if port == "" {
+		port = yamlString(yamlMap(service["port"])["name"])
+	}
+	// networking.k8s.io/v1beta1 layout.
+	if name == "" {
+		name = yamlString(backend["serviceName"])
+		port = yamlString(backend["servicePort"])
+	}
+
+	target := name
+	if port != "" {
+		target += ":" + port
+	}
+	if pathValue != "" {
+		return pathValue + " -> " + target
+	}
+	return target
+}
+
+func containerEnvSources(container map[string]any) []string {
+	sources := []string{}
+	for _, item := range yamlList(container["envFrom"]) {
+		ref := yamlMap(item)
+		if name := yamlString(yamlMap(ref["configMapRef"])["name"]); name != "" {
+			sources = append(sources, "configmap/"+name)
+		}
+		if name := yamlString(yamlMap(ref["secretRef"])["name"]); name != "" {
+			sources = append(sources, "secret/"+name)
+		}
+	}
+	for _, item := range yamlList(container["env"]) {
+		valueFrom := yamlMap(yamlMap(item)["valueFrom"])
+		if name := yamlString(yamlMap(valueFrom["configMapKeyRef"])["name"]); name != "" {
+			sources = append(sources, "configmap/"+name)
+		}
+		if name := yamlString(yamlMap(valueFrom["secretKeyRef"])["name"]); name != "" {
+			sources = append(sources, "secret/"+name)
+		}
+	}
+	return sources
+}
+
+func yamlMap(value any) map[string]any {
+	if m, ok := value.(map[string]any); ok {
+		return m
+	}
+	return map[string]any{}
+}
+
+func yamlList(value any) []any {
+	if l, ok := value.([]any); ok {
+		return l
+	}
+	return nil
+}
+
+func yamlString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func appendUnique(slice []string, item string) []string {
+	if item == "" || slices.Contains(slice, item) {
+		return slice
+	}
+	return append(slice, item)
+}
+
+func dedupeSorted(items []string) []string {
+	result := []string{}
+	for i, item := range items {
+		if i == 0 || item != items[i-1] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// checkK8sResources flags operational risks in workload pod specs. Blocks
+// that come from unresolved Helm includes are treated as present.
+func checkK8sResources(resources []K8sResource) []Finding {
+	findings := []Finding{}
+
+	for _, resource := range resources {
+		if resource.podSpec == nil {
+			continue
+		}
+		name := resource.Kind + "/" + resource.Name
+		finding := func(severity, rule, message string) {
+			findings = append(findings, Finding{
+				Severity: severity,
+				Rule:     rule,
+				File:     resource.Source,
+				Message:  name + ": " + message,
+			})
+		}
+
+		for _, item := range yamlList(resource.podSpec["volumes"]) {
+			volume := yamlMap(item)
+			if hostPath := yamlMap(volume["hostPath"]); len(hostPath) > 0 {
+				finding(SeverityHigh, "host-path-mount",
+					fmt.Sprintf("volume %s mounts host path %s", yamlString(volume["name"]), yamlString(hostPath["path"])))
+			}
+		}
+
+		longRunning := resource.Kind != "Job" && resource.Kind != "CronJob"
+		for _, item := range yamlList(resource.podSpec["containers"]) {
+			container := yamlMap(item)
+			containerName := yamlString(container["name"])
+
+			if yamlBool(yamlMap(container["securityContext"])["privileged"]) {
+				finding(SeverityHigh, "privileged-container", "container "+containerName+" runs privileged")
+			}
+
+			resourcesBlock := yamlMap(container["resources"])
+			if len(yamlMap(resourcesBlock["limits"])) == 0 && !hasTemplatedKey(resourcesBlock) {
+				finding(SeverityMedium, "missing-resource-limits", "container "+containerName+" has no resource limits")
+			}
+
+			if longRunning && container["livenessProbe"] == nil && container["readinessProbe"] == nil && !hasTemplatedKey(container) {
+				finding(SeverityMedium, "missing-probes", "container "+containerName+" has no liveness or readiness probe")
+			}
+		}
+	}
+
+	return findings
+}
+
+func hasTemplatedKey(m map[string]any) bool {
+	for key := range m {
+		if strings.HasPrefix(key, "__templated_") {
+			return true
+		}
+	}
+	return false
+}
+
+func yamlBool(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectKubernetes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"deploy/app.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\nspec:\n  replicas: 3\n  template:\n    spec:\n      containers:\n        - name: api\n          image: acme/api:1.2\n          ports:\n            - containerPort: 8080\n          envFrom:\n            - configMapRef:\n                name: api-config\n          env:\n            - name: DB_PASSWORD\n              valueFrom:\n                secretKeyRef:\n                  name: db\n                  key: password\n" +
+			"---\napiVersion: v1\nkind: Service\nmetadata:\n  name: api\nspec:\n  ports:\n    - port: 80\n      targetPort: 8080\n",
+		"charts/web/Chart.yaml":                "apiVersion: v2\nname: web\nversion: 0.3.0\nappVersion: \"2.0\"\n",
+		"charts/web/values.yaml":               "replicaCount: 2\nimage:\n  repository: acme/web\n  tag: \"2.0\"\n",
+		"charts/web/templates/deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: {{ include \"web.fullname\" . }}\nspec:\n  {{- if .Values.autoscaling.enabled }}\n  replicas: 1\n  {{- else }}\n  replicas: 9\n  {{- end }}\n  template:\n    spec:\n      containers:\n        - name: web\n          image: \"{{ .Values.image.repository }}:{{ .Values.image.tag }}\"\n          resources:\n            {{- toYaml .Values.resources | nindent 12 }}\n",
+		"charts/web/templates/ingress.yaml":    "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata:\n  name: web\nspec:\n  rules:\n    - host: web.example.com\n      http:\n        paths:\n          - path: /\n            backend:\n              service:\n                name: web\n                port:\n                  number: 80\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	charts, resources := detectKubernetes(context.Background(), tempDir)
+
+	if len(charts) != 1 || charts[0].Name != "web" || charts[0].Path != "charts/web" {
+		t.Fatalf("Unexpected charts: %+v", charts)
+	}
+
+	byKey := map[string]K8sResource{}
+	for _, resource := range resources {
+		byKey[resource.Kind+"/"+resource.Name] = resource
+	}
+
+	api := byKey["Deployment/api"]
+	if api.Replicas != "3" || len(api.Ports) != 1 || len(api.EnvSources) != 2 {
+		t.Errorf("Unexpected api deployment: %+v", api)
+	}
+	if svc := byKey["Service/api"]; len(svc.Ports) != 1 || svc.Ports[0] != "80->8080" {
+		t.Errorf("Unexpected api service: %+v", svc)
+	}
+
+	web, ok := byKey["Deployment/web"]
+	if !ok {
+		t.Fatalf("Expected templated web deployment, got %+v", resources)
+	}
+	if web.Chart != "web" || web.Replicas != "1" || len(web.Images) != 1 || web.Images[0] != "acme/web:2.0" {
+		t.Errorf("Unexpected web deployment: %+v", web)
+	}
+	if ing := byKey["Ingress/web"]; len(ing.Backends) != 1 || ing.Backends[0] != "/ -> web:80" {
+		t.Errorf("Unexpected ingress: %+v", ing)
+	}
+}
+
+func TestCheckK8sResources(t *testing.T) {
+	resources := []K8sResource{
+		{
+			Kind:   "Deployment",
+			Name:   "agent",
+			Source: "deploy/agent.yaml",
+			podSpec: map[string]any{
+				"volumes": []any{
+					map[string]any{"name": "docker", "hostPath": map[string]any{"path": "/var/run/docker.sock"}},
+				},
+				"containers": []any{
+					map[string]any{
+						"name":            "agent",
+						"securityContext": map[string]any{"privileged": true},
+					},
+					map[string]any{
+						"name":           "sidecar",
+						"resources":      map[string]any{"limits": map[string]any{"memory": "64Mi"}},
+						"readinessProbe": map[string]any{"httpGet": map[string]any{"path": "/ready"}},
+					},
+				},
+			},
+		},
+		{
+			Kind: "CronJob",
+			Name: "backup",
+			podSpec: map[string]any{
+				"containers": []any{
+					map[string]any{"name": "backup", "resources": map[string]any{"__templated_1__": "toYaml .Values.resources"}},
+				},
+			},
+		},
+	}
+
+	findings := checkK8sResources(resources)
+
+	counts := map[string]int{}
+	for _, finding := range findings {
+		counts[finding.Rule]++
+	}
+
+	expected := map[string]int{
+		"host-path-mount":         1,
+		"privileged-container":    1,
+		"missing-resource-limits": 1,
+		"missing-probes":          1,
+	}
+	for rule, count := range expected {
+		if counts[rule] != count {
+			t.Errorf("Expected %d %s findings, got %d (%+v)", count, rule, counts[rule], findings)
+		}
+	}
+	if len(findings) != 4 {
+		t.Errorf("Expected 4 findings, got %+v", findings)
+	}
+}
@@ -0,0 +1,324 @@
+package detect
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// frameworkPatterns are source indicators per language. They are only
+// matched against files of that language.
+var frameworkPatterns = map[string]map[string][]string{
+	"go": {
+		"gin":         {"github.com/gin-gonic/gin", "gin.New()", "gin.Default()"},
+		"echo":        {"github.com/labstack/echo", "echo.New()"},
+		"fiber":       {"github.com/gofiber/fiber", "fiber.New()"},
+		"chi":         {"github.com/go-chi/chi", "chi.NewRouter()"},
+		"gorilla/mux": {"github.com/gorilla/mux", "mux.NewRouter()"},
+		"beego":       {"github.com/astaxie/beego", "beego.Run()"},
+	},
+	"python": {
+		"flask":   {"from flask import", "Flask(__name__)"},
+		"django":  {"from django", "django.contrib"},
+		"fastapi": {"from fastapi import", "FastAPI()"},
+		"tornado": {"import tornado", "tornado.web"},
+		"pyramid": {"from pyramid", "pyramid.config"},
+	},
+	"javascript": {
+		"express": {"require('express')", "require(\"express\")", "from 'express'"},
+		"koa":     {"require('koa')", "from 'koa'"},
+		"hapi":    {"require('@hapi/hapi')", "from '@hapi/hapi'"},
+		"fastify": {"require('fastify')", "from 'fastify'"},
+	},
+	"typescript": {
+		"express": {"from 'express'", "import express"},
+		"nest":    {"@nestjs/", "from '@nestjs"},
+		"next":    {"from 'next'", "import next"},
+	},
+	"java": {
+		"spring-boot": {"import org.springframework.boot", "@SpringBootApplication"},
+		"micronaut":   {"import io.micronaut"},
+		"quarkus":     {"import io.quarkus"},
+	},
+	"kotlin": {
+		"spring-boot": {"import org.springframework.boot", "@SpringBootApplication"},
+		"micronaut":   {"import io.micronaut"},
+		"quarkus":     {"import io.quarkus"},
+	},
+	"rust": {
+		"axum":      {"use axum"},
+		"actix-web": {"use actix_web"},
+		"rocket":    {"use rocket", "#[macro_use] extern crate rocket"},
+		"warp":      {"use warp"},
+	},
+	"ruby": {
+		"rails":   {"require \"rails/all\"", "require 'rails/all'", "Rails.application"},
+		"sinatra": {"require \"sinatra\"", "require 'sinatra'", "Sinatra::Base"},
+		"hanami":  {"require \"hanami\"", "require 'hanami'", "Hanami::"},
+	},
+	"php": {
+		"laravel": {"use Illuminate\\", "Illuminate\\Support\\Facades\\Route"},
+		"symfony": {"use Symfony\\Component\\", "use Symfony\\Bundle\\"},
+	},
+	"csharp": {
+		"aspnetcore":       {"using Microsoft.AspNetCore", "WebApplication.CreateBuilder"},
+		"entity-framework": {"using Microsoft.EntityFrameworkCore"},
+	},
+}
+
+// manifestFrameworks maps declared dependency names to frameworks. Go module
+// paths also match their major-version suffixes (github.com/labstack/echo/v4).
+var manifestFrameworks = map[string]map[string][]string{
+	"go": {
+		"gin":         {"github.com/gin-gonic/gin"},
+		"echo":        {"github.com/labstack/echo"},
+		"fiber":       {"github.com/gofiber/fiber"},
+		"chi":         {"github.com/go-chi/chi"},
+		"gorilla/mux": {"github.com/gorilla/mux"},
+		"beego":       {"github.com/astaxie/beego", "github.com/beego/beego"},
+	},
+	"python": {
+		"flask":   {"flask"},
+		"django":  {"django"},
+		"fastapi": {"fastapi"},
+		"tornado": {"tornado"},
+		"pyramid": {"pyramid"},
+	},
+	"node": {
+		"express": {"express"},
+		"koa":     {"koa"},
+		"hapi":    {"@hapi/hapi"},
+		"fastify": {"fastify"},
+		"nest":    {"@nestjs/core"},
+		"next":    {"next"},
+	},
+	"jvm": {
+		"spring-boot": {"org.springframework.boot"},
+		"micronaut":   {"io.micronaut"},
+		"quarkus":     {"io.quarkus"},
+	},
+	"rust": {
+		"axum":      {"axum"},
+		"actix-web": {"actix-web"},
+		"rocket":    {"rocket"},
+		"warp":      {"warp"},
+	},
+	"ruby": {
+		"rails":   {"rails", "railties"},
+		"sinatra": {"sinatra"},
+		"hanami":  {"hanami"},
+	},
+	"php": {
+		"laravel": {"laravel/framework"},
+		"symfony": {"symfony/framework-bundle", "symfony/http-kernel"},
+	},
+	"dotnet": {
+		"aspnetcore":       {"Microsoft.NET.Sdk.Web", "Microsoft.AspNetCore"},
+		"entity-framework": {"Microsoft.EntityFrameworkCore"},
+	},
+}
+
+var (
+	requirementName = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._\-]*)`)
+	pyprojectDep    = regexp.MustCompile(`^\s*"([A-Za-z0-9][A-Za-z0-9._\-]*)`)
+	poetryDep       = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._\-]*)\s*=`)
+)
+
+// manifestDetection holds frameworks declared in dependency manifests and
+// the languages those manifests cover. For a covered language, source
+// indicators only confirm frameworks the manifests declare.
+type manifestDetection struct {
+	frameworks []Framework
+	declared   map[string]map[string]bool
+}
+
+func (m manifestDetection) allows(language, name string) bool {
+	declared, covered := m.declared[language]
+	return !covered || declared[name]
+}
+
+func detectManifestFrameworks(ctx context.Context, repoPath string) manifestDetection {
+	detection := manifestDetection{
+		frameworks: []Framework{},
+		declared:   make(map[string]map[string]bool),
+	}
+	if repoPath == "" {
+		return detection
+	}
+
+	add := func(languages []string, ecosystem string, names []string, rel string) {
+		for _, language := range languages {
+			if detection.declared[language] == nil {
+				detection.declared[language] = make(map[string]bool)
+			}
+		}
+		for _, framework := range sortedMapKeys(manifestFrameworks[ecosystem]) {
+			if !declaresAny(names, manifestFrameworks[ecosystem][framework], ecosystem == "go") {
+				continue
+			}
+			for _, language := range languages {
+				detection.declared[language][framework] = true
+			}
+			detection.frameworks = append(detection.frameworks, Framework{
+				Name:       framework,
+				Language:   languages[0],
+				Files:      []string{rel},
+				Confidence: ConfidenceHigh,
+			})
+		}
+	}
+
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		base := path.Base(rel)
+		switch {
+		case base == "go.mod":
+			add([]string{"go"}, "go", goModRequires(p), rel)
+
+		case base == "package.json":
+			names, typescript := packageJSONDependencies(p)
+			languages := []string{"javascript", "typescript"}
+			if typescript {
+				languages = []string{"typescript", "javascript"}
+			}
+			add(languages, "node", names, rel)
+
+		case strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+			add([]string{"python"}, "python", requirementNames(p), rel)
+
+		case base == "pyproject.toml":
+			add([]string{"python"}, "python", pyprojectDependencies(p), rel)
+
+		case isJVMBuildFile(base):
+			add([]string{"java", "kotlin"}, "jvm", jvmDependencies(p), rel)
+
+		case base == "Gemfile":
+			add([]string{"ruby"}, "ruby", gemfileGems(p), rel)
+
+		case base == "Cargo.toml":
+			add([]string{"rust"}, "rust", cargoDependencies(p), rel)
+
+		case base == "composer.json":
+			add([]string{"php"}, "php", composerPackages(p), rel)
+
+		case isProjectFile(base):
+			add([]string{"csharp"}, "dotnet", nugetDependencies(p), rel)
+		}
+	})
+
+	return detection
+}
+
+func declaresAny(names, packages []string, modulePrefix bool) bool {
+	for _, name := range names {
+		for _, pkg := range packages {
+			if name == pkg || (modulePrefix && strings.HasPrefix(name, pkg+"/")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func goModRequires(p string) []string {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil
+	}
+	file, err := modfile.ParseLax(p, data, nil)
+	if err != nil {
+		return nil
+	}
+
+	modules := []string{}
+	for _, req := range file.Require {
+		modules = append(modules, req.Mod.Path)
+	}
+	return modules
+}
+
+// packageJSONDependencies returns the declared package names and whether
+// the package uses TypeScript.
+func packageJSONDependencies(p string) ([]string, bool) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, false
+	}
+
+	names := []string{}
+	for _, field := range []string{"dependencies", "devDependencies", "peerDependencies"} {
+		var deps map[string]string
+		if err := json.Unmarshal(manifest[field], &deps); err != nil {
+			continue
+		}
+		names = append(names, sortedMapKeys(deps)...)
+	}
+	return names, containsString(names, "typescript")
+}
+
+func requirementNames(p string) []string {
+	file, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	names := []string{}
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		if match := requirementName.FindStringSubmatch(strings.TrimSpace(lines.Text())); match != nil {
+			names = append(names, strings.ToLower(match[1]))
+		}
+	}
+	return names
+}
+
+// pyprojectDependencies reads PEP 621 dependency arrays and Poetry
+// dependency tables.
+func pyprojectDependencies(p string) []string {
+	file, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	names := []string{}
+	inArray, inTable := false, false
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		switch {
+		case strings.HasPrefix(line, "["):
+			inArray = false
+			inTable = strings.HasPrefix(line, "[tool.poetry") && strings.Contains(line, "dependencies]")
+		case strings.HasPrefix(line, "dependencies") && strings.Contains(line, "["):
+			inArray = !strings.Contains(line, "]")
+			for _, dep := range strings.Split(line[strings.Index(line, "[")+1:], ",") {
+				if match := pyprojectDep.FindStringSubmatch(dep); match != nil {
+					names = append(names, strings.ToLower(match[1]))
+				}
+			}
+		case inArray:
+			if strings.HasPrefix(line, "]") {
+				inArray = false
+			} else if match := pyprojectDep.FindStringSubmatch(line); match != nil {
+				names = append(names, strings.ToLower(match[1]))
+			}
+		case inTable:
+			if match := poetryDep.FindStringSubmatch(line); match != nil {
+				names = append(names, strings.ToLower(match[1]))
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,95 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestFrameworksPreferManifests(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":             "module example.com/app\n\nrequire (\n\tgithub.com/labstack/echo/v4 v4.11.0\n)\n",
+		"main.go":            "package main\n\n// Unlike github.com/gin-gonic/gin, echo ...\nfunc main() { e := echo.New() }\n",
+		"README.md":          "Example: from flask import Flask\n",
+		"web/package.json":   `{"dependencies": {"express": "^4.18.0"}, "devDependencies": {"typescript": "^5.0.0"}}`,
+		"api/pyproject.toml": "[project]\nname = \"api\"\ndependencies = [\n  \"FastAPI>=0.100\",\n  \"uvicorn\",\n]\n",
+	}
+	scanned := []scanner.FileInfo{}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		language := map[string]string{".go": "go", ".md": "markdown"}[filepath.Ext(name)]
+		if language != "" {
+			scanned = append(scanned, scanner.FileInfo{Path: path, RelativePath: name, Language: language})
+		}
+	}
+
+	result, err := Detect(context.Background(), Options{Files: scanned, RepoPath: tempDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, fw := range result.Frameworks {
+		got = append(got, fw.Language+"/"+fw.Name)
+		if fw.Name == "echo" && len(fw.Files) != 2 {
+			t.Errorf("Expected echo in go.mod and main.go, got %v", fw.Files)
+		}
+		if fw.Confidence != ConfidenceHigh {
+			t.Errorf("Expected manifest-backed %s to have high confidence, got %v", fw.Name, fw.Confidence)
+		}
+	}
+	sort.Strings(got)
+
+	expected := []string{"go/echo", "python/fastapi", "typescript/express"}
+	if len(got) != len(expected) {
+		t.Fatalf("Frameworks = %v, want %v", got, expected)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Frameworks = %v, want %v", got, expected)
+			break
+		}
+	}
+}
+
+func TestFrameworkConfidence(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected Confidence
+	}{
+		{"import.go", "package main\n\nimport \"github.com/go-chi/chi\"\n", ConfidenceMedium},
+		{"mention.go", "package main\n\n// r := gin.Default() would also work\n", ConfidenceLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tt.name)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			result := &Result{}
+			file := scanner.FileInfo{Path: path, RelativePath: tt.name, Language: "go"}
+			detectFrameworks(file, detectManifestFrameworks(context.Background(), ""), result)
+
+			if len(result.Frameworks) != 1 || result.Frameworks[0].Confidence != tt.expected {
+				t.Errorf("Expected one framework with confidence %v, got %+v", tt.expected, result.Frameworks)
+			}
+		})
+	}
+}
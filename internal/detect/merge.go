@@ -0,0 +1,169 @@
+package detect
+
+import (
+	"path"
+	"sort"
+)
+
+// Merge combines detection results for several repositories analyzed as one
+// system. Every file path is prefixed with the matching entry of roots so
+// each endpoint, model and finding stays attributed to its repository.
+func Merge(roots []string, results []*Result) *Result {
+	merged := &Result{
+		Entrypoints: []Entrypoint{},
+		Frameworks:  []Framework{},
+		Endpoints:   []Endpoint{},
+		Models:      []Model{},
+		BuildTools:  []BuildTool{},
+		Tables:      []Table{},
+		Artifacts:   []Artifact{},
+		Findings:    []Finding{},
+		HelmCharts:  []HelmChart{},
+		K8s:         []K8sResource{},
+		Pipelines:   []Pipeline{},
+		Decisions:   []Decision{},
+		Docs:        []Doc{},
+		DocGaps:     []DocGap{},
+		ConfigFiles: []ConfigFile{},
+		CLICommands: []CLICommand{},
+		Secrets:     []Secret{},
+		Tags:        []Tag{},
+		Todos:       []Todo{},
+	}
+
+	for i, result := range results {
+		root := roots[i]
+		in := func(file string) string {
+			if file == "" {
+				return ""
+			}
+			return path.Join(root, file)
+		}
+
+		for _, entrypoint := range result.Entrypoints {
+			entrypoint.Path = in(entrypoint.Path)
+			merged.Entrypoints = append(merged.Entrypoints, entrypoint)
+		}
+		for _, framework := range result.Frameworks {
+			files := make([]string, len(framework.Files))
+			for j, file := range framework.Files {
+				files[j] = in(file)
+			}
+			framework.Files = files
+			merged.Frameworks = append(merged.Frameworks, framework)
+		}
+		for _, endpoint := range result.Endpoints {
+			endpoint.File = in(endpoint.File)
+			merged.Endpoints = append(merged.Endpoints, endpoint)
+		}
+		for _, model := range result.Models {
+			model.File = in(model.File)
+			merged.Models = append(merged.Models, model)
+		}
+		for _, tool := range result.BuildTools {
+			tool.File = in(tool.File)
+			merged.BuildTools = append(merged.BuildTools, tool)
+		}
+		for _, table := range result.Tables {
+			table.Source = in(table.Source)
+			merged.Tables = append(merged.Tables, table)
+		}
+		for _, artifact := range result.Artifacts {
+			artifact.Source = in(artifact.Source)
+			merged.Artifacts = append(merged.Artifacts, artifact)
+		}
+		for _, finding := range result.Findings {
+			finding.File = in(finding.File)
+			merged.Findings = append(merged.Findings, finding)
+		}
+		for _, chart := range result.HelmCharts {
+			chart.Path = in(chart.Path)
+			merged.HelmCharts = append(merged.HelmCharts, chart)
+		}
+		for _, resource := range result.K8s {
+			resource.Source = in(resource.Source)
+			merged.K8s = append(merged.K8s, resource)
+		}
+		for _, provider := range result.Infrastructure.Providers {
+			provider.File = in(provider.File)
+			merged.Infrastructure.Providers = append(merged.Infrastructure.Providers, provider)
+		}
+		for _, resource := range result.Infrastructure.Resources {
+			resource.File = in(resource.File)
+			merged.Infrastructure.Resources = append(merged.Infrastructure.Resources, resource)
+		}
+		for _, module := range result.Infrastructure.Modules {
+			module.File = in(module.File)
+			merged.Infrastructure.Modules = append(merged.Infrastructure.Modules, module)
+		}
+		for _, root := range result.Infrastructure.Roots {
+			root.Dir = in(root.Dir)
+			merged.Infrastructure.Roots = append(merged.Infrastructure.Roots, root)
+		}
+		for _, pipeline := range result.Pipelines {
+			pipeline.File = in(pipeline.File)
+			merged.Pipelines = append(merged.Pipelines, pipeline)
+		}
+		for _, decision := range result.Decisions {
+			decision.File = in(decision.File)
+			merged.Decisions = append(merged.Decisions, decision)
+		}
+		for _, doc := range result.Docs {
+			doc.File = in(doc.File)
+			merged.Docs = append(merged.Docs, doc)
+		}
+		for _, gap := range result.DocGaps {
+			gap.File = in(gap.File)
+			merged.DocGaps = append(merged.DocGaps, gap)
+		}
+		for _, config := range result.ConfigFiles {
+			config.Path = in(config.Path)
+			merged.ConfigFiles = append(merged.ConfigFiles, config)
+		}
+		for _, command := range result.CLICommands {
+			command.File = in(command.File)
+			merged.CLICommands = append(merged.CLICommands, command)
+		}
+		for _, secret := range result.Secrets {
+			secret.File = in(secret.File)
+			merged.Secrets = append(merged.Secrets, secret)
+		}
+		for _, todo := range result.Todos {
+			todo.File = in(todo.File)
+			merged.Todos = append(merged.Todos, todo)
+		}
+		for _, tag := range result.Tags {
+			if !hasTag(merged.Tags, tag.Name) {
+				merged.Tags = append(merged.Tags, tag)
+			}
+		}
+
+		for _, framework := range result.Testing.Frameworks {
+			framework.Source = in(framework.Source)
+			merged.Testing.Frameworks = append(merged.Testing.Frameworks, framework)
+		}
+		for _, module := range result.Testing.Modules {
+			module.Path = in(module.Path)
+			merged.Testing.Modules = append(merged.Testing.Modules, module)
+		}
+		for _, coverage := range result.Testing.Coverage {
+			coverage.File = in(coverage.File)
+			merged.Testing.Coverage = append(merged.Testing.Coverage, coverage)
+		}
+	}
+
+	SortFindings(merged.Findings)
+	sortTodos(merged.Todos)
+	sort.Slice(merged.Tags, func(i, j int) bool { return merged.Tags[i].Name < merged.Tags[j].Name })
+
+	return merged
+}
+
+func hasTag(tags []Tag, name string) bool {
+	for _, tag := range tags {
+		if tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
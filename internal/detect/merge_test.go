@@ -0,0 +1,37 @@
+package detect
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	api := &Result{
+		Frameworks: []Framework{{Name: "gin", Language: "go", Files: []string{"go.mod"}}},
+		Endpoints:  []Endpoint{{Method: "GET", Path: "/users", File: "main.go"}},
+		Findings:   []Finding{{Severity: SeverityLow, Rule: "a", File: "Dockerfile"}},
+		Testing:    TestInventory{Modules: []TestModule{{Path: ".", Files: 2}}},
+	}
+	infra := &Result{
+		Findings: []Finding{{Severity: SeverityHigh, Rule: "b", File: "k8s/deploy.yaml"}},
+		K8s:      []K8sResource{{Kind: "Deployment", Name: "api", Source: "k8s/deploy.yaml"}},
+	}
+
+	merged := Merge([]string{"api", "infra"}, []*Result{api, infra})
+
+	if got := merged.Frameworks[0].Files[0]; got != "api/go.mod" {
+		t.Errorf("framework file = %q, want api/go.mod", got)
+	}
+	if got := merged.Endpoints[0].File; got != "api/main.go" {
+		t.Errorf("endpoint file = %q, want api/main.go", got)
+	}
+	if got := merged.K8s[0].Source; got != "infra/k8s/deploy.yaml" {
+		t.Errorf("k8s source = %q, want infra/k8s/deploy.yaml", got)
+	}
+	if got := merged.Testing.Modules[0].Path; got != "api" {
+		t.Errorf("test module = %q, want api", got)
+	}
+	if len(merged.Findings) != 2 || merged.Findings[0].File != "infra/k8s/deploy.yaml" {
+		t.Errorf("Expected findings sorted by severity across roots, got %+v", merged.Findings)
+	}
+	if api.Endpoints[0].File != "main.go" {
+		t.Error("Merge should not modify its inputs")
+	}
+}
@@ -0,0 +1,273 @@
+package detect
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const maxSpecFileSize = 4 * 1024 * 1024
+
+var (
+	openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+	// pathParam matches {id}, :id and <id> style route parameters.
+	pathParam = regexp.MustCompile(`\{[^}]*\}|:[A-Za-z_]\w*|<[^>]*>`)
+)
+
+// detectOpenAPI reads OpenAPI 3 and Swagger 2 documents in the tree and
+// returns their operations as endpoints and component schemas as models.
+func detectOpenAPI(ctx context.Context, repoPath string) ([]Endpoint, []Model) {
+	endpoints := []Endpoint{}
+	models := []Model{}
+	if repoPath == "" {
+		return endpoints, models
+	}
+
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		ext := path.Ext(rel)
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			return
+		}
+		info, err := os.Stat(p)
+		if err != nil || info.Size() > maxSpecFileSize {
+			return
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return
+		}
+		head := content[:min(len(content), 2048)]
+		if !bytes.Contains(head, []byte("openapi")) && !bytes.Contains(head, []byte("swagger")) {
+			return
+		}
+
+		var spec map[string]any
+		if err := yaml.Unmarshal(content, &spec); err != nil {
+			return
+		}
+		if _, ok := spec["openapi"]; !ok {
+			if _, ok := spec["swagger"]; !ok {
+				return
+			}
+		}
+
+		endpoints = append(endpoints, specEndpoints(spec, rel)...)
+		models = append(models, specModels(spec, rel)...)
+	})
+
+	return endpoints, models
+}
+
+func specEndpoints(spec map[string]any, rel string) []Endpoint {
+	endpoints := []Endpoint{}
+	paths := yamlMap(spec["paths"])
+	base := specBasePath(spec)
+
+	for _, route := range sortedMapKeys(paths) {
+		item := yamlMap(paths[route])
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			summary := yamlString(op["summary"])
+			if summary == "" {
+				summary, _, _ = strings.Cut(strings.TrimSpace(yamlString(op["description"])), "\n")
+			}
+
+			full := base + route
+			if base != "" && route == "/" {
+				full = base
+			}
+			endpoints = append(endpoints, Endpoint{
+				Method:     strings.ToUpper(method),
+				Path:       full,
+				Handler:    yamlString(op["operationId"]),
+				File:       rel,
+				Summary:    summary,
+				Request:    requestSchema(op, item),
+				Response:   responseSchema(op),
+				Source:     "openapi",
+				Confidence: ConfidenceHigh,
+			})
+		}
+	}
+
+	return endpoints
+}
+
+// specBasePath returns the prefix of every path in a spec, so its operations
+// match the routes served: Swagger 2's basePath, or the path of the first
+// OpenAPI 3 server URL with its variables at their defaults.
+func specBasePath(spec map[string]any) string {
+	base := yamlString(spec["basePath"])
+	if servers := yamlList(spec["servers"]); len(servers) > 0 {
+		server := yamlMap(servers[0])
+		base = yamlString(server["url"])
+		for name, variable := range yamlMap(server["variables"]) {
+			base = strings.ReplaceAll(base, "{"+name+"}", yamlString(yamlMap(variable)["default"]))
+		}
+		if _, rest, ok := strings.Cut(base, "://"); ok {
+			base = ""
+			if i := strings.Index(rest, "/"); i >= 0 {
+				base = rest[i:]
+			}
+		}
+	}
+	base = strings.TrimSuffix(base, "/")
+	if base != "" && !strings.HasPrefix(base, "/") {
+		base = "/" + base
+	}
+	return base
+}
+
+func requestSchema(op, item map[string]any) string {
+	// OpenAPI 3: requestBody.content.<media>.schema
+	if body := yamlMap(op["requestBody"]); len(body) > 0 {
+		if ref := yamlString(body["$ref"]); ref != "" {
+			return refName(ref)
+		}
+		return contentSchema(yamlMap(body["content"]))
+	}
+
+	// Swagger 2: a parameter with in: body.
+	params := append(yamlList(item["parameters"]), yamlList(op["parameters"])...)
+	for _, p := range params {
+		param := yamlMap(p)
+		if yamlString(param["in"]) == "body" {
+			return schemaName(yamlMap(param["schema"]))
+		}
+	}
+	return ""
+}
+
+func responseSchema(op map[string]any) string {
+	responses := yamlMap(op["responses"])
+	for _, code := range []string{"200", "201", "202", "default"} {
+		response := yamlMap(responses[code])
+		if len(response) == 0 {
+			continue
+		}
+		if ref := yamlString(response["$ref"]); ref != "" {
+			return refName(ref)
+		}
+		if schema := yamlMap(response["schema"]); len(schema) > 0 {
+			return schemaName(schema)
+		}
+		if name := contentSchema(yamlMap(response["content"])); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func contentSchema(content map[string]any) string {
+	for _, media := range sortedMapKeys(content) {
+		if name := schemaName(yamlMap(yamlMap(content[media])["schema"])); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// schemaName gives a short description of a schema: the referenced
+// component name, "Name[]" for arrays, or the primitive type.
+func schemaName(schema map[string]any) string {
+	if ref := yamlString(schema["$ref"]); ref != "" {
+		return refName(ref)
+	}
+	switch yamlString(schema["type"]) {
+	case "array":
+		if item := schemaName(yamlMap(schema["items"])); item != "" {
+			return item + "[]"
+		}
+		return "array"
+	case "object", "":
+		properties := yamlMap(schema["properties"])
+		if len(properties) == 0 {
+			return yamlString(schema["type"])
+		}
+		return "{" + strings.Join(sortedMapKeys(properties), ", ") + "}"
+	default:
+		return yamlString(schema["type"])
+	}
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func specModels(spec map[string]any, rel string) []Model {
+	schemas := yamlMap(yamlMap(spec["components"])["schemas"])
+	if len(schemas) == 0 {
+		schemas = yamlMap(spec["definitions"])
+	}
+
+	models := []Model{}
+	for _, name := range sortedMapKeys(schemas) {
+		properties := yamlMap(yamlMap(schemas[name])["properties"])
+		models = append(models, Model{
+			Name:       name,
+			Fields:     sortedMapKeys(properties),
+			File:       rel,
+			Confidence: ConfidenceHigh,
+		})
+	}
+	return models
+}
+
+// mergeSpecEndpoints treats spec operations as authoritative. A code-detected
+// route matching a spec operation (ignoring parameter syntax) contributes its
+// file and handler; unmatched code routes are kept as they are.
+func mergeSpecEndpoints(spec, code []Endpoint) []Endpoint {
+	if len(spec) == 0 {
+		return code
+	}
+
+	byKey := make(map[string]int, len(spec))
+	merged := make([]Endpoint, 0, len(spec)+len(code))
+	for _, ep := range spec {
+		key := endpointKey(ep)
+		if _, ok := byKey[key]; ok {
+			continue
+		}
+		byKey[key] = len(merged)
+		merged = append(merged, ep)
+	}
+
+	for _, ep := range code {
+		idx, ok := byKey[endpointKey(ep)]
+		if !ok {
+			merged = append(merged, ep)
+			continue
+		}
+		merged[idx].File = ep.File
+		if ep.Handler != "" {
+			merged[idx].Handler = ep.Handler
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Path != merged[j].Path {
+			return merged[i].Path < merged[j].Path
+		}
+		return merged[i].Method < merged[j].Method
+	})
+	return merged
+}
+
+func endpointKey(ep Endpoint) string {
+	route := pathParam.ReplaceAllString(ep.Path, "{}")
+	if len(route) > 1 {
+		route = strings.TrimSuffix(route, "/")
+	}
+	return strings.ToUpper(ep.Method) + " " + route
+}
@@ -0,0 +1,157 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectOpenAPI(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"api/openapi.yaml": `openapi: 3.0.0
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      summary: Fetch a user
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+  /users:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/NewUser"
+      responses:
+        "201":
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/User"
+components:
+  schemas:
+    User:
+      properties:
+        id: {type: string}
+        name: {type: string}
+`,
+		"docs/swagger.json": `{"swagger": "2.0", "paths": {"/orders": {"put": {"parameters": [{"in": "body", "schema": {"$ref": "#/definitions/Order"}}], "responses": {"200": {"schema": {"type": "string"}}}}}}}`,
+		"config.yaml":       "name: app\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	endpoints, models := detectOpenAPI(context.Background(), tempDir)
+
+	expected := []Endpoint{
+		{Method: "POST", Path: "/users", File: "api/openapi.yaml", Request: "NewUser", Response: "User[]", Source: "openapi", Confidence: ConfidenceHigh},
+		{Method: "GET", Path: "/users/{id}", Handler: "getUser", File: "api/openapi.yaml", Summary: "Fetch a user", Response: "User", Source: "openapi", Confidence: ConfidenceHigh},
+		{Method: "PUT", Path: "/orders", File: "docs/swagger.json", Request: "Order", Response: "string", Source: "openapi", Confidence: ConfidenceHigh},
+	}
+	if len(endpoints) != len(expected) {
+		t.Fatalf("Expected %d endpoints, got %+v", len(expected), endpoints)
+	}
+	for i, want := range expected {
+		if endpoints[i] != want {
+			t.Errorf("Endpoint %d = %+v, want %+v", i, endpoints[i], want)
+		}
+	}
+
+	if len(models) != 1 || models[0].Name != "User" || len(models[0].Fields) != 2 {
+		t.Errorf("Expected the User component schema, got %+v", models)
+	}
+}
+
+func TestMergeSpecEndpoints(t *testing.T) {
+	spec := []Endpoint{
+		{Method: "GET", Path: "/users/{id}", Handler: "getUser", File: "openapi.yaml", Summary: "Fetch a user", Source: "openapi", Confidence: ConfidenceHigh},
+	}
+	code := []Endpoint{
+		{Method: "GET", Path: "/users/:id", Handler: "showUser", File: "routes.js"},
+		{Method: "GET", Path: "/health", File: "main.go"},
+	}
+
+	merged := mergeSpecEndpoints(spec, code)
+
+	if len(merged) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %+v", merged)
+	}
+	if merged[0].Path != "/health" {
+		t.Errorf("Expected code-only route to be kept, got %+v", merged[0])
+	}
+	user := merged[1]
+	if user.Path != "/users/{id}" || user.Summary != "Fetch a user" || user.File != "routes.js" || user.Handler != "showUser" {
+		t.Errorf("Expected spec endpoint enriched with code location, got %+v", user)
+	}
+}
+
+func TestSpecBasePath(t *testing.T) {
+	tests := []struct {
+		name string
+		spec map[string]any
+		want string
+	}{
+		{"none", map[string]any{"openapi": "3.0.0"}, ""},
+		{"swagger basePath", map[string]any{"swagger": "2.0", "basePath": "/api/v1/"}, "/api/v1"},
+		{"swagger root", map[string]any{"swagger": "2.0", "basePath": "/"}, ""},
+		{"server URL", map[string]any{"servers": []any{map[string]any{"url": "https://api.example.com/v2"}}}, "/v2"},
+		{"server host only", map[string]any{"servers": []any{map[string]any{"url": "https://api.example.com"}}}, ""},
+		{"relative server", map[string]any{"servers": []any{map[string]any{"url": "v3"}}}, "/v3"},
+		{"first server", map[string]any{"servers": []any{map[string]any{"url": "/v1"}, map[string]any{"url": "/v0"}}}, "/v1"},
+		{"server variables", map[string]any{"servers": []any{map[string]any{
+			"url":       "{scheme}://api.example.com/{version}",
+			"variables": map[string]any{"scheme": map[string]any{"default": "https"}, "version": map[string]any{"default": "v4"}},
+		}}}, "/v4"},
+	}
+	for _, tt := range tests {
+		if got := specBasePath(tt.spec); got != tt.want {
+			t.Errorf("%s: specBasePath() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMergeSpecEndpointsBasePath(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"swagger.json": `{"swagger": "2.0", "basePath": "/api/v1", "paths": {"/orders/{id}": {"get": {"operationId": "getOrder"}}}}`,
+		"openapi.yaml": "openapi: 3.0.0\nservers:\n  - url: https://example.com/v2\npaths:\n  /:\n    get:\n      summary: Index\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spec, _ := detectOpenAPI(context.Background(), tempDir)
+	code := []Endpoint{
+		{Method: "GET", Path: "/api/v1/orders/:id", Handler: "showOrder", File: "routes.js"},
+		{Method: "GET", Path: "/v2/", Handler: "index", File: "main.go"},
+	}
+
+	merged := mergeSpecEndpoints(spec, code)
+	if len(merged) != 2 {
+		t.Fatalf("Expected the code routes to match the spec operations, got %+v", merged)
+	}
+	if order := merged[0]; order.Path != "/api/v1/orders/{id}" || order.Handler != "showOrder" || order.Source != "openapi" {
+		t.Errorf("Expected the basePath operation enriched with its route, got %+v", order)
+	}
+	if index := merged[1]; index.Path != "/v2" || index.Handler != "index" || index.Summary != "Index" {
+		t.Errorf("Expected the server path operation enriched with its route, got %+v", index)
+	}
+}
@@ -0,0 +1,329 @@
+package detect
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// PerformanceHint is a code pattern with a known performance footgun, such
+// as a query issued once per loop iteration. Hints are heuristics: they
+// point at code worth profiling, not at proven problems.
+type PerformanceHint struct {
+	Rule    string
+	File    string
+	Line    int
+	Code    string
+	Message string
+}
+
+const (
+	RuleNPlusOne       = "n-plus-one-query"
+	RuleUnbounded      = "unbounded-concurrency"
+	RuleFileReadInLoop = "file-read-in-loop"
+	RuleBlockingCall   = "blocking-call-in-request-path"
+)
+
+// Patterns flagged inside loops and request handlers, per language family.
+var (
+	identifier    = regexp.MustCompile(`[A-Za-z_]\w*`)
+	stringLiteral = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|` + "`[^`]*`")
+	assignment    = regexp.MustCompile(`^\s*(?:(?:const|let|var)\s+)?([\w\s,]+?)\s*(?::=|=[^=])`)
+
+	goLoop         = regexp.MustCompile(`^\s*for\b`)
+	goRangeLoop    = regexp.MustCompile(`^\s*for\b.*\brange\b`)
+	goHandler      = regexp.MustCompile(`^\s*func\b.*(?:http\.ResponseWriter|\*gin\.Context|echo\.Context|\*fiber\.Ctx)`)
+	goQuery        = regexp.MustCompile(`\b(?:db|tx|conn|pool|DB|Tx)\.(?:Query|QueryRow|Exec|Get|Select|First|Find|Take|Raw)(?:Context|x)?\(`)
+	goSpawn        = regexp.MustCompile(`^\s*go\s+[\w.]+(?:\(|\s*func)`)
+	goBounded      = regexp.MustCompile(`SetLimit\(|semaphore|make\(chan struct\{\}\s*,`)
+	goReadFile     = regexp.MustCompile(`\b(?:os|ioutil)\.ReadFile\(([^)]*)\)`)
+	goBlockingCall = regexp.MustCompile(`\bhttp\.(?:Get|Post|Head|PostForm|DefaultClient\.\w+)\(|\btime\.Sleep\(`)
+
+	pyLoop       = regexp.MustCompile(`^\s*(?:async\s+)?(?:for\s+.+\s+in\s+.+|while\s+.+):\s*(?:#.*)?$`)
+	pyFunc       = regexp.MustCompile(`^\s*(async\s+)?def\s+\w+\s*\(`)
+	pyRoute      = regexp.MustCompile(`^\s*@\w+\.(?:route|get|post|put|patch|delete|api_route)\(`)
+	pyQuery      = regexp.MustCompile(`\.objects\.(?:get|filter|exclude|get_or_create)\(|\.query\.(?:get|filter|filter_by)\(|\b(?:cursor|cur|conn|connection|session|db)\.(?:execute|query|get)\(`)
+	pySpawn      = regexp.MustCompile(`\bthreading\.Thread\(|\basyncio\.(?:create_task|ensure_future)\(`)
+	pyBounded    = regexp.MustCompile(`Semaphore\(|ThreadPoolExecutor\(|max_workers`)
+	pyOpen       = regexp.MustCompile(`\bopen\(([^)]*)\)`)
+	pyRequest    = regexp.MustCompile(`\brequests\.(?:get|post|put|patch|delete|head|request)\(`)
+	pyAsyncBlock = regexp.MustCompile(`\brequests\.(?:get|post|put|patch|delete|head|request)\(|\burllib\.request\.urlopen\(|\btime\.sleep\(`)
+
+	jsLoop        = regexp.MustCompile(`^\s*(?:for|while)\s*\(|\.(?:forEach|map)\(\s*(?:async\b|\(?\s*\w+\s*\)?\s*=>|function\b)`)
+	jsHandler     = regexp.MustCompile(`\(\s*req\b[^)]*\bres\b|\(\s*request\b[^)]*\breply\b`)
+	jsQuery       = regexp.MustCompile(`\bawait\s+[\w.]+\.(?:findOne|findById|findByPk|findUnique|findFirst|findMany|findAll|find|query|count)\(`)
+	jsUnbounded   = regexp.MustCompile(`Promise\.(?:all|allSettled)\(\s*[\w.]+\.map\(`)
+	jsReadFile    = regexp.MustCompile(`\breadFile(?:Sync)?\(([^)]*)\)`)
+	jsSyncIOCall  = regexp.MustCompile(`\b(?:readFileSync|writeFileSync|readdirSync|statSync|existsSync|execSync|spawnSync)\(`)
+	jsLineComment = regexp.MustCompile(`^\s*//`)
+)
+
+// scope is an enclosing loop or function while walking a file.
+type scope struct {
+	indent  int // Python only
+	loop    bool
+	ranged  bool // Go range loop: one iteration per item
+	handler bool
+	async   bool
+	// varying are the identifiers that may change between iterations: those
+	// in the loop header and those assigned in its body.
+	varying map[string]bool
+}
+
+func detectPerformance(file scanner.FileInfo, result *Result) {
+	var walk func(rel, content string) []PerformanceHint
+	switch file.Language {
+	case "go":
+		walk = func(rel, content string) []PerformanceHint { return bracePerformance(rel, content, goRules) }
+	case "javascript", "typescript":
+		walk = func(rel, content string) []PerformanceHint { return bracePerformance(rel, content, jsRules) }
+	case "python":
+		walk = pythonPerformance
+	default:
+		return
+	}
+
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return
+	}
+	result.Performance = append(result.Performance, walk(file.RelativePath, string(content))...)
+}
+
+// braceRules adapt bracePerformance to a curly-brace language.
+type braceRules struct {
+	comment func(line string) bool
+	header  func(line string) scope
+	check   func(line string, loop, handler *scope, bounded bool) (rule, message string)
+	bounded *regexp.Regexp
+}
+
+var goRules = braceRules{
+	comment: func(line string) bool { return strings.HasPrefix(strings.TrimSpace(line), "//") },
+	header: func(line string) scope {
+		return scope{loop: goLoop.MatchString(line), ranged: goRangeLoop.MatchString(line), handler: goHandler.MatchString(line)}
+	},
+	check: func(line string, loop, handler *scope, bounded bool) (string, string) {
+		switch {
+		case loop != nil && goQuery.MatchString(line):
+			return RuleNPlusOne, "Database call inside a loop runs once per iteration; batch it or load the rows up front"
+		case loop != nil && loop.ranged && !bounded && goSpawn.MatchString(line):
+			return RuleUnbounded, "Starts a goroutine per item with no limit; use a worker pool or errgroup.SetLimit"
+		case loop != nil && readsInvariant(goReadFile, line, loop):
+			return RuleFileReadInLoop, "Reads the same whole file on every iteration; read it once before the loop"
+		case handler != nil && goBlockingCall.MatchString(line):
+			return RuleBlockingCall, "Blocking call on the request path (http.DefaultClient has no timeout); use a client with a timeout or move it off the request"
+		}
+		return "", ""
+	},
+	bounded: goBounded,
+}
+
+var jsRules = braceRules{
+	comment: jsLineComment.MatchString,
+	header: func(line string) scope {
+		return scope{loop: jsLoop.MatchString(line), handler: jsHandler.MatchString(line)}
+	},
+	check: func(line string, loop, handler *scope, _ bool) (string, string) {
+		switch {
+		case loop != nil && jsQuery.MatchString(line):
+			return RuleNPlusOne, "Awaits a query inside a loop, one round trip per iteration; batch it or load the rows up front"
+		case jsUnbounded.MatchString(line):
+			return RuleUnbounded, "Starts one promise per item at once; bound the concurrency (p-limit or batches) for large inputs"
+		case loop != nil && readsInvariant(jsReadFile, line, loop):
+			return RuleFileReadInLoop, "Reads the same whole file on every iteration; read it once before the loop"
+		case handler != nil && jsSyncIOCall.MatchString(line):
+			return RuleBlockingCall, "Synchronous call blocks the event loop on the request path; use the async API"
+		}
+		return "", ""
+	},
+}
+
+// bracePerformance walks a curly-brace source file, tracking the loops and
+// request handlers each line is in.
+func bracePerformance(rel, content string, rules braceRules) []PerformanceHint {
+	hints := []PerformanceHint{}
+	bounded := rules.bounded != nil && rules.bounded.MatchString(content)
+
+	stack := []scope{}
+	for i, line := range strings.Split(content, "\n") {
+		if rules.comment(line) {
+			continue
+		}
+
+		loop, handler := innermost(stack)
+		if rule, message := rules.check(line, loop, handler, bounded); rule != "" {
+			hints = append(hints, newHint(rule, rel, i+1, line, message))
+		}
+		if loop != nil {
+			for _, name := range assignedNames(line) {
+				loop.varying[name] = true
+			}
+		}
+
+		header := rules.header(line)
+		header.varying = identifiers(line)
+		first := true
+		for _, delta := range braceDeltas(line) {
+			if delta < 0 {
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+				continue
+			}
+			if first {
+				stack = append(stack, header)
+				first = false
+			} else {
+				stack = append(stack, scope{varying: map[string]bool{}})
+			}
+		}
+	}
+	return hints
+}
+
+// pythonPerformance walks a Python file, using indentation for scopes.
+func pythonPerformance(rel, content string) []PerformanceHint {
+	hints := []PerformanceHint{}
+	bounded := pyBounded.MatchString(content)
+
+	stack := []scope{}
+	route := false
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		loop, handler := innermost(stack)
+		async := false
+		for _, s := range stack {
+			async = async || s.async
+		}
+
+		rule, message := "", ""
+		switch {
+		case loop != nil && pyQuery.MatchString(line):
+			rule, message = RuleNPlusOne, "Query inside a loop runs once per iteration; batch it, or use select_related/prefetch_related or a join"
+		case loop != nil && !bounded && pySpawn.MatchString(line):
+			rule, message = RuleUnbounded, "Starts a thread or task per item with no limit; use a pool or a semaphore"
+		case loop != nil && readsInvariant(pyOpen, line, loop):
+			rule, message = RuleFileReadInLoop, "Opens the same file on every iteration; read it once before the loop"
+		case async && pyAsyncBlock.MatchString(line):
+			rule, message = RuleBlockingCall, "Blocking call inside async def stalls the event loop; use an async client or asyncio.sleep"
+		case handler != nil && pyRequest.MatchString(line) && !strings.Contains(line, "timeout="):
+			rule, message = RuleBlockingCall, "Outbound request on the request path without a timeout; pass timeout= or move it to a background job"
+		}
+		if rule != "" {
+			hints = append(hints, newHint(rule, rel, i+1, line, message))
+		}
+		if loop != nil {
+			for _, name := range assignedNames(line) {
+				loop.varying[name] = true
+			}
+		}
+
+		switch {
+		case pyRoute.MatchString(line):
+			route = true
+		case pyFunc.MatchString(line):
+			def := pyFunc.FindStringSubmatch(line)
+			stack = append(stack, scope{indent: indent, handler: route, async: def[1] != "", varying: identifiers(line)})
+			route = false
+		case pyLoop.MatchString(line):
+			stack = append(stack, scope{indent: indent, loop: true, varying: identifiers(line)})
+		case strings.HasSuffix(trimmed, ":"):
+			stack = append(stack, scope{indent: indent, varying: map[string]bool{}})
+		}
+	}
+	return hints
+}
+
+// innermost returns the innermost enclosing loop and handler, or nil.
+func innermost(stack []scope) (loop, handler *scope) {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].loop && loop == nil {
+			loop = &stack[i]
+		}
+		if stack[i].handler && handler == nil {
+			handler = &stack[i]
+		}
+	}
+	return loop, handler
+}
+
+// readsInvariant reports whether line reads a file whose path uses nothing
+// that varies between iterations of loop.
+func readsInvariant(pattern *regexp.Regexp, line string, loop *scope) bool {
+	match := pattern.FindStringSubmatch(line)
+	if match == nil {
+		return false
+	}
+	for name := range identifiers(stringLiteral.ReplaceAllString(match[1], "")) {
+		if loop.varying[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// assignedNames returns the variables a statement assigns.
+func assignedNames(line string) []string {
+	match := assignment.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+	return identifier.FindAllString(match[1], -1)
+}
+
+func identifiers(text string) map[string]bool {
+	names := map[string]bool{}
+	for _, name := range identifier.FindAllString(text, -1) {
+		names[name] = true
+	}
+	return names
+}
+
+// braceDeltas returns +1 for every opening and -1 for every closing brace
+// outside string literals and trailing comments, in order.
+func braceDeltas(line string) []int {
+	deltas := []int{}
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote != '`' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '/' && strings.HasPrefix(line[i:], "//"):
+			return deltas
+		case r == '{':
+			deltas = append(deltas, 1)
+		case r == '}':
+			deltas = append(deltas, -1)
+		}
+	}
+	return deltas
+}
+
+func newHint(rule, rel string, line int, code, message string) PerformanceHint {
+	code = strings.TrimSpace(code)
+	if len(code) > 80 {
+		code = code[:77] + "..."
+	}
+	return PerformanceHint{Rule: rule, File: rel, Line: line, Code: code, Message: message}
+}
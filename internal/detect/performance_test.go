@@ -0,0 +1,137 @@
+package detect
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPerformanceHints(t *testing.T) {
+	tests := []struct {
+		name    string
+		walk    func(rel, content string) []PerformanceHint
+		content string
+		want    []string
+	}{
+		{
+			name: "go",
+			walk: func(rel, content string) []PerformanceHint { return bracePerformance(rel, content, goRules) },
+			content: `package orders
+
+func (s *Store) Load(ids []int) error {
+	for _, id := range ids {
+		row := s.db.QueryRow("SELECT * FROM orders WHERE id = ?", id) // {
+		go s.notify(id)
+		rules, _ := os.ReadFile("rules.json")
+		path := filepath.Join("orders", strconv.Itoa(id))
+		data, _ := os.ReadFile(path)
+	}
+	for i := 0; i < workers; i++ {
+		go worker()
+	}
+	return nil
+}
+
+func handle(w http.ResponseWriter, r *http.Request) {
+	resp, err := http.Get("https://example.com/rates")
+	if err != nil {
+		return
+	}
+}
+
+func background() {
+	http.Get("https://example.com/ping")
+	// db.Query in a comment
+}
+`,
+			want: []string{
+				"5 n-plus-one-query",
+				"6 unbounded-concurrency",
+				"7 file-read-in-loop",
+				"18 blocking-call-in-request-path",
+			},
+		},
+		{
+			name: "go bounded",
+			walk: func(rel, content string) []PerformanceHint { return bracePerformance(rel, content, goRules) },
+			content: `package orders
+
+func run(items []string) {
+	g.SetLimit(8)
+	for _, item := range items {
+		go process(item)
+	}
+}
+`,
+			want: []string{},
+		},
+		{
+			name: "javascript",
+			walk: func(rel, content string) []PerformanceHint { return bracePerformance(rel, content, jsRules) },
+			content: `app.get("/orders", async (req, res) => {
+  const template = fs.readFileSync("order.html");
+  for (const id of req.query.ids) {
+    const order = await Order.findById(id);
+  }
+  await Promise.all(users.map((user) => notify(user)));
+  res.send(template);
+});
+
+function build(pages) {
+  pages.forEach((page) => {
+    const layout = readFileSync("layout.html");
+    render(page, readFileSync(page.path));
+  });
+}
+`,
+			want: []string{
+				"2 blocking-call-in-request-path",
+				"4 n-plus-one-query",
+				"6 unbounded-concurrency",
+				"12 file-read-in-loop",
+			},
+		},
+		{
+			name: "python",
+			walk: pythonPerformance,
+			content: `@app.route("/orders")
+def orders():
+    rates = requests.get("https://example.com/rates")
+    ok = requests.get("https://example.com/ok", timeout=5)
+    for order in Order.objects.all():
+        customer = Customer.objects.get(id=order.customer_id)
+        config = open("config.json").read()
+        with open(order.path) as f:
+            pass
+    return rates
+
+async def refresh(ids):
+    for i in ids:
+        asyncio.create_task(sync(i))
+    time.sleep(1)
+
+def report():
+    requests.get("https://example.com/report")
+`,
+			want: []string{
+				"3 blocking-call-in-request-path",
+				"6 n-plus-one-query",
+				"7 file-read-in-loop",
+				"14 unbounded-concurrency",
+				"15 blocking-call-in-request-path",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, hint := range tt.walk("file", tt.content) {
+				got = append(got, fmt.Sprintf("%d %s", hint.Line, hint.Rule))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hints = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
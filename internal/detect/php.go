@@ -0,0 +1,388 @@
+package detect
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	laravelPrefix     = `^Route::(?:\w+\([^()]*\)->)*`
+	laravelVerb       = regexp.MustCompile(laravelPrefix + `(get|post|put|patch|delete|options|any)\(\s*['"]([^'"]*)['"]\s*(.*)$`)
+	laravelMatch      = regexp.MustCompile(laravelPrefix + `match\(\s*\[([^\]]*)\]\s*,\s*['"]([^'"]*)['"]\s*(.*)$`)
+	laravelResource   = regexp.MustCompile(laravelPrefix + `(resource|apiResource)\(\s*['"]([^'"]+)['"]\s*,\s*\\?([\w\\]+)::class\s*\)(.*)$`)
+	laravelGroup      = regexp.MustCompile(`^Route::.*\bgroup\(`)
+	laravelGroupPath  = regexp.MustCompile(`(?:\bprefix\(\s*|['"]prefix['"]\s*=>\s*)['"]([^'"]*)['"]`)
+	laravelController = regexp.MustCompile(`(?:\bcontroller\(\s*|['"]controller['"]\s*=>\s*)\\?([\w\\]+)::class`)
+	laravelOnly       = regexp.MustCompile(`->(only|except)\(\s*\[([^\]]*)\]`)
+	laravelAction     = regexp.MustCompile(`^,\s*(?:\[\s*\\?([\w\\]+)::class\s*,\s*['"](\w+)['"]\s*\]|['"]([\w\\]+)@(\w+)['"]|\\?([\w\\]+)::class|['"](\w+)['"])`)
+	symfonyRoute      = regexp.MustCompile(`^(?:#\[|\*\s*@)Route\((.*)\)`)
+	symfonyPath       = regexp.MustCompile(`(?:path\s*[:=]\s*)?["']([^"']*)["']`)
+	symfonyMethods    = regexp.MustCompile(`methods\s*[:=]\s*[\[{]([^\]}]*)[\]}]`)
+	phpClass          = regexp.MustCompile(`^(?:(?:abstract|final|readonly)\s+)*class\s+(\w+)`)
+	phpFunction       = regexp.MustCompile(`^(?:(?:public|protected|private|static|final)\s+)*function\s+(\w+)`)
+	eloquentModel     = regexp.MustCompile(`(?m)^\s*(?:final\s+)?class\s+(\w+)\s+extends\s+\\?(?:[\w\\]+\\)?(?:Model|Authenticatable|Pivot)\b`)
+	eloquentFillable  = regexp.MustCompile(`(?s)\$fillable\s*=\s*\[(.*?)\]`)
+	eloquentTableName = regexp.MustCompile(`protected\s+\$table\s*=\s*['"](\w+)['"]`)
+	laravelTable      = regexp.MustCompile(`Schema::(?:create|table)\(\s*['"](\w+)['"]`)
+	laravelColumn     = regexp.MustCompile(`\$table->(\w+)\(\s*(?:['"](\w+)['"]|\\?([\w\\]+)::class)?`)
+)
+
+// laravelActions are the routes Route::resource registers, in Laravel's
+// order. apiResource leaves out create and edit.
+var laravelActions = []struct {
+	action, method, suffix string
+	member                 bool
+}{
+	{"index", "GET", "", false},
+	{"create", "GET", "/create", false},
+	{"store", "POST", "", false},
+	{"show", "GET", "", true},
+	{"edit", "GET", "/edit", true},
+	{"update", "PUT", "", true},
+	{"destroy", "DELETE", "", true},
+}
+
+// laravelColumnless are Blueprint methods that take a column name without
+// adding a column.
+var laravelColumnless = map[string]bool{
+	"index": true, "unique": true, "primary": true, "foreign": true, "fullText": true, "spatialIndex": true,
+	"dropColumn": true, "dropColumns": true, "renameColumn": true, "dropForeign": true, "dropIndex": true,
+	"dropUnique": true, "dropPrimary": true, "dropConstrainedForeignId": true,
+}
+
+// composerManifest is the part of a composer.json the detectors use.
+type composerManifest struct {
+	Require    map[string]string          `json:"require"`
+	RequireDev map[string]string          `json:"require-dev"`
+	Scripts    map[string]json.RawMessage `json:"scripts"`
+}
+
+func parseComposerManifest(p string) (composerManifest, bool) {
+	content, err := os.ReadFile(p)
+	if err != nil {
+		return composerManifest{}, false
+	}
+	var manifest composerManifest
+	if json.Unmarshal(content, &manifest) != nil {
+		return composerManifest{}, false
+	}
+	return manifest, true
+}
+
+// composerPackages returns the packages a composer.json requires,
+// including development ones.
+func composerPackages(p string) []string {
+	manifest, _ := parseComposerManifest(p)
+	return append(sortedMapKeys(manifest.Require), sortedMapKeys(manifest.RequireDev)...)
+}
+
+// detectComposerBuildTools reports each composer.json as a Composer build
+// with the install, database, server and test commands of its framework.
+// Custom scripts are listed too; pre- and post- event hooks are not.
+func detectComposerBuildTools(ctx context.Context, repoPath string) []BuildTool {
+	if repoPath == "" {
+		return nil
+	}
+
+	tools := []BuildTool{}
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		if path.Base(rel) != "composer.json" {
+			return
+		}
+		manifest, ok := parseComposerManifest(p)
+		if !ok {
+			return
+		}
+		_, laravel := manifest.Require["laravel/framework"]
+		_, symfony := manifest.Require["symfony/framework-bundle"]
+
+		scripts := []string{"composer install"}
+		switch {
+		case laravel:
+			scripts = append(scripts, "php artisan migrate", "php artisan serve")
+		case symfony:
+			scripts = append(scripts, "symfony server:start")
+		}
+		_, pest := manifest.RequireDev["pestphp/pest"]
+		_, phpunit := manifest.RequireDev["phpunit/phpunit"]
+		switch {
+		case manifest.Scripts["test"] != nil:
+			scripts = append(scripts, "composer test")
+		case laravel:
+			scripts = append(scripts, "php artisan test")
+		case pest:
+			scripts = append(scripts, "vendor/bin/pest")
+		case phpunit:
+			scripts = append(scripts, "vendor/bin/phpunit")
+		}
+		for _, name := range sortedMapKeys(manifest.Scripts) {
+			if name != "test" && !strings.HasPrefix(name, "pre-") && !strings.HasPrefix(name, "post-") {
+				scripts = append(scripts, "composer run-script "+name)
+			}
+		}
+		tools = append(tools, BuildTool{Type: "composer", File: rel, Scripts: scripts})
+	})
+	return tools
+}
+
+// extractPHPEndpoints reads Laravel route files (routes/*.php, where
+// api.php is served under /api) and Symfony #[Route] and @Route
+// controller attributes elsewhere.
+func extractPHPEndpoints(content, file string) []Endpoint {
+	if strings.HasPrefix(file, "routes/") || strings.Contains(file, "/routes/") {
+		prefix := ""
+		if path.Base(file) == "api.php" {
+			prefix = "api"
+		}
+		return extractLaravelRoutes(content, file, prefix)
+	}
+	return extractSymfonyRoutes(content, file)
+}
+
+// laravelScope is a Route::group block and the prefix and controller it
+// applies to the routes inside.
+type laravelScope struct {
+	prefix, controller string
+	depth              int
+}
+
+// extractLaravelRoutes finds Route::get and friends, Route::match,
+// Route::resource and Route::apiResource (with only/except), nested in
+// prefix and controller groups. Handlers are Controller@action; closures
+// have none.
+func extractLaravelRoutes(content, file, prefix string) []Endpoint {
+	endpoints := []Endpoint{}
+	scopes := []laravelScope{{prefix: prefix}}
+	depth := 0
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		scope := scopes[len(scopes)-1]
+		add := func(method, route, handler string) {
+			endpoints = append(endpoints, Endpoint{
+				Method:     method,
+				Path:       joinRoute(scope.prefix, route),
+				Handler:    handler,
+				File:       file,
+				Confidence: ConfidenceHigh,
+			})
+		}
+
+		switch {
+		case laravelGroup.MatchString(line):
+			next := laravelScope{prefix: scope.prefix, controller: scope.controller, depth: depth}
+			if match := laravelGroupPath.FindStringSubmatch(line); match != nil {
+				next.prefix = joinRoute(scope.prefix, match[1])
+			}
+			if match := laravelController.FindStringSubmatch(line); match != nil {
+				next.controller = phpBaseName(match[1])
+			}
+			scopes = append(scopes, next)
+
+		case laravelResource.MatchString(line):
+			match := laravelResource.FindStringSubmatch(line)
+			controller := phpBaseName(match[3])
+			only, except := []string{}, []string{}
+			for _, filter := range laravelOnly.FindAllStringSubmatch(match[4], -1) {
+				if filter[1] == "only" {
+					only = quotedValues(filter[2])
+				} else {
+					except = quotedValues(filter[2])
+				}
+			}
+
+			// "photos.comments" nests comments under a photo.
+			base := ""
+			names := strings.Split(match[2], ".")
+			for _, parent := range names[:len(names)-1] {
+				base += "/" + parent + "/{" + laravelParameter(parent) + "}"
+			}
+			name := names[len(names)-1]
+			for _, action := range laravelActions {
+				if match[1] == "apiResource" && (action.action == "create" || action.action == "edit") {
+					continue
+				}
+				if len(only) > 0 && !containsString(only, action.action) || containsString(except, action.action) {
+					continue
+				}
+				route := base + "/" + name
+				if action.member {
+					route += "/{" + laravelParameter(name) + "}"
+				}
+				add(action.method, route+action.suffix, controller+"@"+action.action)
+			}
+
+		case laravelVerb.MatchString(line):
+			match := laravelVerb.FindStringSubmatch(line)
+			add(strings.ToUpper(match[1]), match[2], laravelHandler(match[3], scope.controller))
+
+		case laravelMatch.MatchString(line):
+			match := laravelMatch.FindStringSubmatch(line)
+			handler := laravelHandler(match[3], scope.controller)
+			for _, verb := range quotedValues(match[1]) {
+				add(strings.ToUpper(verb), match[2], handler)
+			}
+		}
+
+		for _, delta := range braceDeltas(line) {
+			depth += delta
+		}
+		for len(scopes) > 1 && depth <= scopes[len(scopes)-1].depth {
+			scopes = scopes[:len(scopes)-1]
+		}
+	}
+	return endpoints
+}
+
+// laravelHandler names the action after a route's path argument:
+// [Controller::class, 'method'], 'Controller@method', an invokable
+// Controller::class or, inside a controller group, 'method'.
+func laravelHandler(rest, controller string) string {
+	match := laravelAction.FindStringSubmatch(rest)
+	switch {
+	case match == nil:
+		return ""
+	case match[1] != "":
+		return phpBaseName(match[1]) + "@" + match[2]
+	case match[3] != "":
+		return phpBaseName(match[3]) + "@" + match[4]
+	case match[5] != "":
+		return phpBaseName(match[5])
+	case controller != "":
+		return controller + "@" + match[6]
+	}
+	return ""
+}
+
+// laravelParameter is the route parameter Laravel names after a resource:
+// its singular, with dashes as underscores.
+func laravelParameter(resource string) string {
+	return strings.ReplaceAll(singularize(resource), "-", "_")
+}
+
+func phpBaseName(name string) string {
+	return name[strings.LastIndex(name, `\`)+1:]
+}
+
+// extractSymfonyRoutes finds #[Route] attributes and @Route annotations on
+// controller actions, joined with the class-level prefix. Routes without
+// methods match any method.
+func extractSymfonyRoutes(content, file string) []Endpoint {
+	endpoints := []Endpoint{}
+	class, prefix := "", ""
+	args, routed := "", false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if match := symfonyRoute.FindStringSubmatch(line); match != nil {
+			args, routed = match[1], true
+			continue
+		}
+		if match := phpClass.FindStringSubmatch(line); match != nil {
+			class, prefix = match[1], ""
+			if route := symfonyPath.FindStringSubmatch(args); routed && route != nil {
+				prefix = route[1]
+			}
+			routed = false
+			continue
+		}
+		match := phpFunction.FindStringSubmatch(line)
+		if match == nil || !routed {
+			continue
+		}
+
+		routed = false
+		route := symfonyPath.FindStringSubmatch(args)
+		if route == nil {
+			continue
+		}
+		methods := []string{"ANY"}
+		if verbs := symfonyMethods.FindStringSubmatch(args); verbs != nil {
+			methods = quotedValues(verbs[1])
+		}
+		for _, method := range methods {
+			endpoints = append(endpoints, Endpoint{
+				Method:     strings.ToUpper(method),
+				Path:       joinRoute(prefix, route[1]),
+				Handler:    class + "::" + match[1],
+				File:       file,
+				Confidence: ConfidenceHigh,
+			})
+		}
+	}
+	return endpoints
+}
+
+// extractEloquentModels finds Eloquent models with their $fillable
+// attributes. linkModelColumns adds the columns of their table from the
+// migrations.
+func extractEloquentModels(content, file string) []Model {
+	models := []Model{}
+	for _, match := range eloquentModel.FindAllStringSubmatch(content, -1) {
+		fields := []string{}
+		if fillable := eloquentFillable.FindStringSubmatch(content); fillable != nil {
+			fields = quotedValues(fillable[1])
+		}
+		models = append(models, Model{
+			Name:       match[1],
+			Fields:     fields,
+			File:       file,
+			Confidence: ConfidenceHigh,
+		})
+	}
+	return models
+}
+
+func isLaravelMigration(rel string) bool {
+	return strings.Contains(rel, "database/migrations/") && strings.HasSuffix(rel, ".php")
+}
+
+// parseLaravelMigration returns the columns each Schema::create or
+// Schema::table call in a migration adds. Shorthands expand to their
+// columns: id, timestamps, softDeletes, rememberToken, foreignIdFor and
+// morphs.
+func parseLaravelMigration(content string) map[string][]string {
+	tables := map[string][]string{}
+	table := ""
+	for _, line := range strings.Split(content, "\n") {
+		if match := laravelTable.FindStringSubmatch(line); match != nil {
+			table = match[1]
+			if _, ok := tables[table]; !ok {
+				tables[table] = []string{}
+			}
+			continue
+		}
+		if table == "" {
+			continue
+		}
+		for _, match := range laravelColumn.FindAllStringSubmatch(line, -1) {
+			method, column := match[1], match[2]
+			switch {
+			case method == "id" && column == "":
+				column = "id"
+			case method == "timestamps" || method == "timestampsTz" || method == "nullableTimestamps":
+				tables[table] = append(tables[table], "created_at", "updated_at")
+				continue
+			case method == "softDeletes" || method == "softDeletesTz":
+				column = firstNonEmpty(column, "deleted_at")
+			case method == "rememberToken":
+				column = "remember_token"
+			case method == "foreignIdFor" && match[3] != "":
+				column = underscore(phpBaseName(match[3])) + "_id"
+			case strings.HasSuffix(method, "morphs") || strings.HasSuffix(method, "Morphs"):
+				if column != "" {
+					tables[table] = append(tables[table], column+"_id", column+"_type")
+				}
+				continue
+			case laravelColumnless[method] || strings.HasPrefix(method, "drop"):
+				continue
+			}
+			if column != "" {
+				tables[table] = append(tables[table], column)
+			}
+		}
+	}
+	return tables
+}
@@ -0,0 +1,216 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestExtractPHPEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []string
+	}{
+		{
+			name: "laravel web",
+			file: "routes/web.php",
+			content: `<?php
+
+use App\Http\Controllers\PhotoController;
+use Illuminate\Support\Facades\Route;
+
+Route::get('/', function () {
+    return view('welcome');
+});
+Route::post('/login', 'Auth\LoginController@login');
+Route::middleware(['auth'])->get('/dashboard', [DashboardController::class, 'show'])->name('dashboard');
+Route::match(['get', 'post'], '/search', SearchController::class);
+Route::resource('photos', PhotoController::class)->only(['index', 'show']);
+
+Route::prefix('admin')->middleware('auth')->group(function () {
+    Route::controller(UserController::class)->group(function () {
+        Route::get('/users/{id}', 'show');
+    });
+    Route::delete('/cache', [CacheController::class, 'clear']);
+});
+Route::get('/about', AboutController::class);`,
+			want: []string{
+				"GET / ",
+				"POST /login LoginController@login",
+				"GET /dashboard DashboardController@show",
+				"GET /search SearchController",
+				"POST /search SearchController",
+				"GET /photos PhotoController@index",
+				"GET /photos/{photo} PhotoController@show",
+				"GET /admin/users/{id} UserController@show",
+				"DELETE /admin/cache CacheController@clear",
+				"GET /about AboutController",
+			},
+		},
+		{
+			name: "laravel api",
+			file: "routes/api.php",
+			content: `<?php
+Route::group(['prefix' => 'v1'], function () {
+    Route::apiResource('photos.comments', CommentController::class)->except(['destroy']);
+});`,
+			want: []string{
+				"GET /api/v1/photos/{photo}/comments CommentController@index",
+				"POST /api/v1/photos/{photo}/comments CommentController@store",
+				"GET /api/v1/photos/{photo}/comments/{comment} CommentController@show",
+				"PUT /api/v1/photos/{photo}/comments/{comment} CommentController@update",
+			},
+		},
+		{
+			name: "symfony",
+			file: "src/Controller/OrderController.php",
+			content: `<?php
+#[Route('/api/orders')]
+final class OrderController extends AbstractController
+{
+    #[Route('', name: 'order_list', methods: ['GET'])]
+    public function list(): JsonResponse {}
+
+    /**
+     * @Route("/{id}", methods={"GET","DELETE"})
+     */
+    public function item(int $id): JsonResponse {}
+
+    #[Route('/export')]
+    public function export(): Response {}
+
+    public function helper(): void {}
+}`,
+			want: []string{
+				"GET /api/orders OrderController::list",
+				"GET /api/orders/{id} OrderController::item",
+				"DELETE /api/orders/{id} OrderController::item",
+				"ANY /api/orders/export OrderController::export",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, endpoint := range extractPHPEndpoints(tt.content, tt.file) {
+				got = append(got, endpoint.Method+" "+endpoint.Path+" "+endpoint.Handler)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractPHPEndpoints() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLaravelMigration(t *testing.T) {
+	content := `<?php
+return new class extends Migration
+{
+    public function up(): void
+    {
+        Schema::create('posts', function (Blueprint $table) {
+            $table->id();
+            $table->foreignIdFor(User::class)->constrained();
+            $table->string('title', 200)->index();
+            $table->morphs('commentable');
+            $table->unique('title');
+            $table->timestamps();
+            $table->softDeletes();
+        });
+    }
+};`
+	want := map[string][]string{
+		"posts": {"id", "user_id", "title", "commentable_id", "commentable_type", "created_at", "updated_at", "deleted_at"},
+	}
+	if got := parseLaravelMigration(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLaravelMigration() = %v, want %v", got, want)
+	}
+}
+
+func TestDetectLaravel(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"composer.json": `{
+  "require": {"php": "^8.2", "laravel/framework": "^11.0", "stripe/stripe-php": "^13.0"},
+  "require-dev": {"phpunit/phpunit": "^11.0"},
+  "scripts": {"post-autoload-dump": ["@php artisan package:discover"], "dev": "npm run dev"}
+}`,
+		"routes/api.php": "<?php\n\nuse Illuminate\\Support\\Facades\\Route;\n\nRoute::get('/posts', [PostController::class, 'index']);\n",
+		"app/Models/Post.php": `<?php
+
+namespace App\Models;
+
+use Illuminate\Database\Eloquent\Model;
+
+class Post extends Model
+{
+    protected $fillable = [
+        'title',
+        'body',
+    ];
+}
+`,
+		"app/Models/Author.php": "<?php\n\nclass Author extends Model\n{\n    protected $table = 'writers';\n}\n",
+		"database/migrations/2024_01_01_000000_create_posts_table.php":   "<?php\nSchema::create('posts', function (Blueprint $table) {\n    $table->id();\n    $table->string('title');\n    $table->timestamps();\n});\n",
+		"database/migrations/2024_01_02_000000_create_writers_table.php": "<?php\nSchema::create('writers', function (Blueprint $table) {\n    $table->id();\n    $table->string('name');\n});\n",
+	}
+	scanned := []scanner.FileInfo{}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(name, ".php") {
+			scanned = append(scanned, scanner.FileInfo{Path: path, RelativePath: name, Language: "php"})
+		}
+	}
+	sort.Slice(scanned, func(i, j int) bool { return scanned[i].RelativePath < scanned[j].RelativePath })
+
+	result, err := Detect(context.Background(), Options{Files: scanned, RepoPath: tempDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.BuildTools) != 1 || !reflect.DeepEqual(result.BuildTools[0].Scripts, []string{
+		"composer install", "php artisan migrate", "php artisan serve", "php artisan test", "composer run-script dev",
+	}) {
+		t.Errorf("BuildTools = %+v", result.BuildTools)
+	}
+
+	if len(result.Frameworks) == 0 || result.Frameworks[0].Name != "laravel" || result.Frameworks[0].Confidence != ConfidenceHigh {
+		t.Errorf("Frameworks = %+v, want laravel", result.Frameworks)
+	}
+
+	if len(result.Endpoints) != 1 || result.Endpoints[0].Path != "/api/posts" || result.Endpoints[0].Handler != "PostController@index" {
+		t.Errorf("Endpoints = %+v, want GET /api/posts", result.Endpoints)
+	}
+
+	models := []string{}
+	for _, model := range result.Models {
+		models = append(models, model.Name+"("+strings.Join(model.Fields, ",")+")")
+	}
+	if got := strings.Join(models, " "); got != "Author(id,name) Post(title,body,id,created_at,updated_at)" {
+		t.Errorf("Models = %s", got)
+	}
+
+	tags := []string{}
+	for _, tag := range result.Tags {
+		tags = append(tags, tag.Name)
+	}
+	if !containsString(tags, "payments") {
+		t.Errorf("Tags = %v, want payments from stripe/stripe-php", tags)
+	}
+}
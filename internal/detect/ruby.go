@@ -0,0 +1,518 @@
+package detect
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	gemfileGem     = regexp.MustCompile(`^\s*gem\s+["']([\w.-]+)["']`)
+	rakeTask       = regexp.MustCompile(`^\s*task\s+:?["']?([\w:-]+?)["']?\s*(?:$|,|=>|:\s|do\b|\{)`)
+	rakeTaskHash   = regexp.MustCompile(`^\s*task\s+([\w-]+):`)
+	rakeNamespace  = regexp.MustCompile(`^\s*namespace\s+:?["']?([\w-]+)["']?\s+do\b`)
+	railsRoute     = regexp.MustCompile(`^(get|post|put|patch|delete|match|root|resources|resource|namespace|scope|member|collection)\b\s*\(?\s*(.*)$`)
+	routeTarget    = regexp.MustCompile(`^(?::(\w+)|["']([^"']*)["'])`)
+	routeArrow     = regexp.MustCompile(`=>\s*["']([\w/]+#\w+)["']`)
+	routeOption    = regexp.MustCompile(`(\w+):\s*((?:%[iw])?\[[^\]]*\]|["'][^"']*["']|:\w+)`)
+	symbol         = regexp.MustCompile(`:(\w+)`)
+	sinatraRoute   = regexp.MustCompile(`(?m)^\s*(get|post|put|patch|delete)\s*\(?\s*["'](/[^"']*)["']\s*\)?\s*(?:do|\{)`)
+	activeRecord   = regexp.MustCompile(`(?m)^\s*class\s+((?:\w+::)*\w+)\s*<\s*(?:ApplicationRecord|ActiveRecord::Base)\b`)
+	arTableName    = regexp.MustCompile(`self\.table_name\s*=\s*["'](\w+)["']`)
+	railsTable     = regexp.MustCompile(`^\s*create_table\s+\(?\s*[:"'](\w+)`)
+	railsColumn    = regexp.MustCompile(`^\s*t\.(\w+)\s+[:"'](\w+)`)
+	railsAddColumn = regexp.MustCompile(`^\s*add_(?:column|reference)\s+\(?\s*[:"'](\w+)["']?\s*,\s*[:"'](\w+)`)
+)
+
+// railsActions are the routes `resources` generates, in Rails' order. The
+// singular `resource` has no index and no :id segment.
+var railsActions = []struct {
+	action, method, suffix string
+	member                 bool
+}{
+	{"index", "GET", "", false},
+	{"create", "POST", "", false},
+	{"new", "GET", "/new", false},
+	{"edit", "GET", "/edit", true},
+	{"show", "GET", "", true},
+	{"update", "PATCH", "", true},
+	{"destroy", "DELETE", "", true},
+}
+
+// gemfileGems returns the gems a Gemfile declares.
+func gemfileGems(p string) []string {
+	file, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	gems := []string{}
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		if match := gemfileGem.FindStringSubmatch(lines.Text()); match != nil {
+			gems = append(gems, match[1])
+		}
+	}
+	return gems
+}
+
+func isRakeFile(base string) bool {
+	return base == "Rakefile" || strings.HasSuffix(base, ".rake")
+}
+
+// detectRubyBuildTools reports Bundler projects, with the Rails commands a
+// newcomer runs first, and the tasks defined in Rakefiles and lib/tasks.
+func detectRubyBuildTools(ctx context.Context, repoPath string) []BuildTool {
+	if repoPath == "" {
+		return nil
+	}
+
+	tools := []BuildTool{}
+	rake := BuildTool{Type: "rake", Scripts: []string{}}
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		base := path.Base(rel)
+		switch {
+		case base == "Gemfile":
+			gems := gemfileGems(p)
+			scripts := []string{"bundle install"}
+			if containsString(gems, "rails") || containsString(gems, "railties") {
+				scripts = append(scripts, "bin/rails db:setup", "bin/rails server")
+				if containsString(gems, "rspec-rails") {
+					scripts = append(scripts, "bundle exec rspec")
+				} else {
+					scripts = append(scripts, "bin/rails test")
+				}
+			} else if containsString(gems, "rspec") {
+				scripts = append(scripts, "bundle exec rspec")
+			}
+			tools = append(tools, BuildTool{Type: "bundler", File: rel, Scripts: scripts})
+
+		case isRakeFile(base):
+			if rake.File == "" || base == "Rakefile" && path.Base(rake.File) != "Rakefile" {
+				rake.File = rel
+			}
+			rake.Scripts = unionPreservingOrder(rake.Scripts, extractRakeTasks(p))
+		}
+	})
+	if rake.File != "" {
+		tools = append(tools, rake)
+	}
+	return tools
+}
+
+// extractRakeTasks returns the tasks defined in a Rakefile or .rake file,
+// qualified by their namespaces ("db:seed_demo").
+func extractRakeTasks(p string) []string {
+	file, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	type frame struct {
+		namespace string
+		indent    int
+	}
+	stack := []frame{}
+	tasks := []string{}
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := lines.Text()
+		indent := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		if strings.TrimSpace(line) == "end" && len(stack) > 0 && stack[len(stack)-1].indent == indent {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if match := rakeNamespace.FindStringSubmatch(line); match != nil {
+			stack = append(stack, frame{namespace: match[1], indent: indent})
+			continue
+		}
+
+		match := rakeTaskHash.FindStringSubmatch(line)
+		if match == nil {
+			match = rakeTask.FindStringSubmatch(line)
+		}
+		if match == nil || match[1] == "default" {
+			continue
+		}
+		name := match[1]
+		for i := len(stack) - 1; i >= 0; i-- {
+			name = stack[i].namespace + ":" + name
+		}
+		if !containsString(tasks, name) {
+			tasks = append(tasks, name)
+		}
+	}
+	return tasks
+}
+
+// routeScope is an open block of config/routes.rb.
+type routeScope struct {
+	path       string // prefix for routes declared inside the block
+	module     string // controller namespace ("admin/")
+	controller string // controller of the enclosing resource
+	// member and collection are the paths of the enclosing resource's
+	// member and collection blocks.
+	member     string
+	collection string
+	indent     int
+}
+
+// extractRailsRoutes expands the routing DSL of config/routes.rb: verb
+// routes, root, resources and resource (with only/except and nesting),
+// member and collection blocks, namespace and scope. Handlers are
+// "controller#action".
+func extractRailsRoutes(content, file string) []Endpoint {
+	endpoints := []Endpoint{}
+	add := func(method, routePath, handler string) {
+		if routePath == "" {
+			routePath = "/"
+		}
+		endpoints = append(endpoints, Endpoint{
+			Method:     method,
+			Path:       routePath,
+			Handler:    handler,
+			File:       file,
+			Confidence: ConfidenceHigh,
+		})
+	}
+
+	stack := []routeScope{{}}
+	for _, line := range strings.Split(content, "\n") {
+		indent := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		trimmed := strings.TrimSpace(line)
+		if i := strings.Index(trimmed, " #"); i >= 0 {
+			trimmed = strings.TrimSpace(trimmed[:i])
+		}
+		current := stack[len(stack)-1]
+
+		if trimmed == "end" {
+			if len(stack) > 1 && stack[len(stack)-1].indent == indent {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+		opensBlock := strings.HasSuffix(trimmed, " do") || strings.Contains(trimmed, " do |")
+		body := strings.TrimSuffix(trimmed, " do")
+
+		match := railsRoute.FindStringSubmatch(body)
+		if match == nil {
+			if opensBlock {
+				// constraints, concerns, defaults and similar blocks keep
+				// the enclosing scope.
+				current.indent = indent
+				stack = append(stack, current)
+			}
+			continue
+		}
+		keyword, args := match[1], strings.TrimSuffix(strings.TrimSpace(match[2]), ")")
+		options := routeOptions(args)
+		name := ""
+		if target := routeTarget.FindStringSubmatch(args); target != nil {
+			name = target[1] + target[2]
+		}
+
+		inner := current
+		inner.indent = indent
+		switch keyword {
+		case "root":
+			handler := options["to"]
+			if handler == "" && name != "" && strings.Contains(name, "#") {
+				handler = name
+			}
+			add("GET", current.path, current.module+handler)
+
+		case "get", "post", "put", "patch", "delete", "match":
+			if name == "" {
+				break
+			}
+			handler := firstNonEmpty(options["to"], routeArrowTarget(args))
+			if handler == "" && current.controller != "" && !strings.Contains(name, "/") {
+				handler = current.controller + "#" + strings.Trim(name, "/")
+			} else if handler != "" {
+				handler = current.module + handler
+			}
+			prefix := current.path
+			switch options["on"] {
+			case "member":
+				prefix = current.member
+			case "collection":
+				prefix = current.collection
+			}
+			routePath := joinRoute(prefix, firstNonEmpty(options["path"], name))
+			methods := []string{strings.ToUpper(keyword)}
+			if keyword == "match" {
+				methods = []string{}
+				for _, verb := range symbol.FindAllStringSubmatch(options["via"], -1) {
+					methods = append(methods, strings.ToUpper(verb[1]))
+				}
+			}
+			for _, method := range methods {
+				if method != "ALL" {
+					add(method, routePath, handler)
+				}
+			}
+
+		case "resources", "resource":
+			if name == "" {
+				break
+			}
+			singular := keyword == "resource"
+			controller := current.module + firstNonEmpty(options["controller"], name)
+			if singular {
+				controller = current.module + firstNonEmpty(options["controller"], pluralize(name))
+			}
+			base := joinRoute(current.path, firstNonEmpty(options["path"], name))
+			member := base + "/:id"
+			if singular {
+				member = base
+			}
+			for _, action := range railsActions {
+				if !routeIncludes(options, action.action) || singular && action.action == "index" {
+					continue
+				}
+				routePath := base + action.suffix
+				if action.member {
+					routePath = member + action.suffix
+				}
+				add(action.method, routePath, controller+"#"+action.action)
+			}
+			inner.controller = controller
+			inner.path = base + "/:" + singularize(name) + "_id"
+			if singular {
+				inner.path = base
+			}
+			inner.member, inner.collection = member, base
+
+		case "member":
+			inner.path = current.member
+
+		case "collection":
+			inner.path = current.collection
+
+		case "namespace":
+			if name == "" {
+				break
+			}
+			inner.path = joinRoute(current.path, firstNonEmpty(options["path"], name))
+			inner.module = current.module + name + "/"
+			inner.controller, inner.member, inner.collection = "", "", ""
+
+		case "scope":
+			inner.path = joinRoute(current.path, firstNonEmpty(options["path"], strings.Trim(name, "/")))
+			if module := options["module"]; module != "" {
+				inner.module = current.module + module + "/"
+			}
+		}
+
+		if opensBlock {
+			stack = append(stack, inner)
+		}
+	}
+
+	return endpoints
+}
+
+// routeOptions parses the keyword arguments of a route, unquoting strings
+// and dropping the colon of symbols.
+func routeOptions(args string) map[string]string {
+	options := map[string]string{}
+	for _, match := range routeOption.FindAllStringSubmatch(args, -1) {
+		options[match[1]] = strings.Trim(match[2], `"':`)
+	}
+	return options
+}
+
+func routeArrowTarget(args string) string {
+	if match := routeArrow.FindStringSubmatch(args); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// routeIncludes applies the only: and except: options of resources.
+func routeIncludes(options map[string]string, action string) bool {
+	if only, ok := options["only"]; ok {
+		return containsString(symbolNames(only), action)
+	}
+	return !containsString(symbolNames(options["except"]), action)
+}
+
+func symbolNames(list string) []string {
+	names := []string{}
+	for _, field := range strings.FieldsFunc(list, func(r rune) bool { return strings.ContainsRune(",[] ", r) }) {
+		if name := strings.Trim(field, `:"'`); name != "" && name != "%i" && name != "%w" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func extractSinatraEndpoints(content, file string) []Endpoint {
+	endpoints := []Endpoint{}
+	for _, match := range sinatraRoute.FindAllStringSubmatch(content, -1) {
+		endpoints = append(endpoints, Endpoint{
+			Method:     strings.ToUpper(match[1]),
+			Path:       match[2],
+			File:       file,
+			Confidence: ConfidenceMedium,
+		})
+	}
+	return endpoints
+}
+
+func extractRubyEndpoints(content, file string) []Endpoint {
+	if path.Base(file) == "routes.rb" || strings.Contains(file, "config/routes/") {
+		return extractRailsRoutes(content, file)
+	}
+	return extractSinatraEndpoints(content, file)
+}
+
+// extractActiveRecordModels finds ActiveRecord classes. Their columns live
+// in db/schema.rb and migrations; linkActiveRecordColumns fills them in.
+func extractActiveRecordModels(content, file string) []Model {
+	models := []Model{}
+	for _, match := range activeRecord.FindAllStringSubmatch(content, -1) {
+		name := match[1][strings.LastIndex(match[1], ":")+1:]
+		models = append(models, Model{
+			Name:       name,
+			Fields:     []string{},
+			File:       file,
+			Confidence: ConfidenceHigh,
+		})
+	}
+	return models
+}
+
+// parseRailsSchema returns the columns of the tables created or altered in
+// db/schema.rb or a migration. References add their _id column and
+// timestamps add created_at and updated_at.
+func parseRailsSchema(content string) map[string][]string {
+	tables := map[string][]string{}
+	table := ""
+	for _, line := range strings.Split(content, "\n") {
+		if match := railsTable.FindStringSubmatch(line); match != nil {
+			table = match[1]
+			if _, ok := tables[table]; !ok {
+				tables[table] = []string{}
+			}
+			continue
+		}
+		if match := railsAddColumn.FindStringSubmatch(line); match != nil {
+			column := match[2]
+			if strings.HasPrefix(strings.TrimSpace(line), "add_reference") {
+				column += "_id"
+			}
+			tables[match[1]] = append(tables[match[1]], column)
+			continue
+		}
+		if table == "" {
+			continue
+		}
+		if strings.TrimSpace(line) == "end" {
+			table = ""
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "t.timestamps") {
+			tables[table] = append(tables[table], "created_at", "updated_at")
+			continue
+		}
+		match := railsColumn.FindStringSubmatch(line)
+		if match == nil || match[1] == "index" || match[1] == "check_constraint" {
+			continue
+		}
+		column := match[2]
+		if match[1] == "references" || match[1] == "belongs_to" {
+			column += "_id"
+		}
+		tables[table] = append(tables[table], column)
+	}
+	return tables
+}
+
+func isRailsSchema(rel string) bool {
+	return path.Base(rel) == "schema.rb" || strings.Contains(rel, "db/migrate/") && strings.HasSuffix(rel, ".rb")
+}
+
+// linkModelColumns gives ActiveRecord and Eloquent models the columns of
+// their table: the pluralized, underscored class name unless the model
+// names its table (self.table_name, protected $table).
+func linkModelColumns(repoPath string, result *Result) {
+	orms := []struct {
+		kind, ext string
+		tableName *regexp.Regexp
+	}{
+		{"activerecord", ".rb", arTableName},
+		{"eloquent", ".php", eloquentTableName},
+	}
+	for _, orm := range orms {
+		columns := map[string][]string{}
+		for _, table := range result.Tables {
+			if table.Kind == orm.kind {
+				columns[table.Name] = table.Columns
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+
+		for i, model := range result.Models {
+			if !strings.HasSuffix(model.File, orm.ext) {
+				continue
+			}
+			table := pluralize(underscore(model.Name))
+			if content, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(model.File))); err == nil {
+				if match := orm.tableName.FindSubmatch(content); match != nil {
+					table = string(match[1])
+				}
+			}
+			result.Models[i].Fields = unionPreservingOrder(model.Fields, columns[table])
+		}
+	}
+}
+
+func underscore(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// pluralize and singularize cover the regular English forms Rails infers
+// table, controller and parameter names with.
+func pluralize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+func singularize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
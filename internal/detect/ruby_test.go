@@ -0,0 +1,166 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestExtractRailsRoutes(t *testing.T) {
+	content := `Rails.application.routes.draw do
+  root "pages#home"
+  get "/about", to: "pages#about"
+  get "legacy" => "pages#legacy"
+  match "webhooks/:provider", to: "webhooks#receive", via: [:get, :post]
+
+  resources :users, only: [:index, :show] do
+    resources :posts, except: %i[new edit destroy]
+    member do
+      post :lock
+    end
+    get :search, on: :collection
+  end
+
+  resource :profile, only: [:show, :update]
+
+  namespace :admin do
+    resources :reports, only: :index
+  end
+
+  scope "/api" do
+    constraints format: :json do
+      get "status", to: "health#show" # liveness
+    end
+  end
+end
+`
+	var got []string
+	for _, endpoint := range extractRailsRoutes(content, "config/routes.rb") {
+		got = append(got, endpoint.Method+" "+endpoint.Path+" "+endpoint.Handler)
+	}
+	want := []string{
+		"GET / pages#home",
+		"GET /about pages#about",
+		"GET /legacy pages#legacy",
+		"GET /webhooks/:provider webhooks#receive",
+		"POST /webhooks/:provider webhooks#receive",
+		"GET /users users#index",
+		"GET /users/:id users#show",
+		"GET /users/:user_id/posts posts#index",
+		"POST /users/:user_id/posts posts#create",
+		"GET /users/:user_id/posts/:id posts#show",
+		"PATCH /users/:user_id/posts/:id posts#update",
+		"POST /users/:id/lock users#lock",
+		"GET /users/search users#search",
+		"GET /profile profiles#show",
+		"PATCH /profile profiles#update",
+		"GET /admin/reports admin/reports#index",
+		"GET /api/status health#show",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractRailsRoutes() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDetectRails(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"Gemfile":  "source \"https://rubygems.org\"\n\ngem \"rails\", \"~> 7.1\"\ngem 'stripe'\n\ngroup :test do\n  gem \"rspec-rails\"\nend\n",
+		"Rakefile": "require_relative \"config/application\"\n\nRails.application.load_tasks\n\ntask default: :spec\n",
+		"lib/tasks/import.rake": `namespace :import do
+  desc "Import products"
+  task products: :environment do
+  end
+
+  namespace :legacy do
+    task :orders do
+    end
+  end
+end
+
+task "cleanup" do
+end
+`,
+		"config/routes.rb":         "Rails.application.routes.draw do\n  resources :orders, only: [:index]\nend\n",
+		"app/models/order.rb":      "class Order < ApplicationRecord\n  belongs_to :customer\nend\n",
+		"app/models/line_item.rb":  "class LineItem < ApplicationRecord\nend\n",
+		"app/models/legacy/sku.rb": "module Legacy\n  class Sku < ActiveRecord::Base\n    self.table_name = \"stock_units\"\n  end\nend\n",
+		"db/schema.rb": `ActiveRecord::Schema[7.1].define(version: 2024_01_01_000000) do
+  create_table "orders", force: :cascade do |t|
+    t.references "customer", null: false
+    t.decimal "total", precision: 10, scale: 2
+    t.timestamps
+    t.index ["customer_id"], name: "index_orders_on_customer_id"
+  end
+
+  create_table "line_items" do |t|
+    t.integer "quantity"
+  end
+
+  create_table "stock_units" do |t|
+    t.string "code"
+  end
+end
+`,
+		"db/migrate/20240102000000_add_note_to_orders.rb": "class AddNoteToOrders < ActiveRecord::Migration[7.1]\n  def change\n    add_column :orders, :note, :text\n  end\nend\n",
+	}
+	scanned := []scanner.FileInfo{}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(name, ".rb") {
+			scanned = append(scanned, scanner.FileInfo{Path: path, RelativePath: name, Language: "ruby"})
+		}
+	}
+	sort.Slice(scanned, func(i, j int) bool { return scanned[i].RelativePath < scanned[j].RelativePath })
+
+	result, err := Detect(context.Background(), Options{Files: scanned, RepoPath: tempDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Frameworks) != 1 || result.Frameworks[0].Name != "rails" || result.Frameworks[0].Confidence != ConfidenceHigh {
+		t.Errorf("Frameworks = %+v, want rails", result.Frameworks)
+	}
+	if len(result.Endpoints) != 1 || result.Endpoints[0].Handler != "orders#index" {
+		t.Errorf("Endpoints = %+v", result.Endpoints)
+	}
+
+	models := []string{}
+	for _, model := range result.Models {
+		models = append(models, model.Name+"("+strings.Join(model.Fields, ",")+")")
+	}
+	if got := strings.Join(models, " "); got != "LineItem(quantity) Order(note,customer_id,total,created_at,updated_at) Sku(code)" {
+		t.Errorf("Models = %s", got)
+	}
+
+	tools := []string{}
+	for _, tool := range result.BuildTools {
+		tools = append(tools, tool.Type+":"+tool.File+"["+strings.Join(tool.Scripts, ",")+"]")
+	}
+	want := "bundler:Gemfile[bundle install,bin/rails db:setup,bin/rails server,bundle exec rspec] " +
+		"rake:Rakefile[import:products,import:legacy:orders,cleanup]"
+	if got := strings.Join(tools, " "); got != want {
+		t.Errorf("BuildTools = %s\nwant %s", got, want)
+	}
+
+	tags := []string{}
+	for _, tag := range result.Tags {
+		tags = append(tags, tag.Name)
+	}
+	if !containsString(tags, "payments") {
+		t.Errorf("Tags = %v, want payments from the stripe gem", tags)
+	}
+}
@@ -0,0 +1,173 @@
+package detect
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// Rule is a user-supplied detection pattern. A file matches when its language
+// equals Language (or Language is empty) and it contains any of Indicators.
+// Capture, when set, is a regular expression whose named groups (method,
+// path, handler, name, fields) fill in the detected item.
+type Rule struct {
+	Language   string   `yaml:"language"`
+	Name       string   `yaml:"name"`
+	Indicators []string `yaml:"indicators"`
+	Capture    string   `yaml:"capture"`
+}
+
+type Rules struct {
+	Frameworks []Rule
+	Endpoints  []Rule
+	Models     []Rule
+}
+
+type compiledRule struct {
+	Rule
+	capture *regexp.Regexp
+}
+
+type compiledRules struct {
+	frameworks []compiledRule
+	endpoints  []compiledRule
+	models     []compiledRule
+}
+
+func (r Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if len(r.Indicators) == 0 && r.Capture == "" {
+		return fmt.Errorf("rule %q needs indicators or a capture pattern", r.Name)
+	}
+	if r.Capture != "" {
+		if _, err := regexp.Compile(r.Capture); err != nil {
+			return fmt.Errorf("rule %q has invalid capture pattern: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func compileRules(rules Rules) (compiledRules, error) {
+	var compiled compiledRules
+	var err error
+
+	if compiled.frameworks, err = compileRuleList(rules.Frameworks); err != nil {
+		return compiled, err
+	}
+	if compiled.endpoints, err = compileRuleList(rules.Endpoints); err != nil {
+		return compiled, err
+	}
+	if compiled.models, err = compileRuleList(rules.Models); err != nil {
+		return compiled, err
+	}
+
+	return compiled, nil
+}
+
+func compileRuleList(rules []Rule) ([]compiledRule, error) {
+	compiled := []compiledRule{}
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		c := compiledRule{Rule: rule}
+		if rule.Capture != "" {
+			c.capture = regexp.MustCompile(rule.Capture)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+func (r compiledRule) appliesTo(file scanner.FileInfo, content string) bool {
+	if r.Language != "" && !strings.EqualFold(r.Language, file.Language) {
+		return false
+	}
+	if len(r.Indicators) == 0 {
+		return true
+	}
+	for _, indicator := range r.Indicators {
+		if strings.Contains(content, indicator) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r compiledRule) matches(content string) []map[string]string {
+	if r.capture == nil {
+		return nil
+	}
+
+	names := r.capture.SubexpNames()
+	matches := []map[string]string{}
+	for _, submatch := range r.capture.FindAllStringSubmatch(content, -1) {
+		groups := make(map[string]string)
+		for i, name := range names {
+			if i > 0 && name != "" {
+				groups[name] = submatch[i]
+			}
+		}
+		matches = append(matches, groups)
+	}
+	return matches
+}
+
+func applyCustomRules(file scanner.FileInfo, content string, rules compiledRules, result *Result) {
+	for _, rule := range rules.frameworks {
+		if rule.appliesTo(file, content) && (rule.capture == nil || rule.capture.MatchString(content)) {
+			result.Frameworks = append(result.Frameworks, Framework{
+				Name:       rule.Name,
+				Language:   file.Language,
+				Files:      []string{file.RelativePath},
+				Confidence: ConfidenceMedium,
+			})
+		}
+	}
+
+	for _, rule := range rules.endpoints {
+		if !rule.appliesTo(file, content) {
+			continue
+		}
+		for _, groups := range rule.matches(content) {
+			method := strings.ToUpper(groups["method"])
+			if method == "" {
+				method = "ANY"
+			}
+			result.Endpoints = append(result.Endpoints, Endpoint{
+				Method:     method,
+				Path:       groups["path"],
+				Handler:    groups["handler"],
+				File:       file.RelativePath,
+				Confidence: ConfidenceMedium,
+			})
+		}
+	}
+
+	for _, rule := range rules.models {
+		if !rule.appliesTo(file, content) {
+			continue
+		}
+		for _, groups := range rule.matches(content) {
+			if groups["name"] == "" {
+				continue
+			}
+			fields := []string{}
+			for _, field := range strings.Split(groups["fields"], ",") {
+				if field = strings.TrimSpace(field); field != "" {
+					fields = append(fields, field)
+				}
+			}
+			result.Models = append(result.Models, Model{
+				Name:       groups["name"],
+				Fields:     fields,
+				File:       file.RelativePath,
+				Confidence: ConfidenceMedium,
+			})
+		}
+	}
+}
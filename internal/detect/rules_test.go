@@ -0,0 +1,67 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestApplyCustomRules(t *testing.T) {
+	rules, err := compileRules(Rules{
+		Frameworks: []Rule{
+			{Language: "go", Name: "acme-rpc", Indicators: []string{"github.com/acme/rpc"}},
+		},
+		Endpoints: []Rule{
+			{
+				Language: "go",
+				Name:     "acme-rpc",
+				Capture:  `rpc\.Handle\("(?P<method>\w+)", "(?P<path>[^"]+)"`,
+			},
+		},
+		Models: []Rule{
+			{Name: "acme-model", Capture: `// acme:model (?P<name>\w+) (?P<fields>[\w,]+)`},
+		},
+	})
+	if err != nil {
+		t.Fatalf("compileRules failed: %v", err)
+	}
+
+	content := "import \"github.com/acme/rpc\"\n" +
+		"rpc.Handle(\"get\", \"/users\")\n" +
+		"rpc.Handle(\"POST\", \"/users\")\n" +
+		"// acme:model User id,name\n"
+
+	result := &Result{}
+	applyCustomRules(scanner.FileInfo{Language: "go", RelativePath: "main.go"}, content, rules, result)
+
+	if len(result.Frameworks) != 1 || result.Frameworks[0].Name != "acme-rpc" {
+		t.Errorf("Expected acme-rpc framework, got %+v", result.Frameworks)
+	}
+	if len(result.Endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(result.Endpoints))
+	}
+	if result.Endpoints[0].Method != "GET" || result.Endpoints[0].Path != "/users" {
+		t.Errorf("Unexpected endpoint %+v", result.Endpoints[0])
+	}
+	if len(result.Models) != 1 || len(result.Models[0].Fields) != 2 {
+		t.Errorf("Expected User model with 2 fields, got %+v", result.Models)
+	}
+
+	other := &Result{}
+	applyCustomRules(scanner.FileInfo{Language: "python", RelativePath: "app.py"}, content, rules, other)
+	if len(other.Frameworks) != 0 || len(other.Endpoints) != 0 {
+		t.Error("Language-scoped rules should not match other languages")
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	if err := (Rule{Name: "x"}).Validate(); err == nil {
+		t.Error("Expected error for rule without indicators or capture")
+	}
+	if err := (Rule{Name: "x", Capture: "("}).Validate(); err == nil {
+		t.Error("Expected error for invalid capture pattern")
+	}
+	if err := (Rule{Name: "x", Indicators: []string{"y"}}).Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+package detect
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	cargoKey        = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*=`)
+	cargoTable      = regexp.MustCompile(`^\[(?:workspace\.)?(?:dev-|build-)?dependencies\.([A-Za-z0-9_-]+)\]`)
+	rustAttrRoute   = regexp.MustCompile(`^#\[(get|post|put|delete|patch|head|options)\(\s*"([^"]*)"`)
+	rustFn          = regexp.MustCompile(`\bfn\s+(\w+)`)
+	rustRoute       = regexp.MustCompile(`\.route\(\s*"([^"]*)"\s*,`)
+	actixMethod     = regexp.MustCompile(`\b(get|post|put|delete|patch|head|options)\(\s*\)\s*\.to\(\s*([\w:]+)`)
+	axumMethod      = regexp.MustCompile(`\b(get|post|put|delete|patch|head|options)\(\s*([\w:]+)\s*\)`)
+	rustDerive      = regexp.MustCompile(`^#\[derive\(([^)]*)\)\]`)
+	rustStruct      = regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?struct\s+(\w+)(?:<[^>{]*>)?\s*\{`)
+	rustField       = regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(\w+)\s*:`)
+	rustSerdeRename = regexp.MustCompile(`^#\[serde\([^)]*\brename\s*=\s*"([^"]+)"`)
+	rustSerdeSkip   = regexp.MustCompile(`^#\[serde\([^)]*\bskip\s*[,)]`)
+)
+
+// cargoManifest is the part of a Cargo.toml the detectors use.
+type cargoManifest struct {
+	Name         string
+	Members      []string
+	Dependencies []string
+}
+
+// parseCargoManifest reads the package name, workspace members and
+// dependency names of a Cargo.toml. Dependencies include dev, build and
+// workspace dependencies, in inline and table form.
+func parseCargoManifest(p string) (cargoManifest, bool) {
+	file, err := os.Open(p)
+	if err != nil {
+		return cargoManifest{}, false
+	}
+	defer file.Close()
+
+	manifest := cargoManifest{}
+	section, inMembers := "", false
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if i := strings.Index(line, "#"); i >= 0 && !strings.Contains(line[:i], `"`) {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case inMembers:
+			manifest.Members = append(manifest.Members, quotedValues(line)...)
+			inMembers = !strings.Contains(line, "]")
+
+		case strings.HasPrefix(line, "["):
+			section = strings.Trim(line, "[]")
+			if match := cargoTable.FindStringSubmatch(line); match != nil {
+				manifest.Dependencies = append(manifest.Dependencies, match[1])
+			}
+
+		case section == "package" && strings.HasPrefix(line, "name"):
+			if values := quotedValues(line); len(values) > 0 {
+				manifest.Name = values[0]
+			}
+
+		case section == "workspace" && strings.HasPrefix(line, "members"):
+			_, list, _ := strings.Cut(line, "=")
+			manifest.Members = append(manifest.Members, quotedValues(list)...)
+			inMembers = strings.Contains(list, "[") && !strings.Contains(list, "]")
+
+		case strings.HasSuffix(section, "dependencies"):
+			if match := cargoKey.FindStringSubmatch(line); match != nil {
+				manifest.Dependencies = append(manifest.Dependencies, match[1])
+			}
+		}
+	}
+	return manifest, true
+}
+
+func quotedValues(text string) []string {
+	values := []string{}
+	for _, match := range quotedString.FindAllStringSubmatch(text, -1) {
+		values = append(values, match[1])
+	}
+	return values
+}
+
+func cargoDependencies(p string) []string {
+	manifest, _ := parseCargoManifest(p)
+	return manifest.Dependencies
+}
+
+// detectCargoBuildTools reports Cargo packages and workspaces. A
+// workspace lists its member crates as modules rather than as separate
+// builds.
+func detectCargoBuildTools(ctx context.Context, repoPath string) []BuildTool {
+	if repoPath == "" {
+		return nil
+	}
+
+	manifests := []string{}
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		if path.Base(rel) == "Cargo.toml" {
+			manifests = append(manifests, rel)
+		}
+	})
+	sort.Slice(manifests, func(i, j int) bool {
+		if di, dj := strings.Count(manifests[i], "/"), strings.Count(manifests[j], "/"); di != dj {
+			return di < dj
+		}
+		return manifests[i] < manifests[j]
+	})
+
+	tools := []BuildTool{}
+	members := map[string]bool{}
+	for _, rel := range manifests {
+		dir := path.Dir(rel)
+		if members[dir] {
+			continue
+		}
+
+		manifest, ok := parseCargoManifest(filepath.Join(repoPath, filepath.FromSlash(rel)))
+		if !ok {
+			continue
+		}
+		tool := BuildTool{
+			Type:    "cargo",
+			File:    rel,
+			Scripts: []string{"cargo build", "cargo test", "cargo run"},
+		}
+		if len(manifest.Members) > 0 {
+			tool.Modules = cargoMembers(filepath.Join(repoPath, filepath.FromSlash(dir)), manifest.Members)
+			for _, member := range tool.Modules {
+				members[path.Join(dir, member)] = true
+			}
+		}
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// cargoMembers expands workspace member globs ("crates/*") to the
+// directories holding a Cargo.toml.
+func cargoMembers(dir string, patterns []string) []string {
+	members := []string{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern), "Cargo.toml"))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			rel, err := filepath.Rel(dir, filepath.Dir(match))
+			if err == nil && !containsString(members, filepath.ToSlash(rel)) {
+				members = append(members, filepath.ToSlash(rel))
+			}
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// rustEntrypoint reports src/main.rs and src/bin targets with the cargo
+// command that runs them. Crates other than the repository root are
+// selected with -p.
+func rustEntrypoint(absPath, relPath string) (Entrypoint, bool) {
+	dir, base := path.Dir(relPath), path.Base(relPath)
+	crate, binary := "", ""
+	switch {
+	case path.Base(dir) == "bin" && path.Base(path.Dir(dir)) == "src":
+		crate, binary = path.Dir(path.Dir(dir)), strings.TrimSuffix(base, ".rs")
+	case base == "main.rs" && path.Base(path.Dir(dir)) == "bin" && path.Base(path.Dir(path.Dir(dir))) == "src":
+		crate, binary = path.Dir(path.Dir(path.Dir(dir))), path.Base(dir)
+	case base == "main.rs" && path.Base(dir) == "src":
+		crate = path.Dir(dir)
+	default:
+		return Entrypoint{}, false
+	}
+
+	command := "cargo run"
+	if crate != "." {
+		root := strings.TrimSuffix(absPath, filepath.FromSlash(relPath))
+		if manifest, ok := parseCargoManifest(filepath.Join(root, filepath.FromSlash(crate), "Cargo.toml")); ok && manifest.Name != "" {
+			command += " -p " + manifest.Name
+		}
+	}
+	if binary != "" {
+		command += " --bin " + binary
+	}
+
+	confidence := ConfidenceMedium
+	if content, err := os.ReadFile(absPath); err == nil && strings.Contains(string(content), "fn main(") {
+		confidence = ConfidenceHigh
+	}
+	return Entrypoint{
+		Type:        "rust-binary",
+		Path:        relPath,
+		Command:     command,
+		Description: "Rust binary",
+		Confidence:  confidence,
+	}, true
+}
+
+// extractRustEndpoints finds actix-web and Rocket attribute routes
+// (#[get("/users/{id}")]) and axum and actix .route() registrations.
+func extractRustEndpoints(content, file string) []Endpoint {
+	endpoints := []Endpoint{}
+	add := func(method, route, handler string) {
+		endpoints = append(endpoints, Endpoint{
+			Method:     strings.ToUpper(method),
+			Path:       route,
+			Handler:    handler,
+			File:       file,
+			Confidence: ConfidenceHigh,
+		})
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		match := rustAttrRoute.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		handler := ""
+		for _, next := range lines[i+1 : min(i+6, len(lines))] {
+			if fn := rustFn.FindStringSubmatch(next); fn != nil {
+				handler = fn[1]
+				break
+			}
+		}
+		add(match[1], match[2], handler)
+	}
+
+	for _, loc := range rustRoute.FindAllStringSubmatchIndex(content, -1) {
+		route := content[loc[2]:loc[3]]
+		methods := balancedArgument(content[loc[1]:])
+		if matches := actixMethod.FindAllStringSubmatch(methods, -1); matches != nil {
+			for _, match := range matches {
+				add(match[1], route, match[2])
+			}
+			continue
+		}
+		for _, match := range axumMethod.FindAllStringSubmatch(methods, -1) {
+			add(match[1], route, match[2])
+		}
+	}
+	return endpoints
+}
+
+// balancedArgument returns the text up to the parenthesis closing the
+// call whose arguments start text.
+func balancedArgument(text string) string {
+	depth := 0
+	for i, r := range text {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return text[:i]
+			}
+			depth--
+		}
+	}
+	return text
+}
+
+// extractSerdeModels returns the structs deriving Serialize or
+// Deserialize, with their fields as serialized: #[serde(rename)] applies
+// and #[serde(skip)] fields are left out.
+func extractSerdeModels(content, file string) []Model {
+	models := []Model{}
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		match := rustDerive.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if match == nil || !strings.Contains(match[1], "Serialize") && !strings.Contains(match[1], "Deserialize") {
+			continue
+		}
+
+		j := i + 1
+		for j < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[j]), "#[") {
+			j++
+		}
+		if j >= len(lines) {
+			break
+		}
+		structMatch := rustStruct.FindStringSubmatch(strings.TrimSpace(lines[j]))
+		if structMatch == nil {
+			continue
+		}
+
+		model := Model{Name: structMatch[1], Fields: []string{}, File: file, Confidence: ConfidenceHigh}
+		rename, skip := "", false
+		for j++; j < len(lines); j++ {
+			field := strings.TrimSpace(lines[j])
+			if strings.HasPrefix(field, "}") {
+				break
+			}
+			if strings.HasPrefix(field, "#[serde(") {
+				if m := rustSerdeRename.FindStringSubmatch(field); m != nil {
+					rename = m[1]
+				}
+				skip = skip || rustSerdeSkip.MatchString(field)
+				continue
+			}
+			if m := rustField.FindStringSubmatch(field); m != nil {
+				if !skip {
+					model.Fields = append(model.Fields, firstNonEmpty(rename, m[1]))
+				}
+				rename, skip = "", false
+			}
+		}
+		models = append(models, model)
+		i = j
+	}
+	return models
+}
@@ -0,0 +1,166 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestExtractRustEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "axum",
+			content: `let app = Router::new()
+    .route("/users", get(list_users).post(create_user))
+    .route(
+        "/users/:id",
+        get(handlers::show_user).delete(delete_user),
+    );`,
+			want: []string{
+				"GET /users list_users",
+				"POST /users create_user",
+				"GET /users/:id handlers::show_user",
+				"DELETE /users/:id delete_user",
+			},
+		},
+		{
+			name: "actix",
+			content: `#[get("/orders/{id}")]
+async fn show(path: web::Path<u32>) -> impl Responder {}
+
+App::new().route("/health", web::get().to(health))`,
+			want: []string{
+				"GET /orders/{id} show",
+				"GET /health health",
+			},
+		},
+		{
+			name: "rocket",
+			content: `#[post("/login", data = "<form>")]
+#[allow(unused)]
+pub async fn login(form: Form<Login>) -> Redirect {}`,
+			want: []string{"POST /login login"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, endpoint := range extractRustEndpoints(tt.content, "src/main.rs") {
+				got = append(got, endpoint.Method+" "+endpoint.Path+" "+endpoint.Handler)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractRustEndpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractSerdeModels(t *testing.T) {
+	content := `#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(rename_all = "camelCase")]
+pub struct Order<T> {
+    pub id: u64,
+    #[serde(rename = "total_cents")]
+    pub total: i64,
+    #[serde(skip)]
+    cache: Option<T>,
+    pub(crate) items: Vec<Item>,
+}
+
+#[derive(Debug)]
+struct Internal {
+    secret: String,
+}
+
+#[derive(Deserialize)]
+struct Id(u64);
+`
+	got := extractSerdeModels(content, "src/models.rs")
+	if len(got) != 1 || got[0].Name != "Order" || !reflect.DeepEqual(got[0].Fields, []string{"id", "total_cents", "items"}) {
+		t.Errorf("extractSerdeModels() = %+v", got)
+	}
+}
+
+func TestDetectCargoWorkspace(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"Cargo.toml": "[workspace]\nmembers = [\n  \"crates/*\", # libraries\n  \"api\",\n]\n\n[workspace.dependencies]\ntonic = \"0.11\"\n",
+		"api/Cargo.toml": `[package]
+name = "orders-api"
+
+[dependencies]
+axum = { version = "0.7", features = ["macros"] }
+serde = "1"
+
+[dependencies.tokio]
+version = "1"
+`,
+		"api/src/main.rs":               "use axum::Router;\n\n#[tokio::main]\nasync fn main() {}\n",
+		"api/src/bin/migrate.rs":        "fn main() {}\n",
+		"crates/core/Cargo.toml":        "[package]\nname = \"orders-core\"\n",
+		"crates/core/src/lib.rs":        "pub fn total() {}\n",
+		"crates/README.md":              "libraries\n",
+		"tools/gen/Cargo.toml":          "[package]\nname = \"gen\"\n",
+		"tools/gen/src/bin/gen/main.rs": "fn main() {}\n",
+	}
+	scanned := []scanner.FileInfo{}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(name, ".rs") {
+			scanned = append(scanned, scanner.FileInfo{Path: path, RelativePath: name, Language: "rust"})
+		}
+	}
+	sort.Slice(scanned, func(i, j int) bool { return scanned[i].RelativePath < scanned[j].RelativePath })
+
+	result, err := Detect(context.Background(), Options{Files: scanned, RepoPath: tempDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tools := []string{}
+	for _, tool := range result.BuildTools {
+		tools = append(tools, tool.File+"["+strings.Join(tool.Modules, ",")+"]")
+	}
+	if got := strings.Join(tools, " "); got != "Cargo.toml[api,crates/core] tools/gen/Cargo.toml[]" {
+		t.Errorf("BuildTools = %s", got)
+	}
+
+	entrypoints := []string{}
+	for _, entrypoint := range result.Entrypoints {
+		entrypoints = append(entrypoints, entrypoint.Command)
+	}
+	want := []string{"cargo run -p orders-api --bin migrate", "cargo run -p orders-api", "cargo run -p gen --bin gen"}
+	if !reflect.DeepEqual(entrypoints, want) {
+		t.Errorf("Entrypoints = %v, want %v", entrypoints, want)
+	}
+
+	if len(result.Frameworks) != 1 || result.Frameworks[0].Name != "axum" || result.Frameworks[0].Confidence != ConfidenceHigh {
+		t.Errorf("Frameworks = %+v, want axum", result.Frameworks)
+	}
+
+	tags := []string{}
+	for _, tag := range result.Tags {
+		tags = append(tags, tag.Name)
+	}
+	if !containsString(tags, "grpc") {
+		t.Errorf("Tags = %v, want grpc from workspace dependencies", tags)
+	}
+}
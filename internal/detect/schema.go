@@ -0,0 +1,248 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+type Table struct {
+	Name       string
+	Columns    []string
+	Source     string
+	Kind       string
+	References []string
+}
+
+var (
+	sqlCreateTable  = regexp.MustCompile(`(?is)create\s+table\s+(?:if\s+not\s+exists\s+)?[\x60"\[]?(?:\w+[\x60"\]]?\.[\x60"\[]?)?(\w+)[\x60"\]]?\s*\((.*?)\)\s*;`)
+	sqlAlterAdd     = regexp.MustCompile(`(?i)alter\s+table\s+(?:if\s+exists\s+)?[\x60"\[]?(?:\w+[\x60"\]]?\.[\x60"\[]?)?(\w+)[\x60"\]]?\s+add\s+(?:column\s+)?(?:if\s+not\s+exists\s+)?[\x60"\[]?(\w+)`)
+	alembicCreate   = regexp.MustCompile(`(?s)op\.create_table\(\s*['"](\w+)['"](.*?)\n\s*\)`)
+	alembicAdd      = regexp.MustCompile(`op\.add_column\(\s*['"](\w+)['"]\s*,\s*sa\.Column\(\s*['"](\w+)['"]`)
+	alembicColumn   = regexp.MustCompile(`sa\.Column\(\s*['"](\w+)['"]`)
+	djangoCreate    = regexp.MustCompile(`(?s)migrations\.CreateModel\(\s*name=['"](\w+)['"]\s*,\s*fields=\[(.*?)\]\s*,?\s*(?:options=|bases=|\))`)
+	djangoField     = regexp.MustCompile(`\(\s*['"](\w+)['"]\s*,\s*models\.`)
+	prismaModel     = regexp.MustCompile(`(?s)\bmodel\s+(\w+)\s*\{(.*?)\n\}`)
+	prismaMap       = regexp.MustCompile(`@@map\(\s*"(\w+)"\s*\)`)
+	sqlConstraintKw = map[string]bool{
+		"primary": true, "foreign": true, "unique": true, "constraint": true,
+		"key": true, "index": true, "check": true, "exclude": true,
+	}
+)
+
+// flywayUndo matches Flyway undo migrations, which reverse a versioned
+// migration the way .down.sql files do.
+var flywayUndo = regexp.MustCompile(`^U\d+(_\d+)*__.*\.sql$`)
+
+// detectSchema walks repoPath for migration files and ORM schema
+// definitions. It reads the tree directly because migration formats (.sql,
+// schema.prisma) are often excluded by the --lang filter.
+func detectSchema(ctx context.Context, repoPath string, files []scanner.FileInfo) []Table {
+	if repoPath == "" {
+		return []Table{}
+	}
+
+	tables := make(map[string]*Table)
+	addColumns := func(name, source, kind string, columns ...string) {
+		key := strings.ToLower(name)
+		table, ok := tables[key]
+		if !ok {
+			table = &Table{Name: name, Source: source, Kind: kind}
+			tables[key] = table
+		}
+		for _, column := range columns {
+			if column != "" && !containsString(table.Columns, column) {
+				table.Columns = append(table.Columns, column)
+			}
+		}
+	}
+
+	walkRepo(ctx, repoPath, func(path, rel string) {
+		base := filepath.Base(rel)
+		switch {
+		case strings.HasSuffix(base, ".sql") && !strings.HasSuffix(base, ".down.sql") && !flywayUndo.MatchString(base):
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return
+			}
+			for _, match := range sqlCreateTable.FindAllStringSubmatch(string(content), -1) {
+				addColumns(match[1], rel, "sql", parseSQLColumns(match[2])...)
+			}
+			for _, match := range sqlAlterAdd.FindAllStringSubmatch(string(content), -1) {
+				if !sqlConstraintKw[strings.ToLower(match[2])] {
+					addColumns(match[1], rel, "sql", match[2])
+				}
+			}
+
+		case base == "schema.prisma" || strings.HasSuffix(base, ".prisma"):
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return
+			}
+			for _, match := range prismaModel.FindAllStringSubmatch(string(content), -1) {
+				name := match[1]
+				if mapped := prismaMap.FindStringSubmatch(match[2]); mapped != nil {
+					name = mapped[1]
+				}
+				addColumns(name, rel, "prisma", parsePrismaFields(match[2])...)
+			}
+
+		case strings.HasSuffix(base, ".rb") && isRailsSchema(rel):
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return
+			}
+			tables := parseRailsSchema(string(content))
+			for _, name := range sortedMapKeys(tables) {
+				addColumns(name, rel, "activerecord", tables[name]...)
+			}
+
+		case isLaravelMigration(rel):
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return
+			}
+			tables := parseLaravelMigration(string(content))
+			for _, name := range sortedMapKeys(tables) {
+				addColumns(name, rel, "eloquent", tables[name]...)
+			}
+
+		case strings.HasSuffix(base, ".py") && isPythonMigration(rel):
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return
+			}
+			text := string(content)
+			for _, match := range alembicCreate.FindAllStringSubmatch(text, -1) {
+				columns := []string{}
+				for _, column := range alembicColumn.FindAllStringSubmatch(match[2], -1) {
+					columns = append(columns, column[1])
+				}
+				addColumns(match[1], rel, "alembic", columns...)
+			}
+			for _, match := range alembicAdd.FindAllStringSubmatch(text, -1) {
+				addColumns(match[1], rel, "alembic", match[2])
+			}
+			for _, match := range djangoCreate.FindAllStringSubmatch(text, -1) {
+				columns := []string{}
+				for _, field := range djangoField.FindAllStringSubmatch(match[2], -1) {
+					columns = append(columns, field[1])
+				}
+				addColumns(match[1], rel, "django", columns...)
+			}
+		}
+	})
+
+	result := []Table{}
+	for _, key := range sortedMapKeys(tables) {
+		table := tables[key]
+		table.References = findTableReferences(table.Name, table.Source, files)
+		result = append(result, *table)
+	}
+
+	return result
+}
+
+func parseSQLColumns(body string) []string {
+	columns := []string{}
+	depth := 0
+	start := 0
+	parts := []string{}
+	for i, r := range body {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, body[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, body[start:])
+
+	for _, part := range parts {
+		fields := strings.Fields(strings.TrimSpace(part))
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.Trim(fields[0], "`\"[]")
+		if sqlConstraintKw[strings.ToLower(name)] {
+			continue
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+func parsePrismaFields(body string) []string {
+	fields := []string{}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") || strings.HasPrefix(line, "@@") {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			continue
+		}
+		// Relation fields reference another model and have no column.
+		if strings.Contains(line, "@relation") && !strings.Contains(line, "fields:") {
+			continue
+		}
+		fields = append(fields, parts[0])
+	}
+	return fields
+}
+
+func isPythonMigration(rel string) bool {
+	return strings.Contains(rel, "/migrations/") || strings.HasPrefix(rel, "migrations/") ||
+		strings.Contains(rel, "alembic/versions/")
+}
+
+// findTableReferences lists source files (other than the defining migration)
+// that mention the table name as a whole word.
+func findTableReferences(table, source string, files []scanner.FileInfo) []string {
+	pattern, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(table) + `\b`)
+	if err != nil {
+		return nil
+	}
+
+	references := []string{}
+	for _, file := range files {
+		rel := filepath.ToSlash(file.RelativePath)
+		if rel == source || isPythonMigration(rel) || file.IsTest {
+			continue
+		}
+		switch file.Language {
+		case "markdown", "yaml", "json", "sql", "unknown":
+			continue
+		}
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			continue
+		}
+		if pattern.Match(content) {
+			references = append(references, rel)
+		}
+	}
+
+	sort.Strings(references)
+	return references
+}
+
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestDetectSchema(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"migrations/0001_users.up.sql":   "CREATE TABLE IF NOT EXISTS users (\n  id SERIAL PRIMARY KEY,\n  email VARCHAR(255) NOT NULL,\n  PRIMARY KEY (id)\n);\n",
+		"migrations/0001_users.down.sql": "DROP TABLE users;\n",
+		"migrations/0002_name.up.sql":    "ALTER TABLE users ADD COLUMN name TEXT;\n",
+		"prisma/schema.prisma":           "model Post {\n  id Int @id\n  title String\n  author User @relation(\"x\")\n  @@map(\"posts\")\n}\n",
+		"alembic/versions/abc_orders.py": "def upgrade():\n    op.create_table('orders',\n        sa.Column('id', sa.Integer()),\n        sa.Column('total', sa.Numeric()),\n    )\n",
+		"store/repo.go":                  "package store\n\nconst q = \"SELECT * FROM users\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scanned := []scanner.FileInfo{{
+		Path:         filepath.Join(tempDir, "store/repo.go"),
+		RelativePath: "store/repo.go",
+		Language:     "go",
+	}}
+
+	tables := detectSchema(context.Background(), tempDir, scanned)
+	if len(tables) != 3 {
+		t.Fatalf("Expected 3 tables, got %+v", tables)
+	}
+
+	byName := map[string]Table{}
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+
+	users := byName["users"]
+	if len(users.Columns) != 3 || users.Columns[2] != "name" {
+		t.Errorf("Unexpected users columns: %v", users.Columns)
+	}
+	if len(users.References) != 1 || users.References[0] != "store/repo.go" {
+		t.Errorf("Expected users to be referenced by store/repo.go, got %v", users.References)
+	}
+	if posts := byName["posts"]; len(posts.Columns) != 2 || posts.Kind != "prisma" {
+		t.Errorf("Unexpected posts table: %+v", posts)
+	}
+	if orders := byName["orders"]; len(orders.Columns) != 2 || orders.Kind != "alembic" {
+		t.Errorf("Unexpected orders table: %+v", orders)
+	}
+}
+
+func TestDetectSchemaSkipsUndoMigrations(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"db/V2__accounts.sql":       "CREATE TABLE accounts (\n  id INT\n);\n",
+		"db/U2__accounts.sql":       "CREATE TABLE legacy_accounts (\n  id INT\n);\n",
+		"db/U2_1__accounts.sql":     "CREATE TABLE legacy_users (\n  id INT\n);\n",
+		"db/Users.sql":              "CREATE TABLE users (\n  id INT\n);\n",
+		"db/Upgrade__audit.sql":     "CREATE TABLE audit (\n  id INT\n);\n",
+		"db/U3_notes_undo_like.sql": "CREATE TABLE notes (\n  id INT\n);\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := []string{}
+	for _, table := range detectSchema(context.Background(), tempDir, nil) {
+		got = append(got, table.Name)
+	}
+	if want := []string{"accounts", "audit", "notes", "users"}; !slices.Equal(got, want) {
+		t.Errorf("Expected tables %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,153 @@
+package detect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Secret is a likely credential committed to the repository. Match is masked
+// so the report never repeats the secret.
+type Secret struct {
+	Rule  string
+	File  string
+	Line  int
+	Match string
+}
+
+type secretRule struct {
+	id      string
+	pattern *regexp.Regexp
+	// group is the submatch holding the secret; 0 is the whole match.
+	group int
+	// minEntropy, when set, rejects low-entropy values such as placeholders.
+	minEntropy float64
+}
+
+// secretAllowComment on a line suppresses secrets reported for it.
+const secretAllowComment = "codedoc:allow-secret"
+
+var (
+	secretRules = []secretRule{
+		{id: "private-key", pattern: regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`)},
+		{id: "aws-access-key-id", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`)},
+		{id: "github-token", pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+		{id: "gitlab-token", pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20}\b`)},
+		{id: "slack-token", pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9\-]{10,}\b`)},
+		{id: "stripe-key", pattern: regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}\b`)},
+		{id: "google-api-key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+		{id: "anthropic-api-key", pattern: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_\-]{32,}`)},
+		{id: "openai-api-key", pattern: regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_\-]{40,}`), minEntropy: 3.5},
+		{id: "jwt", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`)},
+		{id: "credentials-in-url", pattern: regexp.MustCompile(`\b[a-z][a-z0-9+.\-]*://[^\s:/@"']+:([^\s:/@"']{6,})@`), group: 1, minEntropy: 3},
+		{
+			id:         "generic-secret",
+			pattern:    regexp.MustCompile(`(?i)(?:secret|token|passw(?:or)?d|api[_\-]?key|access[_\-]?key|private[_\-]?key|client[_\-]?secret)[A-Za-z0-9_\-]*["']?\s*[:=]\s*["']([^"'\s]{16,})["']`),
+			group:      1,
+			minEntropy: 3.5,
+		},
+	}
+
+	// placeholderSecret matches values that only look like credentials.
+	placeholderSecret = regexp.MustCompile(`(?i)example|sample|dummy|changeme|placeholder|your[_\-]|xxxx|\*\*\*\*|\$\{|\{\{|<[a-z_\-]+>|^\$[A-Z_]+$`)
+
+	// secretSkipFiles hold hashes and checksums that trip the entropy rules.
+	secretSkipFiles = map[string]bool{
+		"go.sum": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+		"poetry.lock": true, "Cargo.lock": true, "Gemfile.lock": true, "composer.lock": true,
+	}
+)
+
+// detectSecrets scans every text file of the repository, not only the
+// analyzed ones, since a secret anywhere in the tree is worth a warning.
+func detectSecrets(ctx context.Context, repoPath string) []Secret {
+	secrets := []Secret{}
+	if repoPath == "" {
+		return secrets
+	}
+
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		if secretSkipFiles[path.Base(rel)] {
+			return
+		}
+		info, err := os.Stat(p)
+		if err != nil || info.Size() > 1024*1024 {
+			return
+		}
+		content, err := os.ReadFile(p)
+		if err != nil || bytes.IndexByte(content, 0) >= 0 {
+			return
+		}
+		secrets = append(secrets, scanSecrets(rel, content)...)
+	})
+
+	sort.SliceStable(secrets, func(i, j int) bool {
+		if secrets[i].File != secrets[j].File {
+			return secrets[i].File < secrets[j].File
+		}
+		return secrets[i].Line < secrets[j].Line
+	})
+	return secrets
+}
+
+func scanSecrets(rel string, content []byte) []Secret {
+	secrets := []Secret{}
+	lines := bufio.NewScanner(bytes.NewReader(content))
+	lines.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for number := 1; lines.Scan(); number++ {
+		line := lines.Text()
+		if strings.Contains(line, secretAllowComment) {
+			continue
+		}
+		for _, rule := range secretRules {
+			match := rule.pattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			value := match[rule.group]
+			if placeholderSecret.MatchString(value) {
+				continue
+			}
+			if rule.minEntropy > 0 && shannonEntropy(value) < rule.minEntropy {
+				continue
+			}
+			secrets = append(secrets, Secret{Rule: rule.id, File: rel, Line: number, Match: maskSecret(value)})
+			break
+		}
+	}
+	return secrets
+}
+
+// shannonEntropy is the entropy of value in bits per character.
+func shannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range value {
+		counts[r]++
+	}
+	length := float64(len([]rune(value)))
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// maskSecret keeps the first four characters, enough to recognize the
+// credential type, and hides the rest.
+func maskSecret(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:4] + strings.Repeat("*", min(len(value)-4, 12))
+}
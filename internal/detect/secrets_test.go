@@ -0,0 +1,73 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanSecrets(t *testing.T) {
+	// Fixtures are split so scanning this repository does not flag them.
+	awsKey := "AKIA" + "Z7QW3MRT5XKP2LNB"
+	githubToken := "ghp_" + "abcdefghijklmnopqrstuvwxyzABCDEFGHIJ"
+
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"aws key", `AWS_KEY = "` + awsKey + `"`, "aws-access-key-id"},
+		{"github token", "GH_TOKEN=" + githubToken, "github-token"},
+		{"private key", "-----BEGIN " + "OPENSSH PRIVATE KEY-----", "private-key"},
+		{"generic high entropy", `api_key: "q8Zr3LmT9vXw` + `2KpB7nYc4HdF"`, "generic-secret"},
+		{"credentials in url", `DSN = "postgres://admin:Zx9k` + `Lq2Pw7@db:5432/app"`, "credentials-in-url"},
+		{"low entropy", `password = "aaaaaaaaaaaaaaaaaaaa"`, ""},
+		{"placeholder", `api_key = "your-api-key-goes-here-123"`, ""},
+		{"template", `token: "${{ secrets.DEPLOY_TOKEN }}"`, ""},
+		{"allowed", "GH_TOKEN=" + githubToken + " # codedoc:" + "allow-secret", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secrets := scanSecrets("config.txt", []byte("first line\n"+tt.line+"\n"))
+			if tt.want == "" {
+				if len(secrets) != 0 {
+					t.Fatalf("Expected no secrets, got %+v", secrets)
+				}
+				return
+			}
+			if len(secrets) != 1 || secrets[0].Rule != tt.want || secrets[0].Line != 2 {
+				t.Fatalf("Expected one %s secret on line 2, got %+v", tt.want, secrets)
+			}
+		})
+	}
+}
+
+func TestDetectSecretsMasksAndSkips(t *testing.T) {
+	dir := t.TempDir()
+	token := "ghp_" + "abcdefghijklmnopqrstuvwxyzABCDEFGHIJ"
+	files := map[string]string{
+		".env":        "GITHUB_TOKEN=" + token + "\n",
+		"go.sum":      "example.com/mod v1.0.0 h1:q8Zr3LmT9vXw2KpB7nYc4HdFq8Zr3LmT9vXw2KpB7nY=\n",
+		"logo.png":    "\x89PNG\x00" + token,
+		"src/main.go": "package main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secrets := detectSecrets(context.Background(), dir)
+	if len(secrets) != 1 || secrets[0].File != ".env" {
+		t.Fatalf("Expected only the .env token, got %+v", secrets)
+	}
+	if secrets[0].Match != "ghp_************" {
+		t.Errorf("Match = %q, want the token masked", secrets[0].Match)
+	}
+}
@@ -0,0 +1,115 @@
+package detect
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Tag is a topic inferred for the repository, such as "payments" or
+// "kubernetes", for catalogs like Backstage. Evidence names what suggested
+// it.
+type Tag struct {
+	Name     string
+	Evidence string
+}
+
+// dependencyTags maps a topic to the declared dependencies that suggest it.
+// Go module paths also match their subpackages and major versions.
+var dependencyTags = map[string][]string{
+	"payments":            {"stripe", "github.com/stripe/stripe-go", "braintree", "adyen", "@adyen/api-library", "paypalrestsdk", "@paypal/checkout-server-sdk", "async-stripe", "Stripe.net", "stripe/stripe-php"},
+	"kubernetes-operator": {"sigs.k8s.io/controller-runtime", "github.com/operator-framework/operator-sdk", "kopf", "kube-runtime"},
+	"react-spa":           {"react-dom"},
+	"etl":                 {"apache-airflow", "dbt-core", "pyspark", "luigi", "dagster", "prefect"},
+	"machine-learning":    {"torch", "tensorflow", "scikit-learn", "transformers", "xgboost"},
+	"graphql":             {"graphql", "graphene", "strawberry-graphql", "github.com/99designs/gqlgen", "apollo-server", "async-graphql", "juniper", "HotChocolate.AspNetCore", "webonyx/graphql-php", "nuwave/lighthouse"},
+	"grpc":                {"google.golang.org/grpc", "grpcio", "@grpc/grpc-js", "tonic", "Grpc.AspNetCore", "grpc/grpc"},
+	"messaging":           {"kafka-python", "confluent-kafka", "kafkajs", "github.com/segmentio/kafka-go", "pika", "amqplib", "github.com/nats-io/nats.go", "rdkafka", "lapin", "Confluent.Kafka", "RabbitMQ.Client", "MassTransit", "php-amqplib/php-amqplib"},
+}
+
+// inferTags derives topic tags from what detection found and from declared
+// dependencies. Tags are sorted by name.
+func inferTags(ctx context.Context, repoPath string, result *Result) []Tag {
+	found := make(map[string]string)
+	add := func(name, evidence string) {
+		if _, ok := found[name]; !ok {
+			found[name] = evidence
+		}
+	}
+
+	for _, framework := range result.Frameworks {
+		add(strings.ReplaceAll(framework.Name, "/", "-"), "framework")
+	}
+	if len(result.Endpoints) > 0 {
+		add("http-api", fmt.Sprintf("%d endpoints", len(result.Endpoints)))
+	}
+	if len(result.CLICommands) > 0 {
+		add("cli", fmt.Sprintf("%d commands", len(result.CLICommands)))
+	}
+	if len(result.K8s) > 0 || len(result.HelmCharts) > 0 {
+		add("kubernetes", "manifests")
+	}
+	for _, provider := range result.Infrastructure.Providers {
+		add("terraform", provider.File)
+		if cloud := terraformCloud(provider.Name); cloud != "" {
+			add(cloud, provider.File)
+		}
+	}
+	for _, artifact := range result.Artifacts {
+		if artifact.Kind == "image" {
+			add("docker", artifact.Source)
+		}
+	}
+	if len(result.Tables) > 0 {
+		add("database", fmt.Sprintf("%d tables", len(result.Tables)))
+	}
+	for _, resource := range result.K8s {
+		if resource.Kind == "CustomResourceDefinition" {
+			add("kubernetes-operator", resource.Source)
+		}
+	}
+
+	if repoPath != "" {
+		walkRepo(ctx, repoPath, func(p, rel string) {
+			base := path.Base(rel)
+			var names []string
+			modulePrefix := false
+			switch {
+			case base == "go.mod":
+				names, modulePrefix = goModRequires(p), true
+			case base == "package.json":
+				names, _ = packageJSONDependencies(p)
+			case strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+				names = requirementNames(p)
+			case base == "pyproject.toml":
+				names = pyprojectDependencies(p)
+			case base == "Gemfile":
+				names = gemfileGems(p)
+			case base == "Cargo.toml":
+				names = cargoDependencies(p)
+			case base == "composer.json":
+				names = composerPackages(p)
+			case isProjectFile(base):
+				names = nugetDependencies(p)
+			case base == "dbt_project.yml":
+				add("etl", rel)
+			case strings.HasSuffix(base, ".proto"):
+				add("grpc", rel)
+			}
+			for _, tag := range sortedMapKeys(dependencyTags) {
+				if declaresAny(names, dependencyTags[tag], modulePrefix) {
+					add(tag, rel)
+				}
+			}
+		})
+	}
+
+	tags := make([]Tag, 0, len(found))
+	for name, evidence := range found {
+		tags = append(tags, Tag{Name: name, Evidence: evidence})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
@@ -0,0 +1,48 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInferTags(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example.com/shop\n\nrequire (\n\tgithub.com/stripe/stripe-go/v76 v76.0.0\n\tsigs.k8s.io/controller-runtime v0.16.0\n)\n",
+		"web/package.json": `{"dependencies": {"react": "^18.0.0", "react-dom": "^18.0.0"}}`,
+		"api/orders.proto": "syntax = \"proto3\";\n",
+		"requirements.txt": "requests==2.31.0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := &Result{
+		Frameworks: []Framework{{Name: "gorilla/mux"}},
+		Endpoints:  []Endpoint{{Method: "GET", Path: "/orders"}},
+	}
+	tags := inferTags(context.Background(), tempDir, result)
+
+	names := []string{}
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	want := "gorilla-mux, grpc, http-api, kubernetes-operator, payments, react-spa"
+	if got := strings.Join(names, ", "); got != want {
+		t.Errorf("inferTags = %s, want %s", got, want)
+	}
+	for _, tag := range tags {
+		if tag.Name == "payments" && tag.Evidence != "go.mod" {
+			t.Errorf("Expected payments to cite go.mod, got %q", tag.Evidence)
+		}
+	}
+}
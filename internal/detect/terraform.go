@@ -0,0 +1,325 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Infrastructure is what the repository's Terraform configuration
+// provisions.
+type Infrastructure struct {
+	Providers []TerraformProvider
+	Resources []TerraformResource
+	Modules   []TerraformModule
+	// Roots are the directories Terraform is run from: those configuring a
+	// provider or a backend.
+	Roots []TerraformRoot
+}
+
+type TerraformProvider struct {
+	Name    string
+	Source  string
+	Version string
+	File    string
+}
+
+// TerraformResource is a resource block, or a data source when Data is set.
+type TerraformResource struct {
+	Type     string
+	Name     string
+	Provider string
+	File     string
+	Data     bool
+}
+
+type TerraformModule struct {
+	Name    string
+	Source  string
+	Version string
+	File    string
+}
+
+type TerraformRoot struct {
+	Dir     string
+	Backend string
+	// VarFiles are the .tfvars files next to the root, other than the ones
+	// Terraform loads on its own.
+	VarFiles []string
+	file     string
+}
+
+var (
+	hclBlockHeader = regexp.MustCompile(`^([\w-]+)\s*(=\s*)?((?:"[^"]*"\s*)*)\{`)
+	hclLabel       = regexp.MustCompile(`"([^"]*)"`)
+	hclAttribute   = regexp.MustCompile(`\b(source|version)\s*=\s*"([^"]*)"`)
+	hclProviderRef = regexp.MustCompile(`^provider\s*=\s*([\w-]+)`)
+	hclHeredoc     = regexp.MustCompile(`<<-?\s*"?(\w+)"?\s*$`)
+)
+
+func detectTerraform(ctx context.Context, repoPath string) Infrastructure {
+	infra := Infrastructure{
+		Providers: []TerraformProvider{},
+		Resources: []TerraformResource{},
+		Modules:   []TerraformModule{},
+		Roots:     []TerraformRoot{},
+	}
+	if repoPath == "" {
+		return infra
+	}
+
+	roots := map[string]*TerraformRoot{}
+	varFiles := map[string][]string{}
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		base := path.Base(rel)
+		switch {
+		case strings.HasSuffix(base, ".tf"):
+			content, err := os.ReadFile(p)
+			if err != nil {
+				return
+			}
+			dir := path.Dir(rel)
+			backend, root := parseTerraform(string(content), rel, &infra)
+			if !root {
+				return
+			}
+			if roots[dir] == nil {
+				roots[dir] = &TerraformRoot{Dir: dir, file: rel}
+			}
+			if backend != "" {
+				roots[dir].Backend = backend
+			}
+		case strings.HasSuffix(base, ".tfvars") && base != "terraform.tfvars" && !strings.HasSuffix(base, ".auto.tfvars"):
+			varFiles[path.Dir(rel)] = append(varFiles[path.Dir(rel)], base)
+		}
+	})
+
+	for _, dir := range sortedMapKeys(roots) {
+		root := roots[dir]
+		root.VarFiles = varFiles[dir]
+		infra.Roots = append(infra.Roots, *root)
+	}
+	infra.Providers = terraformProviders(infra)
+	return infra
+}
+
+// parseTerraform adds the blocks of one .tf file to infra. It reports the
+// file's backend and whether the file belongs to a root configuration. The
+// parser follows block nesting line by line, which is enough for the
+// conventional formatting `terraform fmt` enforces.
+func parseTerraform(content, rel string, infra *Infrastructure) (backend string, root bool) {
+	stack := []string{}
+	current := -1
+	for _, line := range hclLines(content) {
+		header := hclBlockHeader.FindStringSubmatch(line)
+		deltas := braceDeltas(line)
+		opened := header != nil && len(deltas) > 0 && deltas[0] == 1
+
+		scope := stack
+		var labels []string
+		if opened {
+			scope = append(append([]string{}, stack...), header[1])
+			for _, match := range hclLabel.FindAllStringSubmatch(header[3], -1) {
+				labels = append(labels, match[1])
+			}
+		}
+
+		switch {
+		case len(scope) == 1 && opened:
+			current = -1
+			switch scope[0] {
+			case "resource", "data":
+				if len(labels) == 2 {
+					provider, _, _ := strings.Cut(labels[0], "_")
+					infra.Resources = append(infra.Resources, TerraformResource{
+						Type: labels[0], Name: labels[1], Provider: provider, File: rel, Data: scope[0] == "data",
+					})
+					current = len(infra.Resources) - 1
+				}
+			case "module":
+				if len(labels) == 1 {
+					infra.Modules = append(infra.Modules, TerraformModule{Name: labels[0], File: rel})
+					current = len(infra.Modules) - 1
+				}
+			case "provider":
+				if len(labels) == 1 {
+					infra.Providers = append(infra.Providers, TerraformProvider{Name: labels[0], File: rel})
+					root = true
+				}
+			}
+		case len(scope) == 1 && current >= 0 && (scope[0] == "resource" || scope[0] == "data"):
+			if match := hclProviderRef.FindStringSubmatch(line); match != nil {
+				infra.Resources[current].Provider = match[1]
+			}
+		case len(scope) == 2 && opened && scope[0] == "terraform":
+			switch {
+			case scope[1] == "backend" && len(labels) == 1:
+				backend, root = labels[0], true
+			case scope[1] == "cloud":
+				backend, root = "cloud", true
+			}
+		case len(scope) == 2 && scope[0] == "terraform" && scope[1] == "required_providers":
+			// Terraform 0.12 style: aws = "~> 3.0"
+			if name, version, ok := strings.Cut(line, "="); ok && !opened {
+				infra.Providers = append(infra.Providers, TerraformProvider{
+					Name: strings.TrimSpace(name), Version: strings.Trim(strings.TrimSpace(version), `"`), File: rel,
+				})
+			}
+		case len(scope) == 3 && scope[0] == "terraform" && scope[1] == "required_providers":
+			if opened {
+				infra.Providers = append(infra.Providers, TerraformProvider{Name: scope[2], File: rel})
+			}
+			provider := &infra.Providers[len(infra.Providers)-1]
+			for _, match := range hclAttribute.FindAllStringSubmatch(line, -1) {
+				if match[1] == "source" {
+					provider.Source = match[2]
+				} else {
+					provider.Version = match[2]
+				}
+			}
+		}
+
+		if len(scope) == 1 && scope[0] == "module" && current >= 0 {
+			for _, match := range hclAttribute.FindAllStringSubmatch(line, -1) {
+				if match[1] == "source" {
+					infra.Modules[current].Source = match[2]
+				} else {
+					infra.Modules[current].Version = match[2]
+				}
+			}
+		}
+
+		for i, delta := range deltas {
+			if delta > 0 {
+				name := ""
+				if i == 0 && opened {
+					name = header[1]
+				}
+				stack = append(stack, name)
+			} else if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	return backend, root
+}
+
+// hclLines returns the trimmed, non-empty lines of an HCL file without
+// comments and heredoc bodies.
+func hclLines(content string) []string {
+	lines := []string{}
+	heredoc := ""
+	inComment := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if heredoc != "" {
+			if line == heredoc {
+				heredoc = ""
+			}
+			continue
+		}
+		if inComment {
+			end := strings.Index(line, "*/")
+			if end < 0 {
+				continue
+			}
+			line, inComment = strings.TrimSpace(line[end+2:]), false
+		}
+		line = stripHCLComment(line)
+		if start := strings.Index(line, "/*"); start >= 0 && !insideString(line, start) {
+			if end := strings.Index(line[start:], "*/"); end >= 0 {
+				line = line[:start] + line[start+end+2:]
+			} else {
+				line, inComment = line[:start], true
+			}
+		}
+		if match := hclHeredoc.FindStringSubmatch(line); match != nil {
+			heredoc = match[1]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func stripHCLComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if (line[i] == '#' || strings.HasPrefix(line[i:], "//")) && !insideString(line, i) {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func insideString(line string, pos int) bool {
+	inside := false
+	for i := 0; i < pos; i++ {
+		if line[i] == '\\' && inside {
+			i++
+		} else if line[i] == '"' {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// terraformProviders merges the required_providers entries and provider
+// blocks by name, and adds providers only implied by resource types.
+func terraformProviders(infra Infrastructure) []TerraformProvider {
+	byName := map[string]*TerraformProvider{}
+	for _, provider := range infra.Providers {
+		existing, ok := byName[provider.Name]
+		if !ok {
+			copied := provider
+			byName[provider.Name] = &copied
+			continue
+		}
+		existing.Source = firstNonEmpty(existing.Source, provider.Source)
+		existing.Version = firstNonEmpty(existing.Version, provider.Version)
+	}
+	for _, resource := range infra.Resources {
+		if _, ok := byName[resource.Provider]; !ok {
+			byName[resource.Provider] = &TerraformProvider{Name: resource.Provider, File: resource.File}
+		}
+	}
+
+	providers := make([]TerraformProvider, 0, len(byName))
+	for _, name := range sortedMapKeys(byName) {
+		providers = append(providers, *byName[name])
+	}
+	return providers
+}
+
+// terraformBuildTools runs each root with -chdir, adding a plan per
+// variables file.
+func terraformBuildTools(infra Infrastructure) []BuildTool {
+	tools := []BuildTool{}
+	for _, root := range infra.Roots {
+		command := "terraform"
+		if root.Dir != "." {
+			command += " -chdir=" + root.Dir
+		}
+		scripts := []string{command + " init", command + " validate", command + " plan"}
+		for _, file := range root.VarFiles {
+			scripts = append(scripts, command+" plan -var-file="+file)
+		}
+		tools = append(tools, BuildTool{Type: "terraform", File: root.file, Scripts: scripts})
+	}
+	return tools
+}
+
+// terraformCloud names the cloud of a well-known provider.
+func terraformCloud(provider string) string {
+	switch provider {
+	case "aws":
+		return "aws"
+	case "google", "google-beta":
+		return "gcp"
+	case "azurerm", "azuread", "azapi":
+		return "azure"
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTerraform(t *testing.T) {
+	content := `terraform {
+  required_version = ">= 1.5"
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    random = { source = "hashicorp/random" }
+  }
+  backend "s3" {
+    bucket = "state" # not a resource
+  }
+}
+
+/* resource "aws_instance" "commented" {} */
+resource "aws_s3_bucket" "assets" {
+  bucket = "assets-${var.env}"
+  tags = {
+    Name = "assets"
+  }
+}
+
+resource "aws_iam_policy" "read" {
+  provider = aws.west
+  policy   = <<EOF
+resource "aws_instance" "in_heredoc" {}
+EOF
+}
+
+data "aws_caller_identity" "current" {}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+  // source = "./old"
+}
+`
+	infra := Infrastructure{}
+	backend, root := parseTerraform(content, "main.tf", &infra)
+	if backend != "s3" || !root {
+		t.Errorf("parseTerraform() = %q, %v, want s3 root", backend, root)
+	}
+
+	resources := []string{}
+	for _, resource := range infra.Resources {
+		resources = append(resources, resource.Provider+":"+resource.Type+"."+resource.Name)
+	}
+	wantResources := []string{"aws:aws_s3_bucket.assets", "aws:aws_iam_policy.read", "aws:aws_caller_identity.current"}
+	if !reflect.DeepEqual(resources, wantResources) || !infra.Resources[2].Data {
+		t.Errorf("Resources = %+v, want %q", infra.Resources, wantResources)
+	}
+
+	wantProviders := []TerraformProvider{
+		{Name: "aws", Source: "hashicorp/aws", Version: "~> 5.0", File: "main.tf"},
+		{Name: "random", Source: "hashicorp/random", File: "main.tf"},
+	}
+	if !reflect.DeepEqual(infra.Providers, wantProviders) {
+		t.Errorf("Providers = %+v, want %+v", infra.Providers, wantProviders)
+	}
+
+	wantModules := []TerraformModule{{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", File: "main.tf"}}
+	if !reflect.DeepEqual(infra.Modules, wantModules) {
+		t.Errorf("Modules = %+v, want %+v", infra.Modules, wantModules)
+	}
+}
+
+func TestDetectTerraform(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"envs/prod/main.tf":          "provider \"google\" {\n  project = var.project\n}\n\nmodule \"network\" {\n  source = \"../../modules/network\"\n}\n",
+		"envs/prod/prod.tfvars":      "project = \"shop-prod\"\n",
+		"envs/prod/terraform.tfvars": "region = \"europe-west1\"\n",
+		"modules/network/main.tf":    "resource \"google_compute_network\" \"vpc\" {\n  name = var.name\n}\n\nresource \"google_compute_subnetwork\" \"private\" {\n  network = google_compute_network.vpc.id\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := Detect(context.Background(), Options{RepoPath: tempDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infra := result.Infrastructure
+	wantRoots := []TerraformRoot{{Dir: "envs/prod", VarFiles: []string{"prod.tfvars"}, file: "envs/prod/main.tf"}}
+	if !reflect.DeepEqual(infra.Roots, wantRoots) {
+		t.Errorf("Roots = %+v, want %+v", infra.Roots, wantRoots)
+	}
+	if len(infra.Providers) != 1 || infra.Providers[0].Name != "google" || len(infra.Resources) != 2 {
+		t.Errorf("Infrastructure = %+v, want two google resources", infra)
+	}
+
+	wantScripts := []string{
+		"terraform -chdir=envs/prod init",
+		"terraform -chdir=envs/prod validate",
+		"terraform -chdir=envs/prod plan",
+		"terraform -chdir=envs/prod plan -var-file=prod.tfvars",
+	}
+	if len(result.BuildTools) != 1 || !reflect.DeepEqual(result.BuildTools[0].Scripts, wantScripts) {
+		t.Errorf("BuildTools = %+v, want %q", result.BuildTools, wantScripts)
+	}
+
+	tags := []string{}
+	for _, tag := range result.Tags {
+		tags = append(tags, tag.Name)
+	}
+	if !reflect.DeepEqual(tags, []string{"gcp", "terraform"}) {
+		t.Errorf("Tags = %v, want gcp and terraform", tags)
+	}
+}
@@ -0,0 +1,280 @@
+package detect
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// TestInventory describes how a repository is tested. It is built from the
+// whole tree, so it is populated even when test files are excluded from the
+// scan.
+type TestInventory struct {
+	Frameworks []TestFramework
+	Modules    []TestModule
+	Coverage   []Coverage
+}
+
+type TestFramework struct {
+	Name    string
+	Command string
+	Source  string
+}
+
+type TestModule struct {
+	Path  string
+	Files int
+}
+
+// Coverage is a line or statement coverage figure read from a report left in
+// the tree by a previous test run.
+type Coverage struct {
+	File    string
+	Format  string
+	Percent float64
+}
+
+var (
+	coberturaLineRate = regexp.MustCompile(`<coverage[^>]*\bline-rate="([\d.]+)"`)
+	pytestRequirement = regexp.MustCompile(`(?mi)^pytest\b`)
+)
+
+func detectTesting(ctx context.Context, repoPath string, tests *scanner.TestMatcher) TestInventory {
+	inventory := TestInventory{
+		Frameworks: []TestFramework{},
+		Modules:    []TestModule{},
+		Coverage:   []Coverage{},
+	}
+	if repoPath == "" {
+		return inventory
+	}
+
+	moduleCounts := map[string]int{}
+	frameworks := map[string]TestFramework{}
+	addFramework := func(name, command, source string) {
+		if _, ok := frameworks[name]; !ok {
+			frameworks[name] = TestFramework{Name: name, Command: command, Source: source}
+		}
+	}
+	pythonTests := ""
+
+	walkRepo(ctx, repoPath, func(p, rel string) {
+		base := path.Base(rel)
+
+		if tests.Match(rel) && !strings.Contains(rel, "testdata/") && isTestSource(base) {
+			moduleCounts[testModule(rel)]++
+			switch {
+			case strings.HasSuffix(base, "_test.go"):
+				addFramework("go test", "go test ./...", rel)
+			case strings.HasSuffix(base, ".py") && pythonTests == "":
+				pythonTests = rel
+			}
+		}
+
+		switch {
+		case base == "pytest.ini" || base == "conftest.py" || base == "tox.ini":
+			addFramework("pytest", "pytest", rel)
+		case base == "pyproject.toml" || base == "setup.cfg":
+			if content, err := os.ReadFile(p); err == nil {
+				text := string(content)
+				if strings.Contains(text, "[tool.pytest") || strings.Contains(text, "[tool:pytest]") {
+					addFramework("pytest", "pytest", rel)
+				}
+			}
+		case strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+			if content, err := os.ReadFile(p); err == nil && pytestRequirement.Match(content) {
+				addFramework("pytest", "pytest", rel)
+			}
+		case strings.HasPrefix(base, "jest.config."):
+			addFramework("jest", "npx jest", rel)
+		case strings.HasPrefix(base, "vitest.config."):
+			addFramework("vitest", "npx vitest run", rel)
+		case base == "package.json":
+			if content, err := os.ReadFile(p); err == nil {
+				for _, fw := range jsTestFrameworks(rel, content) {
+					addFramework(fw.Name, fw.Command, fw.Source)
+				}
+			}
+		}
+
+		if coverage, ok := readCoverage(p, rel); ok {
+			inventory.Coverage = append(inventory.Coverage, coverage)
+		}
+	})
+
+	if pythonTests != "" {
+		if _, ok := frameworks["pytest"]; !ok {
+			addFramework("unittest", "python -m unittest discover", pythonTests)
+		}
+	}
+
+	for _, name := range sortedMapKeys(frameworks) {
+		inventory.Frameworks = append(inventory.Frameworks, frameworks[name])
+	}
+	for module, count := range moduleCounts {
+		inventory.Modules = append(inventory.Modules, TestModule{Path: module, Files: count})
+	}
+	sort.Slice(inventory.Modules, func(i, j int) bool {
+		return inventory.Modules[i].Path < inventory.Modules[j].Path
+	})
+
+	return inventory
+}
+
+func isTestSource(base string) bool {
+	switch path.Ext(base) {
+	case ".go", ".py", ".js", ".ts", ".jsx", ".tsx", ".mjs", ".cjs":
+		return true
+	}
+	return false
+}
+
+// testModule groups a test file under its first two directory levels.
+func testModule(rel string) string {
+	dir := path.Dir(rel)
+	if dir == "." {
+		return "."
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, "/")
+}
+
+func jsTestFrameworks(rel string, content []byte) []TestFramework {
+	var pkg struct {
+		Scripts         map[string]string `json:"scripts"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(content, &pkg) != nil {
+		return nil
+	}
+
+	hasDep := func(name string) bool {
+		_, dev := pkg.DevDependencies[name]
+		_, dep := pkg.Dependencies[name]
+		return dev || dep
+	}
+
+	dir := path.Dir(rel)
+	prefix := ""
+	if dir != "." {
+		prefix = "cd " + dir + " && "
+	}
+
+	frameworks := []TestFramework{}
+	for _, candidate := range []struct{ name, command string }{
+		{"jest", "npx jest"},
+		{"vitest", "npx vitest run"},
+		{"mocha", "npx mocha"},
+	} {
+		if !hasDep(candidate.name) {
+			continue
+		}
+		command := candidate.command
+		if script := pkg.Scripts["test"]; strings.Contains(script, candidate.name) {
+			command = "npm test"
+		}
+		frameworks = append(frameworks, TestFramework{
+			Name:    candidate.name,
+			Command: prefix + command,
+			Source:  rel,
+		})
+	}
+	return frameworks
+}
+
+func readCoverage(p, rel string) (Coverage, bool) {
+	base := path.Base(rel)
+
+	switch {
+	case base == "coverage.out" || base == "cover.out" || strings.HasSuffix(base, ".coverprofile"):
+		if percent, ok := goCoverage(p); ok {
+			return Coverage{File: rel, Format: "go", Percent: percent}, true
+		}
+	case base == "lcov.info":
+		if percent, ok := lcovCoverage(p); ok {
+			return Coverage{File: rel, Format: "lcov", Percent: percent}, true
+		}
+	case base == "coverage.xml" || base == "cobertura.xml" || base == "cobertura-coverage.xml":
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return Coverage{}, false
+		}
+		if match := coberturaLineRate.FindSubmatch(content); match != nil {
+			if rate, err := strconv.ParseFloat(string(match[1]), 64); err == nil {
+				return Coverage{File: rel, Format: "cobertura", Percent: rate * 100}, true
+			}
+		}
+	}
+	return Coverage{}, false
+}
+
+// goCoverage computes statement coverage from a `go test -coverprofile`
+// file. Lines look like "file.go:10.2,12.3 2 1".
+func goCoverage(p string) (float64, bool) {
+	file, err := os.Open(p)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	total, covered := 0, 0
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		fields := strings.Fields(lines.Text())
+		if len(fields) != 3 || strings.HasPrefix(fields[0], "mode:") {
+			continue
+		}
+		statements, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		total += statements
+		if count > 0 {
+			covered += statements
+		}
+	}
+
+	if total == 0 {
+		return 0, false
+	}
+	return float64(covered) / float64(total) * 100, true
+}
+
+func lcovCoverage(p string) (float64, bool) {
+	file, err := os.Open(p)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	found, hit := 0, 0
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := lines.Text()
+		if value, ok := strings.CutPrefix(line, "LF:"); ok {
+			n, _ := strconv.Atoi(value)
+			found += n
+		} else if value, ok := strings.CutPrefix(line, "LH:"); ok {
+			n, _ := strconv.Atoi(value)
+			hit += n
+		}
+	}
+
+	if found == 0 {
+		return 0, false
+	}
+	return float64(hit) / float64(found) * 100, true
+}
@@ -0,0 +1,61 @@
+package detect
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestDetectTesting(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":                        "module x\n",
+		"internal/a/a_test.go":          "package a\n",
+		"internal/a/b_test.go":          "package a\n",
+		"internal/a/testdata/x_test.go": "package x\n",
+		"cmd/x/main_test.go":            "package main\n",
+		"web/package.json":              `{"scripts": {"test": "vitest"}, "devDependencies": {"vitest": "^1.0.0"}}`,
+		"web/src/app.test.ts":           "test('x', () => {})\n",
+		"coverage.out":                  "mode: set\na.go:1.1,2.2 3 1\na.go:3.1,4.2 1 0\n",
+		"web/coverage/lcov.info":        "SF:a.ts\nLF:10\nLH:5\nend_of_record\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inventory := detectTesting(context.Background(), tempDir, scanner.NewTestMatcher(tempDir, scanner.TestRules{}))
+
+	if len(inventory.Frameworks) != 2 {
+		t.Fatalf("Expected go test and vitest, got %+v", inventory.Frameworks)
+	}
+	if vitest := inventory.Frameworks[1]; vitest.Name != "vitest" || vitest.Command != "cd web && npm test" {
+		t.Errorf("Unexpected vitest framework: %+v", vitest)
+	}
+
+	modules := map[string]int{}
+	for _, module := range inventory.Modules {
+		modules[module.Path] = module.Files
+	}
+	if modules["internal/a"] != 2 || modules["cmd/x"] != 1 || modules["web/src"] != 1 {
+		t.Errorf("Unexpected module counts: %v", modules)
+	}
+
+	coverage := map[string]float64{}
+	for _, c := range inventory.Coverage {
+		coverage[c.Format] = c.Percent
+	}
+	if math.Abs(coverage["go"]-75) > 0.01 || math.Abs(coverage["lcov"]-50) > 0.01 {
+		t.Errorf("Unexpected coverage: %+v", inventory.Coverage)
+	}
+}
@@ -0,0 +1,103 @@
+package detect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+// Todo is a TODO, FIXME, HACK or XXX comment.
+type Todo struct {
+	Kind string
+	File string
+	Line int
+	Text string
+	// Owner is the name in a TODO(name) comment.
+	Owner string
+	// Author and Date are from git blame: who last changed the line, and
+	// when. They are empty outside git and for uncommitted lines.
+	Author string
+	Date   string
+}
+
+// todoKinds are ranked: fixes and hacks come before plain TODOs.
+var todoKinds = []string{"FIXME", "HACK", "XXX", "TODO"}
+
+// todoComment matches a marker right after a comment token, so identifiers
+// and strings mentioning TODO are not picked up.
+var todoComment = regexp.MustCompile(`(?://|#|/\*|\*|--|<!--)\s*(FIXME|HACK|XXX|TODO)\b(?:\(([^)]*)\))?[:\s-]*(.*)`)
+
+// maxBlamedTodos caps the TODOs attributed with git blame, which reads the
+// history of each file involved.
+const maxBlamedTodos = 50
+
+func detectTodos(file scanner.FileInfo, result *Result) {
+	// Markdown headings and config keys would match too; only code comments
+	// are mined.
+	if !scanner.IsCodeLanguage(file.Language) {
+		return
+	}
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return
+	}
+	result.Todos = append(result.Todos, parseTodos(filepath.ToSlash(file.RelativePath), string(content))...)
+}
+
+func parseTodos(rel, content string) []Todo {
+	todos := []Todo{}
+	for i, line := range strings.Split(content, "\n") {
+		match := todoComment.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		text := strings.TrimSpace(match[3])
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(text, "*/"), "-->"))
+		todos = append(todos, Todo{
+			Kind:  match[1],
+			File:  rel,
+			Line:  i + 1,
+			Text:  util.SafeTruncate(text, 160),
+			Owner: strings.TrimSpace(match[2]),
+		})
+	}
+	return todos
+}
+
+// sortTodos orders todos by kind, keeping file order within a kind.
+func sortTodos(todos []Todo) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		return slices.Index(todoKinds, todos[i].Kind) < slices.Index(todoKinds, todos[j].Kind)
+	})
+}
+
+// attributeTodos fills in Author and Date for the first todos with git
+// blame.
+func attributeTodos(ctx context.Context, repoPath string, todos []Todo) {
+	blames := map[string][]util.BlameLine{}
+	for i := range todos[:min(maxBlamedTodos, len(todos))] {
+		if ctx.Err() != nil {
+			return
+		}
+		todo := &todos[i]
+		lines, ok := blames[todo.File]
+		if !ok {
+			// Files outside git, or not committed yet, stay unattributed.
+			lines, _ = util.GitBlame(ctx, filepath.Join(repoPath, filepath.FromSlash(todo.File)))
+			blames[todo.File] = lines
+		}
+		if todo.Line <= len(lines) {
+			todo.Author, todo.Date = lines[todo.Line-1].Author, lines[todo.Line-1].Date
+			if todo.Author == "" {
+				todo.Date = ""
+			}
+		}
+	}
+}
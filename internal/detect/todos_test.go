@@ -0,0 +1,43 @@
+package detect
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTodos(t *testing.T) {
+	content := `package orders
+
+// TODO(ana): retry failed refunds
+func refund() {
+	todo := "TODO: not a comment"
+	x := 1 // FIXME - overflows on large carts
+	/* HACK: skip validation until v2 */
+}
+
+# XXX legacy path
+	 * TODO
+var TODOs = []string{}
+// TODOS are not markers
+`
+	want := []Todo{
+		{Kind: "TODO", File: "orders.go", Line: 3, Text: "retry failed refunds", Owner: "ana"},
+		{Kind: "FIXME", File: "orders.go", Line: 6, Text: "overflows on large carts"},
+		{Kind: "HACK", File: "orders.go", Line: 7, Text: "skip validation until v2"},
+		{Kind: "XXX", File: "orders.go", Line: 10, Text: "legacy path"},
+		{Kind: "TODO", File: "orders.go", Line: 11},
+	}
+	got := parseTodos("orders.go", content)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseTodos() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	sortTodos(got)
+	kinds := []string{}
+	for _, todo := range got {
+		kinds = append(kinds, todo.Kind)
+	}
+	if want := []string{"FIXME", "HACK", "XXX", "TODO", "TODO"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("sortTodos() kinds = %v, want %v", kinds, want)
+	}
+}
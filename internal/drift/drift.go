@@ -0,0 +1,307 @@
+// Package drift compares two analyses of a repository and describes how its
+// architecture changed between them, for release notes.
+package drift
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// Snapshot is one analysis to compare. Label names it in the output, such as
+// the artifact file or the git ref it was taken from.
+type Snapshot struct {
+	Label     string
+	Commit    string
+	Scan      *scanner.Result
+	Detection *detect.Result
+}
+
+type Result struct {
+	Old, New Snapshot
+
+	AddedModules   []string
+	RemovedModules []string
+
+	AddedEndpoints   []string
+	RemovedEndpoints []string
+
+	AddedModels   []string
+	RemovedModels []string
+	ChangedModels []ModelChange
+
+	AddedFrameworks   []string
+	RemovedFrameworks []string
+
+	NewFindings      []detect.Finding
+	ResolvedFindings []detect.Finding
+}
+
+// ModelChange lists the fields added to and removed from a model present in
+// both snapshots.
+type ModelChange struct {
+	Name    string
+	Added   []string
+	Removed []string
+}
+
+// Compare describes what changed from before to after.
+func Compare(before, after Snapshot) *Result {
+	result := &Result{Old: before, New: after}
+
+	result.AddedModules, result.RemovedModules = difference(modules(before.Scan), modules(after.Scan))
+	result.AddedEndpoints, result.RemovedEndpoints = difference(endpoints(before.Detection), endpoints(after.Detection))
+	result.AddedFrameworks, result.RemovedFrameworks = difference(frameworks(before.Detection), frameworks(after.Detection))
+
+	oldModels, newModels := models(before.Detection), models(after.Detection)
+	result.AddedModels, result.RemovedModels = difference(keys(oldModels), keys(newModels))
+	for _, name := range keys(newModels) {
+		fields, ok := oldModels[name]
+		if !ok {
+			continue
+		}
+		added, removed := difference(fields, newModels[name])
+		if len(added) > 0 || len(removed) > 0 {
+			result.ChangedModels = append(result.ChangedModels, ModelChange{Name: name, Added: added, Removed: removed})
+		}
+	}
+
+	oldFindings, newFindings := findings(before.Detection), findings(after.Detection)
+	for _, key := range keys(newFindings) {
+		if _, ok := oldFindings[key]; !ok {
+			result.NewFindings = append(result.NewFindings, newFindings[key])
+		}
+	}
+	for _, key := range keys(oldFindings) {
+		if _, ok := newFindings[key]; !ok {
+			result.ResolvedFindings = append(result.ResolvedFindings, oldFindings[key])
+		}
+	}
+
+	return result
+}
+
+// Empty reports whether nothing architectural changed.
+func (r *Result) Empty() bool {
+	return len(r.AddedModules)+len(r.RemovedModules)+
+		len(r.AddedEndpoints)+len(r.RemovedEndpoints)+
+		len(r.AddedModels)+len(r.RemovedModels)+len(r.ChangedModels)+
+		len(r.AddedFrameworks)+len(r.RemovedFrameworks)+
+		len(r.NewFindings)+len(r.ResolvedFindings) == 0
+}
+
+// Markdown renders the changes as a changelog section.
+func (r *Result) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Architecture Changes\n\n")
+	b.WriteString(fmt.Sprintf("Comparing %s with %s.\n\n", describe(r.Old), describe(r.New)))
+
+	b.WriteString("| | Before | After | Change |\n")
+	b.WriteString("|---|---|---|---|\n")
+	writeCount(&b, "Files", totalFiles(r.Old.Scan), totalFiles(r.New.Scan))
+	writeCount(&b, "Lines", totalLines(r.Old.Scan), totalLines(r.New.Scan))
+	writeCount(&b, "Modules", len(modules(r.Old.Scan)), len(modules(r.New.Scan)))
+	writeCount(&b, "Endpoints", len(endpoints(r.Old.Detection)), len(endpoints(r.New.Detection)))
+	writeCount(&b, "Models", len(models(r.Old.Detection)), len(models(r.New.Detection)))
+	writeCount(&b, "Findings", len(findings(r.Old.Detection)), len(findings(r.New.Detection)))
+	b.WriteString("\n")
+
+	if r.Empty() {
+		b.WriteString("No architectural changes.\n")
+		return b.String()
+	}
+
+	writeSection(&b, "Modules", r.AddedModules, r.RemovedModules)
+	writeSection(&b, "Endpoints", r.AddedEndpoints, r.RemovedEndpoints)
+
+	changed := []string{}
+	for _, model := range r.ChangedModels {
+		parts := []string{}
+		for _, field := range model.Added {
+			parts = append(parts, "+"+field)
+		}
+		for _, field := range model.Removed {
+			parts = append(parts, "-"+field)
+		}
+		changed = append(changed, fmt.Sprintf("`%s` (%s)", model.Name, strings.Join(parts, ", ")))
+	}
+	if len(r.AddedModels)+len(r.RemovedModels)+len(changed) > 0 {
+		b.WriteString("## Models\n")
+		writeItems(&b, "Added", r.AddedModels)
+		writeItems(&b, "Removed", r.RemovedModels)
+		for _, line := range changed {
+			b.WriteString("- Changed: " + line + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	writeSection(&b, "Frameworks", r.AddedFrameworks, r.RemovedFrameworks)
+
+	if len(r.NewFindings)+len(r.ResolvedFindings) > 0 {
+		b.WriteString("## Risks\n")
+		for _, finding := range r.NewFindings {
+			b.WriteString(fmt.Sprintf("- New (%s): %s — %s\n", finding.Severity, finding.Message, location(finding)))
+		}
+		for _, finding := range r.ResolvedFindings {
+			b.WriteString(fmt.Sprintf("- Resolved (%s): %s — %s\n", finding.Severity, finding.Message, location(finding)))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func describe(s Snapshot) string {
+	if s.Commit == "" || s.Commit == "unknown" {
+		return "`" + s.Label + "`"
+	}
+	commit := s.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	return fmt.Sprintf("`%s` (%s)", s.Label, commit)
+}
+
+func writeCount(b *strings.Builder, name string, before, after int) {
+	b.WriteString(fmt.Sprintf("| %s | %d | %d | %+d |\n", name, before, after, after-before))
+}
+
+func writeSection(b *strings.Builder, title string, added, removed []string) {
+	if len(added)+len(removed) == 0 {
+		return
+	}
+	b.WriteString("## " + title + "\n")
+	writeItems(b, "Added", added)
+	writeItems(b, "Removed", removed)
+	b.WriteString("\n")
+}
+
+func writeItems(b *strings.Builder, label string, items []string) {
+	for _, item := range items {
+		b.WriteString(fmt.Sprintf("- %s: `%s`\n", label, item))
+	}
+}
+
+func location(finding detect.Finding) string {
+	if finding.Line > 0 {
+		return fmt.Sprintf("`%s:%d`", finding.File, finding.Line)
+	}
+	return "`" + finding.File + "`"
+}
+
+// modules are the directories holding non-test source files.
+func modules(scan *scanner.Result) []string {
+	if scan == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, file := range scan.Files {
+		if file.IsTest {
+			continue
+		}
+		if dir := path.Dir(strings.ReplaceAll(file.RelativePath, "\\", "/")); dir != "." {
+			seen[dir] = true
+		}
+	}
+	return keys(seen)
+}
+
+func endpoints(detection *detect.Result) []string {
+	if detection == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, endpoint := range detection.Endpoints {
+		seen[strings.TrimSpace(endpoint.Method+" "+endpoint.Path)] = true
+	}
+	return keys(seen)
+}
+
+func frameworks(detection *detect.Result) []string {
+	if detection == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, framework := range detection.Frameworks {
+		if framework.Confidence >= detect.ConfidenceMedium {
+			seen[framework.Name] = true
+		}
+	}
+	return keys(seen)
+}
+
+func models(detection *detect.Result) map[string][]string {
+	byName := make(map[string][]string)
+	if detection == nil {
+		return byName
+	}
+	for _, model := range detection.Models {
+		fields := append([]string{}, model.Fields...)
+		sort.Strings(fields)
+		byName[model.Name] = fields
+	}
+	return byName
+}
+
+// findings are keyed without the line number so findings that merely moved
+// are not reported.
+func findings(detection *detect.Result) map[string]detect.Finding {
+	byKey := make(map[string]detect.Finding)
+	if detection == nil {
+		return byKey
+	}
+	for _, finding := range detection.Findings {
+		byKey[finding.Rule+"\x00"+finding.File+"\x00"+finding.Message] = finding
+	}
+	return byKey
+}
+
+func totalFiles(scan *scanner.Result) int {
+	if scan == nil {
+		return 0
+	}
+	return scan.TotalFiles
+}
+
+func totalLines(scan *scanner.Result) int {
+	if scan == nil {
+		return 0
+	}
+	return scan.TotalLines
+}
+
+// difference returns the values only in after and those only in before.
+func difference(before, after []string) (added, removed []string) {
+	inBefore := make(map[string]bool, len(before))
+	for _, value := range before {
+		inBefore[value] = true
+	}
+	inAfter := make(map[string]bool, len(after))
+	for _, value := range after {
+		inAfter[value] = true
+		if !inBefore[value] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range before {
+		if !inAfter[value] {
+			removed = append(removed, value)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
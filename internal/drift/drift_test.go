@@ -0,0 +1,85 @@
+package drift
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestCompare(t *testing.T) {
+	before := Snapshot{
+		Label: "v1.json",
+		Scan: &scanner.Result{Files: []scanner.FileInfo{
+			{RelativePath: "api/handler.go"},
+			{RelativePath: "legacy/old.go"},
+			{RelativePath: "main.go"},
+		}},
+		Detection: &detect.Result{
+			Endpoints: []detect.Endpoint{{Method: "GET", Path: "/users"}, {Method: "POST", Path: "/login"}},
+			Models:    []detect.Model{{Name: "User", Fields: []string{"ID", "Name"}}, {Name: "Session"}},
+			Findings:  []detect.Finding{{Severity: "high", Rule: "root-user", File: "Dockerfile", Line: 3, Message: "runs as root"}},
+		},
+	}
+	after := Snapshot{
+		Label: "v2.json",
+		Scan: &scanner.Result{Files: []scanner.FileInfo{
+			{RelativePath: "api/handler.go"},
+			{RelativePath: "billing/invoice.go"},
+			{RelativePath: "billing/invoice_test.go", IsTest: true},
+			{RelativePath: "main.go"},
+		}},
+		Detection: &detect.Result{
+			Endpoints: []detect.Endpoint{{Method: "GET", Path: "/users"}, {Method: "GET", Path: "/invoices"}},
+			Models:    []detect.Model{{Name: "User", Fields: []string{"Email", "ID"}}, {Name: "Invoice"}},
+			Findings: []detect.Finding{
+				{Severity: "high", Rule: "root-user", File: "Dockerfile", Line: 7, Message: "runs as root"},
+				{Severity: "medium", Rule: "latest-tag", File: "Dockerfile", Line: 1, Message: "unpinned base image"},
+			},
+		},
+	}
+
+	result := Compare(before, after)
+
+	checks := []struct {
+		name      string
+		got, want []string
+	}{
+		{"added modules", result.AddedModules, []string{"billing"}},
+		{"removed modules", result.RemovedModules, []string{"legacy"}},
+		{"added endpoints", result.AddedEndpoints, []string{"GET /invoices"}},
+		{"removed endpoints", result.RemovedEndpoints, []string{"POST /login"}},
+		{"added models", result.AddedModels, []string{"Invoice"}},
+		{"removed models", result.RemovedModels, []string{"Session"}},
+	}
+	for _, check := range checks {
+		if !reflect.DeepEqual(check.got, check.want) {
+			t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+		}
+	}
+
+	wantChange := []ModelChange{{Name: "User", Added: []string{"Email"}, Removed: []string{"Name"}}}
+	if !reflect.DeepEqual(result.ChangedModels, wantChange) {
+		t.Errorf("ChangedModels = %+v, want %+v", result.ChangedModels, wantChange)
+	}
+	if len(result.NewFindings) != 1 || result.NewFindings[0].Rule != "latest-tag" || len(result.ResolvedFindings) != 0 {
+		t.Errorf("Expected only the unpinned image as a new finding, got %+v / %+v", result.NewFindings, result.ResolvedFindings)
+	}
+
+	markdown := result.Markdown()
+	for _, want := range []string{"| Endpoints | 2 | 2 | +0 |", "- Added: `billing`", "- Changed: `User` (+Email, -Name)", "- New (medium): unpinned base image"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestCompareUnchanged(t *testing.T) {
+	snapshot := Snapshot{Label: "report.json", Scan: &scanner.Result{}, Detection: &detect.Result{}}
+	result := Compare(snapshot, snapshot)
+	if !result.Empty() || !strings.Contains(result.Markdown(), "No architectural changes.") {
+		t.Errorf("Expected no changes, got %+v", result)
+	}
+}
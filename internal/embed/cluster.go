@@ -0,0 +1,251 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Clustering limits. Below minSilhouette the documents show no clear
+// grouping and no areas are reported.
+const (
+	MinDocuments  = 4
+	maxAreas      = 8
+	maxTerms      = 3
+	minSilhouette = 0.1
+)
+
+// Document is a text to cluster, such as a file's summary.
+type Document struct {
+	Path string
+	Text string
+	// Vector is Text's embedding when it was computed earlier; Search
+	// embeds only documents without one.
+	Vector []float64
+}
+
+// Area is a group of files whose summaries are alike.
+type Area struct {
+	// Terms are the words most distinctive of the area's documents.
+	Terms []string `json:"terms"`
+	Files []string `json:"files"`
+	// Dirs are the directories of Files. More than one means the area cuts
+	// across the directory structure.
+	Dirs []string `json:"dirs"`
+}
+
+func (a Area) CrossCutting() bool {
+	return len(a.Dirs) > 1
+}
+
+// Areas embeds docs and clusters them with k-means, keeping the number of
+// clusters whose silhouette is best. Single-file clusters are dropped. It
+// returns nil for fewer than MinDocuments docs or without clear clusters.
+func Areas(ctx context.Context, provider Provider, docs []Document) ([]Area, error) {
+	if len(docs) < MinDocuments {
+		return nil, nil
+	}
+	docs = append([]Document(nil), docs...)
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Path < docs[j].Path })
+
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
+	}
+	vectors, err := provider.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+	if len(vectors) != len(docs) {
+		return nil, fmt.Errorf("embedding failed: got %d vectors for %d documents", len(vectors), len(docs))
+	}
+
+	var best []int
+	bestScore := minSilhouette
+	for k := 2; k <= min(maxAreas, len(docs)/2); k++ {
+		assignment := kMeans(vectors, k)
+		if score := silhouette(vectors, assignment, k); score > bestScore {
+			best, bestScore = assignment, score
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	groups := map[int][]int{}
+	for i, cluster := range best {
+		groups[cluster] = append(groups[cluster], i)
+	}
+	areas := []Area{}
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		area := Area{Terms: distinctiveTerms(docs, members)}
+		dirs := map[string]bool{}
+		for _, i := range members {
+			file := filepath.ToSlash(docs[i].Path)
+			area.Files = append(area.Files, file)
+			dirs[path.Dir(file)] = true
+		}
+		for dir := range dirs {
+			area.Dirs = append(area.Dirs, dir)
+		}
+		sort.Strings(area.Dirs)
+		areas = append(areas, area)
+	}
+	if len(areas) < 2 {
+		return nil, nil
+	}
+	sort.Slice(areas, func(i, j int) bool {
+		if len(areas[i].Files) != len(areas[j].Files) {
+			return len(areas[i].Files) > len(areas[j].Files)
+		}
+		return areas[i].Files[0] < areas[j].Files[0]
+	})
+	return areas, nil
+}
+
+// kMeans clusters unit vectors by cosine similarity. The first centroid is
+// the first vector and each next one the vector farthest from those chosen,
+// so results do not vary between runs.
+func kMeans(vectors [][]float64, k int) []int {
+	centroids := [][]float64{vectors[0]}
+	for len(centroids) < k {
+		farthest, farthestSimilarity := 0, math.Inf(1)
+		for i, vector := range vectors {
+			nearest := math.Inf(-1)
+			for _, centroid := range centroids {
+				nearest = max(nearest, dot(vector, centroid))
+			}
+			if nearest < farthestSimilarity {
+				farthest, farthestSimilarity = i, nearest
+			}
+		}
+		centroids = append(centroids, vectors[farthest])
+	}
+
+	assignment := make([]int, len(vectors))
+	for iteration := 0; iteration < 50; iteration++ {
+		changed := iteration == 0
+		for i, vector := range vectors {
+			nearest, nearestSimilarity := 0, math.Inf(-1)
+			for c, centroid := range centroids {
+				if similarity := dot(vector, centroid); similarity > nearestSimilarity {
+					nearest, nearestSimilarity = c, similarity
+				}
+			}
+			if assignment[i] != nearest {
+				assignment[i] = nearest
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		for c := range centroids {
+			sum := make([]float64, len(vectors[0]))
+			members := 0
+			for i, vector := range vectors {
+				if assignment[i] != c {
+					continue
+				}
+				members++
+				for d, value := range vector {
+					sum[d] += value
+				}
+			}
+			// An empty cluster keeps its centroid.
+			if members > 0 {
+				centroids[c] = normalize(sum)
+			}
+		}
+	}
+	return assignment
+}
+
+// silhouette is the mean silhouette coefficient of the clustering, with
+// cosine distance: near 1 when documents sit well inside their clusters.
+func silhouette(vectors [][]float64, assignment []int, k int) float64 {
+	total := 0.0
+	for i := range vectors {
+		distances := make([]float64, k)
+		counts := make([]int, k)
+		for j := range vectors {
+			if i == j {
+				continue
+			}
+			distances[assignment[j]] += 1 - dot(vectors[i], vectors[j])
+			counts[assignment[j]]++
+		}
+		own := assignment[i]
+		if counts[own] == 0 {
+			continue
+		}
+		a := distances[own] / float64(counts[own])
+		b := math.Inf(1)
+		for c := range k {
+			if c != own && counts[c] > 0 {
+				b = min(b, distances[c]/float64(counts[c]))
+			}
+		}
+		if math.IsInf(b, 1) || max(a, b) == 0 {
+			continue
+		}
+		total += (b - a) / max(a, b)
+	}
+	return total / float64(len(vectors))
+}
+
+// distinctiveTerms names an area by the words most of its documents share
+// that are rare elsewhere.
+func distinctiveTerms(docs []Document, members []int) []string {
+	frequency := map[string]int{}
+	documentWords := make([]map[string]bool, len(docs))
+	for i, doc := range docs {
+		documentWords[i] = map[string]bool{}
+		for _, word := range words(doc.Text) {
+			if !documentWords[i][word] {
+				documentWords[i][word] = true
+				frequency[word]++
+			}
+		}
+	}
+
+	inArea := map[string]int{}
+	for _, i := range members {
+		for word := range documentWords[i] {
+			inArea[word]++
+		}
+	}
+	type scored struct {
+		word  string
+		score float64
+	}
+	candidates := []scored{}
+	for word, count := range inArea {
+		if count < 2 {
+			continue
+		}
+		coverage := float64(count) / float64(len(members))
+		score := coverage * coverage * math.Log(float64(len(docs))/float64(frequency[word]))
+		if score > 0 {
+			candidates = append(candidates, scored{word, score})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].word < candidates[j].word
+	})
+
+	terms := []string{}
+	for _, candidate := range candidates[:min(maxTerms, len(candidates))] {
+		terms = append(terms, candidate.word)
+	}
+	return terms
+}
@@ -0,0 +1,79 @@
+// Package embed turns texts into vectors and clusters them, to find
+// functional areas that cut across the directory structure.
+package embed
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+)
+
+// Provider embeds texts.
+type Provider interface {
+	// Embed returns one unit-length vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// Providers are the names New accepts.
+var Providers = []string{"anthropic", "openai", "local"}
+
+// New returns the named provider. Anthropic's API has no embeddings
+// endpoint, so "anthropic" uses Voyage AI, the embeddings service Anthropic
+// recommends, with VOYAGE_API_KEY. "openai" needs OPENAI_API_KEY; "local"
+// runs offline.
+func New(name string) (Provider, error) {
+	switch name {
+	case "anthropic":
+		return newHTTPProvider(voyageEndpoint)
+	case "openai":
+		return newHTTPProvider(openAIEndpoint)
+	case "local":
+		return NewLocalProvider(), nil
+	}
+	return nil, fmt.Errorf("unknown embeddings provider %q; known providers: %s", name, strings.Join(Providers, ", "))
+}
+
+// KeyEnv returns the environment variable holding the named provider's API
+// key, or "" when it needs none.
+func KeyEnv(name string) string {
+	if e, ok := endpoints[name]; ok {
+		return e.keyEnv
+	}
+	return ""
+}
+
+// Host returns the host the named provider calls, or "" when it runs
+// offline.
+func Host(name string) string {
+	if e, ok := endpoints[name]; ok {
+		if parsed, err := url.Parse(e.url); err == nil {
+			return parsed.Hostname()
+		}
+	}
+	return ""
+}
+
+func normalize(vector []float64) []float64 {
+	norm := 0.0
+	for _, value := range vector {
+		norm += value * value
+	}
+	if norm == 0 {
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range min(len(a), len(b)) {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
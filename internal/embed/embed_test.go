@@ -0,0 +1,117 @@
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAreas(t *testing.T) {
+	docs := []Document{
+		{Path: "api/login.go", Text: "Checks passwords and issues session tokens for authenticated users."},
+		{Path: "web/session.go", Text: "Stores session tokens in cookies and rejects expired authenticated sessions."},
+		{Path: "worker/tokens.go", Text: "Rotates signing keys for session tokens of authenticated users."},
+		{Path: "api/invoice.go", Text: "Creates invoices, applies tax rates and totals invoice line items."},
+		{Path: "billing/tax.go", Text: "Computes tax rates per region for invoice line items."},
+		{Path: "billing/ledger.go", Text: "Records invoice payments and tax in the ledger."},
+	}
+
+	areas, err := Areas(context.Background(), NewLocalProvider(), docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(areas) != 2 {
+		t.Fatalf("Areas() = %+v, want 2 areas", areas)
+	}
+	want := []Area{
+		{Terms: []string{"invoice", "tax", "item"}, Files: []string{"api/invoice.go", "billing/ledger.go", "billing/tax.go"}, Dirs: []string{"api", "billing"}},
+		{Terms: []string{"authenticated", "session", "token"}, Files: []string{"api/login.go", "web/session.go", "worker/tokens.go"}, Dirs: []string{"api", "web", "worker"}},
+	}
+	if !reflect.DeepEqual(areas, want) {
+		t.Errorf("Areas() = %+v, want %+v", areas, want)
+	}
+	if !areas[1].CrossCutting() {
+		t.Error("Expected the session area to cut across directories")
+	}
+
+	same := []Document{{Path: "a.go", Text: "placeholder"}, {Path: "b.go", Text: "placeholder"}, {Path: "c.go", Text: "placeholder"}, {Path: "d.go", Text: "placeholder"}}
+	if areas, err := Areas(context.Background(), NewLocalProvider(), same); err != nil || areas != nil {
+		t.Errorf("Areas(identical) = %+v, %v; want no areas", areas, err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	docs := []Document{
+		{Path: "api/login.go", Text: "Checks passwords and issues session tokens."},
+		{Path: "billing/tax.go", Text: "Computes tax rates per region."},
+		{Path: "web/session.go", Text: "Stores session tokens in cookies."},
+	}
+	matches, err := Search(context.Background(), NewLocalProvider(), "Where are session tokens stored?", docs, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, match := range matches {
+		got = append(got, match.Path)
+	}
+	if want := []string{"web/session.go", "api/login.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Search() = %v, want %v", got, want)
+	}
+
+	// Precomputed vectors rank the same as embedding the texts again.
+	vectors, err := NewLocalProvider().Embed(context.Background(), []string{docs[0].Text, docs[2].Text})
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs[0].Vector, docs[2].Vector = vectors[0], vectors[1]
+	again, err := Search(context.Background(), NewLocalProvider(), "Where are session tokens stored?", docs, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, matches) {
+		t.Errorf("Search() with vectors = %v, want %v", again, matches)
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var request struct {
+			Model     string   `json:"model"`
+			Input     []string `json:"input"`
+			InputType string   `json:"input_type"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Model != "m" || request.InputType != "document" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		// Answer out of order, as the APIs may.
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,2]},{"index":0,"embedding":[3,4]}]}`))
+	}))
+	defer server.Close()
+
+	provider := &httpProvider{
+		endpoint: endpoint{url: server.URL, model: "m", extra: map[string]any{"input_type": "document"}},
+		apiKey:   "key",
+		client:   server.Client(),
+	}
+	vectors, err := provider.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]float64{{0.6, 0.8}, {0, 1}}; !reflect.DeepEqual(vectors, want) {
+		t.Errorf("Embed() = %v, want %v", vectors, want)
+	}
+
+	provider.apiKey = "wrong"
+	if _, err := provider.Embed(context.Background(), []string{"a"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Embed() error = %v, want the API error", err)
+	}
+}
@@ -0,0 +1,121 @@
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// batchSize bounds the texts sent in one request.
+const batchSize = 96
+
+// endpoint describes an embeddings API taking {"model", "input"} and
+// answering {"data": [{"index", "embedding"}]}, as OpenAI's and Voyage AI's
+// do.
+type endpoint struct {
+	url    string
+	model  string
+	keyEnv string
+	// extra fields of every request body.
+	extra map[string]any
+}
+
+var (
+	voyageEndpoint = endpoint{
+		url:    "https://api.voyageai.com/v1/embeddings",
+		model:  "voyage-3-lite",
+		keyEnv: "VOYAGE_API_KEY",
+		extra:  map[string]any{"input_type": "document"},
+	}
+	openAIEndpoint = endpoint{
+		url:    "https://api.openai.com/v1/embeddings",
+		model:  "text-embedding-3-small",
+		keyEnv: "OPENAI_API_KEY",
+	}
+	endpoints = map[string]endpoint{"anthropic": voyageEndpoint, "openai": openAIEndpoint}
+)
+
+type httpProvider struct {
+	endpoint
+	apiKey string
+	client *http.Client
+}
+
+func newHTTPProvider(e endpoint) (Provider, error) {
+	apiKey := os.Getenv(e.keyEnv)
+	if apiKey == "" {
+		return nil, fmt.Errorf("%s not set", e.keyEnv)
+	}
+	return &httpProvider{endpoint: e, apiKey: apiKey, client: &http.Client{Timeout: 60 * time.Second}}, nil
+}
+
+func (p *httpProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += batchSize {
+		batch, err := p.embedBatch(ctx, texts[start:min(start+batchSize, len(texts))])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (p *httpProvider) embedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	requestBody := map[string]any{"model": p.model, "input": texts}
+	for key, value := range p.extra {
+		requestBody[key] = value
+	}
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings API returned %d vectors for %d texts", len(response.Data), len(texts))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings API returned index %d for %d texts", item.Index, len(texts))
+		}
+		vectors[item.Index] = normalize(item.Embedding)
+	}
+	return vectors, nil
+}
@@ -0,0 +1,80 @@
+package embed
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// localDimensions is the size of local vectors.
+const localDimensions = 512
+
+// stopWords carry no meaning about what code does.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"into": true, "its": true, "are": true, "was": true, "were": true, "has": true, "have": true,
+	"which": true, "when": true, "each": true, "all": true, "any": true, "not": true, "but": true,
+	"can": true, "via": true, "uses": true, "using": true, "used": true, "file": true, "files": true,
+	"function": true, "functions": true, "module": true, "package": true, "returns": true,
+	"provides": true, "defines": true, "handles": true, "other": true, "such": true, "also": true,
+}
+
+// LocalProvider embeds texts offline by hashing their words into a fixed
+// number of dimensions. It finds texts that share vocabulary, not synonyms.
+type LocalProvider struct{}
+
+func NewLocalProvider() *LocalProvider {
+	return &LocalProvider{}
+}
+
+func (p *LocalProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		counts := map[string]int{}
+		for _, word := range words(text) {
+			counts[word]++
+		}
+		vector := make([]float64, localDimensions)
+		for word, count := range counts {
+			h := fnv.New64a()
+			h.Write([]byte(word))
+			sum := h.Sum64()
+			sign := 1.0
+			if sum&(1<<63) != 0 {
+				sign = -1
+			}
+			vector[sum%localDimensions] += sign * (1 + math.Log(float64(count)))
+		}
+		vectors[i] = normalize(vector)
+	}
+	return vectors, nil
+}
+
+// words returns the meaningful lowercase words of text, splitting
+// identifiers at case changes and dropping a plural "s".
+func words(text string) []string {
+	result := []string{}
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		start := 0
+		runes := []rune(field)
+		for i := 1; i <= len(runes); i++ {
+			if i < len(runes) && !(unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1])) {
+				continue
+			}
+			word := strings.ToLower(string(runes[start:i]))
+			start = i
+			if len(word) > 4 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+				word = word[:len(word)-1]
+			}
+			if len(word) >= 3 && !stopWords[word] {
+				result = append(result, word)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// Match is a document ranked against a query.
+type Match struct {
+	Path  string  `json:"path"`
+	Score float64 `json:"score"`
+}
+
+// Search embeds query with docs and returns the limit docs most similar to
+// it, best first. Docs unrelated to the query are left out. Docs with a
+// Vector are not embedded again; it must come from the same provider.
+func Search(ctx context.Context, provider Provider, query string, docs []Document, limit int) ([]Match, error) {
+	if len(docs) == 0 {
+		return []Match{}, nil
+	}
+	texts := []string{query}
+	for _, doc := range docs {
+		if doc.Vector == nil {
+			texts = append(texts, doc.Text)
+		}
+	}
+	vectors, err := provider.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedding failed: got %d vectors for %d texts", len(vectors), len(texts))
+	}
+
+	matches := []Match{}
+	next := 1
+	for _, doc := range docs {
+		vector := doc.Vector
+		if vector == nil {
+			vector = vectors[next]
+			next++
+		}
+		if score := dot(vectors[0], vector); score > 0 {
+			matches = append(matches, Match{Path: filepath.ToSlash(doc.Path), Score: score})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Path < matches[j].Path
+	})
+	return matches[:min(limit, len(matches))], nil
+}
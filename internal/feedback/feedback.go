@@ -0,0 +1,140 @@
+// Package feedback keeps reviewers' verdicts on generated summaries, so later
+// runs over the same repository reuse their corrections and show the model
+// what was rejected.
+package feedback
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+type Rating string
+
+const (
+	Good Rating = "good"
+	Bad  Rating = "bad"
+)
+
+// Entry is one verdict. Hash is the content hash of the summarized file, so a
+// correction is only reused while the file is unchanged; it is empty for
+// module, architecture and quickstart summaries.
+type Entry struct {
+	Type       llm.SummaryType `json:"type"`
+	Key        string          `json:"key,omitempty"`
+	Hash       string          `json:"hash,omitempty"`
+	Rating     Rating          `json:"rating"`
+	Summary    string          `json:"summary"`
+	Correction string          `json:"correction,omitempty"`
+	Note       string          `json:"note,omitempty"`
+}
+
+// Store holds the feedback for one repository, oldest entry first.
+type Store struct {
+	path    string
+	Entries []Entry `json:"entries"`
+}
+
+// Path returns where feedback for the repository identified by repo (its
+// path, or its URL for clones) is kept under cacheDir.
+func Path(cacheDir, repo string) string {
+	if abs, err := filepath.Abs(repo); err == nil && !isURL(repo) {
+		repo = abs
+	}
+	sum := sha256.Sum256([]byte(repo))
+	return filepath.Join(cacheDir, "feedback", hex.EncodeToString(sum[:8])+".json")
+}
+
+func isURL(repo string) bool {
+	return strings.Contains(repo, "://") || strings.HasPrefix(repo, "git@")
+}
+
+// Load reads the store at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, Entries: []Entry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feedback: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Record adds entry, replacing an earlier verdict on the same summary.
+func (s *Store) Record(entry Entry) {
+	if s == nil {
+		return
+	}
+	kept := s.Entries[:0]
+	for _, existing := range s.Entries {
+		if existing.Type != entry.Type || existing.Key != entry.Key {
+			kept = append(kept, existing)
+		}
+	}
+	s.Entries = append(kept, entry)
+}
+
+// Save writes the store back to the path it was loaded from.
+func (s *Store) Save() error {
+	if s == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create feedback directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write feedback: %w", err)
+	}
+	return nil
+}
+
+// Correction returns the reviewer's replacement for the summary of the file
+// at key while its content hash is still hash.
+func (s *Store) Correction(key, hash string) (string, bool) {
+	if s == nil || hash == "" {
+		return "", false
+	}
+	for _, entry := range s.Entries {
+		if entry.Type == llm.SummaryTypeFile && entry.Key == key && entry.Hash == hash && entry.Correction != "" {
+			return entry.Correction, true
+		}
+	}
+	return "", false
+}
+
+// Examples returns up to limit rejected summaries of type with their
+// corrections or notes, most recent first, for use as few-shot corrections.
+func (s *Store) Examples(summaryType llm.SummaryType, limit int) []llm.Example {
+	if s == nil {
+		return nil
+	}
+	examples := []llm.Example{}
+	for i := len(s.Entries) - 1; i >= 0 && len(examples) < limit; i-- {
+		entry := s.Entries[i]
+		if entry.Type != summaryType || entry.Rating != Bad || (entry.Correction == "" && entry.Note == "") {
+			continue
+		}
+		examples = append(examples, llm.Example{
+			Rejected:   entry.Summary,
+			Correction: entry.Correction,
+			Note:       entry.Note,
+		})
+	}
+	return examples
+}
@@ -0,0 +1,72 @@
+package feedback
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feedback", "repo.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.Record(Entry{Type: llm.SummaryTypeFile, Key: "main.go", Hash: "h1", Rating: Bad, Summary: "wrong", Correction: "right"})
+	store.Record(Entry{Type: llm.SummaryTypeModule, Key: "api", Rating: Bad, Summary: "vague", Note: "name the endpoints"})
+	store.Record(Entry{Type: llm.SummaryTypeModule, Key: "store", Rating: Good, Summary: "fine"})
+	store.Record(Entry{Type: llm.SummaryTypeFile, Key: "util.go", Hash: "h2", Rating: Bad, Summary: "too long", Correction: "short"})
+	store.Record(Entry{Type: llm.SummaryTypeFile, Key: "util.go", Hash: "h2", Rating: Bad, Summary: "too long", Correction: "shorter"})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Entries) != 4 {
+		t.Fatalf("Expected the second verdict on util.go to replace the first, got %d entries", len(loaded.Entries))
+	}
+
+	tests := []struct {
+		key, hash string
+		want      string
+		found     bool
+	}{
+		{"main.go", "h1", "right", true},
+		{"main.go", "changed", "", false},
+		{"util.go", "h2", "shorter", true},
+		{"other.go", "h1", "", false},
+	}
+	for _, tt := range tests {
+		got, found := loaded.Correction(tt.key, tt.hash)
+		if got != tt.want || found != tt.found {
+			t.Errorf("Correction(%s, %s) = %q, %v; want %q, %v", tt.key, tt.hash, got, found, tt.want, tt.found)
+		}
+	}
+
+	files := loaded.Examples(llm.SummaryTypeFile, 5)
+	if len(files) != 2 || files[0].Correction != "shorter" || files[1].Correction != "right" {
+		t.Errorf("Expected file examples most recent first, got %+v", files)
+	}
+	if got := loaded.Examples(llm.SummaryTypeFile, 1); len(got) != 1 {
+		t.Errorf("Expected the limit to apply, got %d examples", len(got))
+	}
+	modules := loaded.Examples(llm.SummaryTypeModule, 5)
+	if len(modules) != 1 || modules[0].Note != "name the endpoints" {
+		t.Errorf("Expected only the rejected module summary, got %+v", modules)
+	}
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	if Path("cache", dir) != Path("cache", dir+string(filepath.Separator)+".") {
+		t.Error("Expected equivalent paths to share a store")
+	}
+	if Path("cache", "https://example.com/a.git") == Path("cache", "https://example.com/b.git") {
+		t.Error("Expected different repositories to use different stores")
+	}
+}
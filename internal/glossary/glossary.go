@@ -0,0 +1,230 @@
+// Package glossary mines identifiers for the abbreviations and domain terms
+// a codebase uses without explaining.
+package glossary
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+const (
+	KindAbbreviation = "abbreviation"
+	KindTerm         = "term"
+)
+
+const (
+	// MaxTerms caps the terms Mine returns.
+	MaxTerms = 30
+	// minIdentifiers is how many distinct identifiers must use a term.
+	minIdentifiers = 2
+	// maxExamples caps the identifiers and files kept per term.
+	maxExamples = 5
+	maxFiles    = 3
+)
+
+// Term is an abbreviation or domain word recurring in identifiers, with the
+// identifiers and files that use it as context for a definition.
+type Term struct {
+	Term        string
+	Kind        string
+	Identifiers int
+	Examples    []string
+	Files       []string
+}
+
+var (
+	goDeclaration      = regexp.MustCompile(`(?m)^(?:package|type|func(?:\s*\([^)]*\))?)\s+(\w+)`)
+	genericDeclaration = regexp.MustCompile(`\b(?:class|interface|struct|enum|trait|record|def|function|fn|module)\s+(\w+)`)
+	wordPattern        = regexp.MustCompile(`[A-Z]+[a-z0-9]*|[a-z]+[0-9]*`)
+)
+
+var codeLanguages = map[string]bool{
+	"go": true, "python": true, "javascript": true, "typescript": true, "java": true, "kotlin": true,
+	"scala": true, "csharp": true, "ruby": true, "php": true, "rust": true, "swift": true, "dart": true,
+}
+
+// common are words every codebase uses; they need no definition.
+var common = toSet(`
+	abstract access action adapter add admin all and any api app append application apply apps arg args
+	array async attr auth author auto backend base batch bean bin body bool buf buffer build builder bundle byte
+	bytes cache calculate call callback can cfg change check child class clean cli client close cmd code codec
+	collect collection column command common component compute config conn connection const constant container
+	content context controller convert copy core count create crud csv ctx current custom data database date db
+	debug decode default define delete dep deps desc detail detect dev dict diff dir directory dispatch doc docs
+	document dom dto dump edit element emit empty encode end ensure entity entry enum env environment err error
+	errors escape event events exception exec execute exists export ext extract factory fetch field file files
+	filter find first fix flag float fmt form format from func function gen generate generic get global graph group
+	handle handler hash header helper helpers hook host html http https identify impl import index info init input
+	insert instance int interface internal io is item items iter iterator job join json key keys kind label last
+	layout len length level lib line lines link list listener load loader local lock log logger lookup main make
+	manager map mapper mask match max merge message meta method middleware min mock mod model models module msg
+	mutation name new next node normalize null num number obj object on open option options output page param
+	params parse parser path pattern payload pkg plugin pointer pool port post prefix print process props provider
+	proxy query queue read reader record ref register registry remove render repo report repository req request res
+	reset resolve resource resp response result results retry route router row rows rule run runner save scan schema
+	scope script search send serialize server service session set setting settings setup size slice sort source spec
+	split src start state static status store str stream string strip struct sub suffix sync table tag target task
+	temp template test tests text the time timeout tmp to token tool trim type types ui uid unit update uri url user
+	util utils uuid val validate validator value values var version view walk web with worker wrapper write writer
+	xml yaml
+`)
+
+// Mine collects identifiers declared in files (packages, types and
+// functions) along with detected table and model names, and returns the
+// words that recur among them, most used first. Words with no vowel and
+// all-caps acronyms are reported as abbreviations.
+func Mine(files []scanner.FileInfo, detection *detect.Result) []Term {
+	type usage struct {
+		spellings   map[string]int
+		identifiers map[string]bool
+		files       map[string]bool
+		acronym     bool
+	}
+	usages := map[string]*usage{}
+	add := func(identifier, file string) {
+		for _, word := range splitIdentifier(identifier) {
+			key := normalize(word)
+			if len(key) < 2 || common[key] || isNumeric(key) {
+				continue
+			}
+			u, ok := usages[key]
+			if !ok {
+				u = &usage{spellings: map[string]int{}, identifiers: map[string]bool{}, files: map[string]bool{}}
+				usages[key] = u
+			}
+			u.spellings[word]++
+			u.identifiers[identifier] = true
+			u.files[file] = true
+			if len(word) > 1 && strings.ToUpper(word) == word && !isNumeric(word) {
+				u.acronym = true
+			}
+		}
+	}
+
+	for _, file := range files {
+		if !codeLanguages[file.Language] || file.IsTest {
+			continue
+		}
+		rel := filepath.ToSlash(file.RelativePath)
+		if dir := path.Dir(rel); dir != "." {
+			add(path.Base(dir), rel)
+		}
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			continue
+		}
+		pattern := genericDeclaration
+		if file.Language == "go" {
+			pattern = goDeclaration
+		}
+		for _, match := range pattern.FindAllStringSubmatch(string(content), -1) {
+			add(match[1], rel)
+		}
+	}
+	if detection != nil {
+		for _, table := range detection.Tables {
+			add(table.Name, table.Source)
+		}
+		for _, model := range detection.Models {
+			add(model.Name, model.File)
+		}
+	}
+
+	terms := []Term{}
+	for key, u := range usages {
+		if len(u.identifiers) < minIdentifiers {
+			continue
+		}
+		kind := KindTerm
+		if u.acronym || !strings.ContainsAny(key, "aeiouy") {
+			kind = KindAbbreviation
+		} else if len(key) < 4 {
+			continue
+		}
+		// Spell the term as written most often in its singular form.
+		spelling := key
+		for word, count := range u.spellings {
+			if strings.ToLower(word) != key {
+				continue
+			}
+			if best := u.spellings[spelling]; count > best || (count == best && word < spelling) {
+				spelling = word
+			}
+		}
+		if u.acronym {
+			spelling = strings.ToUpper(key)
+		}
+		terms = append(terms, Term{
+			Term:        spelling,
+			Kind:        kind,
+			Identifiers: len(u.identifiers),
+			Examples:    firstSorted(u.identifiers, maxExamples),
+			Files:       firstSorted(u.files, maxFiles),
+		})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Identifiers != terms[j].Identifiers {
+			return terms[i].Identifiers > terms[j].Identifiers
+		}
+		return strings.ToLower(terms[i].Term) < strings.ToLower(terms[j].Term)
+	})
+	return terms[:min(MaxTerms, len(terms))]
+}
+
+// splitIdentifier splits camelCase, PascalCase, snake_case and kebab-case
+// identifiers into words, keeping acronyms such as the SKU of SKUPrice.
+func splitIdentifier(identifier string) []string {
+	words := []string{}
+	for _, match := range wordPattern.FindAllString(identifier, -1) {
+		// "SKUPrice" matches as one run; split before the last capital.
+		if upper := strings.IndexFunc(match, unicode.IsLower); upper > 2 {
+			words = append(words, match[:upper-1], match[upper-1:])
+			continue
+		}
+		words = append(words, match)
+	}
+	return words
+}
+
+// normalize lowercases word and folds simple plurals.
+func normalize(word string) string {
+	word = strings.ToLower(word)
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+func isNumeric(word string) bool {
+	return strings.TrimFunc(word, unicode.IsDigit) == ""
+}
+
+func firstSorted(set map[string]bool, n int) []string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values[:min(n, len(values))]
+}
+
+func toSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
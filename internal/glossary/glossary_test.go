@@ -0,0 +1,60 @@
+package glossary
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestMine(t *testing.T) {
+	tempDir := t.TempDir()
+	sources := map[string]string{
+		"ledger/txn.go":      "package ledger\n\ntype TxnBatch struct{}\n\nfunc (b *TxnBatch) PostTxn() {}\n\nfunc settleTxns() {}\n",
+		"ledger/sku.go":      "package ledger\n\ntype SKUPrice struct{}\n\nfunc LookupSKU() {}\n",
+		"web/cart.ts":        "export class CartSummary {}\nexport function addToCart() {}\n",
+		"ledger/txn_test.go": "package ledger\n\nfunc TestTxnOnly() {}\n",
+	}
+	files := []scanner.FileInfo{}
+	for name, content := range sources {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		language := "go"
+		if filepath.Ext(name) == ".ts" {
+			language = "typescript"
+		}
+		files = append(files, scanner.FileInfo{Path: path, RelativePath: name, Language: language, IsTest: name == "ledger/txn_test.go"})
+	}
+	detection := &detect.Result{Tables: []detect.Table{{Name: "ledger_entries", Source: "migrations/001.sql"}}}
+
+	got := []string{}
+	for _, term := range Mine(files, detection) {
+		got = append(got, term.Term+" "+term.Kind)
+	}
+	want := []string{"Txn abbreviation", "Cart term", "ledger term", "SKU abbreviation"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Mine() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := map[string][]string{
+		"SKUPrice":       {"SKU", "Price"},
+		"settleTxns":     {"settle", "Txns"},
+		"ledger_entries": {"ledger", "entries"},
+		"HTTPServer":     {"HTTP", "Server"},
+	}
+	for identifier, want := range tests {
+		if got := splitIdentifier(identifier); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitIdentifier(%q) = %q, want %q", identifier, got, want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package graph
+
+import (
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// Graph is a file-level import graph keyed by repository-relative path
+// (always slash-separated).
+type Graph struct {
+	Files      []string
+	imports    map[string][]string
+	importedBy map[string][]string
+	// goPackages maps each directory to its Go files.
+	goPackages map[string][]string
+}
+
+var jsExtensions = []string{"", ".ts", ".tsx", ".js", ".jsx", "/index.ts", "/index.js"}
+
+// Build resolves each file's imports to files in the same repository. module
+// is the Go module path from go.mod; it may be empty for non-Go repositories.
+func Build(files []scanner.FileInfo, module string) *Graph {
+	g := &Graph{
+		imports:    make(map[string][]string),
+		importedBy: make(map[string][]string),
+	}
+
+	byPath := make(map[string]bool)
+	goPackages := make(map[string][]string)
+	g.goPackages = goPackages
+	for _, file := range files {
+		rel := filepath.ToSlash(file.RelativePath)
+		g.Files = append(g.Files, rel)
+		byPath[rel] = true
+		if file.Language == "go" && strings.HasSuffix(rel, ".go") {
+			dir := path.Dir(rel)
+			goPackages[dir] = append(goPackages[dir], rel)
+		}
+	}
+	sort.Strings(g.Files)
+
+	for _, file := range files {
+		from := filepath.ToSlash(file.RelativePath)
+		targets := []string{}
+
+		for _, imp := range file.Imports {
+			switch file.Language {
+			case "go":
+				if module == "" || (imp != module && !strings.HasPrefix(imp, module+"/")) {
+					continue
+				}
+				dir := strings.TrimPrefix(strings.TrimPrefix(imp, module), "/")
+				if dir == "" {
+					dir = "."
+				}
+				targets = append(targets, goPackages[dir]...)
+
+			case "python":
+				modulePath := strings.ReplaceAll(strings.TrimLeft(imp, "."), ".", "/")
+				for _, candidate := range []string{modulePath + ".py", modulePath + "/__init__.py"} {
+					if byPath[candidate] {
+						targets = append(targets, candidate)
+						break
+					}
+				}
+
+			case "javascript", "typescript":
+				if !strings.HasPrefix(imp, ".") {
+					continue
+				}
+				base := path.Join(path.Dir(from), imp)
+				for _, ext := range jsExtensions {
+					if byPath[base+ext] {
+						targets = append(targets, base+ext)
+						break
+					}
+				}
+			}
+		}
+
+		for _, to := range targets {
+			if to == from || slices.Contains(g.imports[from], to) {
+				continue
+			}
+			g.imports[from] = append(g.imports[from], to)
+			g.importedBy[to] = append(g.importedBy[to], from)
+		}
+	}
+
+	for _, edges := range g.imports {
+		sort.Strings(edges)
+	}
+	for _, edges := range g.importedBy {
+		sort.Strings(edges)
+	}
+
+	return g
+}
+
+func (g *Graph) Imports(file string) []string {
+	return g.imports[filepath.ToSlash(file)]
+}
+
+func (g *Graph) ImportedBy(file string) []string {
+	return g.importedBy[filepath.ToSlash(file)]
+}
+
+// Dependents returns every file that transitively imports file, in
+// breadth-first order.
+func (g *Graph) Dependents(file string) []string {
+	return g.walk(filepath.ToSlash(file), g.importedBy)
+}
+
+// Dependencies returns every file transitively imported by file, in
+// breadth-first order.
+func (g *Graph) Dependencies(file string) []string {
+	return g.walk(filepath.ToSlash(file), g.imports)
+}
+
+// Unreachable returns the files that no root reaches through imports, in
+// path order. A Go file reaches the other files of its package.
+func (g *Graph) Unreachable(roots []string) []string {
+	reached := map[string]bool{}
+	queue := []string{}
+	visit := func(file string) {
+		if !reached[file] {
+			reached[file] = true
+			queue = append(queue, file)
+		}
+	}
+	for _, root := range roots {
+		visit(filepath.ToSlash(root))
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if strings.HasSuffix(current, ".go") {
+			for _, sibling := range g.goPackages[path.Dir(current)] {
+				visit(sibling)
+			}
+		}
+		for _, next := range g.imports[current] {
+			visit(next)
+		}
+	}
+
+	unreachable := []string{}
+	for _, file := range g.Files {
+		if !reached[file] {
+			unreachable = append(unreachable, file)
+		}
+	}
+	return unreachable
+}
+
+// Stop is one file of a reading order.
+type Stop struct {
+	File string
+	// From is the earlier stop that imports File; empty for a root.
+	From string
+}
+
+// ReadingOrder returns at most limit files to read, starting at roots and
+// following imports breadth-first, so every file comes after one that
+// imports it. The imports of a file are visited most imported first. A Go
+// package is one stop: the file named after its directory, or else its
+// first file, stands for the others. Roots not in the graph are skipped.
+func (g *Graph) ReadingOrder(roots []string, limit int) []Stop {
+	known := map[string]bool{}
+	for _, file := range g.Files {
+		known[file] = true
+	}
+	visited := map[string]bool{}
+	order := []Stop{}
+	visit := func(file, from string) {
+		if !known[file] || visited[file] || len(order) >= limit {
+			return
+		}
+		for _, member := range g.goPackage(file) {
+			visited[member] = true
+		}
+		order = append(order, Stop{File: file, From: from})
+	}
+	for _, root := range roots {
+		visit(filepath.ToSlash(root), "")
+	}
+	for i := 0; i < len(order) && len(order) < limit; i++ {
+		current := order[i].File
+		next := []string{}
+		for _, member := range g.goPackage(current) {
+			for _, file := range g.imports[member] {
+				if file = g.packageFile(file); !slices.Contains(next, file) {
+					next = append(next, file)
+				}
+			}
+		}
+		sort.SliceStable(next, func(a, b int) bool {
+			return len(g.importedBy[next[a]]) > len(g.importedBy[next[b]])
+		})
+		for _, file := range next {
+			visit(file, current)
+		}
+	}
+	return order
+}
+
+// goPackage returns the files of file's Go package, or just file when it
+// is not Go.
+func (g *Graph) goPackage(file string) []string {
+	if members := g.goPackages[path.Dir(file)]; strings.HasSuffix(file, ".go") && slices.Contains(members, file) {
+		return members
+	}
+	return []string{file}
+}
+
+// packageFile returns the file that stands for file's Go package.
+func (g *Graph) packageFile(file string) string {
+	members := g.goPackage(file)
+	dir := path.Dir(file)
+	if named := path.Join(dir, path.Base(dir)+".go"); slices.Contains(members, named) {
+		return named
+	}
+	for _, member := range members {
+		if !strings.HasSuffix(member, "_test.go") {
+			return member
+		}
+	}
+	return file
+}
+
+func (g *Graph) walk(start string, edges map[string][]string) []string {
+	visited := map[string]bool{start: true}
+	queue := []string{start}
+	order := []string{}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[current] {
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			order = append(order, next)
+			queue = append(queue, next)
+		}
+	}
+
+	return order
+}
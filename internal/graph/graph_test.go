@@ -0,0 +1,84 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestBuild(t *testing.T) {
+	files := []scanner.FileInfo{
+		{RelativePath: "cmd/app/main.go", Language: "go", Imports: []string{"fmt", "example.com/app/internal/store"}},
+		{RelativePath: "internal/store/store.go", Language: "go", Imports: []string{"example.com/app/internal/model"}},
+		{RelativePath: "internal/store/cache.go", Language: "go"},
+		{RelativePath: "internal/model/model.go", Language: "go"},
+		{RelativePath: "web/index.ts", Language: "typescript", Imports: []string{"./api", "react"}},
+		{RelativePath: "web/api/index.ts", Language: "typescript"},
+		{RelativePath: "svc/app.py", Language: "python", Imports: []string{"svc.db"}},
+		{RelativePath: "svc/db.py", Language: "python"},
+	}
+
+	g := Build(files, "example.com/app")
+
+	if got := g.Imports("cmd/app/main.go"); len(got) != 2 {
+		t.Errorf("Expected main.go to import both store files, got %v", got)
+	}
+	if got := g.Dependents("internal/model/model.go"); len(got) != 2 || got[0] != "internal/store/store.go" || got[1] != "cmd/app/main.go" {
+		t.Errorf("Unexpected dependents of model.go: %v", got)
+	}
+	if got := g.ImportedBy("web/api/index.ts"); len(got) != 1 || got[0] != "web/index.ts" {
+		t.Errorf("Expected web/index.ts to import web/api/index.ts, got %v", got)
+	}
+	if got := g.ImportedBy("svc/db.py"); len(got) != 1 || got[0] != "svc/app.py" {
+		t.Errorf("Expected svc/app.py to import svc/db.py, got %v", got)
+	}
+	if got := g.Dependencies("cmd/app/main.go"); len(got) != 3 {
+		t.Errorf("Expected 3 transitive dependencies, got %v", got)
+	}
+}
+
+func TestReadingOrder(t *testing.T) {
+	files := []scanner.FileInfo{
+		{RelativePath: "cmd/app/main.go", Language: "go", Imports: []string{"example.com/app/internal/api", "example.com/app/internal/model"}},
+		{RelativePath: "internal/api/api.go", Language: "go", Imports: []string{"example.com/app/internal/model", "example.com/app/internal/store"}},
+		{RelativePath: "internal/store/cache.go", Language: "go"},
+		{RelativePath: "internal/store/store.go", Language: "go", Imports: []string{"example.com/app/internal/model"}},
+		{RelativePath: "internal/model/model.go", Language: "go"},
+		{RelativePath: "internal/legacy/legacy.go", Language: "go"},
+	}
+	g := Build(files, "example.com/app")
+
+	got := g.ReadingOrder([]string{"cmd/app/main.go", "Dockerfile"}, 10)
+	want := []Stop{
+		{File: "cmd/app/main.go"},
+		{File: "internal/model/model.go", From: "cmd/app/main.go"},
+		{File: "internal/api/api.go", From: "cmd/app/main.go"},
+		{File: "internal/store/store.go", From: "internal/api/api.go"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadingOrder() = %v, want %v", got, want)
+	}
+	if got := g.ReadingOrder([]string{"cmd/app/main.go"}, 2); len(got) != 2 {
+		t.Errorf("ReadingOrder() with limit 2 returned %d stops", len(got))
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	files := []scanner.FileInfo{
+		{RelativePath: "cmd/app/main.go", Language: "go", Imports: []string{"example.com/app/internal/store"}},
+		{RelativePath: "cmd/app/flags.go", Language: "go", Imports: []string{"example.com/app/internal/config"}},
+		{RelativePath: "internal/store/store.go", Language: "go"},
+		{RelativePath: "internal/config/config.go", Language: "go"},
+		{RelativePath: "internal/legacy/legacy.go", Language: "go", Imports: []string{"example.com/app/internal/store"}},
+		{RelativePath: "web/index.ts", Language: "typescript", Imports: []string{"./api"}},
+		{RelativePath: "web/api.ts", Language: "typescript"},
+		{RelativePath: "web/old.ts", Language: "typescript"},
+	}
+
+	got := Build(files, "example.com/app").Unreachable([]string{"cmd/app/main.go", "web/index.ts"})
+	want := []string{"internal/legacy/legacy.go", "web/old.ts"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unreachable() = %v, want %v", got, want)
+	}
+}
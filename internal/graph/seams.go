@@ -0,0 +1,271 @@
+package graph
+
+import (
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Seam is a candidate module boundary: a group of directories whose files
+// import each other much more than they import, or are imported by, the rest
+// of the repository.
+type Seam struct {
+	// Boundary is the deepest directory holding every directory of the seam.
+	Boundary string
+	Dirs     []string
+	Files    int
+	// Internal counts the imports between directories of the seam: each file
+	// importing a directory counts once. Incoming and Outgoing count the
+	// imports crossing its boundary.
+	Internal int
+	Incoming int
+	Outgoing int
+	// Interface lists the directories of the seam imported from outside it.
+	Interface []string
+	// DependsOn holds the indexes of the seams this one imports.
+	DependsOn []int
+}
+
+// Cohesion is the share of the seam's imports that stay inside it.
+func (s Seam) Cohesion() float64 {
+	total := s.Internal + s.Incoming + s.Outgoing
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Internal) / float64(total)
+}
+
+// MinSeamFiles is the smallest group of files Seams suggests as a module.
+const MinSeamFiles = 3
+
+// Seams groups directories by greedy modularity maximization over the
+// directory-level import graph and returns the groups of at least
+// MinSeamFiles files with imports among them, most cohesive first. It
+// returns nil when the graph does not split into at least two such groups.
+func (g *Graph) Seams() []Seam {
+	fileCount := map[string]int{}
+	for _, file := range g.Files {
+		fileCount[path.Dir(file)]++
+	}
+
+	// Imports between directories, keyed by the sorted pair, and each
+	// directory's degree; an import inside a directory adds 2 to its degree.
+	imports := g.dirImports()
+	weights := map[[2]string]int{}
+	degree := map[string]int{}
+	total := 0
+	for from, targets := range imports {
+		for _, to := range targets {
+			degree[from]++
+			degree[to]++
+			total++
+			if from == to {
+				continue
+			}
+			key := [2]string{from, to}
+			if to < from {
+				key = [2]string{to, from}
+			}
+			weights[key]++
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	clusters := map[string][]string{}
+	for dir := range fileCount {
+		clusters[dir] = []string{dir}
+	}
+	m := float64(total)
+	for {
+		best, bestGain := [2]string{}, 0.0
+		for _, pair := range sortedPairs(weights) {
+			gain := float64(weights[pair])/m - float64(degree[pair[0]])*float64(degree[pair[1]])/(2*m*m)
+			if gain > bestGain {
+				best, bestGain = pair, gain
+			}
+		}
+		if bestGain == 0 {
+			break
+		}
+
+		// Merge the second cluster into the first, keyed by its first member.
+		keep, drop := best[0], best[1]
+		clusters[keep] = append(clusters[keep], clusters[drop]...)
+		delete(clusters, drop)
+		degree[keep] += degree[drop]
+		delete(degree, drop)
+		delete(weights, best)
+		for pair, weight := range weights {
+			if pair[0] != drop && pair[1] != drop {
+				continue
+			}
+			delete(weights, pair)
+			other := pair[0]
+			if other == drop {
+				other = pair[1]
+			}
+			key := [2]string{keep, other}
+			if other < keep {
+				key = [2]string{other, keep}
+			}
+			weights[key] += weight
+		}
+	}
+
+	clusterOf := map[string]string{}
+	for key, dirs := range clusters {
+		for _, dir := range dirs {
+			clusterOf[dir] = key
+		}
+	}
+
+	seams := []Seam{}
+	index := map[string]int{}
+	for _, key := range slices.Sorted(maps.Keys(clusters)) {
+		dirs := clusters[key]
+		files := 0
+		for _, dir := range dirs {
+			files += fileCount[dir]
+		}
+		if files < MinSeamFiles || !hasInternalImport(dirs, clusterOf, imports) {
+			continue
+		}
+		sort.Strings(dirs)
+		index[key] = len(seams)
+		seams = append(seams, Seam{Boundary: commonDir(dirs), Dirs: dirs, Files: files})
+	}
+	if len(seams) < 2 {
+		return nil
+	}
+
+	interfaces := make([]map[string]bool, len(seams))
+	dependsOn := make([]map[int]bool, len(seams))
+	for fromDir, targets := range imports {
+		for _, toDir := range targets {
+			a, b := clusterOf[fromDir], clusterOf[toDir]
+			i, fromSeam := index[a]
+			j, toSeam := index[b]
+			switch {
+			case a == b:
+				if fromSeam {
+					seams[i].Internal++
+				}
+				continue
+			case fromSeam && toSeam:
+				if dependsOn[i] == nil {
+					dependsOn[i] = map[int]bool{}
+				}
+				dependsOn[i][j] = true
+			}
+			if fromSeam {
+				seams[i].Outgoing++
+			}
+			if toSeam {
+				seams[j].Incoming++
+				if interfaces[j] == nil {
+					interfaces[j] = map[string]bool{}
+				}
+				interfaces[j][toDir] = true
+			}
+		}
+	}
+	for i := range seams {
+		seams[i].Interface = slices.Sorted(maps.Keys(interfaces[i]))
+		if seams[i].Interface == nil {
+			seams[i].Interface = []string{}
+		}
+		for j := range dependsOn[i] {
+			seams[i].DependsOn = append(seams[i].DependsOn, j)
+		}
+	}
+
+	// Order by cohesion, then remap DependsOn to the new positions.
+	order := make([]int, len(seams))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(x, y int) bool {
+		a, b := seams[order[x]], seams[order[y]]
+		if a.Cohesion() != b.Cohesion() {
+			return a.Cohesion() > b.Cohesion()
+		}
+		return a.Files > b.Files
+	})
+	position := make([]int, len(seams))
+	for newIndex, oldIndex := range order {
+		position[oldIndex] = newIndex
+	}
+	sorted := make([]Seam, len(seams))
+	for oldIndex, seam := range seams {
+		for k, j := range seam.DependsOn {
+			seam.DependsOn[k] = position[j]
+		}
+		sort.Ints(seam.DependsOn)
+		sorted[position[oldIndex]] = seam
+	}
+	return sorted
+}
+
+// dirImports maps each file's directory to the directories it imports, once
+// per importing file, so a Go import of a package counts once rather than
+// once per file of the package.
+func (g *Graph) dirImports() map[string][]string {
+	imports := map[string][]string{}
+	for _, from := range slices.Sorted(maps.Keys(g.imports)) {
+		seen := map[string]bool{}
+		for _, to := range g.imports[from] {
+			dir := path.Dir(to)
+			if !seen[dir] {
+				seen[dir] = true
+				imports[path.Dir(from)] = append(imports[path.Dir(from)], dir)
+			}
+		}
+	}
+	return imports
+}
+
+func hasInternalImport(dirs []string, clusterOf map[string]string, imports map[string][]string) bool {
+	for _, dir := range dirs {
+		for _, to := range imports[dir] {
+			if clusterOf[to] == clusterOf[dir] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortedPairs(weights map[[2]string]int) [][2]string {
+	pairs := make([][2]string, 0, len(weights))
+	for pair := range weights {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+// commonDir returns the deepest directory containing every one of dirs.
+func commonDir(dirs []string) string {
+	common := strings.Split(dirs[0], "/")
+	for _, dir := range dirs[1:] {
+		parts := strings.Split(dir, "/")
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	if len(common) == 0 {
+		return "."
+	}
+	return strings.Join(common, "/")
+}
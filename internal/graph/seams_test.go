@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestSeams(t *testing.T) {
+	files := []scanner.FileInfo{
+		{RelativePath: "billing/invoice.py", Language: "python", Imports: []string{"billing.tax", "billing.rates.table"}},
+		{RelativePath: "billing/tax.py", Language: "python", Imports: []string{"billing.rates.table"}},
+		{RelativePath: "billing/rates/table.py", Language: "python", Imports: []string{"shared.money"}},
+		{RelativePath: "users/api.py", Language: "python", Imports: []string{"users.store", "users.auth.token", "billing.invoice"}},
+		{RelativePath: "users/store.py", Language: "python", Imports: []string{"users.auth.token"}},
+		{RelativePath: "users/auth/token.py", Language: "python", Imports: []string{"users.store"}},
+		{RelativePath: "shared/money.py", Language: "python"},
+	}
+
+	seams := Build(files, "").Seams()
+	if len(seams) != 2 {
+		t.Fatalf("Seams() = %+v, want 2 seams", seams)
+	}
+
+	want := []Seam{
+		{Boundary: ".", Dirs: []string{"billing", "billing/rates", "shared"}, Files: 4, Internal: 4, Incoming: 1, Interface: []string{"billing"}},
+		{Boundary: "users", Dirs: []string{"users", "users/auth"}, Files: 3, Internal: 4, Outgoing: 1, Interface: []string{}, DependsOn: []int{0}},
+	}
+	if !reflect.DeepEqual(seams, want) {
+		t.Errorf("Seams() =\n%+v\nwant\n%+v", seams, want)
+	}
+	if cohesion := seams[0].Cohesion(); cohesion != 0.8 {
+		t.Errorf("Cohesion() = %v, want 0.8", cohesion)
+	}
+}
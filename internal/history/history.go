@@ -0,0 +1,349 @@
+// Package history mines a repository's git log for change frequency, churn
+// and authorship.
+package history
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+// Window is how far back Analyze looks from the latest commit.
+const Window = 365 * 24 * time.Hour
+
+type Result struct {
+	Since   time.Time
+	Until   time.Time
+	Commits int
+	// Files are the files that still exist, hotspots first: most commits,
+	// then most churn.
+	Files []File
+	// Contributors are sorted by commits, most first.
+	Contributors []Contributor
+}
+
+type File struct {
+	Path    string
+	Commits int
+	Added   int
+	Deleted int
+	// Authors counts each author's commits to the file.
+	Authors map[string]int
+}
+
+type Contributor struct {
+	Name       string
+	Email      string
+	Commits    int
+	LastCommit time.Time
+}
+
+// Churn is the number of lines added and deleted.
+func (f File) Churn() int {
+	return f.Added + f.Deleted
+}
+
+// MainAuthor returns the author of most commits to the file and their share
+// of its commits.
+func (f File) MainAuthor() (string, float64) {
+	return mainAuthor(f.Authors)
+}
+
+// mainAuthor returns the author with the most commits in authors and their
+// share of all of them. Ties go to the name sorting first.
+func mainAuthor(authors map[string]int) (string, float64) {
+	best, total := "", 0
+	for name, commits := range authors {
+		total += commits
+		if commits > authors[best] || (commits == authors[best] && (best == "" || name < best)) {
+			best = name
+		}
+	}
+	if total == 0 {
+		return "", 0
+	}
+	return best, float64(authors[best]) / float64(total)
+}
+
+// MergeAuthors adds up the per-author commits of several files, such as the
+// files of a module, and returns the main author, their share and the number
+// of authors.
+func MergeAuthors(files []File) (string, float64, int) {
+	authors := map[string]int{}
+	for _, file := range files {
+		for name, commits := range file.Authors {
+			authors[name] += commits
+		}
+	}
+	name, share := mainAuthor(authors)
+	return name, share, len(authors)
+}
+
+// TopAuthors returns up to n authors of files, most commits first.
+func TopAuthors(files []File, n int) []string {
+	authors := map[string]int{}
+	for _, file := range files {
+		for name, commits := range file.Authors {
+			authors[name] += commits
+		}
+	}
+	names := make([]string, 0, len(authors))
+	for name := range authors {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if authors[names[i]] != authors[names[j]] {
+			return authors[names[i]] > authors[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names[:min(n, len(names))]
+}
+
+type commit struct {
+	hash   string
+	author string
+	email  string
+	when   time.Time
+	files  []fileChange
+}
+
+type fileChange struct {
+	path           string
+	added, deleted int
+}
+
+// Analyze reads the non-merge commits of the Window before the latest commit.
+// The boundary commits of a shallow clone are skipped, since their diffs
+// would add the whole tree.
+func Analyze(ctx context.Context, repoPath string) (*Result, error) {
+	if !util.IsGitRepo(repoPath) {
+		return nil, fmt.Errorf("%s is not a git repository", repoPath)
+	}
+
+	var commits []commit
+	var err error
+	if util.GitAvailable() {
+		commits, err = gitLog(ctx, repoPath)
+	} else {
+		commits, err = goGitLog(ctx, repoPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return aggregate(commits, repoPath, shallowCommits(repoPath)), nil
+}
+
+func gitLog(ctx context.Context, repoPath string) ([]commit, error) {
+	head, err := exec.CommandContext(ctx, "git", "-C", repoPath, "log", "-1", "--format=%ct").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	if len(strings.TrimSpace(string(head))) == 0 {
+		return nil, nil
+	}
+	latest, err := strconv.ParseInt(strings.TrimSpace(string(head)), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	since := time.Unix(latest, 0).Add(-Window)
+
+	cmd := exec.CommandContext(ctx, "git", "-C", repoPath, "log", "--no-merges", "--no-renames", "--numstat",
+		"--since="+since.Format(time.RFC3339), "--format=\x1e%H\x1f%aN\x1f%aE\x1f%at")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	commits, parseErr := parseLog(stdout)
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	return commits, parseErr
+}
+
+// parseLog reads `git log --numstat` output whose commit headers start with
+// a record separator and hold hash, author, email and time separated by unit
+// separators.
+func parseLog(r io.Reader) ([]commit, error) {
+	commits := []commit{}
+	lines := bufio.NewScanner(r)
+	lines.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lines.Scan() {
+		line := lines.Text()
+		if header, ok := strings.CutPrefix(line, "\x1e"); ok {
+			fields := strings.Split(header, "\x1f")
+			if len(fields) != 4 {
+				continue
+			}
+			seconds, _ := strconv.ParseInt(fields[3], 10, 64)
+			commits = append(commits, commit{hash: fields[0], author: fields[1], email: fields[2], when: time.Unix(seconds, 0).UTC()})
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || len(commits) == 0 {
+			continue
+		}
+		// Binary files have "-" for both counts.
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		last := &commits[len(commits)-1]
+		last.files = append(last.files, fileChange{path: fields[2], added: added, deleted: deleted})
+	}
+	return commits, lines.Err()
+}
+
+func goGitLog(ctx context.Context, repoPath string) ([]commit, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	latest, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	since := latest.Committer.When.Add(-Window)
+
+	iter, err := repo.Log(&git.LogOptions{From: head.Hash(), Since: &since})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	errStop := errors.New("stop")
+	commits := []commit{}
+	err = iter.ForEach(func(c *object.Commit) error {
+		if ctx.Err() != nil {
+			return errStop
+		}
+		if c.NumParents() > 1 {
+			return nil
+		}
+		stats, err := c.Stats()
+		if err != nil {
+			return nil
+		}
+		entry := commit{hash: c.Hash.String(), author: c.Author.Name, email: c.Author.Email, when: c.Author.When.UTC()}
+		for _, stat := range stats {
+			entry.files = append(entry.files, fileChange{path: stat.Name, added: stat.Addition, deleted: stat.Deletion})
+		}
+		commits = append(commits, entry)
+		return nil
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// shallowCommits lists the boundary commits of a shallow clone.
+func shallowCommits(repoPath string) map[string]bool {
+	boundaries := map[string]bool{}
+	content, err := os.ReadFile(filepath.Join(repoPath, ".git", "shallow"))
+	if err != nil {
+		return boundaries
+	}
+	for _, hash := range strings.Fields(string(content)) {
+		boundaries[hash] = true
+	}
+	return boundaries
+}
+
+func aggregate(commits []commit, repoPath string, skip map[string]bool) *Result {
+	result := &Result{Files: []File{}, Contributors: []Contributor{}}
+	files := map[string]*File{}
+	contributors := map[string]*Contributor{}
+
+	for _, c := range commits {
+		if skip[c.hash] {
+			continue
+		}
+		result.Commits++
+		if result.Since.IsZero() || c.when.Before(result.Since) {
+			result.Since = c.when
+		}
+		if c.when.After(result.Until) {
+			result.Until = c.when
+		}
+
+		key := strings.ToLower(c.email)
+		if key == "" {
+			key = c.author
+		}
+		// The log is newest first, so the first name seen is the current one.
+		contributor, ok := contributors[key]
+		if !ok {
+			contributor = &Contributor{Name: c.author, Email: c.email}
+			contributors[key] = contributor
+		}
+		contributor.Commits++
+		if c.when.After(contributor.LastCommit) {
+			contributor.LastCommit = c.when
+		}
+
+		for _, change := range c.files {
+			file, ok := files[change.path]
+			if !ok {
+				file = &File{Path: change.path, Authors: map[string]int{}}
+				files[change.path] = file
+			}
+			file.Commits++
+			file.Added += change.added
+			file.Deleted += change.deleted
+			file.Authors[contributor.Name]++
+		}
+	}
+
+	for path, file := range files {
+		if util.FileExists(filepath.Join(repoPath, filepath.FromSlash(path))) {
+			result.Files = append(result.Files, *file)
+		}
+	}
+	sort.Slice(result.Files, func(i, j int) bool {
+		a, b := result.Files[i], result.Files[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		if a.Churn() != b.Churn() {
+			return a.Churn() > b.Churn()
+		}
+		return a.Path < b.Path
+	})
+
+	for _, contributor := range contributors {
+		result.Contributors = append(result.Contributors, *contributor)
+	}
+	sort.Slice(result.Contributors, func(i, j int) bool {
+		a, b := result.Contributors[i], result.Contributors[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Name < b.Name
+	})
+
+	return result
+}
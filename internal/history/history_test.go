@@ -0,0 +1,75 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"api/server.go", "api/routes.go", "README.md"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	log := strings.Join([]string{
+		"\x1ec4\x1fAda Lovelace\x1fada@example.com\x1f1700300000",
+		"",
+		"10\t2\tapi/server.go",
+		"-\t-\tlogo.png",
+		"\x1ec3\x1fAda L.\x1fADA@example.com\x1f1700200000",
+		"",
+		"5\t5\tapi/server.go",
+		"1\t0\tapi/routes.go",
+		"\x1ec2\x1fGrace Hopper\x1fgrace@example.com\x1f1700100000",
+		"",
+		"3\t1\tapi/server.go",
+		"2\t0\tREADME.md",
+		"\x1ec1\x1fGrace Hopper\x1fgrace@example.com\x1f1700000000",
+		"",
+		"900\t0\tapi/server.go",
+		"",
+	}, "\n")
+
+	commits, err := parseLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := aggregate(commits, tempDir, map[string]bool{"c1": true})
+
+	if result.Commits != 3 || !result.Since.Equal(time.Unix(1700100000, 0)) || !result.Until.Equal(time.Unix(1700300000, 0)) {
+		t.Errorf("Commits = %d from %v to %v, want 3 from c2 to c4", result.Commits, result.Since, result.Until)
+	}
+
+	got := []string{}
+	for _, file := range result.Files {
+		author, share := file.MainAuthor()
+		got = append(got, fmt.Sprintf("%s %d %d %s %.0f", file.Path, file.Commits, file.Churn(), author, share*100))
+	}
+	want := []string{"api/server.go 3 26 Ada Lovelace 67", "README.md 1 2 Grace Hopper 100", "api/routes.go 1 1 Ada Lovelace 100"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Files = %q, want %q", got, want)
+	}
+
+	contributors := []string{}
+	for _, contributor := range result.Contributors {
+		contributors = append(contributors, fmt.Sprintf("%s %d", contributor.Name, contributor.Commits))
+	}
+	if !reflect.DeepEqual(contributors, []string{"Ada Lovelace 2", "Grace Hopper 1"}) {
+		t.Errorf("Contributors = %q", contributors)
+	}
+
+	if author, share, authors := MergeAuthors(result.Files); author != "Ada Lovelace" || share != 0.6 || authors != 2 {
+		t.Errorf("MergeAuthors() = %s, %v, %d, want Ada Lovelace, 0.6, 2", author, share, authors)
+	}
+}
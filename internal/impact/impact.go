@@ -0,0 +1,81 @@
+package impact
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+type Options struct {
+	Target    string
+	Files     []scanner.FileInfo
+	Graph     *graph.Graph
+	Detection *detect.Result
+}
+
+type Result struct {
+	Target     string            `json:"target"`
+	Dependents []string          `json:"dependents"`
+	Endpoints  []detect.Endpoint `json:"endpoints"`
+	Tests      []string          `json:"tests"`
+}
+
+// Analyze lists what is likely to break when Target changes: files that
+// transitively import it, endpoints declared in any of those files, and
+// tests that either import them or sit alongside them.
+func Analyze(opts Options) Result {
+	target := filepath.ToSlash(opts.Target)
+	result := Result{
+		Target:     target,
+		Dependents: []string{},
+		Endpoints:  []detect.Endpoint{},
+		Tests:      []string{},
+	}
+
+	isTest := make(map[string]bool)
+	for _, file := range opts.Files {
+		isTest[filepath.ToSlash(file.RelativePath)] = file.IsTest
+	}
+
+	affected := map[string]bool{target: true}
+	for _, dependent := range opts.Graph.Dependents(target) {
+		affected[dependent] = true
+		if isTest[dependent] {
+			continue
+		}
+		result.Dependents = append(result.Dependents, dependent)
+	}
+
+	affectedDirs := make(map[string]bool)
+	for file := range affected {
+		affectedDirs[path.Dir(file)] = true
+	}
+
+	tests := make(map[string]bool)
+	for file, test := range isTest {
+		if !test {
+			continue
+		}
+		if affected[file] || affectedDirs[path.Dir(file)] {
+			tests[file] = true
+		}
+	}
+	for test := range tests {
+		result.Tests = append(result.Tests, test)
+	}
+	sort.Strings(result.Tests)
+
+	if opts.Detection != nil {
+		for _, endpoint := range opts.Detection.Endpoints {
+			if affected[filepath.ToSlash(endpoint.File)] {
+				result.Endpoints = append(result.Endpoints, endpoint)
+			}
+		}
+	}
+
+	return result
+}
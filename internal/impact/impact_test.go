@@ -0,0 +1,84 @@
+package impact
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestAnalyze(t *testing.T) {
+	files := []scanner.FileInfo{
+		{RelativePath: "app/models.py", Language: "python"},
+		{RelativePath: "app/service.py", Language: "python", Imports: []string{"app.models"}},
+		{RelativePath: "app/api.py", Language: "python", Imports: []string{"app.service"}},
+		{RelativePath: "app/test_models.py", Language: "python", IsTest: true},
+		{RelativePath: "tests/test_api.py", Language: "python", IsTest: true, Imports: []string{"app.api"}},
+		{RelativePath: "lib/util.py", Language: "python"},
+		{RelativePath: "lib/health.py", Language: "python"},
+	}
+	orders := detect.Endpoint{Method: "POST", Path: "/orders", Handler: "create_order", File: "app/api.py"}
+	health := detect.Endpoint{Method: "GET", Path: "/health", Handler: "health", File: "lib/health.py"}
+	detection := &detect.Result{Endpoints: []detect.Endpoint{orders, health}}
+
+	tests := []struct {
+		name      string
+		target    string
+		detection *detect.Result
+		want      Result
+	}{
+		{
+			name:      "transitive dependents",
+			target:    "app/models.py",
+			detection: detection,
+			want: Result{
+				Target:     "app/models.py",
+				Dependents: []string{"app/service.py", "app/api.py"},
+				Endpoints:  []detect.Endpoint{orders},
+				Tests:      []string{"app/test_models.py", "tests/test_api.py"},
+			},
+		},
+		{
+			name:      "imported only by tests",
+			target:    "app/api.py",
+			detection: detection,
+			want: Result{
+				Target:     "app/api.py",
+				Dependents: []string{},
+				Endpoints:  []detect.Endpoint{orders},
+				Tests:      []string{"app/test_models.py", "tests/test_api.py"},
+			},
+		},
+		{
+			name:      "endpoint in the target",
+			target:    "lib/health.py",
+			detection: detection,
+			want: Result{
+				Target:     "lib/health.py",
+				Dependents: []string{},
+				Endpoints:  []detect.Endpoint{health},
+				Tests:      []string{},
+			},
+		},
+		{
+			name:   "no importers",
+			target: "lib/util.py",
+			want: Result{
+				Target:     "lib/util.py",
+				Dependents: []string{},
+				Endpoints:  []detect.Endpoint{},
+				Tests:      []string{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Analyze(Options{Target: tt.target, Files: files, Graph: graph.Build(files, ""), Detection: tt.detection})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Analyze(%s) = %+v, want %+v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
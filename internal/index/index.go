@@ -0,0 +1,182 @@
+// Package index saves a whole analysis to one file, so that ask, diff,
+// serve and render can reuse it without scanning the repository or calling
+// the LLM again.
+package index
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/workspace"
+)
+
+// Version changes when the format changes incompatibly.
+const Version = 1
+
+// Index is the analysis behind a report: the JSON artifact plus what
+// re-rendering and questions need.
+type Index struct {
+	Version int `json:"version"`
+	report.Artifact
+	// RepoPath is the directory analyzed. Clones of RepoURL are gone once
+	// the run ends.
+	RepoPath string              `json:"repo_path"`
+	RepoURL  string              `json:"repo_url,omitempty"`
+	Projects []workspace.Project `json:"projects,omitempty"`
+	// Embeddings, when present, embed summarize.AskDocuments.
+	Embeddings *Embeddings `json:"embeddings,omitempty"`
+}
+
+// Embeddings are the vectors of one provider, by slash path.
+type Embeddings struct {
+	Provider string            `json:"provider"`
+	Vectors  map[string]Vector `json:"vectors"`
+}
+
+// Vector is stored as base64 little-endian float32s, which is plenty of
+// precision for ranking and a fraction of the size of JSON numbers.
+type Vector []float64
+
+func (v Vector) MarshalJSON() ([]byte, error) {
+	data := make([]byte, 4*len(v))
+	for i, value := range v {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(float32(value)))
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(data))
+}
+
+func (v *Vector) UnmarshalJSON(raw []byte) error {
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		return err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	if len(data)%4 != 0 {
+		return fmt.Errorf("vector of %d bytes", len(data))
+	}
+	*v = make(Vector, len(data)/4)
+	for i := range *v {
+		(*v)[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:])))
+	}
+	return nil
+}
+
+// New collects the analysis of a report.
+func New(opts report.Options) *Index {
+	return &Index{
+		Version:  Version,
+		Artifact: report.NewArtifact(opts),
+		RepoPath: opts.RepoPath,
+		RepoURL:  opts.RepoURL,
+		Projects: opts.Projects,
+	}
+}
+
+// Options returns report options that render the indexed analysis again.
+// Settings that only shape the output, such as the format, are left to the
+// caller.
+func (idx *Index) Options() report.Options {
+	return report.Options{
+		RepoPath:        idx.RepoPath,
+		RepoURL:         idx.RepoURL,
+		ScanResult:      idx.Scan,
+		DetectionResult: idx.Detection,
+		Summaries:       idx.Summaries,
+		Provenance:      idx.Provenance,
+		InternalDeps:    idx.Internal,
+		Dependencies:    idx.Dependencies,
+		History:         idx.History,
+		Glossary:        idx.Glossary,
+		Seams:           idx.Seams,
+		FunctionalAreas: idx.Areas,
+		Projects:        idx.Projects,
+		Roots:           idx.Roots,
+		System:          idx.System,
+	}
+}
+
+// Vectors returns the embeddings made with provider, or nil when the index
+// has none from it.
+func (idx *Index) Vectors(provider string) map[string][]float64 {
+	if idx.Embeddings == nil || idx.Embeddings.Provider != provider {
+		return nil
+	}
+	vectors := make(map[string][]float64, len(idx.Embeddings.Vectors))
+	for path, vector := range idx.Embeddings.Vectors {
+		vectors[path] = vector
+	}
+	return vectors
+}
+
+// Save writes idx to path as gzip-compressed JSON.
+func Save(path string, idx *Index) error {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(writer).Encode(idx); err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// Load reads an index written by Save.
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return idx, nil
+}
+
+// Decode parses the contents of an index file.
+func Decode(data []byte) (*Index, error) {
+	if !Is(data) {
+		return nil, fmt.Errorf("not a codedoc index")
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("not a codedoc index: %w", err)
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("not a codedoc index: %w", err)
+	}
+
+	var idx Index
+	if err := json.Unmarshal(decoded, &idx); err != nil {
+		return nil, fmt.Errorf("not a codedoc index: %w", err)
+	}
+	if idx.Version != Version {
+		return nil, fmt.Errorf("version %d index; this codedoc reads version %d, so generate it again", idx.Version, Version)
+	}
+	if idx.Scan == nil {
+		return nil, fmt.Errorf("index has no scan results")
+	}
+	return &idx, nil
+}
+
+// Is reports whether data starts like an index, so commands taking either
+// an index or a JSON artifact can tell them apart.
+func Is(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
@@ -0,0 +1,88 @@
+package index
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+func TestSaveLoad(t *testing.T) {
+	opts := report.Options{
+		RepoPath: "/src/shop",
+		ScanResult: &scanner.Result{
+			TotalFiles: 1,
+			Files:      []scanner.FileInfo{{RelativePath: "main.go", Language: "go", Lines: 3, Hash: "abc"}},
+		},
+		DetectionResult: &detect.Result{},
+		Summaries:       &summarize.Result{FileSummaries: map[string]summarize.FileSummary{"main.go": {Summary: "Starts the shop."}}},
+	}
+	idx := New(opts)
+	idx.Embeddings = &Embeddings{Provider: "local", Vectors: map[string]Vector{"main.go": {0.6, -0.8, 1.0 / 3}}}
+
+	path := filepath.Join(t.TempDir(), "index.codedoc")
+	if err := Save(path, idx); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.RepoPath != "/src/shop" || loaded.Scan.Files[0].Hash != "abc" ||
+		loaded.Summaries.FileSummaries["main.go"].Summary != "Starts the shop." {
+		t.Errorf("Unexpected index %+v", loaded)
+	}
+	if options := loaded.Options(); options.ScanResult.TotalFiles != 1 || options.RepoPath != "/src/shop" {
+		t.Errorf("Unexpected options %+v", options)
+	}
+	if vectors := loaded.Vectors("openai"); vectors != nil {
+		t.Errorf("Vectors(openai) = %v, want none", vectors)
+	}
+	vector := loaded.Vectors("local")["main.go"]
+	for i, want := range []float64{0.6, -0.8, 1.0 / 3} {
+		if len(vector) != 3 || math.Abs(vector[i]-want) > 1e-6 {
+			t.Fatalf("Vectors(local) = %v, want about %v", vector, want)
+		}
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	gzipped := func(text string) []byte {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		writer.Write([]byte(text))
+		writer.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"json artifact", []byte(`{"scan":{}}`), "not a codedoc index"},
+		{"other version", gzipped(`{"version":99,"scan":{}}`), "version 99"},
+		{"no scan", gzipped(`{"version":1}`), "no scan results"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "index.codedoc")
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -8,22 +8,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
 type AnthropicProvider struct {
-	apiKey   string
-	cacheDir string
-	force    bool
-	client   *http.Client
-	limiter  *rateLimiter
+	apiKey      string
+	cache       Cache
+	force       bool
+	model       string
+	maxTokens   int
+	temperature float64
+	client      *http.Client
+	limiter     *rateLimiter
+	prompts     Prompts
+	language    string
 }
 
+// rateLimiter spaces requests minDelay apart. It is shared by the routed
+// providers and by concurrent callers, such as the serve requests.
 type rateLimiter struct {
+	mu          sync.Mutex
 	lastRequest time.Time
 	minDelay    time.Duration
 }
@@ -37,12 +46,17 @@ func NewAnthropicProvider(config AnthropicConfig) (Provider, error) {
 		return nil, fmt.Errorf("ANTHROPIC_API_KEY not set")
 	}
 
-	if config.CacheDir == "" {
-		config.CacheDir = ".codedoc-cache"
-	}
+	cache := config.Cache
+	if cache == nil {
+		if config.CacheDir == "" {
+			config.CacheDir = ".codedoc-cache"
+		}
 
-	if err := os.MkdirAll(config.CacheDir, 0o755); err != nil {
-		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		fileCache, err := NewFileCache(config.CacheDir)
+		if err != nil {
+			return nil, err
+		}
+		cache = fileCache
 	}
 
 	maxQPS := config.MaxQPS
@@ -50,37 +64,83 @@ func NewAnthropicProvider(config AnthropicConfig) (Provider, error) {
 		maxQPS = 2.0
 	}
 
-	return &AnthropicProvider{
-		apiKey:   apiKey,
-		cacheDir: config.CacheDir,
-		force:    config.Force,
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+	model := config.Model
+	if model == "" {
+		model = DefaultModel
+	}
+	maxTokens := config.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = DefaultMaxTokens
+	}
+	temperature := DefaultTemperature
+	if config.Temperature != nil {
+		temperature = *config.Temperature
+	}
+	if config.Reproducible {
+		temperature = 0
+	}
+
+	provider := &AnthropicProvider{
+		apiKey:      apiKey,
+		cache:       cache,
+		force:       config.Force,
+		model:       model,
+		maxTokens:   maxTokens,
+		temperature: temperature,
+		client:      config.HTTPClient,
 		limiter: &rateLimiter{
 			minDelay: time.Duration(1000/maxQPS) * time.Millisecond,
 		},
-	}, nil
+		prompts:  config.Prompts,
+		language: config.OutputLanguage,
+	}
+	if provider.client == nil {
+		provider.client = &http.Client{
+			Timeout: 60 * time.Second,
+		}
+	}
+	if len(config.Models) == 0 {
+		return provider, nil
+	}
+
+	routes := map[SummaryType]Provider{}
+	for summaryType, model := range config.Models {
+		routed := *provider
+		routed.model = model
+		routes[summaryType] = &routed
+	}
+	return NewRoutingProvider(provider, routes), nil
 }
 
 func (p *AnthropicProvider) Summarize(ctx context.Context, request SummarizeRequest) (SummarizeResponse, error) {
 	cacheKey := p.getCacheKey(request)
-	cacheFile := filepath.Join(p.cacheDir, cacheKey+".json")
 
-	if !p.force {
-		if cached, err := p.loadFromCache(cacheFile); err == nil {
+	if !p.force && !request.SkipCache {
+		if cached, err := p.loadFromCache(ctx, cacheKey); err == nil {
+			slog.DebugContext(ctx, "llm cache hit", "type", request.Type, "key", shortKey(cacheKey))
 			return cached, nil
 		}
 	}
 
 	prompt := p.buildPrompt(request)
 
-	p.limiter.wait()
+	waited, err := p.limiter.wait(ctx)
+	if err != nil {
+		return SummarizeResponse{}, err
+	}
+	if waited > 0 {
+		slog.DebugContext(ctx, "llm rate limited", "type", request.Type, "wait", waited)
+	}
 
-	response, err := p.callAPI(ctx, prompt)
+	start := time.Now()
+	response, err := p.callAPI(ctx, prompt, streamFor(ctx, request.Type))
 	if err != nil {
+		slog.WarnContext(ctx, "llm request failed", "type", request.Type, "model", p.model,
+			"duration", time.Since(start), "err", err)
 		return SummarizeResponse{}, err
 	}
+	slog.DebugContext(ctx, "llm request", "type", request.Type, "model", p.model,
+		"duration", time.Since(start), "request_bytes", len(prompt), "response_bytes", len(response))
 
 	result := SummarizeResponse{
 		Summary: response,
@@ -88,33 +148,72 @@ func (p *AnthropicProvider) Summarize(ctx context.Context, request SummarizeRequ
 		Tokens:  p.estimateTokens(prompt + response),
 	}
 
-	// Best effort cache save - don't fail the request if caching fails
-	_ = p.saveToCache(cacheFile, result)
+	// Best effort cache save - don't fail the request if caching fails. The
+	// response is already paid for, so keep it even if ctx was cancelled
+	// while it arrived.
+	if err := p.saveToCache(context.WithoutCancel(ctx), cacheKey, result); err != nil {
+		slog.WarnContext(ctx, "llm cache write failed", "key", shortKey(cacheKey), "err", err)
+	}
 
 	return result, nil
 }
 
 func (p *AnthropicProvider) getCacheKey(request SummarizeRequest) string {
-	if request.CacheKey != "" {
-		return request.CacheKey
+	key := p.defaultCacheKey(request)
+	if prompt, ok := p.prompts[request.Type]; ok {
+		hash := sha256.Sum256([]byte(key + "-" + prompt.Digest))
+		key = hex.EncodeToString(hash[:])
+	}
+	// Keys from before the response cap could be changed stay valid.
+	if p.maxTokens != DefaultMaxTokens {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s-max-tokens-%d", key, p.maxTokens)))
+		key = hex.EncodeToString(hash[:])
+	}
+	if p.localized(request.Type) {
+		hash := sha256.Sum256([]byte(key + "-lang-" + strings.ToLower(p.language)))
+		key = hex.EncodeToString(hash[:])
 	}
+	return key
+}
+
+// localized reports whether summaries of this type are written in the
+// output language. Tags stay English so they match across repositories.
+func (p *AnthropicProvider) localized(summaryType SummaryType) bool {
+	return !isEnglish(p.language) && summaryType != SummaryTypeTags
+}
 
-	data := fmt.Sprintf("%s-%s-%d-%d",
+// defaultCacheKey hashes what the response depends on. An explicit
+// CacheKey stands in for the request's content; the model, temperature and
+// reviewer examples still change the response, so they are always part of
+// the key.
+func (p *AnthropicProvider) defaultCacheKey(request SummarizeRequest) string {
+	content := fmt.Sprintf("%s-%s-%d-%d",
 		request.Type,
 		request.Context,
 		request.Constraints.MaxWords,
 		request.Constraints.MaxBullets,
 	)
+	if request.CacheKey != "" {
+		content = fmt.Sprintf("%s-key-%s", request.Type, request.CacheKey)
+	}
+
+	data := fmt.Sprintf("%s-%s-%g", content, p.model, p.temperature)
+	for _, example := range request.Examples {
+		data += fmt.Sprintf("-%q-%q-%q", example.Rejected, example.Correction, example.Note)
+	}
 
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:])
 }
 
-func (p *AnthropicProvider) loadFromCache(cacheFile string) (SummarizeResponse, error) {
-	data, err := os.ReadFile(cacheFile)
+func (p *AnthropicProvider) loadFromCache(ctx context.Context, key string) (SummarizeResponse, error) {
+	data, ok, err := p.cache.Get(ctx, key)
 	if err != nil {
 		return SummarizeResponse{}, err
 	}
+	if !ok {
+		return SummarizeResponse{}, fmt.Errorf("cache miss")
+	}
 
 	var result SummarizeResponse
 	if err := json.Unmarshal(data, &result); err != nil {
@@ -125,13 +224,13 @@ func (p *AnthropicProvider) loadFromCache(cacheFile string) (SummarizeResponse,
 	return result, nil
 }
 
-func (p *AnthropicProvider) saveToCache(cacheFile string, response SummarizeResponse) error {
+func (p *AnthropicProvider) saveToCache(ctx context.Context, key string, response SummarizeResponse) error {
 	data, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(cacheFile, data, 0o644)
+	return p.cache.Set(ctx, key, data)
 }
 
 func (p *AnthropicProvider) buildPrompt(request SummarizeRequest) string {
@@ -184,22 +283,157 @@ func (p *AnthropicProvider) buildPrompt(request SummarizeRequest) string {
 				"List the quickstart steps:",
 			request.Constraints.MaxBullets, request.Context)
 
+	case SummaryTypeConfig:
+		systemPrompt = "You are a senior software engineer writing concise internal documentation."
+		userPrompt = fmt.Sprintf(
+			"Explain what each top-level section of this configuration file controls, "+
+				"in no more than %d bullet points. "+
+				"Format: '- section — what it configures'\n\n"+
+				"Context:\n%s\n\n"+
+				"List the sections:",
+			request.Constraints.MaxBullets, request.Context)
+
+	case SummaryTypeTags:
+		systemPrompt = "You are a senior software engineer cataloguing repositories."
+		userPrompt = fmt.Sprintf(
+			"Classify this codebase with at most %d short lowercase topic tags describing its business domain "+
+				"and kind of software, such as payments, kubernetes-operator, react-spa or etl. "+
+				"Keep the detected tags that fit and add what they miss.\n\n"+
+				"Context:\n%s\n\n"+
+				"Reply with the tags only, comma-separated:",
+			request.Constraints.MaxBullets, request.Context)
+
+	case SummaryTypeSeams:
+		systemPrompt = "You are a senior software architect planning the modularization of a monolith."
+		userPrompt = fmt.Sprintf(
+			"These candidate module boundaries were found by clustering the import graph. "+
+				"For each candidate, in no more than %d bullet points, name the module it would become "+
+				"and say in one sentence whether the boundary is worth extracting and what its interface is. "+
+				"Format: '- C1 — name: assessment'\n\n"+
+				"Context:\n%s\n\n"+
+				"List the candidates:",
+			request.Constraints.MaxBullets, request.Context)
+
+	case SummaryTypeGlossary:
+		systemPrompt = "You are a senior software engineer writing onboarding documentation."
+		userPrompt = fmt.Sprintf(
+			"These abbreviations and domain terms recur in the identifiers of this codebase. "+
+				"Define each of them in one short sentence, as used here, judging from the identifiers and files "+
+				"that use it (at most %d bullet points). Expand abbreviations. "+
+				"Format: '- term — definition'\n\n"+
+				"Context:\n%s\n\n"+
+				"List the definitions:",
+			request.Constraints.MaxBullets, request.Context)
+
+	case SummaryTypeAnswer:
+		systemPrompt = "You are a senior software engineer answering a colleague's question about a codebase."
+		userPrompt = fmt.Sprintf(
+			"Answer the question in no more than %d words, using only the context: summaries of the codebase "+
+				"and excerpts of the files most relevant to the question. Name the files and functions "+
+				"involved. If the context does not answer the question, say so and say which files to read.\n\n"+
+				"Context:\n%s\n\n"+
+				"Answer:",
+			request.Constraints.MaxWords, request.Context)
+
+	case SummaryTypeDocComment:
+		systemPrompt = "You are a senior software engineer writing source code documentation."
+		userPrompt = fmt.Sprintf(
+			"Write the doc comment of this package or module in no more than %d words, following the "+
+				"conventions of its language: a Go package comment starts with \"Package <name>\", and a "+
+				"Go command's with the command's name; a Python docstring starts with a one-line summary. "+
+				"Say what it provides and when to use it, not how each file works. Reply with the comment "+
+				"text only, without comment markers or quotes, paragraphs separated by blank lines.\n\n"+
+				"Context:\n%s\n\n"+
+				"Doc comment:",
+			request.Constraints.MaxWords, request.Context)
+
+	case SummaryTypeChange:
+		systemPrompt = "You are a senior software engineer writing release notes."
+		userPrompt = fmt.Sprintf(
+			"Describe what this change to one file does in no more than %d words: the behavior added, "+
+				"removed or fixed, not the lines edited. Skip formatting-only changes in a few words.\n\n"+
+				"Context:\n%s\n\n"+
+				"Change summary:",
+			request.Constraints.MaxWords, request.Context)
+
+	case SummaryTypeChangelog:
+		systemPrompt = "You are a senior software engineer writing release notes."
+		userPrompt = fmt.Sprintf(
+			"Summarize these changes for a reader of the changelog in no more than %d words. Lead with "+
+				"what users and operators will notice, then internal changes worth knowing about, "+
+				"naming the modules involved.\n\n"+
+				"Context:\n%s\n\n"+
+				"Summary:",
+			request.Constraints.MaxWords, request.Context)
+
 	default:
 		systemPrompt = "You are a senior software engineer writing concise internal documentation."
 		userPrompt = fmt.Sprintf("Summarize the following:\n\n%s", request.Context)
 	}
 
-	return systemPrompt + "\n\n" + userPrompt
+	language := ""
+	if p.localized(request.Type) {
+		language = LanguageName(p.language)
+		systemPrompt += fmt.Sprintf(" Write your answer in %s. Keep code identifiers, file paths, "+
+			"commands and the requested bullet format unchanged.", language)
+	}
+
+	prompt := systemPrompt + "\n\n" + formatExamples(request.Examples) + userPrompt
+	custom, ok := p.prompts[request.Type]
+	if !ok {
+		return prompt
+	}
+	rendered, err := custom.render(PromptData{
+		Type:       request.Type,
+		Context:    request.Context,
+		MaxWords:   request.Constraints.MaxWords,
+		MaxBullets: request.Constraints.MaxBullets,
+		Style:      request.Constraints.Style,
+		Examples:   formatExamples(request.Examples),
+		Language:   language,
+		Default:    prompt,
+	})
+	if err != nil {
+		slog.Warn("custom prompt failed, using the built-in one", "type", request.Type, "err", err)
+		return prompt
+	}
+	return rendered
 }
 
-func (p *AnthropicProvider) callAPI(ctx context.Context, prompt string) (string, error) {
+// formatExamples lists summaries reviewers rejected for this repository, so
+// the model avoids repeating their mistakes.
+func formatExamples(examples []Example) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Reviewers rejected these earlier summaries from this codebase. Avoid the same mistakes.\n")
+	for i, example := range examples {
+		fmt.Fprintf(&b, "\nExample %d\nRejected: %s\n", i+1, example.Rejected)
+		if example.Note != "" {
+			fmt.Fprintf(&b, "Reviewer note: %s\n", example.Note)
+		}
+		if example.Correction != "" {
+			fmt.Fprintf(&b, "Preferred: %s\n", example.Correction)
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// callAPI sends prompt to the Messages API, streaming the response to
+// onText when it is not nil.
+func (p *AnthropicProvider) callAPI(ctx context.Context, prompt string, onText func(text string)) (string, error) {
 	requestBody := map[string]interface{}{
-		"model": "claude-3-haiku-20240307",
+		"model": p.model,
 		"messages": []map[string]string{
 			{"role": "user", "content": prompt},
 		},
-		"max_tokens":  1000,
-		"temperature": 0.2,
+		"max_tokens":  p.maxTokens,
+		"temperature": p.temperature,
+	}
+	if onText != nil {
+		requestBody["stream"] = true
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -207,7 +441,7 @@ func (p *AnthropicProvider) callAPI(ctx context.Context, prompt string) (string,
 		return "", err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://"+APIHost+"/v1/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", err
 	}
@@ -222,18 +456,30 @@ func (p *AnthropicProvider) callAPI(ctx context.Context, prompt string) (string,
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusTooManyRequests {
 			return "", fmt.Errorf("rate limited, please retry")
 		}
+		body, _ := io.ReadAll(resp.Body)
 		return "", fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
 	}
 
+	if onText != nil {
+		text, err := readStream(resp.Body, onText)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(text) == "" {
+			return "", fmt.Errorf("empty response from API")
+		}
+		return strings.TrimSpace(text), nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
 	var response struct {
 		Content []struct {
 			Text string `json:"text"`
@@ -251,14 +497,40 @@ func (p *AnthropicProvider) callAPI(ctx context.Context, prompt string) (string,
 	return strings.TrimSpace(response.Content[0].Text), nil
 }
 
+func shortKey(key string) string {
+	if len(key) > 12 {
+		return key[:12]
+	}
+	return key
+}
+
 func (p *AnthropicProvider) estimateTokens(text string) int {
 	return len(text) / 4
 }
 
-func (l *rateLimiter) wait() {
-	elapsed := time.Since(l.lastRequest)
-	if elapsed < l.minDelay {
-		time.Sleep(l.minDelay - elapsed)
+// wait blocks until the next request is allowed and returns how long it
+// waited. Each caller reserves its own slot, so concurrent callers are
+// spaced minDelay apart. It returns ctx.Err() if ctx is done first.
+func (l *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	now := time.Now()
+	next := now
+	if earliest := l.lastRequest.Add(l.minDelay); earliest.After(now) {
+		next = earliest
+	}
+	l.lastRequest = next
+	l.mu.Unlock()
+
+	waited := next.Sub(now)
+	if waited <= 0 {
+		return 0, ctx.Err()
+	}
+	timer := time.NewTimer(waited)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return waited, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
 	}
-	l.lastRequest = time.Now()
 }
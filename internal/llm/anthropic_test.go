@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewAnthropicProvider(t *testing.T) {
+	warm := 0.7
+	tests := []struct {
+		name        string
+		config      AnthropicConfig
+		model       string
+		maxTokens   int
+		temperature float64
+	}{
+		{"defaults", AnthropicConfig{}, DefaultModel, DefaultMaxTokens, DefaultTemperature},
+		{"overrides", AnthropicConfig{Model: "claude-sonnet-4-5", MaxTokens: 4000, Temperature: &warm}, "claude-sonnet-4-5", 4000, 0.7},
+		{"reproducible", AnthropicConfig{Temperature: &warm, Reproducible: true}, DefaultModel, DefaultMaxTokens, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.APIKey = "test"
+			tt.config.CacheDir = t.TempDir()
+			provider, err := NewAnthropicProvider(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := provider.(*AnthropicProvider)
+			if p.model != tt.model || p.maxTokens != tt.maxTokens || p.temperature != tt.temperature {
+				t.Errorf("got model %q, max tokens %d, temperature %g", p.model, p.maxTokens, p.temperature)
+			}
+		})
+	}
+
+	request := SummarizeRequest{Type: SummaryTypeFile, Context: "File: main.go"}
+	short := &AnthropicProvider{model: DefaultModel, maxTokens: DefaultMaxTokens}
+	long := &AnthropicProvider{model: DefaultModel, maxTokens: 4000}
+	if short.getCacheKey(request) != short.defaultCacheKey(request) {
+		t.Error("Expected the default response cap to keep the cache key")
+	}
+	if short.getCacheKey(request) == long.getCacheKey(request) {
+		t.Error("Expected a different response cap to change the cache key")
+	}
+}
+
+func TestCacheKeyExplicit(t *testing.T) {
+	request := SummarizeRequest{Type: SummaryTypeFile, CacheKey: "filehash", Context: "File: main.go"}
+	providers := map[string]*AnthropicProvider{
+		"default":     {model: DefaultModel, maxTokens: DefaultMaxTokens, temperature: DefaultTemperature},
+		"other model": {model: "claude-sonnet-4-5", maxTokens: DefaultMaxTokens, temperature: DefaultTemperature},
+		"warm":        {model: DefaultModel, maxTokens: DefaultMaxTokens, temperature: 0.9},
+	}
+	keys := map[string]string{}
+	for name, p := range providers {
+		key := p.getCacheKey(request)
+		if key == request.CacheKey {
+			t.Errorf("%s: the explicit cache key was used as is", name)
+		}
+		for other, otherKey := range keys {
+			if key == otherKey {
+				t.Errorf("%s and %s share the cache key %s", name, other, key)
+			}
+		}
+		keys[name] = key
+	}
+
+	p := providers["default"]
+	changed := request
+	changed.Context = "File: main.go (edited)"
+	if p.getCacheKey(changed) != p.getCacheKey(request) {
+		t.Error("Expected the explicit cache key to stand in for the context")
+	}
+	functions := request
+	functions.Type = SummaryTypeFunction
+	if p.getCacheKey(functions) == p.getCacheKey(request) {
+		t.Error("Expected summary types to keep separate keys")
+	}
+}
+
+// cachedBy reports whether p answers request from its cache. The context
+// is cancelled, so a miss fails instead of calling the API.
+func cachedBy(t *testing.T, p Provider, request SummarizeRequest) bool {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := p.Summarize(ctx, request)
+	return err == nil
+}
+
+func TestCacheKeyFlags(t *testing.T) {
+	cacheDir := t.TempDir()
+	newProvider := func(config AnthropicConfig) *AnthropicProvider {
+		config.APIKey, config.CacheDir = "test", cacheDir
+		p, err := NewAnthropicProvider(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.(*AnthropicProvider)
+	}
+	request := SummarizeRequest{Type: SummaryTypeFile, CacheKey: "filehash"}
+	defaults := newProvider(AnthropicConfig{})
+	if err := defaults.saveToCache(context.Background(), defaults.getCacheKey(request), SummarizeResponse{Summary: "cached"}); err != nil {
+		t.Fatal(err)
+	}
+	if !cachedBy(t, defaults, request) {
+		t.Fatal("Expected the default provider to hit its own entry")
+	}
+
+	warm := 0.9
+	for name, config := range map[string]AnthropicConfig{
+		"--model":       {Model: "claude-sonnet-4-5"},
+		"--temperature": {Temperature: &warm},
+	} {
+		if cachedBy(t, newProvider(config), request) {
+			t.Errorf("%s: served the summary cached with the defaults", name)
+		}
+	}
+}
+
+func TestCacheKeyReproducible(t *testing.T) {
+	cacheDir := t.TempDir()
+	defaults, err := NewAnthropicProvider(AnthropicConfig{APIKey: "test", CacheDir: cacheDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reproducible, err := NewAnthropicProvider(AnthropicConfig{APIKey: "test", CacheDir: cacheDir, Reproducible: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := SummarizeRequest{Type: SummaryTypeFunction, CacheKey: "filehash-functions"}
+	p := defaults.(*AnthropicProvider)
+	if err := p.saveToCache(context.Background(), p.getCacheKey(request), SummarizeResponse{Summary: "cached"}); err != nil {
+		t.Fatal(err)
+	}
+	if cachedBy(t, reproducible, request) {
+		t.Error("Expected a reproducible run to skip summaries cached at a non-zero temperature")
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := &rateLimiter{minDelay: 20 * time.Millisecond}
+	var mu sync.Mutex
+	var starts []time.Time
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := limiter.wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			starts = append(starts, time.Now())
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	slices.SortFunc(starts, time.Time.Compare)
+	if elapsed := starts[len(starts)-1].Sub(starts[0]); elapsed < 4*limiter.minDelay-5*time.Millisecond {
+		t.Errorf("5 concurrent requests took %v, want them spaced %v apart", elapsed, limiter.minDelay)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter = &rateLimiter{minDelay: time.Hour, lastRequest: time.Now()}
+	if _, err := limiter.wait(ctx); err != context.Canceled {
+		t.Errorf("wait() = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cache stores serialized summaries by key. Implementations must treat a
+// missing key as (nil, false, nil) rather than an error.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// NewCache builds a cache from a location string: s3://bucket/prefix,
+// redis://[:password@]host:port/db, or a filesystem directory.
+func NewCache(location string) (Cache, error) {
+	switch {
+	case strings.HasPrefix(location, "s3://"):
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 cache URL: %w", err)
+		}
+		return NewS3Cache(S3CacheConfig{
+			Bucket:   u.Host,
+			Prefix:   strings.Trim(u.Path, "/"),
+			Region:   u.Query().Get("region"),
+			Endpoint: u.Query().Get("endpoint"),
+		})
+
+	case strings.HasPrefix(location, "redis://"):
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Redis cache URL: %w", err)
+		}
+		password, _ := u.User.Password()
+		return NewRedisCache(RedisCacheConfig{
+			Addr:     u.Host,
+			Password: password,
+			DB:       strings.Trim(u.Path, "/"),
+			Prefix:   u.Query().Get("prefix"),
+		})
+
+	default:
+		return NewFileCache(location)
+	}
+}
+
+type FileCache struct {
+	dir string
+}
+
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (c *FileCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Set writes through a temporary file so an interrupted run never leaves a
+// truncated entry behind.
+func (c *FileCache) Set(ctx context.Context, key string, value []byte) error {
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+func (c *FileCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type RedisCacheConfig struct {
+	Addr     string
+	Password string
+	DB       string
+	Prefix   string
+	TTL      time.Duration
+}
+
+// RedisCache speaks the minimal subset of RESP needed for GET/SET over a
+// single connection, so no client library is required. A connection that
+// fails is dropped, and the next command dials a new one.
+type RedisCache struct {
+	config RedisCacheConfig
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewRedisCache(config RedisCacheConfig) (*RedisCache, error) {
+	if config.Addr == "" {
+		config.Addr = "localhost:6379"
+	}
+	if config.Prefix == "" {
+		config.Prefix = "codedoc:"
+	}
+	if config.TTL == 0 {
+		config.TTL = 30 * 24 * time.Hour
+	}
+
+	cache := &RedisCache{config: config}
+	if err := cache.connect(); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := c.command(ctx, "GET", c.config.Prefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	return reply, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte) error {
+	ttl := strconv.Itoa(int(c.config.TTL.Seconds()))
+	_, err := c.command(ctx, "SET", c.config.Prefix+key, string(value), "EX", ttl)
+	return err
+}
+
+func (c *RedisCache) connect() error {
+	conn, err := net.DialTimeout("tcp", c.config.Addr, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Redis at %s: %w", c.config.Addr, err)
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	if c.config.Password != "" {
+		if _, err := c.roundTrip("AUTH", c.config.Password); err != nil {
+			c.disconnect()
+			return fmt.Errorf("redis AUTH failed: %w", err)
+		}
+	}
+	if c.config.DB != "" && c.config.DB != "0" {
+		if _, err := c.roundTrip("SELECT", c.config.DB); err != nil {
+			c.disconnect()
+			return fmt.Errorf("redis SELECT failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *RedisCache) disconnect() {
+	c.conn.Close()
+	c.conn, c.reader = nil, nil
+}
+
+func (c *RedisCache) command(ctx context.Context, args ...string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetDeadline(deadline)
+	} else {
+		c.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+
+	reply, err := c.roundTrip(args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// After an I/O error or a malformed reply the connection may be
+		// closed or out of step with the server.
+		c.disconnect()
+	}
+	return reply, err
+}
+
+func (c *RedisCache) roundTrip(args ...string) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("*%d\r\n", len(args)))
+	for _, arg := range args {
+		b.WriteString(fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg))
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+
+	return c.readReply()
+}
+
+func (c *RedisCache) readReply() ([]byte, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty Redis reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return []byte(line[1:]), nil
+	case '-':
+		return nil, redisError(line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return nil, err
+		}
+		return buf[:size], nil
+	default:
+		return nil, fmt.Errorf("unexpected Redis reply %q", line)
+	}
+}
+
+// redisError is an error reply from the server, which leaves the connection
+// usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
@@ -0,0 +1,179 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+type S3CacheConfig struct {
+	Bucket string
+	Prefix string
+	Region string
+	// Endpoint overrides the AWS endpoint for S3-compatible stores such as
+	// MinIO; requests then use path-style addressing.
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3Cache stores summaries as objects in an S3 bucket, signing requests with
+// AWS Signature Version 4. Credentials default to the standard AWS_*
+// environment variables.
+type S3Cache struct {
+	config S3CacheConfig
+	client *http.Client
+}
+
+func NewS3Cache(config S3CacheConfig) (*S3Cache, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 cache requires a bucket")
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.AccessKeyID == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 cache requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	return &S3Cache{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (c *S3Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	resp, err := c.do(ctx, "GET", key, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("S3 GET %s: status %d: %s", key, resp.StatusCode, string(body))
+	}
+
+	return body, true, nil
+}
+
+func (c *S3Cache) Set(ctx context.Context, key string, value []byte) error {
+	resp, err := c.do(ctx, "PUT", key, value)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("S3 PUT %s: status %d: %s", key, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (c *S3Cache) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	objectKey := path.Join(c.config.Prefix, key+".json")
+
+	var host, uri string
+	if c.config.Endpoint != "" {
+		endpoint := strings.TrimSuffix(c.config.Endpoint, "/")
+		host = strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
+		uri = "/" + c.config.Bucket + "/" + objectKey
+	} else {
+		host = fmt.Sprintf("%s.s3.%s.amazonaws.com", c.config.Bucket, c.config.Region)
+		uri = "/" + objectKey
+	}
+
+	scheme := "https"
+	if strings.HasPrefix(c.config.Endpoint, "http://") {
+		scheme = "http"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, scheme+"://"+host+uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	c.sign(req, host, uri, body, time.Now().UTC())
+
+	return c.client.Do(req)
+}
+
+func (c *S3Cache) sign(req *http.Request, host, uri string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Host", host)
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", host, payloadHash, amzDate)
+	if c.config.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", c.config.SessionToken)
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += fmt.Sprintf("x-amz-security-token:%s\n", c.config.SessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uri,
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, c.config.Region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.config.SecretAccessKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, c.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFileCache(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCacheRoundTrip(t, cache)
+}
+
+func TestS3Cache(t *testing.T) {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case "PUT":
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+		case "GET":
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	cache, err := NewS3Cache(S3CacheConfig{
+		Bucket:          "summaries",
+		Prefix:          "ci",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCacheRoundTrip(t, cache)
+
+	if _, ok := objects["/summaries/ci/key-1.json"]; !ok {
+		t.Errorf("Expected object at /summaries/ci/key-1.json, have %v", objects)
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer listener.Close()
+
+	go serveFakeRedis(listener)
+
+	cache, err := NewRedisCache(RedisCacheConfig{Addr: listener.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCacheRoundTrip(t, cache)
+}
+
+func TestRedisCacheReconnects(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer listener.Close()
+
+	// The first connection is closed at its first command, as by a Redis
+	// restart; later ones are served.
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		readRESPArray(bufio.NewReader(conn))
+		conn.Close()
+		serveFakeRedis(listener)
+	}()
+
+	cache, err := NewRedisCache(RedisCacheConfig{Addr: listener.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cache.Get(context.Background(), "key-1"); err == nil {
+		t.Fatal("Expected Get on the closed connection to fail")
+	}
+	testCacheRoundTrip(t, cache)
+}
+
+func testCacheRoundTrip(t *testing.T, cache Cache) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, ok, err := cache.Get(ctx, "missing"); err != nil || ok {
+		t.Fatalf("Get(missing) = ok %v, err %v; want miss", ok, err)
+	}
+
+	if err := cache.Set(ctx, "key-1", []byte(`{"Summary":"hello"}`)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	data, ok, err := cache.Get(ctx, "key-1")
+	if err != nil || !ok {
+		t.Fatalf("Get(key-1) = ok %v, err %v; want hit", ok, err)
+	}
+	if string(data) != `{"Summary":"hello"}` {
+		t.Errorf("Get(key-1) = %s", data)
+	}
+}
+
+func serveFakeRedis(listener net.Listener) {
+	store := make(map[string]string)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		reader := bufio.NewReader(conn)
+		for {
+			args, err := readRESPArray(reader)
+			if err != nil {
+				conn.Close()
+				break
+			}
+			switch strings.ToUpper(args[0]) {
+			case "GET":
+				if value, ok := store[args[1]]; ok {
+					fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+				} else {
+					io.WriteString(conn, "$-1\r\n")
+				}
+			case "SET":
+				store[args[1]] = args[2]
+				io.WriteString(conn, "+OK\r\n")
+			default:
+				io.WriteString(conn, "-ERR unknown command\r\n")
+			}
+		}
+	}
+}
+
+func readRESPArray(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
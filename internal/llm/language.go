@@ -0,0 +1,47 @@
+package llm
+
+import "strings"
+
+// languageNames names the output languages models are most often asked
+// for, so prompts can say "Japanese" rather than "ja".
+var languageNames = map[string]string{
+	"de":    "German",
+	"en":    "English",
+	"es":    "Spanish",
+	"fr":    "French",
+	"it":    "Italian",
+	"ja":    "Japanese",
+	"ko":    "Korean",
+	"nl":    "Dutch",
+	"pl":    "Polish",
+	"pt":    "Portuguese",
+	"pt-br": "Brazilian Portuguese",
+	"pt-pt": "European Portuguese",
+	"ru":    "Russian",
+	"sv":    "Swedish",
+	"tr":    "Turkish",
+	"uk":    "Ukrainian",
+	"zh":    "Simplified Chinese",
+	"zh-cn": "Simplified Chinese",
+	"zh-tw": "Traditional Chinese",
+}
+
+// LanguageName returns the English name of a BCP 47 language tag such as
+// ja or pt-BR, falling back to its primary language and then to the tag.
+func LanguageName(tag string) string {
+	tag = strings.ToLower(tag)
+	if name, ok := languageNames[tag]; ok {
+		return name
+	}
+	primary, _, _ := strings.Cut(tag, "-")
+	if name, ok := languageNames[primary]; ok {
+		return name
+	}
+	return "the language with BCP 47 tag " + tag
+}
+
+// isEnglish reports whether tag asks for the default output language.
+func isEnglish(tag string) bool {
+	primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	return primary == "" || primary == "en"
+}
@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutputLanguage(t *testing.T) {
+	english := &AnthropicProvider{model: DefaultModel}
+	japanese := &AnthropicProvider{model: DefaultModel, language: "ja"}
+
+	architecture := SummarizeRequest{Type: SummaryTypeArchitecture, Context: "Repository: orders", Constraints: Constraints{MaxWords: 200}}
+	if prompt := japanese.buildPrompt(architecture); !strings.Contains(prompt, "Write your answer in Japanese.") {
+		t.Errorf("Expected a Japanese instruction, got %q", prompt)
+	}
+	if japanese.getCacheKey(architecture) == english.getCacheKey(architecture) {
+		t.Error("Expected the output language to change the cache key")
+	}
+
+	tags := SummarizeRequest{Type: SummaryTypeTags, Context: "Repository: orders"}
+	if japanese.buildPrompt(tags) != english.buildPrompt(tags) || japanese.getCacheKey(tags) != english.getCacheKey(tags) {
+		t.Error("Expected tags to stay in English")
+	}
+
+	for _, tag := range []string{"", "en", "en-GB"} {
+		p := &AnthropicProvider{model: DefaultModel, language: tag}
+		if p.getCacheKey(architecture) != english.getCacheKey(architecture) {
+			t.Errorf("Expected %q to keep the English cache key", tag)
+		}
+	}
+}
+
+func TestLanguageName(t *testing.T) {
+	tests := map[string]string{
+		"ja":    "Japanese",
+		"pt-BR": "Brazilian Portuguese",
+		"de-AT": "German",
+		"tlh":   "the language with BCP 47 tag tlh",
+	}
+	for tag, want := range tests {
+		if got := LanguageName(tag); got != want {
+			t.Errorf("LanguageName(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
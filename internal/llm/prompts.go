@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// SummaryTypes lists every summary type, in the order prompts are documented.
+var SummaryTypes = []SummaryType{
+	SummaryTypeArchitecture, SummaryTypeModule, SummaryTypeFile, SummaryTypeFunction, SummaryTypeQuickstart,
+	SummaryTypeConfig, SummaryTypeTags, SummaryTypeSeams, SummaryTypeGlossary, SummaryTypeAnswer,
+	SummaryTypeDocComment, SummaryTypeChange, SummaryTypeChangelog,
+}
+
+// PromptData is what a prompt template is executed with.
+type PromptData struct {
+	Type       SummaryType
+	Context    string
+	MaxWords   int
+	MaxBullets int
+	Style      string
+	// Examples are the summaries reviewers rejected, formatted as in the
+	// built-in prompt, or empty.
+	Examples string
+	// Language names the language summaries are written in, such as
+	// Japanese, or is empty for English.
+	Language string
+	// Default is the built-in prompt, for templates that only add
+	// instructions to it.
+	Default string
+}
+
+// Prompts are user templates replacing the built-in prompt of their
+// summary type.
+type Prompts map[SummaryType]*Prompt
+
+type Prompt struct {
+	tmpl *template.Template
+	// Digest is the SHA-256 of the template source. It is part of the cache
+	// key, so editing a template invalidates the summaries it produced.
+	Digest string
+}
+
+// LoadPrompts parses every *.tmpl file in dir, each named after the summary
+// type whose prompt it replaces, such as architecture.tmpl.
+func LoadPrompts(dir string) (Prompts, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.tmpl files in %s", dir)
+	}
+
+	known := map[SummaryType]bool{}
+	names := []string{}
+	for _, summaryType := range SummaryTypes {
+		known[summaryType] = true
+		names = append(names, string(summaryType))
+	}
+
+	prompts := Prompts{}
+	for _, path := range paths {
+		summaryType := SummaryType(strings.TrimSuffix(filepath.Base(path), ".tmpl"))
+		if !known[summaryType] {
+			return nil, fmt.Errorf("prompt %s does not match a summary type; known types: %s", filepath.Base(path), strings.Join(names, ", "))
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(string(summaryType)).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse prompt: %w", err)
+		}
+		// Catch misspelled fields now rather than on the first request.
+		if err := tmpl.Execute(io.Discard, PromptData{}); err != nil {
+			return nil, fmt.Errorf("failed to execute prompt: %w", err)
+		}
+		sum := sha256.Sum256(content)
+		prompts[summaryType] = &Prompt{tmpl: tmpl, Digest: hex.EncodeToString(sum[:])}
+	}
+	return prompts, nil
+}
+
+func (p *Prompt) render(data PromptData) (string, error) {
+	var builder strings.Builder
+	if err := p.tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("failed to execute prompt: %w", err)
+	}
+	return builder.String(), nil
+}
+
+// Digests maps each overridden summary type to its template's digest, for
+// the report's provenance.
+func (p Prompts) Digests() map[string]string {
+	if len(p) == 0 {
+		return nil
+	}
+	digests := map[string]string{}
+	for summaryType, prompt := range p {
+		digests[string(summaryType)] = prompt.Digest
+	}
+	return digests
+}
+
+// Types lists the overridden summary types, sorted.
+func (p Prompts) Types() []string {
+	types := []string{}
+	for summaryType := range p {
+		types = append(types, string(summaryType))
+	}
+	sort.Strings(types)
+	return types
+}
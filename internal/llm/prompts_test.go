@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPrompts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"architecture.tmpl": "{{.Default}}\n\nMention SOC2 boundaries.",
+		"quickstart.tmpl":   "House style: imperative mood, at most {{.MaxBullets}} steps.\n\n{{.Context}}",
+		"notes.txt":         "not a prompt",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prompts, err := LoadPrompts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(prompts.Types(), ","); got != "architecture,quickstart" {
+		t.Fatalf("Types() = %s, want architecture,quickstart", got)
+	}
+
+	builtin := &AnthropicProvider{model: DefaultModel}
+	custom := &AnthropicProvider{model: DefaultModel, prompts: prompts}
+	architecture := SummarizeRequest{Type: SummaryTypeArchitecture, Context: "Repository: orders", Constraints: Constraints{MaxWords: 200}}
+	if got, want := custom.buildPrompt(architecture), builtin.buildPrompt(architecture)+"\n\nMention SOC2 boundaries."; got != want {
+		t.Errorf("architecture prompt = %q, want %q", got, want)
+	}
+	quickstart := SummarizeRequest{Type: SummaryTypeQuickstart, Context: "Build tools: go", Constraints: Constraints{MaxBullets: 5}}
+	if got, want := custom.buildPrompt(quickstart), "House style: imperative mood, at most 5 steps.\n\nBuild tools: go"; got != want {
+		t.Errorf("quickstart prompt = %q, want %q", got, want)
+	}
+	module := SummarizeRequest{Type: SummaryTypeModule, Context: "Module: api"}
+	if custom.buildPrompt(module) != builtin.buildPrompt(module) {
+		t.Error("Expected the built-in prompt for a type without a template")
+	}
+
+	if custom.getCacheKey(architecture) == builtin.getCacheKey(architecture) {
+		t.Error("Expected a custom prompt to change the cache key")
+	}
+	if custom.getCacheKey(module) != builtin.getCacheKey(module) {
+		t.Error("Expected the cache key of other types to be unchanged")
+	}
+}
+
+func TestLoadPromptsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{"unknown type", "architecure.tmpl", "{{.Context}}", "does not match a summary type"},
+		{"syntax", "module.tmpl", "{{.Context", "failed to parse prompt"},
+		{"unknown field", "module.tmpl", "{{.Contex}}", "failed to execute prompt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadPrompts(dir); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadPrompts() = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,19 @@ package llm
 import (
 	"context"
 	"fmt"
+	"net/http"
+)
+
+const (
+	DefaultModel       = "claude-3-haiku-20240307"
+	DefaultTemperature = 0.2
+	// DefaultMaxTokens caps the length of each response.
+	DefaultMaxTokens = 1000
+	// PromptVersion is bumped whenever buildPrompt output changes so reports
+	// can record which prompt set produced their summaries.
+	PromptVersion = "3"
+	// APIHost serves the Anthropic Messages API.
+	APIHost = "api.anthropic.com"
 )
 
 type Provider interface {
@@ -14,6 +27,18 @@ type SummarizeRequest struct {
 	Context     string
 	Constraints Constraints
 	CacheKey    string
+	// SkipCache asks for a fresh response, which then replaces the cached one.
+	SkipCache bool
+	// Examples are earlier summaries of the same kind that reviewers
+	// rejected, shown to the model as corrections.
+	Examples []Example
+}
+
+// Example is a rejected summary with the reviewer's correction, note or both.
+type Example struct {
+	Rejected   string `json:"rejected"`
+	Correction string `json:"correction,omitempty"`
+	Note       string `json:"note,omitempty"`
 }
 
 type SummarizeResponse struct {
@@ -30,6 +55,14 @@ const (
 	SummaryTypeFile         SummaryType = "file"
 	SummaryTypeFunction     SummaryType = "function"
 	SummaryTypeQuickstart   SummaryType = "quickstart"
+	SummaryTypeConfig       SummaryType = "config"
+	SummaryTypeTags         SummaryType = "tags"
+	SummaryTypeSeams        SummaryType = "seams"
+	SummaryTypeGlossary     SummaryType = "glossary"
+	SummaryTypeAnswer       SummaryType = "answer"
+	SummaryTypeDocComment   SummaryType = "doc_comment"
+	SummaryTypeChange       SummaryType = "change"
+	SummaryTypeChangelog    SummaryType = "changelog"
 )
 
 type Constraints struct {
@@ -41,8 +74,33 @@ type Constraints struct {
 type AnthropicConfig struct {
 	APIKey   string
 	CacheDir string
-	Force    bool
-	MaxQPS   float64
+	// Cache overrides CacheDir with a shared backend (see NewCache).
+	Cache  Cache
+	Force  bool
+	MaxQPS float64
+	// Model is the Anthropic model summaries are written with; empty means
+	// DefaultModel.
+	Model string
+	// MaxTokens caps the length of each response; 0 means DefaultMaxTokens.
+	MaxTokens int
+	// Models writes the summaries of these types with another model than
+	// Model, such as a larger one for architecture summaries. The models
+	// share the cache and rate limit.
+	Models map[SummaryType]string
+	// Temperature, when set, replaces DefaultTemperature unless Reproducible
+	// is set.
+	Temperature *float64
+	// Reproducible pins the model snapshot and uses temperature 0 so repeated
+	// runs over the same inputs produce comparable summaries.
+	Reproducible bool
+	// Prompts override the built-in prompts of their summary types.
+	Prompts Prompts
+	// OutputLanguage is a BCP 47 tag, such as ja or pt-BR, for the language
+	// summaries are written in; empty means English.
+	OutputLanguage string
+	// HTTPClient sends the API requests; nil means a client with a 60 second
+	// timeout.
+	HTTPClient *http.Client
 }
 
 type NoOpProvider struct{}
@@ -0,0 +1,21 @@
+package llm
+
+import "context"
+
+// RoutingProvider sends each request to the provider of its summary type,
+// and requests of other types to a fallback.
+type RoutingProvider struct {
+	fallback Provider
+	routes   map[SummaryType]Provider
+}
+
+func NewRoutingProvider(fallback Provider, routes map[SummaryType]Provider) Provider {
+	return &RoutingProvider{fallback: fallback, routes: routes}
+}
+
+func (p *RoutingProvider) Summarize(ctx context.Context, request SummarizeRequest) (SummarizeResponse, error) {
+	if provider, ok := p.routes[request.Type]; ok {
+		return provider.Summarize(ctx, request)
+	}
+	return p.fallback.Summarize(ctx, request)
+}
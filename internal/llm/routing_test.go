@@ -0,0 +1,48 @@
+package llm
+
+import (
+	"context"
+	"testing"
+)
+
+type modelProvider string
+
+func (p modelProvider) Summarize(ctx context.Context, request SummarizeRequest) (SummarizeResponse, error) {
+	return SummarizeResponse{Summary: string(p)}, nil
+}
+
+func TestRoutingProvider(t *testing.T) {
+	provider := NewRoutingProvider(modelProvider("haiku"), map[SummaryType]Provider{
+		SummaryTypeArchitecture: modelProvider("sonnet"),
+	})
+	for summaryType, want := range map[SummaryType]string{
+		SummaryTypeArchitecture: "sonnet",
+		SummaryTypeFile:         "haiku",
+	} {
+		response, err := provider.Summarize(context.Background(), SummarizeRequest{Type: summaryType})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Summary != want {
+			t.Errorf("%s request went to %s, want %s", summaryType, response.Summary, want)
+		}
+	}
+
+	routed, err := NewAnthropicProvider(AnthropicConfig{
+		APIKey:   "test",
+		CacheDir: t.TempDir(),
+		Models:   map[SummaryType]string{SummaryTypeArchitecture: "claude-sonnet-4-5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := routed.(*RoutingProvider)
+	fallback := router.fallback.(*AnthropicProvider)
+	architecture := router.routes[SummaryTypeArchitecture].(*AnthropicProvider)
+	if fallback.model != DefaultModel || architecture.model != "claude-sonnet-4-5" {
+		t.Errorf("models = %q and %q", fallback.model, architecture.model)
+	}
+	if architecture.limiter != fallback.limiter {
+		t.Error("Expected routed models to share the rate limit")
+	}
+}
@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type streamKey struct{}
+
+// WithStream returns a context under which providers that support it
+// stream their responses, passing each piece of text to onText, with the
+// type of the summary it belongs to, as it arrives. The response is still
+// returned whole; cached responses are not streamed.
+func WithStream(ctx context.Context, onText func(summaryType SummaryType, text string)) context.Context {
+	return context.WithValue(ctx, streamKey{}, onText)
+}
+
+// streamFor returns the function the text of a summaryType response is
+// streamed to, or nil when ctx asks for no streaming.
+func streamFor(ctx context.Context, summaryType SummaryType) func(text string) {
+	onText, _ := ctx.Value(streamKey{}).(func(SummaryType, string))
+	if onText == nil {
+		return nil
+	}
+	return func(text string) { onText(summaryType, text) }
+}
+
+// readStream reads a Messages API event stream, returning the text of the
+// response once the message stops.
+func readStream(body io.Reader, onText func(text string)) (string, error) {
+	var text strings.Builder
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event struct {
+			Type  string `json:"type"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return "", fmt.Errorf("invalid stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" {
+				text.WriteString(event.Delta.Text)
+				onText(event.Delta.Text)
+			}
+		case "error":
+			return "", fmt.Errorf("API error: %s", event.Error.Message)
+		case "message_stop":
+			return text.String(), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("response stream ended early")
+}
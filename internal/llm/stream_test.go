@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestReadStream(t *testing.T) {
+	events := func(data ...string) string {
+		var b strings.Builder
+		for _, d := range data {
+			b.WriteString("event: x\ndata: " + d + "\n\n")
+		}
+		return b.String()
+	}
+	tests := []struct {
+		name   string
+		stream string
+		want   string
+		pieces []string
+		err    string
+	}{
+		{
+			name: "text",
+			stream: events(`{"type":"message_start","message":{}}`,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+				`{"type":"ping"}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"The scanner "}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"walks files."}}`,
+				`{"type":"message_stop"}`),
+			want:   "The scanner walks files.",
+			pieces: []string{"The scanner ", "walks files."},
+		},
+		{
+			name: "error",
+			stream: events(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"The"}}`,
+				`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`),
+			pieces: []string{"The"},
+			err:    "Overloaded",
+		},
+		{
+			name:   "cut off",
+			stream: events(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"The"}}`),
+			pieces: []string{"The"},
+			err:    "ended early",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pieces := []string{}
+			got, err := readStream(strings.NewReader(tt.stream), func(text string) { pieces = append(pieces, text) })
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("err = %v, want %q", err, tt.err)
+				}
+			} else if err != nil || got != tt.want {
+				t.Errorf("readStream() = %q, %v, want %q", got, err, tt.want)
+			}
+			if strings.Join(pieces, "|") != strings.Join(tt.pieces, "|") {
+				t.Errorf("streamed %q, want %q", pieces, tt.pieces)
+			}
+		})
+	}
+
+	if streamFor(context.Background(), SummaryTypeFile) != nil {
+		t.Error("Expected no stream without WithStream")
+	}
+	var streamed SummaryType
+	ctx := WithStream(context.Background(), func(summaryType SummaryType, text string) { streamed = summaryType })
+	streamFor(ctx, SummaryTypeArchitecture)("The")
+	if streamed != SummaryTypeArchitecture {
+		t.Errorf("streamed type = %q", streamed)
+	}
+}
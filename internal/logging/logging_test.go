@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, "info", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Debug("hidden")
+	logger.Info("llm request", "type", "file", "duration_ms", 120)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected one record at info level, got %q", out.String())
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if record["msg"] != "llm request" || record["type"] != "file" {
+		t.Errorf("Unexpected record %v", record)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, name := range append(Levels, "WARNING") {
+		if _, err := ParseLevel(name); err != nil {
+			t.Errorf("ParseLevel(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,186 @@
+// Package notify sends alerts about architecture drift, such as new
+// endpoints, to the terminal, a webhook or a Slack channel.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/codepigeon/codedoc/internal/drift"
+)
+
+// maxItems caps each list in alert text; webhook payloads carry every item.
+const maxItems = 10
+
+// Alert lists what appeared in a repository since its previous analysis.
+type Alert struct {
+	Job        string   `json:"job"`
+	Repository string   `json:"repository"`
+	Commit     string   `json:"commit,omitempty"`
+	Since      string   `json:"since,omitempty"`
+	Endpoints  []string `json:"endpoints,omitempty"`
+	Models     []string `json:"models,omitempty"`
+	Frameworks []string `json:"frameworks,omitempty"`
+}
+
+// FromDrift builds the alert for the additions in changes. It returns false
+// when nothing was added; removals and findings are left to reports.
+func FromDrift(job string, changes *drift.Result) (Alert, bool) {
+	repository := job
+	if scan := changes.New.Scan; scan != nil && scan.RepoMetadata.Name != "" {
+		repository = scan.RepoMetadata.Name
+	}
+	alert := Alert{
+		Job:        job,
+		Repository: repository,
+		Commit:     knownCommit(changes.New.Commit),
+		Since:      knownCommit(changes.Old.Commit),
+		Endpoints:  changes.AddedEndpoints,
+		Models:     changes.AddedModels,
+		Frameworks: changes.AddedFrameworks,
+	}
+	return alert, len(alert.Endpoints)+len(alert.Models)+len(alert.Frameworks) > 0
+}
+
+// Text renders the alert for people. Items are wrapped in marker, such as
+// "`" for Slack, and bulleted with bullet.
+func (a Alert) Text(marker, bullet string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "codedoc: %s changed", a.Repository)
+	if a.Since != "" {
+		fmt.Fprintf(&b, " since %s", shortCommit(a.Since))
+	}
+	if a.Commit != "" {
+		fmt.Fprintf(&b, " (now at %s)", shortCommit(a.Commit))
+	}
+	b.WriteString("\n")
+
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"New endpoints", a.Endpoints},
+		{"New models", a.Models},
+		{"New frameworks", a.Frameworks},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s (%d):\n", section.title, len(section.items))
+		for _, item := range section.items[:min(maxItems, len(section.items))] {
+			fmt.Fprintf(&b, "%s %s%s%s\n", bullet, marker, item, marker)
+		}
+		if len(section.items) > maxItems {
+			fmt.Fprintf(&b, "%s and %d more\n", bullet, len(section.items)-maxItems)
+		}
+	}
+	return b.String()
+}
+
+// knownCommit drops the scanner's placeholder for repositories without git.
+func knownCommit(commit string) string {
+	if commit == "unknown" {
+		return ""
+	}
+	return commit
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// Sink delivers alerts.
+type Sink interface {
+	Send(ctx context.Context, alert Alert) error
+}
+
+// Config chooses where alerts go. Several sinks may be set at once.
+type Config struct {
+	Stdout  bool   `yaml:"stdout"`
+	Webhook string `yaml:"webhook"`
+	// Slack is a Slack incoming webhook URL.
+	Slack string `yaml:"slack"`
+}
+
+// Empty reports whether no sink is configured.
+func (c Config) Empty() bool {
+	return !c.Stdout && c.Webhook == "" && c.Slack == ""
+}
+
+// Sinks returns the configured sinks; stdout is where Stdout alerts go.
+func (c Config) Sinks(stdout io.Writer) []Sink {
+	client := &http.Client{Timeout: 10 * time.Second}
+	sinks := []Sink{}
+	if c.Stdout {
+		sinks = append(sinks, writerSink{w: stdout})
+	}
+	if c.Webhook != "" {
+		sinks = append(sinks, webhookSink{url: c.Webhook, client: client})
+	}
+	if c.Slack != "" {
+		sinks = append(sinks, slackSink{url: c.Slack, client: client})
+	}
+	return sinks
+}
+
+type writerSink struct {
+	w io.Writer
+}
+
+func (s writerSink) Send(ctx context.Context, alert Alert) error {
+	_, err := io.WriteString(s.w, alert.Text("", "  -")+"\n")
+	return err
+}
+
+// webhookSink posts the alert as JSON.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s webhookSink) Send(ctx context.Context, alert Alert) error {
+	return post(ctx, s.client, s.url, alert)
+}
+
+// slackSink posts the alert as a Slack message.
+type slackSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s slackSink) Send(ctx context.Context, alert Alert) error {
+	return post(ctx, s.client, s.url, map[string]string{"text": alert.Text("`", "•")})
+}
+
+func post(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("alert delivery failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("alert delivery failed: %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/drift"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestFromDrift(t *testing.T) {
+	before := drift.Snapshot{
+		Commit:    "1111111aaaa",
+		Scan:      &scanner.Result{},
+		Detection: &detect.Result{Endpoints: []detect.Endpoint{{Method: "GET", Path: "/orders"}, {Method: "DELETE", Path: "/legacy"}}},
+	}
+	after := drift.Snapshot{
+		Commit: "2222222bbbb",
+		Scan:   &scanner.Result{RepoMetadata: scanner.RepoMetadata{Name: "orders"}},
+		Detection: &detect.Result{
+			Endpoints:  []detect.Endpoint{{Method: "GET", Path: "/orders"}, {Method: "POST", Path: "/orders"}},
+			Models:     []detect.Model{{Name: "Refund"}},
+			Frameworks: []detect.Framework{{Name: "gin", Confidence: detect.ConfidenceHigh}},
+		},
+	}
+
+	alert, ok := FromDrift("api", drift.Compare(before, after))
+	want := Alert{
+		Job: "api", Repository: "orders", Commit: "2222222bbbb", Since: "1111111aaaa",
+		Endpoints: []string{"POST /orders"}, Models: []string{"Refund"}, Frameworks: []string{"gin"},
+	}
+	if !ok || !reflect.DeepEqual(alert, want) {
+		t.Fatalf("FromDrift() = %+v, %v, want %+v", alert, ok, want)
+	}
+
+	wantText := "codedoc: orders changed since 1111111 (now at 2222222)\n" +
+		"New endpoints (1):\n• `POST /orders`\n" +
+		"New models (1):\n• `Refund`\n" +
+		"New frameworks (1):\n• `gin`\n"
+	if got := alert.Text("`", "•"); got != wantText {
+		t.Errorf("Text() =\n%s\nwant\n%s", got, wantText)
+	}
+
+	// Removals alone are not worth an alert.
+	empty := drift.Snapshot{Scan: &scanner.Result{}, Detection: &detect.Result{}}
+	if _, ok := FromDrift("api", drift.Compare(after, empty)); ok {
+		t.Error("Expected no alert when nothing was added")
+	}
+}
+
+func TestSinks(t *testing.T) {
+	received := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received[r.URL.Path] = string(body)
+		if r.URL.Path == "/broken" {
+			http.Error(w, "no such channel", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	endpoints := []string{}
+	for i := 0; i < 12; i++ {
+		endpoints = append(endpoints, fmt.Sprintf("GET /v%d", i))
+	}
+	alert := Alert{Job: "api", Repository: "orders", Endpoints: endpoints}
+
+	var stdout bytes.Buffer
+	config := Config{Stdout: true, Webhook: server.URL + "/hook", Slack: server.URL + "/slack"}
+	for _, sink := range config.Sinks(&stdout) {
+		if err := sink.Send(context.Background(), alert); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !strings.Contains(stdout.String(), "  - GET /v9\n  - and 2 more\n") {
+		t.Errorf("Unexpected stdout alert:\n%s", stdout.String())
+	}
+	var webhook Alert
+	if err := json.Unmarshal([]byte(received["/hook"]), &webhook); err != nil || len(webhook.Endpoints) != 12 {
+		t.Errorf("Expected every endpoint in the webhook payload, got %s", received["/hook"])
+	}
+	var slack map[string]string
+	if err := json.Unmarshal([]byte(received["/slack"]), &slack); err != nil || !strings.HasPrefix(slack["text"], "codedoc: orders changed\nNew endpoints (12):\n• `GET /v0`") {
+		t.Errorf("Unexpected Slack payload %s", received["/slack"])
+	}
+
+	broken := Config{Webhook: server.URL + "/broken"}.Sinks(nil)[0]
+	if err := broken.Send(context.Background(), alert); err == nil || !strings.Contains(err.Error(), "404 Not Found: no such channel") {
+		t.Errorf("Send() = %v, want the server's error", err)
+	}
+}
@@ -0,0 +1,274 @@
+// Package osv looks up known vulnerabilities for pinned dependencies using
+// the OSV.dev API.
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codepigeon/codedoc/internal/deps"
+)
+
+const (
+	DefaultBaseURL = "https://api.osv.dev"
+	// batchSize is the querybatch limit documented by OSV.dev.
+	batchSize = 1000
+)
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Vulnerability is an advisory affecting one dependency.
+type Vulnerability struct {
+	ID         string
+	Aliases    []string
+	Summary    string
+	Severity   string
+	Fixed      []string
+	Dependency deps.Dependency
+}
+
+type query struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Version string `json:"version"`
+	// PageToken asks for the next page of a result that had too many
+	// vulnerabilities for one response.
+	PageToken string `json:"page_token,omitempty"`
+}
+
+type advisory struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Aliases  []string `json:"aliases"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package struct {
+			Name      string `json:"name"`
+			Ecosystem string `json:"ecosystem"`
+		} `json:"package"`
+		Ranges []struct {
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+		DatabaseSpecific struct {
+			Severity string `json:"severity"`
+		} `json:"database_specific"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// Audit returns the known vulnerabilities for the given dependencies.
+// Dependencies without an exact version are skipped.
+func (c *Client) Audit(ctx context.Context, dependencies []deps.Dependency) ([]Vulnerability, error) {
+	pinned := []deps.Dependency{}
+	for _, dep := range dependencies {
+		if dep.Version != "" {
+			pinned = append(pinned, dep)
+		}
+	}
+
+	vulnerabilities := []Vulnerability{}
+	advisories := make(map[string]advisory)
+
+	for start := 0; start < len(pinned); start += batchSize {
+		batch := pinned[start:min(start+batchSize, len(pinned))]
+
+		ids, err := c.queryBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, depIDs := range ids {
+			for _, id := range depIDs {
+				adv, ok := advisories[id]
+				if !ok {
+					adv, err = c.fetch(ctx, id)
+					if err != nil {
+						return nil, err
+					}
+					advisories[id] = adv
+				}
+				vulnerabilities = append(vulnerabilities, toVulnerability(adv, batch[i]))
+			}
+		}
+	}
+
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		if vulnerabilities[i].Dependency.Name != vulnerabilities[j].Dependency.Name {
+			return vulnerabilities[i].Dependency.Name < vulnerabilities[j].Dependency.Name
+		}
+		return vulnerabilities[i].ID < vulnerabilities[j].ID
+	})
+
+	return vulnerabilities, nil
+}
+
+func (c *Client) queryBatch(ctx context.Context, batch []deps.Dependency) ([][]string, error) {
+	queries := make([]query, len(batch))
+	for i, dep := range batch {
+		queries[i].Package.Name = dep.Name
+		queries[i].Package.Ecosystem = dep.Ecosystem
+		queries[i].Version = dep.Version
+		// Only Go module versions carry a "v" prefix in OSV.
+		if dep.Ecosystem != deps.EcosystemGo {
+			queries[i].Version = strings.TrimPrefix(dep.Version, "v")
+		}
+	}
+
+	// A result with a next_page_token has more vulnerabilities; its query is
+	// sent again with the token until the last page.
+	ids := make([][]string, len(batch))
+	pending := make([]int, len(batch))
+	for i := range pending {
+		pending[i] = i
+	}
+	for len(pending) > 0 {
+		page := make([]query, len(pending))
+		for i, index := range pending {
+			page[i] = queries[index]
+		}
+		body, err := json.Marshal(map[string]any{"queries": page})
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			Results []struct {
+				Vulns []struct {
+					ID string `json:"id"`
+				} `json:"vulns"`
+				NextPageToken string `json:"next_page_token"`
+			} `json:"results"`
+		}
+		if err := c.do(ctx, http.MethodPost, "/v1/querybatch", body, &response); err != nil {
+			return nil, err
+		}
+
+		next := []int{}
+		for i, result := range response.Results {
+			if i >= len(pending) {
+				break
+			}
+			index := pending[i]
+			for _, vuln := range result.Vulns {
+				ids[index] = append(ids[index], vuln.ID)
+			}
+			if result.NextPageToken != "" {
+				queries[index].PageToken = result.NextPageToken
+				next = append(next, index)
+			}
+		}
+		pending = next
+	}
+	return ids, nil
+}
+
+func (c *Client) fetch(ctx context.Context, id string) (advisory, error) {
+	var adv advisory
+	err := c.do(ctx, http.MethodGet, "/v1/vulns/"+id, nil, &adv)
+	return adv, err
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out any) error {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(baseURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("osv request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	slog.DebugContext(ctx, "osv request", "method", method, "path", path,
+		"status", resp.StatusCode, "duration", time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("osv %s returned status %d", path, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func toVulnerability(adv advisory, dep deps.Dependency) Vulnerability {
+	vuln := Vulnerability{
+		ID:         adv.ID,
+		Aliases:    adv.Aliases,
+		Summary:    adv.Summary,
+		Dependency: dep,
+	}
+	if vuln.Summary == "" {
+		vuln.Summary, _, _ = strings.Cut(strings.TrimSpace(adv.Details), "\n")
+	}
+
+	rating := adv.DatabaseSpecific.Severity
+	for _, affected := range adv.Affected {
+		if affected.Package.Name != dep.Name {
+			continue
+		}
+		if rating == "" {
+			rating = affected.DatabaseSpecific.Severity
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if fixed := event["fixed"]; fixed != "" && !slices.Contains(vuln.Fixed, fixed) {
+					vuln.Fixed = append(vuln.Fixed, fixed)
+				}
+			}
+		}
+	}
+
+	vuln.Severity = normalizeSeverity(rating)
+	return vuln
+}
+
+// normalizeSeverity maps GHSA-style ratings onto codedoc's high/medium/low.
+// Advisories without a rating are treated as medium.
+func normalizeSeverity(rating string) string {
+	switch strings.ToUpper(rating) {
+	case "CRITICAL", "HIGH":
+		return "high"
+	case "LOW":
+		return "low"
+	default:
+		return "medium"
+	}
+}
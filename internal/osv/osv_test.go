@@ -0,0 +1,121 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/deps"
+)
+
+func TestAudit(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			var body struct {
+				Queries []query `json:"queries"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Queries) != 2 || body.Queries[0].Version != "4.17.20" {
+				t.Errorf("Unexpected queries: %+v", body.Queries)
+			}
+			w.Write([]byte(`{"results": [{"vulns": [{"id": "GHSA-1"}]}, {"vulns": [{"id": "GHSA-1"}]}]}`))
+		case "/v1/vulns/GHSA-1":
+			fetches++
+			w.Write([]byte(`{
+				"id": "GHSA-1",
+				"summary": "Prototype pollution",
+				"affected": [{
+					"package": {"name": "lodash", "ecosystem": "npm"},
+					"ranges": [{"events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}],
+					"database_specific": {"severity": "HIGH"}
+				}]
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	vulnerabilities, err := client.Audit(context.Background(), []deps.Dependency{
+		{Name: "lodash", Version: "v4.17.20", Ecosystem: deps.EcosystemNPM},
+		{Name: "lodash", Version: "4.17.19", Ecosystem: deps.EcosystemNPM},
+		{Name: "unpinned", Ecosystem: deps.EcosystemNPM},
+	})
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+
+	if len(vulnerabilities) != 2 {
+		t.Fatalf("Expected 2 vulnerabilities, got %+v", vulnerabilities)
+	}
+	if fetches != 1 {
+		t.Errorf("Expected advisory to be fetched once, got %d", fetches)
+	}
+	vuln := vulnerabilities[0]
+	if vuln.Severity != "high" || len(vuln.Fixed) != 1 || vuln.Fixed[0] != "4.17.21" {
+		t.Errorf("Unexpected vulnerability: %+v", vuln)
+	}
+}
+
+func TestAuditFollowsPageTokens(t *testing.T) {
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := strings.CutPrefix(r.URL.Path, "/v1/vulns/"); ok {
+			fmt.Fprintf(w, `{"id": %q}`, id)
+			return
+		}
+		var body struct {
+			Queries []query `json:"queries"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		switch pages {
+		case 1:
+			w.Write([]byte(`{"results": [{"vulns": [{"id": "GHSA-1"}], "next_page_token": "page-2"}, {"vulns": [{"id": "GHSA-2"}]}]}`))
+		case 2:
+			if len(body.Queries) != 1 || body.Queries[0].Package.Name != "lodash" || body.Queries[0].PageToken != "page-2" {
+				t.Errorf("Expected lodash queried again with its page token, got %+v", body.Queries)
+			}
+			w.Write([]byte(`{"results": [{"vulns": [{"id": "GHSA-3"}], "next_page_token": "page-3"}]}`))
+		case 3:
+			if len(body.Queries) != 1 || body.Queries[0].PageToken != "page-3" {
+				t.Errorf("Expected the page-3 token, got %+v", body.Queries)
+			}
+			w.Write([]byte(`{"results": [{"vulns": [{"id": "GHSA-4"}]}]}`))
+		default:
+			t.Errorf("Unexpected querybatch request %d: %+v", pages, body.Queries)
+			w.Write([]byte(`{"results": []}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	vulnerabilities, err := client.Audit(context.Background(), []deps.Dependency{
+		{Name: "lodash", Version: "4.17.20", Ecosystem: deps.EcosystemNPM},
+		{Name: "minimist", Version: "1.2.5", Ecosystem: deps.EcosystemNPM},
+	})
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+
+	got := []string{}
+	for _, vuln := range vulnerabilities {
+		got = append(got, vuln.Dependency.Name+" "+vuln.ID)
+	}
+	want := []string{"lodash GHSA-1", "lodash GHSA-3", "lodash GHSA-4", "minimist GHSA-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Audit() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package owners
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var codeownersLocations = []string{
+	"CODEOWNERS",
+	".github/CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+type Rule struct {
+	Pattern string
+	Owners  []string
+}
+
+type Owners struct {
+	File  string
+	Rules []Rule
+}
+
+// Load reads the first CODEOWNERS file found in the standard locations. It
+// returns nil when the repository has none.
+func Load(repoPath string) (*Owners, error) {
+	for _, location := range codeownersLocations {
+		file, err := os.Open(filepath.Join(repoPath, filepath.FromSlash(location)))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		owners := &Owners{File: location}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+				continue
+			}
+			if idx := strings.Index(line, " #"); idx >= 0 {
+				line = line[:idx]
+			}
+			fields := strings.Fields(line)
+			owners.Rules = append(owners.Rules, Rule{Pattern: fields[0], Owners: fields[1:]})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return owners, nil
+	}
+
+	return nil, nil
+}
+
+// OwnersOf returns the owners of a repository-relative path. As in GitHub,
+// the last matching rule wins.
+func (o *Owners) OwnersOf(relPath string) []string {
+	if o == nil {
+		return nil
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	for i := len(o.Rules) - 1; i >= 0; i-- {
+		if matchPattern(o.Rules[i].Pattern, relPath) {
+			return o.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// OwnersOfFiles returns everyone owning any of paths, those owning the most
+// files first.
+func (o *Owners) OwnersOfFiles(paths []string) []string {
+	counts := map[string]int{}
+	result := []string{}
+	for _, p := range paths {
+		for _, owner := range o.OwnersOf(p) {
+			if counts[owner] == 0 {
+				result = append(result, owner)
+			}
+			counts[owner]++
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return counts[result[i]] > counts[result[j]]
+	})
+	return result
+}
+
+// matchPattern implements the gitignore-style subset used by CODEOWNERS:
+// leading "/" anchors to the root, trailing "/" matches a directory and
+// everything below it, "*" stays within a path segment and "**" spans
+// segments. Patterns without a slash match at any depth.
+func matchPattern(pattern, relPath string) bool {
+	if pattern == "*" || pattern == "**" {
+		return true
+	}
+
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	if !anchored && !strings.Contains(pattern, "/") {
+		segments := strings.Split(relPath, "/")
+		for i, segment := range segments {
+			if ok, _ := path.Match(pattern, segment); ok {
+				if dirOnly && i == len(segments)-1 {
+					return false
+				}
+				return true
+			}
+		}
+		return false
+	}
+
+	pathSegments := strings.Split(relPath, "/")
+	patternSegments := strings.Split(pattern, "/")
+	return matchSegments(patternSegments, pathSegments)
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		// A pattern naming a directory covers everything beneath it.
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
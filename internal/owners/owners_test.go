@@ -0,0 +1,67 @@
+package owners
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"*", "any/file.go", true},
+		{"*.js", "web/src/app.js", true},
+		{"*.js", "web/src/app.ts", false},
+		{"/api/", "api/handler.go", true},
+		{"/api/", "internal/api/handler.go", false},
+		{"docs/", "internal/docs/readme.md", true},
+		{"docs/", "docs", false},
+		{"/internal/*.go", "internal/util.go", true},
+		{"/internal/**/test", "internal/a/b/test/x.go", true},
+		{"/cmd/codedoc/main.go", "cmd/codedoc/main.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.path, func(t *testing.T) {
+			result := matchPattern(tt.pattern, tt.path)
+			if result != tt.expected {
+				t.Errorf("matchPattern(%s, %s) = %v, want %v", tt.pattern, tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOwnersOfLastMatchWins(t *testing.T) {
+	o := &Owners{Rules: []Rule{
+		{Pattern: "*", Owners: []string{"@acme/platform"}},
+		{Pattern: "/api/", Owners: []string{"@acme/backend", "@alice"}},
+	}}
+
+	if got := o.OwnersOf("api/server.go"); len(got) != 2 || got[0] != "@acme/backend" {
+		t.Errorf("OwnersOf(api/server.go) = %v", got)
+	}
+	if got := o.OwnersOf("README.md"); len(got) != 1 || got[0] != "@acme/platform" {
+		t.Errorf("OwnersOf(README.md) = %v", got)
+	}
+
+	var none *Owners
+	if got := none.OwnersOf("x"); got != nil {
+		t.Errorf("nil Owners should own nothing, got %v", got)
+	}
+}
+
+func TestOwnersOfFiles(t *testing.T) {
+	o := &Owners{Rules: []Rule{
+		{Pattern: "*", Owners: []string{"@acme/platform"}},
+		{Pattern: "/api/", Owners: []string{"@acme/backend", "@alice"}},
+		{Pattern: "/api/billing/", Owners: []string{"@acme/payments"}},
+	}}
+
+	got := o.OwnersOfFiles([]string{"api/server.go", "api/billing/a.go", "api/billing/b.go", "api/routes.go"})
+	want := []string{"@acme/backend", "@alice", "@acme/payments"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("OwnersOfFiles() = %v, want %v", got, want)
+	}
+}
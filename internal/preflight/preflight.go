@@ -0,0 +1,289 @@
+// Package preflight sizes up a repository before analysis: what it holds,
+// what the settings would cover, what a run would cost, and which flags to
+// change.
+package preflight
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// Rough costs of a run, for DefaultModel. Prompts other than file summaries
+// carry condensed context of about the same size whatever the repository.
+const (
+	inputPricePerMillion  = 0.25
+	outputPricePerMillion = 1.25
+	promptTokens          = 600
+	contextTokens         = 3000
+	outputTokens          = 400
+	requestDuration       = 4 * time.Second
+	// maxModules is the most modules summarized.
+	maxModules = 10
+)
+
+// Thresholds for recommendations.
+const (
+	// minLanguageShare of the code lines makes a language worth analyzing.
+	minLanguageShare = 5.0
+	// largeRepository is the number of files from which --map-reduce and
+	// --files-per-module pay off.
+	largeRepository = 1000
+	// maxRecommendedFiles caps --max-files; past it, --per-project or a
+	// narrower --lang serve better.
+	maxRecommendedFiles = 5000
+)
+
+// Settings are the generate flags the plan depends on.
+type Settings struct {
+	MaxFiles        int
+	MaxLinesPerFile int
+	TopFiles        int
+	FilesPerModule  int
+	IncludeTests    bool
+	MapReduce       bool
+	Languages       []string
+}
+
+type Plan struct {
+	Files int   `json:"files"`
+	Lines int   `json:"lines"`
+	Tests int   `json:"tests"`
+	Bytes int64 `json:"bytes"`
+	// Languages are all languages found, most lines first.
+	Languages []Language `json:"languages"`
+	// Scanned is the number of files the settings would analyze, at most
+	// MaxFiles; Selected is how many they match.
+	Scanned  int      `json:"scanned"`
+	Selected int      `json:"selected"`
+	Estimate Estimate `json:"estimate"`
+	// Recommended estimates the run with the recommendations taken.
+	Recommended     *Estimate        `json:"recommended,omitempty"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+type Language struct {
+	Name       string  `json:"name"`
+	Files      int     `json:"files"`
+	Lines      int     `json:"lines"`
+	Percentage float64 `json:"percentage"`
+	// Code is false for documentation and configuration formats, which
+	// Percentage leaves out.
+	Code     bool `json:"code"`
+	Analyzed bool `json:"analyzed"`
+}
+
+// Estimate is the expected LLM usage of a run with an empty cache.
+type Estimate struct {
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	Seconds      int     `json:"seconds"`
+	Model        string  `json:"model"`
+}
+
+func (e Estimate) String() string {
+	duration := fmt.Sprintf("%ds", e.Seconds)
+	if e.Seconds >= 60 {
+		duration = fmt.Sprintf("%dm", (e.Seconds+30)/60)
+	}
+	return fmt.Sprintf("~%d LLM requests, ~$%.2f, ~%s", e.Requests, e.CostUSD, duration)
+}
+
+// Recommendation is a flag to pass, and why.
+type Recommendation struct {
+	Flag   string `json:"flag"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Arg is the recommendation as a command-line argument.
+func (r Recommendation) Arg() string {
+	if r.Value == "" {
+		return "--" + r.Flag
+	}
+	return "--" + r.Flag + " " + r.Value
+}
+
+// New plans a run with settings over the files in census.
+func New(census *scanner.Census, settings Settings) *Plan {
+	plan := &Plan{Languages: []Language{}, Recommendations: []Recommendation{}}
+
+	languages := map[string]*Language{}
+	codeLines := 0
+	for _, file := range census.Files {
+		plan.Files++
+		plan.Lines += file.Lines
+		plan.Bytes += file.Size
+		if file.IsTest {
+			plan.Tests++
+		}
+		language := languages[file.Language]
+		if language == nil {
+			language = &Language{Name: file.Language, Code: scanner.IsCodeLanguage(file.Language) && file.Language != "unknown"}
+			languages[file.Language] = language
+		}
+		language.Files++
+		language.Lines += file.Lines
+		if language.Code {
+			codeLines += file.Lines
+		}
+	}
+
+	analyzed := map[string]bool{}
+	for _, file := range census.Selected(settings.Languages, true) {
+		analyzed[file.Language] = true
+	}
+	for _, language := range languages {
+		language.Analyzed = analyzed[language.Name]
+		if language.Code && codeLines > 0 {
+			language.Percentage = float64(language.Lines) * 100 / float64(codeLines)
+		}
+		plan.Languages = append(plan.Languages, *language)
+	}
+	sort.Slice(plan.Languages, func(i, j int) bool {
+		if plan.Languages[i].Lines != plan.Languages[j].Lines {
+			return plan.Languages[i].Lines > plan.Languages[j].Lines
+		}
+		return plan.Languages[i].Name < plan.Languages[j].Name
+	})
+
+	plan.Selected = len(census.Selected(settings.Languages, settings.IncludeTests))
+	plan.Scanned = min(plan.Selected, settings.MaxFiles)
+
+	plan.Estimate = estimate(census.Selected(settings.Languages, settings.IncludeTests), settings)
+	if recommended := plan.recommend(census, settings); len(plan.Recommendations) > 0 {
+		e := estimate(census.Selected(recommended.Languages, recommended.IncludeTests), recommended)
+		plan.Recommended = &e
+	}
+	return plan
+}
+
+// recommend fills in the recommendations and returns settings with them
+// applied.
+func (p *Plan) recommend(census *scanner.Census, settings Settings) Settings {
+	missing, shares := []string{}, []string{}
+	for _, language := range p.Languages {
+		if language.Code && !language.Analyzed && language.Percentage >= minLanguageShare {
+			missing = append(missing, language.Name)
+			shares = append(shares, fmt.Sprintf("%s (%.0f%% of the code)", language.Name, language.Percentage))
+		}
+	}
+	if len(missing) > 0 {
+		// Keep the configured languages that match files.
+		languages := []string{}
+		for _, language := range p.Languages {
+			if language.Analyzed {
+				languages = append(languages, language.Name)
+			}
+		}
+		languages = append(languages, missing...)
+		p.Recommendations = append(p.Recommendations, Recommendation{
+			Flag:   "lang",
+			Value:  strings.Join(languages, ","),
+			Reason: strings.Join(shares, ", ") + " not analyzed",
+		})
+		settings.Languages = languages
+	}
+
+	selected := len(census.Selected(settings.Languages, settings.IncludeTests))
+	if selected > settings.MaxFiles {
+		value := min((selected+99)/100*100, maxRecommendedFiles)
+		reason := fmt.Sprintf("%d files match but only %d would be scanned", selected, settings.MaxFiles)
+		if value < selected {
+			reason += "; split the repository with --per-project or narrow --lang to cover the rest"
+		}
+		if value > settings.MaxFiles {
+			p.Recommendations = append(p.Recommendations, Recommendation{Flag: "max-files", Value: strconv.Itoa(value), Reason: reason})
+			settings.MaxFiles = value
+		}
+	}
+
+	if min(selected, settings.MaxFiles) >= largeRepository {
+		if settings.FilesPerModule == 0 {
+			p.Recommendations = append(p.Recommendations, Recommendation{
+				Flag:   "files-per-module",
+				Value:  "2",
+				Reason: fmt.Sprintf("%d top files cover little of a repository this size", settings.TopFiles),
+			})
+			settings.FilesPerModule = 2
+		}
+		if !settings.MapReduce {
+			p.Recommendations = append(p.Recommendations, Recommendation{
+				Flag:   "map-reduce",
+				Reason: "builds the architecture summary from module summaries instead of a condensed file list",
+			})
+			settings.MapReduce = true
+		}
+	}
+	return settings
+}
+
+func estimate(files []scanner.CensusFile, settings Settings) Estimate {
+	source := []scanner.CensusFile{}
+	for _, file := range files[:min(len(files), settings.MaxFiles)] {
+		if !file.IsTest {
+			source = append(source, file)
+		}
+	}
+
+	modules := moduleCount(source)
+	summarized := min(settings.TopFiles, len(source))
+	if settings.FilesPerModule > 0 {
+		// Files outside every module make one more group.
+		summarized = min(settings.FilesPerModule*(modules+1), len(source))
+	}
+	modules = min(modules, maxModules)
+
+	// Each summarized file takes a summary and a functions request over the
+	// same excerpt.
+	fileTokens := 0
+	if len(source) > 0 {
+		total := 0
+		for _, file := range source {
+			size := int(file.Size)
+			if file.Lines > settings.MaxLinesPerFile {
+				size = size / file.Lines * settings.MaxLinesPerFile
+			}
+			total += size / 4
+		}
+		fileTokens = total / len(source)
+	}
+	// Architecture, tags and quickstart, plus one per module.
+	other := 3 + modules
+
+	e := Estimate{
+		Requests:    other + 2*summarized,
+		InputTokens: other*(promptTokens+contextTokens) + 2*summarized*(promptTokens+fileTokens),
+		Model:       llm.DefaultModel,
+	}
+	e.OutputTokens = e.Requests * outputTokens
+	e.CostUSD = (float64(e.InputTokens)*inputPricePerMillion + float64(e.OutputTokens)*outputPricePerMillion) / 1e6
+	e.Seconds = int((time.Duration(e.Requests) * requestDuration).Seconds())
+	return e
+}
+
+// moduleCount counts the directories that look like modules to the
+// summaries: at most three levels deep, holding at least three files.
+func moduleCount(files []scanner.CensusFile) int {
+	dirFiles := map[string]int{}
+	for _, file := range files {
+		if dir := path.Dir(file.RelativePath); dir != "." {
+			dirFiles[dir]++
+		}
+	}
+	count := 0
+	for dir, files := range dirFiles {
+		if strings.Count(dir, "/") <= 2 && files >= 3 {
+			count++
+		}
+	}
+	return count
+}
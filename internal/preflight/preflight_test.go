@@ -0,0 +1,80 @@
+package preflight
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func census(counts map[string]int) *scanner.Census {
+	census := &scanner.Census{}
+	for language, count := range counts {
+		for i := range count {
+			census.Files = append(census.Files, scanner.CensusFile{
+				RelativePath: fmt.Sprintf("%s/pkg%d/file%d", language, i%20, i),
+				Language:     language,
+				Lines:        100,
+				Size:         4000,
+			})
+		}
+	}
+	return census
+}
+
+func TestNew(t *testing.T) {
+	settings := Settings{MaxFiles: 200, MaxLinesPerFile: 1000, TopFiles: 10, Languages: []string{"go", "yaml"}}
+
+	tests := []struct {
+		name     string
+		counts   map[string]int
+		settings Settings
+		want     []string
+	}{
+		{"covered", map[string]int{"go": 50, "yaml": 5}, settings, []string{}},
+		{"missing language", map[string]int{"go": 50, "python": 30, "shell": 1}, settings, []string{"--lang go,python"}},
+		{"too many files", map[string]int{"go": 450}, settings, []string{"--max-files 500"}},
+		{
+			"large repository", map[string]int{"go": 1200}, settings,
+			[]string{"--max-files 1200", "--files-per-module 2", "--map-reduce"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := New(census(tt.counts), tt.settings)
+			got := []string{}
+			for _, recommendation := range plan.Recommendations {
+				got = append(got, recommendation.Arg())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("New() recommends %v, want %v", got, tt.want)
+			}
+			if (plan.Recommended != nil) != (len(tt.want) > 0) {
+				t.Errorf("New() Recommended = %+v, want one only with recommendations", plan.Recommended)
+			}
+		})
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	// 40 files in 20 directories of two files: no modules, 10 top files with
+	// a summary and a functions request each, plus three overall requests.
+	plan := New(census(map[string]int{"go": 40}), Settings{MaxFiles: 200, MaxLinesPerFile: 50, TopFiles: 10, Languages: []string{"go"}})
+	want := Estimate{
+		Requests:     23,
+		InputTokens:  3*3600 + 20*(600+500),
+		OutputTokens: 23 * 400,
+		Seconds:      92,
+		CostUSD:      (32800*0.25 + 9200*1.25) / 1e6,
+		Model:        llm.DefaultModel,
+	}
+	if plan.Estimate != want {
+		t.Errorf("Estimate = %+v, want %+v", plan.Estimate, want)
+	}
+	if got := plan.Estimate.String(); got != "~23 LLM requests, ~$0.02, ~2m" {
+		t.Errorf("Estimate.String() = %q", got)
+	}
+}
@@ -0,0 +1,136 @@
+// Package profile keeps the analysis settings chosen for a repository in
+// .codedoc/profile.yaml, so later runs apply them without any flags.
+package profile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+// DefaultPath is where the profile lives, relative to the repository root.
+const DefaultPath = ".codedoc/profile.yaml"
+
+type Profile struct {
+	// Modules replace the key modules codedoc picks for summaries.
+	Modules []string `yaml:"modules,omitempty"`
+	// PriorityFiles are summarized before any other top file.
+	PriorityFiles []string `yaml:"priority_files,omitempty"`
+	// SuppressedRisks are risk rule IDs left out of the report.
+	SuppressedRisks []string `yaml:"suppressed_risks,omitempty"`
+	// Prompts add guidance to every request of a summary type, keyed by
+	// type, such as "architecture: Focus on the event pipeline."
+	Prompts map[string]string `yaml:"prompts,omitempty"`
+}
+
+// Path returns the profile of the repository at repoPath.
+func Path(repoPath string) string {
+	return filepath.Join(repoPath, filepath.FromSlash(DefaultPath))
+}
+
+// Load reads the profile at path. A missing file yields an empty profile.
+func Load(path string) (*Profile, error) {
+	p := &Profile{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Save writes the profile to path, creating its directory.
+func (p *Profile) Save(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Analysis settings applied to every codedoc run on this repository.\n")
+	buf.WriteString("# Edit freely; `codedoc generate --save-profile` rewrites it.\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(p); err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
+
+func (p *Profile) Validate() error {
+	for summaryType, guidance := range p.Prompts {
+		if !slices.Contains(llm.SummaryTypes, llm.SummaryType(summaryType)) {
+			names := []string{}
+			for _, known := range llm.SummaryTypes {
+				names = append(names, string(known))
+			}
+			return fmt.Errorf("unknown summary type %q; known types: %s", summaryType, strings.Join(names, ", "))
+		}
+		if strings.TrimSpace(guidance) == "" {
+			return fmt.Errorf("no guidance for %s summaries", summaryType)
+		}
+	}
+	return nil
+}
+
+func (p *Profile) Empty() bool {
+	return len(p.Modules) == 0 && len(p.PriorityFiles) == 0 && len(p.SuppressedRisks) == 0 && len(p.Prompts) == 0
+}
+
+// Override returns p with every setting given in override replacing its
+// own. Prompts are replaced per summary type.
+func (p *Profile) Override(override Profile) *Profile {
+	merged := *p
+	if len(override.Modules) > 0 {
+		merged.Modules = override.Modules
+	}
+	if len(override.PriorityFiles) > 0 {
+		merged.PriorityFiles = override.PriorityFiles
+	}
+	if len(override.SuppressedRisks) > 0 {
+		merged.SuppressedRisks = override.SuppressedRisks
+	}
+	if len(override.Prompts) > 0 {
+		merged.Prompts = map[string]string{}
+		for summaryType, guidance := range p.Prompts {
+			merged.Prompts[summaryType] = guidance
+		}
+		for summaryType, guidance := range override.Prompts {
+			merged.Prompts[summaryType] = guidance
+		}
+	}
+	return &merged
+}
+
+// Guide adds the profile's guidance for the request's summary type.
+func (p *Profile) Guide(request llm.SummarizeRequest) llm.SummarizeRequest {
+	guidance := strings.TrimSpace(p.Prompts[string(request.Type)])
+	if guidance == "" {
+		return request
+	}
+	request.Context += "\n\nRepository guidance: " + guidance
+	// Cached answers were written without the guidance; key on the full
+	// context instead.
+	request.CacheKey = ""
+	return request
+}
@@ -0,0 +1,86 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := Path(t.TempDir())
+
+	missing, err := Load(path)
+	if err != nil || !missing.Empty() {
+		t.Fatalf("Load(missing) = %+v, %v; want an empty profile", missing, err)
+	}
+
+	saved := &Profile{
+		Modules:         []string{"internal/orders"},
+		SuppressedRisks: []string{"orphan-code"},
+		Prompts:         map[string]string{"architecture": "Focus on the event pipeline."},
+	}
+	if err := saved.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("Load() = %+v, want %+v", loaded, saved)
+	}
+
+	if err := os.WriteFile(path, []byte("prompts:\n  summary: be brief\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), `unknown summary type "summary"`) {
+		t.Errorf("Load() error = %v, want an unknown summary type", err)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	saved := &Profile{
+		Modules:       []string{"internal/orders"},
+		PriorityFiles: []string{"cmd/api/main.go"},
+		Prompts:       map[string]string{"architecture": "saved", "module": "saved"},
+	}
+	got := saved.Override(Profile{
+		Modules: []string{"internal/billing"},
+		Prompts: map[string]string{"module": "flag"},
+	})
+	want := &Profile{
+		Modules:       []string{"internal/billing"},
+		PriorityFiles: []string{"cmd/api/main.go"},
+		Prompts:       map[string]string{"architecture": "saved", "module": "flag"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Override() = %+v, want %+v", got, want)
+	}
+	if saved.Prompts["module"] != "saved" {
+		t.Error("Override() changed the saved profile")
+	}
+}
+
+func TestGuide(t *testing.T) {
+	p := &Profile{Prompts: map[string]string{"module": "Name the owning team."}}
+
+	module := p.Guide(llm.SummarizeRequest{Type: llm.SummaryTypeModule, Context: "Module: orders", CacheKey: "orders"})
+	if module.Context != "Module: orders\n\nRepository guidance: Name the owning team." || module.CacheKey != "" {
+		t.Errorf("Guide(module) = %+v, want the guidance appended and no cache key", module)
+	}
+
+	file := llm.SummarizeRequest{Type: llm.SummaryTypeFile, Context: "main.go", CacheKey: "abc"}
+	if got := p.Guide(file); !reflect.DeepEqual(got, file) {
+		t.Errorf("Guide(file) = %+v, want it unchanged", got)
+	}
+}
+
+func TestPath(t *testing.T) {
+	if got, want := Path("repo"), filepath.Join("repo", ".codedoc", "profile.yaml"); got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,267 @@
+// Package progress reports pipeline stages on the terminal. On a TTY each
+// stage draws a live progress bar with an ETA; otherwise only completed
+// stages are printed so CI logs stay readable. A nil *Reporter is valid and
+// reports nothing, so library callers need not configure one.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	// Quiet prints nothing; errors are still returned to the caller.
+	Quiet Level = iota
+	// Normal prints stage progress and notices.
+	Normal
+	// Verbose also prints every item as it is processed.
+	Verbose
+)
+
+const (
+	barWidth    = 24
+	redrawEvery = 100 * time.Millisecond
+	// previewWidth is how much of a streamed response the bar shows.
+	previewWidth = 40
+)
+
+// PreviewWindow is how many runes from the end of a streamed response
+// callers need to keep for Preview; the extra covers collapsed whitespace.
+const PreviewWindow = 2 * previewWidth
+
+type Reporter struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level Level
+	tty   bool
+	now   func() time.Time
+
+	stage string
+	total int
+	done  int
+	item  string
+	// preview is the end of the response being streamed.
+	preview   string
+	started   time.Time
+	lastDraw  time.Time
+	drawnLine bool
+}
+
+// New returns a Reporter writing to out. Bars are drawn only when out is a
+// terminal.
+func New(out io.Writer, level Level) *Reporter {
+	return &Reporter{
+		out:   out,
+		level: level,
+		tty:   isTerminal(out),
+		now:   time.Now,
+	}
+}
+
+func isTerminal(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Stage starts a named stage of total items, finishing any stage still
+// open. A total of 0 means the item count is not known in advance.
+func (r *Reporter) Stage(name string, total int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.finishLocked("")
+	r.stage = name
+	r.total = total
+	r.done = 0
+	r.item = ""
+	r.preview = ""
+	r.started = r.now()
+	r.lastDraw = time.Time{}
+	r.drawLocked()
+}
+
+// Advance marks one item of the current stage as processed.
+func (r *Reporter) Advance(item string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done++
+	r.item = item
+	r.preview = ""
+	if r.level >= Verbose && item != "" {
+		r.clearLocked()
+		fmt.Fprintf(r.out, "  %s: %s\n", r.stage, item)
+	}
+	if r.now().Sub(r.lastDraw) >= redrawEvery || r.done == r.total {
+		r.drawLocked()
+	}
+}
+
+// Done finishes the current stage with a one-line summary.
+func (r *Reporter) Done(summary string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.finishLocked(summary)
+}
+
+// Preview shows the end of text, a response still arriving, after the
+// bar.
+func (r *Reporter) Preview(text string) {
+	if !r.Live() {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tail := []rune(strings.Join(strings.Fields(text), " "))
+	if len(tail) > previewWidth {
+		tail = append([]rune("…"), tail[len(tail)-previewWidth:]...)
+	}
+	r.preview = string(tail)
+	if r.now().Sub(r.lastDraw) >= redrawEvery {
+		r.drawLocked()
+	}
+}
+
+// Live reports whether a bar is drawn, so callers can skip streaming
+// responses nobody sees.
+func (r *Reporter) Live() bool {
+	return r != nil && r.tty && r.level >= Normal
+}
+
+// Enabled reports whether messages at level are printed, so callers can
+// skip work only done to report it.
+func (r *Reporter) Enabled(level Level) bool {
+	return r != nil && r.level >= level
+}
+
+// Infof prints a notice unless the reporter is quiet.
+func (r *Reporter) Infof(format string, args ...any) {
+	r.printf(Normal, format, args...)
+}
+
+// Debugf prints a detail only in verbose mode.
+func (r *Reporter) Debugf(format string, args ...any) {
+	r.printf(Verbose, format, args...)
+}
+
+// Write lets log output share the terminal with the progress bar: the bar is
+// cleared before p is written and redrawn after. Log records are written
+// regardless of the level.
+func (r *Reporter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clearLocked()
+	n, err := r.out.Write(p)
+	r.drawLocked()
+	return n, err
+}
+
+func (r *Reporter) printf(level Level, format string, args ...any) {
+	if !r.Enabled(level) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clearLocked()
+	fmt.Fprintf(r.out, format+"\n", args...)
+	r.drawLocked()
+}
+
+func (r *Reporter) finishLocked(summary string) {
+	if r.stage == "" {
+		return
+	}
+
+	r.clearLocked()
+	if r.level >= Normal {
+		line := r.stage
+		if summary != "" {
+			line += ": " + summary
+		} else if r.done > 0 {
+			line += fmt.Sprintf(": %d items", r.done)
+		}
+		fmt.Fprintf(r.out, "%s (%s)\n", line, formatDuration(r.now().Sub(r.started)))
+	}
+	r.stage = ""
+}
+
+func (r *Reporter) clearLocked() {
+	if r.drawnLine {
+		fmt.Fprint(r.out, "\r\033[K")
+		r.drawnLine = false
+	}
+}
+
+func (r *Reporter) drawLocked() {
+	if !r.tty || r.level < Normal || r.stage == "" {
+		return
+	}
+	r.lastDraw = r.now()
+	fmt.Fprint(r.out, "\r\033[K"+r.line())
+	r.drawnLine = true
+}
+
+// line renders the current stage, e.g.
+// "summarize [#########---------------] 6/16 ETA 12s internal/scanner/scanner.go".
+func (r *Reporter) line() string {
+	var b strings.Builder
+	b.WriteString(r.stage)
+
+	if r.total > 0 {
+		filled := barWidth * min(r.done, r.total) / r.total
+		b.WriteString(" [" + strings.Repeat("#", filled) + strings.Repeat("-", barWidth-filled) + "]")
+		b.WriteString(fmt.Sprintf(" %d/%d", r.done, r.total))
+		if eta, ok := r.eta(); ok {
+			b.WriteString(" ETA " + formatDuration(eta))
+		}
+	} else if r.done > 0 {
+		b.WriteString(fmt.Sprintf(" %d", r.done))
+	}
+
+	// The item is the last one done; a preview is of the next.
+	if r.preview != "" {
+		b.WriteString(" › " + r.preview)
+	} else if r.item != "" {
+		b.WriteString(" " + r.item)
+	}
+	return b.String()
+}
+
+// eta extrapolates the remaining time from the average time per item.
+func (r *Reporter) eta() (time.Duration, bool) {
+	if r.done == 0 || r.total <= r.done {
+		return 0, false
+	}
+	perItem := r.now().Sub(r.started) / time.Duration(r.done)
+	return perItem * time.Duration(r.total-r.done), true
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
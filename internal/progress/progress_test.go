@@ -0,0 +1,107 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestReporter(level Level, tty bool) (*Reporter, *bytes.Buffer, *time.Time) {
+	var out bytes.Buffer
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := New(&out, level)
+	r.tty = tty
+	r.now = func() time.Time { return clock }
+	return r, &out, &clock
+}
+
+func TestReporterLevels(t *testing.T) {
+	tests := []struct {
+		level    Level
+		expected []string
+		absent   []string
+	}{
+		{Quiet, nil, []string{"scan", "notice", "a.go"}},
+		{Normal, []string{"notice\n", "scan: 2 files (0s)\n"}, []string{"a.go"}},
+		{Verbose, []string{"  scan: a.go\n", "  scan: b.go\n", "scan: 2 files (0s)\n", "debug\n"}, nil},
+	}
+
+	for _, tt := range tests {
+		r, out, _ := newTestReporter(tt.level, false)
+		r.Infof("notice")
+		r.Debugf("debug")
+		r.Stage("scan", 0)
+		r.Advance("a.go")
+		r.Advance("b.go")
+		r.Done("2 files")
+
+		for _, want := range tt.expected {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("level %d: missing %q in %q", tt.level, want, out.String())
+			}
+		}
+		for _, unwanted := range tt.absent {
+			if strings.Contains(out.String(), unwanted) {
+				t.Errorf("level %d: unexpected %q in %q", tt.level, unwanted, out.String())
+			}
+		}
+		if strings.Contains(out.String(), "\r") {
+			t.Errorf("level %d: bars drawn on a non-terminal: %q", tt.level, out.String())
+		}
+	}
+}
+
+func TestReporterBarAndETA(t *testing.T) {
+	r, _, clock := newTestReporter(Normal, true)
+
+	r.Stage("summarize", 4)
+	*clock = clock.Add(2 * time.Second)
+	r.Advance("internal/a.go")
+
+	want := "summarize [######------------------] 1/4 ETA 6s internal/a.go"
+	if got := r.line(); got != want {
+		t.Errorf("line() = %q, want %q", got, want)
+	}
+}
+
+func TestReporterPreview(t *testing.T) {
+	r, _, clock := newTestReporter(Normal, true)
+
+	r.Stage("summarize", 4)
+	r.Advance("internal/a.go")
+	*clock = clock.Add(time.Second)
+	r.Preview("The scanner walks the repository,\nskipping vendored and generated files")
+
+	want := "summarize [######------------------] 1/4 ETA 3s › …y, skipping vendored and generated files"
+	if got := r.line(); got != want {
+		t.Errorf("line() = %q, want %q", got, want)
+	}
+	// The last PreviewWindow runes preview the same as the whole response.
+	long := []rune(strings.Repeat("Ünïcode   summary text\n", 50))
+	r.Preview(string(long))
+	full := r.line()
+	r.Preview(string(long[len(long)-PreviewWindow:]))
+	if got := r.line(); got != full {
+		t.Errorf("line() = %q, want %q", got, full)
+	}
+	r.Advance("internal/b.go")
+	if got := r.line(); strings.Contains(got, "›") {
+		t.Errorf("Expected Advance to clear the preview, got %q", got)
+	}
+
+	quiet, out, _ := newTestReporter(Normal, false)
+	quiet.Stage("summarize", 4)
+	quiet.Preview("ignored")
+	if quiet.Live() || out.Len() != 0 {
+		t.Errorf("Expected no preview on a non-terminal, got %q", out.String())
+	}
+}
+
+func TestNilReporter(t *testing.T) {
+	var r *Reporter
+	r.Stage("scan", 1)
+	r.Advance("a.go")
+	r.Done("")
+	r.Infof("ignored")
+}
@@ -0,0 +1,247 @@
+// Package readme writes a repository README from the analysis and keeps
+// the sections it wrote up to date, leaving everything else in the file to
+// its authors.
+package readme
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+const (
+	DefaultFileName = "README.md"
+	// maxLanguages bounds the language badges.
+	maxLanguages = 3
+)
+
+// Input is what the README is built from.
+type Input struct {
+	Name        string
+	Description string
+	// RepoURL is the repository's remote; GitHub remotes get workflow
+	// badges.
+	RepoURL string
+	// Languages are the code languages, most used first.
+	Languages    []string
+	License      string
+	Pipelines    []detect.Pipeline
+	Quickstart   []string
+	Architecture string
+	Frameworks   []string
+	Layout       []Dir
+}
+
+// Dir is one entry of the directory layout.
+type Dir struct {
+	// Path is a slash path relative to the repository.
+	Path    string
+	Files   int
+	Summary string
+}
+
+// Section is one codedoc-managed part of the README, between
+// <!-- codedoc:begin name --> and <!-- codedoc:end name --> markers.
+type Section struct {
+	Name string
+	// Heading is written above the markers when the section is added; the
+	// badges and description have none.
+	Heading string
+	Content string
+}
+
+// Sections returns the managed sections in README order, leaving out those
+// the analysis has nothing for.
+func Sections(in Input) []Section {
+	sections := []Section{}
+	add := func(name, heading, content string) {
+		if content = strings.TrimSpace(content); content != "" {
+			sections = append(sections, Section{Name: name, Heading: heading, Content: content})
+		}
+	}
+
+	add("badges", "", strings.Join(badges(in), "\n"))
+	add("description", "", in.Description)
+
+	var b strings.Builder
+	for i, step := range in.Quickstart {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+	}
+	add("quickstart", "Quickstart", b.String())
+
+	architecture := in.Architecture
+	if len(in.Frameworks) > 0 && architecture != "" {
+		architecture += "\n\nBuilt with " + strings.Join(in.Frameworks, ", ") + "."
+	}
+	add("architecture", "Architecture", architecture)
+
+	b.Reset()
+	for _, dir := range in.Layout {
+		indent := strings.Repeat("  ", strings.Count(dir.Path, "/"))
+		fmt.Fprintf(&b, "%s- `%s/`", indent, dir.Path)
+		if dir.Summary != "" {
+			fmt.Fprintf(&b, " — %s", dir.Summary)
+		} else {
+			fmt.Fprintf(&b, " (%d files)", dir.Files)
+		}
+		b.WriteString("\n")
+	}
+	add("layout", "Directory Layout", b.String())
+
+	return sections
+}
+
+func badges(in Input) []string {
+	badges := []string{}
+	if slug, ok := strings.CutPrefix(util.NormalizeRepoURL(in.RepoURL), "https://github.com/"); ok && in.RepoURL != "" {
+		for _, pipeline := range in.Pipelines {
+			if pipeline.System != "github-actions" {
+				continue
+			}
+			workflow := fmt.Sprintf("https://github.com/%s/actions/workflows/%s", slug, path.Base(pipeline.File))
+			badges = append(badges, fmt.Sprintf("[![%s](%s/badge.svg)](%s)", pipeline.Name, workflow, workflow))
+		}
+	}
+	for i, language := range in.Languages {
+		if i == maxLanguages {
+			break
+		}
+		badges = append(badges, badge("language", language, "informational"))
+	}
+	if in.License != "" {
+		badges = append(badges, badge("license", in.License, "lightgrey"))
+	}
+	return badges
+}
+
+// badge returns a static shields.io badge.
+func badge(label, message, color string) string {
+	escape := strings.NewReplacer("-", "--", "_", "__")
+	return fmt.Sprintf("![%s](https://img.shields.io/badge/%s-%s-%s)", label,
+		url.PathEscape(escape.Replace(label)), url.PathEscape(escape.Replace(message)), color)
+}
+
+// licenses recognizes license files by text they all contain, most
+// specific first.
+var licenses = []struct {
+	id      string
+	markers []string
+}{
+	{"MIT", []string{"MIT License"}},
+	{"Apache-2.0", []string{"Apache License", "Version 2.0"}},
+	{"AGPL-3.0", []string{"GNU AFFERO GENERAL PUBLIC LICENSE"}},
+	{"LGPL-3.0", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-3.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-2.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 2"}},
+	{"MPL-2.0", []string{"Mozilla Public License Version 2.0"}},
+	{"BSD-3-Clause", []string{"Redistribution and use in source and binary forms", "Neither the name"}},
+	{"BSD-2-Clause", []string{"Redistribution and use in source and binary forms"}},
+	{"Unlicense", []string{"This is free and unencumbered software"}},
+}
+
+// License returns the SPDX identifier of the license file at the root of
+// repoPath, or "" when there is none or it is not recognized.
+func License(repoPath string) string {
+	for _, name := range []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"} {
+		content, err := os.ReadFile(filepath.Join(repoPath, name))
+		if err != nil {
+			continue
+		}
+		for _, license := range licenses {
+			if containsAll(string(content), license.markers) {
+				return license.id
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+func containsAll(text string, markers []string) bool {
+	for _, marker := range markers {
+		if !strings.Contains(text, marker) {
+			return false
+		}
+	}
+	return true
+}
+
+var markerPattern = regexp.MustCompile(`(?s)<!-- codedoc:begin ([a-z]+) -->\n.*?<!-- codedoc:end ([a-z]+) -->`)
+
+func block(section Section) string {
+	return fmt.Sprintf("<!-- codedoc:begin %s -->\n%s\n<!-- codedoc:end %s -->", section.Name, section.Content, section.Name)
+}
+
+// Write creates the README at path from sections. When it exists, only the
+// sections between codedoc markers are rewritten; deleting a section's
+// markers keeps codedoc out of it. A README without any markers, written
+// by hand, gains the badges and description under its title and the other
+// sections at the end.
+func Write(path, name string, sections []Section) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(Merge(string(content), name, sections)), 0o644); err != nil {
+		return fmt.Errorf("failed to write README: %w", err)
+	}
+	return nil
+}
+
+// Merge returns existing, the current README or "", with sections
+// written in as Write describes.
+func Merge(existing, name string, sections []Section) string {
+	if markerPattern.MatchString(existing) {
+		byName := map[string]Section{}
+		for _, section := range sections {
+			byName[section.Name] = section
+		}
+		return markerPattern.ReplaceAllStringFunc(existing, func(match string) string {
+			names := markerPattern.FindStringSubmatch(match)
+			section, ok := byName[names[1]]
+			if !ok || names[1] != names[2] {
+				return match
+			}
+			return block(section)
+		})
+	}
+
+	lead, rest := []string{}, []string{}
+	for _, section := range sections {
+		if section.Heading == "" {
+			lead = append(lead, block(section))
+		} else {
+			rest = append(rest, "## "+section.Heading+"\n\n"+block(section))
+		}
+	}
+
+	if strings.TrimSpace(existing) == "" {
+		parts := append(append([]string{"# " + name}, lead...), rest...)
+		return strings.Join(parts, "\n\n") + "\n"
+	}
+
+	title, body := "", existing
+	if strings.HasPrefix(existing, "# ") {
+		title, body, _ = strings.Cut(existing, "\n")
+		title += "\n\n"
+	}
+	var b strings.Builder
+	b.WriteString(title)
+	for _, part := range lead {
+		b.WriteString(part + "\n\n")
+	}
+	b.WriteString(strings.Trim(body, "\n") + "\n")
+	for _, part := range rest {
+		b.WriteString("\n" + part + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package readme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+)
+
+func TestSections(t *testing.T) {
+	sections := Sections(Input{
+		Name:         "shop",
+		Description:  "Sells things.",
+		RepoURL:      "git@github.com:acme/shop.git",
+		Languages:    []string{"go", "objective-c", "shell", "python"},
+		License:      "Apache-2.0",
+		Pipelines:    []detect.Pipeline{{System: "github-actions", Name: "CI", File: ".github/workflows/ci.yml"}, {System: "jenkins", Name: "Jenkins", File: "Jenkinsfile"}},
+		Architecture: "A web shop.",
+		Frameworks:   []string{"gin"},
+		Layout:       []Dir{{Path: "cmd", Files: 3}, {Path: "cmd/shop", Files: 2, Summary: "Starts the server."}},
+	})
+
+	got := map[string]string{}
+	names := []string{}
+	for _, section := range sections {
+		got[section.Name] = section.Content
+		names = append(names, section.Name)
+	}
+	if strings.Join(names, ",") != "badges,description,architecture,layout" {
+		t.Errorf("sections = %v, want no quickstart without steps", names)
+	}
+	wantBadges := "[![CI](https://github.com/acme/shop/actions/workflows/ci.yml/badge.svg)](https://github.com/acme/shop/actions/workflows/ci.yml)\n" +
+		"![language](https://img.shields.io/badge/language-go-informational)\n" +
+		"![language](https://img.shields.io/badge/language-objective--c-informational)\n" +
+		"![language](https://img.shields.io/badge/language-shell-informational)\n" +
+		"![license](https://img.shields.io/badge/license-Apache--2.0-lightgrey)"
+	if got["badges"] != wantBadges {
+		t.Errorf("badges =\n%s\nwant\n%s", got["badges"], wantBadges)
+	}
+	if got["architecture"] != "A web shop.\n\nBuilt with gin." {
+		t.Errorf("architecture = %q", got["architecture"])
+	}
+	if got["layout"] != "- `cmd/` (3 files)\n  - `cmd/shop/` — Starts the server." {
+		t.Errorf("layout = %q", got["layout"])
+	}
+}
+
+func TestMerge(t *testing.T) {
+	sections := []Section{
+		{Name: "badges", Content: "![b](x)"},
+		{Name: "description", Content: "Sells things."},
+		{Name: "quickstart", Heading: "Quickstart", Content: "1. make"},
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{
+			name:     "new",
+			existing: "",
+			want: "# shop\n\n" +
+				"<!-- codedoc:begin badges -->\n![b](x)\n<!-- codedoc:end badges -->\n\n" +
+				"<!-- codedoc:begin description -->\nSells things.\n<!-- codedoc:end description -->\n\n" +
+				"## Quickstart\n\n<!-- codedoc:begin quickstart -->\n1. make\n<!-- codedoc:end quickstart -->\n",
+		},
+		{
+			name:     "hand-written",
+			existing: "# The Shop\nOur shop.\n",
+			want: "# The Shop\n\n" +
+				"<!-- codedoc:begin badges -->\n![b](x)\n<!-- codedoc:end badges -->\n\n" +
+				"<!-- codedoc:begin description -->\nSells things.\n<!-- codedoc:end description -->\n\n" +
+				"Our shop.\n\n" +
+				"## Quickstart\n\n<!-- codedoc:begin quickstart -->\n1. make\n<!-- codedoc:end quickstart -->\n",
+		},
+		{
+			name: "managed",
+			existing: "# The Shop\n\n<!-- codedoc:begin description -->\nOld.\nLines.\n<!-- codedoc:end description -->\n\n" +
+				"Our shop.\n\n<!-- codedoc:begin layout -->\n- `old/`\n<!-- codedoc:end layout -->\n",
+			want: "# The Shop\n\n<!-- codedoc:begin description -->\nSells things.\n<!-- codedoc:end description -->\n\n" +
+				"Our shop.\n\n<!-- codedoc:begin layout -->\n- `old/`\n<!-- codedoc:end layout -->\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Merge(tt.existing, "shop", sections); got != tt.want {
+				t.Errorf("Merge() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLicense(t *testing.T) {
+	repo := t.TempDir()
+	if got := License(repo); got != "" {
+		t.Errorf("License() = %q without a license file", got)
+	}
+	text := "MIT License\n\nCopyright (c) 2026 Acme\n"
+	if err := os.WriteFile(filepath.Join(repo, "LICENSE.md"), []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := License(repo); got != "MIT" {
+		t.Errorf("License() = %q, want MIT", got)
+	}
+}
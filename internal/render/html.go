@@ -0,0 +1,237 @@
+package render
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	boldPattern       = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	inlineCodePattern = regexp.MustCompile("`([^`]+)`")
+	linkPattern       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// htmlTableCollapseRows is the number of body rows shown before the rest of
+// a table is collapsed.
+const htmlTableCollapseRows = 25
+
+// Theme modes for HTML output.
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+	// ThemeAuto follows the reader's system preference.
+	ThemeAuto = "auto"
+)
+
+const lightColors = `:root { --fg: #1f2328; --bg: #ffffff; --border: #d0d7de; --subtle: #f6f8fa; --link: #0969da; }`
+
+const darkColors = `:root { --fg: #e6edf3; --bg: #0d1117; --border: #30363d; --subtle: #161b22; --link: #4493f8; }`
+
+const defaultStyle = `body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: var(--fg); background: var(--bg); }
+a { color: var(--link); }
+h1, h2, h3 { line-height: 1.25; }
+h2 { border-bottom: 1px solid var(--border); padding-bottom: .3em; margin-top: 2em; }
+table { border-collapse: collapse; width: 100%; margin: 1em 0; }
+th, td { border: 1px solid var(--border); padding: 6px 10px; text-align: left; vertical-align: top; }
+th { background: var(--subtle); }
+code, pre { font-family: ui-monospace, Menlo, Consolas, monospace; background: var(--subtle); }
+pre { padding: 1em; overflow-x: auto; }
+figure.diagram { margin: 1em 0; overflow-x: auto; }
+.brand { display: flex; align-items: center; gap: .75rem; padding-bottom: 1rem; border-bottom: 1px solid var(--border); font-weight: 600; }
+.brand img { max-height: 40px; }
+@media print { body { max-width: none; margin: 0; } h2 { page-break-after: avoid; } tr { page-break-inside: avoid; } }`
+
+// Theme customizes HTML output, and PDF output printed from it, to match
+// an organization's branding. The zero value is the light theme.
+type Theme struct {
+	Mode string
+	// CSS is added after the built-in style sheet, so it can override it.
+	CSS string
+	// Logo is an image URL shown in a header above the report; see
+	// LogoSource for local files.
+	Logo string
+	// Header is text shown in that header, such as the organization name.
+	Header string
+}
+
+func (t Theme) style() string {
+	style := lightColors + "\n"
+	switch t.Mode {
+	case ThemeDark:
+		style = darkColors + "\n"
+	case ThemeAuto:
+		style += "@media (prefers-color-scheme: dark) { " + darkColors + " }\n"
+	}
+	style += defaultStyle
+	if t.CSS != "" {
+		style += "\n" + t.CSS
+	}
+	return style
+}
+
+// LogoSource returns logo as an image source: URLs are kept and local
+// files are embedded as data URLs so reports stay self-contained.
+func LogoSource(logo string) (string, error) {
+	for _, scheme := range []string{"http://", "https://", "data:"} {
+		if strings.HasPrefix(logo, scheme) {
+			return logo, nil
+		}
+	}
+	data, err := os.ReadFile(logo)
+	if err != nil {
+		return "", fmt.Errorf("failed to read logo: %w", err)
+	}
+	mediaType := mime.TypeByExtension(strings.ToLower(filepath.Ext(logo)))
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", fmt.Errorf("logo %s is not an image", logo)
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// HTML renders report Markdown as a standalone HTML document. Mermaid
+// diagrams are drawn when MermaidCLI is on PATH.
+func HTML(ctx context.Context, markdown, title string, theme Theme) string {
+	frontMatter, blocks := Parse(markdown)
+	drawDiagrams(ctx, blocks)
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString(fmt.Sprintf("<title>%s</title>\n", html.EscapeString(title)))
+	b.WriteString("<style>\n" + theme.style() + "\n</style>\n")
+	if frontMatter != "" {
+		b.WriteString("<!--\n" + strings.ReplaceAll(frontMatter, "--", "- -") + "\n-->\n")
+	}
+	b.WriteString("</head>\n<body>\n")
+	if theme.Logo != "" || theme.Header != "" {
+		b.WriteString("<header class=\"brand\">")
+		if theme.Logo != "" {
+			b.WriteString(fmt.Sprintf("<img src=\"%s\" alt=\"\">", html.EscapeString(theme.Logo)))
+		}
+		if theme.Header != "" {
+			b.WriteString("<span>" + html.EscapeString(theme.Header) + "</span>")
+		}
+		b.WriteString("</header>\n")
+	}
+	b.WriteString(HTMLBody(blocks))
+	b.WriteString("</body>\n</html>\n")
+
+	return b.String()
+}
+
+func HTMLBody(blocks []Block) string {
+	var b strings.Builder
+
+	for _, block := range blocks {
+		switch block.Kind {
+		case BlockHeading:
+			b.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", block.Level, inlineHTML(block.Lines[0]), block.Level))
+
+		case BlockParagraph:
+			b.WriteString("<p>" + inlineHTML(strings.Join(block.Lines, " ")) + "</p>\n")
+
+		case BlockList:
+			tag := "ul"
+			if block.Ordered {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for _, item := range block.Lines {
+				parts := strings.Split(item, "\n")
+				for i, part := range parts {
+					parts[i] = inlineHTML(part)
+				}
+				b.WriteString("<li>" + strings.Join(parts, "<br>\n") + "</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+
+		case BlockTable:
+			writeHTMLTable(&b, block.Rows)
+
+		case BlockCode:
+			if block.SVG != "" {
+				b.WriteString("<figure class=\"diagram\">\n" + block.SVG + "\n</figure>\n")
+				break
+			}
+			b.WriteString("<pre><code>" + html.EscapeString(strings.Join(block.Lines, "\n")) + "</code></pre>\n")
+		}
+	}
+
+	return b.String()
+}
+
+// writeHTMLTable renders a table, moving rows past htmlTableCollapseRows into
+// a collapsed <details> block so long tables don't dominate the page.
+func writeHTMLTable(b *strings.Builder, rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+
+	header := rows[0]
+	body := rows[1:]
+	visible := body
+	var hidden [][]string
+	if len(body) > htmlTableCollapseRows {
+		visible = body[:htmlTableCollapseRows]
+		hidden = body[htmlTableCollapseRows:]
+	}
+
+	writeRows := func(rows [][]string) {
+		b.WriteString("<table>\n")
+		writeHTMLRow(b, header, "th")
+		for _, row := range rows {
+			writeHTMLRow(b, row, "td")
+		}
+		b.WriteString("</table>\n")
+	}
+
+	writeRows(visible)
+	if len(hidden) > 0 {
+		b.WriteString(fmt.Sprintf("<details><summary>Show %d more rows</summary>\n", len(hidden)))
+		writeRows(hidden)
+		b.WriteString("</details>\n")
+	}
+}
+
+func writeHTMLRow(b *strings.Builder, row []string, cell string) {
+	b.WriteString("<tr>")
+	for _, value := range row {
+		b.WriteString(fmt.Sprintf("<%s>%s</%s>", cell, inlineHTML(value), cell))
+	}
+	b.WriteString("</tr>\n")
+}
+
+func inlineHTML(text string) string {
+	text = html.EscapeString(text)
+	text = inlineCodePattern.ReplaceAllString(text, "<code>$1</code>")
+	text = boldPattern.ReplaceAllString(text, "<strong>$1</strong>")
+	text = linkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		match := linkPattern.FindStringSubmatch(link)
+		if !safeLinkTarget(html.UnescapeString(match[2])) {
+			return match[1]
+		}
+		return `<a href="` + match[2] + `">` + match[1] + `</a>`
+	})
+	return text
+}
+
+// safeLinkTarget reports whether a link may be followed: http, https and
+// mailto URLs, relative paths and fragments. Summaries come from the model
+// and the repository, so other schemes such as javascript: stay plain text.
+func safeLinkTarget(target string) bool {
+	scheme, _, found := strings.Cut(target, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return true
+	}
+	switch strings.ToLower(scheme) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
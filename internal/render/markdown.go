@@ -0,0 +1,196 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+)
+
+// BlockKind identifies the subset of Markdown the report generator emits.
+type BlockKind int
+
+const (
+	BlockHeading BlockKind = iota
+	BlockParagraph
+	BlockList
+	BlockTable
+	BlockCode
+)
+
+type Block struct {
+	Kind  BlockKind
+	Level int
+	// Lines are the text of headings and paragraphs, or one entry per list
+	// item; an item's indented continuation lines follow it after "\n".
+	Lines []string
+	// Ordered marks a numbered list, counted from 1.
+	Ordered bool
+	Rows    [][]string
+	// Lang is the info string of fenced code, such as "mermaid".
+	Lang string
+	// SVG is the drawing of a Mermaid block, when one was rendered.
+	SVG string
+}
+
+// Parse splits report Markdown into blocks. It understands exactly what
+// internal/report writes: headings, paragraphs, "-" and "1." lists, pipe
+// tables and fenced code. A leading YAML front-matter block is returned separately.
+func Parse(markdown string) (frontMatter string, blocks []Block) {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	i := 0
+	if len(lines) > 0 && lines[0] == "---" {
+		for j := 1; j < len(lines); j++ {
+			if lines[j] == "---" {
+				frontMatter = strings.Join(lines[1:j], "\n")
+				i = j + 1
+				break
+			}
+		}
+	}
+
+	for i < len(lines) {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			block := Block{Kind: BlockCode, Lang: strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))}
+			i++
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+				block.Lines = append(block.Lines, lines[i])
+				i++
+			}
+			i++
+			blocks = append(blocks, block)
+
+		case strings.HasPrefix(trimmed, "#"):
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			blocks = append(blocks, Block{
+				Kind:  BlockHeading,
+				Level: level,
+				Lines: []string{strings.TrimSpace(trimmed[level:])},
+			})
+			i++
+
+		case strings.HasPrefix(trimmed, "|"):
+			block := Block{Kind: BlockTable}
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|") {
+				row := splitTableRow(lines[i])
+				if !isSeparatorRow(row) {
+					block.Rows = append(block.Rows, row)
+				}
+				i++
+			}
+			blocks = append(blocks, block)
+
+		case isListItem(trimmed):
+			_, ordered, _ := listItem(trimmed)
+			block := Block{Kind: BlockList, Ordered: ordered}
+			for i < len(lines) {
+				t := strings.TrimSpace(lines[i])
+				// Indented lines that start no item continue the one above.
+				if ok, itemOrdered, text := listItem(t); ok && itemOrdered == ordered {
+					block.Lines = append(block.Lines, text)
+				} else if !ok && t != "" && strings.HasPrefix(lines[i], " ") {
+					block.Lines[len(block.Lines)-1] += "\n" + t
+				} else {
+					break
+				}
+				i++
+			}
+			blocks = append(blocks, block)
+
+		default:
+			block := Block{Kind: BlockParagraph}
+			for i < len(lines) {
+				t := strings.TrimSpace(lines[i])
+				if t == "" || strings.HasPrefix(t, "#") || strings.HasPrefix(t, "|") ||
+					isListItem(t) || strings.HasPrefix(t, "```") {
+					break
+				}
+				// Keep the two-space hard line break marker for renderers
+				// that honour it.
+				if strings.HasSuffix(lines[i], "  ") {
+					t += "  "
+				}
+				block.Lines = append(block.Lines, t)
+				i++
+			}
+			blocks = append(blocks, block)
+		}
+	}
+
+	return frontMatter, blocks
+}
+
+func isListItem(line string) bool {
+	ok, _, _ := listItem(line)
+	return ok
+}
+
+// listItem reports whether line starts a list item, "- text", "* text" or
+// a numbered "2. text", and returns the item's text without its marker.
+func listItem(line string) (ok, ordered bool, text string) {
+	if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+		return true, false, strings.TrimSpace(line[2:])
+	}
+	digits := strings.IndexFunc(line, func(r rune) bool { return !unicode.IsDigit(r) })
+	if digits > 0 && strings.HasPrefix(line[digits:], ". ") {
+		return true, true, strings.TrimSpace(line[digits+2:])
+	}
+	return false, false, ""
+}
+
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+func isSeparatorRow(row []string) bool {
+	for _, cell := range row {
+		if strings.Trim(cell, "-: ") != "" {
+			return false
+		}
+	}
+	return len(row) > 0
+}
+
+// PlainInline strips inline Markdown (bold, code, links) for renderers that
+// cannot style text.
+func PlainInline(text string) string {
+	text = strings.ReplaceAll(text, "**", "")
+	text = strings.ReplaceAll(text, "`", "")
+
+	var b strings.Builder
+	for len(text) > 0 {
+		start := strings.Index(text, "[")
+		if start < 0 {
+			b.WriteString(text)
+			break
+		}
+		mid := strings.Index(text[start:], "](")
+		if mid < 0 {
+			b.WriteString(text)
+			break
+		}
+		end := strings.Index(text[start+mid:], ")")
+		if end < 0 {
+			b.WriteString(text)
+			break
+		}
+		b.WriteString(text[:start])
+		b.WriteString(text[start+1 : start+mid])
+		text = text[start+mid+end+1:]
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MermaidCLI is the Mermaid renderer (mermaid-cli) that draws diagrams in
+// HTML and PDF reports. Without it they show their Mermaid source.
+const MermaidCLI = "mmdc"
+
+// lookMermaid finds MermaidCLI; tests replace it.
+var lookMermaid = func() (string, error) { return exec.LookPath(MermaidCLI) }
+
+// drawDiagrams sets the SVG of each Mermaid block. Blocks that fail to
+// render keep their source.
+func drawDiagrams(ctx context.Context, blocks []Block) {
+	var cli string
+	for i := range blocks {
+		if blocks[i].Kind != BlockCode || blocks[i].Lang != "mermaid" {
+			continue
+		}
+		if cli == "" {
+			path, err := lookMermaid()
+			if err != nil {
+				return
+			}
+			cli = path
+		}
+		if svg, err := mermaidSVG(ctx, cli, strings.Join(blocks[i].Lines, "\n")); err == nil {
+			blocks[i].SVG = svg
+		}
+	}
+}
+
+func mermaidSVG(ctx context.Context, cli, source string) (string, error) {
+	dir, err := os.MkdirTemp("", "codedoc-mermaid-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "diagram.mmd")
+	output := filepath.Join(dir, "diagram.svg")
+	if err := os.WriteFile(input, []byte(source), 0o644); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cli, "--quiet", "--input", input, "--output", output, "--backgroundColor", "transparent")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%s failed: %w: %s", MermaidCLI, err, string(out))
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return "", err
+	}
+	svg := string(data)
+	if start := strings.Index(svg, "<svg"); start > 0 {
+		svg = svg[start:]
+	}
+	return svg, nil
+}
@@ -0,0 +1,304 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	pageMargin   = 50.0
+	bodyFontSize = 10.0
+)
+
+var chromeBinaries = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "chrome"}
+
+// PDF renders report Markdown as a PDF. When a Chrome/Chromium binary is on
+// PATH it prints the HTML rendering in headless mode for full fidelity;
+// otherwise it falls back to the built-in text renderer, which ignores the
+// theme.
+func PDF(ctx context.Context, markdown, title string, theme Theme) ([]byte, error) {
+	if chrome := findChrome(); chrome != "" {
+		if data, err := chromePDF(ctx, chrome, HTML(ctx, markdown, title, theme)); err == nil {
+			return data, nil
+		}
+	}
+	return SimplePDF(markdown), nil
+}
+
+func findChrome() string {
+	for _, name := range chromeBinaries {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func chromePDF(ctx context.Context, chrome, document string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "codedoc-pdf-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	htmlPath := filepath.Join(dir, "report.html")
+	pdfPath := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(htmlPath, []byte(document), 0o644); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, chrome,
+		"--headless", "--disable-gpu", "--no-sandbox",
+		"--no-pdf-header-footer",
+		"--print-to-pdf="+pdfPath,
+		"file://"+htmlPath,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("headless chrome failed: %w: %s", err, string(output))
+	}
+
+	return os.ReadFile(pdfPath)
+}
+
+type pdfLine struct {
+	font string
+	size float64
+	text string
+	gap  float64
+}
+
+// SimplePDF lays report Markdown out as text using the standard PDF base
+// fonts, so it needs no external tools or font files. Tables are rendered as
+// monospaced rows.
+func SimplePDF(markdown string) []byte {
+	_, blocks := Parse(markdown)
+
+	lines := []pdfLine{}
+	for _, block := range blocks {
+		switch block.Kind {
+		case BlockHeading:
+			size := map[int]float64{1: 18, 2: 14, 3: 12}[block.Level]
+			if size == 0 {
+				size = 11
+			}
+			for _, text := range wrap(PlainInline(block.Lines[0]), charsPerLine(size, 0.55)) {
+				lines = append(lines, pdfLine{font: "F2", size: size, text: text, gap: size * 0.8})
+			}
+
+		case BlockParagraph:
+			for _, text := range wrap(PlainInline(strings.Join(block.Lines, " ")), charsPerLine(bodyFontSize, 0.5)) {
+				lines = append(lines, pdfLine{font: "F1", size: bodyFontSize, text: text})
+			}
+			lines = append(lines, pdfLine{gap: 4})
+
+		case BlockList:
+			for n, item := range block.Lines {
+				marker := "  • "
+				if block.Ordered {
+					marker = fmt.Sprintf("  %d. ", n+1)
+				}
+				width := utf8.RuneCountInString(marker)
+				prefix := marker
+				for _, part := range strings.Split(item, "\n") {
+					for _, text := range wrap(PlainInline(part), charsPerLine(bodyFontSize, 0.5)-width) {
+						lines = append(lines, pdfLine{font: "F1", size: bodyFontSize, text: prefix + text})
+						prefix = strings.Repeat(" ", width)
+					}
+				}
+			}
+			lines = append(lines, pdfLine{gap: 4})
+
+		case BlockTable:
+			for i, row := range block.Rows {
+				cells := make([]string, len(row))
+				for j, cell := range row {
+					cells[j] = PlainInline(cell)
+				}
+				font := "F3"
+				if i == 0 {
+					font = "F4"
+				}
+				for _, text := range wrap(strings.Join(cells, " | "), charsPerLine(8, 0.6)) {
+					lines = append(lines, pdfLine{font: font, size: 8, text: text})
+				}
+			}
+			lines = append(lines, pdfLine{gap: 6})
+
+		case BlockCode:
+			for _, code := range block.Lines {
+				for _, text := range wrap(code, charsPerLine(8, 0.6)) {
+					lines = append(lines, pdfLine{font: "F3", size: 8, text: text})
+				}
+			}
+			lines = append(lines, pdfLine{gap: 6})
+		}
+	}
+
+	return writePDF(paginate(lines))
+}
+
+func charsPerLine(size, widthFactor float64) int {
+	return int((pageWidth - 2*pageMargin) / (size * widthFactor))
+}
+
+func paginate(lines []pdfLine) [][]pdfLine {
+	pages := [][]pdfLine{{}}
+	y := pageHeight - pageMargin
+
+	for _, line := range lines {
+		height := line.gap
+		if line.text != "" {
+			height += line.size * 1.4
+		}
+		if y-height < pageMargin && len(pages[len(pages)-1]) > 0 {
+			pages = append(pages, []pdfLine{})
+			y = pageHeight - pageMargin
+		}
+		pages[len(pages)-1] = append(pages[len(pages)-1], line)
+		y -= height
+	}
+
+	return pages
+}
+
+func writePDF(pages [][]pdfLine) []byte {
+	var buf bytes.Buffer
+	offsets := []int{}
+
+	writeObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-2: catalog and page tree; 3-6: fonts. Pages follow as
+	// (page, content) pairs starting at object 7.
+	pageRefs := []string{}
+	for i := range pages {
+		pageRefs = append(pageRefs, fmt.Sprintf("%d 0 R", 7+i*2))
+	}
+
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageRefs, " "), len(pages)))
+	for _, font := range []string{"Helvetica", "Helvetica-Bold", "Courier", "Courier-Bold"} {
+		writeObject(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font))
+	}
+
+	for _, page := range pages {
+		var content strings.Builder
+		y := pageHeight - pageMargin
+		for _, line := range page {
+			y -= line.gap
+			if line.text == "" {
+				continue
+			}
+			y -= line.size * 1.4
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n",
+				line.font, line.size, pageMargin, y+line.size*0.4, escapePDF(line.text))
+		}
+
+		contentRef := len(offsets) + 2
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R /F4 6 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, contentRef))
+
+		stream := content.String()
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(stream), stream))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// statusSymbols spells out the scorecard emoji, which have no glyph in the
+// standard PDF fonts.
+var statusSymbols = strings.NewReplacer("✅", "[ok]", "⚠️", "[!]", "⚠", "[!]", "❌", "[x]")
+
+// escapePDF converts text to a WinAnsi-encoded PDF string literal body.
+// Characters outside WinAnsi are replaced with '?'.
+func escapePDF(text string) string {
+	special := map[rune]byte{
+		'—': 0x97, '–': 0x96, '•': 0x95, '’': 0x92,
+		'‘': 0x91, '“': 0x93, '”': 0x94, '…': 0x85,
+		'→': '>',
+	}
+
+	text = statusSymbols.Replace(text)
+
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x80:
+			b.WriteByte(byte(r))
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			if code, ok := special[r]; ok {
+				fmt.Fprintf(&b, "\\%03o", code)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+func wrap(text string, width int) []string {
+	if width < 10 {
+		width = 10
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	lines := []string{}
+	current := ""
+	for _, word := range words {
+		for len([]rune(word)) > width {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		switch {
+		case current == "":
+			current = word
+		case len([]rune(current))+1+len([]rune(word)) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
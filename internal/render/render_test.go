@@ -0,0 +1,308 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const sampleReport = `---
+codedoc_version: "dev"
+---
+
+# demo — Codebase Report
+
+**Path/URL:** /tmp/demo
+
+## Quickstart
+- Build the project: ` + "`make build`" + `
+- Run tests
+
+## Modules
+| Module | Summary |
+|---|---|
+| /internal | Core [logic](internal/README.md) |
+`
+
+func TestParse(t *testing.T) {
+	frontMatter, blocks := Parse(sampleReport)
+
+	if frontMatter != `codedoc_version: "dev"` {
+		t.Errorf("Unexpected front matter %q", frontMatter)
+	}
+
+	kinds := []BlockKind{BlockHeading, BlockParagraph, BlockHeading, BlockList, BlockHeading, BlockTable}
+	if len(blocks) != len(kinds) {
+		t.Fatalf("Expected %d blocks, got %d: %+v", len(kinds), len(blocks), blocks)
+	}
+	for i, kind := range kinds {
+		if blocks[i].Kind != kind {
+			t.Errorf("Block %d kind = %v, want %v", i, blocks[i].Kind, kind)
+		}
+	}
+	if rows := blocks[5].Rows; len(rows) != 2 || rows[1][0] != "/internal" {
+		t.Errorf("Unexpected table rows %v", rows)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	out := HTML(context.Background(), sampleReport, "demo", Theme{})
+
+	for _, want := range []string{
+		"<h1>demo — Codebase Report</h1>",
+		"<strong>Path/URL:</strong>",
+		"<code>make build</code>",
+		"<th>Module</th>",
+		`<a href="internal/README.md">logic</a>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML output missing %q", want)
+		}
+	}
+}
+
+func TestSimplePDFCrossReferences(t *testing.T) {
+	data := SimplePDF(sampleReport + strings.Repeat("\nfiller paragraph line\n", 200))
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) {
+		t.Fatal("Missing PDF header")
+	}
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if startxref == nil {
+		t.Fatal("Missing startxref")
+	}
+	xrefOffset, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(data[xrefOffset:], []byte("xref\n")) {
+		t.Fatal("startxref does not point at the xref table")
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xrefOffset:], -1)
+	if len(entries) < 8 {
+		t.Fatalf("Expected a multi-page document, got %d objects", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		want := fmt.Sprintf("%d 0 obj", i+1)
+		if !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q, want %q", i+1, data[offset:offset+10], want)
+		}
+	}
+}
+
+func TestHTMLLinkSchemes(t *testing.T) {
+	tests := []struct {
+		markdown string
+		want     string
+	}{
+		{"[docs](https://example.com/a?b=1&c=2)", `<a href="https://example.com/a?b=1&amp;c=2">docs</a>`},
+		{"[site](http://example.com)", `<a href="http://example.com">site</a>`},
+		{"[mail](mailto:team@example.com)", `<a href="mailto:team@example.com">mail</a>`},
+		{"[readme](internal/README.md)", `<a href="internal/README.md">readme</a>`},
+		{"[up](../docs/a:b.md)", `<a href="../docs/a:b.md">up</a>`},
+		{"[section](#risks)", `<a href="#risks">section</a>`},
+		{"[click](javascript:alert(1)", "click"},
+		{"[click](JavaScript:alert&#40;1&#41;)", "click"},
+		{"[data](data:text/html;base64,PHNjcmlwdD4=)", "data"},
+		{"[file](file:///etc/passwd)", "file"},
+	}
+	for _, tt := range tests {
+		got := inlineHTML(tt.markdown)
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("inlineHTML(%q) = %q, want %q", tt.markdown, got, tt.want)
+		}
+		if !strings.HasPrefix(tt.want, "<a") && strings.Contains(got, "href") {
+			t.Errorf("inlineHTML(%q) = %q, want no link", tt.markdown, got)
+		}
+	}
+}
+
+func TestOrderedList(t *testing.T) {
+	markdown := "Read these first.\n\n1. `main.go` — Entrypoint\n   Starts the server.\n2. `db.go`\n\n- after\n"
+
+	_, blocks := Parse(markdown)
+	if len(blocks) != 3 || blocks[1].Kind != BlockList || !blocks[1].Ordered || blocks[2].Ordered {
+		t.Fatalf("Expected a paragraph, an ordered list and a list, got %+v", blocks)
+	}
+	if want := []string{"`main.go` — Entrypoint\nStarts the server.", "`db.go`"}; !slices.Equal(blocks[1].Lines, want) {
+		t.Errorf("Items = %q, want %q", blocks[1].Lines, want)
+	}
+
+	html := HTMLBody(blocks)
+	if want := "<ol>\n<li><code>main.go</code> — Entrypoint<br>\nStarts the server.</li>\n<li><code>db.go</code></li>\n</ol>\n"; !strings.Contains(html, want) {
+		t.Errorf("HTMLBody() = %q, want %q", html, want)
+	}
+	if want := "1. main.go — Entrypoint\n   Starts the server.\n2. db.go\n\n- after\n"; !strings.HasSuffix(Text(markdown), want) {
+		t.Errorf("Text() = %q, want suffix %q", Text(markdown), want)
+	}
+}
+
+func TestEscapePDF(t *testing.T) {
+	if got := escapePDF(`a (b) \ — ☃ ✅`); got != `a \(b\) \\ \227 ? [ok]` {
+		t.Errorf("escapePDF() = %q", got)
+	}
+}
+
+func TestText(t *testing.T) {
+	markdown := "---\nversion: 1\n---\n# repo — Codebase Report\n\n" +
+		"**Path:** /src/repo  \n**Size:** 3 files\n\n" +
+		"## Scorecard\n| Check | Status | Details |\n|---|---|---|\n| Tests | ✅ | 3 test files |\n| CI | ❌ | - |\n\n" +
+		"- " + strings.Repeat("word ", 30) + "\n\n" +
+		"```\ngo build\n```\n"
+
+	text := Text(markdown)
+
+	expected := []string{
+		"repo — Codebase Report\n======================\n",
+		"Path: /src/repo\nSize: 3 files\n",
+		"Scorecard\n---------\n",
+		"- Tests — Status: pass; Details: 3 test files\n",
+		"- CI — Status: fail\n",
+		"    go build\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() missing %q in:\n%s", want, text)
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if len([]rune(line)) > TextWidth {
+			t.Errorf("Line exceeds %d columns: %q", TextWidth, line)
+		}
+		if strings.ContainsAny(line, "|*`✅❌") {
+			t.Errorf("Unexpected markup in line: %q", line)
+		}
+	}
+	if strings.Contains(text, "version: 1") {
+		t.Error("Front matter should be omitted")
+	}
+}
+
+func TestHTMLTheme(t *testing.T) {
+	tests := []struct {
+		name  string
+		theme Theme
+		want  []string
+		not   []string
+	}{
+		{
+			name: "default",
+			want: []string{lightColors},
+			not:  []string{darkColors, `class="brand"`},
+		},
+		{
+			name:  "dark",
+			theme: Theme{Mode: ThemeDark},
+			want:  []string{darkColors},
+			not:   []string{lightColors},
+		},
+		{
+			name:  "auto",
+			theme: Theme{Mode: ThemeAuto},
+			want:  []string{lightColors, "@media (prefers-color-scheme: dark) { " + darkColors},
+		},
+		{
+			name:  "branding",
+			theme: Theme{CSS: "h1 { color: purple; }", Logo: "https://example.com/logo.svg", Header: "Acme & Co"},
+			want: []string{
+				"h1 { color: purple; }\n</style>",
+				`<header class="brand"><img src="https://example.com/logo.svg" alt=""><span>Acme &amp; Co</span></header>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := HTML(context.Background(), sampleReport, "demo", tt.theme)
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("HTML output missing %q", want)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(out, not) {
+					t.Errorf("HTML output contains %q", not)
+				}
+			}
+		})
+	}
+}
+
+func TestLogoSource(t *testing.T) {
+	dir := t.TempDir()
+	logo := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(logo, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LogoSource(logo); err != nil || got != "data:image/png;base64,cG5n" {
+		t.Errorf("LogoSource(file) = %q, %v", got, err)
+	}
+	if got, err := LogoSource("https://example.com/logo.svg"); err != nil || got != "https://example.com/logo.svg" {
+		t.Errorf("LogoSource(url) = %q, %v", got, err)
+	}
+	if _, err := LogoSource(filepath.Join(dir, "notes.txt")); err == nil {
+		t.Error("LogoSource(missing) = nil error")
+	}
+}
+
+func TestHTMLCollapsesLongTables(t *testing.T) {
+	var md strings.Builder
+	md.WriteString("| Method | Path |\n|---|---|\n")
+	for i := 0; i < htmlTableCollapseRows+5; i++ {
+		md.WriteString(fmt.Sprintf("| GET | /items/%d |\n", i))
+	}
+
+	out := HTMLBody(mustParse(md.String()))
+
+	if !strings.Contains(out, "<details><summary>Show 5 more rows</summary>") {
+		t.Errorf("Expected collapsed rows, got:\n%s", out)
+	}
+	if strings.Count(out, "<th>Method</th>") != 2 {
+		t.Error("Expected the header to repeat in the collapsed table")
+	}
+}
+
+func mustParse(markdown string) []Block {
+	_, blocks := Parse(markdown)
+	return blocks
+}
+
+func TestHTMLDiagrams(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake renderer is a shell script")
+	}
+	markdown := "## System\n\n```mermaid\nflowchart LR\n  a --> b\n```\n"
+
+	lookMermaid = func() (string, error) { return "", errors.New("not found") }
+	if out := HTML(context.Background(), markdown, "demo", Theme{}); !strings.Contains(out, "<pre><code>flowchart LR") {
+		t.Errorf("Expected the Mermaid source without a renderer:\n%s", out)
+	}
+
+	// The fake renderer writes an SVG to the path after --output.
+	script := filepath.Join(t.TempDir(), "mmdc")
+	fake := "#!/bin/sh\nwhile [ \"$1\" != --output ]; do shift; done\nprintf '<?xml version=\"1.0\"?>\\n<svg id=\"fake\"></svg>' > \"$2\"\n"
+	if err := os.WriteFile(script, []byte(fake), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	lookMermaid = func() (string, error) { return script, nil }
+	defer func() { lookMermaid = func() (string, error) { return exec.LookPath(MermaidCLI) } }()
+
+	out := HTML(context.Background(), markdown, "demo", Theme{})
+	if !strings.Contains(out, "<figure class=\"diagram\">\n<svg id=\"fake\"></svg>") {
+		t.Errorf("Expected the rendered diagram:\n%s", out)
+	}
+	if strings.Contains(out, "flowchart LR") || strings.Contains(out, "<?xml") {
+		t.Errorf("Expected only the SVG:\n%s", out)
+	}
+}
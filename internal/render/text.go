@@ -0,0 +1,149 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TextWidth is the column at which plain-text reports wrap.
+const TextWidth = 80
+
+// textSymbols replaces status emoji with words so the output reads the same
+// in a terminal, an email and a screen reader.
+var textSymbols = strings.NewReplacer("✅", "pass", "⚠️", "warning", "⚠", "warning", "❌", "fail")
+
+// Text renders report Markdown as plain text wrapped at TextWidth columns.
+// Tables become one list entry per row, labelled with the column headers.
+func Text(markdown string) string {
+	_, blocks := Parse(markdown)
+
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		switch block.Kind {
+		case BlockHeading:
+			title := plainText(strings.Join(block.Lines, " "))
+			b.WriteString(title + "\n")
+			underline := "~"
+			switch block.Level {
+			case 1:
+				underline = "="
+			case 2:
+				underline = "-"
+			}
+			b.WriteString(strings.Repeat(underline, min(len([]rune(title)), TextWidth)) + "\n")
+
+		case BlockParagraph:
+			for _, segment := range hardBreakSegments(block.Lines) {
+				for _, line := range wrap(plainText(segment), TextWidth) {
+					b.WriteString(line + "\n")
+				}
+			}
+
+		case BlockList:
+			for i, item := range block.Lines {
+				marker := "- "
+				if block.Ordered {
+					marker = strconv.Itoa(i+1) + ". "
+				}
+				parts := strings.Split(item, "\n")
+				for i, part := range parts {
+					parts[i] = plainText(part)
+				}
+				writeTextLines(&b, marker, parts)
+			}
+
+		case BlockTable:
+			writeTextTable(&b, block.Rows)
+
+		case BlockCode:
+			for _, line := range block.Lines {
+				b.WriteString("    " + line + "\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func writeTextTable(b *strings.Builder, rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+
+	header := rows[0]
+	if len(rows) == 1 {
+		writeTextItem(b, plainText(strings.Join(header, ", ")))
+		return
+	}
+
+	for _, row := range rows[1:] {
+		if len(row) == 0 {
+			continue
+		}
+
+		parts := []string{}
+		for i := 1; i < len(row); i++ {
+			value := plainText(row[i])
+			if value == "" || value == "-" {
+				continue
+			}
+			label := ""
+			if i < len(header) {
+				label = plainText(header[i])
+			}
+			if label != "" {
+				value = label + ": " + value
+			}
+			parts = append(parts, value)
+		}
+
+		item := plainText(row[0])
+		if len(parts) > 0 {
+			item += " — " + strings.Join(parts, "; ")
+		}
+		writeTextItem(b, item)
+	}
+}
+
+func writeTextItem(b *strings.Builder, text string) {
+	writeTextLines(b, "- ", []string{text})
+}
+
+// writeTextLines writes a list item after its marker, each of its lines
+// wrapped and indented under the first.
+func writeTextLines(b *strings.Builder, marker string, lines []string) {
+	indent := strings.Repeat(" ", len(marker))
+	prefix := marker
+	for _, text := range lines {
+		for _, line := range wrap(text, TextWidth-len(marker)) {
+			b.WriteString(prefix + line + "\n")
+			prefix = indent
+		}
+	}
+}
+
+// hardBreakSegments joins paragraph lines, starting a new segment after each
+// line that ends in a Markdown hard break.
+func hardBreakSegments(lines []string) []string {
+	segments := []string{}
+	current := []string{}
+	for _, line := range lines {
+		current = append(current, strings.TrimSpace(line))
+		if strings.HasSuffix(line, "  ") {
+			segments = append(segments, strings.Join(current, " "))
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		segments = append(segments, strings.Join(current, " "))
+	}
+	return segments
+}
+
+func plainText(text string) string {
+	return strings.TrimSpace(textSymbols.Replace(PlainInline(text)))
+}
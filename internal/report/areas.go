@@ -0,0 +1,31 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+func writeFunctionalAreas(builder *strings.Builder, opts Options) {
+	if len(opts.FunctionalAreas) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Functional Areas") + "\n")
+	builder.WriteString("Files whose summaries are alike, found by clustering their embeddings. " +
+		"Areas spanning several directories are concerns the directory structure does not show.\n\n")
+	builder.WriteString("| Area | Key terms | Files | Directories |\n")
+	builder.WriteString("|---|---|---|---|\n")
+	for i, area := range opts.FunctionalAreas {
+		dirs := make([]string, len(area.Dirs))
+		for j, dir := range area.Dirs {
+			dirs[j] = "/" + dir
+		}
+		name := fmt.Sprintf("A%d", i+1)
+		if area.CrossCutting() {
+			name += " (cross-cutting)"
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			name, orDash(strings.Join(area.Terms, ", ")), strings.Join(area.Files, ", "), strings.Join(dirs, ", ")))
+	}
+	builder.WriteString("\n")
+}
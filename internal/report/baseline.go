@@ -0,0 +1,131 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/baseline"
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/risk"
+)
+
+// acknowledgement is a risk the baseline accepted, as listed in the report.
+type acknowledgement struct {
+	Item   string
+	Reason string
+}
+
+func (opts Options) findings() ([]detect.Finding, []acknowledgement) {
+	active := []detect.Finding{}
+	acknowledged := []acknowledgement{}
+	for _, finding := range opts.DetectionResult.Findings {
+		if entry, ok := opts.Baseline.Match(finding.Rule, finding.File); ok {
+			acknowledged = append(acknowledged, acknowledgement{
+				Item:   fmt.Sprintf("`%s` %s (%s)", findingLocation(finding), finding.Message, finding.Rule),
+				Reason: entry.Reason,
+			})
+			continue
+		}
+		active = append(active, finding)
+	}
+	return active, acknowledged
+}
+
+func (opts Options) secrets() ([]detect.Secret, []acknowledgement) {
+	active := []detect.Secret{}
+	acknowledged := []acknowledgement{}
+	for _, secret := range opts.DetectionResult.Secrets {
+		if entry, ok := opts.Baseline.Match(secret.Rule, secret.File); ok {
+			acknowledged = append(acknowledged, acknowledgement{
+				Item:   fmt.Sprintf("`%s:%d` potential secret (%s)", secret.File, secret.Line, secret.Rule),
+				Reason: entry.Reason,
+			})
+			continue
+		}
+		active = append(active, secret)
+	}
+	return active, acknowledged
+}
+
+func (opts Options) risks() ([]risk.Risk, []acknowledgement) {
+	active := []risk.Risk{}
+	acknowledged := []acknowledgement{}
+	for _, r := range identifyRisks(opts) {
+		if entry, ok := matchRisk(opts.Baseline, r); ok {
+			acknowledged = append(acknowledged, acknowledgement{Item: r.Message, Reason: entry.Reason})
+			continue
+		}
+		active = append(active, r)
+	}
+	return active, acknowledged
+}
+
+// matchRisk finds the entry acknowledging r by rule and file or, as
+// baselines record rule risks without a file, by the start of its text.
+func matchRisk(b *baseline.Baseline, r risk.Risk) (baseline.Entry, bool) {
+	if entry, ok := b.Match(r.Rule, r.File); ok {
+		return entry, true
+	}
+	return b.MatchRisk(r.Message)
+}
+
+func writeAcknowledged(builder *strings.Builder, opts Options, acknowledged []acknowledgement) {
+	if len(acknowledged) == 0 {
+		return
+	}
+
+	builder.WriteString(fmt.Sprintf("### %s (%d)\n", opts.heading("Acknowledged"), len(acknowledged)))
+	builder.WriteString("Accepted in the baseline file and not counted above.\n\n")
+	for _, ack := range acknowledged {
+		if ack.Reason != "" {
+			builder.WriteString(fmt.Sprintf("- %s — %s\n", ack.Item, ack.Reason))
+		} else {
+			builder.WriteString(fmt.Sprintf("- %s\n", ack.Item))
+		}
+	}
+	builder.WriteString("\n")
+}
+
+// BaselineEntries acknowledges every risk, finding and secret of the report,
+// for seeding or refreshing a baseline file.
+func BaselineEntries(opts Options) []baseline.Entry {
+	entries := []baseline.Entry{}
+	seen := make(map[baseline.Entry]bool)
+	add := func(entry baseline.Entry) {
+		if !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	for _, r := range identifyRisks(opts) {
+		if r.File != "" {
+			add(baseline.Entry{Rule: r.Rule, File: r.File})
+		} else {
+			add(baseline.Entry{Risk: riskKey(r.Message)})
+		}
+	}
+	for _, finding := range opts.DetectionResult.Findings {
+		add(baseline.Entry{Rule: finding.Rule, File: finding.File})
+	}
+	for _, secret := range opts.DetectionResult.Secrets {
+		add(baseline.Entry{Rule: secret.Rule, File: secret.File})
+	}
+	return entries
+}
+
+// riskKey is the stable start of a heuristic risk, before the counts and
+// paths that change from run to run.
+func riskKey(risk string) string {
+	if i := strings.IndexAny(risk, "(:0123456789"); i > 0 {
+		risk = risk[:i]
+	}
+	return strings.TrimSpace(risk)
+}
+
+func findingLocation(finding detect.Finding) string {
+	if finding.Line > 0 {
+		return fmt.Sprintf("%s:%d", finding.File, finding.Line)
+	}
+	return finding.File
+}
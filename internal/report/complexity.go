@@ -0,0 +1,43 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// maxComplexityHotspots caps the files listed under Complexity Hotspots.
+const maxComplexityHotspots = 10
+
+func writeComplexity(builder *strings.Builder, opts Options) {
+	files := []scanner.FileInfo{}
+	for _, file := range opts.ScanResult.Files {
+		if !file.IsTest && file.Complexity.Functions > 0 {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Complexity.Cyclomatic > files[j].Complexity.Cyclomatic
+	})
+	files = files[:min(maxComplexityHotspots, len(files))]
+
+	builder.WriteString("## " + opts.heading("Complexity Hotspots") + "\n")
+	builder.WriteString("| File | Functions | Longest function | Cyclomatic complexity | Most complex function |\n")
+	builder.WriteString("|---|---|---|---|---|\n")
+	approximate := false
+	for _, file := range files {
+		c := file.Complexity
+		builder.WriteString(fmt.Sprintf("| %s | %d | %d lines | %d | %d |\n",
+			file.RelativePath, c.Functions, c.MaxFunctionLines, c.Cyclomatic, c.MaxCyclomatic))
+		approximate = approximate || c.Approximate
+	}
+	if approximate {
+		builder.WriteString("\nGo files are measured from their syntax tree; other languages are estimated from keywords.\n")
+	}
+	builder.WriteString("\n")
+}
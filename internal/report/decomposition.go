@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxInterfaceDirs caps the interface directories listed per candidate.
+const maxInterfaceDirs = 3
+
+func writeDecomposition(builder *strings.Builder, opts Options) {
+	if len(opts.Seams) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Modularization Candidates") + "\n")
+	builder.WriteString("Groups of directories whose files mostly import each other, found by clustering the import graph. " +
+		"Each is a candidate module boundary; its interface is what the rest of the repository imports from it.\n\n")
+	builder.WriteString("| Candidate | Directories | Files | Cohesion | Imports in | Imports out | Interface | Depends on |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for i, seam := range opts.Seams {
+		dirs := make([]string, len(seam.Dirs))
+		for j, dir := range seam.Dirs {
+			dirs[j] = "/" + dir
+		}
+		iface := []string{}
+		for _, dir := range seam.Interface[:min(maxInterfaceDirs, len(seam.Interface))] {
+			iface = append(iface, "/"+dir)
+		}
+		if extra := len(seam.Interface) - len(iface); extra > 0 {
+			iface = append(iface, fmt.Sprintf("+%d more", extra))
+		}
+		dependsOn := make([]string, len(seam.DependsOn))
+		for j, index := range seam.DependsOn {
+			dependsOn[j] = fmt.Sprintf("C%d", index+1)
+		}
+		builder.WriteString(fmt.Sprintf("| C%d | %s | %d | %.0f%% | %d | %d | %s | %s |\n",
+			i+1, strings.Join(dirs, ", "), seam.Files, seam.Cohesion()*100, seam.Incoming, seam.Outgoing,
+			orDash(strings.Join(iface, ", ")), orDash(strings.Join(dependsOn, ", "))))
+	}
+	builder.WriteString("\n")
+
+	if opts.Summaries == nil || len(opts.Summaries.SeamAssessments) == 0 {
+		return
+	}
+	for i := range opts.Seams {
+		if assessment, ok := opts.Summaries.SeamAssessments[i]; ok {
+			builder.WriteString(fmt.Sprintf("- **C%d** — %s\n", i+1, assessment))
+		}
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/deps"
+)
+
+// maxDirectDependencies caps the direct dependencies table.
+const maxDirectDependencies = 50
+
+// writeDependencies counts the pinned dependencies per manifest or lock file
+// and lists the direct ones with their resolved versions.
+func writeDependencies(builder *strings.Builder, opts Options) {
+	if len(opts.Dependencies) == 0 {
+		return
+	}
+
+	type counts struct {
+		ecosystem          string
+		direct, transitive int
+	}
+	files := []string{}
+	perFile := map[string]*counts{}
+	direct := []deps.Dependency{}
+	for _, dep := range opts.Dependencies {
+		c, ok := perFile[dep.File]
+		if !ok {
+			c = &counts{ecosystem: dep.Ecosystem}
+			perFile[dep.File] = c
+			files = append(files, dep.File)
+		}
+		if dep.Indirect {
+			c.transitive++
+		} else {
+			c.direct++
+			direct = append(direct, dep)
+		}
+	}
+
+	sort.Strings(files)
+
+	builder.WriteString("## " + opts.heading("Dependencies") + "\n")
+	builder.WriteString("| File | Ecosystem | Direct | Transitive |\n")
+	builder.WriteString("|---|---|---|---|\n")
+	for _, file := range files {
+		c := perFile[file]
+		builder.WriteString(fmt.Sprintf("| %s | %s | %d | %d |\n", file, c.ecosystem, c.direct, c.transitive))
+	}
+	builder.WriteString("\n")
+
+	if len(direct) == 0 {
+		return
+	}
+	builder.WriteString("### " + opts.heading("Direct Dependencies") + "\n")
+	builder.WriteString("| Name | Version | File |\n")
+	builder.WriteString("|---|---|---|\n")
+	for _, dep := range direct[:min(maxDirectDependencies, len(direct))] {
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s |\n", dep.Name, orDash(dep.Version), dep.File))
+	}
+	if len(direct) > maxDirectDependencies {
+		builder.WriteString(fmt.Sprintf("\n_%d more direct dependencies in the JSON artifact._\n", len(direct)-maxDirectDependencies))
+	}
+	builder.WriteString("\n")
+}
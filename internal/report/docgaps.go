@@ -0,0 +1,51 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+)
+
+func writeDocGaps(builder *strings.Builder, opts Options) {
+	docs := opts.DetectionResult.Docs
+	if len(docs) == 0 {
+		return
+	}
+
+	files := []string{}
+	for _, doc := range docs {
+		files = append(files, "`"+doc.File+"`")
+	}
+	builder.WriteString("## " + opts.heading("Documentation Gaps") + "\n")
+	builder.WriteString(fmt.Sprintf("Compares %s with the analysis.\n\n", strings.Join(files, " and ")))
+
+	stale := []detect.DocGap{}
+	undocumented := []detect.DocGap{}
+	for _, gap := range opts.DetectionResult.DocGaps {
+		if gap.Kind == detect.DocGapStale {
+			stale = append(stale, gap)
+		} else {
+			undocumented = append(undocumented, gap)
+		}
+	}
+	if len(stale) == 0 && len(undocumented) == 0 {
+		builder.WriteString("- The docs match what was detected\n\n")
+		return
+	}
+
+	if len(stale) > 0 {
+		builder.WriteString("### " + opts.heading("Outdated") + "\n")
+		for _, gap := range stale {
+			builder.WriteString(fmt.Sprintf("- `%s:%d` %s\n", gap.File, gap.Line, gap.Message))
+		}
+		builder.WriteString("\n")
+	}
+	if len(undocumented) > 0 {
+		builder.WriteString("### " + opts.heading("Undocumented") + "\n")
+		for _, gap := range undocumented {
+			builder.WriteString(fmt.Sprintf("- %s\n", gap.Message))
+		}
+		builder.WriteString("\n")
+	}
+}
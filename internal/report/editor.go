@@ -0,0 +1,99 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// editorIndexVersion is bumped when the editor index changes incompatibly.
+const editorIndexVersion = 1
+
+// maxHoverLength caps a one-line summary, in characters.
+const maxHoverLength = 200
+
+// EditorIndex is the --emit-editor export: short documentation keyed by
+// slash-separated path, for editor hovers and tooltips.
+type EditorIndex struct {
+	Version     int                     `json:"version"`
+	Repository  string                  `json:"repository"`
+	Commit      string                  `json:"commit,omitempty"`
+	GeneratedAt string                  `json:"generatedAt,omitempty"`
+	Modules     map[string]EditorModule `json:"modules"`
+	Files       map[string]EditorFile   `json:"files"`
+}
+
+type EditorModule struct {
+	Description string `json:"description"`
+	Files       int    `json:"files"`
+}
+
+type EditorFile struct {
+	Summary   string   `json:"summary"`
+	Module    string   `json:"module,omitempty"`
+	Language  string   `json:"language,omitempty"`
+	Functions []string `json:"functions,omitempty"`
+}
+
+// WriteEditorIndex writes the module descriptions and a one-line summary of
+// every summarized file to path as compact JSON. Files without a summary
+// are left out; editors fall back to the module with the longest matching
+// prefix.
+func WriteEditorIndex(opts Options, path string) error {
+	p := opts.Provenance
+	index := EditorIndex{
+		Version:     editorIndexVersion,
+		Repository:  repositoryName(opts),
+		Commit:      p.CommitSHA,
+		GeneratedAt: p.GeneratedAt,
+		Modules:     map[string]EditorModule{},
+		Files:       map[string]EditorFile{},
+	}
+
+	modules := reportModules(opts)
+	for _, module := range modules {
+		index.Modules[filepath.ToSlash(module)] = EditorModule{Description: oneLine(moduleSummary(opts, module))}
+	}
+	for _, file := range opts.ScanResult.Files {
+		path := filepath.ToSlash(file.RelativePath)
+		module := moduleOf(path, modules)
+		if entry, ok := index.Modules[filepath.ToSlash(module)]; ok {
+			entry.Files++
+			index.Modules[filepath.ToSlash(module)] = entry
+		}
+		summary := opts.Summaries.FileSummaries[file.RelativePath]
+		if summary.Summary == "" {
+			continue
+		}
+		index.Files[path] = EditorFile{
+			Summary:   oneLine(summary.Summary),
+			Module:    filepath.ToSlash(module),
+			Language:  file.Language,
+			Functions: summary.Functions,
+		}
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to encode editor index: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write editor index: %w", err)
+	}
+	return nil
+}
+
+// oneLine shortens a summary to its first sentence on a single line.
+func oneLine(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if end := strings.Index(text, ". "); end >= 0 {
+		text = text[:end+1]
+	}
+	if utf8.RuneCountInString(text) > maxHoverLength {
+		text = string([]rune(text)[:maxHoverLength-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,26 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+func writeGlossary(builder *strings.Builder, opts Options) {
+	if len(opts.Glossary) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Appendix: Domain Terminology") + "\n")
+	builder.WriteString("Abbreviations and domain terms that recur in identifiers, most used first.\n\n")
+	builder.WriteString("| Term | Kind | Definition | Used in |\n")
+	builder.WriteString("|---|---|---|---|\n")
+	for _, term := range opts.Glossary {
+		definition := ""
+		if opts.Summaries != nil {
+			definition = opts.Summaries.Glossary[term.Term]
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			term.Term, term.Kind, orDash(definition), strings.Join(term.Examples, ", ")))
+	}
+	builder.WriteString("\n")
+}
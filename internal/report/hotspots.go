@@ -0,0 +1,75 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/history"
+)
+
+const (
+	// maxHotspots caps the hotspot files and the contributors listed.
+	maxHotspots     = 10
+	maxContributors = 10
+)
+
+func writeHotspots(builder *strings.Builder, opts Options) {
+	h := opts.History
+	if h == nil || h.Commits == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Code Hotspots & Ownership") + "\n")
+	builder.WriteString(fmt.Sprintf("**Period:** %s to %s  \n", h.Since.Format("2006-01-02"), h.Until.Format("2006-01-02")))
+	builder.WriteString(fmt.Sprintf("**Commits:** %d  \n", h.Commits))
+	builder.WriteString(fmt.Sprintf("**Contributors:** %d\n\n", len(h.Contributors)))
+
+	if len(h.Files) > 0 {
+		builder.WriteString("### " + opts.heading("Hotspots") + "\n")
+		builder.WriteString("| File | Commits | Lines changed | Authors | Main author |\n")
+		builder.WriteString("|---|---|---|---|---|\n")
+		for _, file := range h.Files[:min(maxHotspots, len(h.Files))] {
+			author, share := file.MainAuthor()
+			builder.WriteString(fmt.Sprintf("| %s | %d | +%d −%d | %d | %s (%.0f%%) |\n",
+				file.Path, file.Commits, file.Added, file.Deleted, len(file.Authors), author, share*100))
+		}
+		builder.WriteString("\n")
+	}
+
+	if modules := reportModules(opts); len(modules) > 0 {
+		perModule := map[string][]history.File{}
+		for _, file := range h.Files {
+			if module := moduleOf(file.Path, modules); module != "" {
+				perModule[module] = append(perModule[module], file)
+			}
+		}
+		if len(perModule) > 0 {
+			builder.WriteString("### " + opts.heading("Module Ownership") + "\n")
+			builder.WriteString("| Module | Files changed | Lines changed | Authors | Main author |\n")
+			builder.WriteString("|---|---|---|---|---|\n")
+			for _, module := range modules {
+				files, ok := perModule[module]
+				if !ok {
+					continue
+				}
+				churn := 0
+				for _, file := range files {
+					churn += file.Churn()
+				}
+				author, share, authors := history.MergeAuthors(files)
+				builder.WriteString(fmt.Sprintf("| /%s | %d | %d | %d | %s (%.0f%%) |\n",
+					module, len(files), churn, authors, author, share*100))
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	builder.WriteString("### " + opts.heading("Top Contributors") + "\n")
+	builder.WriteString("| Contributor | Commits | Last commit |\n")
+	builder.WriteString("|---|---|---|\n")
+	for _, contributor := range h.Contributors[:min(maxContributors, len(h.Contributors))] {
+		builder.WriteString(fmt.Sprintf("| %s | %d | %s |\n",
+			contributor.Name, contributor.Commits, contributor.LastCommit.Format("2006-01-02")))
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// localeFiles hold the message catalog: one JSON object per primary
+// language, mapping the English text of the report's headings, table
+// columns, labels and notes to its translation.
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalog is keyed by primary language and then by the English text. Text
+// missing from a locale, and languages without one, stay in English; pt
+// follows Brazilian usage.
+var catalog = map[string]map[string]string{}
+
+// formatVerb matches the fmt verbs a translation must keep, in order.
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9.]*[a-zA-Z%]`)
+
+func init() {
+	files, err := fs.Glob(localeFiles, "locales/*.json")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range files {
+		data, err := localeFiles.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("invalid embedded %s: %v", file, err))
+		}
+		for english, translated := range messages {
+			if !slices.Equal(formatVerb.FindAllString(english, -1), formatVerb.FindAllString(translated, -1)) {
+				panic(fmt.Sprintf("%s: %q changes the format verbs of %q", file, translated, english))
+			}
+		}
+		catalog[strings.TrimSuffix(path.Base(file), ".json")] = messages
+	}
+}
+
+// heading returns a fixed heading in the report's language.
+func (opts Options) heading(english string) string {
+	return localize(opts.Language, english)
+}
+
+// text returns fixed text other than a heading, such as a note or a format
+// string, in the report's language.
+func (opts Options) text(english string) string {
+	return localize(opts.Language, english)
+}
+
+// label returns a bold "Label:" prefix in the report's language.
+func (opts Options) label(english string) string {
+	return "**" + opts.text(english) + ":** "
+}
+
+// writeTableHeader writes the header row and separator of a table with
+// the named columns.
+func (opts Options) writeTableHeader(builder *strings.Builder, columns ...string) {
+	translated := make([]string, len(columns))
+	for i, column := range columns {
+		translated[i] = opts.text(column)
+	}
+	builder.WriteString("| " + strings.Join(translated, " | ") + " |\n")
+	builder.WriteString("|" + strings.Repeat("---|", len(columns)) + "\n")
+}
+
+func localize(language, english string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(language), "-")
+	if translated, ok := catalog[primary][english]; ok {
+		return translated
+	}
+	return english
+}
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+func TestLocalizedHeadings(t *testing.T) {
+	tests := []struct {
+		language string
+		want     []string
+	}{
+		{"de", []string{"# shop — Codebasis-Bericht", "## Schnellstart", "- Repository klonen", "## Architekturüberblick"}},
+		// Region subtags fall back to the primary language's catalog.
+		{"pt-BR", []string{"## Início rápido", "## Visão geral da arquitetura"}},
+		{"", []string{"# shop — Codebase Report", "## Quickstart", "## Architecture Overview"}},
+	}
+	for _, test := range tests {
+		t.Run(test.language, func(t *testing.T) {
+			opts := Options{
+				RepoPath:   "/src/shop",
+				ScanResult: &scanner.Result{},
+				Summaries:  &summarize.Result{ArchitectureSummary: "A web shop."},
+				Language:   test.language,
+			}
+			var markdown strings.Builder
+			for _, name := range []string{"header", "quickstart", "architecture"} {
+				content, err := renderBuiltin(context.Background(), opts, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				markdown.WriteString(content)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(markdown.String(), want) {
+					t.Errorf("Expected %q in:\n%s", want, markdown.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLocalizeFallsBackToEnglish(t *testing.T) {
+	tests := []struct {
+		language string
+		english  string
+		want     string
+	}{
+		{"de", "Quickstart", "Schnellstart"},
+		{"DE-at", "Quickstart", "Schnellstart"},
+		{"de", "A heading no catalog has", "A heading no catalog has"},
+		{"xx", "Quickstart", "Quickstart"},
+		{"", "Quickstart", "Quickstart"},
+	}
+	for _, test := range tests {
+		if got := localize(test.language, test.english); got != test.want {
+			t.Errorf("localize(%q, %q) = %q, want %q", test.language, test.english, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/render"
+	"github.com/codepigeon/codedoc/internal/workspace"
+)
+
+type IndexEntry struct {
+	Project    workspace.Project
+	ReportFile string
+	Files      int
+	Lines      int
+	Languages  string
+}
+
+// WriteIndex writes the top-level report for a monorepo, linking to one
+// report per sub-project.
+func WriteIndex(ctx context.Context, path, repoName, format, language string, theme render.Theme, entries []IndexEntry) error {
+	var builder strings.Builder
+
+	title := repoName + " — " + localize(language, "Workspace Index")
+	builder.WriteString(fmt.Sprintf("# %s\n\n", title))
+	builder.WriteString(fmt.Sprintf("This repository contains %d projects.\n\n", len(entries)))
+	builder.WriteString("| Project | Path | Kind | Size | Languages |\n")
+	builder.WriteString("|---|---|---|---|---|\n")
+
+	for _, entry := range entries {
+		link, err := filepath.Rel(filepath.Dir(path), entry.ReportFile)
+		if err != nil {
+			link = entry.ReportFile
+		}
+		builder.WriteString(fmt.Sprintf("| [%s](%s) | /%s | %s | %d files, %d LOC | %s |\n",
+			entry.Project.Name, filepath.ToSlash(link), entry.Project.Path, entry.Project.Kind,
+			entry.Files, entry.Lines, entry.Languages))
+	}
+
+	content, err := Render(ctx, builder.String(), format, title, theme)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+func LanguageSummary(opts Options) string {
+	var builder strings.Builder
+	writeLanguageBreakdown(&builder, opts.ScanResult.LanguageStats)
+	return builder.String()
+}
+
+func writeProjects(builder *strings.Builder, opts Options) {
+	if len(opts.Projects) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Workspace Projects") + "\n")
+	builder.WriteString("| Project | Path | Kind |\n")
+	builder.WriteString("|---|---|---|\n")
+	for _, project := range opts.Projects {
+		builder.WriteString(fmt.Sprintf("| %s | /%s | %s |\n", project.Name, project.Path, project.Kind))
+	}
+	builder.WriteString("\nRun with `--per-project` to generate a separate report for each project.\n\n")
+}
+
+type OwnerReport struct {
+	Owner      string
+	ReportFile string
+	Files      int
+}
+
+func writeOwnerReports(builder *strings.Builder, opts Options) {
+	if len(opts.OwnerReports) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Reports by Owner") + "\n")
+	builder.WriteString("| Owner | Files | Report |\n")
+	builder.WriteString("|---|---|---|\n")
+	for _, owned := range opts.OwnerReports {
+		link, err := filepath.Rel(filepath.Dir(opts.OutputFile), owned.ReportFile)
+		if err != nil {
+			link = owned.ReportFile
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %d | [%s](%s) |\n",
+			owned.Owner, owned.Files, filepath.Base(owned.ReportFile), filepath.ToSlash(link)))
+	}
+	builder.WriteString("\n")
+}
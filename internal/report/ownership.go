@@ -0,0 +1,51 @@
+package report
+
+import (
+	"path/filepath"
+
+	"github.com/codepigeon/codedoc/internal/history"
+)
+
+// maxModuleContributors caps the recent contributors listed per module.
+const maxModuleContributors = 3
+
+// ModuleOwners is who to ask about a module: its CODEOWNERS owners and,
+// with --git-history, its most active recent authors.
+type ModuleOwners struct {
+	Owners       []string `json:"owners,omitempty"`
+	Contributors []string `json:"contributors,omitempty"`
+}
+
+// moduleOwners maps each report module to its owners. It returns nil when
+// the repository has neither a CODEOWNERS file nor a history analysis.
+func moduleOwners(opts Options) map[string]ModuleOwners {
+	if opts.Codeowners == nil && opts.History == nil {
+		return nil
+	}
+
+	modules := reportModules(opts)
+	files := map[string][]string{}
+	for _, file := range opts.ScanResult.Files {
+		rel := filepath.ToSlash(file.RelativePath)
+		if module := moduleOf(rel, modules); module != "" {
+			files[module] = append(files[module], rel)
+		}
+	}
+	changed := map[string][]history.File{}
+	if opts.History != nil {
+		for _, file := range opts.History.Files {
+			if module := moduleOf(file.Path, modules); module != "" {
+				changed[module] = append(changed[module], file)
+			}
+		}
+	}
+
+	result := make(map[string]ModuleOwners, len(modules))
+	for _, module := range modules {
+		result[module] = ModuleOwners{
+			Owners:       opts.Codeowners.OwnersOfFiles(files[module]),
+			Contributors: history.TopAuthors(changed[module], maxModuleContributors),
+		}
+	}
+	return result
+}
@@ -0,0 +1,270 @@
+package report
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/depmap"
+	"github.com/codepigeon/codedoc/internal/deps"
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/glossary"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/history"
+	"github.com/codepigeon/codedoc/internal/risk"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+	"github.com/codepigeon/codedoc/internal/system"
+)
+
+type Provenance struct {
+	ToolVersion   string            `json:"tool_version"`
+	Model         string            `json:"model"`
+	PromptVersion string            `json:"prompt_version"`
+	CommitSHA     string            `json:"commit_sha"`
+	GeneratedAt   string            `json:"generated_at"`
+	Temperature   float64           `json:"temperature"`
+	Reproducible  bool              `json:"reproducible"`
+	Flags         map[string]string `json:"flags,omitempty"`
+	// Models maps each summary type routed to another model than Model to
+	// that model.
+	Models map[string]string `json:"models,omitempty"`
+	// Prompts maps each summary type with a custom prompt to the SHA-256 of
+	// its template.
+	Prompts map[string]string `json:"custom_prompts,omitempty"`
+	Inputs  []InputFile       `json:"inputs,omitempty"`
+	// Limitations note what missing tools, keys or network access left out
+	// of the report.
+	Limitations []string `json:"limitations,omitempty"`
+}
+
+type InputFile struct {
+	Path  string `json:"path"`
+	Hash  string `json:"hash"`
+	Lines int    `json:"lines"`
+}
+
+type Artifact struct {
+	Provenance   Provenance              `json:"provenance"`
+	Repository   string                  `json:"repository"`
+	Tags         []string                `json:"tags"`
+	Roots        []Root                  `json:"roots,omitempty"`
+	System       *system.Result          `json:"system,omitempty"`
+	Scan         *scanner.Result         `json:"scan"`
+	Detection    *detect.Result          `json:"detection"`
+	Summaries    *summarize.Result       `json:"summaries"`
+	Internal     *depmap.Result          `json:"internal_dependencies,omitempty"`
+	Dependencies []deps.Dependency       `json:"dependencies,omitempty"`
+	History      *history.Result         `json:"history,omitempty"`
+	Seams        []graph.Seam            `json:"seams,omitempty"`
+	Areas        []embed.Area            `json:"functional_areas,omitempty"`
+	ModuleOwners map[string]ModuleOwners `json:"module_owners,omitempty"`
+	Glossary     []glossary.Term         `json:"glossary,omitempty"`
+	Risks        []risk.Risk             `json:"risks,omitempty"`
+}
+
+// Tags returns the repository's topic tags: the classified ones when
+// summaries have them, otherwise the detected ones.
+func Tags(opts Options) []string {
+	if opts.Summaries != nil && len(opts.Summaries.Tags) > 0 {
+		return opts.Summaries.Tags
+	}
+	tags := []string{}
+	if opts.DetectionResult != nil {
+		for _, tag := range opts.DetectionResult.Tags {
+			tags = append(tags, tag.Name)
+		}
+	}
+	return tags
+}
+
+func writeFrontMatter(builder *strings.Builder, opts Options) {
+	p := opts.Provenance
+	if p.ToolVersion == "" {
+		return
+	}
+
+	builder.WriteString("---\n")
+	builder.WriteString(fmt.Sprintf("codedoc_version: %q\n", p.ToolVersion))
+	builder.WriteString(fmt.Sprintf("model: %q\n", p.Model))
+	builder.WriteString(fmt.Sprintf("prompt_version: %q\n", p.PromptVersion))
+	builder.WriteString(fmt.Sprintf("commit: %q\n", p.CommitSHA))
+	if p.Reproducible {
+		builder.WriteString("reproducible: true\n")
+		builder.WriteString(fmt.Sprintf("temperature: %g\n", p.Temperature))
+	} else {
+		builder.WriteString(fmt.Sprintf("generated_at: %q\n", p.GeneratedAt))
+	}
+
+	if len(p.Models) > 0 {
+		names := make([]string, 0, len(p.Models))
+		for name := range p.Models {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		builder.WriteString("models:\n")
+		for _, name := range names {
+			builder.WriteString(fmt.Sprintf("  %s: %q\n", name, p.Models[name]))
+		}
+	}
+
+	if len(p.Flags) > 0 {
+		names := make([]string, 0, len(p.Flags))
+		for name := range p.Flags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		builder.WriteString("flags:\n")
+		for _, name := range names {
+			builder.WriteString(fmt.Sprintf("  %s: %q\n", name, p.Flags[name]))
+		}
+	}
+
+	if len(p.Prompts) > 0 {
+		names := make([]string, 0, len(p.Prompts))
+		for name := range p.Prompts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		builder.WriteString("custom_prompts:\n")
+		for _, name := range names {
+			builder.WriteString(fmt.Sprintf("  %s: %q\n", name, p.Prompts[name]))
+		}
+	}
+
+	builder.WriteString("---\n\n")
+}
+
+// NewArtifact collects the analysis behind a report.
+func NewArtifact(opts Options) Artifact {
+	repository := opts.RepoPath
+	if opts.RepoURL != "" {
+		repository = opts.RepoURL
+	}
+
+	return Artifact{
+		Provenance:   opts.Provenance,
+		Repository:   repository,
+		Tags:         Tags(opts),
+		Roots:        opts.Roots,
+		System:       opts.System,
+		Scan:         opts.ScanResult,
+		Detection:    opts.DetectionResult,
+		Summaries:    opts.Summaries,
+		Internal:     opts.InternalDeps,
+		Dependencies: opts.Dependencies,
+		History:      opts.History,
+		Seams:        opts.Seams,
+		Areas:        opts.FunctionalAreas,
+		ModuleOwners: moduleOwners(opts),
+		Glossary:     opts.Glossary,
+		Risks:        identifyRisks(opts),
+	}
+}
+
+func WriteJSON(opts Options, path string) error {
+	data, err := json.MarshalIndent(NewArtifact(opts), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+
+	return nil
+}
+
+// WriteManifest records the provenance, including every input file, so two
+// reproducible runs can be compared input by input.
+func WriteManifest(provenance Provenance, path string) error {
+	data, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// SignFile writes a detached Ed25519 signature of path to path+".sig". The key
+// must be a PEM-encoded PKCS#8 Ed25519 private key, as produced by
+// `openssl genpkey -algorithm ed25519`.
+func SignFile(path, keyPath string) (string, error) {
+	key, err := loadSigningKey(keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := key.Sign(nil, data, crypto.Hash(0))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign %s: %w", path, err)
+	}
+
+	sigPath := path + ".sig"
+	if err := os.WriteFile(sigPath, signature, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	return sigPath, nil
+}
+
+func VerifyFile(path, sigPath string, publicKey ed25519.PublicKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	signature, err := os.ReadFile(sigPath)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, data, signature) {
+		return fmt.Errorf("signature verification failed for %s", path)
+	}
+
+	return nil
+}
+
+func loadSigningKey(keyPath string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", keyPath)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 key", keyPath)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,165 @@
+package report
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKey writes key as a PEM-encoded PKCS#8 private key, the format
+// `openssl genpkey` produces.
+func writeKey(t *testing.T, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "signing.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSignFileVerifies(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := writeKey(t, privateKey)
+
+	tests := []struct {
+		name      string
+		tamper    string
+		publicKey ed25519.PublicKey
+		wantErr   bool
+	}{
+		{name: "round trip", publicKey: publicKey},
+		{name: "tampered report", tamper: "# shop — Codebase Report, edited\n", publicKey: publicKey, wantErr: true},
+		{name: "wrong key", publicKey: otherKey, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.md")
+			if err := os.WriteFile(path, []byte("# shop — Codebase Report\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			sigPath, err := SignFile(path, keyPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sigPath != path+".sig" {
+				t.Errorf("SignFile() wrote %s, want %s.sig", sigPath, path)
+			}
+			if tt.tamper != "" {
+				if err := os.WriteFile(path, []byte(tt.tamper), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = VerifyFile(path, sigPath, tt.publicKey)
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "signature verification failed")) {
+				t.Errorf("VerifyFile() = %v, want a verification failure", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("VerifyFile() = %v", err)
+			}
+		})
+	}
+}
+
+func TestSignFileRejectsKeys(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notPEM := filepath.Join(t.TempDir(), "signing.pem")
+	if err := os.WriteFile(notPEM, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	report := filepath.Join(t.TempDir(), "report.md")
+	if err := os.WriteFile(report, []byte("# report\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keyPath string
+		want    string
+	}{
+		{"not PEM", notPEM, "is not PEM encoded"},
+		{"not Ed25519", writeKey(t, ecdsaKey), "is not an Ed25519 key"},
+		{"missing", filepath.Join(t.TempDir(), "missing.pem"), "failed to read signing key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SignFile(report, tt.keyPath); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("SignFile() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := os.Stat(report + ".sig"); !os.IsNotExist(err) {
+		t.Errorf("Expected no signature for a rejected key, got %v", err)
+	}
+}
+
+func TestWriteFrontMatter(t *testing.T) {
+	tests := []struct {
+		name       string
+		provenance Provenance
+		want       string
+	}{
+		{name: "no version", provenance: Provenance{Model: "claude"}, want: ""},
+		{
+			name: "timestamped",
+			provenance: Provenance{
+				ToolVersion: "1.2.0", Model: "claude", PromptVersion: "3", CommitSHA: "abc123", GeneratedAt: "2026-10-18T00:00:00Z",
+				Flags:   map[string]string{"top-files": "20", "format": "html"},
+				Models:  map[string]string{"module": "haiku"},
+				Prompts: map[string]string{"file": "f00d"},
+			},
+			want: "---\n" +
+				"codedoc_version: \"1.2.0\"\n" +
+				"model: \"claude\"\n" +
+				"prompt_version: \"3\"\n" +
+				"commit: \"abc123\"\n" +
+				"generated_at: \"2026-10-18T00:00:00Z\"\n" +
+				"models:\n  module: \"haiku\"\n" +
+				"flags:\n  format: \"html\"\n  top-files: \"20\"\n" +
+				"custom_prompts:\n  file: \"f00d\"\n" +
+				"---\n\n",
+		},
+		{
+			name:       "reproducible",
+			provenance: Provenance{ToolVersion: "1.2.0", Model: "claude", CommitSHA: "abc123", GeneratedAt: "ignored", Reproducible: true},
+			want: "---\n" +
+				"codedoc_version: \"1.2.0\"\n" +
+				"model: \"claude\"\n" +
+				"prompt_version: \"\"\n" +
+				"commit: \"abc123\"\n" +
+				"reproducible: true\n" +
+				"temperature: 0\n" +
+				"---\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var builder strings.Builder
+			writeFrontMatter(&builder, Options{Provenance: tt.provenance})
+			if got := builder.String(); got != tt.want {
+				t.Errorf("writeFrontMatter() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// maxReadingStops caps the files of the reading order.
+const maxReadingStops = 15
+
+// writeReadingOrder proposes the files a newcomer should read first: the
+// entrypoints, then what they import breadth-first.
+func writeReadingOrder(builder *strings.Builder, opts Options) {
+	if opts.ImportGraph == nil {
+		return
+	}
+	roots := []string{}
+	kinds := map[string]string{}
+	for _, entrypoint := range opts.DetectionResult.Entrypoints {
+		rel := filepath.ToSlash(entrypoint.Path)
+		if entrypoint.Type == "docker" || kinds[rel] != "" {
+			continue
+		}
+		roots = append(roots, rel)
+		kinds[rel] = entrypoint.Description
+	}
+	stops := opts.ImportGraph.ReadingOrder(roots, maxReadingStops)
+	if len(stops) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Reading Order") + "\n")
+	builder.WriteString(opts.text("Start at the entrypoints and follow their imports, most used first.") + "\n\n")
+	for i, stop := range stops {
+		why := opts.text("Entrypoint") + ": " + kinds[stop.File]
+		if stop.From != "" {
+			why = fmt.Sprintf(opts.text("Imported by `%s`"), stop.From)
+		}
+		if dependents := len(opts.ImportGraph.ImportedBy(stop.File)); dependents > 1 {
+			why += "; " + fmt.Sprintf(opts.text("used by %d files"), dependents)
+		}
+
+		name := "`" + stop.File + "`"
+		summary, ok := opts.Summaries.FileSummaries[filepath.FromSlash(stop.File)]
+		if ok && opts.SplitPages {
+			name = fmt.Sprintf("[%s](%s)", stop.File, filePage(filepath.FromSlash(stop.File), opts.Format))
+		}
+		builder.WriteString(fmt.Sprintf("%d. %s — %s\n", i+1, name, why))
+		if ok && summary.Summary != "" && !opts.SplitPages {
+			builder.WriteString("   " + strings.Join(strings.Fields(summary.Summary), " ") + "\n")
+		}
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/render"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+func TestReadingOrderRenders(t *testing.T) {
+	files := []scanner.FileInfo{
+		{RelativePath: "cmd/shop/main.go", Language: "go", Imports: []string{"example.com/shop/internal/orders"}},
+		{RelativePath: "internal/orders/orders.go", Language: "go"},
+	}
+	opts := Options{
+		ScanResult: &scanner.Result{Files: files},
+		DetectionResult: &detect.Result{Entrypoints: []detect.Entrypoint{
+			{Type: "go", Path: "cmd/shop/main.go", Description: "Go main package"},
+		}},
+		Summaries: &summarize.Result{FileSummaries: map[string]summarize.FileSummary{
+			"internal/orders/orders.go": {Summary: "Places and cancels orders."},
+		}},
+		ImportGraph: graph.Build(files, "example.com/shop"),
+	}
+	markdown, err := renderBuiltin(context.Background(), opts, "reading-order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, blocks := render.Parse(markdown)
+	html := render.HTMLBody(blocks)
+	for _, want := range []string{
+		"<ol>\n<li><code>cmd/shop/main.go</code> — Entrypoint: Go main package</li>\n",
+		"<li><code>internal/orders/orders.go</code> — Imported by <code>cmd/shop/main.go</code><br>\nPlaces and cancels orders.</li>\n</ol>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected %q in HTML:\n%s", want, html)
+		}
+	}
+
+	text := render.Text(markdown)
+	want := "1. cmd/shop/main.go — Entrypoint: Go main package\n" +
+		"2. internal/orders/orders.go — Imported by cmd/shop/main.go\n" +
+		"   Places and cancels orders.\n"
+	if !strings.HasSuffix(text, want) {
+		t.Errorf("Expected text to end with:\n%s\ngot:\n%s", want, text)
+	}
+}
@@ -4,15 +4,36 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/codepigeon/codedoc/internal/baseline"
+	"github.com/codepigeon/codedoc/internal/depmap"
+	"github.com/codepigeon/codedoc/internal/deps"
 	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/glossary"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/history"
+	"github.com/codepigeon/codedoc/internal/owners"
+	"github.com/codepigeon/codedoc/internal/render"
+	"github.com/codepigeon/codedoc/internal/risk"
 	"github.com/codepigeon/codedoc/internal/scanner"
 	"github.com/codepigeon/codedoc/internal/summarize"
+	"github.com/codepigeon/codedoc/internal/system"
+	"github.com/codepigeon/codedoc/internal/util"
+	"github.com/codepigeon/codedoc/internal/workspace"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatPDF      = "pdf"
+	FormatText     = "text"
 )
 
 type Options struct {
@@ -22,53 +43,217 @@ type Options struct {
 	DetectionResult *detect.Result
 	Summaries       *summarize.Result
 	OutputFile      string
+	Provenance      Provenance
+	InternalDeps    *depmap.Result
+	// Dependencies are the pinned third-party dependencies.
+	Dependencies []deps.Dependency
+	// History is the git history analysis of --git-history.
+	History *history.Result
+	// Codeowners, when the repository has a CODEOWNERS file, annotates
+	// modules with their owners.
+	Codeowners *owners.Owners
+	// Glossary are the terms mined from identifiers for --glossary.
+	Glossary []glossary.Term
+	// Seams are the candidate module boundaries of --decompose.
+	Seams []graph.Seam
+	// FunctionalAreas are the clusters of file summaries of --embeddings.
+	FunctionalAreas []embed.Area
+	// ImportGraph, when set, lets risk rules find code no entrypoint reaches.
+	ImportGraph  *graph.Graph
+	Projects     []workspace.Project
+	OwnerReports []OwnerReport
+	Format       string
+	// Roots lists the repositories of a report covering several --path
+	// roots; RepoPath is then the first of them.
+	Roots []Root
+	// System links the repositories of a multi-root report.
+	System *system.Result
+	// MaxEndpoints caps the endpoints table; 0 lists every endpoint.
+	MaxEndpoints int
+	// Baseline lists the risks the team accepted; they are reported apart.
+	Baseline *baseline.Baseline
+	// Templates, when set, override the layout or individual sections.
+	Templates *Templates
+	// SplitPages also writes one page per module and per top file next to
+	// OutputFile, which then links to them instead of inlining file details.
+	SplitPages bool
+	// Theme styles HTML and PDF output.
+	Theme render.Theme
+	// RiskRules are the risk rules of codedoc.yaml; nil uses the built-in
+	// ones.
+	RiskRules risk.Rules
+	// Language is the --output-lang tag the fixed headings are translated
+	// to, when there is a table for it.
+	Language string
 }
 
-func Generate(ctx context.Context, opts Options) error {
-	var builder strings.Builder
+// section is one part of the report. Names are how templates refer to
+// sections.
+type section struct {
+	name   string
+	write  func(*strings.Builder, Options)
+	render SectionFunc
+}
 
-	writeHeader(&builder, opts)
-	writeQuickstart(&builder, opts)
-	writeArchitecture(&builder, opts)
-	writeModules(&builder, opts)
-	writeTopFiles(&builder, opts)
-	writeEndpoints(&builder, opts)
-	writeModels(&builder, opts)
-	writeRisks(&builder, opts)
+// builtinSections lists the built-in sections in their default order.
+var builtinSections = []section{
+	{name: "front-matter", write: writeFrontMatter},
+	{name: "header", write: writeHeader},
+	{name: "scorecard", write: writeScorecard},
+	{name: "roots", write: writeRoots},
+	{name: "system", write: writeSystem},
+	{name: "projects", write: writeProjects},
+	{name: "owners", write: writeOwnerReports},
+	{name: "quickstart", write: writeQuickstart},
+	{name: "architecture", write: writeArchitecture},
+	{name: "reading-order", write: writeReadingOrder},
+	{name: "decisions", write: writeDecisions},
+	{name: "modules", write: writeModules},
+	{name: "decomposition", write: writeDecomposition},
+	{name: "functional-areas", write: writeFunctionalAreas},
+	{name: "internal-dependencies", write: writeInternalDependencies},
+	{name: "dependencies", write: writeDependencies},
+	{name: "top-files", write: writeTopFiles},
+	{name: "hotspots", write: writeHotspots},
+	{name: "complexity", write: writeComplexity},
+	{name: "endpoints", write: writeEndpoints},
+	{name: "cli-commands", write: writeCLICommands},
+	{name: "models", write: writeModels},
+	{name: "schema", write: writeSchema},
+	{name: "artifacts", write: writeArtifacts},
+	{name: "runtime-topology", write: writeRuntimeTopology},
+	{name: "infrastructure", write: writeInfrastructure},
+	{name: "pipelines", write: writePipelines},
+	{name: "configuration", write: writeConfiguration},
+	{name: "testing", write: writeTesting},
+	{name: "performance", write: writePerformance},
+	{name: "doc-gaps", write: writeDocGaps},
+	{name: "risks", write: writeRisks},
+	{name: "glossary", write: writeGlossary},
+}
 
-	content := builder.String()
+func Generate(ctx context.Context, opts Options) error {
+	markdown, err := buildMarkdown(ctx, opts)
+	if err != nil {
+		return err
+	}
 
-	if err := os.WriteFile(opts.OutputFile, []byte(content), 0o644); err != nil {
+	content, err := Render(ctx, markdown, opts.Format, reportTitle(opts), opts.Theme)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(opts.OutputFile, content, 0o644); err != nil {
 		return fmt.Errorf("failed to write report: %w", err)
 	}
 
+	if opts.SplitPages {
+		return writePages(ctx, opts)
+	}
 	return nil
 }
 
-func writeHeader(builder *strings.Builder, opts Options) {
+// Render converts report Markdown into the requested output format. The
+// theme applies to HTML and PDF.
+func Render(ctx context.Context, markdown, format, title string, theme render.Theme) ([]byte, error) {
+	switch format {
+	case "", FormatMarkdown:
+		return []byte(markdown), nil
+	case FormatHTML:
+		return []byte(render.HTML(ctx, markdown, title, theme)), nil
+	case FormatPDF:
+		return render.PDF(ctx, markdown, title, theme)
+	case FormatText:
+		return []byte(render.Text(markdown)), nil
+	default:
+		return nil, fmt.Errorf("unsupported report format %q", format)
+	}
+}
+
+// FormatExtension returns the conventional file extension for format.
+func FormatExtension(format string) string {
+	switch format {
+	case FormatHTML:
+		return ".html"
+	case FormatPDF:
+		return ".pdf"
+	case FormatText:
+		return ".txt"
+	default:
+		return ".md"
+	}
+}
+
+func buildMarkdown(ctx context.Context, opts Options) (string, error) {
+	if opts.Templates != nil {
+		return opts.Templates.execute(ctx, opts)
+	}
+
+	var builder strings.Builder
+	for _, s := range sections() {
+		content, err := s.run(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(content)
+	}
+	return builder.String(), nil
+}
+
+func reportTitle(opts Options) string {
 	repoName := opts.ScanResult.RepoMetadata.Name
 	if repoName == "" {
 		repoName = filepath.Base(opts.RepoPath)
 	}
+	return repoName + " — " + opts.heading("Codebase Report")
+}
 
-	builder.WriteString(fmt.Sprintf("# %s — Codebase Report\n\n", repoName))
+func writeHeader(builder *strings.Builder, opts Options) {
+	builder.WriteString(fmt.Sprintf("# %s\n\n", reportTitle(opts)))
+
+	if opts.Summaries != nil && opts.Summaries.Incomplete {
+		builder.WriteString(opts.label("Incomplete report") + opts.text("generation was interrupted before every summary was written. "+
+			"Summaries finished so far are cached, so rerunning completes the report quickly.") + "\n\n")
+	}
+	if limitations := opts.Provenance.Limitations; len(limitations) > 0 {
+		builder.WriteString(opts.label("Limited report") + strings.Join(limitations, "; ") + ".\n\n")
+	}
 
 	pathOrURL := opts.RepoPath
 	if opts.RepoURL != "" {
 		pathOrURL = opts.RepoURL
 	}
-	builder.WriteString(fmt.Sprintf("**Path/URL:** %s  \n", pathOrURL))
+	if len(opts.Roots) > 0 {
+		paths := make([]string, len(opts.Roots))
+		for i, root := range opts.Roots {
+			paths[i] = root.Path
+		}
+		pathOrURL = strings.Join(paths, ", ")
+	}
+	builder.WriteString(opts.label("Path/URL") + pathOrURL + "  \n")
 
-	commitInfo := getGitCommitInfo(opts.RepoPath)
-	builder.WriteString(fmt.Sprintf("**Last Commit:** %s by %s on %s  \n",
-		commitInfo.Hash, commitInfo.Author, commitInfo.Date))
+	if len(opts.Roots) == 0 {
+		commitInfo := getGitCommitInfo(opts.RepoPath)
+		builder.WriteString(opts.label("Last Commit") + fmt.Sprintf(opts.text("%s by %s on %s"),
+			commitInfo.Hash, commitInfo.Author, commitInfo.Date) + "  \n")
+	}
+
+	if tags := Tags(opts); len(tags) > 0 {
+		builder.WriteString(opts.label("Tags") + "`" + strings.Join(tags, "` `") + "`  \n")
+	}
 
-	builder.WriteString("**Languages:** ")
+	builder.WriteString(opts.label("Languages"))
 	writeLanguageBreakdown(builder, opts.ScanResult.LanguageStats)
 	builder.WriteString("  \n")
+	// The code-only view only adds something when docs or config are mixed in.
+	if code := opts.ScanResult.CodeLanguageStats; len(code) > 0 && len(code) < len(opts.ScanResult.LanguageStats) {
+		builder.WriteString(opts.label("Code (excluding docs and config)"))
+		writeLanguageBreakdown(builder, code)
+		builder.WriteString("  \n")
+	}
 
-	builder.WriteString(fmt.Sprintf("**Size:** %d files, %d LOC\n\n",
-		opts.ScanResult.TotalFiles, opts.ScanResult.TotalLines))
+	builder.WriteString(opts.label("Size") + fmt.Sprintf(opts.text("%d files, %d LOC"),
+		opts.ScanResult.TotalFiles, opts.ScanResult.TotalLines) + "\n\n")
 }
 
 func writeLanguageBreakdown(builder *strings.Builder, stats map[string]scanner.LanguageStat) {
@@ -83,7 +268,10 @@ func writeLanguageBreakdown(builder *strings.Builder, stats map[string]scanner.L
 	}
 
 	sort.Slice(languages, func(i, j int) bool {
-		return languages[i].percentage > languages[j].percentage
+		if languages[i].percentage != languages[j].percentage {
+			return languages[i].percentage > languages[j].percentage
+		}
+		return languages[i].name < languages[j].name
 	})
 
 	parts := []string{}
@@ -98,38 +286,79 @@ func writeLanguageBreakdown(builder *strings.Builder, stats map[string]scanner.L
 }
 
 func writeQuickstart(builder *strings.Builder, opts Options) {
-	builder.WriteString("## Quickstart\n")
+	builder.WriteString("## " + opts.heading("Quickstart") + "\n")
 
 	if len(opts.Summaries.QuickstartSteps) > 0 {
 		for _, step := range opts.Summaries.QuickstartSteps {
 			builder.WriteString(fmt.Sprintf("- %s\n", step))
 		}
 	} else {
-		builder.WriteString("- Clone the repository\n")
-		builder.WriteString("- Install dependencies\n")
-		builder.WriteString("- Run the application\n")
+		builder.WriteString("- " + opts.text("Clone the repository") + "\n")
+		builder.WriteString("- " + opts.text("Install dependencies") + "\n")
+		builder.WriteString("- " + opts.text("Run the application") + "\n")
 	}
 
 	builder.WriteString("\n")
 }
 
 func writeArchitecture(builder *strings.Builder, opts Options) {
-	builder.WriteString("## Architecture Overview\n")
+	builder.WriteString("## " + opts.heading("Architecture Overview") + "\n")
 
 	if opts.Summaries.ArchitectureSummary != "" {
 		builder.WriteString(opts.Summaries.ArchitectureSummary)
 	} else {
-		builder.WriteString("Architecture overview not available (dry-run mode or LLM unavailable).")
+		builder.WriteString(opts.text("Architecture overview not available (dry-run mode or LLM unavailable)."))
 	}
 
 	builder.WriteString("\n\n")
 }
 
+func writeDecisions(builder *strings.Builder, opts Options) {
+	decisions := opts.DetectionResult.Decisions
+	if len(decisions) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Architecture Decisions") + "\n")
+	opts.writeTableHeader(builder, "ADR", "Decision", "Status", "Date", "File")
+	for _, decision := range decisions {
+		builder.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |\n",
+			decision.Number, decision.Title, orDash(decision.Status), orDash(decision.Date), decision.File))
+	}
+	builder.WriteString("\n")
+}
+
 func writeModules(builder *strings.Builder, opts Options) {
-	builder.WriteString("## Key Modules / Directories\n")
-	builder.WriteString("| Module | Summary |\n")
-	builder.WriteString("|---|---|\n")
+	ownership := moduleOwners(opts)
+	builder.WriteString("## " + opts.heading("Key Modules / Directories") + "\n")
+	columns := []string{"Module", "Summary"}
+	if opts.Codeowners != nil {
+		columns = append(columns, "Owners")
+	}
+	if opts.History != nil {
+		columns = append(columns, "Recent contributors")
+	}
+	opts.writeTableHeader(builder, columns...)
 
+	for _, module := range reportModules(opts) {
+		name := "/" + module
+		if opts.SplitPages {
+			name = fmt.Sprintf("[/%s](%s)", module, modulePage(module, opts.Format))
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %s |", name, moduleSummary(opts, module)))
+		if opts.Codeowners != nil {
+			builder.WriteString(" " + orDash(strings.Join(ownership[module].Owners, ", ")) + " |")
+		}
+		if opts.History != nil {
+			builder.WriteString(" " + orDash(strings.Join(ownership[module].Contributors, ", ")) + " |")
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("\n")
+}
+
+func reportModules(opts Options) []string {
 	modules := []string{}
 	for module := range opts.Summaries.ModuleSummaries {
 		modules = append(modules, module)
@@ -139,21 +368,63 @@ func writeModules(builder *strings.Builder, opts Options) {
 	if len(modules) == 0 {
 		modules = identifyModulesFromScan(opts.ScanResult)
 	}
+	return modules
+}
+
+func moduleSummary(opts Options, module string) string {
+	if summary := opts.Summaries.ModuleSummaries[module]; summary != "" {
+		return summary
+	}
+	return fmt.Sprintf(opts.text("Module containing %s functionality"), opts.text(getModuleType(module)))
+}
+
+func writeInternalDependencies(builder *strings.Builder, opts Options) {
+	deps := opts.InternalDeps
+	if deps == nil {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Internal Dependencies") + "\n")
+
+	if len(deps.DependsOn) > 0 {
+		opts.writeTableHeader(builder, "Library", "Packages", "Used in")
+		for _, lib := range deps.DependsOn {
+			builder.WriteString(fmt.Sprintf("| %s | %d | %s |\n",
+				lib.Name, len(lib.Packages), fmt.Sprintf(opts.text("%d files"), len(lib.Files))))
+		}
+	} else {
+		builder.WriteString(opts.text("No internal shared libraries used.") + "\n")
+	}
 
-	for _, module := range modules {
-		summary := opts.Summaries.ModuleSummaries[module]
-		if summary == "" {
-			summary = fmt.Sprintf("Module containing %s functionality", getModuleType(module))
+	if len(deps.Consumers) > 0 {
+		builder.WriteString("\n**" + fmt.Sprintf(opts.text("Packages of %s consumed elsewhere"), deps.Module) + "**\n")
+		opts.writeTableHeader(builder, "Package", "Consumed by")
+		for _, consumer := range deps.Consumers {
+			builder.WriteString(fmt.Sprintf("| %s | %s |\n", consumer.Package, strings.Join(consumer.Repos, ", ")))
 		}
-		builder.WriteString(fmt.Sprintf("| /%s | %s |\n", module, summary))
 	}
 
 	builder.WriteString("\n")
 }
 
 func writeTopFiles(builder *strings.Builder, opts Options) {
-	builder.WriteString("## Top Files\n")
+	builder.WriteString("## " + opts.heading("Top Files") + "\n")
 
+	if opts.SplitPages {
+		for _, path := range reportFiles(opts) {
+			builder.WriteString(fmt.Sprintf("- [%s](%s)\n", path, filePage(path, opts.Format)))
+		}
+		builder.WriteString("\n")
+		return
+	}
+
+	for _, path := range reportFiles(opts) {
+		builder.WriteString(fmt.Sprintf("### %s\n", path))
+		writeFileSummary(builder, opts, opts.Summaries.FileSummaries[path])
+	}
+}
+
+func reportFiles(opts Options) []string {
 	files := []string{}
 	for path := range opts.Summaries.FileSummaries {
 		files = append(files, path)
@@ -163,59 +434,127 @@ func writeTopFiles(builder *strings.Builder, opts Options) {
 	if len(files) == 0 {
 		files = selectTopFilesForReport(opts.ScanResult.Files, 5)
 	}
+	return files
+}
 
-	for _, path := range files {
-		summary := opts.Summaries.FileSummaries[path]
+func writeFileSummary(builder *strings.Builder, opts Options, summary summarize.FileSummary) {
+	role := "**" + opts.text("Role") + ".** "
+	if summary.Summary != "" {
+		builder.WriteString(role + summary.Summary + "\n\n")
+	} else {
+		builder.WriteString(role + opts.text("File summary not available.") + "\n\n")
+	}
 
-		builder.WriteString(fmt.Sprintf("### %s\n", path))
+	if len(summary.Functions) > 0 {
+		builder.WriteString("**" + opts.text("Key functions/classes") + "**\n")
+		for _, fn := range summary.Functions {
+			builder.WriteString(fmt.Sprintf("- %s\n", fn))
+		}
+		builder.WriteString("\n")
+	}
+}
 
-		if summary.Summary != "" {
-			builder.WriteString(fmt.Sprintf("**Role.** %s\n\n", summary.Summary))
+// minConfidence is the evidence a detection needs to be listed in the
+// report. Weaker detections are still written to the JSON artifact.
+const minConfidence = detect.ConfidenceMedium
+
+func confident[T any](items []T, confidence func(T) detect.Confidence) (kept []T, suppressed int) {
+	kept = []T{}
+	for _, item := range items {
+		if confidence(item) >= minConfidence {
+			kept = append(kept, item)
 		} else {
-			builder.WriteString("**Role.** File summary not available.\n\n")
+			suppressed++
 		}
+	}
+	return kept, suppressed
+}
 
-		if len(summary.Functions) > 0 {
-			builder.WriteString("**Key functions/classes**\n")
-			for _, fn := range summary.Functions {
-				builder.WriteString(fmt.Sprintf("- %s\n", fn))
-			}
-			builder.WriteString("\n")
-		}
+// writeSuppressed notes count hidden detections with format, which has one
+// %d verb.
+func writeSuppressed(builder *strings.Builder, opts Options, count int, format string) {
+	if count > 0 {
+		builder.WriteString("\n" + fmt.Sprintf(opts.text(format), count) + "\n")
 	}
 }
 
 func writeEndpoints(builder *strings.Builder, opts Options) {
-	builder.WriteString("## HTTP Endpoints (detected)\n")
+	builder.WriteString("## " + opts.heading("HTTP Endpoints (detected)") + "\n")
+
+	allEndpoints, suppressed := confident(opts.DetectionResult.Endpoints, func(e detect.Endpoint) detect.Confidence { return e.Confidence })
+	if len(allEndpoints) > 0 {
+		hasSpec := false
+		for _, endpoint := range allEndpoints {
+			if endpoint.Source == "openapi" {
+				hasSpec = true
+				break
+			}
+		}
 
-	if len(opts.DetectionResult.Endpoints) > 0 {
-		builder.WriteString("| Method | Path | Handler/File |\n")
-		builder.WriteString("|---|---|---|\n")
+		if hasSpec {
+			opts.writeTableHeader(builder, "Method", "Path", "Summary", "Request", "Response", "Handler/File")
+		} else {
+			opts.writeTableHeader(builder, "Method", "Path", "Handler/File")
+		}
 
-		count := 0
-		for _, endpoint := range opts.DetectionResult.Endpoints {
-			builder.WriteString(fmt.Sprintf("| %s | %s | %s |\n",
-				endpoint.Method, endpoint.Path, endpoint.File))
-			count++
-			if count >= 20 {
-				break
+		endpoints := allEndpoints
+		if opts.MaxEndpoints > 0 && len(endpoints) > opts.MaxEndpoints {
+			endpoints = endpoints[:opts.MaxEndpoints]
+		}
+		for _, endpoint := range endpoints {
+			location := endpoint.File
+			if endpoint.Handler != "" {
+				location = fmt.Sprintf("%s (%s)", endpoint.Handler, endpoint.File)
+			}
+			if hasSpec {
+				builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+					endpoint.Method, endpoint.Path, orDash(endpoint.Summary),
+					orDash(endpoint.Request), orDash(endpoint.Response), location))
+			} else {
+				builder.WriteString(fmt.Sprintf("| %s | %s | %s |\n",
+					endpoint.Method, endpoint.Path, location))
 			}
 		}
+
+		if hidden := len(allEndpoints) - len(endpoints); hidden > 0 {
+			builder.WriteString("\n" + fmt.Sprintf(opts.text("%d more endpoints not shown (raise --max-endpoints or set it to 0 to list all)."), hidden) + "\n")
+		}
 	} else {
-		builder.WriteString("No HTTP endpoints detected.\n")
+		builder.WriteString(opts.text("No HTTP endpoints detected.") + "\n")
+	}
+	writeSuppressed(builder, opts, suppressed, "%d low-confidence endpoints hidden (listed in the JSON artifact).")
+
+	builder.WriteString("\n")
+}
+
+func writeCLICommands(builder *strings.Builder, opts Options) {
+	if len(opts.DetectionResult.CLICommands) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("CLI Commands") + "\n")
+	opts.writeTableHeader(builder, "Command", "Description", "Flags", "File")
+
+	for _, command := range opts.DetectionResult.CLICommands {
+		flags := strings.Join(command.Flags[:min(6, len(command.Flags))], ", ")
+		if len(command.Flags) > 6 {
+			flags += ", ..."
+		}
+		builder.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s (%s) |\n",
+			command.Name, orDash(command.Description), orDash(flags), command.File, command.Framework))
 	}
 
 	builder.WriteString("\n")
 }
 
 func writeModels(builder *strings.Builder, opts Options) {
-	builder.WriteString("## Data Models (detected)\n")
+	builder.WriteString("## " + opts.heading("Data Models (detected)") + "\n")
 
-	if len(opts.DetectionResult.Models) > 0 {
-		builder.WriteString("| Model | Fields | File |\n")
-		builder.WriteString("|---|---|---|\n")
+	models, suppressed := confident(opts.DetectionResult.Models, func(m detect.Model) detect.Confidence { return m.Confidence })
+	if len(models) > 0 {
+		opts.writeTableHeader(builder, "Model", "Fields", "File")
 
-		for _, model := range opts.DetectionResult.Models {
+		for _, model := range models {
 			fields := strings.Join(model.Fields[:min(5, len(model.Fields))], ", ")
 			if len(model.Fields) > 5 {
 				fields += ", ..."
@@ -224,28 +563,500 @@ func writeModels(builder *strings.Builder, opts Options) {
 				model.Name, fields, model.File))
 		}
 	} else {
-		builder.WriteString("No data models detected.\n")
+		builder.WriteString(opts.text("No data models detected.") + "\n")
 	}
+	writeSuppressed(builder, opts, suppressed, "%d low-confidence models hidden (listed in the JSON artifact).")
 
 	builder.WriteString("\n")
 }
 
-func writeRisks(builder *strings.Builder, opts Options) {
-	builder.WriteString("## Notable Risks / TODOs\n")
+func writeSchema(builder *strings.Builder, opts Options) {
+	if len(opts.DetectionResult.Tables) == 0 {
+		return
+	}
 
-	risks := identifyRisks(opts)
+	builder.WriteString("## " + opts.heading("Database Schema") + "\n")
+	opts.writeTableHeader(builder, "Table", "Columns", "Defined in", "Referenced by")
 
-	if len(risks) > 0 {
-		for _, risk := range risks {
-			builder.WriteString(fmt.Sprintf("- %s\n", risk))
+	for _, table := range opts.DetectionResult.Tables {
+		columns := strings.Join(table.Columns[:min(8, len(table.Columns))], ", ")
+		if len(table.Columns) > 8 {
+			columns += ", ..."
 		}
-	} else {
-		builder.WriteString("- No significant risks detected\n")
+
+		references := strings.Join(table.References[:min(3, len(table.References))], ", ")
+		if len(table.References) > 3 {
+			references += " (" + fmt.Sprintf(opts.text("+%d more"), len(table.References)-3) + ")"
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s (%s) | %s |\n",
+			table.Name, columns, table.Source, table.Kind, orDash(references)))
 	}
 
 	builder.WriteString("\n")
 }
 
+func writeArtifacts(builder *strings.Builder, opts Options) {
+	if len(opts.DetectionResult.Artifacts) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Build Artifacts") + "\n")
+	opts.writeTableHeader(builder, "Kind", "Name", "Targets", "Published to", "Defined in")
+
+	for _, artifact := range opts.DetectionResult.Artifacts {
+		targets := "-"
+		if len(artifact.Targets) > 0 {
+			targets = strings.Join(artifact.Targets, ", ")
+		}
+
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+			artifact.Kind, artifact.Name, targets, artifact.Destination, artifact.Source))
+	}
+
+	builder.WriteString("\n")
+}
+
+func writeRuntimeTopology(builder *strings.Builder, opts Options) {
+	charts := opts.DetectionResult.HelmCharts
+	resources := opts.DetectionResult.K8s
+	if len(charts) == 0 && len(resources) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Runtime Topology") + "\n")
+
+	if len(charts) > 0 {
+		builder.WriteString("### " + opts.heading("Helm Charts") + "\n")
+		for _, chart := range charts {
+			line := fmt.Sprintf("- **%s** %s (`%s`)", chart.Name, chart.Version, chart.Path)
+			if chart.AppVersion != "" {
+				line += ", " + fmt.Sprintf(opts.text("app version %s"), chart.AppVersion)
+			}
+			if len(chart.Dependencies) > 0 {
+				line += ", " + fmt.Sprintf(opts.text("depends on %s"), strings.Join(chart.Dependencies, ", "))
+			}
+			builder.WriteString(line + "\n")
+		}
+		builder.WriteString("\n")
+	}
+
+	var workloads, services, ingresses, configMaps []detect.K8sResource
+	for _, resource := range resources {
+		switch resource.Kind {
+		case "Service":
+			services = append(services, resource)
+		case "Ingress":
+			ingresses = append(ingresses, resource)
+		case "ConfigMap":
+			configMaps = append(configMaps, resource)
+		default:
+			workloads = append(workloads, resource)
+		}
+	}
+
+	if len(workloads) > 0 {
+		builder.WriteString("### " + opts.heading("Workloads") + "\n")
+		opts.writeTableHeader(builder, "Kind", "Name", "Replicas", "Images", "Ports", "Env sources", "Source")
+		for _, w := range workloads {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s |\n",
+				w.Kind, w.Name, orDash(w.Replicas), orDash(strings.Join(w.Images, ", ")),
+				orDash(strings.Join(w.Ports, ", ")), orDash(strings.Join(w.EnvSources, ", ")), w.Source))
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(services) > 0 {
+		builder.WriteString("### " + opts.heading("Services") + "\n")
+		opts.writeTableHeader(builder, "Name", "Type", "Ports", "Source")
+		for _, svc := range services {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				svc.Name, svc.ServiceType, orDash(strings.Join(svc.Ports, ", ")), svc.Source))
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(ingresses) > 0 {
+		builder.WriteString("### " + opts.heading("Ingresses") + "\n")
+		opts.writeTableHeader(builder, "Name", "Hosts", "Routes", "Source")
+		for _, ing := range ingresses {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				ing.Name, orDash(strings.Join(ing.Hosts, ", ")), orDash(strings.Join(ing.Backends, ", ")), ing.Source))
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(configMaps) > 0 {
+		builder.WriteString("### " + opts.heading("ConfigMaps") + "\n")
+		for _, cm := range configMaps {
+			builder.WriteString(fmt.Sprintf("- **%s** (`%s`): %s\n",
+				cm.Name, cm.Source, orDash(strings.Join(cm.DataKeys, ", "))))
+		}
+		builder.WriteString("\n")
+	}
+}
+
+func writeInfrastructure(builder *strings.Builder, opts Options) {
+	infra := opts.DetectionResult.Infrastructure
+	if len(infra.Resources) == 0 && len(infra.Modules) == 0 {
+		return
+	}
+
+	type resourceGroup struct {
+		provider, kind string
+		names          []string
+	}
+	groups := map[string]*resourceGroup{}
+	perProvider := map[string]int{}
+	dataSources := []string{}
+	for _, resource := range infra.Resources {
+		if resource.Data {
+			dataSources = append(dataSources, resource.Type)
+			continue
+		}
+		group, ok := groups[resource.Type]
+		if !ok {
+			group = &resourceGroup{provider: resource.Provider, kind: resource.Type}
+			groups[resource.Type] = group
+		}
+		group.names = append(group.names, resource.Name)
+		perProvider[resource.Provider]++
+	}
+
+	builder.WriteString("## " + opts.heading("Infrastructure") + "\n")
+	builder.WriteString(opts.label("Resources") + fmt.Sprintf(opts.text("%d of %d types"), len(infra.Resources)-len(dataSources), len(groups)) + "  \n")
+	if len(dataSources) > 0 {
+		sort.Strings(dataSources)
+		builder.WriteString(opts.label("Data sources") + strings.Join(slices.Compact(dataSources), ", ") + "  \n")
+	}
+	builder.WriteString(opts.label("Modules") + strconv.Itoa(len(infra.Modules)) + "\n\n")
+
+	if len(infra.Providers) > 0 {
+		builder.WriteString("### " + opts.heading("Providers") + "\n")
+		opts.writeTableHeader(builder, "Provider", "Source", "Version", "Resources")
+		for _, provider := range infra.Providers {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s | %d |\n",
+				provider.Name, orDash(provider.Source), orDash(provider.Version), perProvider[provider.Name]))
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(groups) > 0 {
+		ordered := make([]*resourceGroup, 0, len(groups))
+		for _, group := range groups {
+			ordered = append(ordered, group)
+		}
+		sort.Slice(ordered, func(i, j int) bool {
+			if ordered[i].provider != ordered[j].provider {
+				return ordered[i].provider < ordered[j].provider
+			}
+			return ordered[i].kind < ordered[j].kind
+		})
+
+		builder.WriteString("### " + opts.heading("Resources") + "\n")
+		opts.writeTableHeader(builder, "Provider", "Type", "Count", "Names")
+		for _, group := range ordered {
+			builder.WriteString(fmt.Sprintf("| %s | `%s` | %d | %s |\n",
+				group.provider, group.kind, len(group.names), strings.Join(group.names, ", ")))
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(infra.Modules) > 0 {
+		builder.WriteString("### " + opts.heading("Modules") + "\n")
+		opts.writeTableHeader(builder, "Name", "Source", "Version", "File")
+		for _, module := range infra.Modules {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				module.Name, orDash(module.Source), orDash(module.Version), module.File))
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(infra.Roots) > 0 {
+		builder.WriteString("### " + opts.heading("Root Configurations") + "\n")
+		opts.writeTableHeader(builder, "Directory", "Backend", "Variable files")
+		for _, root := range infra.Roots {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s |\n",
+				root.Dir, orDash(root.Backend), orDash(strings.Join(root.VarFiles, ", "))))
+		}
+		builder.WriteString("\n")
+	}
+}
+
+var ciSystemNames = map[string]string{
+	"github-actions": "GitHub Actions",
+	"gitlab-ci":      "GitLab CI",
+	"jenkins":        "Jenkins",
+	"circleci":       "CircleCI",
+}
+
+// maxJobCommands caps the commands listed per CI job.
+const maxJobCommands = 5
+
+func writePipelines(builder *strings.Builder, opts Options) {
+	pipelines := opts.DetectionResult.Pipelines
+	if len(pipelines) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("CI/CD Pipelines") + "\n")
+
+	deploys := []string{}
+	for _, pipeline := range pipelines {
+		for _, job := range pipeline.Jobs {
+			if job.Deploy {
+				deploys = append(deploys, fmt.Sprintf("`%s` in %s (%s)", job.Name, pipeline.Name, orDash(strings.Join(pipeline.Triggers, ", "))))
+			}
+		}
+	}
+	if len(deploys) > 0 {
+		builder.WriteString(opts.label("Deployment") + strings.Join(deploys, "; ") + "\n\n")
+	}
+
+	for _, pipeline := range pipelines {
+		builder.WriteString(fmt.Sprintf("### %s\n", pipeline.Name))
+		line := fmt.Sprintf("`%s` (%s)", pipeline.File, ciSystemNames[pipeline.System])
+		if len(pipeline.Triggers) > 0 {
+			line += ", " + fmt.Sprintf(opts.text("triggered by %s"), strings.Join(pipeline.Triggers, ", "))
+		}
+		builder.WriteString(line + "\n\n")
+
+		for _, job := range pipeline.Jobs {
+			details := []string{}
+			if job.Deploy {
+				details = append(details, opts.text("deploys"))
+			}
+			if job.Stage != "" {
+				details = append(details, fmt.Sprintf(opts.text("stage %s"), job.Stage))
+			}
+			if len(job.Needs) > 0 {
+				details = append(details, fmt.Sprintf(opts.text("needs %s"), strings.Join(job.Needs, ", ")))
+			}
+			if job.Environment != "" {
+				details = append(details, fmt.Sprintf(opts.text("environment %s"), job.Environment))
+			}
+			if len(job.Conditions) > 0 {
+				details = append(details, fmt.Sprintf(opts.text("when %s"), strings.Join(job.Conditions, ", ")))
+			}
+
+			line := "- **" + job.Name + "**"
+			if len(details) > 0 {
+				line += " (" + strings.Join(details, "; ") + ")"
+			}
+			steps := []string{}
+			for _, uses := range job.Uses {
+				steps = append(steps, fmt.Sprintf(opts.text("uses %s"), "`"+uses+"`"))
+			}
+			for _, command := range job.Commands {
+				steps = append(steps, "`"+command+"`")
+			}
+			if len(steps) > maxJobCommands {
+				steps = append(steps[:maxJobCommands], fmt.Sprintf(opts.text("%d more"), len(steps)-maxJobCommands))
+			}
+			if len(steps) > 0 {
+				line += ": " + strings.Join(steps, ", ")
+			}
+			builder.WriteString(line + "\n")
+		}
+		builder.WriteString("\n")
+	}
+}
+
+func writeConfiguration(builder *strings.Builder, opts Options) {
+	if len(opts.DetectionResult.ConfigFiles) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Configuration") + "\n")
+
+	for _, config := range opts.DetectionResult.ConfigFiles {
+		builder.WriteString(fmt.Sprintf("### %s\n", config.Path))
+
+		var descriptions map[string]string
+		if opts.Summaries != nil {
+			descriptions = opts.Summaries.ConfigSummaries[config.Path]
+		}
+		if overview := descriptions[""]; overview != "" {
+			builder.WriteString(overview + "\n\n")
+		}
+
+		opts.writeTableHeader(builder, "Section", "Type", "Keys", "Controls")
+		for _, section := range config.Sections {
+			keys := strings.Join(section.Keys, ", ")
+			builder.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s |\n",
+				section.Key, section.Type, orDash(keys), orDash(descriptions[section.Key])))
+		}
+		builder.WriteString("\n")
+	}
+}
+
+func writeTesting(builder *strings.Builder, opts Options) {
+	testing := opts.DetectionResult.Testing
+	if len(testing.Frameworks) == 0 && len(testing.Modules) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Testing") + "\n")
+
+	if len(testing.Frameworks) > 0 {
+		opts.writeTableHeader(builder, "Framework", "Run with", "Detected from")
+		for _, fw := range testing.Frameworks {
+			builder.WriteString(fmt.Sprintf("| %s | `%s` | %s |\n", fw.Name, fw.Command, fw.Source))
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(testing.Modules) > 0 {
+		total := 0
+		for _, module := range testing.Modules {
+			total += module.Files
+		}
+		builder.WriteString(opts.label("Test files") + strconv.Itoa(total) + "\n\n")
+		opts.writeTableHeader(builder, "Module", "Test files")
+		for _, module := range testing.Modules {
+			builder.WriteString(fmt.Sprintf("| %s | %d |\n", module.Path, module.Files))
+		}
+		builder.WriteString("\n")
+	}
+
+	for _, coverage := range testing.Coverage {
+		builder.WriteString(fmt.Sprintf("- %s: **%.1f%%** (%s, `%s`)\n", opts.text("Coverage"), coverage.Percent, coverage.Format, coverage.File))
+	}
+	if len(testing.Coverage) > 0 {
+		builder.WriteString("\n")
+	}
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func writePerformance(builder *strings.Builder, opts Options) {
+	hints := opts.DetectionResult.Performance
+	if len(hints) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Performance Notes") + "\n")
+	builder.WriteString(opts.text("Heuristic hints from code patterns; confirm them with a profiler before optimizing.") + "\n\n")
+	opts.writeTableHeader(builder, "Location", "Note", "Code")
+	for _, hint := range hints[:min(20, len(hints))] {
+		builder.WriteString(fmt.Sprintf("| `%s:%d` | %s (%s) | `%s` |\n",
+			hint.File, hint.Line, hint.Message, hint.Rule, strings.ReplaceAll(hint.Code, "|", "\\|")))
+	}
+	if len(hints) > 20 {
+		builder.WriteString("\n_" + fmt.Sprintf(opts.text("%d more hints in the JSON artifact."), len(hints)-20) + "_\n")
+	}
+
+	builder.WriteString("\n")
+}
+
+func writeRisks(builder *strings.Builder, opts Options) {
+	builder.WriteString("## " + opts.heading("Notable Risks / TODOs") + "\n")
+
+	risks, acknowledged := opts.risks()
+	findings, acknowledgedFindings := opts.findings()
+	secrets, acknowledgedSecrets := opts.secrets()
+	acknowledged = append(append(acknowledged, acknowledgedFindings...), acknowledgedSecrets...)
+
+	// Rule risks, findings and secrets are listed together, most severe
+	// first; each kind is already ranked.
+	type item struct {
+		severity string
+		line     string
+	}
+	items := []item{}
+	if len(secrets) > 0 {
+		files := make(map[string]bool)
+		for _, secret := range secrets {
+			files[secret.File] = true
+		}
+		items = append(items, item{detect.SeverityHigh, fmt.Sprintf(opts.text("%d potential secrets committed in %d files; rotate them and remove them from history (see %s)"),
+			len(secrets), len(files), opts.heading("Potential Secrets"))})
+	}
+	for _, r := range risks {
+		if r.File != "" && !strings.Contains(r.Message, r.File) {
+			items = append(items, item{r.Severity, fmt.Sprintf("`%s` %s (%s)", r.File, r.Message, r.Rule)})
+		} else {
+			items = append(items, item{r.Severity, fmt.Sprintf("%s (%s)", r.Message, r.Rule)})
+		}
+	}
+	for _, finding := range findings {
+		items = append(items, item{finding.Severity, fmt.Sprintf("`%s` %s (%s)", findingLocation(finding), finding.Message, finding.Rule)})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return risk.Rank(items[i].severity) < risk.Rank(items[j].severity)
+	})
+
+	if len(items) == 0 {
+		builder.WriteString("- " + opts.text("No significant risks detected") + "\n")
+	}
+	for _, item := range items[:min(maxRisks, len(items))] {
+		builder.WriteString(fmt.Sprintf("- %s %s\n", severityBadge(item.severity), item.line))
+	}
+	if len(items) > maxRisks {
+		builder.WriteString("\n_" + fmt.Sprintf(opts.text("%d more risks in the JSON artifact."), len(items)-maxRisks) + "_\n")
+	}
+
+	builder.WriteString("\n")
+	writeSecrets(builder, opts, secrets)
+	writeTodos(builder, opts)
+	writeAcknowledged(builder, opts, acknowledged)
+}
+
+// maxRisks caps the risks listed in the report, mostly against custom rules
+// matching many files.
+const maxRisks = 30
+
+func severityBadge(severity string) string {
+	return "**[" + severity + "]**"
+}
+
+func writeSecrets(builder *strings.Builder, opts Options, secrets []detect.Secret) {
+	if len(secrets) == 0 {
+		return
+	}
+
+	builder.WriteString("### " + opts.heading("Potential Secrets") + "\n")
+	builder.WriteString(opts.text("Values are masked. Add `codedoc:allow-secret` to a line to mark a false positive.") + "\n\n")
+	opts.writeTableHeader(builder, "Location", "Rule", "Match")
+	for _, secret := range secrets {
+		builder.WriteString(fmt.Sprintf("| `%s:%d` | %s | `%s` |\n", secret.File, secret.Line, secret.Rule, secret.Match))
+	}
+	builder.WriteString("\n")
+}
+
+// maxTodos caps the TODO comments listed in the report.
+const maxTodos = 20
+
+func writeTodos(builder *strings.Builder, opts Options) {
+	todos := opts.DetectionResult.Todos
+	if len(todos) == 0 {
+		return
+	}
+
+	builder.WriteString("### " + opts.heading("Open TODOs") + "\n")
+	builder.WriteString(fmt.Sprintf(opts.text("%d TODO, FIXME, HACK and XXX comments, FIXMEs and HACKs first."), len(todos)) + "\n\n")
+	opts.writeTableHeader(builder, "Location", "Kind", "Note", "Author")
+	for _, todo := range todos[:min(maxTodos, len(todos))] {
+		author := todo.Author
+		if author == "" {
+			author = todo.Owner
+		}
+		if todo.Date != "" {
+			author += " (" + todo.Date + ")"
+		}
+		builder.WriteString(fmt.Sprintf("| `%s:%d` | %s | %s | %s |\n",
+			todo.File, todo.Line, todo.Kind, strings.ReplaceAll(todo.Text, "|", "\\|"), author))
+	}
+	if len(todos) > maxTodos {
+		builder.WriteString("\n_" + fmt.Sprintf(opts.text("%d more TODOs in the JSON artifact."), len(todos)-maxTodos) + "_\n")
+	}
+	builder.WriteString("\n")
+}
+
 func getGitCommitInfo(repoPath string) scanner.CommitInfo {
 	info := scanner.CommitInfo{
 		Hash:   "unknown",
@@ -253,19 +1064,14 @@ func getGitCommitInfo(repoPath string) scanner.CommitInfo {
 		Date:   time.Now().Format("2006-01-02"),
 	}
 
-	cmd := exec.Command("git", "log", "-1", "--format=%H|%an|%ad", "--date=short")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+	commit, err := util.GitLastCommit(repoPath)
 	if err != nil {
 		return info
 	}
 
-	parts := strings.Split(strings.TrimSpace(string(output)), "|")
-	if len(parts) >= 3 {
-		info.Hash = parts[0][:7]
-		info.Author = parts[1]
-		info.Date = parts[2]
-	}
+	info.Hash = commit.Hash[:min(7, len(commit.Hash))]
+	info.Author = commit.Author
+	info.Date = commit.Date
 
 	return info
 }
@@ -349,85 +1155,12 @@ func selectTopFilesForReport(files []scanner.FileInfo, limit int) []string {
 	return paths
 }
 
-func identifyRisks(opts Options) []string {
-	risks := []string{}
-
-	if opts.ScanResult.TotalFiles > 1000 {
-		risks = append(risks, fmt.Sprintf("Large codebase with %d files may benefit from modularization",
-			opts.ScanResult.TotalFiles))
-	}
-
-	testCount := 0
-	for _, file := range opts.ScanResult.Files {
-		if file.IsTest {
-			testCount++
-		}
+func identifyRisks(opts Options) []risk.Risk {
+	rules := opts.RiskRules
+	if rules == nil {
+		rules = risk.Builtin()
 	}
-
-	if float64(testCount)/float64(opts.ScanResult.TotalFiles) < 0.1 {
-		risks = append(risks, "Low test coverage (less than 10% test files)")
-	}
-
-	for _, file := range opts.ScanResult.Files {
-		if file.Lines > 1000 {
-			risks = append(risks, fmt.Sprintf("Large file: %s (%d lines) - consider splitting",
-				file.RelativePath, file.Lines))
-			break
-		}
-	}
-
-	hasTests := false
-	hasDocs := false
-	hasCI := false
-
-	for _, file := range opts.ScanResult.Files {
-		base := filepath.Base(file.RelativePath)
-		if strings.Contains(base, "test") {
-			hasTests = true
-		}
-		if base == "README.md" || base == "CONTRIBUTING.md" {
-			hasDocs = true
-		}
-		if strings.Contains(file.RelativePath, ".github/workflows") ||
-			base == ".gitlab-ci.yml" || base == "Jenkinsfile" {
-			hasCI = true
-		}
-	}
-
-	if !hasTests {
-		risks = append(risks, "No test files detected")
-	}
-	if !hasDocs {
-		risks = append(risks, "Missing README.md documentation")
-	}
-	if !hasCI {
-		risks = append(risks, "No CI/CD configuration detected")
-	}
-
-	if len(opts.DetectionResult.Frameworks) > 3 {
-		risks = append(risks, fmt.Sprintf("Multiple frameworks detected (%d) - consider consolidation",
-			len(opts.DetectionResult.Frameworks)))
-	}
-
-	foundLockFile := false
-	for _, file := range opts.ScanResult.Files {
-		base := filepath.Base(file.RelativePath)
-		if base == "package-lock.json" || base == "go.sum" || base == "Gemfile.lock" ||
-			base == "yarn.lock" || base == "poetry.lock" || base == "Cargo.lock" {
-			foundLockFile = true
-			break
-		}
-	}
-
-	if !foundLockFile && len(opts.DetectionResult.BuildTools) > 0 {
-		risks = append(risks, "Missing dependency lock file")
-	}
-
-	if len(risks) > 10 {
-		risks = risks[:10]
-	}
-
-	return risks
+	return rules.Evaluate(risk.Input{Scan: opts.ScanResult, Detection: opts.DetectionResult, Graph: opts.ImportGraph})
 }
 
 func min(a, b int) int {
@@ -0,0 +1,49 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/render"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+func TestHeaderNotesRender(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		html string
+		text string
+	}{
+		{
+			name: "incomplete",
+			opts: Options{Summaries: &summarize.Result{Incomplete: true}},
+			html: "<p><strong>Incomplete report:</strong> generation was interrupted",
+			text: "\nIncomplete report: generation was interrupted",
+		},
+		{
+			name: "limited",
+			opts: Options{Provenance: Provenance{Limitations: []string{"no summaries", "no dependency audit"}}},
+			html: "<p><strong>Limited report:</strong> no summaries; no dependency audit.</p>",
+			text: "\nLimited report: no summaries; no dependency audit.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.RepoPath = "/src/shop"
+			tt.opts.ScanResult = &scanner.Result{}
+			var builder strings.Builder
+			writeHeader(&builder, tt.opts)
+			markdown := builder.String()
+
+			_, blocks := render.Parse(markdown)
+			if html := render.HTMLBody(blocks); !strings.Contains(html, tt.html) || strings.Contains(html, "&gt;") {
+				t.Errorf("Expected %q in HTML:\n%s", tt.html, html)
+			}
+			if text := render.Text(markdown); !strings.Contains(text, tt.text) || strings.Contains(text, ">") {
+				t.Errorf("Expected %q in text:\n%s", tt.text, text)
+			}
+		})
+	}
+}
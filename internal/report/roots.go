@@ -0,0 +1,120 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// Root is one repository of a report that covers several --path roots. File
+// paths in the merged results are prefixed with Name.
+type Root struct {
+	Name string          `json:"name"`
+	Path string          `json:"path"`
+	Scan *scanner.Result `json:"-"`
+}
+
+func writeRoots(builder *strings.Builder, opts Options) {
+	if len(opts.Roots) == 0 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("Repositories") + "\n")
+	builder.WriteString("This report covers several repositories; paths are prefixed with the repository name.\n\n")
+	builder.WriteString("| Repository | Path | Last Commit | Size | Languages |\n")
+	builder.WriteString("|---|---|---|---|---|\n")
+	for _, root := range opts.Roots {
+		commit := getGitCommitInfo(root.Path)
+		var languages strings.Builder
+		writeLanguageBreakdown(&languages, root.Scan.LanguageStats)
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s (%s) | %d files, %d LOC | %s |\n",
+			root.Name, root.Path, commit.Hash, commit.Date,
+			root.Scan.TotalFiles, root.Scan.TotalLines, languages.String()))
+	}
+	builder.WriteString("\n")
+}
+
+func writeSystem(builder *strings.Builder, opts Options) {
+	if opts.System == nil || len(opts.System.Services) < 2 {
+		return
+	}
+
+	builder.WriteString("## " + opts.heading("System Architecture") + "\n")
+	builder.WriteString("| Service | Module | Frameworks | Endpoints | Calls | Called by |\n")
+	builder.WriteString("|---|---|---|---|---|---|\n")
+	for _, service := range opts.System.Services {
+		calls, calledBy := []string{}, []string{}
+		for _, link := range opts.System.Links {
+			if link.From == service.Name {
+				calls = appendMissing(calls, link.To)
+			}
+			if link.To == service.Name {
+				calledBy = appendMissing(calledBy, link.From)
+			}
+		}
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s | %d | %s | %s |\n",
+			service.Name, orDash(service.Module), orDash(strings.Join(service.Frameworks, ", ")),
+			service.Endpoints, orDash(strings.Join(calls, ", ")), orDash(strings.Join(calledBy, ", "))))
+	}
+	builder.WriteString("\n```mermaid\n")
+	builder.WriteString(opts.System.Mermaid())
+	builder.WriteString("```\n\n")
+
+	if len(opts.System.Links) == 0 {
+		builder.WriteString("No references between the repositories were found.\n\n")
+		return
+	}
+
+	builder.WriteString("### " + opts.heading("Cross-Repository References") + "\n")
+	builder.WriteString("| From | To | Kind | Evidence |\n")
+	builder.WriteString("|---|---|---|---|\n")
+	for _, link := range opts.System.Links {
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s | `%s` |\n", link.From, link.To, link.Kind, link.Evidence))
+	}
+	builder.WriteString("\n")
+}
+
+func appendMissing(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// roots returns the repositories covered by the report; a single-path report
+// has one unnamed root.
+func (opts Options) roots() []Root {
+	if len(opts.Roots) == 0 {
+		return []Root{{Path: opts.RepoPath}}
+	}
+	return opts.Roots
+}
+
+// existing returns the candidates that exist in any root, prefixed with the
+// root name when the report covers several repositories.
+func (opts Options) existing(candidates []string) []string {
+	found := []string{}
+	for _, root := range opts.roots() {
+		for _, candidate := range existingPaths(root.Path, candidates) {
+			if root.Name != "" {
+				candidate = root.Name + "/" + candidate
+			}
+			found = append(found, candidate)
+		}
+	}
+	return found
+}
+
+// locate maps a report-relative path to a file on disk.
+func (opts Options) locate(rel string) string {
+	for _, root := range opts.Roots {
+		if rest, ok := strings.CutPrefix(rel, root.Name+"/"); ok {
+			return filepath.Join(root.Path, filepath.FromSlash(rest))
+		}
+	}
+	return filepath.Join(opts.RepoPath, filepath.FromSlash(rel))
+}
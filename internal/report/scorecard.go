@@ -0,0 +1,234 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+)
+
+const (
+	statusPass = "✅"
+	statusWarn = "⚠️"
+	statusFail = "❌"
+)
+
+type scoreCheck struct {
+	Name    string
+	Status  string
+	Details string
+}
+
+var (
+	ciConfigs = []string{
+		".github/workflows", ".gitlab-ci.yml", "Jenkinsfile", ".circleci/config.yml",
+		"azure-pipelines.yml", "bitbucket-pipelines.yml", ".travis.yml", ".buildkite",
+	}
+	securityPolicies = []string{"SECURITY.md", ".github/SECURITY.md", "docs/SECURITY.md"}
+	dependencyBots   = []string{
+		".github/dependabot.yml", ".github/dependabot.yaml", "renovate.json", ".github/renovate.json",
+		"renovate.json5", ".renovaterc", ".renovaterc.json",
+	}
+
+	// lockFiles lists the lock files that satisfy each build tool type.
+	lockFiles = map[string][]string{
+		"npm":   {"package-lock.json", "yarn.lock", "pnpm-lock.yaml", "bun.lockb"},
+		"go":    {"go.sum"},
+		"pip":   {"poetry.lock", "Pipfile.lock", "uv.lock", "pdm.lock"},
+		"cargo": {"Cargo.lock"},
+	}
+)
+
+func writeScorecard(builder *strings.Builder, opts Options) {
+	checks := scorecard(opts)
+
+	builder.WriteString("## " + opts.heading("Scorecard") + "\n")
+	builder.WriteString("| Check | Status | Details |\n")
+	builder.WriteString("|---|---|---|\n")
+	for _, check := range checks {
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s |\n", check.Name, check.Status, check.Details))
+	}
+	builder.WriteString("\n")
+}
+
+func scorecard(opts Options) []scoreCheck {
+	return []scoreCheck{
+		testsCheck(opts),
+		ciCheck(opts),
+		docsCheck(opts),
+		lockfileCheck(opts),
+		securityCheck(opts),
+		containerCheck(opts),
+	}
+}
+
+func testsCheck(opts Options) scoreCheck {
+	check := scoreCheck{Name: "Tests"}
+
+	testFiles := 0
+	for _, module := range opts.DetectionResult.Testing.Modules {
+		testFiles += module.Files
+	}
+
+	switch {
+	case testFiles == 0:
+		check.Status = statusFail
+		check.Details = "no test files found"
+		return check
+	case opts.ScanResult.TotalFiles > 0 && float64(testFiles)/float64(opts.ScanResult.TotalFiles) < 0.1:
+		check.Status = statusWarn
+	default:
+		check.Status = statusPass
+	}
+
+	check.Details = fmt.Sprintf("%d test files", testFiles)
+	if len(opts.DetectionResult.Testing.Frameworks) > 0 {
+		names := []string{}
+		for _, fw := range opts.DetectionResult.Testing.Frameworks {
+			names = append(names, fw.Name)
+		}
+		check.Details += " (" + strings.Join(names, ", ") + ")"
+	}
+	return check
+}
+
+func ciCheck(opts Options) scoreCheck {
+	if found := opts.existing(ciConfigs); len(found) > 0 {
+		return scoreCheck{Name: "CI", Status: statusPass, Details: strings.Join(found, ", ")}
+	}
+	return scoreCheck{Name: "CI", Status: statusFail, Details: "no CI configuration found"}
+}
+
+func docsCheck(opts Options) scoreCheck {
+	readme := opts.existing([]string{"README.md", "README.rst", "README.txt", "README"})
+	extra := opts.existing([]string{"CONTRIBUTING.md", ".github/CONTRIBUTING.md", "docs"})
+
+	switch {
+	case len(readme) > 0 && len(extra) > 0:
+		return scoreCheck{Name: "Docs", Status: statusPass, Details: strings.Join(append(readme, extra...), ", ")}
+	case len(readme) > 0:
+		return scoreCheck{Name: "Docs", Status: statusWarn, Details: readme[0] + " only; no CONTRIBUTING or docs/"}
+	default:
+		return scoreCheck{Name: "Docs", Status: statusFail, Details: "no README"}
+	}
+}
+
+func lockfileCheck(opts Options) scoreCheck {
+	check := scoreCheck{Name: "Lockfiles"}
+
+	missing := []string{}
+	checked := 0
+	for _, tool := range opts.DetectionResult.BuildTools {
+		candidates, ok := lockFiles[tool.Type]
+		if !ok || !declaresDependencies(opts.locate(tool.File), tool) {
+			continue
+		}
+		checked++
+
+		dir := path.Dir(filepath.ToSlash(tool.File))
+		locked := false
+		for _, candidate := range candidates {
+			if _, err := os.Stat(opts.locate(path.Join(dir, candidate))); err == nil {
+				locked = true
+				break
+			}
+		}
+		if !locked {
+			missing = append(missing, tool.File)
+		}
+	}
+
+	switch {
+	case checked == 0:
+		check.Status = statusPass
+		check.Details = "no package manifests"
+	case len(missing) == 0:
+		check.Status = statusPass
+		check.Details = fmt.Sprintf("%d of %d manifests locked", checked, checked)
+	case len(missing) < checked:
+		check.Status = statusWarn
+		check.Details = "unlocked: " + strings.Join(missing, ", ")
+	default:
+		check.Status = statusFail
+		check.Details = "unlocked: " + strings.Join(missing, ", ")
+	}
+	return check
+}
+
+func securityCheck(opts Options) scoreCheck {
+	if secrets, _ := opts.secrets(); len(secrets) > 0 {
+		return scoreCheck{Name: "Security", Status: statusFail, Details: fmt.Sprintf("%d potential secrets committed", len(secrets))}
+	}
+
+	policy := opts.existing(securityPolicies)
+	bots := opts.existing(dependencyBots)
+	found := append(policy, bots...)
+
+	switch {
+	case len(policy) > 0 && len(bots) > 0:
+		return scoreCheck{Name: "Security", Status: statusPass, Details: strings.Join(found, ", ")}
+	case len(found) > 0:
+		missing := "dependency update bot"
+		if len(policy) == 0 {
+			missing = "SECURITY.md"
+		}
+		return scoreCheck{Name: "Security", Status: statusWarn, Details: strings.Join(found, ", ") + "; no " + missing}
+	default:
+		return scoreCheck{Name: "Security", Status: statusFail, Details: "no SECURITY.md or dependency update bot"}
+	}
+}
+
+func containerCheck(opts Options) scoreCheck {
+	images := 0
+	for _, artifact := range opts.DetectionResult.Artifacts {
+		if artifact.Kind == "image" {
+			images++
+		}
+	}
+	if images == 0 {
+		return scoreCheck{Name: "Container", Status: statusFail, Details: "no Dockerfile"}
+	}
+
+	high := 0
+	findings, _ := opts.findings()
+	for _, finding := range findings {
+		if finding.Severity == detect.SeverityHigh && detect.IsDockerfile(finding.File) {
+			high++
+		}
+	}
+	if high > 0 {
+		return scoreCheck{Name: "Container", Status: statusWarn, Details: fmt.Sprintf("%d high-severity Dockerfile findings", high)}
+	}
+	details := "1 image"
+	if images > 1 {
+		details = fmt.Sprintf("%d images", images)
+	}
+	return scoreCheck{Name: "Container", Status: statusPass, Details: details}
+}
+
+// declaresDependencies reports false for manifests that cannot produce a lock
+// file, such as a go.mod without require directives.
+func declaresDependencies(manifest string, tool detect.BuildTool) bool {
+	if tool.Type != "go" {
+		return true
+	}
+	content, err := os.ReadFile(manifest)
+	if err != nil {
+		return true
+	}
+	return strings.Contains(string(content), "require")
+}
+
+// existingPaths returns the candidates, relative to repoPath, that exist.
+func existingPaths(repoPath string, candidates []string) []string {
+	found := []string{}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(candidate))); err == nil {
+			found = append(found, candidate)
+		}
+	}
+	return found
+}
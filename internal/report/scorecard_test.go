@@ -0,0 +1,153 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// scorecardRepo writes files, relative slash paths to content, to a new
+// repository and returns report options for it.
+func scorecardRepo(t *testing.T, files map[string]string, detection detect.Result) Options {
+	t.Helper()
+	root := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return Options{RepoPath: root, ScanResult: &scanner.Result{TotalFiles: 20}, DetectionResult: &detection}
+}
+
+func TestScorecardChecks(t *testing.T) {
+	tests := []struct {
+		name      string
+		check     func(Options) scoreCheck
+		files     map[string]string
+		detection detect.Result
+		status    string
+		details   string
+	}{
+		{
+			name:  "tests pass",
+			check: testsCheck,
+			detection: detect.Result{Testing: detect.TestInventory{
+				Modules:    []detect.TestModule{{Path: "pkg", Files: 4}},
+				Frameworks: []detect.TestFramework{{Name: "go test"}},
+			}},
+			status:  statusPass,
+			details: "4 test files (go test)",
+		},
+		{
+			name:      "tests warn",
+			check:     testsCheck,
+			detection: detect.Result{Testing: detect.TestInventory{Modules: []detect.TestModule{{Path: "pkg", Files: 1}}}},
+			status:    statusWarn,
+			details:   "1 test files",
+		},
+		{name: "tests fail", check: testsCheck, status: statusFail, details: "no test files"},
+		{
+			name:    "ci pass",
+			check:   ciCheck,
+			files:   map[string]string{".github/workflows/ci.yml": "on: push\n"},
+			status:  statusPass,
+			details: ".github/workflows",
+		},
+		{name: "ci fail", check: ciCheck, status: statusFail, details: "no CI"},
+		{
+			name:    "docs pass",
+			check:   docsCheck,
+			files:   map[string]string{"README.md": "# r\n", "CONTRIBUTING.md": "PRs welcome\n"},
+			status:  statusPass,
+			details: "README.md, CONTRIBUTING.md",
+		},
+		{name: "docs warn", check: docsCheck, files: map[string]string{"README.md": "# r\n"}, status: statusWarn, details: "README.md only"},
+		{name: "docs fail", check: docsCheck, status: statusFail, details: "no README"},
+		{
+			name:      "lockfiles pass",
+			check:     lockfileCheck,
+			files:     map[string]string{"web/package.json": "{}", "web/yarn.lock": "", "go.mod": "module m\n"},
+			detection: detect.Result{BuildTools: []detect.BuildTool{{Type: "npm", File: "web/package.json"}, {Type: "go", File: "go.mod"}}},
+			status:    statusPass,
+			details:   "1 of 1 manifests locked",
+		},
+		{
+			name:  "lockfiles warn",
+			check: lockfileCheck,
+			files: map[string]string{"web/package.json": "{}", "web/yarn.lock": "", "api/package.json": "{}"},
+			detection: detect.Result{BuildTools: []detect.BuildTool{
+				{Type: "npm", File: "web/package.json"}, {Type: "npm", File: "api/package.json"},
+			}},
+			status:  statusWarn,
+			details: "unlocked: api/package.json",
+		},
+		{
+			name:      "lockfiles fail",
+			check:     lockfileCheck,
+			files:     map[string]string{"go.mod": "module m\n\nrequire example.com/x v1.0.0\n"},
+			detection: detect.Result{BuildTools: []detect.BuildTool{{Type: "go", File: "go.mod"}}},
+			status:    statusFail,
+			details:   "unlocked: go.mod",
+		},
+		{
+			name:    "security pass",
+			check:   securityCheck,
+			files:   map[string]string{"SECURITY.md": "Report to security@example.com\n", ".github/dependabot.yml": "version: 2\n"},
+			status:  statusPass,
+			details: "SECURITY.md, .github/dependabot.yml",
+		},
+		{
+			name:    "security warn",
+			check:   securityCheck,
+			files:   map[string]string{"renovate.json": "{}"},
+			status:  statusWarn,
+			details: "no SECURITY.md",
+		},
+		{
+			name:      "security fail on secrets",
+			check:     securityCheck,
+			files:     map[string]string{"SECURITY.md": "", "renovate.json": "{}"},
+			detection: detect.Result{Secrets: []detect.Secret{{Rule: "aws-key", File: "config.go", Line: 3}}},
+			status:    statusFail,
+			details:   "1 potential secrets",
+		},
+		{name: "security fail", check: securityCheck, status: statusFail, details: "no SECURITY.md or dependency update bot"},
+		{
+			name:      "container pass",
+			check:     containerCheck,
+			detection: detect.Result{Artifacts: []detect.Artifact{{Kind: "image"}, {Kind: "image"}, {Kind: "binary"}}},
+			status:    statusPass,
+			details:   "2 images",
+		},
+		{
+			name:  "container warn",
+			check: containerCheck,
+			detection: detect.Result{
+				Artifacts: []detect.Artifact{{Kind: "image"}},
+				Findings: []detect.Finding{
+					{Severity: detect.SeverityHigh, File: "build/Dockerfile.prod"},
+					{Severity: detect.SeverityHigh, File: "deploy/chart.yaml"},
+				},
+			},
+			status:  statusWarn,
+			details: "1 high-severity Dockerfile findings",
+		},
+		{name: "container fail", check: containerCheck, status: statusFail, details: "no Dockerfile"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.check(scorecardRepo(t, tt.files, tt.detection))
+			if got.Status != tt.status || !strings.Contains(got.Details, tt.details) {
+				t.Errorf("got %s %q, want %s containing %q", got.Status, got.Details, tt.status, tt.details)
+			}
+		})
+	}
+}
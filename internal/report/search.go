@@ -0,0 +1,130 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+)
+
+// SearchIndexFile is written next to the index of a split report.
+const SearchIndexFile = "search-index.json"
+
+// SearchRecord is one entry of a split report's search index. Records
+// follow Algolia's format, so the file can be uploaded as is, and load
+// directly into lunr or elasticlunr with objectID as the reference.
+type SearchRecord struct {
+	ObjectID string `json:"objectID"`
+	// Type is page, module, file, symbol, endpoint or model.
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Content string `json:"content,omitempty"`
+	Module  string `json:"module,omitempty"`
+	File    string `json:"file,omitempty"`
+}
+
+// searchRecords indexes the summaries and symbols of a split report. URLs
+// are relative to the index and point at the most specific page: a
+// symbol's file page when it has one, otherwise its module page.
+func searchRecords(opts Options, modules, files []string) []SearchRecord {
+	index := filepath.Base(opts.OutputFile)
+	topFiles := make(map[string]bool, len(files))
+	for _, path := range files {
+		topFiles[path] = true
+	}
+	pageOf := func(file string) string {
+		if topFiles[file] {
+			return filePage(file, opts.Format)
+		}
+		if module := moduleOf(file, modules); module != "" {
+			return modulePage(module, opts.Format)
+		}
+		return index
+	}
+
+	records := []SearchRecord{{
+		ObjectID: "page:" + index,
+		Type:     "page",
+		Title:    reportTitle(opts),
+		URL:      index,
+		Content:  opts.Summaries.ArchitectureSummary,
+	}}
+
+	for _, module := range modules {
+		records = append(records, SearchRecord{
+			ObjectID: "module:" + module,
+			Type:     "module",
+			Title:    "/" + module,
+			URL:      modulePage(module, opts.Format),
+			Content:  moduleSummary(opts, module),
+			Module:   module,
+		})
+	}
+
+	for _, path := range files {
+		summary := opts.Summaries.FileSummaries[path]
+		module := moduleOf(path, modules)
+		records = append(records, SearchRecord{
+			ObjectID: "file:" + path,
+			Type:     "file",
+			Title:    path,
+			URL:      filePage(path, opts.Format),
+			Content:  summary.Summary,
+			Module:   module,
+			File:     path,
+		})
+		for _, function := range summary.Functions {
+			records = append(records, SearchRecord{
+				ObjectID: "symbol:" + path + "#" + function,
+				Type:     "symbol",
+				Title:    function,
+				URL:      filePage(path, opts.Format),
+				Module:   module,
+				File:     path,
+			})
+		}
+	}
+
+	endpoints, _ := confident(opts.DetectionResult.Endpoints, func(e detect.Endpoint) detect.Confidence { return e.Confidence })
+	for _, endpoint := range endpoints {
+		title := endpoint.Method + " " + endpoint.Path
+		records = append(records, SearchRecord{
+			ObjectID: "endpoint:" + title,
+			Type:     "endpoint",
+			Title:    title,
+			URL:      pageOf(endpoint.File),
+			Content:  endpoint.Handler,
+			Module:   moduleOf(endpoint.File, modules),
+			File:     endpoint.File,
+		})
+	}
+
+	models, _ := confident(opts.DetectionResult.Models, func(m detect.Model) detect.Confidence { return m.Confidence })
+	for _, model := range models {
+		records = append(records, SearchRecord{
+			ObjectID: "model:" + model.File + "#" + model.Name,
+			Type:     "model",
+			Title:    model.Name,
+			URL:      pageOf(model.File),
+			Content:  strings.Join(model.Fields, " "),
+			Module:   moduleOf(model.File, modules),
+			File:     model.File,
+		})
+	}
+	return records
+}
+
+func writeSearchIndex(opts Options, modules, files []string) error {
+	data, err := json.MarshalIndent(searchRecords(opts, modules, files), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(opts.OutputFile), SearchIndexFile), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	return nil
+}
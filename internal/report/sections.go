@@ -0,0 +1,85 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SectionFunc renders a report section as Markdown. An empty result omits
+// the section.
+type SectionFunc func(ctx context.Context, opts Options) (string, error)
+
+var (
+	registryMu sync.RWMutex
+	registered []section
+)
+
+// RegisterSection adds a section, such as an on-call runbook or SLOs, to
+// every report. Registered sections follow the built-in ones in registration
+// order; templates can place them anywhere with {{section "name"}}.
+// RegisterSection panics if name is already taken, so it is meant to be
+// called from init functions.
+func RegisterSection(name string, fn SectionFunc) {
+	if name == "" || fn == nil {
+		panic("report: RegisterSection needs a name and a function")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, s := range builtinSections {
+		if s.name == name {
+			panic(fmt.Sprintf("report: section %q is built in", name))
+		}
+	}
+	for _, s := range registered {
+		if s.name == name {
+			panic(fmt.Sprintf("report: section %q registered twice", name))
+		}
+	}
+	registered = append(registered, section{name: name, render: fn})
+}
+
+// sections returns the built-in sections followed by the registered ones.
+func sections() []section {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append(append([]section{}, builtinSections...), registered...)
+}
+
+// SectionNames lists every section in its default order.
+func SectionNames() []string {
+	all := sections()
+	names := make([]string, len(all))
+	for i, s := range all {
+		names[i] = s.name
+	}
+	return names
+}
+
+func (s section) run(ctx context.Context, opts Options) (string, error) {
+	if s.write != nil {
+		var builder strings.Builder
+		s.write(&builder, opts)
+		return builder.String(), nil
+	}
+
+	content, err := s.render(ctx, opts)
+	if err != nil {
+		return "", fmt.Errorf("section %s: %w", s.name, err)
+	}
+	if content != "" && !strings.HasSuffix(content, "\n\n") {
+		content = strings.TrimRight(content, "\n") + "\n\n"
+	}
+	return content, nil
+}
+
+func renderBuiltin(ctx context.Context, opts Options, name string) (string, error) {
+	for _, s := range sections() {
+		if s.name == name {
+			return s.run(ctx, opts)
+		}
+	}
+	return "", fmt.Errorf("unknown report section %q", name)
+}
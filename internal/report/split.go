@@ -0,0 +1,174 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+const (
+	modulesDir = "modules"
+	filesDir   = "files"
+)
+
+// writePages writes the module and file pages of a split report, and its
+// search index. Pages live in two flat directories next to the index so
+// every link is one level deep.
+func writePages(ctx context.Context, opts Options) error {
+	dir := filepath.Dir(opts.OutputFile)
+	index := filepath.Base(opts.OutputFile)
+	modules := reportModules(opts)
+	files := reportFiles(opts)
+	ownership := moduleOwners(opts)
+
+	topFiles := make(map[string]bool, len(files))
+	for _, path := range files {
+		topFiles[path] = true
+	}
+
+	for _, module := range modules {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("# /%s\n\n", module))
+		builder.WriteString(fmt.Sprintf("[← Report](../%s)\n\n", index))
+		builder.WriteString(moduleSummary(opts, module) + "\n\n")
+		if owned, ok := ownership[module]; ok && len(owned.Owners)+len(owned.Contributors) > 0 {
+			if len(owned.Owners) > 0 {
+				builder.WriteString("**Owners:** " + strings.Join(owned.Owners, ", ") + "  \n")
+			}
+			if len(owned.Contributors) > 0 {
+				builder.WriteString("**Recent contributors:** " + strings.Join(owned.Contributors, ", ") + "  \n")
+			}
+			builder.WriteString("\n")
+		}
+
+		builder.WriteString("## " + opts.heading("Files") + "\n")
+		builder.WriteString("| File | Language | Lines |\n")
+		builder.WriteString("|---|---|---|\n")
+		for _, file := range opts.ScanResult.Files {
+			rel := filepath.ToSlash(file.RelativePath)
+			if moduleOf(rel, modules) != module {
+				continue
+			}
+			name := rel
+			if topFiles[rel] {
+				name = fmt.Sprintf("[%s](../%s)", rel, filePage(rel, opts.Format))
+			}
+			builder.WriteString(fmt.Sprintf("| %s | %s | %d |\n", name, file.Language, file.Lines))
+		}
+		builder.WriteString("\n")
+
+		writePageDetections(&builder, opts, func(file string) bool { return moduleOf(file, modules) == module })
+
+		if err := writePage(ctx, opts, filepath.Join(dir, modulePage(module, opts.Format)), "/"+module, builder.String()); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range files {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("# %s\n\n", path))
+		builder.WriteString(fmt.Sprintf("[← Report](../%s)", index))
+		if module := moduleOf(path, modules); module != "" {
+			builder.WriteString(fmt.Sprintf(" · Module [/%s](../%s)", module, modulePage(module, opts.Format)))
+		}
+		builder.WriteString("\n\n")
+		if file, ok := findFile(opts.ScanResult, path); ok {
+			builder.WriteString(fmt.Sprintf("%s, %d lines\n\n", file.Language, file.Lines))
+		}
+		writeFileSummary(&builder, opts, opts.Summaries.FileSummaries[path])
+
+		writePageDetections(&builder, opts, func(file string) bool { return file == path })
+
+		if err := writePage(ctx, opts, filepath.Join(dir, filePage(path, opts.Format)), path, builder.String()); err != nil {
+			return err
+		}
+	}
+
+	return writeSearchIndex(opts, modules, files)
+}
+
+// writePageDetections lists the endpoints and models defined in the files
+// a page covers.
+func writePageDetections(builder *strings.Builder, opts Options, covers func(file string) bool) {
+	endpoints, _ := confident(opts.DetectionResult.Endpoints, func(e detect.Endpoint) detect.Confidence { return e.Confidence })
+	rows := []string{}
+	for _, endpoint := range endpoints {
+		if covers(endpoint.File) {
+			rows = append(rows, fmt.Sprintf("| %s | %s | %s |\n", endpoint.Method, endpoint.Path, endpoint.Handler))
+		}
+	}
+	if len(rows) > 0 {
+		builder.WriteString("## " + opts.heading("Endpoints") + "\n")
+		builder.WriteString("| Method | Path | Handler |\n")
+		builder.WriteString("|---|---|---|\n")
+		builder.WriteString(strings.Join(rows, ""))
+		builder.WriteString("\n")
+	}
+
+	models, _ := confident(opts.DetectionResult.Models, func(m detect.Model) detect.Confidence { return m.Confidence })
+	rows = rows[:0]
+	for _, model := range models {
+		if covers(model.File) {
+			rows = append(rows, fmt.Sprintf("| %s | %s |\n", model.Name, strings.Join(model.Fields, ", ")))
+		}
+	}
+	if len(rows) > 0 {
+		builder.WriteString("## " + opts.heading("Models") + "\n")
+		builder.WriteString("| Model | Fields |\n")
+		builder.WriteString("|---|---|\n")
+		builder.WriteString(strings.Join(rows, ""))
+		builder.WriteString("\n")
+	}
+}
+
+func writePage(ctx context.Context, opts Options, path, title, markdown string) error {
+	content, err := Render(ctx, markdown, opts.Format, title, opts.Theme)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write report page: %w", err)
+	}
+	return nil
+}
+
+// modulePage and filePage return page paths relative to the index.
+func modulePage(module, format string) string {
+	return modulesDir + "/" + pageSlug(module) + FormatExtension(format)
+}
+
+func filePage(path, format string) string {
+	return filesDir + "/" + pageSlug(path) + FormatExtension(format)
+}
+
+func pageSlug(path string) string {
+	return strings.NewReplacer("/", "-", "\\", "-", " ", "-").Replace(strings.Trim(path, "/"))
+}
+
+// moduleOf returns the most specific module containing file.
+func moduleOf(file string, modules []string) string {
+	best := ""
+	for _, module := range modules {
+		if strings.HasPrefix(file, filepath.ToSlash(module)+"/") && len(module) > len(best) {
+			best = module
+		}
+	}
+	return best
+}
+
+func findFile(scan *scanner.Result, path string) (scanner.FileInfo, bool) {
+	for _, file := range scan.Files {
+		if filepath.ToSlash(file.RelativePath) == path {
+			return file, true
+		}
+	}
+	return scanner.FileInfo{}, false
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/sqlite"
+)
+
+// WriteSQLite writes the analysis to a SQLite database at path: the
+// repositories, files, symbols, endpoints, models, dependencies and
+// summaries. Every table has a repository column, and paths are relative to
+// their repository, so the tables of several runs can be combined.
+func WriteSQLite(opts Options, path string) error {
+	repositories := sqliteTable("repositories", "name TEXT", "path TEXT", "commit_sha TEXT", "generated_at TEXT", "model TEXT", "tool_version TEXT")
+	files := sqliteTable("files", "repository TEXT", "path TEXT", "module TEXT", "language TEXT", "lines INTEGER", "size INTEGER", "is_test INTEGER", "hash TEXT", "summary TEXT")
+	symbols := sqliteTable("symbols", "repository TEXT", "file TEXT", "name TEXT", "description TEXT")
+	endpoints := sqliteTable("endpoints", "repository TEXT", "method TEXT", "path TEXT", "handler TEXT", "file TEXT", "source TEXT", "confidence REAL", "summary TEXT")
+	models := sqliteTable("models", "repository TEXT", "name TEXT", "fields TEXT", "file TEXT", "confidence REAL")
+	dependencies := sqliteTable("dependencies", "repository TEXT", "name TEXT", "version TEXT", "ecosystem TEXT", "manifest TEXT", "direct INTEGER", "replaces TEXT")
+	summaries := sqliteTable("summaries", "repository TEXT", "kind TEXT", "subject TEXT", "summary TEXT")
+
+	p := opts.Provenance
+	name := repositoryName(opts)
+	if len(opts.Roots) == 0 {
+		repositories.Rows = append(repositories.Rows, []any{name, opts.RepoPath, p.CommitSHA, p.GeneratedAt, p.Model, p.ToolVersion})
+	}
+	for _, root := range opts.Roots {
+		commit := ""
+		if root.Scan != nil {
+			commit = root.Scan.RepoMetadata.LastCommit.Hash
+		}
+		repositories.Rows = append(repositories.Rows, []any{root.Name, root.Path, commit, p.GeneratedAt, p.Model, p.ToolVersion})
+	}
+
+	modules := reportModules(opts)
+	for _, file := range opts.ScanResult.Files {
+		path := filepath.ToSlash(file.RelativePath)
+		repository, relative := splitRoot(opts, name, path)
+		_, module := splitRoot(opts, name, moduleOf(path, modules))
+		summary := opts.Summaries.FileSummaries[file.RelativePath]
+		files.Rows = append(files.Rows, []any{
+			repository, relative, module, file.Language, file.Lines, file.Size, file.IsTest, file.Hash, nullIfEmpty(summary.Summary),
+		})
+		for _, function := range summary.Functions {
+			symbol, description, _ := strings.Cut(function, " — ")
+			symbols.Rows = append(symbols.Rows, []any{repository, relative, strings.TrimSpace(symbol), nullIfEmpty(strings.TrimSpace(description))})
+		}
+	}
+
+	for _, endpoint := range opts.DetectionResult.Endpoints {
+		repository, file := splitRoot(opts, name, endpoint.File)
+		endpoints.Rows = append(endpoints.Rows, []any{
+			repository, endpoint.Method, endpoint.Path, endpoint.Handler, file, nullIfEmpty(endpoint.Source), float64(endpoint.Confidence), nullIfEmpty(endpoint.Summary),
+		})
+	}
+	for _, model := range opts.DetectionResult.Models {
+		repository, file := splitRoot(opts, name, model.File)
+		models.Rows = append(models.Rows, []any{repository, model.Name, strings.Join(model.Fields, "; "), file, float64(model.Confidence)})
+	}
+	for _, dependency := range opts.Dependencies {
+		repository, file := splitRoot(opts, name, dependency.File)
+		dependencies.Rows = append(dependencies.Rows, []any{
+			repository, dependency.Name, dependency.Version, dependency.Ecosystem, file, !dependency.Indirect, nullIfEmpty(dependency.Replaces),
+		})
+	}
+
+	if opts.Summaries.ArchitectureSummary != "" {
+		summaries.Rows = append(summaries.Rows, []any{name, "architecture", nil, opts.Summaries.ArchitectureSummary})
+	}
+	for _, module := range slices.Sorted(maps.Keys(opts.Summaries.ModuleSummaries)) {
+		repository, subject := splitRoot(opts, name, module)
+		summaries.Rows = append(summaries.Rows, []any{repository, "module", subject, opts.Summaries.ModuleSummaries[module]})
+	}
+	for _, file := range slices.Sorted(maps.Keys(opts.Summaries.FileSummaries)) {
+		if summary := opts.Summaries.FileSummaries[file].Summary; summary != "" {
+			repository, subject := splitRoot(opts, name, filepath.ToSlash(file))
+			summaries.Rows = append(summaries.Rows, []any{repository, "file", subject, summary})
+		}
+	}
+	for _, file := range slices.Sorted(maps.Keys(opts.Summaries.ConfigSummaries)) {
+		repository, subject := splitRoot(opts, name, file)
+		sections := opts.Summaries.ConfigSummaries[file]
+		for _, section := range slices.Sorted(maps.Keys(sections)) {
+			summaries.Rows = append(summaries.Rows, []any{repository, "config", strings.TrimSuffix(subject+"#"+section, "#"), sections[section]})
+		}
+	}
+	if len(opts.Summaries.QuickstartSteps) > 0 {
+		summaries.Rows = append(summaries.Rows, []any{name, "quickstart", nil, strings.Join(opts.Summaries.QuickstartSteps, "\n")})
+	}
+
+	return sqlite.Write(path, []sqlite.Table{repositories, files, symbols, endpoints, models, dependencies, summaries})
+}
+
+// sqliteTable declares a table from "name TYPE" column definitions.
+func sqliteTable(name string, columns ...string) sqlite.Table {
+	table := sqlite.Table{Name: name}
+	for _, column := range columns {
+		columnName, columnType, _ := strings.Cut(column, " ")
+		table.Columns = append(table.Columns, sqlite.Column{Name: columnName, Type: columnType})
+	}
+	return table
+}
+
+func repositoryName(opts Options) string {
+	if opts.ScanResult.RepoMetadata.Name != "" {
+		return opts.ScanResult.RepoMetadata.Name
+	}
+	return filepath.Base(opts.RepoPath)
+}
+
+// splitRoot returns the repository of a path and the path within it. Paths
+// of several analyzed repositories start with the repository's name.
+func splitRoot(opts Options, name, path string) (string, string) {
+	for _, root := range opts.Roots {
+		if rest, ok := strings.CutPrefix(path, root.Name+"/"); ok {
+			return root.Name, rest
+		}
+	}
+	return name, path
+}
+
+func nullIfEmpty(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
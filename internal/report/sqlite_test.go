@@ -0,0 +1,98 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/deps"
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/sqlite"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+func TestWriteSQLite(t *testing.T) {
+	scan := &scanner.Result{RepoMetadata: scanner.RepoMetadata{Name: "shop"}}
+	summaries := &summarize.Result{
+		ArchitectureSummary: "A web shop.",
+		ModuleSummaries:     map[string]string{"api": "HTTP handlers."},
+		FileSummaries:       map[string]summarize.FileSummary{},
+	}
+	// Enough files to fill several leaf pages under an interior page.
+	for i := 0; i < 300; i++ {
+		path := fmt.Sprintf("api/handler%03d.go", i)
+		scan.Files = append(scan.Files, scanner.FileInfo{RelativePath: path, Language: "Go", Lines: i, Size: int64(i * 40), Hash: "h"})
+	}
+	// A summary larger than a page continues on overflow pages.
+	long := strings.Repeat("Handles checkout and payment retries. ", 500)
+	scan.Files = append(scan.Files, scanner.FileInfo{RelativePath: "api/checkout.go", Language: "Go", Lines: 900})
+	summaries.FileSummaries["api/checkout.go"] = summarize.FileSummary{
+		Summary:   long,
+		Functions: []string{"Checkout — charges the cart", "retry"},
+	}
+	opts := Options{
+		RepoPath:   "/src/shop",
+		ScanResult: scan,
+		DetectionResult: &detect.Result{
+			Endpoints: []detect.Endpoint{{Method: "POST", Path: "/checkout", Handler: "Checkout", File: "api/checkout.go", Confidence: detect.ConfidenceHigh}},
+			Models:    []detect.Model{{Name: "Order", Fields: []string{"id", "total"}, File: "api/order.go", Confidence: detect.ConfidenceMedium}},
+		},
+		Summaries:    summaries,
+		Dependencies: []deps.Dependency{{Name: "github.com/stripe/stripe-go", Version: "v76.0.0", Ecosystem: "go", File: "go.mod"}},
+		Provenance:   Provenance{CommitSHA: "abc123", Model: "claude", ToolVersion: "1.0"},
+	}
+
+	path := filepath.Join(t.TempDir(), "analysis.db")
+	if err := WriteSQLite(opts, path); err != nil {
+		t.Fatal(err)
+	}
+	tables, err := sqlite.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int{}
+	rows := map[string][][]any{}
+	for _, table := range tables {
+		counts[table.Name] = len(table.Rows)
+		rows[table.Name] = table.Rows
+	}
+	wantCounts := map[string]int{
+		"repositories": 1,
+		"files":        301,
+		"symbols":      2,
+		"endpoints":    1,
+		"models":       1,
+		"dependencies": 1,
+		"summaries":    3,
+	}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Fatalf("Row counts = %v, want %v", counts, wantCounts)
+	}
+
+	checks := []struct {
+		table string
+		row   int
+		want  []any
+	}{
+		{"repositories", 0, []any{"shop", "/src/shop", "abc123", "", "claude", "1.0"}},
+		{"files", 0, []any{"shop", "api/handler000.go", "api", "Go", int64(0), int64(0), int64(0), "h", nil}},
+		{"files", 300, []any{"shop", "api/checkout.go", "api", "Go", int64(900), int64(0), int64(0), "", long}},
+		{"symbols", 0, []any{"shop", "api/checkout.go", "Checkout", "charges the cart"}},
+		{"symbols", 1, []any{"shop", "api/checkout.go", "retry", nil}},
+		{"endpoints", 0, []any{"shop", "POST", "/checkout", "Checkout", "api/checkout.go", nil, 0.9, nil}},
+		{"models", 0, []any{"shop", "Order", "id; total", "api/order.go", 0.6}},
+		{"dependencies", 0, []any{"shop", "github.com/stripe/stripe-go", "v76.0.0", "go", "go.mod", int64(1), nil}},
+		{"summaries", 0, []any{"shop", "architecture", nil, "A web shop."}},
+		{"summaries", 1, []any{"shop", "module", "api", "HTTP handlers."}},
+		{"summaries", 2, []any{"shop", "file", "api/checkout.go", long}},
+	}
+	for _, check := range checks {
+		if got := rows[check.table][check.row]; !reflect.DeepEqual(got, check.want) {
+			t.Errorf("%s row %d = %.200v, want %.200v", check.table, check.row+1, got, check.want)
+		}
+	}
+}
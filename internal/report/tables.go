@@ -0,0 +1,176 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+)
+
+const (
+	TableFormatCSV = "csv"
+	TableFormatTSV = "tsv"
+)
+
+// table is one exported file: a header row and the data rows.
+type table struct {
+	name   string
+	header []string
+	rows   func(Options) [][]string
+}
+
+var exportedTables = []table{
+	{
+		name:   "endpoints",
+		header: []string{"method", "path", "handler", "file", "source", "confidence", "summary"},
+		rows:   endpointRows,
+	},
+	{
+		name:   "models",
+		header: []string{"name", "fields", "file", "confidence"},
+		rows:   modelRows,
+	},
+	{
+		name:   "modules",
+		header: []string{"module", "files", "lines", "summary", "owners", "recent_contributors"},
+		rows:   moduleRows,
+	},
+	{
+		name:   "dependencies",
+		header: []string{"name", "version", "ecosystem", "file", "direct", "replaces"},
+		rows:   dependencyRows,
+	},
+	{
+		name:   "risks",
+		header: []string{"kind", "severity", "rule", "file", "line", "message", "acknowledged", "reason"},
+		rows:   riskRows,
+	},
+}
+
+// WriteTables writes the tabular sections of the report to dir as CSV or
+// TSV files, one per table, and returns their paths. Every table is written,
+// with just its header when it has no rows, so imports keep working.
+func WriteTables(opts Options, dir, format string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create tables directory: %w", err)
+	}
+
+	paths := []string{}
+	for _, t := range exportedTables {
+		path := filepath.Join(dir, t.name+"."+format)
+		if err := writeTable(path, format, append([][]string{t.header}, t.rows(opts)...)); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeTable(path, format string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write table: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if format == TableFormatTSV {
+		writer.Comma = '\t'
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write table %s: %w", filepath.Base(path), err)
+	}
+	return file.Close()
+}
+
+func endpointRows(opts Options) [][]string {
+	rows := [][]string{}
+	for _, endpoint := range opts.DetectionResult.Endpoints {
+		rows = append(rows, []string{
+			endpoint.Method, endpoint.Path, endpoint.Handler, endpoint.File, endpoint.Source,
+			formatConfidence(endpoint.Confidence), endpoint.Summary,
+		})
+	}
+	return rows
+}
+
+func modelRows(opts Options) [][]string {
+	rows := [][]string{}
+	for _, model := range opts.DetectionResult.Models {
+		rows = append(rows, []string{model.Name, strings.Join(model.Fields, "; "), model.File, formatConfidence(model.Confidence)})
+	}
+	return rows
+}
+
+func moduleRows(opts Options) [][]string {
+	modules := reportModules(opts)
+	files := map[string]int{}
+	lines := map[string]int{}
+	for _, file := range opts.ScanResult.Files {
+		if module := moduleOf(filepath.ToSlash(file.RelativePath), modules); module != "" {
+			files[module]++
+			lines[module] += file.Lines
+		}
+	}
+
+	ownership := moduleOwners(opts)
+	rows := [][]string{}
+	for _, module := range modules {
+		rows = append(rows, []string{
+			module, strconv.Itoa(files[module]), strconv.Itoa(lines[module]), moduleSummary(opts, module),
+			strings.Join(ownership[module].Owners, "; "), strings.Join(ownership[module].Contributors, "; "),
+		})
+	}
+	return rows
+}
+
+func dependencyRows(opts Options) [][]string {
+	rows := [][]string{}
+	for _, dependency := range opts.Dependencies {
+		rows = append(rows, []string{
+			dependency.Name, dependency.Version, dependency.Ecosystem, dependency.File,
+			strconv.FormatBool(!dependency.Indirect), dependency.Replaces,
+		})
+	}
+	return rows
+}
+
+// riskRows lists the heuristic risks, findings and potential secrets,
+// including those the baseline acknowledges.
+func riskRows(opts Options) [][]string {
+	rows := [][]string{}
+	for _, r := range identifyRisks(opts) {
+		entry, acknowledged := matchRisk(opts.Baseline, r)
+		rows = append(rows, []string{"risk", r.Severity, r.Rule, r.File, "", r.Message, strconv.FormatBool(acknowledged), entry.Reason})
+	}
+	for _, finding := range opts.DetectionResult.Findings {
+		entry, acknowledged := opts.Baseline.Match(finding.Rule, finding.File)
+		rows = append(rows, []string{
+			"finding", finding.Severity, finding.Rule, finding.File, lineNumber(finding.Line), finding.Message,
+			strconv.FormatBool(acknowledged), entry.Reason,
+		})
+	}
+	for _, secret := range opts.DetectionResult.Secrets {
+		entry, acknowledged := opts.Baseline.Match(secret.Rule, secret.File)
+		rows = append(rows, []string{
+			"secret", detect.SeverityHigh, secret.Rule, secret.File, lineNumber(secret.Line), "potential secret",
+			strconv.FormatBool(acknowledged), entry.Reason,
+		})
+	}
+	return rows
+}
+
+func formatConfidence(confidence detect.Confidence) string {
+	return strconv.FormatFloat(float64(confidence), 'f', 1, 64)
+}
+
+func lineNumber(line int) string {
+	if line == 0 {
+		return ""
+	}
+	return strconv.Itoa(line)
+}
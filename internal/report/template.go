@@ -0,0 +1,157 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/codepigeon/codedoc/internal/depmap"
+	"github.com/codepigeon/codedoc/internal/deps"
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/glossary"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/history"
+	"github.com/codepigeon/codedoc/internal/risk"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+// layoutTemplate, when present in a template directory, replaces the whole
+// report. Other templates are named after the section they override, or
+// start with "_" for shared definitions.
+const layoutTemplate = "report"
+
+// Templates are user-provided Go text/templates loaded from a directory.
+type Templates struct {
+	tmpl *template.Template
+}
+
+// TemplateData is what templates receive as dot.
+type TemplateData struct {
+	Title        string
+	RepoPath     string
+	Scan         *scanner.Result
+	Detection    *detect.Result
+	Summaries    *summarize.Result
+	InternalDeps *depmap.Result
+	Dependencies []deps.Dependency
+	History      *history.Result
+	Seams        []graph.Seam
+	// ModuleOwners maps each module to its CODEOWNERS owners and recent
+	// contributors.
+	ModuleOwners map[string]ModuleOwners
+	Glossary     []glossary.Term
+	Provenance   Provenance
+	// Risks are the rule risks not acknowledged in the baseline, most
+	// severe first.
+	Risks []risk.Risk
+}
+
+// LoadTemplates parses every *.tmpl file in dir.
+func LoadTemplates(dir string) (*Templates, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.tmpl files in %s", dir)
+	}
+	sort.Strings(paths)
+
+	known := map[string]bool{layoutTemplate: true}
+	for _, name := range SectionNames() {
+		known[name] = true
+	}
+
+	root := template.New("codedoc").Funcs(templateFuncs(context.Background(), nil, Options{}))
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+		if !known[name] && !strings.HasPrefix(name, "_") {
+			return nil, fmt.Errorf("template %s does not match a report section; known sections: %s", filepath.Base(path), strings.Join(SectionNames(), ", "))
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := root.New(name).Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+	}
+	return &Templates{tmpl: root}, nil
+}
+
+func (t *Templates) execute(ctx context.Context, opts Options) (string, error) {
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(templateFuncs(ctx, tmpl, opts))
+	data := templateData(opts)
+
+	var builder strings.Builder
+	if tmpl.Lookup(layoutTemplate) != nil {
+		if err := tmpl.ExecuteTemplate(&builder, layoutTemplate, data); err != nil {
+			return "", fmt.Errorf("failed to execute template: %w", err)
+		}
+		return builder.String(), nil
+	}
+
+	for _, name := range SectionNames() {
+		content, err := renderSection(ctx, tmpl, opts, name)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(content)
+	}
+	return builder.String(), nil
+}
+
+// templateFuncs gives templates access to the built-in sections: section
+// honours overrides in the template directory, builtin never does, so an
+// override can wrap the section it replaces.
+func templateFuncs(ctx context.Context, tmpl *template.Template, opts Options) template.FuncMap {
+	return template.FuncMap{
+		"section": func(name string) (string, error) {
+			return renderSection(ctx, tmpl, opts, name)
+		},
+		"builtin": func(name string) (string, error) {
+			return renderBuiltin(ctx, opts, name)
+		},
+		"join": strings.Join,
+	}
+}
+
+func templateData(opts Options) TemplateData {
+	risks, _ := opts.risks()
+	return TemplateData{
+		Title:        reportTitle(opts),
+		RepoPath:     opts.RepoPath,
+		Scan:         opts.ScanResult,
+		Detection:    opts.DetectionResult,
+		Summaries:    opts.Summaries,
+		InternalDeps: opts.InternalDeps,
+		Dependencies: opts.Dependencies,
+		History:      opts.History,
+		Seams:        opts.Seams,
+		ModuleOwners: moduleOwners(opts),
+		Glossary:     opts.Glossary,
+		Provenance:   opts.Provenance,
+		Risks:        risks,
+	}
+}
+
+func renderSection(ctx context.Context, tmpl *template.Template, opts Options, name string) (string, error) {
+	override := tmpl.Lookup(name)
+	if override == nil {
+		return renderBuiltin(ctx, opts, name)
+	}
+	var builder strings.Builder
+	if err := override.Execute(&builder, templateData(opts)); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return builder.String(), nil
+}
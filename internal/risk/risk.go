@@ -0,0 +1,412 @@
+// Package risk evaluates repository-wide risks with rules: built-in
+// heuristics, which codedoc.yaml can disable or re-rank, plus custom rules
+// matching files by path, import or size.
+package risk
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// Risk is a rule match. File is set for rules about a single file.
+type Risk struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+}
+
+// String returns the message, so templates can print a risk as is.
+func (r Risk) String() string {
+	return r.Message
+}
+
+// Input is what rules are evaluated against.
+type Input struct {
+	Scan      *scanner.Result
+	Detection *detect.Result
+	// Graph is the import graph; rules that need it are skipped when nil.
+	Graph *graph.Graph
+}
+
+// Rule is a built-in heuristic or a compiled custom rule.
+type Rule struct {
+	ID          string
+	Severity    string
+	Description string
+	check       func(in Input) []Risk
+}
+
+// Rules are evaluated in order; Evaluate ranks their risks by severity.
+type Rules []Rule
+
+// Config is the risks section of codedoc.yaml.
+type Config struct {
+	// Disable lists built-in rule IDs to skip.
+	Disable []string `yaml:"disable"`
+	// Severity overrides the severity of built-in rules by ID.
+	Severity map[string]string `yaml:"severity"`
+	Rules    []CustomRule      `yaml:"rules"`
+}
+
+// CustomRule flags every scanned file matching all of its matchers.
+type CustomRule struct {
+	ID       string `yaml:"id"`
+	Severity string `yaml:"severity"`
+	Message  string `yaml:"message"`
+	// Paths are slash-separated globs, such as internal/** or *.sql.
+	Paths []string `yaml:"paths"`
+	// Imports are packages, matching their subpackages too.
+	Imports  []string `yaml:"imports"`
+	MinLines int      `yaml:"min_lines"`
+}
+
+var severities = []string{detect.SeverityHigh, detect.SeverityMedium, detect.SeverityLow}
+
+// Builtin returns the built-in rules.
+func Builtin() Rules {
+	return Rules{
+		{ID: "no-tests", Severity: detect.SeverityHigh, Description: "No test files", check: checkNoTests},
+		{ID: "low-test-ratio", Severity: detect.SeverityMedium, Description: "Fewer than 10% test files", check: checkTestRatio},
+		{ID: "no-ci", Severity: detect.SeverityMedium, Description: "No CI/CD configuration", check: checkCI},
+		{ID: "missing-lock-file", Severity: detect.SeverityMedium, Description: "Build tools without a dependency lock file", check: checkLockFile},
+		{ID: "large-codebase", Severity: detect.SeverityLow, Description: "More than 1000 files", check: checkCodebaseSize},
+		{ID: "large-file", Severity: detect.SeverityLow, Description: "A file over 1000 lines", check: checkLargeFile},
+		{ID: "missing-readme", Severity: detect.SeverityLow, Description: "No README.md or CONTRIBUTING.md", check: checkReadme},
+		{ID: "many-frameworks", Severity: detect.SeverityLow, Description: "More than 3 frameworks", check: checkFrameworks},
+		{ID: "orphan-code", Severity: detect.SeverityLow, Description: "Code no entrypoint imports", check: checkOrphans},
+	}
+}
+
+// Validate reports the first unknown rule ID, invalid severity or invalid
+// custom rule.
+func (c Config) Validate() error {
+	_, err := Compile(c)
+	return err
+}
+
+// Compile applies c to the built-in rules and appends its custom rules.
+func Compile(c Config) (Rules, error) {
+	rules := Builtin()
+	known := make(map[string]bool)
+	for _, rule := range rules {
+		known[rule.ID] = true
+	}
+
+	for _, id := range c.Disable {
+		if !known[id] {
+			return nil, fmt.Errorf("risks.disable: unknown rule %q", id)
+		}
+	}
+	for id, severity := range c.Severity {
+		if !known[id] {
+			return nil, fmt.Errorf("risks.severity: unknown rule %q", id)
+		}
+		if !slices.Contains(severities, severity) {
+			return nil, fmt.Errorf("risks.severity.%s: severity must be one of %s", id, strings.Join(severities, ", "))
+		}
+	}
+
+	compiled := Rules{}
+	for _, rule := range rules {
+		if slices.Contains(c.Disable, rule.ID) {
+			continue
+		}
+		if severity, ok := c.Severity[rule.ID]; ok {
+			rule.Severity = severity
+		}
+		compiled = append(compiled, rule)
+	}
+
+	for i, custom := range c.Rules {
+		if known[custom.ID] {
+			return nil, fmt.Errorf("risks.rules[%d]: rule %q is already defined", i, custom.ID)
+		}
+		rule, err := custom.compile()
+		if err != nil {
+			return nil, fmt.Errorf("risks.rules[%d]: %w", i, err)
+		}
+		known[custom.ID] = true
+		compiled = append(compiled, rule)
+	}
+	return compiled, nil
+}
+
+func (c CustomRule) compile() (Rule, error) {
+	if c.ID == "" {
+		return Rule{}, fmt.Errorf("id is required")
+	}
+	if !slices.Contains(severities, c.Severity) {
+		return Rule{}, fmt.Errorf("severity must be one of %s", strings.Join(severities, ", "))
+	}
+	if len(c.Paths) == 0 && len(c.Imports) == 0 && c.MinLines <= 0 {
+		return Rule{}, fmt.Errorf("rule %s needs paths, imports or min_lines", c.ID)
+	}
+
+	paths := []*regexp.Regexp{}
+	for _, pattern := range c.Paths {
+		re, err := scanner.CompileGlob(pattern)
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid path %q: %w", pattern, err)
+		}
+		paths = append(paths, re)
+	}
+
+	message := c.Message
+	if message == "" {
+		message = "matches rule " + c.ID
+	}
+	rule := Rule{ID: c.ID, Severity: c.Severity, Description: message}
+	rule.check = func(in Input) []Risk {
+		risks := []Risk{}
+		for _, file := range in.Scan.Files {
+			rel := filepath.ToSlash(file.RelativePath)
+			if c.matches(file, rel, paths) {
+				risks = append(risks, Risk{Message: message, File: rel})
+			}
+		}
+		return risks
+	}
+	return rule, nil
+}
+
+func (c CustomRule) matches(file scanner.FileInfo, rel string, paths []*regexp.Regexp) bool {
+	if len(paths) > 0 && !slices.ContainsFunc(paths, func(re *regexp.Regexp) bool { return re.MatchString(rel) }) {
+		return false
+	}
+	if len(c.Imports) > 0 && !slices.ContainsFunc(file.Imports, func(imp string) bool {
+		return slices.ContainsFunc(c.Imports, func(pkg string) bool { return isWithin(imp, pkg) })
+	}) {
+		return false
+	}
+	return file.Lines >= c.MinLines
+}
+
+func isWithin(imp, pkg string) bool {
+	return imp == pkg || strings.HasPrefix(imp, pkg+"/") || strings.HasPrefix(imp, pkg+".")
+}
+
+// Evaluate runs the rules and returns their risks, most severe first and
+// otherwise in rule order.
+func (r Rules) Evaluate(in Input) []Risk {
+	risks := []Risk{}
+	for _, rule := range r {
+		for _, risk := range rule.check(in) {
+			risk.Rule, risk.Severity = rule.ID, rule.Severity
+			risks = append(risks, risk)
+		}
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		return Rank(risks[i].Severity) < Rank(risks[j].Severity)
+	})
+	return risks
+}
+
+// Rank orders severities from high (0) to low.
+func Rank(severity string) int {
+	if i := slices.Index(severities, severity); i >= 0 {
+		return i
+	}
+	return len(severities)
+}
+
+func one(message string) []Risk {
+	return []Risk{{Message: message}}
+}
+
+// testFiles counts test files in the scan or, since tests are only scanned
+// with --include-tests, in the tree-wide inventory, whichever is larger.
+func testFiles(in Input) (count, inventory int) {
+	for _, file := range in.Scan.Files {
+		if file.IsTest {
+			count++
+		}
+	}
+	for _, module := range in.Detection.Testing.Modules {
+		inventory += module.Files
+	}
+	return max(count, inventory), inventory
+}
+
+func checkNoTests(in Input) []Risk {
+	if _, inventory := testFiles(in); inventory > 0 {
+		return nil
+	}
+	for _, file := range in.Scan.Files {
+		if strings.Contains(filepath.Base(file.RelativePath), "test") {
+			return nil
+		}
+	}
+	return one("No test files detected")
+}
+
+func checkTestRatio(in Input) []Risk {
+	count, _ := testFiles(in)
+	if float64(count)/float64(in.Scan.TotalFiles) < 0.1 {
+		return one("Low test coverage (less than 10% test files)")
+	}
+	return nil
+}
+
+func checkCI(in Input) []Risk {
+	if len(in.Detection.Pipelines) > 0 {
+		return nil
+	}
+	for _, file := range in.Scan.Files {
+		base := filepath.Base(file.RelativePath)
+		if strings.Contains(file.RelativePath, ".github/workflows") || base == ".gitlab-ci.yml" || base == "Jenkinsfile" {
+			return nil
+		}
+	}
+	return one("No CI/CD configuration detected")
+}
+
+var lockFiles = []string{"package-lock.json", "go.sum", "Gemfile.lock", "yarn.lock", "poetry.lock", "Cargo.lock", "gradle.lockfile"}
+
+func checkLockFile(in Input) []Risk {
+	if len(in.Detection.BuildTools) == 0 {
+		return nil
+	}
+	for _, file := range in.Scan.Files {
+		if slices.Contains(lockFiles, filepath.Base(file.RelativePath)) {
+			return nil
+		}
+	}
+	return one("Missing dependency lock file")
+}
+
+func checkCodebaseSize(in Input) []Risk {
+	if in.Scan.TotalFiles > 1000 {
+		return one(fmt.Sprintf("Large codebase with %d files may benefit from modularization", in.Scan.TotalFiles))
+	}
+	return nil
+}
+
+func checkLargeFile(in Input) []Risk {
+	for _, file := range in.Scan.Files {
+		if file.Lines > 1000 {
+			return []Risk{{
+				Message: fmt.Sprintf("Large file: %s (%d lines) - consider splitting", file.RelativePath, file.Lines),
+				File:    filepath.ToSlash(file.RelativePath),
+			}}
+		}
+	}
+	return nil
+}
+
+func checkReadme(in Input) []Risk {
+	for _, file := range in.Scan.Files {
+		if base := filepath.Base(file.RelativePath); base == "README.md" || base == "CONTRIBUTING.md" {
+			return nil
+		}
+	}
+	return one("Missing README.md documentation")
+}
+
+func checkFrameworks(in Input) []Risk {
+	// Only count frameworks confident enough for the report to list them.
+	frameworks := 0
+	for _, framework := range in.Detection.Frameworks {
+		if framework.Confidence >= detect.ConfidenceMedium {
+			frameworks++
+		}
+	}
+	if frameworks > 3 {
+		return one(fmt.Sprintf("Multiple frameworks detected (%d) - consider consolidation", frameworks))
+	}
+	return nil
+}
+
+// graphLanguages are the languages whose imports graph.Build resolves.
+var graphLanguages = map[string]bool{"go": true, "python": true, "javascript": true, "typescript": true}
+
+// checkOrphans flags code that no entrypoint reaches through imports: whole
+// directories when none of their files is reached, otherwise single files.
+// A language is only judged when it has an entrypoint and at least one
+// resolved import, so an import style the graph cannot follow, such as path
+// aliases, does not flag all of its files.
+func checkOrphans(in Input) []Risk {
+	if in.Graph == nil {
+		return nil
+	}
+
+	language := map[string]string{}
+	resolved := map[string]bool{}
+	for _, file := range in.Scan.Files {
+		rel := filepath.ToSlash(file.RelativePath)
+		if !graphLanguages[file.Language] || file.IsTest || (file.Language == "go" && !strings.HasSuffix(rel, ".go")) {
+			continue
+		}
+		language[rel] = file.Language
+		if len(in.Graph.Imports(rel)) > 0 {
+			resolved[file.Language] = true
+		}
+	}
+	roots := []string{}
+	judged := map[string]bool{}
+	for _, entrypoint := range in.Detection.Entrypoints {
+		rel := filepath.ToSlash(entrypoint.Path)
+		if lang := language[rel]; lang != "" && resolved[lang] {
+			roots = append(roots, rel)
+			judged[lang] = true
+		}
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+
+	// Files that tools and tests load by path, rather than import, are
+	// never candidates.
+	loadedByTools := func(rel string) bool {
+		base := path.Base(rel)
+		if path.Dir(rel) == "." || base == "__init__.py" || strings.Contains(base, ".config.") || strings.HasSuffix(base, ".d.ts") {
+			return true
+		}
+		return slices.ContainsFunc(strings.Split(rel, "/"), func(dir string) bool { return dir == "testdata" || dir == "fixtures" })
+	}
+	candidates := map[string][]string{}
+	for _, rel := range in.Graph.Unreachable(roots) {
+		if judged[language[rel]] && !loadedByTools(rel) {
+			candidates[path.Dir(rel)] = append(candidates[path.Dir(rel)], rel)
+		}
+	}
+	sources := map[string]int{}
+	for rel, lang := range language {
+		if judged[lang] && !loadedByTools(rel) {
+			sources[path.Dir(rel)]++
+		}
+	}
+
+	dirs := make([]string, 0, len(candidates))
+	for dir := range candidates {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	risks := []Risk{}
+	for _, dir := range dirs {
+		files := candidates[dir]
+		if len(files) == sources[dir] {
+			risks = append(risks, Risk{
+				Message: fmt.Sprintf("Orphaned module: %s (%d files) is not imported from any entrypoint; candidate for deletion", dir, len(files)),
+				File:    dir,
+			})
+			continue
+		}
+		for _, rel := range files {
+			risks = append(risks, Risk{
+				Message: fmt.Sprintf("Orphaned file: %s is not imported from any entrypoint; candidate for deletion", rel),
+				File:    rel,
+			})
+		}
+	}
+	return risks
+}
@@ -0,0 +1,114 @@
+package risk
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestEvaluate(t *testing.T) {
+	in := Input{
+		Scan: &scanner.Result{
+			TotalFiles: 4,
+			Files: []scanner.FileInfo{
+				{RelativePath: "internal/db/legacy.go", Lines: 1200, Imports: []string{"github.com/acme/olddb/driver", "fmt"}},
+				{RelativePath: "internal/api/server.go", Lines: 80, Imports: []string{"github.com/acme/olddb"}},
+				{RelativePath: "cmd/tool/main.go", Lines: 40, Imports: []string{"github.com/acme/olddbx"}},
+				{RelativePath: "README.md", Lines: 10},
+			},
+		},
+		Detection: &detect.Result{BuildTools: []detect.BuildTool{{Type: "go", File: "go.mod"}}},
+	}
+
+	rules, err := Compile(Config{
+		Disable:  []string{"large-file"},
+		Severity: map[string]string{"missing-lock-file": detect.SeverityLow},
+		Rules: []CustomRule{
+			{ID: "legacy-db", Severity: detect.SeverityHigh, Message: "uses the legacy database driver",
+				Paths: []string{"internal/**"}, Imports: []string{"github.com/acme/olddb"}},
+			{ID: "huge-file", Severity: detect.SeverityMedium, MinLines: 1000},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Risk{
+		{Rule: "no-tests", Severity: detect.SeverityHigh, Message: "No test files detected"},
+		{Rule: "legacy-db", Severity: detect.SeverityHigh, Message: "uses the legacy database driver", File: "internal/db/legacy.go"},
+		{Rule: "legacy-db", Severity: detect.SeverityHigh, Message: "uses the legacy database driver", File: "internal/api/server.go"},
+		{Rule: "low-test-ratio", Severity: detect.SeverityMedium, Message: "Low test coverage (less than 10% test files)"},
+		{Rule: "no-ci", Severity: detect.SeverityMedium, Message: "No CI/CD configuration detected"},
+		{Rule: "huge-file", Severity: detect.SeverityMedium, Message: "matches rule huge-file", File: "internal/db/legacy.go"},
+		{Rule: "missing-lock-file", Severity: detect.SeverityLow, Message: "Missing dependency lock file"},
+	}
+	if got := rules.Evaluate(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("Evaluate() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	files := []scanner.FileInfo{
+		{RelativePath: "cmd/app/main.go", Language: "go", Imports: []string{"example.com/app/internal/store"}},
+		{RelativePath: "internal/store/store.go", Language: "go"},
+		{RelativePath: "internal/legacy/a.go", Language: "go", Imports: []string{"example.com/app/internal/store"}},
+		{RelativePath: "internal/legacy/b.go", Language: "go"},
+		{RelativePath: "internal/legacy/go.mod", Language: "go"},
+		{RelativePath: "internal/store/store_test.go", Language: "go", IsTest: true},
+		{RelativePath: "testdata/sample.go", Language: "go"},
+		{RelativePath: "web/index.js", Language: "javascript", Imports: []string{"./api"}},
+		{RelativePath: "web/api.js", Language: "javascript"},
+		{RelativePath: "web/old.js", Language: "javascript"},
+		{RelativePath: "web/vite.config.js", Language: "javascript"},
+		// Python has no entrypoint, so it is not judged.
+		{RelativePath: "jobs/sync.py", Language: "python", Imports: []string{"jobs.db"}},
+		{RelativePath: "jobs/db.py", Language: "python"},
+	}
+	in := Input{
+		Scan: &scanner.Result{Files: files},
+		Detection: &detect.Result{Entrypoints: []detect.Entrypoint{
+			{Type: "go-binary", Path: "cmd/app/main.go"},
+			{Type: "node-script", Path: "web/index.js"},
+			{Type: "docker", Path: "Dockerfile"},
+		}},
+		Graph: graph.Build(files, "example.com/app"),
+	}
+
+	want := []Risk{
+		{Message: "Orphaned module: internal/legacy (2 files) is not imported from any entrypoint; candidate for deletion", File: "internal/legacy"},
+		{Message: "Orphaned file: web/old.js is not imported from any entrypoint; candidate for deletion", File: "web/old.js"},
+	}
+	if got := checkOrphans(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("checkOrphans() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	in.Graph = nil
+	if got := checkOrphans(in); got != nil {
+		t.Errorf("Expected no orphans without an import graph, got %+v", got)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"unknown disable", Config{Disable: []string{"no-docs"}}, `risks.disable: unknown rule "no-docs"`},
+		{"unknown severity rule", Config{Severity: map[string]string{"no-docs": "high"}}, `risks.severity: unknown rule "no-docs"`},
+		{"bad severity", Config{Severity: map[string]string{"no-ci": "critical"}}, "risks.severity.no-ci: severity must be one of high, medium, low"},
+		{"missing id", Config{Rules: []CustomRule{{Severity: "low", MinLines: 10}}}, "risks.rules[0]: id is required"},
+		{"no matcher", Config{Rules: []CustomRule{{ID: "x", Severity: "low"}}}, "rule x needs paths, imports or min_lines"},
+		{"builtin id", Config{Rules: []CustomRule{{ID: "no-ci", Severity: "low", MinLines: 10}}}, `rule "no-ci" is already defined`},
+		{"bad custom severity", Config{Rules: []CustomRule{{ID: "x", MinLines: 10}}}, "risks.rules[0]: severity must be one of"},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.config); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Compile() = %v, want error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
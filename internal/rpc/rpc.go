@@ -0,0 +1,307 @@
+// Package rpc serves JSON-RPC 2.0 over a byte stream framed the way the
+// Language Server Protocol frames it: every message is a JSON body preceded
+// by a Content-Length header.
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Error codes from the JSON-RPC and Language Server Protocol specifications.
+const (
+	CodeParseError       = -32700
+	CodeInvalidRequest   = -32600
+	CodeMethodNotFound   = -32601
+	CodeInvalidParams    = -32602
+	CodeInternalError    = -32603
+	CodeRequestCancelled = -32800
+)
+
+// Error is a JSON-RPC error. Handlers return one to choose the code sent to
+// the client; any other error is reported as an internal error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func Errorf(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Handler answers one method. The context is cancelled when the client
+// sends $/cancelRequest for the call or the server stops.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Decode unmarshals params into v, reporting bad params to the client as
+// such. Missing params leave v unchanged.
+func Decode(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return Errorf(CodeInvalidParams, "invalid params: %v", err)
+	}
+	return nil
+}
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Server dispatches requests to handlers. Requests run concurrently, so
+// handlers must be safe to call from several goroutines.
+type Server struct {
+	handlers map[string]Handler
+
+	writeMu sync.Mutex
+	out     io.Writer
+
+	pendingMu sync.Mutex
+	pending   map[string]context.CancelFunc
+}
+
+func NewServer() *Server {
+	return &Server{
+		handlers: map[string]Handler{},
+		pending:  map[string]context.CancelFunc{},
+	}
+}
+
+// Handle registers handler for method. It must not be called once Serve
+// has started.
+func (s *Server) Handle(method string, handler Handler) {
+	s.handlers[method] = handler
+}
+
+// Serve reads messages from in and writes responses to out until in is
+// exhausted, the client sends the exit notification or ctx is done. On
+// end of input it waits for the requests in flight; otherwise it cancels
+// them first.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	type read struct {
+		body []byte
+		err  error
+	}
+	messages := make(chan read)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			body, err := readMessage(reader)
+			select {
+			case messages <- read{body, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var next read
+		select {
+		case <-ctx.Done():
+			return nil
+		case next = <-messages:
+		}
+		if errors.Is(next.err, io.EOF) {
+			return nil
+		}
+		if next.err != nil {
+			// Without a valid frame there is no telling where the next
+			// message starts.
+			return next.err
+		}
+
+		var msg message
+		if err := json.Unmarshal(next.body, &msg); err != nil {
+			s.reply(nil, nil, Errorf(CodeParseError, "parse error: %v", err))
+			continue
+		}
+		if msg.JSONRPC != "2.0" || msg.Method == "" {
+			s.reply(msg.ID, nil, Errorf(CodeInvalidRequest, "invalid request: want a jsonrpc 2.0 method call"))
+			continue
+		}
+
+		switch msg.Method {
+		case "exit":
+			cancel()
+			return nil
+		case "$/cancelRequest":
+			var params struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if err := Decode(msg.Params, &params); err == nil {
+				s.cancel(params.ID)
+			}
+			continue
+		}
+
+		// Requests are tracked before they start, so a cancellation that
+		// follows at once still finds them.
+		requestCtx, cancelRequest := context.WithCancel(ctx)
+		key := idKey(msg.ID)
+		if msg.ID != nil {
+			s.pendingMu.Lock()
+			s.pending[key] = cancelRequest
+			s.pendingMu.Unlock()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer cancelRequest()
+			s.handle(requestCtx, msg)
+			if msg.ID != nil {
+				s.pendingMu.Lock()
+				delete(s.pending, key)
+				s.pendingMu.Unlock()
+			}
+		}()
+	}
+}
+
+func (s *Server) handle(ctx context.Context, msg message) {
+	handler, ok := s.handlers[msg.Method]
+	if !ok {
+		// Notifications the server does not know are ignored.
+		if msg.ID != nil {
+			s.reply(msg.ID, nil, Errorf(CodeMethodNotFound, "method not found: %s", msg.Method))
+		}
+		return
+	}
+
+	result, err := call(ctx, handler, msg.Params)
+	if msg.ID == nil {
+		if err != nil {
+			slog.WarnContext(ctx, "notification failed", "method", msg.Method, "err", err)
+		}
+		return
+	}
+	var rpcErr *Error
+	if err != nil && ctx.Err() != nil && !errors.As(err, &rpcErr) {
+		err = Errorf(CodeRequestCancelled, "request cancelled")
+	}
+	s.reply(msg.ID, result, err)
+}
+
+func call(ctx context.Context, handler Handler, params json.RawMessage) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, params)
+}
+
+func (s *Server) cancel(id json.RawMessage) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	if cancel, ok := s.pending[idKey(id)]; ok {
+		cancel()
+	}
+}
+
+func (s *Server) reply(id json.RawMessage, result any, err error) {
+	response := message{JSONRPC: "2.0", ID: id}
+	if id == nil {
+		response.ID = json.RawMessage("null")
+	}
+	if err == nil {
+		response.Result, err = json.Marshal(result)
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		response.Result = nil
+		response.Error = rpcErr
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		slog.Error("failed to encode response", "err", err)
+		return
+	}
+	if err := s.write(body); err != nil {
+		slog.Error("failed to write response", "err", err)
+	}
+}
+
+// Notify sends the client a notification, such as $/progress for a
+// request still running. Handlers may call it while Serve runs.
+func (s *Server) Notify(method string, params any) error {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(message{JSONRPC: "2.0", Method: method, Params: encoded})
+	if err != nil {
+		return err
+	}
+	return s.write(body)
+}
+
+func (s *Server) write(body []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err := fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// idKey identifies a request by its ID whatever the spacing around it.
+func idKey(id json.RawMessage) string {
+	return string(bytes.TrimSpace(id))
+}
+
+// readMessage reads one framed message body.
+func readMessage(reader *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+	value := header.Get("Content-Length")
+	if value == "" {
+		return nil, fmt.Errorf("message header has no Content-Length")
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", value)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
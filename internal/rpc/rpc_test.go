@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func frame(messages ...string) string {
+	var b strings.Builder
+	for _, message := range messages {
+		fmt.Fprintf(&b, "Content-Length: %d\r\n\r\n%s", len(message), message)
+	}
+	return b.String()
+}
+
+func TestServe(t *testing.T) {
+	server := NewServer()
+	server.Handle("echo", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Text string `json:"text"`
+		}
+		if err := Decode(params, &p); err != nil {
+			return nil, err
+		}
+		return p, nil
+	})
+	server.Handle("fail", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, errors.New("scan failed")
+	})
+	server.Handle("panic", func(ctx context.Context, params json.RawMessage) (any, error) {
+		panic("boom")
+	})
+	server.Handle("wait", func(ctx context.Context, params json.RawMessage) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	in := frame(
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hi"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"echo","params":[1]}`,
+		`{"jsonrpc":"2.0","id":3,"method":"missing"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"fail"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"panic"}`,
+		`{"jsonrpc":"2.0","method":"echo"}`,
+		`{"jsonrpc":"2.0","id":"w","method":"wait"}`,
+		`{"jsonrpc":"2.0","method":"$/cancelRequest","params":{"id":"w"}}`,
+		`{not json`,
+	)
+	var out bytes.Buffer
+	if err := server.Serve(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]message{}
+	reader := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(reader)
+		if err != nil {
+			break
+		}
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		got[string(msg.ID)] = msg
+	}
+
+	if len(got) != 7 {
+		t.Errorf("got %d responses, want 7 (none for the notification)", len(got))
+	}
+	if result := string(got["1"].Result); result != `{"text":"hi"}` {
+		t.Errorf("echo result = %s", result)
+	}
+	wantCodes := map[string]int{
+		"2":    CodeInvalidParams,
+		"3":    CodeMethodNotFound,
+		"4":    CodeInternalError,
+		"5":    CodeInternalError,
+		`"w"`:  CodeRequestCancelled,
+		"null": CodeParseError,
+	}
+	for id, code := range wantCodes {
+		if response := got[id]; response.Error == nil || response.Error.Code != code {
+			t.Errorf("response %s error = %+v, want code %d", id, response.Error, code)
+		}
+	}
+}
+
+func TestServeBadFrame(t *testing.T) {
+	err := NewServer().Serve(context.Background(), strings.NewReader("Content-Type: json\r\n\r\n{}"), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "Content-Length") {
+		t.Errorf("Serve() error = %v, want a missing Content-Length", err)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	server := NewServer()
+	server.Handle("stream", func(ctx context.Context, params json.RawMessage) (any, error) {
+		if err := server.Notify("$/progress", map[string]any{"token": "t", "value": "partial"}); err != nil {
+			return nil, err
+		}
+		return "done", nil
+	})
+
+	var out bytes.Buffer
+	in := frame(`{"jsonrpc":"2.0","id":1,"method":"stream"}`)
+	if err := server.Serve(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(&out)
+	want := []string{
+		`{"jsonrpc":"2.0","method":"$/progress","params":{"token":"t","value":"partial"}}`,
+		`{"jsonrpc":"2.0","id":1,"result":"done"}`,
+	}
+	for _, w := range want {
+		body, err := readMessage(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != w {
+			t.Errorf("message = %s, want %s", body, w)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Census lists every file Scan could keep, ignoring MaxFiles and Languages,
+// without analyzing them.
+type Census struct {
+	Files []CensusFile
+}
+
+type CensusFile struct {
+	RelativePath string
+	Language     string
+	Lines        int
+	Size         int64
+	IsTest       bool
+}
+
+// TakeCensus walks opts.Path like Scan, counting the lines of each file but
+// leaving out imports, hashes and complexity.
+func TakeCensus(ctx context.Context, opts Options) (*Census, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	census := &Census{Files: []CensusFile{}}
+	tests := NewTestMatcher(opts.Path, opts.Tests)
+
+	err := filepath.WalkDir(opts.Path, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			slog.Debug("skipping unreadable path", "path", path, "err", err)
+			return nil
+		}
+		if d.IsDir() {
+			if shouldIgnoreDir(path, opts.Path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if shouldIgnoreFile(path, opts) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			slog.Debug("skipping file", "path", path, "err", err)
+			return nil
+		}
+		rel, _ := filepath.Rel(opts.Path, path)
+		census.Files = append(census.Files, CensusFile{
+			RelativePath: filepath.ToSlash(rel),
+			Language:     detectLanguage(path),
+			Lines:        countLines(content),
+			Size:         int64(len(content)),
+			IsTest:       tests.Match(rel),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return census, nil
+}
+
+// Selected returns the files a Scan with these settings would keep,
+// ignoring MaxFiles.
+func (c *Census) Selected(languages []string, includeTests bool) []CensusFile {
+	selected := []CensusFile{}
+	for _, file := range c.Files {
+		if (includeTests || !file.IsTest) && isLanguageSupported(file.Language, languages) {
+			selected = append(selected, file)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,219 @@
+package scanner
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// Complexity measures how hard a file's code is to follow.
+type Complexity struct {
+	Functions int
+	// MaxFunctionLines is the length of the longest function.
+	MaxFunctionLines int
+	// Cyclomatic is the file's total: one per function plus one per branch.
+	// MaxCyclomatic is that of its most complex function.
+	Cyclomatic    int
+	MaxCyclomatic int
+	// Approximate is set when the metrics come from matching keywords rather
+	// than parsing, as for every language but Go.
+	Approximate bool
+}
+
+func (c *Complexity) addFunction(lines, cyclomatic int) {
+	c.Functions++
+	c.MaxFunctionLines = max(c.MaxFunctionLines, lines)
+	c.MaxCyclomatic = max(c.MaxCyclomatic, cyclomatic)
+}
+
+var (
+	jsFunction = regexp.MustCompile(`\bfunction\b|=>|^\s*(?:(?:public|private|protected|static|async|override|get|set)\s+)*(\w+)\s*(?:<[^>]*>)?\s*\([^)]*\)\s*(?::\s*[^{]+)?\{`)
+	cFunction  = regexp.MustCompile(`^\s*(?:[\w<>\[\]*&:,.?]+\s+)+[*&]*(\w+)\s*\([^;{]*\)?\s*(?:const\s*)?(?:throws\s+[\w.,\s]+)?\{?\s*$`)
+
+	// functionPatterns match the line a function starts on. A captured name
+	// that is a control keyword, as in "else if (x) {", is not a function.
+	functionPatterns = map[string]*regexp.Regexp{
+		"python":     regexp.MustCompile(`^\s*(?:async\s+)?def\s+\w+`),
+		"ruby":       regexp.MustCompile(`^\s*def\s+`),
+		"javascript": jsFunction,
+		"typescript": jsFunction,
+		"php":        regexp.MustCompile(`\bfunction\b`),
+		"rust":       regexp.MustCompile(`\bfn\s+\w+`),
+		"swift":      regexp.MustCompile(`\bfunc\s+\w+`),
+		"kotlin":     regexp.MustCompile(`\bfun\s+`),
+		"scala":      regexp.MustCompile(`\bdef\s+\w+`),
+		"java":       cFunction,
+		"csharp":     cFunction,
+		"c":          cFunction,
+		"cpp":        cFunction,
+	}
+
+	controlKeywords = map[string]bool{
+		"if": true, "for": true, "foreach": true, "while": true, "switch": true,
+		"catch": true, "return": true, "else": true, "new": true, "sizeof": true,
+	}
+
+	branchPattern       = regexp.MustCompile(`\b(?:if|elif|elsif|for|foreach|while|until|unless|case|when|catch|except|rescue)\b|&&|\|\|`)
+	wordOperatorPattern = regexp.MustCompile(`\b(?:and|or)\b`)
+)
+
+func measureComplexity(content []byte, language string) Complexity {
+	if language == "go" {
+		return goComplexity(content)
+	}
+	if pattern, ok := functionPatterns[language]; ok {
+		return approximateComplexity(string(content), language, pattern)
+	}
+	return Complexity{}
+}
+
+// goComplexity counts the branches of each function declaration, including
+// those of the closures inside it.
+func goComplexity(content []byte) Complexity {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return Complexity{}
+	}
+
+	c := Complexity{}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		cyclomatic := 1
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+				cyclomatic++
+			case *ast.CaseClause:
+				if n.List != nil {
+					cyclomatic++
+				}
+			case *ast.CommClause:
+				if n.Comm != nil {
+					cyclomatic++
+				}
+			case *ast.BinaryExpr:
+				if n.Op == token.LAND || n.Op == token.LOR {
+					cyclomatic++
+				}
+			}
+			return true
+		})
+		c.addFunction(fset.Position(fn.End()).Line-fset.Position(fn.Pos()).Line+1, cyclomatic)
+		c.Cyclomatic += cyclomatic
+	}
+	return c
+}
+
+// approximateComplexity finds functions by pattern and their ends by
+// indentation (Python, Ruby) or braces, and counts branch keywords. Nested
+// functions count towards their enclosing one too, but only once towards
+// the file.
+func approximateComplexity(content, language string, pattern *regexp.Regexp) Complexity {
+	lines := strings.Split(content, "\n")
+	indented := language == "python" || language == "ruby"
+
+	branches := make([]int, len(lines))
+	for i, line := range lines {
+		if isCommentLine(line) {
+			continue
+		}
+		branches[i] = len(branchPattern.FindAllStringIndex(line, -1))
+		if indented {
+			branches[i] += len(wordOperatorPattern.FindAllStringIndex(line, -1))
+		}
+	}
+
+	c := Complexity{Approximate: true}
+	for i, line := range lines {
+		if isCommentLine(line) {
+			continue
+		}
+		match := pattern.FindStringSubmatch(line)
+		if match == nil || (len(match) > 1 && controlKeywords[match[1]]) {
+			continue
+		}
+		end := braceEnd(lines, i)
+		if indented {
+			end = indentEnd(lines, i)
+		}
+		cyclomatic := 1
+		for _, n := range branches[i : end+1] {
+			cyclomatic += n
+		}
+		c.addFunction(end-i+1, cyclomatic)
+		c.Cyclomatic++
+	}
+	for _, n := range branches {
+		c.Cyclomatic += n
+	}
+	return c
+}
+
+func isCommentLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, token := range []string{"//", "#", "/*", "*"} {
+		if strings.HasPrefix(trimmed, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// braceEnd returns the line closing the braces opened from start on. A
+// signature without a brace by its last line is a one-line function.
+func braceEnd(lines []string, start int) int {
+	depth, opened := 0, false
+	for i := start; i < len(lines); i++ {
+		for _, r := range lines[i] {
+			switch r {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			}
+		}
+		if opened && depth <= 0 {
+			return i
+		}
+		if !opened {
+			trimmed := strings.TrimSpace(lines[i])
+			next := ""
+			if i+1 < len(lines) {
+				next = strings.TrimSpace(lines[i+1])
+			}
+			continued := strings.HasSuffix(trimmed, "(") || strings.HasSuffix(trimmed, ",") ||
+				strings.HasPrefix(next, "{") || strings.HasPrefix(next, ")")
+			if !continued {
+				return i
+			}
+		}
+	}
+	return len(lines) - 1
+}
+
+// indentEnd returns the last line indented deeper than start.
+func indentEnd(lines []string, start int) int {
+	indent := indentation(lines[start])
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if indentation(lines[i]) <= indent {
+			break
+		}
+		end = i
+	}
+	return end
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
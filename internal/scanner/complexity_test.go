@@ -0,0 +1,98 @@
+package scanner
+
+import "testing"
+
+func TestMeasureComplexity(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		content  string
+		want     Complexity
+	}{
+		{
+			name:     "go",
+			language: "go",
+			content: `package orders
+
+func total(items []int, discount bool) int {
+	sum := 0
+	for _, item := range items {
+		if item > 0 && discount {
+			sum += item
+		}
+	}
+	switch {
+	case sum > 100:
+		return sum - 10
+	default:
+		return sum
+	}
+}
+
+func noop() {}
+`,
+			want: Complexity{Functions: 2, MaxFunctionLines: 14, Cyclomatic: 6, MaxCyclomatic: 5},
+		},
+		{
+			name:     "python",
+			language: "python",
+			content: `import os
+
+def load(path):
+    # if the file is missing, use defaults
+    if not os.path.exists(path) or path == "":
+        return {}
+    for line in open(path):
+        pass
+
+    return {}
+
+def name():
+    return "orders"
+`,
+			want: Complexity{Functions: 2, MaxFunctionLines: 8, Cyclomatic: 5, MaxCyclomatic: 4, Approximate: true},
+		},
+		{
+			name:     "javascript",
+			language: "javascript",
+			content: `function handle(req, res) {
+  if (req.user && req.user.admin) {
+    return res.send(items.map(item => item.id));
+  } else if (req.user) {
+    return res.send([]);
+  }
+}
+`,
+			want: Complexity{Functions: 2, MaxFunctionLines: 7, Cyclomatic: 5, MaxCyclomatic: 4, Approximate: true},
+		},
+		{
+			name:     "java",
+			language: "java",
+			content: `public class Orders {
+    public int total(List<Integer> items)
+    {
+        int sum = 0;
+        for (int item : items) {
+            sum += item;
+        }
+        return sum;
+    }
+}
+`,
+			want: Complexity{Functions: 1, MaxFunctionLines: 8, Cyclomatic: 2, MaxCyclomatic: 2, Approximate: true},
+		},
+		{
+			name:     "unmeasured",
+			language: "markdown",
+			content:  "# if for while\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := measureComplexity([]byte(tt.content), tt.language); got != tt.want {
+				t.Errorf("measureComplexity() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
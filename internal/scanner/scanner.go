@@ -2,11 +2,21 @@ package scanner
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/codepigeon/codedoc/internal/progress"
+	"github.com/codepigeon/codedoc/internal/util"
 )
 
 type Options struct {
@@ -14,6 +24,10 @@ type Options struct {
 	MaxFiles     int
 	IncludeTests bool
 	Languages    []string
+	// Tests overrides which files count as tests.
+	Tests TestRules
+	// Progress, when set, is advanced once per accepted file.
+	Progress *progress.Reporter
 }
 
 type Result struct {
@@ -21,7 +35,10 @@ type Result struct {
 	TotalFiles    int
 	TotalLines    int
 	LanguageStats map[string]LanguageStat
-	RepoMetadata  RepoMetadata
+	// CodeLanguageStats leaves out documentation and configuration formats
+	// (see IsCodeLanguage); its percentages are of the code lines only.
+	CodeLanguageStats map[string]LanguageStat
+	RepoMetadata      RepoMetadata
 }
 
 type FileInfo struct {
@@ -33,6 +50,7 @@ type FileInfo struct {
 	IsTest       bool
 	Imports      []string
 	Hash         string
+	Complexity   Complexity
 }
 
 type LanguageStat struct {
@@ -61,24 +79,33 @@ var defaultIgnorePatterns = []string{
 	"dist",
 	"build",
 	".codedoc-cache",
+	".codedoc",
 	"*.min.js",
 	"*.min.css",
 }
 
+// Scan walks opts.Path and analyzes the files it keeps. When ctx is cancelled
+// it stops at the next file and returns what it found so far with ctx.Err().
 func Scan(ctx context.Context, opts Options) (*Result, error) {
 	if opts.Path == "" {
 		return nil, fmt.Errorf("path is required")
 	}
 
 	result := &Result{
-		Files:         []FileInfo{},
-		LanguageStats: make(map[string]LanguageStat),
+		Files:             []FileInfo{},
+		LanguageStats:     make(map[string]LanguageStat),
+		CodeLanguageStats: make(map[string]LanguageStat),
 	}
 
 	result.RepoMetadata = getRepoMetadata(opts.Path)
+	tests := NewTestMatcher(opts.Path, opts.Tests)
 
 	err := filepath.WalkDir(opts.Path, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if err != nil {
+			slog.Debug("skipping unreadable path", "path", path, "err", err)
 			return nil
 		}
 
@@ -94,11 +121,13 @@ func Scan(ctx context.Context, opts Options) (*Result, error) {
 		}
 
 		if len(result.Files) >= opts.MaxFiles {
+			slog.Warn("max files limit reached, remaining files skipped", "max_files", opts.MaxFiles)
 			return fmt.Errorf("reached max files limit")
 		}
 
-		fileInfo, err := processFile(path, opts.Path)
+		fileInfo, err := processFile(path, opts.Path, tests)
 		if err != nil {
+			slog.Debug("skipping file", "path", path, "err", err)
 			return nil
 		}
 
@@ -113,18 +142,92 @@ func Scan(ctx context.Context, opts Options) (*Result, error) {
 		result.Files = append(result.Files, *fileInfo)
 		updateLanguageStats(result, fileInfo)
 		result.TotalLines += fileInfo.Lines
+		opts.Progress.Advance(fileInfo.RelativePath)
 
 		return nil
 	})
 
-	if err != nil && !strings.Contains(err.Error(), "reached max files limit") {
+	if err != nil && ctx.Err() == nil && !strings.Contains(err.Error(), "reached max files limit") {
 		return nil, err
 	}
 
 	result.TotalFiles = len(result.Files)
 	calculateLanguagePercentages(result)
 
-	return result, nil
+	return result, ctx.Err()
+}
+
+// ScanFile analyzes the file at rel, relative to opts.Path, regardless of
+// the filters and limits in opts.
+func ScanFile(opts Options, rel string) (*FileInfo, error) {
+	return processFile(filepath.Join(opts.Path, filepath.FromSlash(rel)), opts.Path, NewTestMatcher(opts.Path, opts.Tests))
+}
+
+// Subset returns a copy of result restricted to the files keep accepts, with
+// totals and language statistics recomputed.
+func Subset(result *Result, keep func(FileInfo) bool) *Result {
+	subset := &Result{
+		Files:             []FileInfo{},
+		LanguageStats:     make(map[string]LanguageStat),
+		CodeLanguageStats: make(map[string]LanguageStat),
+		RepoMetadata:      result.RepoMetadata,
+	}
+
+	for i := range result.Files {
+		file := result.Files[i]
+		if !keep(file) {
+			continue
+		}
+		subset.Files = append(subset.Files, file)
+		updateLanguageStats(subset, &file)
+		subset.TotalLines += file.Lines
+	}
+
+	subset.TotalFiles = len(subset.Files)
+	calculateLanguagePercentages(subset)
+
+	return subset
+}
+
+// Merge combines the results of scanning several repositories into one.
+// Relative paths are prefixed with the matching entry of roots so files stay
+// attributed to their repository; absolute paths are kept.
+func Merge(roots []string, results []*Result) *Result {
+	merged := &Result{
+		Files:             []FileInfo{},
+		LanguageStats:     make(map[string]LanguageStat),
+		CodeLanguageStats: make(map[string]LanguageStat),
+		RepoMetadata: RepoMetadata{
+			Name: strings.Join(roots, " + "),
+		},
+	}
+
+	for i, result := range results {
+		for _, file := range result.Files {
+			file.RelativePath = filepath.ToSlash(filepath.Join(roots[i], file.RelativePath))
+			merged.Files = append(merged.Files, file)
+			updateLanguageStats(merged, &file)
+			merged.TotalLines += file.Lines
+		}
+	}
+
+	merged.TotalFiles = len(merged.Files)
+	calculateLanguagePercentages(merged)
+
+	return merged
+}
+
+// Ignored reports whether Scan skips the file at the slash path rel, such
+// as one under vendor or node_modules, by its path alone.
+func Ignored(rel string) bool {
+	for _, part := range strings.Split(rel, "/") {
+		for _, pattern := range defaultIgnorePatterns {
+			if matched, _ := filepath.Match(pattern, part); matched {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func shouldIgnoreDir(path, basePath string) bool {
@@ -169,7 +272,7 @@ func shouldIgnoreFile(path string, opts Options) bool {
 	return false
 }
 
-func processFile(path, basePath string) (*FileInfo, error) {
+func processFile(path, basePath string, tests *TestMatcher) (*FileInfo, error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
@@ -181,16 +284,18 @@ func processFile(path, basePath string) (*FileInfo, error) {
 	}
 
 	rel, _ := filepath.Rel(basePath, path)
+	language := detectLanguage(path)
 
 	fileInfo := &FileInfo{
 		Path:         path,
 		RelativePath: rel,
 		Size:         info.Size(),
 		Lines:        countLines(content),
-		Language:     detectLanguage(path),
-		IsTest:       isTestFile(path),
-		Imports:      extractImports(content, detectLanguage(path)),
-		Hash:         hashFile(path, info),
+		Language:     language,
+		IsTest:       tests.Match(rel),
+		Imports:      extractImports(content, language),
+		Hash:         hashContent(content),
+		Complexity:   measureComplexity(content, language),
 	}
 
 	return fileInfo, nil
@@ -233,6 +338,7 @@ func detectLanguage(path string) string {
 		".cs":         "csharp",
 		".swift":      "swift",
 		".kt":         "kotlin",
+		".kts":        "kotlin",
 		".scala":      "scala",
 		".r":          "r",
 		".m":          "objc",
@@ -280,8 +386,13 @@ func detectLanguage(path string) string {
 		".graphql":    "graphql",
 		".vue":        "vue",
 		".svelte":     "svelte",
+		".tf":         "terraform",
+		".tfvars":     "terraform",
+		".hcl":        "hcl",
 	}
 
+	// With the .dockerfile extension above, the names detect.IsDockerfile
+	// matches; detect imports this package, so it cannot be called here.
 	if base == "dockerfile" || strings.HasPrefix(base, "dockerfile.") {
 		return "dockerfile"
 	}
@@ -306,6 +417,9 @@ func detectLanguage(path string) string {
 	if base == "requirements.txt" || base == "setup.py" || base == "pipfile" {
 		return "python"
 	}
+	if base == "gemfile" || base == "rakefile" || ext == ".rake" || ext == ".gemspec" {
+		return "ruby"
+	}
 
 	if lang, ok := languageMap[ext]; ok {
 		return lang
@@ -314,44 +428,53 @@ func detectLanguage(path string) string {
 	return "unknown"
 }
 
-func isTestFile(path string) bool {
-	base := filepath.Base(path)
-	lower := strings.ToLower(base)
-
-	testPatterns := []string{
-		"_test.go",
-		"_test.py",
-		"_test.js",
-		"_test.ts",
-		".test.js",
-		".test.ts",
-		".spec.js",
-		".spec.ts",
-		"test_",
-		"tests.",
-		"spec.",
-	}
-
-	for _, pattern := range testPatterns {
-		if strings.Contains(lower, pattern) {
-			return true
+var (
+	pythonImportPattern = regexp.MustCompile(`(?m)^\s*(?:from\s+([\w.]+)\s+import|import\s+([\w.]+(?:\s*,\s*[\w.]+)*))`)
+	jsImportPattern     = regexp.MustCompile(`(?:import\s+(?:[\w*{}\s,]+\s+from\s+)?|require\(\s*|import\(\s*)["']([^"']+)["']`)
+)
+
+func extractImports(content []byte, language string) []string {
+	imports := []string{}
+
+	switch language {
+	case "go":
+		file, err := parser.ParseFile(token.NewFileSet(), "", content, parser.ImportsOnly)
+		if err != nil {
+			return imports
+		}
+		for _, spec := range file.Imports {
+			if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+				imports = append(imports, path)
+			}
 		}
-	}
 
-	if strings.Contains(path, "/test/") || strings.Contains(path, "/tests/") ||
-		strings.Contains(path, "/__tests__/") || strings.Contains(path, "/spec/") {
-		return true
-	}
+	case "python":
+		for _, match := range pythonImportPattern.FindAllStringSubmatch(string(content), -1) {
+			if match[1] != "" {
+				imports = append(imports, match[1])
+				continue
+			}
+			for _, name := range strings.Split(match[2], ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					imports = append(imports, name)
+				}
+			}
+		}
 
-	return false
-}
+	case "javascript", "typescript":
+		for _, match := range jsImportPattern.FindAllStringSubmatch(string(content), -1) {
+			imports = append(imports, match[1])
+		}
+	}
 
-func extractImports(content []byte, language string) []string {
-	return []string{}
+	return imports
 }
 
-func hashFile(path string, info os.FileInfo) string {
-	return fmt.Sprintf("%s_%d_%d", path, info.Size(), info.ModTime().Unix())
+// hashContent keys the summary cache on file content so that fresh clones,
+// whose mtimes differ, still hit the cache.
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
 }
 
 func isLanguageSupported(language string, supported []string) bool {
@@ -367,11 +490,31 @@ func isLanguageSupported(language string, supported []string) bool {
 	return false
 }
 
+// docsAndConfig are the languages that describe or configure a codebase
+// rather than implement it.
+var docsAndConfig = map[string]bool{
+	"markdown": true, "rst": true, "latex": true, "text": true,
+	"yaml": true, "json": true, "xml": true, "toml": true, "ini": true,
+}
+
+// IsCodeLanguage reports whether language counts towards the code
+// breakdown, which leaves out documentation and configuration formats.
+func IsCodeLanguage(language string) bool {
+	return language != "" && !docsAndConfig[language]
+}
+
 func updateLanguageStats(result *Result, fileInfo *FileInfo) {
 	stat := result.LanguageStats[fileInfo.Language]
 	stat.FileCount++
 	stat.Lines += fileInfo.Lines
 	result.LanguageStats[fileInfo.Language] = stat
+
+	if IsCodeLanguage(fileInfo.Language) {
+		stat := result.CodeLanguageStats[fileInfo.Language]
+		stat.FileCount++
+		stat.Lines += fileInfo.Lines
+		result.CodeLanguageStats[fileInfo.Language] = stat
+	}
 }
 
 func calculateLanguagePercentages(result *Result) {
@@ -383,6 +526,17 @@ func calculateLanguagePercentages(result *Result) {
 		stat.Percentage = float64(stat.Lines) / float64(result.TotalLines) * 100
 		result.LanguageStats[lang] = stat
 	}
+
+	codeLines := 0
+	for _, stat := range result.CodeLanguageStats {
+		codeLines += stat.Lines
+	}
+	for lang, stat := range result.CodeLanguageStats {
+		if codeLines > 0 {
+			stat.Percentage = float64(stat.Lines) / float64(codeLines) * 100
+		}
+		result.CodeLanguageStats[lang] = stat
+	}
 }
 
 func getRepoMetadata(path string) RepoMetadata {
@@ -398,5 +552,14 @@ func getRepoMetadata(path string) RepoMetadata {
 		},
 	}
 
+	if commit, err := util.GitLastCommit(path); err == nil {
+		metadata.LastCommit = CommitInfo{
+			Hash:    commit.Hash,
+			Author:  commit.Author,
+			Date:    commit.Date,
+			Message: commit.Message,
+		}
+	}
+
 	return metadata
 }
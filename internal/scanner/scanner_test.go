@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -18,7 +19,11 @@ func TestDetectLanguage(t *testing.T) {
 		{"style.css", "css"},
 		{"Dockerfile", "dockerfile"},
 		{"Makefile", "makefile"},
+		{"Gemfile", "ruby"},
+		{"lib/tasks/import.rake", "ruby"},
 		{"README.md", "markdown"},
+		{"infra/main.tf", "terraform"},
+		{"envs/prod.tfvars", "terraform"},
 		{"unknown.xyz", "unknown"},
 	}
 
@@ -44,13 +49,20 @@ func TestIsTestFile(t *testing.T) {
 		{"scanner.go", false},
 		{"main.py", false},
 		{"app.js", false},
+		{"latest.go", false},
+		{"contest.py", false},
+		{"tests/helpers.py", true},
+		{"docs/testing.md", false},
+		{"src/__tests__/button.jsx", true},
+		{"src/inspect.js", false},
+		{"test/e2e/checkout.rb", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.path, func(t *testing.T) {
-			result := isTestFile(tt.path)
+			result := IsTestFile(tt.path)
 			if result != tt.expected {
-				t.Errorf("isTestFile(%s) = %v, want %v", tt.path, result, tt.expected)
+				t.Errorf("IsTestFile(%s) = %v, want %v", tt.path, result, tt.expected)
 			}
 		})
 	}
@@ -202,3 +214,179 @@ func TestLanguageSupport(t *testing.T) {
 		})
 	}
 }
+
+func TestHashContent(t *testing.T) {
+	a := hashContent([]byte("package main\n"))
+	b := hashContent([]byte("package main\n"))
+	c := hashContent([]byte("package other\n"))
+
+	if a != b {
+		t.Error("Identical content should produce identical hashes")
+	}
+	if a == c {
+		t.Error("Different content should produce different hashes")
+	}
+	if len(a) != 64 {
+		t.Errorf("Expected 64 hex characters, got %d", len(a))
+	}
+}
+
+func TestExtractImports(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		content  string
+		expected []string
+	}{
+		{"go", "go", "package main\n\nimport (\n\t\"fmt\"\n\tx \"github.com/acme/lib\"\n)\n", []string{"fmt", "github.com/acme/lib"}},
+		{"python", "python", "import os, sys\nfrom acme.lib import thing\n", []string{"os", "sys", "acme.lib"}},
+		{"javascript", "javascript", "import x from 'react'\nconst y = require(\"./util\")\n", []string{"react", "./util"}},
+		{"unknown", "markdown", "import x from 'react'\n", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := extractImports([]byte(tt.content), tt.language)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("extractImports() = %v, want %v", result, tt.expected)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("extractImports()[%d] = %s, want %s", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTestMatcher(t *testing.T) {
+	tempDir := t.TempDir()
+	packageJSON := `{"jest": {"testMatch": ["<rootDir>/specs/**/*.[jt]s?(x)", "**/?(*.)+(check).js"]}}`
+	if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(packageJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	matcher := NewTestMatcher(tempDir, TestRules{
+		Include: []string{"integration/**/*.go"},
+		Exclude: []string{"internal/testutil/**", "*_golden_test.go"},
+	})
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"specs/ui/button.tsx", true},
+		{"lib/cart.check.js", true},
+		{"lib/cart.test.js", false},
+		{"integration/run.go", true},
+		{"internal/testutil/fake_test.go", false},
+		{"render/html_golden_test.go", false},
+		{"render/html_test.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := matcher.Match(tt.path); got != tt.expected {
+				t.Errorf("Match(%s) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	api := &Result{
+		Files: []FileInfo{{Path: "/src/api/main.go", RelativePath: "main.go", Language: "go", Lines: 30}},
+	}
+	infra := &Result{
+		Files: []FileInfo{{Path: "/src/infra/deploy.yaml", RelativePath: filepath.Join("k8s", "deploy.yaml"), Language: "yaml", Lines: 10}},
+	}
+
+	merged := Merge([]string{"api", "infra"}, []*Result{api, infra})
+
+	if merged.TotalFiles != 2 || merged.TotalLines != 40 {
+		t.Errorf("Expected 2 files and 40 lines, got %d and %d", merged.TotalFiles, merged.TotalLines)
+	}
+	if got := merged.Files[1].RelativePath; got != "infra/k8s/deploy.yaml" {
+		t.Errorf("RelativePath = %q, want infra/k8s/deploy.yaml", got)
+	}
+	if got := merged.Files[0].Path; got != "/src/api/main.go" {
+		t.Errorf("Path = %q, want the absolute path unchanged", got)
+	}
+	if got := merged.LanguageStats["go"].Percentage; got != 75 {
+		t.Errorf("go percentage = %v, want 75", got)
+	}
+	if merged.RepoMetadata.Name != "api + infra" {
+		t.Errorf("Name = %q, want %q", merged.RepoMetadata.Name, "api + infra")
+	}
+}
+
+func TestCodeLanguageStats(t *testing.T) {
+	result := &Result{Files: []FileInfo{
+		{RelativePath: "main.go", Language: "go", Lines: 60},
+		{RelativePath: "web/app.ts", Language: "typescript", Lines: 20},
+		{RelativePath: "README.md", Language: "markdown", Lines: 120},
+		{RelativePath: "deploy.yaml", Language: "yaml", Lines: 200},
+	}}
+
+	subset := Subset(result, func(FileInfo) bool { return true })
+
+	if got := subset.LanguageStats["go"].Percentage; got != 15 {
+		t.Errorf("go share of all lines = %v, want 15", got)
+	}
+	if got := subset.CodeLanguageStats["go"].Percentage; got != 75 {
+		t.Errorf("go share of code lines = %v, want 75", got)
+	}
+	if _, ok := subset.CodeLanguageStats["markdown"]; ok || len(subset.CodeLanguageStats) != 2 {
+		t.Errorf("CodeLanguageStats = %v, want only go and typescript", subset.CodeLanguageStats)
+	}
+}
+
+func TestScanCancelled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := Scan(ctx, Options{Path: dir})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Scan() error = %v, want context.Canceled", err)
+	}
+	if result == nil || len(result.Files) != 0 {
+		t.Errorf("Scan() = %+v, want an empty partial result", result)
+	}
+}
+
+func TestTakeCensus(t *testing.T) {
+	tempDir := t.TempDir()
+	testFiles := map[string]string{
+		"main.go":                  "package main\n\nfunc main() {}\n",
+		"main_test.go":             "package main\n",
+		"app.py":                   "print('hi')\n",
+		"node_modules/pkg/main.js": "// ignored\n",
+	}
+	for name, content := range testFiles {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	census, err := TakeCensus(context.Background(), Options{Path: tempDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(census.Files) != 3 {
+		t.Fatalf("TakeCensus() found %+v, want 3 files", census.Files)
+	}
+	if got := census.Selected([]string{"go"}, false); len(got) != 1 || got[0].RelativePath != "main.go" || got[0].Lines != 4 {
+		t.Errorf("Selected(go) = %+v, want main.go with 4 lines", got)
+	}
+	if got := census.Selected(nil, true); len(got) != 3 {
+		t.Errorf("Selected(all) = %+v, want 3 files", got)
+	}
+}
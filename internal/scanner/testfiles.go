@@ -0,0 +1,225 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TestRules override test classification with slash-separated glob
+// patterns relative to the repository root. Exclude wins over Include.
+type TestRules struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// TestMatcher classifies files as tests using per-language conventions, the
+// repository's jest testMatch setting and TestRules overrides.
+type TestMatcher struct {
+	include   []*regexp.Regexp
+	exclude   []*regexp.Regexp
+	jestMatch []*regexp.Regexp
+}
+
+var (
+	jestTestMatch = regexp.MustCompile(`(?s)testMatch\s*:\s*\[(.*?)\]`)
+	quotedString  = regexp.MustCompile(`['"\x60]([^'"\x60]+)['"\x60]`)
+)
+
+// NewTestMatcher reads jest's testMatch from package.json or jest.config.*
+// at repoPath, when present, and compiles rules.
+func NewTestMatcher(repoPath string, rules TestRules) *TestMatcher {
+	m := &TestMatcher{
+		include: compileGlobs(rules.Include),
+		exclude: compileGlobs(rules.Exclude),
+	}
+	if repoPath != "" {
+		m.jestMatch = compileGlobs(jestTestPatterns(repoPath))
+	}
+	return m
+}
+
+// Match reports whether rel, a slash-separated path relative to the
+// repository root, is a test file.
+func (m *TestMatcher) Match(rel string) bool {
+	rel = strings.TrimPrefix(filepath.ToSlash(rel), "./")
+	if matchesAny(m.exclude, rel) {
+		return false
+	}
+	if matchesAny(m.include, rel) {
+		return true
+	}
+
+	switch detectLanguage(rel) {
+	case "javascript", "typescript":
+		if len(m.jestMatch) > 0 {
+			return matchesAny(m.jestMatch, rel)
+		}
+	}
+	return IsTestFile(rel)
+}
+
+// IsTestFile applies the default per-language conventions to a path
+// relative to the repository root:
+//   - Go: the _test.go suffix.
+//   - Python: pytest's test_*.py and *_test.py, conftest.py, and modules
+//     under a tests/ directory.
+//   - JavaScript/TypeScript: jest's defaults, *.test.* / *.spec.* and
+//     files under __tests__/.
+//   - Other languages: files under test/, tests/ or spec/ directories.
+func IsTestFile(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	base := path.Base(rel)
+	dirs := "/" + path.Dir(rel) + "/"
+
+	switch detectLanguage(rel) {
+	case "go":
+		return strings.HasSuffix(base, "_test.go")
+
+	case "python":
+		if !strings.HasSuffix(base, ".py") {
+			return false
+		}
+		return strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py") ||
+			base == "conftest.py" || strings.Contains(dirs, "/tests/")
+
+	case "javascript", "typescript":
+		stem := strings.TrimSuffix(base, path.Ext(base))
+		return strings.HasSuffix(stem, ".test") || strings.HasSuffix(stem, ".spec") ||
+			strings.Contains(dirs, "/__tests__/")
+	}
+
+	return strings.Contains(dirs, "/test/") || strings.Contains(dirs, "/tests/") ||
+		strings.Contains(dirs, "/spec/")
+}
+
+func jestTestPatterns(repoPath string) []string {
+	if content, err := os.ReadFile(filepath.Join(repoPath, "package.json")); err == nil {
+		var manifest struct {
+			Jest struct {
+				TestMatch []string `json:"testMatch"`
+			} `json:"jest"`
+		}
+		if json.Unmarshal(content, &manifest) == nil && len(manifest.Jest.TestMatch) > 0 {
+			return manifest.Jest.TestMatch
+		}
+	}
+
+	for _, name := range []string{"jest.config.js", "jest.config.ts", "jest.config.mjs", "jest.config.cjs", "jest.config.json"} {
+		content, err := os.ReadFile(filepath.Join(repoPath, name))
+		if err != nil {
+			continue
+		}
+		match := jestTestMatch.FindSubmatch(content)
+		if match == nil {
+			continue
+		}
+		patterns := []string{}
+		for _, quoted := range quotedString.FindAllSubmatch(match[1], -1) {
+			patterns = append(patterns, string(quoted[1]))
+		}
+		return patterns
+	}
+
+	return nil
+}
+
+func compileGlobs(patterns []string) []*regexp.Regexp {
+	compiled := []*regexp.Regexp{}
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(pattern, "<rootDir>/")
+		if re, err := regexp.Compile(globToRegexp(pattern)); err == nil {
+			compiled = append(compiled, re)
+		}
+	}
+	return compiled
+}
+
+// CompileGlob compiles a slash-separated glob with the syntax of TestRules.
+func CompileGlob(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(globToRegexp(pattern))
+}
+
+func matchesAny(patterns []*regexp.Regexp, rel string) bool {
+	for _, re := range patterns {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp translates the glob syntax used by jest and .gitignore-style
+// overrides: **, *, ?, {a,b}, [...] and the extglobs ?(), *(), +() and @().
+// A pattern without a slash matches the file name in any directory.
+func globToRegexp(glob string) string {
+	if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	braces := 0
+	var groups []byte
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		next := byte(0)
+		if i+1 < len(glob) {
+			next = glob[i+1]
+		}
+
+		switch {
+		case strings.IndexByte("?*+@!", c) >= 0 && next == '(':
+			groups = append(groups, c)
+			b.WriteString("(?:")
+			i++
+		case c == ')' && len(groups) > 0:
+			op := groups[len(groups)-1]
+			groups = groups[:len(groups)-1]
+			b.WriteString(")")
+			switch op {
+			case '?', '*', '+':
+				b.WriteByte(op)
+			}
+		case c == '|' && len(groups) > 0:
+			b.WriteString("|")
+		case c == '*' && next == '*':
+			if i+2 < len(glob) && glob[i+2] == '/' {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else {
+				b.WriteString(".*")
+				i++
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '{':
+			braces++
+			b.WriteString("(?:")
+		case c == '}' && braces > 0:
+			braces--
+			b.WriteString(")")
+		case c == ',' && braces > 0:
+			b.WriteString("|")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(glob[i : i+end+1])
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+	return b.String()
+}
@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Read reads back a database written by Write, returning each table's name
+// and rows in insertion order. Column definitions are not parsed. It reads
+// only the subset of the format Write produces: 4096-byte pages and table
+// b-trees with no free pages.
+func Read(path string) ([]Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database: %w", err)
+	}
+	if len(data) < pageSize || string(data[:16]) != "SQLite format 3\x00" || len(data)%pageSize != 0 {
+		return nil, fmt.Errorf("%s is not a database of %d-byte pages", path, pageSize)
+	}
+	r := reader{data: data}
+	schema, err := r.table(1)
+	if err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+	tables := []Table{}
+	for _, entry := range schema {
+		name, _ := entry[1].(string)
+		root, ok := entry[3].(int64)
+		if len(entry) != 5 || !ok {
+			return nil, fmt.Errorf("schema entry %v is malformed", entry)
+		}
+		rows, err := r.table(uint32(root))
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", name, err)
+		}
+		tables = append(tables, Table{Name: name, Rows: rows})
+	}
+	return tables, nil
+}
+
+type reader struct {
+	data []byte
+}
+
+func (r reader) page(number uint32) ([]byte, error) {
+	if number == 0 || int(number)*pageSize > len(r.data) {
+		return nil, fmt.Errorf("page %d is out of range", number)
+	}
+	return r.data[(number-1)*pageSize : number*pageSize], nil
+}
+
+// table walks the table b-tree rooted at page in rowid order and decodes
+// its records, checking rowids run from 1 without gaps.
+func (r reader) table(root uint32) ([][]any, error) {
+	rows := [][]any{}
+	var walk func(uint32) error
+	walk = func(number uint32) error {
+		page, err := r.page(number)
+		if err != nil {
+			return err
+		}
+		offset := 0
+		if number == 1 {
+			offset = headerSize
+		}
+		cells := int(binary.BigEndian.Uint16(page[offset+3:]))
+		switch page[offset] {
+		case interiorTable:
+			for i := 0; i < cells; i++ {
+				pointer := binary.BigEndian.Uint16(page[offset+12+2*i:])
+				if err := walk(binary.BigEndian.Uint32(page[pointer:])); err != nil {
+					return err
+				}
+			}
+			return walk(binary.BigEndian.Uint32(page[offset+8:]))
+		case leafTable:
+			for i := 0; i < cells; i++ {
+				cell := page[binary.BigEndian.Uint16(page[offset+8+2*i:]):]
+				size, n := readVarint(cell)
+				rowid, m := readVarint(cell[n:])
+				if int(rowid) != len(rows)+1 {
+					return fmt.Errorf("rowid %d after %d rows", rowid, len(rows))
+				}
+				payload, err := r.payload(cell[n+m:], int(size))
+				if err != nil {
+					return err
+				}
+				row, err := decodeRecord(payload)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", rowid, err)
+				}
+				rows = append(rows, row)
+			}
+			return nil
+		default:
+			return fmt.Errorf("page %d has type %#x", number, page[offset])
+		}
+	}
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// payload reassembles a cell's payload, following its overflow chain with
+// the thresholds leafCell uses to split it.
+func (r reader) payload(cell []byte, size int) ([]byte, error) {
+	if size <= pageSize-35 {
+		return cell[:size], nil
+	}
+	minLocal := (pageSize-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(pageSize-4)
+	if local > pageSize-35 {
+		local = minLocal
+	}
+	payload := append([]byte{}, cell[:local]...)
+	for next := binary.BigEndian.Uint32(cell[local:]); len(payload) < size; {
+		page, err := r.page(next)
+		if err != nil {
+			return nil, fmt.Errorf("overflow: %w", err)
+		}
+		payload = append(payload, page[4:min(pageSize, 4+size-len(payload))]...)
+		next = binary.BigEndian.Uint32(page)
+	}
+	return payload, nil
+}
+
+func decodeRecord(record []byte) ([]any, error) {
+	headerSize, n := readVarint(record)
+	types := []uint64{}
+	for i := n; i < int(headerSize); {
+		serial, m := readVarint(record[i:])
+		types = append(types, serial)
+		i += m
+	}
+	body := record[headerSize:]
+	values := []any{}
+	for _, serial := range types {
+		size := 0
+		switch {
+		case serial == 7:
+			size = 8
+		case serial >= 1 && serial <= 6:
+			size = []int{0, 1, 2, 3, 4, 6, 8}[serial]
+		case serial >= 12:
+			size = int(serial-12) / 2
+		}
+		if size > len(body) {
+			return nil, fmt.Errorf("record is truncated")
+		}
+		switch {
+		case serial == 0:
+			values = append(values, nil)
+		case serial == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(body)))
+		case serial == 8 || serial == 9:
+			values = append(values, int64(serial-8))
+		case serial <= 6:
+			v := int64(int8(body[0]))
+			for _, b := range body[1:size] {
+				v = v<<8 | int64(b)
+			}
+			values = append(values, v)
+		case serial%2 == 0:
+			values = append(values, append([]byte{}, body[:size]...))
+		default:
+			values = append(values, string(body[:size]))
+		}
+		body = body[size:]
+	}
+	return values, nil
+}
+
+func readVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8 && i < len(b); i++ {
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	if len(b) < 9 {
+		return v, len(b)
+	}
+	return v<<8 | uint64(b[8]), 9
+}
@@ -0,0 +1,329 @@
+// Package sqlite writes SQLite 3 database files. It supports what exports
+// need, creating a new database of tables filled with rows, with no driver
+// or cgo required. The files can be opened by any SQLite client, and Read
+// reads them back.
+package sqlite
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+const (
+	pageSize = 4096
+	// headerSize is the database header at the start of page 1.
+	headerSize = 100
+
+	leafTable     = 0x0d
+	interiorTable = 0x05
+)
+
+// Table is a table to create and the rows to insert into it. Values may be
+// nil, bool, int, int64, float64, string or []byte.
+type Table struct {
+	Name    string
+	Columns []Column
+	Rows    [][]any
+}
+
+type Column struct {
+	Name string
+	// Type is the declared type, such as TEXT or INTEGER.
+	Type string
+}
+
+// Write creates the database at path, replacing any file there.
+func Write(path string, tables []Table) error {
+	w := &writer{pages: [][]byte{nil}} // page 1 is written last
+	schema := [][]byte{}
+	for _, table := range tables {
+		records := make([][]byte, len(table.Rows))
+		for i, row := range table.Rows {
+			if len(row) != len(table.Columns) {
+				return fmt.Errorf("table %s: row %d has %d values for %d columns", table.Name, i+1, len(row), len(table.Columns))
+			}
+			record, err := encodeRecord(row)
+			if err != nil {
+				return fmt.Errorf("table %s: %w", table.Name, err)
+			}
+			records[i] = record
+		}
+		root := w.buildTree(records)
+
+		record, err := encodeRecord([]any{"table", table.Name, table.Name, int64(root), createStatement(table)})
+		if err != nil {
+			return err
+		}
+		schema = append(schema, record)
+	}
+
+	cells := make([][]byte, len(schema))
+	used := headerSize + 8
+	for i, record := range schema {
+		cells[i] = w.leafCell(int64(i+1), record)
+		used += len(cells[i]) + 2
+	}
+	if used > pageSize {
+		return fmt.Errorf("schema of %d tables does not fit on the first page", len(tables))
+	}
+	w.pages[0] = leafPage(cells, headerSize)
+	writeHeader(w.pages[0], len(w.pages))
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write database: %w", err)
+	}
+	defer file.Close()
+	for _, page := range w.pages {
+		if _, err := file.Write(page); err != nil {
+			return fmt.Errorf("failed to write database: %w", err)
+		}
+	}
+	return file.Close()
+}
+
+func createStatement(table Table) string {
+	columns := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		columns[i] = quote(column.Name) + " " + column.Type
+	}
+	return fmt.Sprintf("CREATE TABLE %s (%s)", quote(table.Name), strings.Join(columns, ", "))
+}
+
+func quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+type writer struct {
+	pages [][]byte
+}
+
+// allocate appends a page and returns its number, counting from 1.
+func (w *writer) allocate(page []byte) uint32 {
+	w.pages = append(w.pages, page)
+	return uint32(len(w.pages))
+}
+
+// child is a page of a b-tree level and the largest rowid under it.
+type child struct {
+	page   uint32
+	maxKey int64
+}
+
+// buildTree writes a table b-tree holding records as rows 1 to n and
+// returns its root page: leaves filled in rowid order, then interior levels
+// above them until one page remains.
+func (w *writer) buildTree(records [][]byte) uint32 {
+	level := []child{}
+	cells := [][]byte{}
+	used := 8
+	for i, record := range records {
+		cell := w.leafCell(int64(i+1), record)
+		if len(cells) > 0 && used+len(cell)+2 > pageSize {
+			level = append(level, child{page: w.allocate(leafPage(cells, 0)), maxKey: int64(i)})
+			cells, used = nil, 8
+		}
+		cells = append(cells, cell)
+		used += len(cell) + 2
+	}
+	level = append(level, child{page: w.allocate(leafPage(cells, 0)), maxKey: int64(len(records))})
+
+	for len(level) > 1 {
+		parents := []child{}
+		cells, used = nil, 12
+		right := level[0]
+		for _, next := range level[1:] {
+			cell := binary.BigEndian.AppendUint32(nil, right.page)
+			cell = appendVarint(cell, uint64(right.maxKey))
+			if len(cells) > 0 && used+len(cell)+2 > pageSize {
+				parents = append(parents, child{page: w.allocate(interiorPage(cells, right.page)), maxKey: right.maxKey})
+				cells, used = nil, 12
+			} else {
+				cells = append(cells, cell)
+				used += len(cell) + 2
+			}
+			right = next
+		}
+		parents = append(parents, child{page: w.allocate(interiorPage(cells, right.page)), maxKey: right.maxKey})
+		level = parents
+	}
+	return level[0].page
+}
+
+// leafCell encodes a table leaf cell, spilling the end of a payload too
+// large for the page into a chain of overflow pages.
+func (w *writer) leafCell(rowid int64, payload []byte) []byte {
+	cell := appendVarint(nil, uint64(len(payload)))
+	cell = appendVarint(cell, uint64(rowid))
+
+	// Thresholds from the file format: the largest payload kept whole on
+	// the page, and how much of a larger one stays local.
+	maxLocal := pageSize - 35
+	if len(payload) <= maxLocal {
+		return append(cell, payload...)
+	}
+	minLocal := (pageSize-12)*32/255 - 23
+	local := minLocal + (len(payload)-minLocal)%(pageSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+
+	// Allocate the chain back to front so each page knows the next one.
+	rest := payload[local:]
+	chunks := [][]byte{}
+	for len(rest) > 0 {
+		n := min(len(rest), pageSize-4)
+		chunks = append(chunks, rest[:n])
+		rest = rest[n:]
+	}
+	next := uint32(0)
+	for i := len(chunks) - 1; i >= 0; i-- {
+		page := make([]byte, pageSize)
+		binary.BigEndian.PutUint32(page, next)
+		copy(page[4:], chunks[i])
+		next = w.allocate(page)
+	}
+	cell = append(cell, payload[:local]...)
+	return binary.BigEndian.AppendUint32(cell, next)
+}
+
+// leafPage lays out a leaf page: the header at offset, the cell pointers
+// after it, and the cells packed at the end of the page.
+func leafPage(cells [][]byte, offset int) []byte {
+	page := make([]byte, pageSize)
+	page[offset] = leafTable
+	writeCells(page, offset, 8, cells)
+	return page
+}
+
+func interiorPage(cells [][]byte, right uint32) []byte {
+	page := make([]byte, pageSize)
+	page[0] = interiorTable
+	binary.BigEndian.PutUint32(page[8:], right)
+	writeCells(page, 0, 12, cells)
+	return page
+}
+
+func writeCells(page []byte, offset, header int, cells [][]byte) {
+	content := pageSize
+	for i, cell := range cells {
+		content -= len(cell)
+		copy(page[content:], cell)
+		binary.BigEndian.PutUint16(page[offset+header+2*i:], uint16(content))
+	}
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+}
+
+func writeHeader(page []byte, pages int) {
+	copy(page, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(page[16:], pageSize)
+	page[18], page[19] = 1, 1                 // legacy (rollback journal) file format
+	page[21], page[22], page[23] = 64, 32, 32 // payload fractions, fixed by the format
+	binary.BigEndian.PutUint32(page[24:], 1)  // file change counter
+	binary.BigEndian.PutUint32(page[28:], uint32(pages))
+	binary.BigEndian.PutUint32(page[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(page[44:], 4) // schema format
+	binary.BigEndian.PutUint32(page[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(page[92:], 1) // version-valid-for, matching the change counter
+	binary.BigEndian.PutUint32(page[96:], 3045000)
+}
+
+// encodeRecord encodes values in the record format: a header of serial
+// types, one per value, followed by the values.
+func encodeRecord(values []any) ([]byte, error) {
+	types := []byte{}
+	body := []byte{}
+	for _, value := range values {
+		var serial uint64
+		switch v := value.(type) {
+		case nil:
+			serial = 0
+		case bool:
+			serial = 8
+			if v {
+				serial = 9
+			}
+		case int:
+			serial, body = appendInt(body, int64(v))
+		case int64:
+			serial, body = appendInt(body, v)
+		case float64:
+			serial = 7
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(v))
+		case string:
+			serial = uint64(13 + 2*len(v))
+			body = append(body, v...)
+		case []byte:
+			serial = uint64(12 + 2*len(v))
+			body = append(body, v...)
+		default:
+			return nil, fmt.Errorf("unsupported value of type %T", value)
+		}
+		types = appendVarint(types, serial)
+	}
+
+	// The header size counts its own varint.
+	size := len(types) + 1
+	for varintLen(uint64(size)) != size-len(types) {
+		size = len(types) + varintLen(uint64(size))
+	}
+	record := appendVarint(nil, uint64(size))
+	record = append(record, types...)
+	return append(record, body...), nil
+}
+
+// appendInt appends v in the smallest integer serial type holding it.
+func appendInt(body []byte, v int64) (uint64, []byte) {
+	switch {
+	case v == 0:
+		return 8, body
+	case v == 1:
+		return 9, body
+	}
+	sizes := []struct {
+		serial uint64
+		bytes  int
+	}{{1, 1}, {2, 2}, {3, 3}, {4, 4}, {5, 6}, {6, 8}}
+	for _, size := range sizes {
+		bits := uint(size.bytes * 8)
+		if size.bytes == 8 || (v >= -1<<(bits-1) && v < 1<<(bits-1)) {
+			for i := size.bytes - 1; i >= 0; i-- {
+				body = append(body, byte(v>>(8*uint(i))))
+			}
+			return size.serial, body
+		}
+	}
+	return 0, body
+}
+
+// appendVarint appends v as a SQLite varint: big-endian groups of 7 bits
+// with the high bit set on all but the last byte, and a ninth byte holding
+// a full 8 bits.
+func appendVarint(b []byte, v uint64) []byte {
+	if v > 1<<56-1 {
+		for i := 8; i > 0; i-- {
+			b = append(b, byte(v>>(8+7*uint(i-1)))|0x80)
+		}
+		return append(b, byte(v))
+	}
+	var groups []byte
+	for {
+		groups = append(groups, byte(v&0x7f))
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	for i := len(groups) - 1; i > 0; i-- {
+		b = append(b, groups[i]|0x80)
+	}
+	return append(b, groups[0])
+}
+
+func varintLen(v uint64) int {
+	return len(appendVarint(nil, v))
+}
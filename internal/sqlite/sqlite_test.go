@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	files := []Table{
+		{
+			Name:    "files",
+			Columns: []Column{{"path", "TEXT"}, {"lines", "INTEGER"}, {"is_test", "INTEGER"}, {"score", "REAL"}, {"hash", "BLOB"}},
+		},
+		{Name: "empty", Columns: []Column{{"name", "TEXT"}}},
+	}
+	want := [][]any{}
+	for i := 0; i < 5000; i++ {
+		want = append(want, []any{"internal/file" + strings.Repeat("x", i%40) + ".go", int64(i * 977), int64(i % 2), 0.5, nil})
+	}
+	// Payloads larger than a page continue on overflow pages.
+	want = append(want, []any{strings.Repeat("é", 9000), int64(-1 << 40), int64(1), -2.25, []byte{0xde, 0xad}})
+	for _, row := range want {
+		files[0].Rows = append(files[0].Rows, []any{row[0], row[1], row[2] == int64(1), row[3], row[4]})
+	}
+
+	path := filepath.Join(t.TempDir(), "analysis.db")
+	if err := Write(path, files); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "SQLite format 3\x00") || len(data)%pageSize != 0 {
+		t.Fatalf("Expected a SQLite database of whole pages, got %d bytes", len(data))
+	}
+	if pages := binary.BigEndian.Uint32(data[28:]); int(pages)*pageSize != len(data) {
+		t.Errorf("Header counts %d pages, file has %d", pages, len(data)/pageSize)
+	}
+
+	r := reader{data: data}
+	schema, err := r.table(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema) != 2 || schema[0][4] != `CREATE TABLE "files" ("path" TEXT, "lines" INTEGER, "is_test" INTEGER, "score" REAL, "hash" BLOB)` {
+		t.Fatalf("Unexpected schema %v", schema)
+	}
+
+	tables, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || tables[0].Name != "files" || tables[1].Name != "empty" {
+		t.Fatalf("Read() returned %d tables", len(tables))
+	}
+	if got := tables[0].Rows; !reflect.DeepEqual(got, want) {
+		t.Errorf("Read back %d rows, want %d; first %v, last %v", len(got), len(want), got[0], got[len(got)-1])
+	}
+	if got := tables[1].Rows; len(got) != 0 {
+		t.Errorf("Expected an empty table, got %v", got)
+	}
+}
+
+func TestReadRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analysis.db")
+	if err := os.WriteFile(path, []byte("not a database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil || !strings.Contains(err.Error(), "is not a database") {
+		t.Errorf("Read() = %v, want a format error", err)
+	}
+}
+
+func TestWriteRejectsShortRows(t *testing.T) {
+	err := Write(filepath.Join(t.TempDir(), "analysis.db"), []Table{
+		{Name: "files", Columns: []Column{{"path", "TEXT"}, {"lines", "INTEGER"}}, Rows: [][]any{{"main.go"}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "row 1 has 1 values for 2 columns") {
+		t.Errorf("Write() = %v, want a column count error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/annotate"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+const (
+	docCommentWords = 80
+	// docCommentFiles caps the files of a package a doc comment is drawn
+	// from, largest first.
+	docCommentFiles = 5
+)
+
+// DocComment proposes the package comment of a Go package, or the
+// docstring of a Python module, from the summaries of its files in result.
+// Files without one are summarized now and added to result.
+func DocComment(ctx context.Context, opts Options, result *Result, target annotate.Target) (string, error) {
+	opts.LLMProvider = opts.provider()
+
+	sources := map[string]scanner.FileInfo{}
+	for _, file := range opts.ScanResult.Files {
+		sources[file.RelativePath] = file
+	}
+	files := []scanner.FileInfo{}
+	for _, rel := range target.Files {
+		if file, ok := sources[rel]; ok {
+			files = append(files, file)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Lines > files[j].Lines })
+	if len(files) > docCommentFiles {
+		files = files[:docCommentFiles]
+	}
+
+	var b strings.Builder
+	switch {
+	case target.Command():
+		command := path.Base(path.Dir(target.File))
+		if command == "." {
+			command = opts.ScanResult.RepoMetadata.Name
+		}
+		fmt.Fprintf(&b, "Go command: %s\n", command)
+	case target.Language == "go":
+		fmt.Fprintf(&b, "Go package: %s\n", target.Name)
+	default:
+		fmt.Fprintf(&b, "Python module: %s\n", target.Name)
+	}
+	if result.ArchitectureSummary != "" {
+		fmt.Fprintf(&b, "\nRepository architecture:\n%s\n", result.ArchitectureSummary)
+	}
+	for _, file := range files {
+		summary, ok := result.FileSummaries[file.RelativePath]
+		if !ok {
+			var err error
+			if summary, err = summarizeFile(ctx, opts, file); err != nil {
+				return "", fmt.Errorf("%s: %w", file.RelativePath, err)
+			}
+			result.FileSummaries[file.RelativePath] = summary
+		}
+		fmt.Fprintf(&b, "\n--- %s\nSummary: %s\n", filepath.ToSlash(file.RelativePath), summary.Summary)
+		for _, function := range summary.Functions {
+			fmt.Fprintf(&b, "- %s\n", function)
+		}
+	}
+	if len(target.Files) > len(files) {
+		fmt.Fprintf(&b, "\n(%d more files)\n", len(target.Files)-len(files))
+	}
+
+	response, err := opts.LLMProvider.Summarize(ctx, llm.SummarizeRequest{
+		Type:        llm.SummaryTypeDocComment,
+		Context:     b.String(),
+		Constraints: llm.Constraints{MaxWords: docCommentWords},
+	})
+	if err != nil {
+		return "", err
+	}
+	return response.Summary, nil
+}
@@ -0,0 +1,106 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+const (
+	// DefaultSources is how many files an answer draws on.
+	DefaultSources = 5
+	answerWords    = 300
+	// sourceLines caps each file excerpt shown with a question.
+	sourceLines = 150
+)
+
+// Answer is the model's answer to a question and the files it was shown.
+type Answer struct {
+	Text    string        `json:"answer"`
+	Sources []embed.Match `json:"sources"`
+}
+
+// Ask answers question about the repository of opts.ScanResult. It ranks
+// the scanned files by how close their summaries, or paths when they have
+// none, are to the question with embedder, and shows the model the best
+// sources of them, with excerpts read from file.Path where it still exists,
+// alongside the summaries in result. vectors holds embeddings of
+// AskDocuments made earlier with the same embedder, by slash path; files
+// without one are embedded now.
+func Ask(ctx context.Context, opts Options, result *Result, embedder embed.Provider, vectors map[string][]float64, question string, sources int) (*Answer, error) {
+	docs := AskDocuments(opts.ScanResult, result)
+	files := map[string]scanner.FileInfo{}
+	for i, file := range opts.ScanResult.Files {
+		files[docs[i].Path] = file
+		docs[i].Vector = vectors[docs[i].Path]
+	}
+	matches, err := embed.Search(ctx, embedder, question, docs, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question: %s\n", question)
+	if result.ArchitectureSummary != "" {
+		fmt.Fprintf(&b, "\nArchitecture:\n%s\n", result.ArchitectureSummary)
+	}
+	modules := slices.Sorted(maps.Keys(result.ModuleSummaries))
+	shown := map[string]bool{}
+	for _, match := range matches {
+		module := moduleOf(files[match.Path].RelativePath, modules)
+		if module != "" && !shown[module] {
+			fmt.Fprintf(&b, "\nModule %s:\n%s\n", filepath.ToSlash(module), result.ModuleSummaries[module])
+			shown[module] = true
+		}
+	}
+	lines := sourceLines
+	if opts.MaxLinesPerFile > 0 {
+		lines = min(lines, opts.MaxLinesPerFile)
+	}
+	for _, match := range matches {
+		file := files[match.Path]
+		fmt.Fprintf(&b, "\n--- %s\n", match.Path)
+		if summary, ok := result.FileSummaries[file.RelativePath]; ok {
+			fmt.Fprintf(&b, "Summary: %s\n", summary.Summary)
+			for _, function := range summary.Functions {
+				fmt.Fprintf(&b, "- %s\n", function)
+			}
+		}
+		// Files deleted or moved since the analysis keep their summary.
+		if excerpt, err := buildFileContext(file, lines); err == nil {
+			fmt.Fprintf(&b, "%s\n", excerpt)
+		}
+	}
+
+	response, err := opts.provider().Summarize(ctx, llm.SummarizeRequest{
+		Type:        llm.SummaryTypeAnswer,
+		Context:     b.String(),
+		Constraints: llm.Constraints{MaxWords: answerWords},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Answer{Text: response.Summary, Sources: matches}, nil
+}
+
+// AskDocuments returns, for each scanned file in order, the text Ask ranks
+// it by: its path, summary and functions.
+func AskDocuments(scanResult *scanner.Result, result *Result) []embed.Document {
+	docs := make([]embed.Document, 0, len(scanResult.Files))
+	for _, file := range scanResult.Files {
+		path := filepath.ToSlash(file.RelativePath)
+		text := path
+		if summary, ok := result.FileSummaries[file.RelativePath]; ok {
+			text += "\n" + summary.Summary + "\n" + strings.Join(summary.Functions, "\n")
+		}
+		docs = append(docs, embed.Document{Path: path, Text: text})
+	}
+	return docs
+}
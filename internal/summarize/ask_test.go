@@ -0,0 +1,71 @@
+package summarize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestAsk(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.go")
+	if err := os.WriteFile(path, []byte("package auth\n\nfunc StoreToken() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := []scanner.FileInfo{
+		// Analyzed elsewhere: the files are read from Path.
+		{Path: path, RelativePath: filepath.Join("auth", "session.go"), Language: "go", Lines: 3},
+		{Path: filepath.Join(dir, "gone.go"), RelativePath: filepath.Join("auth", "login.go"), Language: "go"},
+		{Path: filepath.Join(dir, "tax.go"), RelativePath: filepath.Join("billing", "tax.go"), Language: "go"},
+	}
+	result := &Result{
+		ArchitectureSummary: "A shop.",
+		ModuleSummaries:     map[string]string{"auth": "Signs users in."},
+		FileSummaries: map[string]FileSummary{
+			filepath.Join("auth", "session.go"): {Summary: "Stores session tokens in cookies.", Functions: []string{"StoreToken() — sets the cookie"}},
+			filepath.Join("auth", "login.go"):   {Summary: "Checks passwords and issues session tokens."},
+			filepath.Join("billing", "tax.go"):  {Summary: "Computes tax rates."},
+		},
+	}
+
+	var request llm.SummarizeRequest
+	provider := ProviderFunc(func(ctx context.Context, r llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+		request = r
+		return llm.SummarizeResponse{Summary: "In auth/session.go."}, nil
+	})
+	opts := Options{ScanResult: &scanner.Result{Files: files}, LLMProvider: provider}
+
+	answer, err := Ask(context.Background(), opts, result, embed.NewLocalProvider(), nil, "Where are session cookies stored?", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer.Text != "In auth/session.go." {
+		t.Errorf("Ask() = %q", answer.Text)
+	}
+	got := []string{}
+	for _, source := range answer.Sources {
+		got = append(got, source.Path)
+	}
+	if want := []string{"auth/session.go", "auth/login.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Ask() sources = %v, want %v", got, want)
+	}
+
+	if request.Type != llm.SummaryTypeAnswer {
+		t.Errorf("Ask() requested %q, want an answer", request.Type)
+	}
+	for _, want := range []string{"Question: Where are session cookies stored?", "Architecture:\nA shop.", "Module auth:\nSigns users in.", "- StoreToken() — sets the cookie", "func StoreToken() {}", "--- auth/login.go\nSummary: Checks passwords"} {
+		if !strings.Contains(request.Context, want) {
+			t.Errorf("Ask() context lacks %q:\n%s", want, request.Context)
+		}
+	}
+	if strings.Contains(request.Context, "billing/tax.go") {
+		t.Errorf("Ask() context has an unrelated file:\n%s", request.Context)
+	}
+}
@@ -0,0 +1,200 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+const (
+	// DefaultChangedFiles is how many changed files are summarized.
+	DefaultChangedFiles = 100
+	changeWords         = 60
+	changelogWords      = 250
+	// changeLines caps the diff of one file shown to the model.
+	changeLines = 300
+	// moduleDepth is how many directories deep changes are grouped.
+	moduleDepth = 3
+)
+
+// FileChange is a changed file and the model's summary of its diff.
+type FileChange struct {
+	util.FileChange
+	Summary string `json:"summary,omitempty"`
+}
+
+// ModuleChanges are the changed files of one module, "." for the root.
+type ModuleChanges struct {
+	Module string       `json:"module"`
+	Files  []FileChange `json:"files"`
+}
+
+// Changes describes what changed between two refs.
+type Changes struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Overview string          `json:"overview"`
+	Modules  []ModuleChanges `json:"modules"`
+}
+
+// SummarizeChanges summarizes the diffs of the maxFiles most changed files
+// among changes, then the whole change from those summaries. Files Scan
+// ignores, such as vendored ones, are left out; binary files are listed
+// without a summary.
+func SummarizeChanges(ctx context.Context, opts Options, from, to string, changes []util.FileChange, maxFiles int) (*Changes, error) {
+	provider := opts.provider()
+	result := &Changes{From: from, To: to, Modules: []ModuleChanges{}}
+
+	files := []FileChange{}
+	for _, change := range changes {
+		if !scanner.Ignored(change.Path) {
+			files = append(files, FileChange{FileChange: change})
+		}
+	}
+
+	ranked := make([]int, len(files))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return changedLines(files[ranked[i]].Patch) > changedLines(files[ranked[j]].Patch)
+	})
+	for n, i := range ranked {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		file := &files[i]
+		if n >= maxFiles || file.Patch == "" {
+			continue
+		}
+		response, err := provider.Summarize(ctx, changeRequest(file.FileChange, opts.MaxLinesPerFile))
+		opts.Progress.Advance(file.Path)
+		if err != nil {
+			slog.WarnContext(ctx, "change summary skipped", "file", file.Path, "err", err)
+			continue
+		}
+		file.Summary = response.Summary
+	}
+
+	byModule := map[string][]FileChange{}
+	for _, file := range files {
+		module := changeModule(file.Path)
+		byModule[module] = append(byModule[module], file)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Changes from %s to %s.\n", from, to)
+	for _, module := range slices.Sorted(maps.Keys(byModule)) {
+		result.Modules = append(result.Modules, ModuleChanges{Module: module, Files: byModule[module]})
+		fmt.Fprintf(&b, "\nModule %s:\n", module)
+		for _, file := range byModule[module] {
+			fmt.Fprintf(&b, "- %s (%s)", file.Path, file.Status)
+			if file.Summary != "" {
+				fmt.Fprintf(&b, ": %s", strings.Join(strings.Fields(file.Summary), " "))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(files) == 0 {
+		return result, nil
+	}
+
+	response, err := provider.Summarize(ctx, llm.SummarizeRequest{
+		Type:        llm.SummaryTypeChangelog,
+		Context:     b.String(),
+		Constraints: llm.Constraints{MaxWords: changelogWords},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Overview = response.Summary
+	return result, nil
+}
+
+func changeRequest(change util.FileChange, maxLines int) llm.SummarizeRequest {
+	lines := strings.Split(change.Patch, "\n")
+	limit := changeLines
+	if maxLines > 0 {
+		limit = min(limit, maxLines)
+	}
+	if len(lines) > limit {
+		lines = append(lines[:limit], fmt.Sprintf("... (%d more diff lines)", len(lines)-limit))
+	}
+
+	context := fmt.Sprintf("File: %s\n", change.Path)
+	if change.OldPath != "" {
+		context += fmt.Sprintf("Renamed from: %s\n", change.OldPath)
+	}
+	context += fmt.Sprintf("Change: %s\n\nDiff:\n%s", change.Status, strings.Join(lines, "\n"))
+	return llm.SummarizeRequest{
+		Type:        llm.SummaryTypeChange,
+		Context:     context,
+		Constraints: llm.Constraints{MaxWords: changeWords},
+	}
+}
+
+// changedLines counts the added and removed lines of a unified diff.
+func changedLines(patch string) int {
+	count := 0
+	for _, line := range strings.Split(patch, "\n") {
+		if (strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++")) ||
+			(strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---")) {
+			count++
+		}
+	}
+	return count
+}
+
+// changeModule is the directory of the slash path file, at most
+// moduleDepth levels deep.
+func changeModule(file string) string {
+	parts := strings.Split(path.Dir(file), "/")
+	if len(parts) > moduleDepth {
+		parts = parts[:moduleDepth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// Markdown renders the changes as a changelog grouped by module.
+func (c *Changes) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Changes from %s to %s\n\n", c.From, c.To)
+	if len(c.Modules) == 0 {
+		b.WriteString("No changes.\n")
+		return b.String()
+	}
+	if c.Overview != "" {
+		b.WriteString(strings.TrimSpace(c.Overview) + "\n\n")
+	}
+
+	for _, module := range c.Modules {
+		name := "/" + module.Module
+		if module.Module == "." {
+			name = "Repository root"
+		}
+		fmt.Fprintf(&b, "## %s\n\n", name)
+		for _, file := range module.Files {
+			fmt.Fprintf(&b, "- `%s`", file.Path)
+			switch file.Status {
+			case "renamed":
+				fmt.Fprintf(&b, " (renamed from `%s`)", file.OldPath)
+			case "added", "deleted":
+				fmt.Fprintf(&b, " (%s)", file.Status)
+			}
+			if file.Summary != "" {
+				fmt.Fprintf(&b, ": %s", strings.Join(strings.Fields(file.Summary), " "))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+func TestSummarizeChanges(t *testing.T) {
+	changes := []util.FileChange{
+		{Path: "README.md", Status: "modified", Patch: "@@ -1 +1 @@\n-Shop\n+The shop\n"},
+		{Path: "internal/billing/tax/rates.go", Status: "added", Patch: "@@ -0,0 +1,3 @@\n+package tax\n+\n+var Rate = 0.2\n"},
+		{Path: "internal/billing/tax/old.go", OldPath: "internal/billing/legacy.go", Status: "renamed", Patch: "@@ -1 +1 @@\n-package billing\n+package tax\n"},
+		{Path: "logo.png", Status: "deleted"},
+		{Path: "vendor/lib/lib.go", Status: "modified", Patch: "@@ -1 +1 @@\n-a\n+b\n"},
+	}
+
+	var overview llm.SummarizeRequest
+	summarized := []string{}
+	provider := ProviderFunc(func(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+		if request.Type == llm.SummaryTypeChangelog {
+			overview = request
+			return llm.SummarizeResponse{Summary: "Adds tax rates."}, nil
+		}
+		file := strings.TrimPrefix(strings.SplitN(request.Context, "\n", 2)[0], "File: ")
+		summarized = append(summarized, file)
+		return llm.SummarizeResponse{Summary: "Changes " + file + "."}, nil
+	})
+
+	result, err := SummarizeChanges(context.Background(), Options{LLMProvider: provider}, "v1.0.0", "HEAD", changes, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The two most changed files; binary and vendored files are skipped.
+	if strings.Join(summarized, ",") != "internal/billing/tax/rates.go,README.md" {
+		t.Errorf("summarized %v", summarized)
+	}
+	if !strings.Contains(overview.Context, "Module internal/billing/tax:\n- internal/billing/tax/rates.go (added): Changes internal/billing/tax/rates.go.\n- internal/billing/tax/old.go (renamed)\n") {
+		t.Errorf("overview context:\n%s", overview.Context)
+	}
+
+	markdown := result.Markdown()
+	for _, want := range []string{
+		"# Changes from v1.0.0 to HEAD\n\nAdds tax rates.\n",
+		"## Repository root\n\n- `README.md`: Changes README.md.\n- `logo.png` (deleted)\n",
+		"## /internal/billing/tax\n\n- `internal/billing/tax/rates.go` (added): Changes internal/billing/tax/rates.go.\n" +
+			"- `internal/billing/tax/old.go` (renamed from `internal/billing/legacy.go`)\n",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown lacks %q:\n%s", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "vendor") {
+		t.Errorf("Markdown lists a vendored file:\n%s", markdown)
+	}
+
+	empty, err := SummarizeChanges(context.Background(), Options{LLMProvider: provider}, "v1.0.0", "v1.0.0", nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(empty.Markdown(), "No changes.") {
+		t.Errorf("Markdown() = %q", empty.Markdown())
+	}
+}
@@ -0,0 +1,180 @@
+package summarize
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+const checkpointVersion = 1
+
+// Checkpoint records every summary a run has received so a crashed or
+// cancelled run can resume without repeating LLM requests. Unlike the LLM
+// cache it is honoured with --force, because it only holds responses from
+// the run being resumed.
+type Checkpoint struct {
+	path string
+
+	mu        sync.Mutex
+	responses map[string]llm.SummarizeResponse
+	resumed   int
+}
+
+type checkpointFile struct {
+	Version   int                              `json:"version"`
+	Responses map[string]llm.SummarizeResponse `json:"responses"`
+}
+
+// CheckpointPath returns where the checkpoint for the run writing outputFile
+// from repoPath is kept under cacheDir.
+func CheckpointPath(cacheDir, repoPath, outputFile string) string {
+	absRepo, _ := filepath.Abs(repoPath)
+	absOut, _ := filepath.Abs(outputFile)
+	sum := sha256.Sum256([]byte(absRepo + "\x00" + absOut))
+	return filepath.Join(cacheDir, "checkpoints", hex.EncodeToString(sum[:8])+".json")
+}
+
+// OpenCheckpoint starts a checkpoint at path. With resume it loads the
+// responses a previous run saved there; otherwise any saved progress is
+// discarded.
+func OpenCheckpoint(path string, resume bool) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, responses: make(map[string]llm.SummarizeResponse)}
+	if !resume {
+		return c, c.Remove()
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var file checkpointFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if file.Version != checkpointVersion {
+		return c, nil
+	}
+	if file.Responses != nil {
+		c.responses = file.Responses
+	}
+	return c, nil
+}
+
+// Saved is the number of responses available to resume from.
+func (c *Checkpoint) Saved() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.responses)
+}
+
+// Resumed is the number of requests answered from the checkpoint.
+func (c *Checkpoint) Resumed() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resumed
+}
+
+// Remove deletes the checkpoint once the run has completed.
+func (c *Checkpoint) Remove() error {
+	if c == nil {
+		return nil
+	}
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (c *Checkpoint) lookup(key string) (llm.SummarizeResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	response, ok := c.responses[key]
+	if ok {
+		c.resumed++
+	}
+	return response, ok
+}
+
+// record stores response and rewrites the checkpoint file, through a
+// temporary file so a crash mid-write keeps the previous state.
+func (c *Checkpoint) record(key string, response llm.SummarizeResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[key] = response
+
+	data, err := json.Marshal(checkpointFile{Version: checkpointVersion, Responses: c.responses})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// checkpointKey identifies a request by its type and content, so entries for
+// files that changed since the checkpoint was written are never reused.
+func checkpointKey(request llm.SummarizeRequest) string {
+	key := request.CacheKey
+	if key == "" {
+		sum := sha256.Sum256([]byte(request.Context))
+		key = hex.EncodeToString(sum[:])
+	}
+	return string(request.Type) + ":" + key
+}
+
+// checkpointProvider answers requests from the checkpoint and records the
+// responses of the ones it forwards.
+type checkpointProvider struct {
+	provider   llm.Provider
+	checkpoint *Checkpoint
+}
+
+func (p *checkpointProvider) Summarize(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+	key := checkpointKey(request)
+	if !request.SkipCache {
+		if response, ok := p.checkpoint.lookup(key); ok {
+			return response, nil
+		}
+	}
+
+	response, err := p.provider.Summarize(ctx, request)
+	if err != nil {
+		return response, err
+	}
+	if err := p.checkpoint.record(key, response); err != nil {
+		slog.WarnContext(ctx, "checkpoint write failed", "path", p.checkpoint.path, "err", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,72 @@
+package summarize
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Summarize(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+	p.calls++
+	return llm.SummarizeResponse{Summary: "summary of " + request.CacheKey}, nil
+}
+
+func TestCheckpointResume(t *testing.T) {
+	path := CheckpointPath(t.TempDir(), "/repo", "REPORT.md")
+	request := llm.SummarizeRequest{Type: llm.SummaryTypeFile, CacheKey: "abc"}
+
+	first, err := OpenCheckpoint(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := &countingProvider{}
+	provider := &checkpointProvider{provider: inner, checkpoint: first}
+	if _, err := provider.Summarize(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Saved() != 1 {
+		t.Fatalf("Saved() = %d, want 1", resumed.Saved())
+	}
+	provider = &checkpointProvider{provider: inner, checkpoint: resumed}
+	response, err := provider.Summarize(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 1 || response.Summary != "summary of abc" || resumed.Resumed() != 1 {
+		t.Errorf("Expected the saved response without a new request, got %q after %d calls", response.Summary, inner.calls)
+	}
+
+	other := llm.SummarizeRequest{Type: llm.SummaryTypeFile, CacheKey: "changed"}
+	if _, err := provider.Summarize(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("Expected a request for a file not in the checkpoint, got %d calls", inner.calls)
+	}
+
+	fresh, err := OpenCheckpoint(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.Saved() != 0 {
+		t.Errorf("Expected a run without --resume to start empty, got %d saved", fresh.Saved())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected the old checkpoint to be discarded")
+	}
+
+	if err := resumed.Remove(); err != nil {
+		t.Errorf("Remove of a missing checkpoint failed: %v", err)
+	}
+}
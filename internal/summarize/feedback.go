@@ -0,0 +1,61 @@
+package summarize
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/codepigeon/codedoc/internal/feedback"
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+// maxExamples bounds the rejected summaries added to each prompt.
+const maxExamples = 3
+
+// feedbackProvider answers file summaries a reviewer corrected while the file
+// is unchanged, and shows the model earlier rejections for everything else.
+type feedbackProvider struct {
+	provider llm.Provider
+	store    *feedback.Store
+	// files maps a file's content hash to its path.
+	files map[string]string
+}
+
+func newFeedbackProvider(opts Options, provider llm.Provider) *feedbackProvider {
+	files := make(map[string]string)
+	for _, file := range opts.ScanResult.Files {
+		files[file.Hash] = filepath.ToSlash(file.RelativePath)
+	}
+	return &feedbackProvider{provider: provider, store: opts.Feedback, files: files}
+}
+
+func (p *feedbackProvider) Summarize(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+	if request.Type == llm.SummaryTypeFile && !request.SkipCache {
+		if correction, ok := p.store.Correction(p.files[request.CacheKey], request.CacheKey); ok {
+			return llm.SummarizeResponse{Summary: correction, Cached: true}, nil
+		}
+	}
+	request.Examples = p.store.Examples(request.Type, maxExamples)
+	return p.provider.Summarize(ctx, request)
+}
+
+// Feedback records a verdict on item, whose text as generated was summary.
+// Corrections of file summaries are tied to the file's current content.
+func (item ReviewItem) Feedback(opts Options, rating feedback.Rating, summary, correction, note string) feedback.Entry {
+	entry := feedback.Entry{
+		Type:       item.Type,
+		Key:        item.Key,
+		Rating:     rating,
+		Summary:    summary,
+		Correction: correction,
+		Note:       note,
+	}
+	if item.Type == llm.SummaryTypeFile {
+		for _, file := range opts.ScanResult.Files {
+			if filepath.ToSlash(file.RelativePath) == filepath.ToSlash(item.Key) {
+				entry.Hash = file.Hash
+				break
+			}
+		}
+	}
+	return entry
+}
@@ -0,0 +1,72 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/glossary"
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+// defineTerms asks the model to define the mined glossary terms from the
+// identifiers using them.
+func defineTerms(ctx context.Context, opts Options, result *Result) {
+	if len(opts.Glossary) == 0 || ctx.Err() != nil {
+		return
+	}
+	response, err := opts.LLMProvider.Summarize(ctx, glossaryRequest(opts))
+	opts.Progress.Advance("glossary")
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.WarnContext(ctx, "glossary definitions skipped", "err", err)
+		}
+		return
+	}
+	result.Glossary = parseTermBullets(response.Summary, opts.Glossary)
+}
+
+func glossaryRequest(opts Options) llm.SummarizeRequest {
+	parts := []string{fmt.Sprintf("Project: %s", opts.ScanResult.RepoMetadata.Name), ""}
+	for _, term := range opts.Glossary {
+		parts = append(parts, fmt.Sprintf("%s (%s): used in %s; files %s",
+			term.Term, term.Kind, strings.Join(term.Examples, ", "), strings.Join(term.Files, ", ")))
+	}
+	return llm.SummarizeRequest{
+		Type:    llm.SummaryTypeGlossary,
+		Context: strings.Join(parts, "\n"),
+		Constraints: llm.Constraints{
+			MaxBullets: len(opts.Glossary),
+		},
+	}
+}
+
+// parseTermBullets matches "- term — definition" lines back to the terms,
+// ignoring case.
+func parseTermBullets(summary string, terms []glossary.Term) map[string]string {
+	byKey := make(map[string]string, len(terms))
+	for _, term := range terms {
+		byKey[strings.ToLower(term.Term)] = term.Term
+	}
+
+	definitions := make(map[string]string)
+	for _, line := range strings.Split(summary, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "*") {
+			continue
+		}
+		line = strings.TrimSpace(line[1:])
+		for _, separator := range []string{" — ", " - ", ": "} {
+			key, definition, ok := strings.Cut(line, separator)
+			if !ok {
+				continue
+			}
+			if term, ok := byKey[strings.ToLower(strings.Trim(strings.TrimSpace(key), "`*"))]; ok {
+				definitions[term] = strings.TrimSpace(definition)
+				break
+			}
+		}
+	}
+	return definitions
+}
@@ -0,0 +1,179 @@
+package summarize
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// defaultTopFiles is how many files are summarized when Options.TopFiles is
+// unset.
+const defaultTopFiles = 10
+
+// Importance weights. Fan-in and entrypoint proximity say how central a file
+// is, complexity and size how much there is to explain, and churn how much
+// it is being worked on.
+const (
+	fanInWeight     = 0.3
+	proximityWeight = 0.2
+	complexWeight   = 0.2
+	sizeWeight      = 0.15
+	churnWeight     = 0.15
+)
+
+// entrypointNames mark entrypoints detection may have missed.
+var entrypointNames = map[string]bool{
+	"main.go": true, "main.py": true, "app.py": true, "index.js": true, "server.js": true,
+}
+
+// importanceScores rates files from 0 to 1, each signal relative to the
+// file scoring highest on it. Without an import graph or history those
+// signals count as zero for every file.
+func importanceScores(opts Options, files []scanner.FileInfo) map[string]float64 {
+	entrypoints := []string{}
+	for _, entrypoint := range opts.DetectionResult.Entrypoints {
+		entrypoints = append(entrypoints, filepath.ToSlash(entrypoint.Path))
+	}
+	for _, file := range opts.ScanResult.Files {
+		if entrypointNames[filepath.Base(file.RelativePath)] {
+			entrypoints = append(entrypoints, filepath.ToSlash(file.RelativePath))
+		}
+	}
+
+	// distance is the fewest imports from an entrypoint to each file.
+	distance := map[string]int{}
+	queue := []string{}
+	for _, entrypoint := range entrypoints {
+		if _, seen := distance[entrypoint]; !seen {
+			distance[entrypoint] = 0
+			queue = append(queue, entrypoint)
+		}
+	}
+	for len(queue) > 0 && opts.Graph != nil {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range opts.Graph.Imports(current) {
+			if _, seen := distance[next]; !seen {
+				distance[next] = distance[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	churn := map[string]int{}
+	if opts.History != nil {
+		for _, file := range opts.History.Files {
+			churn[file.Path] = file.Commits
+		}
+	}
+
+	fanIn := func(file scanner.FileInfo) float64 {
+		if opts.Graph == nil {
+			return 0
+		}
+		return float64(len(opts.Graph.ImportedBy(file.RelativePath)))
+	}
+	signals := []struct {
+		weight float64
+		value  func(file scanner.FileInfo) float64
+	}{
+		{fanInWeight, fanIn},
+		{complexWeight, func(file scanner.FileInfo) float64 { return float64(file.Complexity.Cyclomatic) }},
+		{sizeWeight, func(file scanner.FileInfo) float64 { return math.Log1p(float64(file.Lines)) }},
+		{churnWeight, func(file scanner.FileInfo) float64 { return float64(churn[filepath.ToSlash(file.RelativePath)]) }},
+		{proximityWeight, func(file scanner.FileInfo) float64 {
+			if d, ok := distance[filepath.ToSlash(file.RelativePath)]; ok {
+				return 1 / float64(1+d)
+			}
+			return 0
+		}},
+	}
+
+	scores := make(map[string]float64, len(files))
+	for _, signal := range signals {
+		highest := 0.0
+		for _, file := range files {
+			highest = max(highest, signal.value(file))
+		}
+		if highest == 0 {
+			continue
+		}
+		for _, file := range files {
+			scores[file.RelativePath] += signal.weight * signal.value(file) / highest
+		}
+	}
+	return scores
+}
+
+// selectTopFiles picks the pinned files, in their order, then the most
+// important of the rest, overall or per module. Tests are left out.
+func selectTopFiles(opts Options) []scanner.FileInfo {
+	limit := opts.TopFiles
+	if limit <= 0 {
+		limit = defaultTopFiles
+	}
+
+	selected := []scanner.FileInfo{}
+	pinned := map[string]bool{}
+	for _, path := range opts.PriorityFiles {
+		for _, file := range opts.ScanResult.Files {
+			if filepath.ToSlash(file.RelativePath) == filepath.ToSlash(path) && !pinned[file.RelativePath] {
+				selected = append(selected, file)
+				pinned[file.RelativePath] = true
+				break
+			}
+		}
+	}
+
+	rest := []scanner.FileInfo{}
+	for _, file := range opts.ScanResult.Files {
+		if !file.IsTest && !pinned[file.RelativePath] {
+			rest = append(rest, file)
+		}
+	}
+	scores := importanceScores(opts, rest)
+	sort.SliceStable(rest, func(i, j int) bool {
+		return scores[rest[i].RelativePath] > scores[rest[j].RelativePath]
+	})
+
+	if opts.FilesPerModule > 0 {
+		return append(selected, moduleTopFiles(opts, rest)...)
+	}
+	selected = append(selected, rest...)
+	return selected[:min(limit, len(selected))]
+}
+
+// moduleTopFiles takes the opts.FilesPerModule best of ranked in every
+// module, files outside all modules counting as one more. They are
+// interleaved by rank, so a token budget that runs out still leaves each
+// module its most important files.
+func moduleTopFiles(opts Options, ranked []scanner.FileInfo) []scanner.FileInfo {
+	modules := moduleDirs(opts.ScanResult.Files)
+	if len(opts.Modules) > 0 {
+		modules = opts.keyModules()
+	}
+
+	groups := map[string][]scanner.FileInfo{}
+	order := []string{}
+	for _, file := range ranked {
+		module := moduleOf(file.RelativePath, modules)
+		if _, seen := groups[module]; !seen {
+			order = append(order, module)
+		}
+		if len(groups[module]) < opts.FilesPerModule {
+			groups[module] = append(groups[module], file)
+		}
+	}
+
+	selected := []scanner.FileInfo{}
+	for rank := 0; rank < opts.FilesPerModule; rank++ {
+		for _, module := range order {
+			if rank < len(groups[module]) {
+				selected = append(selected, groups[module][rank])
+			}
+		}
+	}
+	return selected
+}
@@ -0,0 +1,61 @@
+package summarize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/history"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestSelectTopFiles(t *testing.T) {
+	files := []scanner.FileInfo{
+		{RelativePath: "README.md", Language: "markdown", Lines: 60},
+		{RelativePath: "internal/util/pad.go", Language: "go", Lines: 5, Complexity: scanner.Complexity{Cyclomatic: 1}},
+		{RelativePath: "internal/report/report.go", Language: "go", Lines: 400, Complexity: scanner.Complexity{Cyclomatic: 30}},
+		{RelativePath: "internal/orders/orders.go", Language: "go", Lines: 150, Complexity: scanner.Complexity{Cyclomatic: 25}},
+		{RelativePath: "internal/orders/orders_test.go", Language: "go", IsTest: true, Lines: 300, Complexity: scanner.Complexity{Cyclomatic: 60}},
+		{RelativePath: "internal/api/api.go", Language: "go", Lines: 80, Complexity: scanner.Complexity{Cyclomatic: 8},
+			Imports: []string{"example.com/shop/internal/orders"}},
+		{RelativePath: "cmd/shop/main.go", Language: "go", Lines: 20, Complexity: scanner.Complexity{Cyclomatic: 2},
+			Imports: []string{"example.com/shop/internal/api", "example.com/shop/internal/orders"}},
+	}
+	opts := Options{
+		ScanResult:      &scanner.Result{Files: files},
+		DetectionResult: &detect.Result{Entrypoints: []detect.Entrypoint{{Path: "cmd/shop/main.go"}}},
+		Graph:           graph.Build(files, "example.com/shop"),
+		History:         &history.Result{Files: []history.File{{Path: "internal/orders/orders.go", Commits: 12}}},
+		TopFiles:        3,
+	}
+
+	tests := []struct {
+		name      string
+		priority  []string
+		modules   []string
+		perModule int
+		want      string
+	}{
+		{"scored", nil, nil, 0, "internal/orders/orders.go|internal/api/api.go|internal/report/report.go"},
+		{"pinned", []string{"internal/util/pad.go", "gone.go"}, nil, 0, "internal/util/pad.go|internal/orders/orders.go|internal/api/api.go"},
+		{"per module", nil, []string{"internal/report", "internal/util", "internal"}, 1,
+			"internal/orders/orders.go|internal/report/report.go|cmd/shop/main.go|internal/util/pad.go"},
+		{"per module ranks interleaved", []string{"cmd/shop/main.go"}, []string{"internal/orders", "internal"}, 2,
+			"cmd/shop/main.go|internal/orders/orders.go|internal/api/api.go|README.md|internal/report/report.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts.PriorityFiles = tt.priority
+			opts.Modules = tt.modules
+			opts.FilesPerModule = tt.perModule
+			got := []string{}
+			for _, file := range selectTopFiles(opts) {
+				got = append(got, file.RelativePath)
+			}
+			if strings.Join(got, "|") != tt.want {
+				t.Errorf("selectTopFiles() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
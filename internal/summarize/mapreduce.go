@@ -0,0 +1,114 @@
+package summarize
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// Map-reduce limits: module summaries read this many of each module's most
+// important files, up to this many lines of each.
+const (
+	excerptFiles = 2
+	excerptLines = 60
+)
+
+// MapReduceStages returns DefaultStages with the module summaries first, so
+// that with Options.MapReduce the architecture summary is built from them.
+func MapReduceStages() []Stage {
+	stages := []Stage{}
+	var modules Stage
+	for _, stage := range DefaultStages() {
+		if stage.Name == "module summary" {
+			modules = stage
+			continue
+		}
+		stages = append(stages, stage)
+	}
+	return append([]Stage{modules}, stages...)
+}
+
+// moduleExcerpts samples the code of module's most important files.
+func moduleExcerpts(opts Options, module string) []string {
+	files := []scanner.FileInfo{}
+	for _, file := range opts.ScanResult.Files {
+		if !file.IsTest && strings.HasPrefix(file.RelativePath, module+string(filepath.Separator)) {
+			files = append(files, file)
+		}
+	}
+	scores := importanceScores(opts, files)
+	sort.SliceStable(files, func(i, j int) bool {
+		return scores[files[i].RelativePath] > scores[files[j].RelativePath]
+	})
+
+	excerpts := []string{}
+	for _, file := range files {
+		if len(excerpts) == excerptFiles {
+			break
+		}
+		context, err := buildFileContext(file, excerptLines)
+		if err != nil {
+			continue
+		}
+		excerpts = append(excerpts, context)
+	}
+	return excerpts
+}
+
+// moduleOverview is the reduce step's input: the module summaries and
+// which modules import which.
+func moduleOverview(opts Options, summaries map[string]string) string {
+	modules := slices.Sorted(maps.Keys(summaries))
+	parts := []string{"\nModule summaries (written from each module's code; base the overview on these):"}
+	for _, module := range modules {
+		parts = append(parts, fmt.Sprintf("- %s: %s", filepath.ToSlash(module), strings.Join(strings.Fields(summaries[module]), " ")))
+	}
+
+	if opts.Graph == nil {
+		return strings.Join(parts, "\n")
+	}
+	dependencies := map[string]map[string]bool{}
+	for _, file := range opts.ScanResult.Files {
+		from := moduleOf(file.RelativePath, modules)
+		if from == "" {
+			continue
+		}
+		for _, imported := range opts.Graph.Imports(file.RelativePath) {
+			to := moduleOf(filepath.FromSlash(imported), modules)
+			if to == "" || to == from {
+				continue
+			}
+			if dependencies[from] == nil {
+				dependencies[from] = map[string]bool{}
+			}
+			dependencies[from][to] = true
+		}
+	}
+	if len(dependencies) > 0 {
+		parts = append(parts, "\nModule dependencies:")
+		for _, from := range slices.Sorted(maps.Keys(dependencies)) {
+			targets := []string{}
+			for _, to := range slices.Sorted(maps.Keys(dependencies[from])) {
+				targets = append(targets, filepath.ToSlash(to))
+			}
+			parts = append(parts, fmt.Sprintf("- %s imports %s", filepath.ToSlash(from), strings.Join(targets, ", ")))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// moduleOf returns the most specific of modules containing path, or "".
+func moduleOf(path string, modules []string) string {
+	module := ""
+	for _, candidate := range modules {
+		if strings.HasPrefix(path, candidate+string(filepath.Separator)) && len(candidate) > len(module) {
+			module = candidate
+		}
+	}
+	return module
+}
@@ -0,0 +1,83 @@
+package summarize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestMapReduce(t *testing.T) {
+	dir := t.TempDir()
+	files := []scanner.FileInfo{}
+	for _, name := range []string{"api/server.go", "api/routes.go", "api/auth.go", "store/db.go", "store/orders.go", "store/users.go"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := "package " + filepath.Base(filepath.Dir(path)) + "\n\nfunc " + strings.TrimSuffix(filepath.Base(name), ".go") + "() {}\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		file := scanner.FileInfo{Path: path, RelativePath: filepath.FromSlash(name), Language: "go", Lines: 3}
+		if strings.HasPrefix(name, "api/") {
+			file.Imports = []string{"example.com/shop/store"}
+		}
+		files = append(files, file)
+	}
+
+	requests := []llm.SummarizeRequest{}
+	provider := ProviderFunc(func(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+		requests = append(requests, request)
+		summary := string(request.Type) + " summary"
+		if request.Type == llm.SummaryTypeModule {
+			summary = strings.SplitN(request.Context, "\n", 2)[0] + " handles things"
+		}
+		return llm.SummarizeResponse{Summary: summary}, nil
+	})
+
+	opts := Options{
+		ScanResult:      &scanner.Result{Files: files, TotalFiles: len(files)},
+		DetectionResult: &detect.Result{},
+		MaxLinesPerFile: 100,
+		LLMProvider:     provider,
+		Graph:           graph.Build(files, "example.com/shop"),
+		MapReduce:       true,
+	}
+	if _, err := Summarize(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) < 3 || requests[0].Type != llm.SummaryTypeModule || requests[1].Type != llm.SummaryTypeModule {
+		t.Fatalf("Expected both module summaries first, got %+v", requests)
+	}
+	if !strings.Contains(requests[0].Context, "Content sample:") {
+		t.Errorf("Module context has no code excerpt:\n%s", requests[0].Context)
+	}
+	var architecture string
+	for _, request := range requests {
+		if request.Type == llm.SummaryTypeArchitecture {
+			architecture = request.Context
+		}
+	}
+	for _, want := range []string{"- api: Module: api handles things", "- store: Module: store handles things", "- api imports store"} {
+		if !strings.Contains(architecture, want) {
+			t.Errorf("Architecture context lacks %q:\n%s", want, architecture)
+		}
+	}
+
+	opts.MapReduce = false
+	requests = nil
+	if _, err := Summarize(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if requests[0].Type != llm.SummaryTypeArchitecture || strings.Contains(requests[0].Context, "Module summaries") {
+		t.Errorf("Expected the statistics-only architecture summary first without MapReduce, got %+v", requests[0])
+	}
+}
@@ -0,0 +1,234 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/progress"
+)
+
+// Stage is one step of Summarize, such as the module summaries. Stages run
+// in order, each filling in its part of the Result with requests to the
+// provider, through the middleware.
+type Stage struct {
+	Name string
+	// Requests is how many requests Run makes, for the progress total.
+	Requests func(opts Options) int
+	// Run returns an error only when the whole summary must fail; a summary
+	// it cannot get is skipped or replaced by a fallback.
+	Run func(ctx context.Context, opts Options, result *Result) error
+}
+
+// DefaultStages returns the built-in stages in the order Summarize runs
+// them.
+func DefaultStages() []Stage {
+	one := func(Options) int { return 1 }
+	return []Stage{
+		{Name: "architecture summary", Requests: one, Run: summarizeArchitecture},
+		{Name: "tag classification", Requests: one, Run: optional(classifyRepository)},
+		{
+			Name:     "module summary",
+			Requests: func(opts Options) int { return len(opts.keyModules()) },
+			Run:      summarizeModules,
+		},
+		{
+			Name:     "file summary",
+			Requests: func(opts Options) int { return len(selectTopFiles(opts)) },
+			Run:      summarizeTopFiles,
+		},
+		{Name: "quickstart generation", Requests: one, Run: generateQuickstart},
+		{
+			Name: "config summary",
+			Requests: func(opts Options) int {
+				count := 0
+				for _, config := range opts.DetectionResult.ConfigFiles {
+					if len(config.Sections) > 0 {
+						count++
+					}
+				}
+				return count
+			},
+			Run: optional(summarizeConfigFiles),
+		},
+		{Name: "decomposition assessment", Requests: func(opts Options) int { return min(len(opts.Seams), 1) }, Run: optional(assessSeams)},
+		{Name: "glossary", Requests: func(opts Options) int { return min(len(opts.Glossary), 1) }, Run: optional(defineTerms)},
+	}
+}
+
+// optional adapts a stage that logs and skips its own failures.
+func optional(run func(ctx context.Context, opts Options, result *Result)) func(context.Context, Options, *Result) error {
+	return func(ctx context.Context, opts Options, result *Result) error {
+		run(ctx, opts, result)
+		return nil
+	}
+}
+
+// Middleware wraps the provider the stages call. It can rewrite requests
+// before they are sent, check responses, or observe both.
+type Middleware func(next llm.Provider) llm.Provider
+
+// ProviderFunc lets a function serve as an llm.Provider.
+type ProviderFunc func(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error)
+
+func (f ProviderFunc) Summarize(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+	return f(ctx, request)
+}
+
+// Chain wraps provider in middleware. The first middleware sees each request
+// first and each response last.
+func Chain(provider llm.Provider, middleware ...Middleware) llm.Provider {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		provider = middleware[i](provider)
+	}
+	return provider
+}
+
+// TransformRequests returns middleware that rewrites every request before
+// it is sent.
+func TransformRequests(transform func(request llm.SummarizeRequest) llm.SummarizeRequest) Middleware {
+	return func(next llm.Provider) llm.Provider {
+		return ProviderFunc(func(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+			return next.Summarize(ctx, transform(request))
+		})
+	}
+}
+
+// ValidateResponses returns middleware that turns responses failing
+// validate into errors, which stages handle like a failed request.
+func ValidateResponses(validate func(request llm.SummarizeRequest, response llm.SummarizeResponse) error) Middleware {
+	return func(next llm.Provider) llm.Provider {
+		return ProviderFunc(func(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+			response, err := next.Summarize(ctx, request)
+			if err != nil {
+				return response, err
+			}
+			if err := validate(request, response); err != nil {
+				return llm.SummarizeResponse{}, fmt.Errorf("invalid %s summary: %w", request.Type, err)
+			}
+			return response, nil
+		})
+	}
+}
+
+// Usage tallies LLM requests.
+type Usage struct {
+	Requests int
+	Cached   int
+	Failed   int
+	Tokens   int
+	Duration time.Duration
+}
+
+func (u Usage) sub(before Usage) Usage {
+	return Usage{
+		Requests: u.Requests - before.Requests,
+		Cached:   u.Cached - before.Cached,
+		Failed:   u.Failed - before.Failed,
+		Tokens:   u.Tokens - before.Tokens,
+		Duration: u.Duration - before.Duration,
+	}
+}
+
+// Metrics counts the requests that pass through its Middleware.
+type Metrics struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+func (m *Metrics) Middleware() Middleware {
+	return func(next llm.Provider) llm.Provider {
+		return ProviderFunc(func(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+			started := time.Now()
+			response, err := next.Summarize(ctx, request)
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.usage.Requests++
+			m.usage.Duration += time.Since(started)
+			switch {
+			case err != nil:
+				m.usage.Failed++
+			case response.Cached:
+				m.usage.Cached++
+			default:
+				m.usage.Tokens += response.Tokens
+			}
+			return response, err
+		})
+	}
+}
+
+// Usage returns the tally so far.
+func (m *Metrics) Usage() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// provider wraps opts.LLMProvider in the built-in middleware, innermost
+// first: secret redaction, reviewer feedback, then the checkpoint, which
+// answers resumed requests before either runs. opts.Middleware wraps them
+// all. Once ctx is cancelled no request reaches opts.LLMProvider; stages
+// handle that like any failed request.
+func (opts Options) provider() llm.Provider {
+	next := opts.LLMProvider
+	if next == nil {
+		next = llm.NewNoOpProvider()
+	}
+	var provider llm.Provider = ProviderFunc(func(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+		if err := ctx.Err(); err != nil {
+			return llm.SummarizeResponse{}, err
+		}
+		// Long summaries show on the progress bar as they are written. Only
+		// the end of the response is kept, so each delta costs the same.
+		if opts.Progress.Live() {
+			var tail []rune
+			ctx = llm.WithStream(ctx, func(_ llm.SummaryType, text string) {
+				tail = append(tail, []rune(text)...)
+				if len(tail) > progress.PreviewWindow {
+					tail = tail[len(tail)-progress.PreviewWindow:]
+				}
+				opts.Progress.Preview(string(tail))
+			})
+		}
+		return next.Summarize(ctx, request)
+	})
+	if opts.RedactSecrets {
+		provider = TransformRequests(redactRequest)(provider)
+	}
+	if opts.Feedback != nil {
+		provider = newFeedbackProvider(opts, provider)
+	}
+	if opts.Checkpoint != nil {
+		provider = &checkpointProvider{provider: provider, checkpoint: opts.Checkpoint}
+	}
+	return Chain(provider, opts.Middleware...)
+}
+
+// runStages runs stages in order, logging each one's usage.
+func runStages(ctx context.Context, opts Options, stages []Stage, result *Result) error {
+	metrics := &Metrics{}
+	opts.LLMProvider = Chain(opts.provider(), metrics.Middleware())
+
+	total := 0
+	for _, stage := range stages {
+		total += stage.Requests(opts)
+	}
+	opts.Progress.Stage("summarize", total)
+
+	for _, stage := range stages {
+		before := metrics.Usage()
+		err := stage.Run(ctx, opts, result)
+		usage := metrics.Usage().sub(before)
+		slog.DebugContext(ctx, "summarize stage finished", "stage", stage.Name, "requests", usage.Requests,
+			"cached", usage.Cached, "failed", usage.Failed, "tokens", usage.Tokens, "duration", usage.Duration.Round(time.Millisecond))
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("%s failed: %w", stage.Name, err)
+		}
+	}
+	return nil
+}
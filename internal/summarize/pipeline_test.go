@@ -0,0 +1,105 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func TestPipeline(t *testing.T) {
+	seen := []string{}
+	echo := ProviderFunc(func(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+		seen = append(seen, request.Context)
+		return llm.SummarizeResponse{Summary: request.Context, Tokens: 10}, nil
+	})
+	tag := func(label string) Middleware {
+		return TransformRequests(func(request llm.SummarizeRequest) llm.SummarizeRequest {
+			request.Context += " " + label
+			return request
+		})
+	}
+	rejectEmpty := ValidateResponses(func(request llm.SummarizeRequest, response llm.SummarizeResponse) error {
+		if strings.Contains(response.Summary, "empty") {
+			return errors.New("no content")
+		}
+		return nil
+	})
+
+	metrics := &Metrics{}
+	opts := Options{
+		ScanResult:      &scanner.Result{},
+		DetectionResult: &detect.Result{},
+		LLMProvider:     echo,
+		Middleware:      []Middleware{metrics.Middleware(), tag("first"), tag("second"), rejectEmpty},
+		Stages: []Stage{
+			{
+				Name:     "overview",
+				Requests: func(Options) int { return 1 },
+				Run: func(ctx context.Context, opts Options, result *Result) error {
+					response, err := opts.LLMProvider.Summarize(ctx, llm.SummarizeRequest{Type: llm.SummaryTypeArchitecture, Context: "repo"})
+					result.ArchitectureSummary = response.Summary
+					return err
+				},
+			},
+			{
+				Name:     "notes",
+				Requests: func(Options) int { return 1 },
+				Run: func(ctx context.Context, opts Options, result *Result) error {
+					_, err := opts.LLMProvider.Summarize(ctx, llm.SummarizeRequest{Type: llm.SummaryTypeModule, Context: "empty"})
+					return err
+				},
+			},
+		},
+	}
+
+	_, err := Summarize(context.Background(), opts)
+	if err == nil || err.Error() != "notes failed: invalid module summary: no content" {
+		t.Fatalf("Summarize() error = %v, want the notes stage's validation error", err)
+	}
+	if want := []string{"repo first second", "empty first second"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("Provider saw %q, want %q", seen, want)
+	}
+	if got, want := metrics.Usage(), (Usage{Requests: 2, Failed: 1, Tokens: 10}); got.Requests != want.Requests || got.Failed != want.Failed || got.Tokens != want.Tokens {
+		t.Errorf("Usage() = %+v, want %+v", got, want)
+	}
+
+	opts.Stages = opts.Stages[:1]
+	result, err := Summarize(context.Background(), opts)
+	if err != nil || result.ArchitectureSummary != "repo first second" {
+		t.Errorf("Summarize() = %q, %v; want only the overview stage to run", result.ArchitectureSummary, err)
+	}
+}
+
+func TestPipelineCancelled(t *testing.T) {
+	calls := 0
+	provider := ProviderFunc(func(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+		calls++
+		return llm.SummarizeResponse{Summary: "summary"}, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := Summarize(ctx, Options{
+		ScanResult:      &scanner.Result{Files: []scanner.FileInfo{{RelativePath: "main.go", Language: "go"}}},
+		DetectionResult: &detect.Result{},
+		LLMProvider:     provider,
+	})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("provider called %d times after cancellation, want 0", calls)
+	}
+	if !result.Incomplete {
+		t.Error("Incomplete = false, want true")
+	}
+	if len(result.QuickstartSteps) == 0 {
+		t.Error("QuickstartSteps empty, want the default steps")
+	}
+}
@@ -0,0 +1,126 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+// ReviewItem is one generated summary offered for review. Key is the module
+// or file path for module and file summaries.
+type ReviewItem struct {
+	Type llm.SummaryType
+	Key  string
+	Text string
+}
+
+// Title names the item for display.
+func (item ReviewItem) Title() string {
+	if item.Key == "" {
+		return string(item.Type)
+	}
+	return fmt.Sprintf("%s %s", item.Type, item.Key)
+}
+
+// ReviewItems lists the summaries of result in report order: architecture,
+// modules, files, then the quickstart.
+func ReviewItems(result *Result) []ReviewItem {
+	items := []ReviewItem{}
+	if result.ArchitectureSummary != "" {
+		items = append(items, ReviewItem{Type: llm.SummaryTypeArchitecture, Text: result.ArchitectureSummary})
+	}
+	for _, module := range slices.Sorted(maps.Keys(result.ModuleSummaries)) {
+		items = append(items, ReviewItem{Type: llm.SummaryTypeModule, Key: module, Text: result.ModuleSummaries[module]})
+	}
+	files := make([]string, 0, len(result.FileSummaries))
+	for path := range result.FileSummaries {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	for _, path := range files {
+		items = append(items, ReviewItem{Type: llm.SummaryTypeFile, Key: path, Text: result.FileSummaries[path].Summary})
+	}
+	if len(result.QuickstartSteps) > 0 {
+		steps := make([]string, len(result.QuickstartSteps))
+		for i, step := range result.QuickstartSteps {
+			steps[i] = "- " + step
+		}
+		items = append(items, ReviewItem{Type: llm.SummaryTypeQuickstart, Text: strings.Join(steps, "\n")})
+	}
+	return items
+}
+
+// Apply stores text as the summary item refers to.
+func (r *Result) Apply(item ReviewItem, text string) {
+	text = strings.TrimSpace(text)
+	switch item.Type {
+	case llm.SummaryTypeArchitecture:
+		r.ArchitectureSummary = text
+	case llm.SummaryTypeModule:
+		r.ModuleSummaries[item.Key] = text
+	case llm.SummaryTypeFile:
+		summary := r.FileSummaries[item.Key]
+		summary.Summary = text
+		r.FileSummaries[item.Key] = summary
+	case llm.SummaryTypeQuickstart:
+		r.QuickstartSteps = parseSteps(text)
+	}
+}
+
+// Regenerate requests a fresh summary for item of result, bypassing the
+// cache. Guidance from the reviewer, when given, is passed to the model with
+// the context.
+func Regenerate(ctx context.Context, opts Options, result *Result, item ReviewItem, guidance string) (string, error) {
+	// The checkpoint only holds the run's own summaries, not reviewed ones.
+	opts.Checkpoint = nil
+	provider := opts.provider()
+
+	var request llm.SummarizeRequest
+	switch item.Type {
+	case llm.SummaryTypeArchitecture:
+		request = architectureRequest(opts, result.ModuleSummaries)
+	case llm.SummaryTypeModule:
+		request = moduleRequest(opts, item.Key)
+	case llm.SummaryTypeQuickstart:
+		request = quickstartRequest(opts)
+	case llm.SummaryTypeFile:
+		found := false
+		for _, file := range opts.ScanResult.Files {
+			if filepath.ToSlash(file.RelativePath) != filepath.ToSlash(item.Key) {
+				continue
+			}
+			context, err := buildFileContext(file, opts.MaxLinesPerFile)
+			if err != nil {
+				return "", err
+			}
+			request = fileRequest(file, context)
+			found = true
+			break
+		}
+		if !found {
+			return "", fmt.Errorf("%s is not among the scanned files", item.Key)
+		}
+	default:
+		return "", fmt.Errorf("cannot regenerate %s summaries", item.Type)
+	}
+
+	if guidance = strings.TrimSpace(guidance); guidance != "" {
+		request.Context += "\n\nReviewer guidance: " + guidance
+		// The guidance changes the answer, so it must not replace the entry
+		// other runs read.
+		request.CacheKey = ""
+	}
+	request.SkipCache = true
+
+	response, err := provider.Summarize(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	return response.Summary, nil
+}
@@ -0,0 +1,185 @@
+package summarize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/feedback"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// recordingProvider remembers the last request it answered.
+type recordingProvider struct {
+	last llm.SummarizeRequest
+}
+
+func (p *recordingProvider) Summarize(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+	p.last = request
+	return llm.SummarizeResponse{Summary: "fresh " + string(request.Type)}, nil
+}
+
+func TestReviewItemsAndApply(t *testing.T) {
+	result := &Result{
+		ArchitectureSummary: "arch",
+		ModuleSummaries:     map[string]string{"b": "module b", "a": "module a"},
+		FileSummaries:       map[string]FileSummary{"a/main.go": {Summary: "main", Functions: []string{"main"}}},
+		QuickstartSteps:     []string{"make build", "make run"},
+	}
+
+	items := ReviewItems(result)
+	titles := []string{}
+	for _, item := range items {
+		titles = append(titles, item.Title())
+	}
+	want := "architecture, module a, module b, file a/main.go, quickstart"
+	if got := strings.Join(titles, ", "); got != want {
+		t.Fatalf("ReviewItems = %s, want %s", got, want)
+	}
+
+	result.Apply(items[1], " edited module a \n")
+	result.Apply(items[3], "edited main")
+	result.Apply(items[4], "1. go build\n2. ./app")
+
+	if result.ModuleSummaries["a"] != "edited module a" {
+		t.Errorf("module a = %q", result.ModuleSummaries["a"])
+	}
+	if file := result.FileSummaries["a/main.go"]; file.Summary != "edited main" || len(file.Functions) != 1 {
+		t.Errorf("Expected the file summary edited and its functions kept, got %+v", file)
+	}
+	if strings.Join(result.QuickstartSteps, "|") != "go build|./app" {
+		t.Errorf("QuickstartSteps = %q", result.QuickstartSteps)
+	}
+}
+
+func TestRegenerateSkipsCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	provider := &recordingProvider{}
+	opts := Options{
+		ScanResult:      &scanner.Result{Files: []scanner.FileInfo{{Path: path, RelativePath: "main.go", Hash: "abc"}}},
+		DetectionResult: &detect.Result{},
+		MaxLinesPerFile: 100,
+		LLMProvider:     provider,
+	}
+
+	text, err := Regenerate(context.Background(), opts, &Result{}, ReviewItem{Type: llm.SummaryTypeFile, Key: "main.go"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "fresh file" || !provider.last.SkipCache || provider.last.CacheKey != "abc" {
+		t.Errorf("Expected a fresh file summary replacing the cached one, got %q from %+v", text, provider.last)
+	}
+
+	if _, err := Regenerate(context.Background(), opts, &Result{}, ReviewItem{Type: llm.SummaryTypeModule, Key: "."}, "mention the CLI"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(provider.last.Context, "Reviewer guidance: mention the CLI") || provider.last.CacheKey != "" {
+		t.Errorf("Expected guidance in the context and no shared cache key, got %+v", provider.last)
+	}
+
+	if _, err := Regenerate(context.Background(), opts, &Result{}, ReviewItem{Type: llm.SummaryTypeFile, Key: "gone.go"}, ""); err == nil {
+		t.Error("Expected an error for a file that was not scanned")
+	}
+}
+
+func TestFeedbackProvider(t *testing.T) {
+	store, err := feedback.Load(filepath.Join(t.TempDir(), "feedback.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{
+		ScanResult: &scanner.Result{Files: []scanner.FileInfo{
+			{RelativePath: "main.go", Hash: "h1"},
+			{RelativePath: "util.go", Hash: "h2"},
+		}},
+		Feedback: store,
+	}
+	item := ReviewItem{Type: llm.SummaryTypeFile, Key: "main.go"}
+	store.Record(item.Feedback(opts, feedback.Bad, "wrong", "right", "mention the flags"))
+
+	recording := &recordingProvider{}
+	opts.LLMProvider = recording
+	provider := newFeedbackProvider(opts, opts.LLMProvider)
+
+	response, err := provider.Summarize(context.Background(), llm.SummarizeRequest{Type: llm.SummaryTypeFile, CacheKey: "h1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Summary != "right" || recording.last.Type != "" {
+		t.Errorf("Expected the correction without an LLM request, got %q", response.Summary)
+	}
+
+	if _, err := provider.Summarize(context.Background(), llm.SummarizeRequest{Type: llm.SummaryTypeFile, CacheKey: "h2"}); err != nil {
+		t.Fatal(err)
+	}
+	if examples := recording.last.Examples; len(examples) != 1 || examples[0].Rejected != "wrong" || examples[0].Note != "mention the flags" {
+		t.Errorf("Expected the rejected summary as an example, got %+v", examples)
+	}
+
+	if _, err := provider.Summarize(context.Background(), llm.SummarizeRequest{Type: llm.SummaryTypeFile, CacheKey: "h1", SkipCache: true}); err != nil {
+		t.Fatal(err)
+	}
+	if recording.last.CacheKey != "h1" {
+		t.Error("Expected regeneration to reach the model despite the correction")
+	}
+}
+
+// memoryCache is an llm.Cache in memory that remembers the keys looked up.
+type memoryCache struct {
+	entries map[string][]byte
+	gets    []string
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.gets = append(c.gets, key)
+	data, ok := c.entries[key]
+	return data, ok, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value []byte) error {
+	c.entries[key] = value
+	return nil
+}
+
+func TestFeedbackMissesCache(t *testing.T) {
+	store, err := feedback.Load(filepath.Join(t.TempDir(), "feedback.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{
+		ScanResult: &scanner.Result{Files: []scanner.FileInfo{{RelativePath: "main.go", Hash: "h1"}}},
+		Feedback:   store,
+	}
+	cache := &memoryCache{entries: map[string][]byte{}}
+	anthropic, err := llm.NewAnthropicProvider(llm.AnthropicConfig{APIKey: "test", Cache: cache, MaxQPS: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := newFeedbackProvider(opts, anthropic)
+
+	// The context is cancelled, so only cache hits succeed.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	request := llm.SummarizeRequest{Type: llm.SummaryTypeFile, CacheKey: "h1"}
+	if _, err := provider.Summarize(ctx, request); err == nil {
+		t.Fatal("Expected a miss on an empty cache")
+	}
+	cache.entries[cache.gets[0]] = []byte(`{"summary":"old summary"}`)
+	if response, err := provider.Summarize(ctx, request); err != nil || response.Summary != "old summary" {
+		t.Fatalf("Expected the cached summary, got %q, %v", response.Summary, err)
+	}
+
+	item := ReviewItem{Type: llm.SummaryTypeFile, Key: "main.go"}
+	store.Record(item.Feedback(opts, feedback.Bad, "old summary", "", "mention the flags"))
+	if response, err := provider.Summarize(ctx, request); err == nil {
+		t.Errorf("Expected the rejection to miss the cache, got %q", response.Summary)
+	}
+}
@@ -0,0 +1,76 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/llm"
+)
+
+// maxSeamInterface bounds the interface directories listed per candidate in
+// the prompt.
+const maxSeamInterface = 5
+
+var seamBullet = regexp.MustCompile(`^[-*]\s*\**C(\d+)\**\s*(?:—|-|:)\s*(.+)$`)
+
+// assessSeams asks the model to name and assess each candidate boundary.
+func assessSeams(ctx context.Context, opts Options, result *Result) {
+	if len(opts.Seams) == 0 || ctx.Err() != nil {
+		return
+	}
+	response, err := opts.LLMProvider.Summarize(ctx, seamsRequest(opts.Seams))
+	opts.Progress.Advance("decomposition")
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.WarnContext(ctx, "decomposition assessment skipped", "err", err)
+		}
+		return
+	}
+	result.SeamAssessments = parseSeamBullets(response.Summary, len(opts.Seams))
+}
+
+func seamsRequest(seams []graph.Seam) llm.SummarizeRequest {
+	var parts []string
+	for i, seam := range seams {
+		parts = append(parts, fmt.Sprintf("C%d: %s (%d files, %.0f%% of imports internal)",
+			i+1, strings.Join(seam.Dirs, ", "), seam.Files, seam.Cohesion()*100))
+		if len(seam.Interface) > 0 {
+			parts = append(parts, "  Imported from outside: "+strings.Join(seam.Interface[:min(maxSeamInterface, len(seam.Interface))], ", "))
+		}
+		if len(seam.DependsOn) > 0 {
+			names := []string{}
+			for _, j := range seam.DependsOn {
+				names = append(names, fmt.Sprintf("C%d", j+1))
+			}
+			parts = append(parts, "  Depends on: "+strings.Join(names, ", "))
+		}
+	}
+	return llm.SummarizeRequest{
+		Type:    llm.SummaryTypeSeams,
+		Context: strings.Join(parts, "\n"),
+		Constraints: llm.Constraints{
+			MaxBullets: len(seams),
+		},
+	}
+}
+
+// parseSeamBullets maps "- C1 — assessment" lines to the candidate's index.
+func parseSeamBullets(summary string, count int) map[int]string {
+	assessments := make(map[int]string)
+	for _, line := range strings.Split(summary, "\n") {
+		match := seamBullet.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(match[1])
+		if n >= 1 && n <= count {
+			assessments[n-1] = strings.TrimSpace(match[2])
+		}
+	}
+	return assessments
+}
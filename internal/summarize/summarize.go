@@ -3,12 +3,22 @@ package summarize
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/feedback"
+	"github.com/codepigeon/codedoc/internal/glossary"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/history"
 	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/progress"
 	"github.com/codepigeon/codedoc/internal/scanner"
 )
 
@@ -18,6 +28,44 @@ type Options struct {
 	MaxLinesPerFile int
 	LLMProvider     llm.Provider
 	RedactSecrets   bool
+	// Progress, when set, is advanced once per LLM request.
+	Progress *progress.Reporter
+	// Checkpoint, when set, answers requests finished by an earlier run and
+	// records new responses.
+	Checkpoint *Checkpoint
+	// Feedback, when set, supplies reviewers' corrections from earlier runs.
+	Feedback *feedback.Store
+	// Seams, when set, are candidate module boundaries for the model to
+	// name and assess.
+	Seams []graph.Seam
+	// Glossary, when set, are mined terms for the model to define.
+	Glossary []glossary.Term
+	// Stages replace DefaultStages when set.
+	Stages []Stage
+	// Middleware wraps the provider, outside the built-in middleware, for
+	// every request the stages make.
+	Middleware []Middleware
+	// Modules, when set, replace the key modules picked from the scan.
+	// Directories without scanned files are skipped.
+	Modules []string
+	// PriorityFiles are summarized before the other top files.
+	PriorityFiles []string
+	// TopFiles is how many files get summaries; 0 means 10. Graph and
+	// History, when set, inform which files those are.
+	TopFiles int
+	Graph    *graph.Graph
+	History  *history.Result
+	// FilesPerModule, when set, replaces TopFiles: that many files are
+	// summarized in every module, so each area of a large repository is
+	// covered.
+	FilesPerModule int
+	// FileTokenBudget, when set, stops file summaries once they have spent
+	// that many tokens. Cached summaries are free.
+	FileTokenBudget int
+	// MapReduce writes module summaries from excerpts of their code, then
+	// the architecture summary from those and the module dependencies.
+	// Without Stages it runs MapReduceStages.
+	MapReduce bool
 }
 
 type Result struct {
@@ -25,6 +73,20 @@ type Result struct {
 	ModuleSummaries     map[string]string
 	FileSummaries       map[string]FileSummary
 	QuickstartSteps     []string
+	// Tags are the repository's topic tags: the detected ones as classified
+	// by the model.
+	Tags []string
+	// ConfigSummaries maps a config file path to one line per top-level
+	// section, keyed by section name.
+	ConfigSummaries map[string]map[string]string
+	// SeamAssessments maps an index of Options.Seams to the model's name and
+	// assessment of that boundary.
+	SeamAssessments map[int]string
+	// Glossary maps a term of Options.Glossary to its definition.
+	Glossary map[string]string
+	// Incomplete is set when ctx was cancelled before every summary was
+	// requested; the summaries gathered until then are kept.
+	Incomplete bool
 }
 
 type FileSummary struct {
@@ -40,49 +102,58 @@ func Summarize(ctx context.Context, opts Options) (*Result, error) {
 		ModuleSummaries: make(map[string]string),
 		FileSummaries:   make(map[string]FileSummary),
 		QuickstartSteps: []string{},
+		ConfigSummaries: make(map[string]map[string]string),
+		SeamAssessments: make(map[int]string),
+		Glossary:        make(map[string]string),
 	}
 
-	if opts.LLMProvider == nil {
-		opts.LLMProvider = llm.NewNoOpProvider()
+	stages := opts.Stages
+	if stages == nil {
+		stages = DefaultStages()
+		if opts.MapReduce {
+			stages = MapReduceStages()
+		}
 	}
-
-	if err := summarizeArchitecture(ctx, opts, result); err != nil {
-		return nil, fmt.Errorf("architecture summary failed: %w", err)
+	if err := runStages(ctx, opts, stages, result); err != nil {
+		return nil, err
 	}
 
-	if err := summarizeModules(ctx, opts, result); err != nil {
-		return nil, fmt.Errorf("module summary failed: %w", err)
+	if ctx.Err() != nil {
+		result.Incomplete = true
+		opts.Progress.Done("interrupted")
+		return result, nil
 	}
 
-	if err := summarizeTopFiles(ctx, opts, result); err != nil {
-		return nil, fmt.Errorf("file summary failed: %w", err)
-	}
-
-	if err := generateQuickstart(ctx, opts, result); err != nil {
-		return nil, fmt.Errorf("quickstart generation failed: %w", err)
-	}
+	opts.Progress.Done(fmt.Sprintf("%d modules, %d files", len(result.ModuleSummaries), len(result.FileSummaries)))
 
 	return result, nil
 }
 
 func summarizeArchitecture(ctx context.Context, opts Options, result *Result) error {
-	context := buildArchitectureContext(opts)
+	response, err := opts.LLMProvider.Summarize(ctx, architectureRequest(opts, result.ModuleSummaries))
+	opts.Progress.Advance("architecture")
+	if err != nil {
+		return err
+	}
 
-	request := llm.SummarizeRequest{
+	result.ArchitectureSummary = response.Summary
+	return nil
+}
+
+// architectureRequest builds on moduleSummaries with opts.MapReduce; they
+// may be empty when the module stage has not run.
+func architectureRequest(opts Options, moduleSummaries map[string]string) llm.SummarizeRequest {
+	context := buildArchitectureContext(opts)
+	if opts.MapReduce && len(moduleSummaries) > 0 {
+		context += "\n" + moduleOverview(opts, moduleSummaries)
+	}
+	return llm.SummarizeRequest{
 		Type:    llm.SummaryTypeArchitecture,
 		Context: context,
 		Constraints: llm.Constraints{
 			MaxWords: 180,
 		},
 	}
-
-	response, err := opts.LLMProvider.Summarize(ctx, request)
-	if err != nil {
-		return err
-	}
-
-	result.ArchitectureSummary = response.Summary
-	return nil
 }
 
 func buildArchitectureContext(opts Options) string {
@@ -93,7 +164,8 @@ func buildArchitectureContext(opts Options) string {
 	parts = append(parts, fmt.Sprintf("Total lines: %d", opts.ScanResult.TotalLines))
 
 	parts = append(parts, "\nLanguages:")
-	for lang, stat := range opts.ScanResult.LanguageStats {
+	for _, lang := range slices.Sorted(maps.Keys(opts.ScanResult.LanguageStats)) {
+		stat := opts.ScanResult.LanguageStats[lang]
 		parts = append(parts, fmt.Sprintf("- %s: %.1f%% (%d files, %d lines)",
 			lang, stat.Percentage, stat.FileCount, stat.Lines))
 	}
@@ -108,7 +180,11 @@ func buildArchitectureContext(opts Options) string {
 	if len(opts.DetectionResult.BuildTools) > 0 {
 		parts = append(parts, "\nBuild tools:")
 		for _, tool := range opts.DetectionResult.BuildTools {
-			parts = append(parts, fmt.Sprintf("- %s (%s)", tool.Type, tool.File))
+			line := fmt.Sprintf("- %s (%s)", tool.Type, tool.File)
+			if len(tool.Modules) > 0 {
+				line += "; modules: " + strings.Join(tool.Modules, ", ")
+			}
+			parts = append(parts, line)
 		}
 	}
 
@@ -119,6 +195,27 @@ func buildArchitectureContext(opts Options) string {
 		}
 	}
 
+	if len(opts.DetectionResult.Decisions) > 0 {
+		parts = append(parts, "\nArchitecture decisions recorded by the team (authoritative; base the overview on "+
+			"the accepted ones and ignore superseded, deprecated or rejected ones):")
+		for _, decision := range opts.DetectionResult.Decisions {
+			status := decision.Status
+			if status == "" {
+				status = "status unknown"
+			}
+			line := fmt.Sprintf("- ADR %d: %s (%s)", decision.Number, decision.Title, status)
+			if decision.Summary != "" {
+				line += ": " + decision.Summary
+			}
+			parts = append(parts, line)
+		}
+	}
+
+	if docs := docContext(opts, detect.DocOverview); len(docs) > 0 {
+		parts = append(parts, "\nThe project's own description (may be outdated; prefer the detected facts above):")
+		parts = append(parts, docs...)
+	}
+
 	dirStructure := buildDirectoryStructure(opts.ScanResult.Files)
 	parts = append(parts, "\nKey directories:")
 	parts = append(parts, dirStructure...)
@@ -140,7 +237,8 @@ func buildDirectoryStructure(files []scanner.FileInfo) []string {
 	}
 
 	topDirs := []string{}
-	for dir, count := range dirCounts {
+	for _, dir := range slices.Sorted(maps.Keys(dirCounts)) {
+		count := dirCounts[dir]
 		depth := strings.Count(dir, string(filepath.Separator))
 		if depth <= 2 && count >= 2 {
 			topDirs = append(topDirs, fmt.Sprintf("- /%s (%d files)", dir, count))
@@ -154,22 +252,79 @@ func buildDirectoryStructure(files []scanner.FileInfo) []string {
 	return topDirs
 }
 
-func summarizeModules(ctx context.Context, opts Options, result *Result) error {
-	modules := identifyKeyModules(opts.ScanResult.Files)
+// maxTags bounds the tags the model may return.
+const maxTags = 8
 
-	for _, module := range modules {
-		context := buildModuleContext(module, opts.ScanResult.Files)
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9+.#-]{0,29}$`)
 
-		request := llm.SummarizeRequest{
-			Type:    llm.SummaryTypeModule,
-			Context: context,
-			Constraints: llm.Constraints{
-				MaxWords: 80,
-			},
+// classifyRepository asks the model for topic tags. When it fails, or its
+// answer holds no usable tag, the detected tags are kept.
+func classifyRepository(ctx context.Context, opts Options, result *Result) {
+	detected := []string{}
+	for _, tag := range opts.DetectionResult.Tags {
+		detected = append(detected, tag.Name)
+	}
+	result.Tags = detected
+
+	if ctx.Err() != nil {
+		return
+	}
+	response, err := opts.LLMProvider.Summarize(ctx, tagsRequest(opts, detected))
+	opts.Progress.Advance("tags")
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.WarnContext(ctx, "tag classification skipped", "err", err)
 		}
+		return
+	}
+	if tags := parseTags(response.Summary); len(tags) > 0 {
+		result.Tags = tags
+	}
+}
 
-		response, err := opts.LLMProvider.Summarize(ctx, request)
+func tagsRequest(opts Options, detected []string) llm.SummarizeRequest {
+	context := buildArchitectureContext(opts)
+	if len(detected) > 0 {
+		context += "\n\nDetected tags: " + strings.Join(detected, ", ")
+	}
+	return llm.SummarizeRequest{
+		Type:    llm.SummaryTypeTags,
+		Context: context,
+		Constraints: llm.Constraints{
+			MaxBullets: maxTags,
+		},
+	}
+}
+
+// parseTags reads a comma- or line-separated list of tags, dropping anything
+// that does not look like one, such as a dry-run placeholder.
+func parseTags(summary string) []string {
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(summary, func(r rune) bool { return r == ',' || r == '\n' }) {
+		tag := strings.ToLower(strings.Trim(field, " \t-*`'\"."))
+		tag = strings.Join(strings.Fields(tag), "-")
+		if !tagPattern.MatchString(tag) || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == maxTags {
+			break
+		}
+	}
+	return tags
+}
+
+func summarizeModules(ctx context.Context, opts Options, result *Result) error {
+	for _, module := range opts.keyModules() {
+		if ctx.Err() != nil {
+			break
+		}
+		response, err := opts.LLMProvider.Summarize(ctx, moduleRequest(opts, module))
+		opts.Progress.Advance(module)
 		if err != nil {
+			slog.WarnContext(ctx, "module summary skipped", "module", module, "err", err)
 			continue
 		}
 
@@ -179,7 +334,50 @@ func summarizeModules(ctx context.Context, opts Options, result *Result) error {
 	return nil
 }
 
+func moduleRequest(opts Options, module string) llm.SummarizeRequest {
+	context := buildModuleContext(module, opts.ScanResult.Files)
+	if opts.MapReduce {
+		for _, excerpt := range moduleExcerpts(opts, module) {
+			context += "\n\n" + excerpt
+		}
+	}
+	return llm.SummarizeRequest{
+		Type:    llm.SummaryTypeModule,
+		Context: context,
+		Constraints: llm.Constraints{
+			MaxWords: 80,
+		},
+	}
+}
+
+func (opts Options) keyModules() []string {
+	if len(opts.Modules) == 0 {
+		return identifyKeyModules(opts.ScanResult.Files)
+	}
+	modules := []string{}
+	for _, module := range opts.Modules {
+		module = filepath.Clean(filepath.FromSlash(module))
+		for _, file := range opts.ScanResult.Files {
+			if strings.HasPrefix(file.RelativePath, module+string(filepath.Separator)) {
+				modules = append(modules, module)
+				break
+			}
+		}
+	}
+	return modules
+}
+
 func identifyKeyModules(files []scanner.FileInfo) []string {
+	modules := moduleDirs(files)
+	if len(modules) > 10 {
+		modules = modules[:10]
+	}
+	return modules
+}
+
+// moduleDirs lists every directory that looks like a module: one at most
+// three levels deep holding at least three files.
+func moduleDirs(files []scanner.FileInfo) []string {
 	dirFiles := make(map[string]int)
 	for _, file := range files {
 		dir := filepath.Dir(file.RelativePath)
@@ -189,17 +387,13 @@ func identifyKeyModules(files []scanner.FileInfo) []string {
 	}
 
 	modules := []string{}
-	for dir, count := range dirFiles {
+	for _, dir := range slices.Sorted(maps.Keys(dirFiles)) {
+		count := dirFiles[dir]
 		depth := strings.Count(dir, string(filepath.Separator))
 		if depth <= 2 && count >= 3 {
 			modules = append(modules, dir)
 		}
 	}
-
-	if len(modules) > 10 {
-		modules = modules[:10]
-	}
-
 	return modules
 }
 
@@ -225,8 +419,8 @@ func buildModuleContext(module string, files []scanner.FileInfo) string {
 	parts = append(parts, fmt.Sprintf("Lines: %d", totalLines))
 
 	parts = append(parts, "Languages:")
-	for lang, count := range langCounts {
-		parts = append(parts, fmt.Sprintf("- %s: %d files", lang, count))
+	for _, lang := range slices.Sorted(maps.Keys(langCounts)) {
+		parts = append(parts, fmt.Sprintf("- %s: %d files", lang, langCounts[lang]))
 	}
 
 	parts = append(parts, "\nKey files:")
@@ -241,103 +435,85 @@ func buildModuleContext(module string, files []scanner.FileInfo) string {
 }
 
 func summarizeTopFiles(ctx context.Context, opts Options, result *Result) error {
-	topFiles := selectTopFiles(opts.ScanResult.Files, 10)
-
-	for _, file := range topFiles {
-		context, err := buildFileContext(file, opts.MaxLinesPerFile, opts.RedactSecrets)
-		if err != nil {
-			continue
+	files := selectTopFiles(opts)
+	spent := 0
+	for i, file := range files {
+		if ctx.Err() != nil {
+			break
 		}
-
-		summaryRequest := llm.SummarizeRequest{
-			Type:    llm.SummaryTypeFile,
-			Context: context,
-			Constraints: llm.Constraints{
-				MaxWords: 120,
-			},
-			CacheKey: file.Hash,
+		if opts.FileTokenBudget > 0 && spent >= opts.FileTokenBudget {
+			slog.InfoContext(ctx, "file token budget reached, remaining files skipped",
+				"budget", opts.FileTokenBudget, "skipped", len(files)-i)
+			break
 		}
-
-		summaryResponse, err := opts.LLMProvider.Summarize(ctx, summaryRequest)
+		summary, err := summarizeFile(ctx, opts, file)
+		opts.Progress.Advance(file.RelativePath)
 		if err != nil {
+			slog.WarnContext(ctx, "file summary skipped", "file", file.RelativePath, "err", err)
 			continue
 		}
-
-		functionsRequest := llm.SummarizeRequest{
-			Type:    llm.SummaryTypeFunction,
-			Context: context,
-			Constraints: llm.Constraints{
-				MaxBullets: 8,
-			},
-			CacheKey: file.Hash + "-functions",
-		}
-
-		functionsResponse, err := opts.LLMProvider.Summarize(ctx, functionsRequest)
-		if err != nil {
-			functionsResponse.Summary = ""
-		}
-
-		functions := []string{}
-		if functionsResponse.Summary != "" {
-			for _, line := range strings.Split(functionsResponse.Summary, "\n") {
-				line = strings.TrimSpace(line)
-				if strings.HasPrefix(line, "-") || strings.HasPrefix(line, "*") {
-					functions = append(functions, strings.TrimSpace(line[1:]))
-				}
-			}
-		}
-
-		result.FileSummaries[file.RelativePath] = FileSummary{
-			Path:       file.RelativePath,
-			Summary:    summaryResponse.Summary,
-			Functions:  functions,
-			Cached:     summaryResponse.Cached,
-			TokensUsed: summaryResponse.Tokens + functionsResponse.Tokens,
+		if !summary.Cached {
+			spent += summary.TokensUsed
 		}
+		result.FileSummaries[file.RelativePath] = summary
 	}
 
 	return nil
 }
 
-func selectTopFiles(files []scanner.FileInfo, limit int) []scanner.FileInfo {
-	selected := []scanner.FileInfo{}
-
-	priority := []scanner.FileInfo{}
-	regular := []scanner.FileInfo{}
+// SummarizeFile summarizes one file and its key functions outside a
+// Summarize run, through the same built-in middleware and opts.Middleware.
+func SummarizeFile(ctx context.Context, opts Options, file scanner.FileInfo) (FileSummary, error) {
+	opts.LLMProvider = opts.provider()
+	return summarizeFile(ctx, opts, file)
+}
 
-	for _, file := range files {
-		if file.IsTest {
-			continue
-		}
+func summarizeFile(ctx context.Context, opts Options, file scanner.FileInfo) (FileSummary, error) {
+	context, err := buildFileContext(file, opts.MaxLinesPerFile)
+	if err != nil {
+		return FileSummary{}, err
+	}
 
-		base := filepath.Base(file.RelativePath)
-		if base == "main.go" || base == "main.py" || base == "index.js" || base == "app.py" ||
-			base == "server.js" || base == "Makefile" || base == "package.json" ||
-			base == "requirements.txt" || base == "go.mod" {
-			priority = append(priority, file)
-		} else {
-			regular = append(regular, file)
-		}
+	summaryResponse, err := opts.LLMProvider.Summarize(ctx, fileRequest(file, context))
+	if err != nil {
+		return FileSummary{}, err
 	}
 
-	selected = append(selected, priority...)
+	functionsRequest := llm.SummarizeRequest{
+		Type:    llm.SummaryTypeFunction,
+		Context: context,
+		Constraints: llm.Constraints{
+			MaxBullets: 8,
+		},
+		CacheKey: file.Hash + "-functions",
+	}
 
-	remaining := limit - len(selected)
-	if remaining > 0 && len(regular) > 0 {
-		if len(regular) > remaining {
-			regular = regular[:remaining]
-		}
-		selected = append(selected, regular...)
+	// The file summary stands without its functions.
+	functionsResponse, err := opts.LLMProvider.Summarize(ctx, functionsRequest)
+	if err != nil {
+		functionsResponse.Summary = ""
 	}
 
-	if len(selected) > limit {
-		selected = selected[:limit]
+	functions := []string{}
+	if functionsResponse.Summary != "" {
+		for _, line := range strings.Split(functionsResponse.Summary, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "-") || strings.HasPrefix(line, "*") {
+				functions = append(functions, strings.TrimSpace(line[1:]))
+			}
+		}
 	}
 
-	return selected
+	return FileSummary{
+		Path:       file.RelativePath,
+		Summary:    summaryResponse.Summary,
+		Functions:  functions,
+		Cached:     summaryResponse.Cached,
+		TokensUsed: summaryResponse.Tokens + functionsResponse.Tokens,
+	}, nil
 }
 
-func buildFileContext(file scanner.FileInfo, maxLines int, redactSecrets bool) (string, error) {
+func buildFileContext(file scanner.FileInfo, maxLines int) (string, error) {
 	content, err := os.ReadFile(file.Path)
 	if err != nil {
 		return "", err
@@ -349,9 +525,6 @@ func buildFileContext(file scanner.FileInfo, maxLines int, redactSecrets bool) (
 	}
 
 	text := strings.Join(lines, "\n")
-	if redactSecrets {
-		text = redactSecretsFromText(text)
-	}
 
 	context := fmt.Sprintf("File: %s\n", file.RelativePath)
 	context += fmt.Sprintf("Language: %s\n", file.Language)
@@ -398,6 +571,12 @@ func extractKeyLines(lines []string, maxLines int) []string {
 	return result
 }
 
+// redactRequest is the --redact-secrets middleware.
+func redactRequest(request llm.SummarizeRequest) llm.SummarizeRequest {
+	request.Context = redactSecretsFromText(request.Context)
+	return request
+}
+
 func redactSecretsFromText(text string) string {
 	patterns := []string{
 		`(api[_-]?key|api[_-]?secret|access[_-]?token|auth[_-]?token|private[_-]?key)[\s]*[:=][\s]*["']?[\w\-]+["']?`,
@@ -418,25 +597,43 @@ func redactPattern(text, pattern string) string {
 	return text
 }
 
-func generateQuickstart(ctx context.Context, opts Options, result *Result) error {
-	context := buildQuickstartContext(opts)
-
-	request := llm.SummarizeRequest{
-		Type:    llm.SummaryTypeQuickstart,
+func fileRequest(file scanner.FileInfo, context string) llm.SummarizeRequest {
+	return llm.SummarizeRequest{
+		Type:    llm.SummaryTypeFile,
 		Context: context,
 		Constraints: llm.Constraints{
-			MaxBullets: 8,
+			MaxWords: 120,
 		},
+		CacheKey: file.Hash,
 	}
+}
 
-	response, err := opts.LLMProvider.Summarize(ctx, request)
+func generateQuickstart(ctx context.Context, opts Options, result *Result) error {
+	response, err := opts.LLMProvider.Summarize(ctx, quickstartRequest(opts))
+	opts.Progress.Advance("quickstart")
 	if err != nil {
 		result.QuickstartSteps = generateDefaultQuickstart(opts)
 		return nil
 	}
 
+	result.QuickstartSteps = parseSteps(response.Summary)
+	return nil
+}
+
+func quickstartRequest(opts Options) llm.SummarizeRequest {
+	return llm.SummarizeRequest{
+		Type:    llm.SummaryTypeQuickstart,
+		Context: buildQuickstartContext(opts),
+		Constraints: llm.Constraints{
+			MaxBullets: 8,
+		},
+	}
+}
+
+// parseSteps extracts the bulleted or numbered lines of a quickstart.
+func parseSteps(summary string) []string {
 	steps := []string{}
-	for _, line := range strings.Split(response.Summary, "\n") {
+	for _, line := range strings.Split(summary, "\n") {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "-") || strings.HasPrefix(line, "*") ||
 			strings.HasPrefix(line, "•") || (len(line) > 2 && line[1] == '.') {
@@ -447,9 +644,87 @@ func generateQuickstart(ctx context.Context, opts Options, result *Result) error
 			steps = append(steps, strings.TrimSpace(step))
 		}
 	}
+	return steps
+}
 
-	result.QuickstartSteps = steps
-	return nil
+func summarizeConfigFiles(ctx context.Context, opts Options, result *Result) {
+	for _, config := range opts.DetectionResult.ConfigFiles {
+		if ctx.Err() != nil {
+			break
+		}
+		if len(config.Sections) == 0 {
+			continue
+		}
+
+		var parts []string
+		parts = append(parts, fmt.Sprintf("Config file: %s (%s)", config.Path, config.Format))
+		parts = append(parts, "Key hierarchy:")
+		for _, section := range config.Sections {
+			line := fmt.Sprintf("- %s (%s)", section.Key, section.Type)
+			if len(section.Keys) > 0 {
+				line += ": " + strings.Join(section.Keys, ", ")
+			}
+			parts = append(parts, line)
+		}
+
+		request := llm.SummarizeRequest{
+			Type:    llm.SummaryTypeConfig,
+			Context: strings.Join(parts, "\n"),
+			Constraints: llm.Constraints{
+				MaxBullets: min(len(config.Sections), 12),
+			},
+		}
+
+		response, err := opts.LLMProvider.Summarize(ctx, request)
+		opts.Progress.Advance(config.Path)
+		if err != nil {
+			continue
+		}
+
+		result.ConfigSummaries[config.Path] = parseSectionBullets(response.Summary, config.Sections)
+	}
+}
+
+// parseSectionBullets matches "- section — description" lines back to the
+// section they describe. Unmatched output is kept under the empty key.
+func parseSectionBullets(summary string, sections []detect.ConfigSection) map[string]string {
+	descriptions := make(map[string]string)
+
+	for _, line := range strings.Split(summary, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "*") {
+			continue
+		}
+		line = strings.TrimSpace(line[1:])
+
+		matched := false
+		for _, separator := range []string{" — ", " - ", ": "} {
+			key, description, ok := strings.Cut(line, separator)
+			if !ok {
+				continue
+			}
+			key = strings.Trim(strings.TrimSpace(key), "`*")
+			for _, section := range sections {
+				if section.Key == key {
+					descriptions[key] = strings.TrimSpace(description)
+					matched = true
+				}
+			}
+			if matched {
+				break
+			}
+		}
+
+		if !matched {
+			descriptions[""] = strings.TrimSpace(descriptions[""] + " " + line)
+		}
+	}
+
+	if len(descriptions) == 0 && strings.TrimSpace(summary) != "" {
+		descriptions[""] = strings.TrimSpace(summary)
+	}
+
+	return descriptions
 }
 
 func buildQuickstartContext(opts Options) string {
@@ -474,9 +749,60 @@ func buildQuickstartContext(opts Options) string {
 		}
 	}
 
+	if len(opts.DetectionResult.Pipelines) > 0 {
+		parts = append(parts, "\nCI pipelines:")
+		for _, pipeline := range opts.DetectionResult.Pipelines {
+			parts = append(parts, fmt.Sprintf("- %s (%s): triggered by %s", pipeline.Name, pipeline.File, strings.Join(pipeline.Triggers, ", ")))
+			for _, job := range pipeline.Jobs {
+				line := fmt.Sprintf("  %s: %s", job.Name, strings.Join(job.Commands[:min(3, len(job.Commands))], "; "))
+				if job.Deploy {
+					line += " (deploys)"
+				}
+				parts = append(parts, line)
+			}
+		}
+	}
+
+	if docs := docContext(opts, detect.DocInstallation, detect.DocUsage, detect.DocDevelopment); len(docs) > 0 {
+		parts = append(parts, "\nInstructions from the project's docs (keep the steps that match the build tools above):")
+		parts = append(parts, docs...)
+	}
+	stale := []string{}
+	for _, gap := range opts.DetectionResult.DocGaps {
+		if gap.Kind == detect.DocGapStale {
+			stale = append(stale, "- "+gap.Message)
+		}
+	}
+	if len(stale) > 0 {
+		parts = append(parts, "\nOutdated instructions in the docs (do not repeat them):")
+		parts = append(parts, stale...)
+	}
+
 	return strings.Join(parts, "\n")
 }
 
+// maxDocContext caps the documentation quoted in a prompt, in characters.
+const maxDocContext = 3000
+
+// docContext quotes the sections of the README and contributing guide of
+// the given kinds, up to maxDocContext characters.
+func docContext(opts Options, kinds ...string) []string {
+	parts := []string{}
+	remaining := maxDocContext
+	for _, doc := range opts.DetectionResult.Docs {
+		for _, section := range doc.Sections {
+			if !contains(kinds, section.Kind) || remaining <= 0 {
+				continue
+			}
+			text := []rune(section.Text)
+			text = text[:min(remaining, len(text))]
+			remaining -= len(text)
+			parts = append(parts, fmt.Sprintf("--- %s: %s ---\n%s", doc.File, section.Heading, string(text)))
+		}
+	}
+	return parts
+}
+
 func generateDefaultQuickstart(opts Options) []string {
 	steps := []string{}
 
@@ -512,6 +838,68 @@ func generateDefaultQuickstart(opts Options) []string {
 				steps = append(steps, "Run the application: make run")
 			}
 
+		case "maven", "gradle":
+			steps = append(steps, "Build the project: "+tool.Scripts[0])
+			steps = append(steps, "Run tests: "+tool.Scripts[1])
+
+		case "cargo":
+			steps = append(steps, "Build the project: cargo build")
+			steps = append(steps, "Run tests: cargo test")
+
+		case "bundler":
+			for _, script := range tool.Scripts {
+				switch script {
+				case "bundle install":
+					steps = append(steps, "Install dependencies: bundle install")
+				case "bin/rails db:setup":
+					steps = append(steps, "Set up the database: bin/rails db:setup")
+				case "bin/rails server":
+					steps = append(steps, "Start the application: bin/rails server")
+				case "bin/rails test", "bundle exec rspec":
+					steps = append(steps, "Run tests: "+script)
+				}
+			}
+
+		case "composer":
+			for _, script := range tool.Scripts {
+				switch script {
+				case "composer install":
+					steps = append(steps, "Install dependencies: composer install")
+				case "php artisan migrate":
+					steps = append(steps, "Set up the database: php artisan migrate")
+				case "php artisan serve", "symfony server:start":
+					steps = append(steps, "Start the application: "+script)
+				case "composer test", "php artisan test", "vendor/bin/pest", "vendor/bin/phpunit":
+					steps = append(steps, "Run tests: "+script)
+				}
+			}
+
+		case "dotnet":
+			for _, script := range tool.Scripts {
+				switch {
+				case strings.HasPrefix(script, "dotnet restore"):
+					steps = append(steps, "Install dependencies: "+script)
+				case strings.HasPrefix(script, "dotnet build"):
+					steps = append(steps, "Build the project: "+script)
+				case strings.HasPrefix(script, "dotnet test"):
+					steps = append(steps, "Run tests: "+script)
+				}
+			}
+			for _, ep := range opts.DetectionResult.Entrypoints {
+				if ep.Type == "dotnet-app" && (ep.Path == tool.File || contains(tool.Modules, path.Dir(ep.Path))) {
+					steps = append(steps, "Start the application: "+ep.Command)
+				}
+			}
+
+		case "terraform":
+			steps = append(steps, "Initialize Terraform: "+tool.Scripts[0])
+			steps = append(steps, "Preview infrastructure changes: "+tool.Scripts[2])
+
+		case "rake":
+			if len(tool.Scripts) > 0 {
+				steps = append(steps, "Project tasks (bundle exec rake <task>): "+strings.Join(tool.Scripts[:min(5, len(tool.Scripts))], ", "))
+			}
+
 		case "pip":
 			steps = append(steps, "Install dependencies: pip install -r requirements.txt")
 
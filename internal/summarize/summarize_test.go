@@ -0,0 +1,155 @@
+package summarize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// cancellingProvider answers calls requests, then cancels the run as a
+// SIGINT would and fails like an aborted HTTP request.
+type cancellingProvider struct {
+	calls  int
+	cancel context.CancelFunc
+}
+
+func (p *cancellingProvider) Summarize(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+	if p.calls == 0 {
+		p.cancel()
+		return llm.SummarizeResponse{}, ctx.Err()
+	}
+	p.calls--
+	return llm.SummarizeResponse{Summary: string(request.Type) + " summary"}, nil
+}
+
+func TestSummarizeInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	files := []scanner.FileInfo{}
+	for _, name := range []string{"api/server.go", "store/db.go"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, scanner.FileInfo{Path: path, RelativePath: name, Language: "go", Lines: 1})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result, err := Summarize(ctx, Options{
+		ScanResult:      &scanner.Result{Files: files, TotalFiles: len(files)},
+		DetectionResult: &detect.Result{},
+		MaxLinesPerFile: 100,
+		LLMProvider:     &cancellingProvider{calls: 1, cancel: cancel},
+	})
+	if err != nil {
+		t.Fatalf("Summarize returned %v; an interrupted run should keep its partial result", err)
+	}
+	if !result.Incomplete {
+		t.Error("Expected the result to be marked incomplete")
+	}
+	if result.ArchitectureSummary != "architecture summary" {
+		t.Errorf("Expected the summary finished before the interrupt, got %q", result.ArchitectureSummary)
+	}
+	if len(result.QuickstartSteps) == 0 {
+		t.Error("Expected default quickstart steps for the interrupted run")
+	}
+
+	complete, err := Summarize(context.Background(), Options{
+		ScanResult:      &scanner.Result{Files: files, TotalFiles: len(files)},
+		DetectionResult: &detect.Result{},
+		MaxLinesPerFile: 100,
+		LLMProvider:     llm.NewNoOpProvider(),
+	})
+	if err != nil || complete.Incomplete {
+		t.Errorf("Expected a complete result, got incomplete=%v err=%v", complete.Incomplete, err)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		summary string
+		want    string
+	}{
+		{"payments, kubernetes-operator, React SPA", "payments|kubernetes-operator|react-spa"},
+		{"- ETL\n- `data pipeline`\n- etl", "etl|data-pipeline"},
+		{"[tags summary placeholder - dry run mode]", ""},
+		{"Here are the tags: a/b, ok", "ok"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(parseTags(tt.summary), "|"); got != tt.want {
+			t.Errorf("parseTags(%q) = %q, want %q", tt.summary, got, tt.want)
+		}
+	}
+}
+
+func TestKeyModules(t *testing.T) {
+	opts := Options{
+		ScanResult: &scanner.Result{Files: []scanner.FileInfo{
+			{RelativePath: filepath.Join("internal", "orders", "orders.go")},
+			{RelativePath: filepath.Join("internal", "ordersync", "sync.go")},
+		}},
+		Modules: []string{"internal/orders", "internal/billing"},
+	}
+	if got := opts.keyModules(); len(got) != 1 || got[0] != filepath.Join("internal", "orders") {
+		t.Errorf("keyModules() = %v, want only internal/orders", got)
+	}
+}
+
+// costlyProvider charges tokens for every response, cached or not.
+type costlyProvider struct {
+	tokens int
+	cached bool
+}
+
+func (p *costlyProvider) Summarize(ctx context.Context, request llm.SummarizeRequest) (llm.SummarizeResponse, error) {
+	return llm.SummarizeResponse{Summary: "summary", Tokens: p.tokens, Cached: p.cached}, nil
+}
+
+func TestFileTokenBudget(t *testing.T) {
+	dir := t.TempDir()
+	files := []scanner.FileInfo{}
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, scanner.FileInfo{Path: path, RelativePath: name, Language: "go", Lines: 1})
+	}
+
+	tests := []struct {
+		name     string
+		provider *costlyProvider
+		want     int
+	}{
+		{"spent", &costlyProvider{tokens: 100}, 2},
+		{"cached", &costlyProvider{tokens: 100, cached: true}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{FileSummaries: map[string]FileSummary{}}
+			opts := Options{
+				ScanResult:      &scanner.Result{Files: files},
+				DetectionResult: &detect.Result{},
+				MaxLinesPerFile: 100,
+				LLMProvider:     tt.provider,
+				FileTokenBudget: 300,
+			}
+			if err := summarizeTopFiles(context.Background(), opts, result); err != nil {
+				t.Fatal(err)
+			}
+			if len(result.FileSummaries) != tt.want {
+				t.Errorf("summarized %d files, want %d", len(result.FileSummaries), tt.want)
+			}
+		})
+	}
+}
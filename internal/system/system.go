@@ -0,0 +1,332 @@
+// Package system stitches several analyzed repositories into one
+// architecture: it finds where one repository references another through a
+// client package, a service URL or a shared protobuf definition.
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/depmap"
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+const (
+	KindClient = "client"
+	KindHTTP   = "http"
+	KindGRPC   = "grpc"
+	KindProto  = "proto"
+)
+
+// Repo is one analyzed repository. Files and Detection use paths relative to
+// Path.
+type Repo struct {
+	Name      string
+	Path      string
+	Files     []scanner.FileInfo
+	Detection *detect.Result
+}
+
+type Result struct {
+	Services []Service
+	Links    []Link
+}
+
+// Service summarizes a repository as a node of the system diagram.
+type Service struct {
+	Name       string
+	Module     string
+	Frameworks []string
+	Endpoints  int
+	// Hosts are the names other services may use to reach this one: the
+	// repository name and the Kubernetes services and compose services it
+	// defines.
+	Hosts []string
+}
+
+// Link is a reference from one repository to another. Evidence is the first
+// place it was found, as "file: reference".
+type Link struct {
+	From     string
+	To       string
+	Kind     string
+	Evidence string
+}
+
+var (
+	serviceURL   = regexp.MustCompile(`\b(https?|grpcs?)://([A-Za-z0-9][A-Za-z0-9.\-]*)(?::\d+)?`)
+	hostPort     = regexp.MustCompile(`["'\x60]([a-z][a-z0-9\-]*):(\d{2,5})["'\x60]`)
+	protoImport  = regexp.MustCompile(`^\s*import\s+(?:public\s+|weak\s+)?"([^"]+\.proto)"`)
+	pyprojectKey = regexp.MustCompile(`^name\s*=\s*"([^"]+)"`)
+
+	skippedDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true, "dist": true, "build": true}
+)
+
+// Stitch finds the references between repos.
+func Stitch(repos []Repo) *Result {
+	result := &Result{Services: []Service{}, Links: []Link{}}
+
+	hosts := make(map[string]string)
+	modules := make(map[string]string)
+	protos := make(map[string][]string)
+	protoImports := make(map[string][]protoRef)
+	for _, repo := range repos {
+		service := describe(repo)
+		result.Services = append(result.Services, service)
+		for _, host := range service.Hosts {
+			if _, taken := hosts[host]; !taken || host == repo.Name {
+				hosts[host] = repo.Name
+			}
+		}
+		if service.Module != "" {
+			modules[service.Module] = repo.Name
+		}
+		protos[repo.Name], protoImports[repo.Name] = protoFiles(repo.Path)
+	}
+
+	links := make(map[string]Link)
+	add := func(from, to, kind, evidence string) {
+		if to == "" || to == from {
+			return
+		}
+		key := from + "\x00" + to + "\x00" + kind
+		if _, ok := links[key]; !ok {
+			links[key] = Link{From: from, To: to, Kind: kind, Evidence: evidence}
+		}
+	}
+
+	for _, repo := range repos {
+		for _, file := range repo.Files {
+			rel := filepath.ToSlash(file.RelativePath)
+			for _, imp := range file.Imports {
+				for module, owner := range modules {
+					if imp == module || strings.HasPrefix(imp, module+"/") {
+						add(repo.Name, owner, KindClient, rel+": "+imp)
+					}
+				}
+			}
+
+			content, err := os.ReadFile(file.Path)
+			if err != nil {
+				continue
+			}
+			for _, match := range serviceURL.FindAllStringSubmatch(string(content), -1) {
+				host, ok := clusterHost(match[2])
+				if !ok {
+					continue
+				}
+				kind := KindHTTP
+				if strings.HasPrefix(match[1], "grpc") {
+					kind = KindGRPC
+				}
+				add(repo.Name, hosts[host], kind, rel+": "+match[0])
+			}
+			for _, match := range hostPort.FindAllStringSubmatch(string(content), -1) {
+				add(repo.Name, hosts[match[1]], KindGRPC, rel+": "+match[1]+":"+match[2])
+			}
+		}
+
+		for _, imp := range protoImports[repo.Name] {
+			for _, other := range repos {
+				if other.Name == repo.Name {
+					continue
+				}
+				for _, defined := range protos[other.Name] {
+					if defined == imp.path || strings.HasSuffix(defined, "/"+imp.path) {
+						add(repo.Name, other.Name, KindProto, imp.file+": "+imp.path)
+					}
+				}
+			}
+		}
+	}
+
+	for _, link := range links {
+		result.Links = append(result.Links, link)
+	}
+	sort.Slice(result.Links, func(i, j int) bool {
+		a, b := result.Links[i], result.Links[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+
+	return result
+}
+
+// clusterHost returns the service name of an in-cluster host: a bare name
+// (users) or a Kubernetes DNS name (users.default.svc.cluster.local). Public
+// hostnames such as api.example.com are not service references.
+func clusterHost(host string) (string, bool) {
+	host = strings.ToLower(host)
+	name, rest, dotted := strings.Cut(host, ".")
+	if dotted && !strings.Contains("."+rest+".", ".svc.") {
+		return "", false
+	}
+	return name, true
+}
+
+func describe(repo Repo) Service {
+	service := Service{
+		Name:       repo.Name,
+		Module:     moduleName(repo.Path),
+		Frameworks: []string{},
+		Hosts:      []string{strings.ToLower(repo.Name)},
+	}
+
+	if repo.Detection != nil {
+		for _, framework := range repo.Detection.Frameworks {
+			if framework.Confidence >= detect.ConfidenceMedium && !slices.Contains(service.Frameworks, framework.Name) {
+				service.Frameworks = append(service.Frameworks, framework.Name)
+			}
+		}
+		service.Endpoints = len(repo.Detection.Endpoints)
+		for _, resource := range repo.Detection.K8s {
+			if resource.Kind == "Service" && !slices.Contains(service.Hosts, resource.Name) {
+				service.Hosts = append(service.Hosts, resource.Name)
+			}
+		}
+	}
+	for _, name := range composeServices(repo.Path) {
+		if !slices.Contains(service.Hosts, name) {
+			service.Hosts = append(service.Hosts, name)
+		}
+	}
+
+	sort.Strings(service.Frameworks)
+	return service
+}
+
+// moduleName extends depmap.ModuleName with the PEP 621 project name, which
+// Python code imports with dashes replaced by underscores.
+func moduleName(repoPath string) string {
+	if name := depmap.ModuleName(repoPath); name != "" {
+		return name
+	}
+
+	file, err := os.Open(filepath.Join(repoPath, "pyproject.toml"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		if match := pyprojectKey.FindStringSubmatch(strings.TrimSpace(lines.Text())); match != nil {
+			return strings.ReplaceAll(match[1], "-", "_")
+		}
+	}
+	return ""
+}
+
+type protoRef struct {
+	file string
+	path string
+}
+
+// protoFiles lists the .proto files under repoPath and the imports they
+// declare.
+func protoFiles(repoPath string) ([]string, []protoRef) {
+	defined := []string{}
+	imports := []protoRef{}
+
+	filepath.WalkDir(repoPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != repoPath && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) != ".proto" {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(repoPath, p)
+		rel = filepath.ToSlash(rel)
+		defined = append(defined, rel)
+
+		file, err := os.Open(p)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+		lines := bufio.NewScanner(file)
+		for lines.Scan() {
+			if match := protoImport.FindStringSubmatch(lines.Text()); match != nil {
+				imports = append(imports, protoRef{file: rel, path: match[1]})
+			}
+		}
+		return nil
+	})
+
+	return defined, imports
+}
+
+// composeServices returns the service names declared in docker-compose
+// files at the repository root.
+func composeServices(repoPath string) []string {
+	names := []string{}
+	for _, base := range []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"} {
+		file, err := os.Open(filepath.Join(repoPath, base))
+		if err != nil {
+			continue
+		}
+
+		inServices := false
+		lines := bufio.NewScanner(file)
+		for lines.Scan() {
+			line := lines.Text()
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			case !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t"):
+				inServices = trimmed == "services:"
+			case inServices && strings.HasSuffix(trimmed, ":") && indent(line) == 2:
+				names = append(names, strings.TrimSuffix(trimmed, ":"))
+			}
+		}
+		file.Close()
+	}
+	return names
+}
+
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// Mermaid renders the services and links as a Mermaid flowchart.
+func (r *Result) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, service := range r.Services {
+		label := service.Name
+		if len(service.Frameworks) > 0 {
+			label += "<br/>" + strings.Join(service.Frameworks, ", ")
+		}
+		b.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", nodeID(service.Name), label))
+	}
+	for _, link := range r.Links {
+		b.WriteString(fmt.Sprintf("  %s -->|%s| %s\n", nodeID(link.From), link.Kind, nodeID(link.To)))
+	}
+	return b.String()
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func nodeID(name string) string {
+	return nonIdentifier.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,106 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStitch(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "api")
+	users := filepath.Join(root, "users")
+	billing := filepath.Join(root, "billing")
+
+	writeFile(t, filepath.Join(api, "go.mod"), "module example.com/api\n")
+	writeFile(t, filepath.Join(api, "main.go"), `const users = "http://users-svc.default.svc.cluster.local"
+const billing = "billing:50051"
+const docs = "https://api.example.com"
+`)
+	writeFile(t, filepath.Join(api, "proto", "api.proto"), `syntax = "proto3";
+import "acme/users/v1/users.proto";
+`)
+	writeFile(t, filepath.Join(users, "go.mod"), "module example.com/users\n")
+	writeFile(t, filepath.Join(users, "proto", "acme", "users", "v1", "users.proto"), "syntax = \"proto3\";\n")
+	writeFile(t, filepath.Join(billing, "docker-compose.yml"), "services:\n  billing:\n    image: billing\n")
+	writeFile(t, filepath.Join(billing, "client.go"), "package billing\n")
+
+	result := Stitch([]Repo{
+		{
+			Name: "api",
+			Path: api,
+			Files: []scanner.FileInfo{{
+				Path:         filepath.Join(api, "main.go"),
+				RelativePath: "main.go",
+				Imports:      []string{"example.com/users/client"},
+			}},
+			Detection: &detect.Result{
+				Frameworks: []detect.Framework{{Name: "gin", Confidence: detect.ConfidenceHigh}},
+			},
+		},
+		{
+			Name: "users",
+			Path: users,
+			Detection: &detect.Result{
+				K8s: []detect.K8sResource{{Kind: "Service", Name: "users-svc"}},
+			},
+		},
+		{Name: "billing", Path: billing},
+	})
+
+	got := []string{}
+	for _, link := range result.Links {
+		got = append(got, link.From+"->"+link.To+":"+link.Kind)
+	}
+	want := []string{"api->billing:grpc", "api->users:client", "api->users:http", "api->users:proto"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("links = %v, want %v", got, want)
+	}
+
+	if result.Services[0].Module != "example.com/api" || result.Services[0].Frameworks[0] != "gin" {
+		t.Errorf("unexpected api service %+v", result.Services[0])
+	}
+
+	mermaid := result.Mermaid()
+	for _, line := range []string{"graph LR", `api["api<br/>gin"]`, "api -->|proto| users"} {
+		if !strings.Contains(mermaid, line) {
+			t.Errorf("Mermaid output missing %q:\n%s", line, mermaid)
+		}
+	}
+}
+
+func TestClusterHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+		ok   bool
+	}{
+		{"users", "users", true},
+		{"Users-Svc", "users-svc", true},
+		{"users.default.svc.cluster.local", "users", true},
+		{"users.default.svc", "users", true},
+		{"api.example.com", "", false},
+		{"localhost.localdomain", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := clusterHost(tt.host)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("clusterHost(%q) = %q, %v; want %q, %v", tt.host, got, ok, tt.want, tt.ok)
+		}
+	}
+}
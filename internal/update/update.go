@@ -0,0 +1,376 @@
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	releasesURL   = "https://api.github.com/repos/pistachionet/codepigeon/releases/latest"
+	checksumsName = "checksums.txt"
+	binaryName    = "codedoc"
+)
+
+type Release struct {
+	Version string
+	URL     string
+	Assets  []Asset
+}
+
+type Asset struct {
+	Name        string
+	DownloadURL string
+}
+
+type Options struct {
+	CurrentVersion string
+	Force          bool
+	Client         *http.Client
+}
+
+type Result struct {
+	PreviousVersion string
+	NewVersion      string
+	Updated         bool
+	BinaryPath      string
+}
+
+func LatestRelease(ctx context.Context, client *http.Client) (Release, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", releasesURL, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("release lookup failed with status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+		Assets  []struct {
+			Name               string `json:"name"`
+			BrowserDownloadURL string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return Release{}, err
+	}
+
+	release := Release{
+		Version: payload.TagName,
+		URL:     payload.HTMLURL,
+	}
+	for _, asset := range payload.Assets {
+		release.Assets = append(release.Assets, Asset{
+			Name:        asset.Name,
+			DownloadURL: asset.BrowserDownloadURL,
+		})
+	}
+
+	return release, nil
+}
+
+// CheckForUpdate returns the latest release when it is newer than the
+// running version. Development builds never report an update.
+func CheckForUpdate(ctx context.Context, currentVersion string) (Release, bool, error) {
+	if currentVersion == "" || currentVersion == "dev" {
+		return Release{}, false, nil
+	}
+
+	release, err := LatestRelease(ctx, nil)
+	if err != nil {
+		return Release{}, false, err
+	}
+
+	return release, IsNewer(release.Version, currentVersion), nil
+}
+
+func SelfUpdate(ctx context.Context, opts Options) (Result, error) {
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+
+	result := Result{PreviousVersion: opts.CurrentVersion}
+
+	release, err := LatestRelease(ctx, client)
+	if err != nil {
+		return result, fmt.Errorf("failed to look up latest release: %w", err)
+	}
+	result.NewVersion = release.Version
+
+	if !opts.Force && !IsNewer(release.Version, opts.CurrentVersion) {
+		return result, nil
+	}
+
+	archive, ok := findArchive(release.Assets, runtime.GOOS, runtime.GOARCH)
+	if !ok {
+		return result, fmt.Errorf("no release archive for %s/%s in %s", runtime.GOOS, runtime.GOARCH, release.Version)
+	}
+
+	checksums, ok := findAsset(release.Assets, checksumsName)
+	if !ok {
+		return result, fmt.Errorf("release %s has no %s", release.Version, checksumsName)
+	}
+
+	checksumData, err := download(ctx, client, checksums.DownloadURL)
+	if err != nil {
+		return result, fmt.Errorf("failed to download checksums: %w", err)
+	}
+
+	expected, err := lookupChecksum(checksumData, archive.Name)
+	if err != nil {
+		return result, err
+	}
+
+	archiveData, err := download(ctx, client, archive.DownloadURL)
+	if err != nil {
+		return result, fmt.Errorf("failed to download %s: %w", archive.Name, err)
+	}
+
+	if err := verifyChecksum(archiveData, expected); err != nil {
+		return result, fmt.Errorf("%s: %w", archive.Name, err)
+	}
+
+	binary, err := extractBinary(archive.Name, archiveData)
+	if err != nil {
+		return result, err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return result, fmt.Errorf("failed to locate running executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	if err := replaceExecutable(executable, binary); err != nil {
+		return result, err
+	}
+
+	result.Updated = true
+	result.BinaryPath = executable
+	return result, nil
+}
+
+func findArchive(assets []Asset, goos, goarch string) (Asset, bool) {
+	suffixes := []string{
+		fmt.Sprintf("_%s_%s.tar.gz", goos, goarch),
+		fmt.Sprintf("_%s_%s.zip", goos, goarch),
+	}
+
+	for _, asset := range assets {
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(asset.Name, suffix) {
+				return asset, true
+			}
+		}
+	}
+	return Asset{}, false
+}
+
+func findAsset(assets []Asset, name string) (Asset, bool) {
+	for _, asset := range assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func lookupChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum listed for %s", name)
+}
+
+func verifyChecksum(data []byte, expected string) error {
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+func extractBinary(archiveName string, data []byte) ([]byte, error) {
+	want := binaryName
+	if runtime.GOOS == "windows" {
+		want += ".exe"
+	}
+
+	if strings.HasSuffix(archiveName, ".zip") {
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range reader.File {
+			if filepath.Base(file.Name) != want {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		}
+		return nil, fmt.Errorf("%s not found in %s", want, archiveName)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == want {
+			return io.ReadAll(tr)
+		}
+	}
+
+	return nil, fmt.Errorf("%s not found in %s", want, archiveName)
+}
+
+// replaceExecutable writes the new binary next to the running one and renames
+// it into place, keeping the old binary until the rename succeeds.
+func replaceExecutable(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".codedoc-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to stage update in %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()|0o111); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	oldPath := path + ".old"
+	os.Remove(oldPath)
+	if err := os.Rename(path, oldPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Rename(oldPath, path)
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+	os.Remove(oldPath)
+
+	return nil
+}
+
+// IsNewer reports whether version a is strictly newer than b. Both may carry
+// a leading "v"; pre-release suffixes are ignored.
+func IsNewer(a, b string) bool {
+	if b == "" || b == "dev" {
+		return true
+	}
+
+	pa := parseVersion(a)
+	pb := parseVersion(b)
+	for i := 0; i < 3; i++ {
+		if pa[i] != pb[i] {
+			return pa[i] > pb[i]
+		}
+	}
+	return false
+}
+
+func parseVersion(v string) [3]int {
+	var parts [3]int
+
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if idx := strings.IndexAny(v, "-+"); idx >= 0 {
+		v = v[:idx]
+	}
+
+	for i, field := range strings.SplitN(v, ".", 3) {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts[i] = n
+	}
+	return parts
+}
@@ -0,0 +1,59 @@
+package update
+
+import (
+	"testing"
+)
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.2.0", "1.2.0", false},
+		{"v1.10.0", "v1.9.0", true},
+		{"v2.0.0-rc1", "v1.9.9", true},
+		{"v1.0.0", "v1.0.1", false},
+		{"v0.1.0", "dev", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			result := IsNewer(tt.a, tt.b)
+			if result != tt.expected {
+				t.Errorf("IsNewer(%s, %s) = %v, want %v", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLookupChecksum(t *testing.T) {
+	checksums := []byte("abc123  codepigeon_1.0.0_linux_amd64.tar.gz\ndef456  codepigeon_1.0.0_darwin_arm64.tar.gz\n")
+
+	sum, err := lookupChecksum(checksums, "codepigeon_1.0.0_darwin_arm64.tar.gz")
+	if err != nil {
+		t.Fatalf("lookupChecksum failed: %v", err)
+	}
+	if sum != "def456" {
+		t.Errorf("Expected def456, got %s", sum)
+	}
+
+	if _, err := lookupChecksum(checksums, "missing.zip"); err == nil {
+		t.Error("Expected error for missing archive")
+	}
+}
+
+func TestFindArchive(t *testing.T) {
+	assets := []Asset{
+		{Name: "checksums.txt"},
+		{Name: "codepigeon_1.0.0_linux_amd64.tar.gz"},
+		{Name: "codepigeon_1.0.0_windows_amd64.zip"},
+	}
+
+	if asset, ok := findArchive(assets, "windows", "amd64"); !ok || asset.Name != "codepigeon_1.0.0_windows_amd64.zip" {
+		t.Errorf("Expected windows zip, got %q (found=%v)", asset.Name, ok)
+	}
+	if _, ok := findArchive(assets, "darwin", "arm64"); ok {
+		t.Error("Expected no archive for darwin/arm64")
+	}
+}
@@ -0,0 +1,420 @@
+package util
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type CommitInfo struct {
+	Hash    string
+	Author  string
+	Date    string
+	Message string
+}
+
+// GitAvailable reports whether a git binary can be found on PATH. When it
+// cannot, git operations fall back to the pure-Go implementation.
+func GitAvailable() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
+func gitCloneShallowPureGo(ctx context.Context, repoURL, targetDir string) error {
+	_, err := git.PlainCloneContext(ctx, targetDir, false, &git.CloneOptions{
+		URL:      repoURL,
+		Depth:    1,
+		Progress: os.Stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("git clone failed: %w", err)
+	}
+
+	return nil
+}
+
+func gitClonePureGo(ctx context.Context, repoURL, targetDir string) error {
+	_, err := git.PlainCloneContext(ctx, targetDir, false, &git.CloneOptions{
+		URL:      repoURL,
+		Progress: os.Stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("git clone failed: %w", err)
+	}
+
+	return nil
+}
+
+func GitLastCommit(repoPath string) (CommitInfo, error) {
+	if GitAvailable() {
+		cmd := exec.Command("git", "log", "-1", "--format=%H|%an|%ad|%s", "--date=short")
+		cmd.Dir = repoPath
+		output, err := cmd.Output()
+		if err == nil {
+			parts := strings.SplitN(strings.TrimSpace(string(output)), "|", 4)
+			if len(parts) >= 3 {
+				info := CommitInfo{Hash: parts[0], Author: parts[1], Date: parts[2]}
+				if len(parts) == 4 {
+					info.Message = parts[3]
+				}
+				return info, nil
+			}
+		}
+	}
+
+	return gitLastCommitPureGo(repoPath)
+}
+
+func gitLastCommitPureGo(repoPath string) (CommitInfo, error) {
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return CommitInfo{}, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return CommitInfo{}, err
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return CommitInfo{}, err
+	}
+
+	message, _, _ := strings.Cut(commit.Message, "\n")
+
+	return CommitInfo{
+		Hash:    commit.Hash.String(),
+		Author:  commit.Author.Name,
+		Date:    commit.Author.When.Format("2006-01-02"),
+		Message: message,
+	}, nil
+}
+
+// GitExport writes the tree of ref in repoPath to targetDir without touching
+// the repository's worktree.
+func GitExport(ctx context.Context, repoPath, ref, targetDir string) error {
+	if !GitAvailable() {
+		return gitExportPureGo(repoPath, ref, targetDir)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "archive", "--format=tar", ref)
+	cmd.Dir = repoPath
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git archive failed: %w", err)
+	}
+
+	extractErr := extractTar(stdout, targetDir)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git archive %s failed: %s", ref, strings.TrimSpace(stderr.String()))
+	}
+	return extractErr
+}
+
+func extractTar(r io.Reader, targetDir string) error {
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target := filepath.Join(targetDir, filepath.FromSlash(header.Name))
+		if !IsWithin(target, targetDir) {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeExported(target, archive, os.FileMode(header.Mode)&0o777); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func gitExportPureGo(repoPath, ref, targetDir string) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	return tree.Files().ForEach(func(file *object.File) error {
+		if !file.Mode.IsFile() {
+			return nil
+		}
+		reader, err := file.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		mode, _ := file.Mode.ToOSFileMode()
+		return writeExported(filepath.Join(targetDir, filepath.FromSlash(file.Name)), reader, mode)
+	})
+}
+
+func writeExported(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// GitRemoteURL returns the URL of the origin remote of the repository at
+// repoPath, or "" when there is none.
+func GitRemoteURL(repoPath string) string {
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return ""
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
+
+// BlameLine is who last changed a line of a file, and when.
+type BlameLine struct {
+	Author string
+	Date   string
+}
+
+// GitBlame returns the last change to each line of the file at path, in
+// whichever git repository contains it. Lines changed since the last commit
+// have an empty Author.
+func GitBlame(ctx context.Context, path string) ([]BlameLine, error) {
+	if !GitAvailable() {
+		return gitBlamePureGo(path)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "blame", "--line-porcelain", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame failed: %w", err)
+	}
+
+	lines := []BlameLine{}
+	var line BlameLine
+	for _, text := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(text, "author "):
+			line.Author = strings.TrimPrefix(text, "author ")
+			if line.Author == "Not Committed Yet" {
+				line.Author = ""
+			}
+		case strings.HasPrefix(text, "author-time "):
+			if seconds, err := strconv.ParseInt(strings.TrimPrefix(text, "author-time "), 10, 64); err == nil {
+				line.Date = time.Unix(seconds, 0).UTC().Format("2006-01-02")
+			}
+		case strings.HasPrefix(text, "\t"):
+			// The line's content ends each entry.
+			lines = append(lines, line)
+			line = BlameLine{}
+		}
+	}
+	return lines, nil
+}
+
+// gitBlamePureGo blames the committed version of the file, so its lines may
+// be off for a file with uncommitted changes.
+func gitBlamePureGo(path string) ([]BlameLine, error) {
+	repo, err := git.PlainOpenWithOptions(filepath.Dir(path), &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(worktree.Filesystem.Root(), path)
+	if err != nil {
+		return nil, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := git.Blame(commit, filepath.ToSlash(rel))
+	if err != nil {
+		return nil, fmt.Errorf("git blame failed: %w", err)
+	}
+	lines := make([]BlameLine, len(result.Lines))
+	for i, line := range result.Lines {
+		lines[i] = BlameLine{Author: line.AuthorName, Date: line.Date.UTC().Format("2006-01-02")}
+	}
+	return lines, nil
+}
+
+// FileChange is a file that differs between two commits.
+type FileChange struct {
+	// Path is the slash path after the change, or before it for deletions;
+	// OldPath is set for renames.
+	Path    string `json:"path"`
+	OldPath string `json:"old_path,omitempty"`
+	// Status is added, modified, deleted or renamed.
+	Status string `json:"status"`
+	// Patch is the file's unified diff, empty for binary files.
+	Patch string `json:"-"`
+}
+
+// GitChanges lists the files that differ between the commits from and to
+// of the repository at repoPath, with their diffs, in path order.
+func GitChanges(ctx context.Context, repoPath, from, to string) ([]FileChange, error) {
+	if !GitAvailable() {
+		return gitChangesPureGo(ctx, repoPath, from, to)
+	}
+
+	diff := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "git", append([]string{"diff", "--no-color", "--no-ext-diff", "-M"}, args...)...)
+		cmd.Dir = repoPath
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("git diff %s %s failed: %s", from, to, strings.TrimSpace(stderr.String()))
+		}
+		return string(output), nil
+	}
+
+	names, err := diff("--name-status", "-z", from, to, "--")
+	if err != nil {
+		return nil, err
+	}
+	changes := []FileChange{}
+	fields := strings.Split(strings.TrimSuffix(names, "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		change := FileChange{Path: fields[i+1]}
+		switch fields[i][0] {
+		case 'A':
+			change.Status = "added"
+		case 'D':
+			change.Status = "deleted"
+		case 'R':
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("git diff %s %s: truncated rename", from, to)
+			}
+			change.Status, change.OldPath, change.Path = "renamed", fields[i+1], fields[i+2]
+			i++
+		default:
+			change.Status = "modified"
+		}
+		changes = append(changes, change)
+	}
+
+	patch, err := diff(from, to, "--")
+	if err != nil {
+		return nil, err
+	}
+	// Both listings come in the same order; the header check guards it.
+	chunks := strings.Split(patch, "\ndiff --git ")
+	if len(chunks) == len(changes) {
+		for i, chunk := range chunks {
+			header, _, _ := strings.Cut(chunk, "\n")
+			if strings.HasSuffix(header, " b/"+changes[i].Path) && strings.Contains(chunk, "\n@@ ") {
+				changes[i].Patch = "diff --git " + strings.TrimSuffix(strings.TrimPrefix(chunk, "diff --git "), "\n") + "\n"
+			}
+		}
+	}
+	return changes, nil
+}
+
+func gitChangesPureGo(ctx context.Context, repoPath, from, to string) ([]FileChange, error) {
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	trees := make([]*object.Tree, 2)
+	for i, ref := range []string{from, to} {
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+		}
+		commit, err := repo.CommitObject(*hash)
+		if err != nil {
+			return nil, err
+		}
+		if trees[i], err = commit.Tree(); err != nil {
+			return nil, err
+		}
+	}
+
+	diff, err := object.DiffTreeWithOptions(ctx, trees[0], trees[1], object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s %s failed: %w", from, to, err)
+	}
+	changes := []FileChange{}
+	for _, c := range diff {
+		change := FileChange{Path: c.To.Name}
+		switch {
+		case c.From.Name == "":
+			change.Status = "added"
+		case c.To.Name == "":
+			change.Status, change.Path = "deleted", c.From.Name
+		case c.From.Name != c.To.Name:
+			change.Status, change.OldPath = "renamed", c.From.Name
+		default:
+			change.Status = "modified"
+		}
+		patch, err := c.PatchContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if files := patch.FilePatches(); len(files) == 1 && !files[0].IsBinary() && len(files[0].Chunks()) > 0 {
+			change.Patch = patch.String()
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
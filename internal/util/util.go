@@ -1,22 +1,69 @@
 package util
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
-func GitCloneShallow(repoURL, targetDir string) error {
-	cmd := exec.Command("git", "clone", "--depth", "1", repoURL, targetDir)
-	cmd.Stdout = os.Stdout
+// GitCloneShallow clones repoURL at depth 1. Cancelling ctx stops the clone.
+func GitCloneShallow(ctx context.Context, repoURL, targetDir string) error {
+	start := time.Now()
+	if !GitAvailable() {
+		slog.Debug("git not found, cloning with go-git", "url", repoURL)
+		if err := gitCloneShallowPureGo(ctx, repoURL, targetDir); err != nil {
+			return err
+		}
+		slog.Debug("cloned repository", "url", repoURL, "duration", time.Since(start))
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", repoURL, targetDir)
+	cmd.Stdout = os.Stderr
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("git clone failed: %w", err)
 	}
 
+	slog.Debug("cloned repository", "url", repoURL, "duration", time.Since(start))
+	return nil
+}
+
+// GitCloneSince clones the history of repoURL back to since, so the log can
+// be analyzed. When no commit is that recent, it falls back to a depth 1
+// clone. go-git cannot clone by date and fetches the full history.
+func GitCloneSince(ctx context.Context, repoURL, targetDir string, since time.Time) error {
+	start := time.Now()
+	if !GitAvailable() {
+		slog.Debug("git not found, cloning full history with go-git", "url", repoURL)
+		if err := gitClonePureGo(ctx, repoURL, targetDir); err != nil {
+			return err
+		}
+		slog.Debug("cloned repository", "url", repoURL, "duration", time.Since(start))
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "clone", "--shallow-since="+since.Format(time.RFC3339), repoURL, targetDir)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("git clone failed: %w", err)
+		}
+		slog.Debug("no commits since the history window, cloning at depth 1", "url", repoURL, "since", since)
+		if err := os.RemoveAll(targetDir); err != nil {
+			return err
+		}
+		return GitCloneShallow(ctx, repoURL, targetDir)
+	}
+
+	slog.Debug("cloned repository", "url", repoURL, "duration", time.Since(start))
 	return nil
 }
 
@@ -42,6 +89,38 @@ func IsDirectory(path string) bool {
 	return info.IsDir()
 }
 
+// IsWithin reports whether path is root or lies beneath it. Symlinks are
+// resolved for the parts of either path that exist, so a link pointing into
+// root counts as within it.
+func IsWithin(path, root string) bool {
+	path, root = resolvePath(path), resolvePath(root)
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// resolvePath makes path absolute and resolves symlinks in its longest
+// existing prefix; the missing remainder is appended unchanged.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	missing := ""
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(resolved, missing)
+		}
+		if dir == filepath.Dir(dir) {
+			return abs
+		}
+		missing = filepath.Join(filepath.Base(dir), missing)
+	}
+}
+
 func NormalizeRepoURL(url string) string {
 	url = strings.TrimSpace(url)
 	url = strings.TrimSuffix(url, ".git")
@@ -118,6 +197,16 @@ func BytesToHumanReadable(bytes int64) string {
 	}
 }
 
+// DefaultCacheDir returns the per-user cache location for codedoc, following
+// XDG_CACHE_HOME on Linux and the platform conventions elsewhere.
+func DefaultCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return ".codedoc-cache"
+	}
+	return filepath.Join(base, "codedoc")
+}
+
 func EnsureDir(path string) error {
 	if !IsDirectory(path) {
 		return os.MkdirAll(path, 0o755)
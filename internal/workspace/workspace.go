@@ -0,0 +1,204 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Project struct {
+	Name string
+	// Path is relative to the repository root, slash-separated; "." is the
+	// root itself.
+	Path string
+	Kind string
+}
+
+var (
+	goWorkUsePattern = regexp.MustCompile(`(?m)^\s*use\s+(\S+)\s*$`)
+	goWorkUseBlock   = regexp.MustCompile(`(?s)use\s*\((.*?)\)`)
+	cargoMembers     = regexp.MustCompile(`(?s)\[workspace\].*?members\s*=\s*\[(.*?)\]`)
+	quotedString     = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// Detect finds sub-project boundaries declared by workspace files (go.work,
+// pnpm-workspace.yaml, lerna.json, package.json workspaces, Cargo
+// workspaces) and by nested go.mod files. It returns nil for ordinary
+// single-project repositories.
+func Detect(repoPath string) []Project {
+	projects := make(map[string]Project)
+	add := func(dir, kind string) {
+		rel, err := filepath.Rel(repoPath, dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return
+		}
+		if _, exists := projects[rel]; !exists {
+			projects[rel] = Project{Name: projectName(dir), Path: rel, Kind: kind}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, "go.work")); err == nil {
+		for _, dir := range parseGoWork(string(data)) {
+			add(filepath.Join(repoPath, dir), "go")
+		}
+	}
+
+	for _, pattern := range jsWorkspacePatterns(repoPath) {
+		for _, dir := range expandGlob(repoPath, pattern) {
+			if fileExists(filepath.Join(dir, "package.json")) {
+				add(dir, "node")
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, "Cargo.toml")); err == nil {
+		if match := cargoMembers.FindStringSubmatch(string(data)); match != nil {
+			for _, member := range quotedString.FindAllStringSubmatch(match[1], -1) {
+				for _, dir := range expandGlob(repoPath, member[1]) {
+					add(dir, "rust")
+				}
+			}
+		}
+	}
+
+	filepath.WalkDir(repoPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != repoPath && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "go.mod" {
+			add(filepath.Dir(path), "go")
+		}
+		return nil
+	})
+
+	if len(projects) == 0 {
+		return nil
+	}
+
+	result := make([]Project, 0, len(projects))
+	for _, project := range projects {
+		result = append(result, project)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result
+}
+
+func parseGoWork(content string) []string {
+	dirs := []string{}
+	for _, block := range goWorkUseBlock.FindAllStringSubmatch(content, -1) {
+		for _, line := range strings.Split(block[1], "\n") {
+			line = strings.TrimSpace(line)
+			if idx := strings.Index(line, "//"); idx >= 0 {
+				line = strings.TrimSpace(line[:idx])
+			}
+			if line != "" {
+				dirs = append(dirs, line)
+			}
+		}
+	}
+	for _, match := range goWorkUsePattern.FindAllStringSubmatch(content, -1) {
+		if match[1] != "(" {
+			dirs = append(dirs, match[1])
+		}
+	}
+	return dirs
+}
+
+func jsWorkspacePatterns(repoPath string) []string {
+	patterns := []string{}
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, "pnpm-workspace.yaml")); err == nil {
+		var pnpm struct {
+			Packages []string `yaml:"packages"`
+		}
+		if yaml.Unmarshal(data, &pnpm) == nil {
+			patterns = append(patterns, pnpm.Packages...)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, "lerna.json")); err == nil {
+		var lerna struct {
+			Packages []string `json:"packages"`
+		}
+		if json.Unmarshal(data, &lerna) == nil {
+			patterns = append(patterns, lerna.Packages...)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, "package.json")); err == nil {
+		var pkg struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && len(pkg.Workspaces) > 0 {
+			var list []string
+			var object struct {
+				Packages []string `json:"packages"`
+			}
+			if json.Unmarshal(pkg.Workspaces, &list) == nil {
+				patterns = append(patterns, list...)
+			} else if json.Unmarshal(pkg.Workspaces, &object) == nil {
+				patterns = append(patterns, object.Packages...)
+			}
+		}
+	}
+
+	return patterns
+}
+
+// expandGlob resolves a workspace pattern relative to repoPath. Negated
+// patterns are ignored and "**" is treated as a single path segment.
+func expandGlob(repoPath, pattern string) []string {
+	if strings.HasPrefix(pattern, "!") {
+		return nil
+	}
+	pattern = strings.ReplaceAll(strings.TrimSuffix(pattern, "/"), "**", "*")
+
+	matches, err := filepath.Glob(filepath.Join(repoPath, filepath.FromSlash(pattern)))
+	if err != nil {
+		return nil
+	}
+
+	dirs := []string{}
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			dirs = append(dirs, match)
+		}
+	}
+	return dirs
+}
+
+func projectName(dir string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.Name != "" {
+			return pkg.Name
+		}
+	}
+	return filepath.Base(dir)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,70 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+
+	files := map[string]string{
+		"go.work":                 "go 1.22\n\nuse (\n\t./services/api // main API\n)\nuse ./tools\n",
+		"services/api/go.mod":     "module example.com/api\n",
+		"tools/go.mod":            "module example.com/tools\n",
+		"libs/shared/go.mod":      "module example.com/shared\n",
+		"pnpm-workspace.yaml":     "packages:\n  - 'apps/*'\n  - '!apps/ignored'\n",
+		"apps/web/package.json":   `{"name": "@acme/web"}`,
+		"apps/notjs/README.md":    "# not a package\n",
+		"node_modules/x/go.mod":   "module x\n",
+		"crates/Cargo.toml":       "",
+		"Cargo.toml":              "[workspace]\nmembers = [\"crates\"]\n",
+		"services/api/main.go":    "package main\n",
+		"libs/shared/shared.go":   "package shared\n",
+		"apps/web/src/index.ts":   "export {}\n",
+		"services/api/README.md":  "# API\n",
+		"tools/tools.go":          "package tools\n",
+		"apps/notjs/placeholder":  "",
+		"crates/src/lib.rs":       "",
+		"services/api/handler.go": "package main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects := Detect(root)
+
+	expected := map[string]string{
+		"apps/web":     "@acme/web",
+		"crates":       "crates",
+		"libs/shared":  "shared",
+		"services/api": "api",
+		"tools":        "tools",
+	}
+	if len(projects) != len(expected) {
+		t.Fatalf("Expected %d projects, got %+v", len(expected), projects)
+	}
+	for _, project := range projects {
+		if name, ok := expected[project.Path]; !ok || name != project.Name {
+			t.Errorf("Unexpected project %+v", project)
+		}
+	}
+}
+
+func TestDetectSingleProject(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if projects := Detect(root); projects != nil {
+		t.Errorf("Expected no projects for a single-module repo, got %+v", projects)
+	}
+}
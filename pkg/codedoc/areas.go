@@ -0,0 +1,47 @@
+package codedoc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+// functionalAreas clusters the file summaries with the --embeddings
+// provider. Failures are noted and leave the section out.
+func (g *generation) functionalAreas(ctx context.Context, summaries *summarize.Result) []embed.Area {
+	docs := []embed.Document{}
+	for path, summary := range summaries.FileSummaries {
+		if summary.Summary != "" {
+			docs = append(docs, embed.Document{Path: path, Text: summary.Summary + "\n" + strings.Join(summary.Functions, "\n")})
+		}
+	}
+	if len(docs) < embed.MinDocuments {
+		g.progress.Infof("Note: --embeddings skipped: %d file summaries, at least %d needed; raise --top-files or use --files-per-module", len(docs), embed.MinDocuments)
+		return nil
+	}
+	if g.config.DryRun && g.config.Embeddings != "local" {
+		g.progress.Infof("Note: --embeddings %s skipped in a dry run; use --embeddings local", g.config.Embeddings)
+		return nil
+	}
+
+	g.progress.Stage("embeddings", 0)
+	provider, err := embed.New(g.config.Embeddings)
+	if err == nil {
+		var areas []embed.Area
+		areas, err = embed.Areas(ctx, provider, docs)
+		if err == nil {
+			g.progress.Done(fmt.Sprintf("%d functional areas from %d files", len(areas), len(docs)))
+			return areas
+		}
+	}
+	if ctx.Err() != nil {
+		g.progress.Done("interrupted")
+		return nil
+	}
+	g.progress.Done("skipped")
+	g.progress.Infof("Note: --embeddings skipped: %v", err)
+	return nil
+}
@@ -0,0 +1,52 @@
+package codedoc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/deps"
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/osv"
+)
+
+// auditDependencies queries OSV.dev for the repository's pinned dependencies
+// and returns one finding per known vulnerability.
+func (g *generation) auditDependencies(ctx context.Context, repoPath string) ([]detect.Finding, error) {
+	dependencies, err := deps.Parse(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(dependencies) == 0 {
+		return nil, nil
+	}
+
+	g.progress.Infof("Auditing %d dependencies against OSV.dev...", len(dependencies))
+
+	vulnerabilities, err := osv.NewClient().Audit(ctx, dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]detect.Finding, 0, len(vulnerabilities))
+	for _, vuln := range vulnerabilities {
+		message := fmt.Sprintf("%s@%s: %s", vuln.Dependency.Name, vuln.Dependency.Version, vuln.ID)
+		if vuln.Summary != "" {
+			message += " " + vuln.Summary
+		}
+		if len(vuln.Fixed) > 0 {
+			message += fmt.Sprintf("; fixed in %s", strings.Join(vuln.Fixed, ", "))
+		} else {
+			message += "; no fixed version"
+		}
+
+		findings = append(findings, detect.Finding{
+			Severity: vuln.Severity,
+			Rule:     "vulnerable-dependency",
+			File:     vuln.Dependency.File,
+			Message:  message,
+		})
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,79 @@
+package codedoc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/capability"
+	"github.com/codepigeon/codedoc/internal/report"
+)
+
+// checkCapabilities detects the optional tools, keys and network access
+// config relies on and turns off what the missing ones would break. It
+// prints the capability matrix when something is missing, or in verbose
+// mode, and returns a note for each feature left out.
+func checkCapabilities(ctx context.Context, config *Config) []string {
+	diagrams := len(config.Paths) > 1 && (config.Format == report.FormatHTML || config.Format == report.FormatPDF)
+	matrix := capability.Detect(ctx, capability.Needs{
+		LLM:        !config.DryRun,
+		Audit:      config.Audit,
+		GitHistory: config.GitHistory,
+		Embeddings: config.Embeddings,
+		Diagrams:   diagrams,
+	})
+
+	reporter := config.Progress
+	if !matrix.Degraded() {
+		reporter.Debugf("Capabilities:\n%s", strings.TrimRight(matrix.String(), "\n"))
+		return nil
+	}
+	reporter.Infof("Capabilities:\n%s", strings.TrimRight(matrix.String(), "\n"))
+
+	limitations := degrade(config, matrix)
+	for _, limitation := range limitations {
+		reporter.Infof("Note: %s", limitation)
+	}
+	return limitations
+}
+
+// degrade turns off the settings of config that need a missing capability
+// and describes each change.
+func degrade(config *Config, matrix capability.Matrix) []string {
+	detail := map[string]string{}
+	for _, c := range matrix {
+		if c.Missing() {
+			detail[c.Name] = c.Detail
+		}
+	}
+	offline := matrix.Missing(capability.Network)
+
+	limitations := []string{}
+	if config.GitHistory && matrix.Missing(capability.Git) {
+		config.GitHistory = false
+		limitations = append(limitations, "git history and hotspots left out ("+detail[capability.Git]+")")
+	}
+	if !config.DryRun && (offline || matrix.Missing(capability.AnthropicKey)) {
+		reason := detail[capability.AnthropicKey]
+		if reason == "" {
+			reason = detail[capability.Network]
+		}
+		config.DryRun = true
+		limitations = append(limitations, "summaries left out, as with --dry-run ("+reason+")")
+	}
+	if config.Audit && offline {
+		config.Audit = false
+		limitations = append(limitations, "--audit left out ("+detail[capability.Network]+")")
+	}
+	if config.Embeddings != "" && config.Embeddings != "local" && (offline || matrix.Missing(capability.EmbeddingsKey)) {
+		reason := detail[capability.EmbeddingsKey]
+		if reason == "" {
+			reason = detail[capability.Network]
+		}
+		limitations = append(limitations, "functional areas clustered with --embeddings local instead of "+config.Embeddings+" ("+reason+")")
+		config.Embeddings = "local"
+	}
+	if matrix.Missing(capability.Mermaid) {
+		limitations = append(limitations, "diagrams shown as Mermaid source ("+detail[capability.Mermaid]+")")
+	}
+	return limitations
+}
@@ -0,0 +1,56 @@
+package codedoc
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/catalog"
+	"github.com/codepigeon/codedoc/internal/owners"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+// writeCatalogInfo creates or updates the Backstage descriptor for the
+// repository at repoPath.
+func (g *generation) writeCatalogInfo(repoPath string, reportOpts report.Options) error {
+	name := reportOpts.ScanResult.RepoMetadata.Name
+	if name == "" {
+		name = filepath.Base(repoPath)
+	}
+
+	repoURL := g.config.RepoURL
+	if repoURL == "" {
+		repoURL = util.GitRemoteURL(repoPath)
+	}
+
+	owner := ""
+	codeowners, err := owners.Load(repoPath)
+	if err != nil {
+		return err
+	}
+	if rootOwners := codeowners.OwnersOf(catalog.DefaultFileName); len(rootOwners) > 0 {
+		owner = rootOwners[0]
+	}
+
+	description := ""
+	if !g.config.DryRun && reportOpts.Summaries != nil {
+		description = firstSentence(reportOpts.Summaries.ArchitectureSummary)
+	}
+
+	return catalog.Write(g.config.CatalogInfo, catalog.Entities(catalog.Input{
+		Name:        name,
+		Description: description,
+		RepoURL:     repoURL,
+		Owner:       owner,
+		Tags:        report.Tags(reportOpts),
+		Detection:   reportOpts.DetectionResult,
+	}))
+}
+
+func firstSentence(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if i := strings.Index(text, ". "); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
@@ -0,0 +1,863 @@
+// Package codedoc runs the codedoc pipeline (scan, detect, summarize,
+// report) as a library, so other tools can embed it instead of shelling out
+// to the CLI.
+package codedoc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codepigeon/codedoc/internal/baseline"
+	appconfig "github.com/codepigeon/codedoc/internal/config"
+	"github.com/codepigeon/codedoc/internal/depmap"
+	"github.com/codepigeon/codedoc/internal/deps"
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/feedback"
+	"github.com/codepigeon/codedoc/internal/glossary"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/history"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/owners"
+	"github.com/codepigeon/codedoc/internal/profile"
+	"github.com/codepigeon/codedoc/internal/render"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/risk"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+	"github.com/codepigeon/codedoc/internal/system"
+	"github.com/codepigeon/codedoc/internal/util"
+	"github.com/codepigeon/codedoc/internal/workspace"
+)
+
+// ErrInterrupted is returned once a partial report has been written because
+// the context was cancelled.
+var ErrInterrupted = errors.New("interrupted; a partial report was written")
+
+// Report describes what a Run wrote.
+type Report struct {
+	// OutputFile is the main report; for per-project runs it is the index.
+	OutputFile     string
+	JSONOutputFile string
+	// RepoPath is the analyzed directory. Clones of RepoURL are removed
+	// before Run returns.
+	RepoPath   string
+	Scan       *ScanResult
+	Detection  *DetectionResult
+	Summaries  *Summaries
+	Provenance Provenance
+	// Projects holds one report per sub-project of a per-project run, whose
+	// own Scan, Detection and Summaries are nil.
+	Projects []*Report
+}
+
+func newReport(opts report.Options, jsonOutputFile string) *Report {
+	return &Report{
+		OutputFile:     opts.OutputFile,
+		JSONOutputFile: jsonOutputFile,
+		RepoPath:       opts.RepoPath,
+		Scan:           opts.ScanResult,
+		Detection:      opts.DetectionResult,
+		Summaries:      opts.Summaries,
+		Provenance:     opts.Provenance,
+	}
+}
+
+// Run analyzes the repository described by config and writes its report.
+// When ctx is cancelled mid-run the partial report is still written and
+// returned together with ErrInterrupted.
+func Run(ctx context.Context, config Config) (*Report, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	reporter := config.Progress
+	repoPath := config.Path
+
+	if !util.GitAvailable() {
+		reporter.Infof("Note: git not found in PATH; using built-in Go implementation for cloning and commit metadata")
+	}
+
+	if config.RepoURL != "" {
+		clonedPath, cleanupFunc, err := cloneRepository(ctx, config.RepoURL, config.GitHistory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone repository: %w", err)
+		}
+		defer cleanupFunc()
+		repoPath = clonedPath
+	}
+
+	limitations := checkCapabilities(ctx, &config)
+
+	if err := config.makeOutputDirs(); err != nil {
+		return nil, err
+	}
+
+	if len(config.Paths) > 1 {
+		reporter.Infof("Analyzing repositories: %s", strings.Join(config.Paths, ", "))
+	} else {
+		reporter.Infof("Analyzing repository: %s", repoPath)
+	}
+
+	fileConfig, err := appconfig.Resolve(config.ConfigFile, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var templates *report.Templates
+	if config.TemplateDir != "" {
+		templates, err = report.LoadTemplates(config.TemplateDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var prompts llm.Prompts
+	if config.PromptsDir != "" {
+		prompts, err = llm.LoadPrompts(config.PromptsDir)
+		if err != nil {
+			return nil, err
+		}
+		reporter.Infof("Custom prompts: %s", strings.Join(prompts.Types(), ", "))
+	}
+
+	theme, err := config.theme()
+	if err != nil {
+		return nil, err
+	}
+
+	repoID := config.Path
+	if config.RepoURL != "" {
+		repoID = config.RepoURL
+	}
+	reviews, err := feedback.Load(feedback.Path(config.CacheDir, repoID))
+	if err != nil {
+		return nil, err
+	}
+
+	baselineFile := baseline.Path(config.BaselineFile, repoPath)
+	accepted, err := baseline.Load(baselineFile)
+	if err != nil {
+		return nil, err
+	}
+
+	profileFile := profile.Path(repoPath)
+	saved, err := profile.Load(profileFile)
+	if err != nil {
+		return nil, err
+	}
+	if !saved.Empty() {
+		reporter.Infof("Applying profile: %s", profileFile)
+	}
+
+	var llmProvider llm.Provider
+	if !config.DryRun {
+		var cache llm.Cache
+		if config.CacheURL != "" {
+			cache, err = llm.NewCache(config.CacheURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open cache: %w", err)
+			}
+		}
+
+		llmProvider, err = llm.NewAnthropicProvider(llm.AnthropicConfig{
+			CacheDir:       config.CacheDir,
+			Cache:          cache,
+			Force:          config.Force,
+			Model:          config.Model,
+			MaxTokens:      config.MaxOutputTokens,
+			Temperature:    &config.Temperature,
+			Models:         fileConfig.Models,
+			Reproducible:   config.Reproducible,
+			Prompts:        prompts,
+			OutputLanguage: config.OutputLang,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create LLM provider: %w", err)
+		}
+	}
+
+	gen := &generation{
+		config:       &config,
+		fileConfig:   fileConfig,
+		baseline:     accepted,
+		baselineFile: baselineFile,
+		profile:      saved.Override(config.Profile),
+		profileFile:  profileFile,
+		templates:    templates,
+		prompts:      prompts,
+		theme:        theme,
+		feedback:     reviews,
+		provider:     llmProvider,
+		progress:     reporter,
+		limitations:  limitations,
+	}
+
+	if len(config.Paths) <= 1 && reporter.Enabled(ProgressNormal) {
+		gen.preflight(ctx, repoPath)
+	}
+
+	var result *Report
+	projects := workspace.Detect(repoPath)
+	if config.PerProject && len(projects) > 0 && len(config.Paths) <= 1 {
+		result, err = gen.runPerProject(ctx, repoPath, projects)
+	} else {
+		var reportOpts report.Options
+		if len(config.Paths) > 1 {
+			reportOpts, err = gen.runRoots(ctx, config.Paths)
+		} else {
+			reportOpts, err = gen.run(ctx, repoPath, reportTarget{
+				outputFile:     config.OutputFile,
+				jsonOutputFile: config.JSONOutputFile,
+				projects:       projects,
+			})
+		}
+		if reportOpts.OutputFile != "" {
+			result = newReport(reportOpts, config.JSONOutputFile)
+		}
+	}
+	if err != nil {
+		return result, err
+	}
+
+	elapsed := time.Since(startTime)
+	reporter.Infof("\nReport generated: %s", config.OutputFile)
+	reporter.Infof("Time elapsed: %s", elapsed.Round(time.Second))
+
+	return result, nil
+}
+
+// generation holds the state shared by every report produced in one
+// invocation, so per-project runs reuse the same provider and cache.
+type generation struct {
+	config     *Config
+	fileConfig *appconfig.File
+	baseline   *baseline.Baseline
+	// baselineFile is where --write-baseline saves the baseline.
+	baselineFile string
+	templates    *report.Templates
+	prompts      llm.Prompts
+	theme        render.Theme
+	feedback     *feedback.Store
+	provider     llm.Provider
+	progress     *Progress
+	// limitations note what missing capabilities left out of the run.
+	limitations []string
+
+	// profile is the saved profile with this run's settings applied;
+	// --save-profile writes it to profileFile.
+	profile     *profile.Profile
+	profileFile string
+}
+
+// reportTarget describes where one report is written and the extra sections
+// that only apply to it.
+type reportTarget struct {
+	outputFile     string
+	jsonOutputFile string
+	projects       []workspace.Project
+	ownerReports   []report.OwnerReport
+	roots          []report.Root
+	system         *system.Result
+}
+
+func (g *generation) run(ctx context.Context, repoPath string, target reportTarget) (report.Options, error) {
+	scanResult, err := g.scan(ctx, repoPath)
+	if err != nil {
+		return report.Options{}, err
+	}
+
+	if g.config.SplitByOwner {
+		codeowners, err := owners.Load(repoPath)
+		if err != nil {
+			return report.Options{}, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+		}
+		if codeowners == nil {
+			g.progress.Infof("Note: --split-by-owner requested but no CODEOWNERS file was found")
+		} else {
+			target.ownerReports, err = g.runPerOwner(ctx, repoPath, scanResult, codeowners, target.outputFile)
+			if err != nil {
+				return report.Options{}, err
+			}
+		}
+	}
+
+	return g.analyze(ctx, repoPath, scanResult, target)
+}
+
+func (g *generation) scan(ctx context.Context, repoPath string) (*scanner.Result, error) {
+	config := g.config
+
+	scanOpts := scanner.Options{
+		Path:         repoPath,
+		MaxFiles:     config.MaxFiles,
+		IncludeTests: config.IncludeTests,
+		Languages:    config.Languages,
+		Tests:        g.fileConfig.Tests,
+		Progress:     g.progress,
+	}
+
+	g.progress.Stage("scan", 0)
+	scanResult, err := scanner.Scan(ctx, scanOpts)
+	if err != nil && ctx.Err() != nil {
+		// The files found so far still make a partial report.
+		g.progress.Done("interrupted")
+		return scanResult, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan failed: %w", err)
+	}
+	g.progress.Done(fmt.Sprintf("%d files (%d lines)", len(scanResult.Files), scanResult.TotalLines))
+
+	return scanResult, nil
+}
+
+// maxEndpoints prefers an explicit --max-endpoints over report.max_endpoints
+// in codedoc.yaml, which in turn overrides the flag default.
+func (g *generation) maxEndpoints() int {
+	if _, explicit := g.config.Flags["max-endpoints"]; !explicit && g.fileConfig.Report.MaxEndpoints != nil {
+		return *g.fileConfig.Report.MaxEndpoints
+	}
+	return g.config.MaxEndpoints
+}
+
+func (g *generation) analyze(ctx context.Context, repoPath string, scanResult *scanner.Result, target reportTarget) (report.Options, error) {
+	detectionResult, err := g.detect(ctx, repoPath, scanResult)
+	if err != nil {
+		return report.Options{}, err
+	}
+	return g.document(ctx, repoPath, scanResult, detectionResult, target)
+}
+
+func (g *generation) detect(ctx context.Context, repoPath string, scanResult *scanner.Result) (*detect.Result, error) {
+	detectOpts := detect.Options{
+		Files:    scanResult.Files,
+		Rules:    g.fileConfig.DetectRules(),
+		RepoPath: repoPath,
+		Tests:    g.fileConfig.Tests,
+		Progress: g.progress,
+	}
+
+	g.progress.Stage("detect", len(scanResult.Files))
+	detectionResult, err := detect.Detect(ctx, detectOpts)
+	if err != nil && ctx.Err() != nil {
+		g.progress.Done("interrupted")
+		return detectionResult, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("detection failed: %w", err)
+	}
+	g.progress.Done(fmt.Sprintf("%d frameworks, %d endpoints, %d findings",
+		len(detectionResult.Frameworks), len(detectionResult.Endpoints), len(detectionResult.Findings)))
+
+	if g.config.Audit {
+		g.progress.Stage("audit", 0)
+		findings, err := g.auditDependencies(ctx, repoPath)
+		if err != nil && ctx.Err() != nil {
+			g.progress.Done("interrupted")
+			return detectionResult, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("dependency audit failed: %w", err)
+		}
+		detectionResult.Findings = append(detectionResult.Findings, findings...)
+		detect.SortFindings(detectionResult.Findings)
+		g.progress.Done(fmt.Sprintf("%d vulnerable dependencies", len(findings)))
+	}
+
+	return detectionResult, nil
+}
+
+// document summarizes the analysis and writes the report and its side
+// artifacts to target.
+func (g *generation) document(ctx context.Context, repoPath string, scanResult *scanner.Result, detectionResult *detect.Result, target reportTarget) (report.Options, error) {
+	config := g.config
+
+	var internalDeps *depmap.Result
+	var err error
+	prefixes := append(append([]string{}, config.InternalPrefix...), g.fileConfig.InternalPrefixes...)
+	if len(prefixes) > 0 {
+		internalDeps, err = depmap.Map(ctx, depmap.Options{
+			Module:   depmap.ModuleName(repoPath),
+			Files:    scanResult.Files,
+			Prefixes: prefixes,
+			OrgPaths: config.OrgPaths,
+		})
+		if err != nil && ctx.Err() == nil {
+			return report.Options{}, fmt.Errorf("internal dependency mapping failed: %w", err)
+		}
+	}
+
+	dependencies, err := deps.Parse(repoPath)
+	if err != nil {
+		return report.Options{}, fmt.Errorf("dependency inventory failed: %w", err)
+	}
+
+	var gitHistory *history.Result
+	if config.GitHistory {
+		g.progress.Stage("history", 0)
+		gitHistory, err = history.Analyze(ctx, repoPath)
+		switch {
+		case ctx.Err() != nil:
+			g.progress.Done("interrupted")
+		case err != nil:
+			g.progress.Done("skipped")
+			g.progress.Infof("Note: --git-history skipped: %v", err)
+		default:
+			g.progress.Done(fmt.Sprintf("%d commits by %d contributors", gitHistory.Commits, len(gitHistory.Contributors)))
+		}
+	}
+
+	codeowners, err := owners.Load(repoPath)
+	if err != nil {
+		return report.Options{}, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+
+	importGraph := graph.Build(scanResult.Files, depmap.ModuleName(repoPath))
+	var seams []graph.Seam
+	if config.Decompose {
+		seams = importGraph.Seams()
+		if seams == nil {
+			g.progress.Infof("Note: --decompose found no split: the import graph does not form two or more clusters")
+		}
+	}
+
+	var terms []glossary.Term
+	if config.Glossary {
+		terms = glossary.Mine(scanResult.Files, detectionResult)
+	}
+
+	// Dry runs make no LLM requests, so there is nothing to checkpoint.
+	var checkpoint *summarize.Checkpoint
+	if !config.DryRun {
+		checkpoint, err = summarize.OpenCheckpoint(summarize.CheckpointPath(config.CacheDir, repoPath, target.outputFile), config.Resume)
+		if err != nil {
+			return report.Options{}, err
+		}
+		if config.Resume {
+			g.progress.Infof("Resuming: %d summaries saved by the interrupted run", checkpoint.Saved())
+		}
+	}
+
+	summarizeOpts := summarize.Options{
+		ScanResult:      scanResult,
+		DetectionResult: detectionResult,
+		MaxLinesPerFile: config.MaxLinesPerFile,
+		LLMProvider:     g.provider,
+		RedactSecrets:   config.RedactSecrets,
+		Progress:        g.progress,
+		Checkpoint:      checkpoint,
+		Feedback:        g.feedback,
+		Seams:           seams,
+		Glossary:        terms,
+		Stages:          config.SummaryStages,
+		Middleware:      config.SummaryMiddleware,
+		Modules:         g.profile.Modules,
+		PriorityFiles:   g.profile.PriorityFiles,
+		TopFiles:        config.TopFiles,
+		FilesPerModule:  config.FilesPerModule,
+		FileTokenBudget: config.FileTokenBudget,
+		MapReduce:       config.MapReduce,
+		Graph:           importGraph,
+		History:         gitHistory,
+	}
+	if len(g.profile.Prompts) > 0 {
+		summarizeOpts.Middleware = append(slices.Clone(config.SummaryMiddleware), summarize.TransformRequests(g.profile.Guide))
+	}
+
+	summaries, err := summarize.Summarize(ctx, summarizeOpts)
+	if err != nil {
+		return report.Options{}, fmt.Errorf("summarization failed: %w", err)
+	}
+
+	if config.Review != nil && !summaries.Incomplete {
+		if err := config.Review(ctx, summarizeOpts, summaries); err != nil {
+			return report.Options{}, err
+		}
+		if err := g.feedback.Save(); err != nil {
+			return report.Options{}, err
+		}
+	}
+
+	var areas []embed.Area
+	if config.Embeddings != "" && !summaries.Incomplete {
+		areas = g.functionalAreas(ctx, summaries)
+	}
+
+	riskRules, err := risk.Compile(g.fileConfig.Risks)
+	if err != nil {
+		return report.Options{}, err
+	}
+	riskRules = slices.DeleteFunc(riskRules, func(rule risk.Rule) bool {
+		return slices.Contains(g.profile.SuppressedRisks, rule.ID)
+	})
+
+	reportOpts := report.Options{
+		RepoPath:        repoPath,
+		RepoURL:         config.RepoURL,
+		ScanResult:      scanResult,
+		DetectionResult: detectionResult,
+		Summaries:       summaries,
+		OutputFile:      target.outputFile,
+		Provenance:      buildProvenance(config, scanResult, g.prompts, g.fileConfig.Models, g.limitations),
+		InternalDeps:    internalDeps,
+		Dependencies:    dependencies,
+		History:         gitHistory,
+		Codeowners:      codeowners,
+		Glossary:        terms,
+		Seams:           seams,
+		FunctionalAreas: areas,
+		ImportGraph:     importGraph,
+		Projects:        target.projects,
+		OwnerReports:    target.ownerReports,
+		Roots:           target.roots,
+		System:          target.system,
+		Format:          config.Format,
+		MaxEndpoints:    g.maxEndpoints(),
+		SplitPages:      g.config.OutDir != "",
+		Baseline:        g.baseline,
+		Templates:       g.templates,
+		Theme:           g.theme,
+		RiskRules:       riskRules,
+		Language:        config.OutputLang,
+	}
+
+	// An interrupted run still renders what it gathered, so the report is
+	// written with a context that is no longer cancelled.
+	reportCtx := ctx
+	if summaries.Incomplete {
+		reportCtx = context.WithoutCancel(ctx)
+	}
+
+	g.progress.Stage("report", 0)
+	if err := report.Generate(reportCtx, reportOpts); err != nil {
+		return report.Options{}, fmt.Errorf("report generation failed: %w", err)
+	}
+	g.progress.Done(target.outputFile)
+
+	if config.WriteBaseline && target.outputFile == config.OutputFile {
+		if err := baseline.Write(g.baselineFile, report.BaselineEntries(reportOpts)); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("Baseline written: %s", g.baselineFile)
+	}
+
+	if config.SaveProfile && target.outputFile == config.OutputFile {
+		if err := g.saveProfile(summaries); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("Profile written: %s", g.profileFile)
+	}
+
+	if config.CatalogInfo != "" && target.outputFile == config.OutputFile {
+		if err := g.writeCatalogInfo(repoPath, reportOpts); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("Catalog descriptor written: %s", config.CatalogInfo)
+	}
+
+	if config.Readme != "" && target.outputFile == config.OutputFile {
+		if err := g.writeReadme(repoPath, reportOpts); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("README written: %s", config.Readme)
+	}
+
+	if config.CycloneDX != "" && target.outputFile == config.OutputFile {
+		if err := g.writeCycloneDX(repoPath, reportOpts); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("CycloneDX BOM written: %s", config.CycloneDX)
+	}
+
+	if config.EmitTables != "" && target.outputFile == config.OutputFile {
+		format := config.TablesFormat
+		if format == "" {
+			format = report.TableFormatCSV
+		}
+		paths, err := report.WriteTables(reportOpts, config.EmitTables, format)
+		if err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("Tables written: %d files in %s", len(paths), config.EmitTables)
+	}
+
+	if config.EmitSQLite != "" && target.outputFile == config.OutputFile {
+		if err := report.WriteSQLite(reportOpts, config.EmitSQLite); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("SQLite database written: %s", config.EmitSQLite)
+	}
+
+	if config.EmitEditor != "" && target.outputFile == config.OutputFile {
+		if err := report.WriteEditorIndex(reportOpts, config.EmitEditor); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("Editor index written: %s", config.EmitEditor)
+	}
+
+	if config.SaveIndex != "" && target.outputFile == config.OutputFile {
+		if err := g.saveIndex(ctx, reportOpts); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("Index written: %s", config.SaveIndex)
+	}
+
+	if config.Reproducible {
+		manifestFile := strings.TrimSuffix(target.outputFile, filepath.Ext(target.outputFile)) + ".manifest.json"
+		if err := report.WriteManifest(reportOpts.Provenance, manifestFile); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("Manifest written: %s", manifestFile)
+	}
+
+	if target.jsonOutputFile != "" {
+		if err := report.WriteJSON(reportOpts, target.jsonOutputFile); err != nil {
+			return report.Options{}, err
+		}
+		g.progress.Infof("JSON artifact written: %s", target.jsonOutputFile)
+
+		if config.SignKey != "" {
+			sigPath, err := report.SignFile(target.jsonOutputFile, config.SignKey)
+			if err != nil {
+				return report.Options{}, err
+			}
+			g.progress.Infof("Signature written: %s", sigPath)
+		}
+	}
+
+	if summaries.Incomplete {
+		return reportOpts, ErrInterrupted
+	}
+	if err := checkpoint.Remove(); err != nil {
+		slog.Warn("Failed to remove checkpoint", "err", err)
+	}
+	return reportOpts, nil
+}
+
+// runPerProject writes one report per workspace project next to the main
+// output file, then replaces the main output with an index linking to them.
+func (g *generation) runPerProject(ctx context.Context, repoPath string, projects []workspace.Project) (*Report, error) {
+	base := strings.TrimSuffix(g.config.OutputFile, filepath.Ext(g.config.OutputFile))
+	ext := report.FormatExtension(g.config.Format)
+	entries := []report.IndexEntry{}
+	index := &Report{OutputFile: g.config.OutputFile, RepoPath: repoPath}
+
+	for _, project := range projects {
+		slug := strings.NewReplacer("/", "-", "@", "", " ", "-").Replace(project.Path)
+		outputFile := fmt.Sprintf("%s-%s%s", base, slug, ext)
+
+		jsonOutputFile := ""
+		if g.config.JSONOutputFile != "" {
+			jsonOutputFile = fmt.Sprintf("%s-%s.json", strings.TrimSuffix(g.config.JSONOutputFile, filepath.Ext(g.config.JSONOutputFile)), slug)
+		}
+
+		g.progress.Infof("\nProject %s (%s)", project.Name, project.Path)
+		reportOpts, err := g.run(ctx, filepath.Join(repoPath, filepath.FromSlash(project.Path)), reportTarget{
+			outputFile:     outputFile,
+			jsonOutputFile: jsonOutputFile,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", project.Name, err)
+		}
+		index.Projects = append(index.Projects, newReport(reportOpts, jsonOutputFile))
+
+		entries = append(entries, report.IndexEntry{
+			Project:    project,
+			ReportFile: outputFile,
+			Files:      reportOpts.ScanResult.TotalFiles,
+			Lines:      reportOpts.ScanResult.TotalLines,
+			Languages:  report.LanguageSummary(reportOpts),
+		})
+	}
+
+	if err := report.WriteIndex(ctx, g.config.OutputFile, filepath.Base(repoPath), g.config.Format, g.config.OutputLang, g.theme, entries); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// runRoots analyzes several repositories as one system. Each root is scanned
+// and detected on its own, so manifests and specs resolve against the right
+// tree, then the results are merged with paths prefixed by the root's name.
+// The first root is the primary one: it names the module for internal
+// dependency mapping.
+func (g *generation) runRoots(ctx context.Context, repoPaths []string) (report.Options, error) {
+	names := rootNames(repoPaths)
+	scans := make([]*scanner.Result, len(repoPaths))
+	detections := make([]*detect.Result, len(repoPaths))
+	roots := make([]report.Root, len(repoPaths))
+	repos := make([]system.Repo, len(repoPaths))
+
+	for i, repoPath := range repoPaths {
+		g.progress.Infof("\nRepository %s (%s)", names[i], repoPath)
+		scanResult, err := g.scan(ctx, repoPath)
+		if err != nil {
+			return report.Options{}, fmt.Errorf("repository %s: %w", names[i], err)
+		}
+		detectionResult, err := g.detect(ctx, repoPath, scanResult)
+		if err != nil {
+			return report.Options{}, fmt.Errorf("repository %s: %w", names[i], err)
+		}
+		scans[i], detections[i] = scanResult, detectionResult
+		roots[i] = report.Root{Name: names[i], Path: repoPath, Scan: scanResult}
+		repos[i] = system.Repo{Name: names[i], Path: repoPath, Files: scanResult.Files, Detection: detectionResult}
+	}
+
+	g.progress.Stage("stitch", 0)
+	stitched := system.Stitch(repos)
+	g.progress.Done(fmt.Sprintf("%d cross-repository references", len(stitched.Links)))
+
+	g.progress.Infof("")
+	return g.document(ctx, repoPaths[0], scanner.Merge(names, scans), detect.Merge(names, detections), reportTarget{
+		outputFile:     g.config.OutputFile,
+		jsonOutputFile: g.config.JSONOutputFile,
+		roots:          roots,
+		system:         stitched,
+	})
+}
+
+// rootNames names each repository after its directory, numbering repeats
+// (api, api-2) so merged paths stay unambiguous.
+func rootNames(repoPaths []string) []string {
+	names := make([]string, len(repoPaths))
+	seen := make(map[string]int)
+	for i, repoPath := range repoPaths {
+		name := filepath.Base(repoPath)
+		if abs, err := filepath.Abs(repoPath); err == nil {
+			name = filepath.Base(abs)
+		}
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, seen[name])
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// runPerOwner writes one report per CODEOWNERS owner covering only the files
+// they own. The caller's report becomes the shared overview linking to them.
+func (g *generation) runPerOwner(ctx context.Context, repoPath string, scanResult *scanner.Result, codeowners *owners.Owners, outputFile string) ([]report.OwnerReport, error) {
+	byOwner := make(map[string]bool)
+	for _, file := range scanResult.Files {
+		for _, owner := range codeowners.OwnersOf(file.RelativePath) {
+			byOwner[owner] = true
+		}
+	}
+
+	ownerNames := make([]string, 0, len(byOwner))
+	for owner := range byOwner {
+		ownerNames = append(ownerNames, owner)
+	}
+	sort.Strings(ownerNames)
+
+	base := strings.TrimSuffix(outputFile, filepath.Ext(outputFile))
+	ownerReports := []report.OwnerReport{}
+
+	for _, owner := range ownerNames {
+		subset := scanner.Subset(scanResult, func(file scanner.FileInfo) bool {
+			for _, o := range codeowners.OwnersOf(file.RelativePath) {
+				if o == owner {
+					return true
+				}
+			}
+			return false
+		})
+
+		slug := strings.NewReplacer("@", "", "/", "-", ".", "-").Replace(owner)
+		ownerFile := fmt.Sprintf("%s-owner-%s%s", base, slug, report.FormatExtension(g.config.Format))
+
+		g.progress.Infof("\nOwner %s (%d files)", owner, subset.TotalFiles)
+		if _, err := g.analyze(ctx, repoPath, subset, reportTarget{outputFile: ownerFile}); err != nil {
+			return nil, fmt.Errorf("owner %s: %w", owner, err)
+		}
+
+		ownerReports = append(ownerReports, report.OwnerReport{
+			Owner:      owner,
+			ReportFile: ownerFile,
+			Files:      subset.TotalFiles,
+		})
+	}
+
+	return ownerReports, nil
+}
+
+func buildProvenance(config *Config, scanResult *scanner.Result, prompts llm.Prompts, models map[llm.SummaryType]string, limitations []string) report.Provenance {
+	model := config.Model
+	if config.DryRun {
+		model = "none (dry run)"
+	}
+
+	temperature := config.Temperature
+	if config.Reproducible {
+		temperature = 0
+	}
+
+	provenance := report.Provenance{
+		ToolVersion:   config.toolVersion(),
+		Model:         model,
+		PromptVersion: llm.PromptVersion,
+		CommitSHA:     scanResult.RepoMetadata.LastCommit.Hash,
+		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+		Temperature:   temperature,
+		Reproducible:  config.Reproducible,
+		Flags:         config.Flags,
+		Prompts:       prompts.Digests(),
+		Limitations:   limitations,
+	}
+	if !config.DryRun && len(models) > 0 {
+		provenance.Models = map[string]string{}
+		for summaryType, model := range models {
+			provenance.Models[string(summaryType)] = model
+		}
+	}
+
+	if config.Reproducible {
+		for _, file := range scanResult.Files {
+			provenance.Inputs = append(provenance.Inputs, report.InputFile{
+				Path:  file.RelativePath,
+				Hash:  file.Hash,
+				Lines: file.Lines,
+			})
+		}
+		sort.Slice(provenance.Inputs, func(i, j int) bool {
+			return provenance.Inputs[i].Path < provenance.Inputs[j].Path
+		})
+	}
+
+	return provenance
+}
+
+func cloneRepository(ctx context.Context, repoURL string, withHistory bool) (string, func(), error) {
+	tempDir, err := os.MkdirTemp("", "codedoc-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+
+	cleanupFunc := func() {
+		os.RemoveAll(tempDir)
+	}
+
+	clone := util.GitCloneShallow
+	if withHistory {
+		clone = func(ctx context.Context, repoURL, targetDir string) error {
+			return util.GitCloneSince(ctx, repoURL, targetDir, time.Now().Add(-history.Window))
+		}
+	}
+	if err := clone(ctx, repoURL, tempDir); err != nil {
+		cleanupFunc()
+		return "", nil, err
+	}
+
+	return tempDir, cleanupFunc, nil
+}
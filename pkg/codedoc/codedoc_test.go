@@ -0,0 +1,188 @@
+package codedoc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codepigeon/codedoc/internal/capability"
+)
+
+func TestRunDryRun(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := t.TempDir()
+	config := DefaultConfig(repo)
+	config.DryRun = true
+	config.OutputFile = filepath.Join(out, "REPORT.md")
+	config.JSONOutputFile = filepath.Join(out, "report.json")
+
+	result, err := Run(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OutputFile != config.OutputFile || result.JSONOutputFile != config.JSONOutputFile {
+		t.Errorf("Expected the configured outputs, got %s and %s", result.OutputFile, result.JSONOutputFile)
+	}
+	if result.Scan == nil || result.Scan.TotalFiles != 1 || result.Detection == nil || result.Summaries == nil {
+		t.Fatalf("Expected the scan, detection and summaries of one file, got %+v", result)
+	}
+	if result.Provenance.ToolVersion != "dev" {
+		t.Errorf("ToolVersion = %q, want dev", result.Provenance.ToolVersion)
+	}
+
+	content, err := os.ReadFile(config.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "main.go") {
+		t.Error("Expected the report to mention main.go")
+	}
+	if _, err := os.Stat(config.JSONOutputFile); err != nil {
+		t.Errorf("Expected a JSON artifact: %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := t.TempDir()
+	config := DefaultConfig(repo)
+	config.DryRun = true
+	config.OutputFile = filepath.Join(out, "REPORT.md")
+	config.SaveIndex = filepath.Join(out, "index.codedoc")
+	if _, err := Run(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+
+	rendered := DefaultConfig("")
+	rendered.OutputFile = filepath.Join(out, "REPORT.html")
+	rendered.Format = "html"
+	result, err := Render(context.Background(), config.SaveIndex, rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RepoPath != repo || result.Scan == nil || result.Scan.TotalFiles != 1 {
+		t.Errorf("Expected the indexed analysis, got %+v", result)
+	}
+	content, err := os.ReadFile(rendered.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "<html") || !strings.Contains(string(content), "main.go") {
+		t.Errorf("Expected an HTML report mentioning main.go:\n%s", content)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"defaults", func(c *Config) {}, ""},
+		{"no path", func(c *Config) { c.Path = "" }, "--path or --repo-url"},
+		{"path and url", func(c *Config) { c.RepoURL = "https://example.com/r.git" }, "both --path and --repo-url"},
+		{"zero config", func(c *Config) { *c = Config{Path: "."} }, "--max-files"},
+		{"format", func(c *Config) { c.Format = "docx" }, "--format"},
+		{"max output tokens", func(c *Config) { c.MaxOutputTokens = 0 }, "--max-output-tokens"},
+		{"temperature", func(c *Config) { c.Temperature = 1.5 }, "--temperature"},
+		{"temperature and reproducible", func(c *Config) {
+			c.Temperature, c.Reproducible = 0.5, true
+			c.Flags = map[string]string{"temperature": "0.5"}
+		}, "--temperature and --reproducible"},
+		{"theme", func(c *Config) { c.Format, c.Theme = "html", "solarized" }, "--theme"},
+		{"theme without html", func(c *Config) { c.Header = "Acme" }, "--format html or pdf"},
+		{"html theme", func(c *Config) { c.Format, c.Theme, c.Logo = "pdf", "dark", "logo.png" }, ""},
+		{"output language", func(c *Config) { c.OutputLang = "Japanese" }, "--output-lang"},
+		{"regional output language", func(c *Config) { c.OutputLang = "pt-BR" }, ""},
+		{"tables format", func(c *Config) { c.EmitTables, c.TablesFormat = "tables", "xlsx" }, "--tables-format"},
+		{"tables per project", func(c *Config) { c.EmitTables, c.PerProject = "tables", true }, "--emit-tables"},
+		{"sqlite per project", func(c *Config) { c.EmitSQLite, c.PerProject = "analysis.db", true }, "--emit-sqlite"},
+		{"editor index per project", func(c *Config) { c.EmitEditor, c.PerProject = "editor.json", true }, "--emit-editor"},
+		{"index per project", func(c *Config) { c.SaveIndex, c.PerProject = "index.codedoc", true }, "--save-index"},
+		{"readme of several paths", func(c *Config) { c.Readme, c.Paths = "README.md", []string{"a", "b"} }, "--readme"},
+		{"embeddings provider", func(c *Config) { c.Embeddings = "voyage" }, "--embeddings"},
+		{"resume dry run", func(c *Config) { c.Resume, c.DryRun = true, true }, "--resume"},
+		{"read-only source", func(c *Config) {
+			c.ReadOnlySource, c.DryRun = true, true
+			c.OutputFile = filepath.Join("repo", "REPORT.md")
+		}, "--read-only-source"},
+		{"read-only source checkpoints", func(c *Config) {
+			c.ReadOnlySource, c.CacheURL = true, "redis://localhost:6379"
+			c.OutputFile, c.CacheDir = "REPORT.md", filepath.Join("repo", ".cache")
+		}, "--cache-dir"},
+		{"read-only source dry run cache", func(c *Config) {
+			c.ReadOnlySource, c.DryRun = true, true
+			c.OutputFile, c.CacheDir = "REPORT.md", filepath.Join("repo", ".cache")
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig("repo")
+			tt.modify(&config)
+
+			err := config.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDegrade(t *testing.T) {
+	missing := func(names ...string) capability.Matrix {
+		matrix := capability.Matrix{}
+		for _, name := range names {
+			matrix = append(matrix, capability.Capability{Name: name, Needed: true, Detail: name + " missing"})
+		}
+		return matrix
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		matrix capability.Matrix
+		want   func(Config) bool
+		notes  int
+	}{
+		{"nothing missing", func(c *Config) { c.GitHistory = true }, missing(), func(c Config) bool { return c.GitHistory && !c.DryRun }, 0},
+		{"no api key", func(c *Config) {}, missing(capability.AnthropicKey), func(c Config) bool { return c.DryRun }, 1},
+		{"offline", func(c *Config) { c.Audit, c.Embeddings = true, "openai" }, missing(capability.Network),
+			func(c Config) bool { return c.DryRun && !c.Audit && c.Embeddings == "local" }, 3},
+		{"no git", func(c *Config) { c.GitHistory = true }, missing(capability.Git), func(c Config) bool { return !c.GitHistory }, 1},
+		{"no embeddings key", func(c *Config) { c.Embeddings = "anthropic" }, missing(capability.EmbeddingsKey),
+			func(c Config) bool { return !c.DryRun && c.Embeddings == "local" }, 1},
+		{"no mermaid", func(c *Config) {}, missing(capability.Mermaid), func(c Config) bool { return !c.DryRun }, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig("repo")
+			tt.modify(&config)
+
+			notes := degrade(&config, tt.matrix)
+			if !tt.want(config) {
+				t.Errorf("Unexpected config after degrade: %+v", config)
+			}
+			if len(notes) != tt.notes {
+				t.Errorf("Expected %d notes, got %q", tt.notes, notes)
+			}
+		})
+	}
+}
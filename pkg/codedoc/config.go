@@ -0,0 +1,414 @@
+package codedoc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/baseline"
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/profile"
+	"github.com/codepigeon/codedoc/internal/render"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+// Config mirrors the flags of `codedoc generate`. Start from DefaultConfig:
+// the zero value fails validation.
+type Config struct {
+	Path  string
+	Paths []string
+	// RepoURL is cloned into a temporary directory instead of reading Path.
+	RepoURL    string
+	OutputFile string
+	// OutputDir is created before anything is written. Relative output
+	// paths are not rebased onto it; join them before calling Run.
+	OutputDir string
+	// OutDir splits the report into pages next to OutputFile, which should
+	// be OutDir/index plus the format's extension.
+	OutDir        string
+	BaselineFile  string
+	TemplateDir   string
+	WriteBaseline bool
+	// PromptsDir holds templates overriding the built-in LLM prompts, one
+	// per summary type.
+	PromptsDir string
+	// OutputLang is a BCP 47 tag, such as ja or pt-BR, for the language of
+	// the summaries and report headings; empty means English.
+	OutputLang string
+	// CatalogInfo is a Backstage catalog-info.yaml to create or update.
+	CatalogInfo string
+	// Readme is a README to create, or whose codedoc-managed sections to
+	// rewrite.
+	Readme string
+	// CycloneDX is a CycloneDX JSON BOM to write with the detected endpoints
+	// and pinned dependencies.
+	CycloneDX string
+	// EmitSQLite is a SQLite database to write the analysis to.
+	EmitSQLite string
+	// EmitEditor is a JSON file of file and module summaries for editor
+	// extensions.
+	EmitEditor string
+	// SaveIndex is a file to save the whole analysis to, for ask, diff,
+	// serve and Render to reuse.
+	SaveIndex string
+	// EmitTables is a directory to write the tabular sections to as
+	// TablesFormat (csv or tsv) files.
+	EmitTables      string
+	TablesFormat    string
+	MaxFiles        int
+	MaxLinesPerFile int
+	TopFiles        int
+	FilesPerModule  int
+	FileTokenBudget int
+	MapReduce       bool
+	IncludeTests    bool
+	DryRun          bool
+	Languages       []string
+	RedactSecrets   bool
+	Force           bool
+	JSONOutputFile  string
+	SignKey         string
+	Reproducible    bool
+	// Model, MaxOutputTokens and Temperature configure the Anthropic
+	// requests.
+	Model           string
+	MaxOutputTokens int
+	Temperature     float64
+	CacheDir        string
+	CacheURL        string
+	ConfigFile      string
+	InternalPrefix  []string
+	OrgPaths        []string
+	PerProject      bool
+	SplitByOwner    bool
+	Format          string
+	// Theme is the HTML and PDF color theme: light, dark or auto.
+	Theme string
+	// CSSFile is a style sheet added to HTML and PDF output.
+	CSSFile string
+	// Logo is an image file or URL shown above the report, next to Header.
+	Logo   string
+	Header string
+	Audit  bool
+	// GitHistory mines the git log for hotspots and ownership. With RepoURL,
+	// the clone reaches back over the analyzed window instead of depth 1.
+	GitHistory bool
+	// Glossary appends definitions of abbreviations and domain terms mined
+	// from identifiers.
+	Glossary bool
+	// Decompose suggests module boundaries from the import graph.
+	Decompose bool
+	// Embeddings names the provider, one of embed.Providers, whose
+	// embeddings of the file summaries are clustered into functional areas.
+	Embeddings     string
+	MaxEndpoints   int
+	ReadOnlySource bool
+	Resume         bool
+	// Flags records the explicitly set CLI flags. They appear in the report
+	// provenance, and an explicit max-endpoints wins over codedoc.yaml.
+	Flags map[string]string
+	// ToolVersion is recorded in the report provenance; empty means "dev".
+	ToolVersion string
+	// Progress receives stage progress and notices; nil reports nothing.
+	Progress *Progress
+	// Review, when set, may edit the summaries before the report is written
+	// and record verdicts in opts.Feedback, which is saved afterwards. An
+	// error aborts the run without writing anything.
+	Review func(ctx context.Context, opts SummarizeOptions, summaries *Summaries) error
+	// SummaryMiddleware wraps every LLM request made for summaries, outside
+	// the built-in checkpoint, feedback and redaction middleware.
+	SummaryMiddleware []SummaryMiddleware
+	// SummaryStages replace DefaultSummaryStages when set.
+	SummaryStages []SummaryStage
+	// Profile settings replace those saved in the repository's
+	// .codedoc/profile.yaml for this run. SaveProfile writes the settings in
+	// effect back, recording the modules and top files the run summarized
+	// where none were set.
+	Profile     Profile
+	SaveProfile bool
+}
+
+// DefaultConfig returns the defaults of `codedoc generate` for the
+// repository at path.
+func DefaultConfig(path string) Config {
+	return Config{
+		Path:            path,
+		Paths:           []string{path},
+		OutputFile:      "CODEBASE_REPORT.md",
+		MaxFiles:        200,
+		MaxLinesPerFile: 1000,
+		TopFiles:        10,
+		MaxEndpoints:    20,
+		Languages:       DefaultLanguages(),
+		RedactSecrets:   true,
+		Model:           llm.DefaultModel,
+		MaxOutputTokens: llm.DefaultMaxTokens,
+		Temperature:     llm.DefaultTemperature,
+		CacheDir:        util.DefaultCacheDir(),
+		Format:          report.FormatMarkdown,
+		TablesFormat:    report.TableFormatCSV,
+		Theme:           render.ThemeLight,
+	}
+}
+
+// DefaultLanguages lists the languages analyzed when none are given.
+func DefaultLanguages() []string {
+	return []string{"go", "py", "ts", "js", "md", "yaml", "dockerfile", "terraform"}
+}
+
+func (c *Config) toolVersion() string {
+	if c.ToolVersion == "" {
+		return "dev"
+	}
+	return c.ToolVersion
+}
+
+// languageTag matches BCP 47 tags such as ja, de-AT or zh-Hant-TW.
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// Validate reports the first invalid or conflicting setting, naming it by
+// its CLI flag.
+func (c *Config) Validate() error {
+	if c.Path == "" && c.RepoURL == "" {
+		return fmt.Errorf("either --path or --repo-url must be specified")
+	}
+
+	if c.Path != "" && c.RepoURL != "" {
+		return fmt.Errorf("cannot specify both --path and --repo-url")
+	}
+
+	if len(c.Paths) > 1 && (c.PerProject || c.SplitByOwner) {
+		return fmt.Errorf("--per-project and --split-by-owner need a single --path")
+	}
+
+	if c.MaxFiles <= 0 {
+		return fmt.Errorf("--max-files must be positive")
+	}
+
+	if c.MaxLinesPerFile <= 0 {
+		return fmt.Errorf("--max-lines-per-file must be positive")
+	}
+
+	if c.TopFiles <= 0 {
+		return fmt.Errorf("--top-files must be positive")
+	}
+
+	if c.FilesPerModule < 0 {
+		return fmt.Errorf("--files-per-module must not be negative")
+	}
+
+	if c.FileTokenBudget < 0 {
+		return fmt.Errorf("--file-token-budget must not be negative")
+	}
+
+	if c.MaxEndpoints < 0 {
+		return fmt.Errorf("--max-endpoints must not be negative")
+	}
+
+	if c.MaxOutputTokens <= 0 {
+		return fmt.Errorf("--max-output-tokens must be positive")
+	}
+
+	if c.Temperature < 0 || c.Temperature > 1 {
+		return fmt.Errorf("--temperature must be between 0 and 1")
+	}
+
+	if _, explicit := c.Flags["temperature"]; explicit && c.Reproducible {
+		return fmt.Errorf("cannot specify both --temperature and --reproducible, which uses temperature 0")
+	}
+
+	switch c.Format {
+	case report.FormatMarkdown, report.FormatHTML, report.FormatPDF, report.FormatText:
+	default:
+		return fmt.Errorf("--format must be one of markdown, html, pdf, text")
+	}
+
+	if c.OutputLang != "" && !languageTag.MatchString(c.OutputLang) {
+		return fmt.Errorf("--output-lang must be a language tag such as ja, de or pt-BR")
+	}
+
+	switch c.TablesFormat {
+	case "", report.TableFormatCSV, report.TableFormatTSV:
+	default:
+		return fmt.Errorf("--tables-format must be one of csv, tsv")
+	}
+
+	switch c.Theme {
+	case "", render.ThemeLight, render.ThemeDark, render.ThemeAuto:
+	default:
+		return fmt.Errorf("--theme must be one of light, dark, auto")
+	}
+
+	themed := (c.Theme != "" && c.Theme != render.ThemeLight) || c.CSSFile != "" || c.Logo != "" || c.Header != ""
+	if themed && c.Format != report.FormatHTML && c.Format != report.FormatPDF {
+		return fmt.Errorf("--theme, --css, --logo and --header need --format html or pdf")
+	}
+
+	if c.SignKey != "" && c.JSONOutputFile == "" {
+		return fmt.Errorf("--sign-key requires --json-out")
+	}
+
+	if c.OutDir != "" {
+		if _, explicit := c.Flags["out"]; explicit {
+			return fmt.Errorf("cannot specify both --out and --out-dir")
+		}
+		if c.PerProject || c.SplitByOwner {
+			return fmt.Errorf("--out-dir cannot be combined with --per-project or --split-by-owner")
+		}
+	}
+
+	if c.WriteBaseline && c.PerProject {
+		return fmt.Errorf("--write-baseline cannot be combined with --per-project")
+	}
+
+	if c.CatalogInfo != "" && c.PerProject {
+		return fmt.Errorf("--catalog-info cannot be combined with --per-project")
+	}
+
+	if c.Readme != "" && (c.PerProject || len(c.Paths) > 1) {
+		return fmt.Errorf("--readme needs a single repository and cannot be combined with --per-project")
+	}
+
+	if c.CycloneDX != "" && c.PerProject {
+		return fmt.Errorf("--cyclonedx cannot be combined with --per-project")
+	}
+
+	if c.EmitTables != "" && c.PerProject {
+		return fmt.Errorf("--emit-tables cannot be combined with --per-project")
+	}
+
+	if c.EmitSQLite != "" && c.PerProject {
+		return fmt.Errorf("--emit-sqlite cannot be combined with --per-project")
+	}
+
+	if c.EmitEditor != "" && c.PerProject {
+		return fmt.Errorf("--emit-editor cannot be combined with --per-project")
+	}
+
+	if c.SaveIndex != "" && c.PerProject {
+		return fmt.Errorf("--save-index cannot be combined with --per-project")
+	}
+
+	if c.Embeddings != "" && !slices.Contains(embed.Providers, c.Embeddings) {
+		return fmt.Errorf("--embeddings must be one of %s", strings.Join(embed.Providers, ", "))
+	}
+
+	if c.Resume && c.DryRun {
+		return fmt.Errorf("cannot specify both --resume and --dry-run")
+	}
+
+	if err := c.Profile.Validate(); err != nil {
+		return fmt.Errorf("--guidance: %w", err)
+	}
+
+	if c.SaveProfile && (c.RepoURL != "" || c.PerProject || len(c.Paths) > 1) {
+		return fmt.Errorf("--save-profile needs a single --path and cannot be combined with --per-project")
+	}
+
+	if c.ReadOnlySource {
+		if err := c.checkReadOnlySource(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkReadOnlySource fails when an artifact of this run would be written
+// under an analyzed path. Manifests, signatures and per-project or per-owner
+// reports are written next to --out and --json-out, so checking those covers
+// them.
+func (c *Config) checkReadOnlySource() error {
+	targets := [][2]string{{"--out", c.OutputFile}}
+	if c.OutputDir != "" {
+		targets = append(targets, [2]string{"--output-dir", c.OutputDir})
+	}
+	if c.JSONOutputFile != "" {
+		targets = append(targets, [2]string{"--json-out", c.JSONOutputFile})
+	}
+	// Checkpoints are kept in the cache directory even when responses are
+	// cached at --cache-url.
+	if !c.DryRun {
+		targets = append(targets, [2]string{"--cache-dir", c.CacheDir})
+	}
+	if c.WriteBaseline {
+		targets = append(targets, [2]string{"--write-baseline", baseline.Path(c.BaselineFile, c.Path)})
+	}
+	if c.SaveProfile {
+		targets = append(targets, [2]string{"--save-profile", profile.Path(c.Path)})
+	}
+	if c.CatalogInfo != "" {
+		targets = append(targets, [2]string{"--catalog-info", c.CatalogInfo})
+	}
+	if c.Readme != "" {
+		targets = append(targets, [2]string{"--readme", c.Readme})
+	}
+	if c.CycloneDX != "" {
+		targets = append(targets, [2]string{"--cyclonedx", c.CycloneDX})
+	}
+	if c.EmitTables != "" {
+		targets = append(targets, [2]string{"--emit-tables", c.EmitTables})
+	}
+	if c.EmitSQLite != "" {
+		targets = append(targets, [2]string{"--emit-sqlite", c.EmitSQLite})
+	}
+	if c.EmitEditor != "" {
+		targets = append(targets, [2]string{"--emit-editor", c.EmitEditor})
+	}
+	if c.SaveIndex != "" {
+		targets = append(targets, [2]string{"--save-index", c.SaveIndex})
+	}
+
+	for _, source := range c.Paths {
+		for _, target := range targets {
+			if util.IsWithin(target[1], source) {
+				return fmt.Errorf("--read-only-source: %s %s is inside the analyzed path %s; write it elsewhere", target[0], target[1], source)
+			}
+		}
+	}
+	return nil
+}
+
+// makeOutputDirs creates --output-dir and the directory of --out-dir
+// pages.
+func (c *Config) makeOutputDirs() error {
+	for _, dir := range []string{c.OutputDir, c.OutDir} {
+		if dir == "" {
+			continue
+		}
+		if dir == c.OutDir {
+			dir = filepath.Dir(c.OutputFile)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// theme reads --css and embeds a local --logo, so the written reports do
+// not depend on those files.
+func (c *Config) theme() (render.Theme, error) {
+	theme := render.Theme{Mode: c.Theme, Header: c.Header}
+	if c.CSSFile != "" {
+		css, err := os.ReadFile(c.CSSFile)
+		if err != nil {
+			return render.Theme{}, fmt.Errorf("failed to read --css: %w", err)
+		}
+		theme.CSS = string(css)
+	}
+	if c.Logo != "" {
+		logo, err := render.LogoSource(c.Logo)
+		if err != nil {
+			return render.Theme{}, err
+		}
+		theme.Logo = logo
+	}
+	return theme, nil
+}
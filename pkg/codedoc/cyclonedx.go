@@ -0,0 +1,40 @@
+package codedoc
+
+import (
+	"path/filepath"
+
+	"github.com/codepigeon/codedoc/internal/cyclonedx"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+// writeCycloneDX exports the endpoints and pinned dependencies of the
+// repository at repoPath as a CycloneDX BOM.
+func (g *generation) writeCycloneDX(repoPath string, reportOpts report.Options) error {
+	name := reportOpts.ScanResult.RepoMetadata.Name
+	if name == "" {
+		name = filepath.Base(repoPath)
+	}
+
+	repoURL := g.config.RepoURL
+	if repoURL == "" {
+		repoURL = util.GitRemoteURL(repoPath)
+	}
+
+	description := ""
+	if !g.config.DryRun && reportOpts.Summaries != nil {
+		description = firstSentence(reportOpts.Summaries.ArchitectureSummary)
+	}
+
+	return cyclonedx.Write(g.config.CycloneDX, cyclonedx.Build(cyclonedx.Input{
+		Name:         name,
+		Description:  description,
+		Version:      reportOpts.Provenance.CommitSHA,
+		RepoURL:      repoURL,
+		Tags:         report.Tags(reportOpts),
+		ToolVersion:  reportOpts.Provenance.ToolVersion,
+		Timestamp:    reportOpts.Provenance.GeneratedAt,
+		Detection:    reportOpts.DetectionResult,
+		Dependencies: reportOpts.Dependencies,
+	}))
+}
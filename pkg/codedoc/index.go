@@ -0,0 +1,133 @@
+package codedoc
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/codepigeon/codedoc/internal/baseline"
+	appconfig "github.com/codepigeon/codedoc/internal/config"
+	"github.com/codepigeon/codedoc/internal/depmap"
+	"github.com/codepigeon/codedoc/internal/embed"
+	"github.com/codepigeon/codedoc/internal/graph"
+	"github.com/codepigeon/codedoc/internal/index"
+	"github.com/codepigeon/codedoc/internal/owners"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/risk"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+// saveIndex writes the analysis behind reportOpts to --save-index, with
+// the embeddings ask ranks files by. Embedding failures are noted and leave
+// them out.
+func (g *generation) saveIndex(ctx context.Context, reportOpts report.Options) error {
+	idx := index.New(reportOpts)
+
+	name := g.config.Embeddings
+	if name == "" || g.config.DryRun {
+		name = "local"
+	}
+	embeddings, err := indexEmbeddings(ctx, name, reportOpts.ScanResult, reportOpts.Summaries)
+	if err != nil && ctx.Err() == nil {
+		g.progress.Infof("Note: --save-index without embeddings: %v", err)
+	}
+	idx.Embeddings = embeddings
+
+	return index.Save(g.config.SaveIndex, idx)
+}
+
+func indexEmbeddings(ctx context.Context, name string, scanResult *ScanResult, summaries *Summaries) (*index.Embeddings, error) {
+	provider, err := embed.New(name)
+	if err != nil {
+		return nil, err
+	}
+	docs := summarize.AskDocuments(scanResult, summaries)
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
+	}
+	vectors, err := provider.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(docs) {
+		return nil, fmt.Errorf("got %d vectors for %d files", len(vectors), len(docs))
+	}
+
+	embeddings := &index.Embeddings{Provider: name, Vectors: make(map[string]index.Vector, len(docs))}
+	for i, doc := range docs {
+		embeddings.Vectors[doc.Path] = vectors[i]
+	}
+	return embeddings, nil
+}
+
+// Render writes the report of an index saved with SaveIndex, without
+// scanning the repository or calling the LLM. Only the output settings of
+// config apply: OutputFile, JSONOutputFile, OutDir, Format, OutputLang, the
+// theme and templates, MaxEndpoints, BaselineFile and ConfigFile. When the
+// indexed repository is still on disk, its CODEOWNERS, baseline and risk
+// rules are read again.
+func Render(ctx context.Context, indexFile string, config Config) (*Report, error) {
+	idx, err := index.Load(indexFile)
+	if err != nil {
+		return nil, err
+	}
+	if config.Path == "" && config.RepoURL == "" {
+		config.Path = idx.RepoPath
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.makeOutputDirs(); err != nil {
+		return nil, err
+	}
+
+	var templates *report.Templates
+	if config.TemplateDir != "" {
+		if templates, err = report.LoadTemplates(config.TemplateDir); err != nil {
+			return nil, err
+		}
+	}
+	theme, err := config.theme()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := idx.Options()
+	opts.OutputFile = config.OutputFile
+	opts.Format = config.Format
+	opts.MaxEndpoints = config.MaxEndpoints
+	opts.SplitPages = config.OutDir != ""
+	opts.Templates = templates
+	opts.Theme = theme
+	opts.Language = config.OutputLang
+	opts.ImportGraph = graph.Build(idx.Scan.Files, depmap.ModuleName(idx.RepoPath))
+
+	if info, err := os.Stat(idx.RepoPath); err == nil && info.IsDir() {
+		if opts.Codeowners, err = owners.Load(idx.RepoPath); err != nil {
+			return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+		}
+		if opts.Baseline, err = baseline.Load(baseline.Path(config.BaselineFile, idx.RepoPath)); err != nil {
+			return nil, err
+		}
+		fileConfig, err := appconfig.Resolve(config.ConfigFile, idx.RepoPath)
+		if err != nil {
+			return nil, err
+		}
+		if opts.RiskRules, err = risk.Compile(fileConfig.Risks); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := report.Generate(ctx, opts); err != nil {
+		return nil, fmt.Errorf("report generation failed: %w", err)
+	}
+	config.Progress.Infof("Report generated: %s", config.OutputFile)
+	if config.JSONOutputFile != "" {
+		if err := report.WriteJSON(opts, config.JSONOutputFile); err != nil {
+			return nil, err
+		}
+		config.Progress.Infof("JSON artifact written: %s", config.JSONOutputFile)
+	}
+	return newReport(opts, config.JSONOutputFile), nil
+}
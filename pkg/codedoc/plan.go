@@ -0,0 +1,68 @@
+package codedoc
+
+import (
+	"context"
+	"fmt"
+
+	appconfig "github.com/codepigeon/codedoc/internal/config"
+	"github.com/codepigeon/codedoc/internal/preflight"
+	"github.com/codepigeon/codedoc/internal/scanner"
+)
+
+// Preflight sizes up the repository described by config without analyzing
+// it: its languages, what config would scan, the expected LLM cost and
+// time, and flags worth changing.
+func Preflight(ctx context.Context, config Config) (*Plan, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	repoPath := config.Path
+	if config.RepoURL != "" {
+		clonedPath, cleanupFunc, err := cloneRepository(ctx, config.RepoURL, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone repository: %w", err)
+		}
+		defer cleanupFunc()
+		repoPath = clonedPath
+	}
+
+	fileConfig, err := appconfig.Resolve(config.ConfigFile, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return plan(ctx, &config, repoPath, fileConfig)
+}
+
+func plan(ctx context.Context, config *Config, repoPath string, fileConfig *appconfig.File) (*Plan, error) {
+	census, err := scanner.TakeCensus(ctx, scanner.Options{Path: repoPath, Tests: fileConfig.Tests})
+	if err != nil {
+		return nil, fmt.Errorf("pre-flight failed: %w", err)
+	}
+	return preflight.New(census, preflight.Settings{
+		MaxFiles:        config.MaxFiles,
+		MaxLinesPerFile: config.MaxLinesPerFile,
+		TopFiles:        config.TopFiles,
+		FilesPerModule:  config.FilesPerModule,
+		IncludeTests:    config.IncludeTests,
+		MapReduce:       config.MapReduce,
+		Languages:       config.Languages,
+	}), nil
+}
+
+// preflight prints a short plan of the run before it starts.
+func (g *generation) preflight(ctx context.Context, repoPath string) {
+	p, err := plan(ctx, g.config, repoPath, g.fileConfig)
+	if err != nil {
+		g.progress.Infof("Note: %v", err)
+		return
+	}
+	estimate := p.Estimate.String()
+	if g.config.DryRun {
+		estimate = "no LLM requests in a dry run"
+	}
+	g.progress.Infof("Pre-flight: %d of %d files selected, %s", p.Scanned, p.Files, estimate)
+	for _, recommendation := range p.Recommendations {
+		g.progress.Infof("  Consider %s: %s", recommendation.Arg(), recommendation.Reason)
+	}
+}
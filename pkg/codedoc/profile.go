@@ -0,0 +1,30 @@
+package codedoc
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+// saveProfile writes the profile in effect. Modules and priority files not
+// set yet are learned from what the run summarized, so later runs keep them
+// until the profile is edited.
+func (g *generation) saveProfile(summaries *summarize.Result) error {
+	learned := *g.profile
+	if !summaries.Incomplete {
+		if len(learned.Modules) == 0 {
+			for module := range summaries.ModuleSummaries {
+				learned.Modules = append(learned.Modules, filepath.ToSlash(module))
+			}
+			sort.Strings(learned.Modules)
+		}
+		if len(learned.PriorityFiles) == 0 {
+			for path := range summaries.FileSummaries {
+				learned.PriorityFiles = append(learned.PriorityFiles, filepath.ToSlash(path))
+			}
+			sort.Strings(learned.PriorityFiles)
+		}
+	}
+	return learned.Save(g.profileFile)
+}
@@ -0,0 +1,98 @@
+package codedoc
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codepigeon/codedoc/internal/readme"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/util"
+)
+
+// maxLayoutDirs bounds the directory layout section of the README.
+const maxLayoutDirs = 15
+
+// writeReadme creates the README of the repository at repoPath, or
+// refreshes the sections codedoc manages in it.
+func (g *generation) writeReadme(repoPath string, reportOpts report.Options) error {
+	name := reportOpts.ScanResult.RepoMetadata.Name
+	if name == "" {
+		name = filepath.Base(repoPath)
+	}
+	repoURL := g.config.RepoURL
+	if repoURL == "" {
+		repoURL = util.GitRemoteURL(repoPath)
+	}
+
+	in := readme.Input{
+		Name:      name,
+		RepoURL:   repoURL,
+		Languages: codeLanguages(reportOpts.ScanResult),
+		License:   readme.License(repoPath),
+		Pipelines: reportOpts.DetectionResult.Pipelines,
+		Layout:    layout(reportOpts.ScanResult),
+	}
+	for _, framework := range reportOpts.DetectionResult.Frameworks {
+		in.Frameworks = append(in.Frameworks, framework.Name)
+	}
+	// Dry-run summaries are placeholders, so those sections stay as they are.
+	if !g.config.DryRun && reportOpts.Summaries != nil {
+		in.Description = firstSentence(reportOpts.Summaries.ArchitectureSummary)
+		in.Architecture = reportOpts.Summaries.ArchitectureSummary
+		in.Quickstart = reportOpts.Summaries.QuickstartSteps
+		for i, dir := range in.Layout {
+			in.Layout[i].Summary = firstSentence(reportOpts.Summaries.ModuleSummaries[filepath.FromSlash(dir.Path)])
+		}
+	}
+
+	return readme.Write(g.config.Readme, name, readme.Sections(in))
+}
+
+func codeLanguages(scanResult *ScanResult) []string {
+	stats := scanResult.CodeLanguageStats
+	if len(stats) == 0 {
+		stats = scanResult.LanguageStats
+	}
+	languages := make([]string, 0, len(stats))
+	for language := range stats {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if stats[languages[i]].Percentage != stats[languages[j]].Percentage {
+			return stats[languages[i]].Percentage > stats[languages[j]].Percentage
+		}
+		return languages[i] < languages[j]
+	})
+	return languages
+}
+
+// layout lists the top-level directories and their children holding at
+// least two scanned files, dropping the children when there are too many.
+func layout(scanResult *ScanResult) []readme.Dir {
+	counts := map[string]int{}
+	for _, file := range scanResult.Files {
+		parts := strings.Split(filepath.ToSlash(filepath.Dir(file.RelativePath)), "/")
+		for i := 1; i <= min(len(parts), 2); i++ {
+			if parts[0] != "." {
+				counts[strings.Join(parts[:i], "/")]++
+			}
+		}
+	}
+
+	dirs := []readme.Dir{}
+	for dir, count := range counts {
+		if count >= 2 {
+			dirs = append(dirs, readme.Dir{Path: dir, Files: count})
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Path < dirs[j].Path })
+	if len(dirs) > maxLayoutDirs {
+		dirs = slices.DeleteFunc(dirs, func(dir readme.Dir) bool { return strings.Contains(dir.Path, "/") })
+	}
+	if len(dirs) > maxLayoutDirs {
+		dirs = dirs[:maxLayoutDirs]
+	}
+	return dirs
+}
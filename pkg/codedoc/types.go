@@ -0,0 +1,100 @@
+package codedoc
+
+import (
+	"io"
+
+	"github.com/codepigeon/codedoc/internal/detect"
+	"github.com/codepigeon/codedoc/internal/llm"
+	"github.com/codepigeon/codedoc/internal/preflight"
+	"github.com/codepigeon/codedoc/internal/profile"
+	"github.com/codepigeon/codedoc/internal/progress"
+	"github.com/codepigeon/codedoc/internal/report"
+	"github.com/codepigeon/codedoc/internal/scanner"
+	"github.com/codepigeon/codedoc/internal/summarize"
+)
+
+// The result types are aliases of the pipeline's own, so embedders can name
+// them without importing internal packages.
+type (
+	ScanResult   = scanner.Result
+	FileInfo     = scanner.FileInfo
+	LanguageStat = scanner.LanguageStat
+	RepoMetadata = scanner.RepoMetadata
+
+	DetectionResult = detect.Result
+	Framework       = detect.Framework
+	Entrypoint      = detect.Entrypoint
+	Endpoint        = detect.Endpoint
+	Model           = detect.Model
+	Finding         = detect.Finding
+	Secret          = detect.Secret
+	Tag             = detect.Tag
+
+	Summaries         = summarize.Result
+	FileSummary       = summarize.FileSummary
+	SummarizeOptions  = summarize.Options
+	SummaryStage      = summarize.Stage
+	SummaryMiddleware = summarize.Middleware
+	ProviderFunc      = summarize.ProviderFunc
+
+	LLMProvider = llm.Provider
+	LLMRequest  = llm.SummarizeRequest
+	LLMResponse = llm.SummarizeResponse
+
+	Provenance = report.Provenance
+
+	Profile = profile.Profile
+
+	Plan           = preflight.Plan
+	Recommendation = preflight.Recommendation
+)
+
+// Progress prints pipeline stages; a nil *Progress reports nothing.
+type Progress = progress.Reporter
+
+type ProgressLevel = progress.Level
+
+const (
+	ProgressQuiet   = progress.Quiet
+	ProgressNormal  = progress.Normal
+	ProgressVerbose = progress.Verbose
+)
+
+// NewProgress returns a Progress writing to out, drawing bars only when out
+// is a terminal.
+func NewProgress(out io.Writer, level ProgressLevel) *Progress {
+	return progress.New(out, level)
+}
+
+// DefaultSummaryStages returns the built-in summary stages, in order.
+func DefaultSummaryStages() []SummaryStage {
+	return summarize.DefaultStages()
+}
+
+// MapReduceSummaryStages returns the stages Config.MapReduce runs: the
+// default ones with the module summaries first.
+func MapReduceSummaryStages() []SummaryStage {
+	return summarize.MapReduceStages()
+}
+
+// TransformRequests returns middleware that rewrites every LLM request
+// before it is sent.
+func TransformRequests(transform func(request LLMRequest) LLMRequest) SummaryMiddleware {
+	return summarize.TransformRequests(transform)
+}
+
+// ValidateResponses returns middleware that rejects LLM responses failing
+// validate; the summary is then skipped or falls back as on any LLM error.
+func ValidateResponses(validate func(request LLMRequest, response LLMResponse) error) SummaryMiddleware {
+	return summarize.ValidateResponses(validate)
+}
+
+// ReportOptions is what a report section is rendered from.
+type ReportOptions = report.Options
+
+type SectionFunc = report.SectionFunc
+
+// RegisterSection adds a custom report section; see report.RegisterSection.
+func RegisterSection(name string, fn SectionFunc) {
+	report.RegisterSection(name, fn)
+}